@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"loan-management/infrastructure"
+	"loan-management/internal/repository"
+	"loan-management/internal/usecase"
+	"log"
+	"strconv"
+	"time"
+)
+
+const apiKeyUsage = "Usage: app apikey [list|create <partner name>|rotate <id> [grace period, e.g. 24h]|revoke <id>]"
+
+// APIKey manages partner api keys, the plain key is only printed once
+func APIKey(args []string) {
+	if len(args) == 0 {
+		log.Fatal(apiKeyUsage)
+	}
+
+	db, err := infrastructure.Initialize()
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer infrastructure.CloseDB()
+
+	auditUsecase := usecase.NewAuditUsecase(repository.NewAuditLogRepository(db, infrastructure.DBDialect))
+	userUsecase := usecase.NewUserUsecase(repository.NewUserRepository(db, infrastructure.DBDialect), auditUsecase)
+	apiKeyRepo := repository.NewAPIKeyRepository(db, infrastructure.DBDialect)
+	authUsecase := usecase.NewAuthUsecase(userUsecase, apiKeyRepo, infrastructure.JWTSecret(), infrastructure.JWTTTL())
+	ctx := context.Background()
+
+	switch args[0] {
+	case "list":
+		keys, err := authUsecase.GetAllAPIKeys(ctx)
+		if err != nil {
+			log.Fatalf("Failed to list api keys: %v", err)
+		}
+		for _, key := range keys {
+			fmt.Printf("%d\t%s\t%s\tstatus=%d\texpires_at=%v\tlast_used_at=%v\n", key.ID, key.Name, key.Prefix, key.Status, key.ExpiresAt, key.LastUsedAt)
+		}
+	case "create":
+		if len(args) < 2 {
+			log.Fatal(apiKeyUsage)
+		}
+		issued, err := authUsecase.CreateAPIKey(ctx, args[1])
+		if err != nil {
+			log.Fatalf("Failed to create api key: %v", err)
+		}
+		fmt.Printf("Created api key %d for %s: %s\n", issued.APIKey.ID, issued.APIKey.Name, issued.Key)
+	case "rotate":
+		id := parseID(args)
+		gracePeriod := 24 * time.Hour
+		if len(args) > 2 {
+			if gracePeriod, err = time.ParseDuration(args[2]); err != nil {
+				log.Fatalf("Invalid grace period: %v", err)
+			}
+		}
+		issued, err := authUsecase.RotateAPIKey(ctx, id, gracePeriod)
+		if err != nil {
+			log.Fatalf("Failed to rotate api key: %v", err)
+		}
+		fmt.Printf("Rotated api key %d, old key expires in %s. New api key %d: %s\n", id, gracePeriod, issued.APIKey.ID, issued.Key)
+	case "revoke":
+		id := parseID(args)
+		if err := authUsecase.RevokeAPIKey(ctx, id); err != nil {
+			log.Fatalf("Failed to revoke api key: %v", err)
+		}
+		fmt.Printf("Revoked api key %d\n", id)
+	default:
+		log.Fatal(apiKeyUsage)
+	}
+}
+
+func parseID(args []string) int64 {
+	if len(args) < 2 {
+		log.Fatal(apiKeyUsage)
+	}
+	id, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		log.Fatalf("Invalid ID format: %v", err)
+	}
+	return id
+}
@@ -6,6 +6,10 @@ import (
 )
 
 func Destroy() {
+	if _, err := infrastructure.Initialize(); err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+
 	if err := infrastructure.Destroy(); err != nil {
 		log.Fatalf("Destroy failed: %v", err)
 	}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"loan-management/infrastructure"
+	"loan-management/internal/entity"
+	"loan-management/internal/repository"
+	"loan-management/internal/usecase"
+	"log"
+	"strconv"
+)
+
+const roleUsage = "Usage: app role <user id> <borrower|credit_officer|collector|finance|admin>"
+
+// Role assigns a role to a user, e.g. to grant the first admin
+func Role(args []string) {
+	if len(args) < 2 {
+		log.Fatal(roleUsage)
+	}
+
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		log.Fatal(roleUsage)
+	}
+
+	role, ok := entity.ParseRole(args[1])
+	if !ok {
+		log.Fatal(roleUsage)
+	}
+
+	db, err := infrastructure.Initialize()
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer infrastructure.CloseDB()
+
+	auditUsecase := usecase.NewAuditUsecase(repository.NewAuditLogRepository(db, infrastructure.DBDialect))
+	userUsecase := usecase.NewUserUsecase(repository.NewUserRepository(db, infrastructure.DBDialect), auditUsecase)
+
+	user, err := userUsecase.UpdateUserRole(context.Background(), id, role)
+	if err != nil {
+		log.Fatalf("Failed to update role: %v", err)
+	}
+
+	fmt.Printf("User %d (%s) is now %s\n", user.ID, user.Email, user.Role)
+}
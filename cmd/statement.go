@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"loan-management/infrastructure"
+	"loan-management/internal/entity"
+	"loan-management/internal/i18n"
+	"loan-management/internal/repository"
+	"loan-management/internal/statement"
+	"loan-management/internal/usecase"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+const statementUsage = "Usage: app statement [--from YYYY-MM-DD] [--to YYYY-MM-DD] [--format pdf|csv] [--locale en|id] [--out dir] <all|loan id...>"
+
+// Statement exports loan statements into a directory, one file per loan, e.g. for auditors at period end
+func Statement(args []string) {
+	flags := flag.NewFlagSet("statement", flag.ExitOnError)
+	flags.Usage = func() { fmt.Fprintln(os.Stderr, statementUsage) }
+	fromParam := flags.String("from", "", "first day of the period, defaults to the disbursement")
+	toParam := flags.String("to", "", "last day of the period, defaults to today")
+	formatParam := flags.String("format", string(entity.StatementFormatPDF), "pdf or csv")
+	localeParam := flags.String("locale", string(i18n.DefaultLocale), "language of the labels")
+	out := flags.String("out", ".", "directory the statements are written to")
+	flags.Parse(args)
+
+	format := entity.StatementFormat(*formatParam)
+	if statement.ContentType(format) == "" || flags.NArg() == 0 {
+		log.Fatal(statementUsage)
+	}
+
+	var from, to time.Time
+	var err error
+	if *fromParam != "" {
+		if from, err = time.Parse(time.DateOnly, *fromParam); err != nil {
+			log.Fatal(statementUsage)
+		}
+	}
+	if *toParam != "" {
+		if to, err = time.Parse(time.DateOnly, *toParam); err != nil {
+			log.Fatal(statementUsage)
+		}
+		to = to.Add(24*time.Hour - time.Nanosecond)
+	}
+
+	db, err := infrastructure.Initialize()
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer infrastructure.CloseDB()
+
+	loanRepo := repository.NewLoanRepository(db, infrastructure.DBDialect)
+	statementUsecase := usecase.NewStatementUsecase(
+		loanRepo,
+		repository.NewPaymentRepository(db, infrastructure.DBDialect),
+		repository.NewTransactionRepository(db, infrastructure.DBDialect),
+		repository.NewLedgerRepository(db, infrastructure.DBDialect),
+	)
+	ctx := context.Background()
+
+	var loanIDs []int64
+	if flags.Arg(0) == "all" {
+		loans, err := loanRepo.GetAllLoans(ctx)
+		if err != nil {
+			log.Fatalf("Failed to list loans: %v", err)
+		}
+		for _, loan := range loans {
+			loanIDs = append(loanIDs, loan.ID)
+		}
+	} else {
+		for _, arg := range flags.Args() {
+			id, err := strconv.ParseInt(arg, 10, 64)
+			if err != nil {
+				log.Fatal(statementUsage)
+			}
+			loanIDs = append(loanIDs, id)
+		}
+	}
+
+	if err := os.MkdirAll(*out, 0o755); err != nil {
+		log.Fatalf("Failed to create %s: %v", *out, err)
+	}
+
+	locale := i18n.Locale(*localeParam)
+	for _, id := range loanIDs {
+		loanStatement, err := statementUsecase.GetStatement(ctx, id, from, to)
+		if err != nil {
+			log.Fatalf("Failed to generate the statement of loan %d: %v", id, err)
+		}
+
+		path := filepath.Join(*out, statement.Filename(loanStatement, format))
+		if err := writeStatement(path, format, locale, loanStatement); err != nil {
+			log.Fatalf("Failed to write %s: %v", path, err)
+		}
+		fmt.Println(path)
+	}
+	fmt.Printf("Exported %d statements\n", len(loanIDs))
+}
+
+func writeStatement(path string, format entity.StatementFormat, locale i18n.Locale, loanStatement *entity.LoanStatement) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := statement.Write(file, format, locale, loanStatement); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"loan-management/infrastructure"
+	"loan-management/internal/repository"
+	"loan-management/internal/usecase"
+	"log"
+	"os"
+)
+
+const verifyUsage = "Usage: app verify [--repair]"
+
+// Verify reconciles every loan with its payments and ledger, exits with 1 while issues remain
+func Verify(args []string) {
+	repair := false
+	for _, arg := range args {
+		if arg != "--repair" {
+			log.Fatal(verifyUsage)
+		}
+		repair = true
+	}
+
+	db, err := infrastructure.Initialize()
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer infrastructure.CloseDB()
+
+	auditUsecase := usecase.NewAuditUsecase(repository.NewAuditLogRepository(db, infrastructure.DBDialect))
+	ledgerRepo := repository.NewLedgerRepository(db, infrastructure.DBDialect)
+	reconciliationUsecase := usecase.NewReconciliationUsecase(
+		repository.NewLoanRepository(db, infrastructure.DBDialect),
+		repository.NewPaymentRepository(db, infrastructure.DBDialect),
+		ledgerRepo,
+		auditUsecase,
+		usecase.NewLedgerUsecase(ledgerRepo),
+	)
+
+	report, err := reconciliationUsecase.Verify(context.Background(), repair)
+	if err != nil {
+		log.Fatalf("Failed to verify: %v", err)
+	}
+
+	for _, issue := range report.Issues {
+		status := ""
+		if issue.Repaired {
+			status = " (repaired)"
+		}
+		fmt.Printf("%-20s loan=%d payment=%d expected=%.2f actual=%.2f%s\n", issue.Type, issue.LoanID, issue.PaymentID, issue.Expected, issue.Actual, status)
+	}
+	fmt.Printf("Checked %d loans, %d issues, %d repaired\n", report.CheckedLoans, len(report.Issues), report.Repaired)
+
+	if report.Unresolved() > 0 {
+		infrastructure.CloseDB()
+		os.Exit(1)
+	}
+}
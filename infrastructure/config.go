@@ -0,0 +1,148 @@
+package infrastructure
+
+import (
+	"crypto/rand"
+	"loan-management/internal/entity"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultVirtualAccountPrefix = "8808"
+	defaultBusinessTimezone     = "Asia/Jakarta"
+)
+
+// JWTSecret reads the signing secret, falling back to a random one so tokens don't survive a restart
+func JWTSecret() []byte {
+	if secret := os.Getenv("JWT_SECRET"); secret != "" {
+		return []byte(secret)
+	}
+
+	log.Println("Warning: JWT_SECRET is not set, using a random secret")
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		log.Fatalf("Failed to generate jwt secret: %v", err)
+	}
+	return secret
+}
+
+func JWTTTL() time.Duration {
+	ttl, err := time.ParseDuration(os.Getenv("JWT_TTL"))
+	if err != nil || ttl <= 0 {
+		return 24 * time.Hour
+	}
+	return ttl
+}
+
+// VirtualAccountPrefix starts every virtual account number, it must be all digits
+func VirtualAccountPrefix() string {
+	prefix := os.Getenv("VIRTUAL_ACCOUNT_PREFIX")
+	if prefix == "" {
+		return defaultVirtualAccountPrefix
+	}
+
+	if strings.Trim(prefix, "0123456789") != "" {
+		log.Printf("Warning: VIRTUAL_ACCOUNT_PREFIX %q is not numeric, using %s", prefix, defaultVirtualAccountPrefix)
+		return defaultVirtualAccountPrefix
+	}
+	return prefix
+}
+
+func EventDispatchInterval() time.Duration {
+	interval, err := time.ParseDuration(os.Getenv("EVENT_DISPATCH_INTERVAL"))
+	if err != nil || interval <= 0 {
+		return 5 * time.Second
+	}
+	return interval
+}
+
+func WebhookDeliveryInterval() time.Duration {
+	interval, err := time.ParseDuration(os.Getenv("WEBHOOK_DELIVERY_INTERVAL"))
+	if err != nil || interval <= 0 {
+		return 5 * time.Second
+	}
+	return interval
+}
+
+func DelinquencyCheckInterval() time.Duration {
+	interval, err := time.ParseDuration(os.Getenv("DELINQUENCY_CHECK_INTERVAL"))
+	if err != nil || interval <= 0 {
+		return time.Hour
+	}
+	return interval
+}
+
+func AutodebitInterval() time.Duration {
+	interval, err := time.ParseDuration(os.Getenv("AUTODEBIT_INTERVAL"))
+	if err != nil || interval <= 0 {
+		return time.Hour
+	}
+	return interval
+}
+
+// AutodebitMaxAttempts is how many times a collection is tried before it is given up
+func AutodebitMaxAttempts() int {
+	attempts, err := strconv.Atoi(os.Getenv("AUTODEBIT_MAX_ATTEMPTS"))
+	if err != nil || attempts <= 0 {
+		return 3
+	}
+	return attempts
+}
+
+func AutodebitRetryInterval() time.Duration {
+	interval, err := time.ParseDuration(os.Getenv("AUTODEBIT_RETRY_INTERVAL"))
+	if err != nil || interval <= 0 {
+		return 24 * time.Hour
+	}
+	return interval
+}
+
+func NotificationInterval() time.Duration {
+	interval, err := time.ParseDuration(os.Getenv("NOTIFICATION_INTERVAL"))
+	if err != nil || interval <= 0 {
+		return time.Hour
+	}
+	return interval
+}
+
+// NotificationReminderDays is how many days before the due date a borrower is reminded
+func NotificationReminderDays() int {
+	days, err := strconv.Atoi(os.Getenv("NOTIFICATION_REMINDER_DAYS"))
+	if err != nil || days <= 0 {
+		return 3
+	}
+	return days
+}
+
+// BusinessDayConvention moves due dates off weekends and holidays, following by default
+func BusinessDayConvention() entity.BusinessDayConvention {
+	name := os.Getenv("BUSINESS_DAY_CONVENTION")
+	if name == "" {
+		return entity.BusinessDayFollowing
+	}
+
+	convention, ok := entity.ParseBusinessDayConvention(name)
+	if !ok {
+		log.Printf("Warning: BUSINESS_DAY_CONVENTION %q is unknown, using %s", name, entity.BusinessDayFollowing)
+		return entity.BusinessDayFollowing
+	}
+	return convention
+}
+
+// BusinessLocation is the timezone due dates and overdue checks are counted in, whatever the zone of the server
+func BusinessLocation() *time.Location {
+	name := os.Getenv("BUSINESS_TIMEZONE")
+	if name == "" {
+		name = defaultBusinessTimezone
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		log.Printf("Warning: BUSINESS_TIMEZONE %q is unknown, using UTC", name)
+		return time.UTC
+	}
+	return loc
+}
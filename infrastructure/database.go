@@ -3,27 +3,68 @@ package infrastructure
 import (
 	"database/sql"
 	"fmt"
+	"loan-management/internal/entity"
 	"log"
 	"os"
+	"strings"
 
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"golang.org/x/crypto/bcrypt"
 	_ "modernc.org/sqlite"
 )
 
-var DB *sql.DB
+var (
+	DB        *sql.DB
+	DBDialect Dialect
+)
+
+// tables are listed in creation order, Destroy drops them in reverse
+var tables = []string{"users", "loans", "transactions", "payments", "api_keys", "audit_logs", "accounts", "journal_entries", "journal_lines", "outbox_events", "webhook_subscriptions", "webhook_deliveries", "payment_callbacks", "bank_statements", "bank_statement_lines", "autodebit_mandates", "collection_attempts", "notification_preferences", "notifications", "loan_restructures", "holidays", "transaction_allocations", "schema_migrations"}
 
 func Initialize() (*sql.DB, error) {
 	var err error
 
-	DB, err = sql.Open("sqlite", "loans")
+	driver := os.Getenv("DB_DRIVER")
+	if driver == "" {
+		driver = DriverSQLite
+	}
+
+	dsn := os.Getenv("DB_DSN")
+	if dsn == "" && driver == DriverSQLite {
+		dsn = "loans"
+	}
+
+	DB, err = Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	DBDialect = NewDialect(driver)
+
+	return DB, nil
+}
+
+// Open connects to the database using one of the supported drivers
+func Open(driver string, dsn string) (*sql.DB, error) {
+	var sqlDriver string
+	switch driver {
+	case DriverSQLite:
+		sqlDriver = "sqlite"
+	case DriverPostgres:
+		sqlDriver = "pgx"
+	default:
+		return nil, fmt.Errorf("unsupported db driver: %s", driver)
+	}
+
+	db, err := sql.Open(sqlDriver, dsn)
 	if err != nil {
 		return nil, fmt.Errorf("Failed accessing db: %w", err)
 	}
 
-	if err = DB.Ping(); err != nil {
+	if err = db.Ping(); err != nil {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	return DB, nil
+	return db, nil
 }
 
 func CloseDB() {
@@ -32,55 +73,466 @@ func CloseDB() {
 	}
 }
 
-func Migrate() error {
-	query := `
+type migration struct {
+	version int
+	name    string
+	up      string
+	// data runs after up for changes SQL alone can't make
+	data func(tx *sql.Tx, dialect Dialect) error
+}
+
+// migrations are applied in order and recorded in schema_migrations, never edit an applied one
+var migrations = []migration{
+	{
+		version: 1,
+		name:    "create base tables",
+		up: `
 	CREATE TABLE IF NOT EXISTS users (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		id {{pk}},
 		email TEXT UNIQUE,
 		name TEXT,
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		created_at {{timestamp}} DEFAULT CURRENT_TIMESTAMP
 	);
 	CREATE TABLE IF NOT EXISTS loans (
-    id INTEGER PRIMARY KEY AUTOINCREMENT,
-    user_id INTEGER,
-    interest REAL,
-    interest_type INTEGER,
-    tenure INTEGER,
-    tenure_type INTEGER,
-    amount REAL,
-    outstanding REAL,
-    status INTEGER,
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    billing_start_at TIMESTAMP,
-    FOREIGN KEY (user_id) REFERENCES users(id)
+		id {{pk}},
+		user_id INTEGER,
+		interest {{real}},
+		interest_type INTEGER,
+		tenure INTEGER,
+		tenure_type INTEGER,
+		amount {{real}},
+		outstanding {{real}},
+		status INTEGER,
+		created_at {{timestamp}} DEFAULT CURRENT_TIMESTAMP,
+		billing_start_at {{timestamp}},
+		FOREIGN KEY (user_id) REFERENCES users(id)
+	);
+	CREATE TABLE IF NOT EXISTS transactions (
+		id {{pk}},
+		total_amount {{real}},
+		penalty {{real}},
+		status INTEGER,
+		paid_at {{timestamp}},
+		created_at {{timestamp}} DEFAULT CURRENT_TIMESTAMP
 	);
 	CREATE TABLE IF NOT EXISTS payments (
-    id INTEGER PRIMARY KEY AUTOINCREMENT,
+		id {{pk}},
 		transaction_id INTEGER,
-    loan_id INTEGER NOT NULL,
-    due_date DATE,
-    payment_no INTEGER,
-    amount REAL,
-    interest REAL,
-    total_amount REAL,
-    status INTEGER,
-    paid_at TIMESTAMP,
-    created_at TIMESTAMP,
-    FOREIGN KEY (loan_id) REFERENCES loans(id)
+		loan_id INTEGER NOT NULL,
+		due_date DATE,
+		payment_no INTEGER,
+		amount {{real}},
+		interest {{real}},
+		total_amount {{real}},
+		status INTEGER,
+		paid_at {{timestamp}},
+		created_at {{timestamp}},
+		FOREIGN KEY (loan_id) REFERENCES loans(id),
 		FOREIGN KEY (transaction_id) REFERENCES transactions(id)
 	);
-	CREATE TABLE IF NOT EXISTS transactions (
-    id INTEGER PRIMARY KEY AUTOINCREMENT,
-    total_amount REAL,
-    penalty REAL,
-    status INTEGER,
-    paid_at TIMESTAMP,
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	`,
+	},
+	{
+		version: 2,
+		name:    "add user credentials and partner api keys",
+		up: `
+	ALTER TABLE users ADD COLUMN password_hash TEXT;
+	CREATE TABLE IF NOT EXISTS api_keys (
+		id {{pk}},
+		name TEXT NOT NULL,
+		prefix TEXT NOT NULL UNIQUE,
+		key_hash TEXT NOT NULL,
+		status INTEGER,
+		expires_at {{timestamp}},
+		last_used_at {{timestamp}},
+		created_at {{timestamp}} DEFAULT CURRENT_TIMESTAMP
+	);
+	`,
+	},
+	{
+		version: 3,
+		name:    "add user roles and loan disbursement date",
+		up: `
+	ALTER TABLE users ADD COLUMN role INTEGER NOT NULL DEFAULT 1;
+	ALTER TABLE loans ADD COLUMN disbursed_at {{timestamp}};
+	UPDATE loans SET disbursed_at = created_at;
+	`,
+	},
+	{
+		version: 4,
+		name:    "create audit logs",
+		up: `
+	CREATE TABLE IF NOT EXISTS audit_logs (
+		id {{pk}},
+		actor_type INTEGER,
+		actor_id INTEGER,
+		actor_name TEXT,
+		action TEXT NOT NULL,
+		entity_type TEXT NOT NULL,
+		entity_id INTEGER NOT NULL,
+		changes TEXT,
+		request_id TEXT,
+		created_at {{timestamp}} DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_audit_logs_entity ON audit_logs (entity_type, entity_id);
+	`,
+	},
+	{
+		version: 5,
+		name:    "create general ledger",
+		up: `
+	CREATE TABLE IF NOT EXISTS accounts (
+		code TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		type INTEGER NOT NULL
+	);
+	INSERT INTO accounts (code, name, type) VALUES
+		('1000', 'Cash', 1),
+		('1100', 'Loan Receivable', 1),
+		('2000', 'Suspense', 2),
+		('4000', 'Interest Income', 4),
+		('4100', 'Fee Income', 4);
+	CREATE TABLE IF NOT EXISTS journal_entries (
+		id {{pk}},
+		loan_id INTEGER,
+		reference_type TEXT NOT NULL,
+		reference_id INTEGER NOT NULL,
+		description TEXT,
+		reversal_of INTEGER,
+		posted_at {{timestamp}} DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (loan_id) REFERENCES loans(id),
+		FOREIGN KEY (reversal_of) REFERENCES journal_entries(id)
 	);
+	CREATE INDEX IF NOT EXISTS idx_journal_entries_reference ON journal_entries (reference_type, reference_id);
+	CREATE TABLE IF NOT EXISTS journal_lines (
+		id {{pk}},
+		journal_entry_id INTEGER NOT NULL,
+		account_code TEXT NOT NULL,
+		debit {{real}} NOT NULL DEFAULT 0,
+		credit {{real}} NOT NULL DEFAULT 0,
+		FOREIGN KEY (journal_entry_id) REFERENCES journal_entries(id),
+		FOREIGN KEY (account_code) REFERENCES accounts(code)
+	);
+	`,
+	},
+	{
+		version: 6,
+		name:    "create outbox events",
+		up: `
+	ALTER TABLE loans ADD COLUMN delinquent_since {{timestamp}};
+	CREATE TABLE IF NOT EXISTS outbox_events (
+		id {{pk}},
+		type TEXT NOT NULL,
+		aggregate_type TEXT NOT NULL,
+		aggregate_id INTEGER NOT NULL,
+		payload TEXT NOT NULL,
+		status INTEGER NOT NULL,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		last_error TEXT,
+		next_attempt_at {{timestamp}} NOT NULL,
+		occurred_at {{timestamp}} NOT NULL,
+		delivered_at {{timestamp}}
+	);
+	CREATE INDEX IF NOT EXISTS idx_outbox_events_pending ON outbox_events (status, next_attempt_at);
+	`,
+	},
+	{
+		version: 7,
+		name:    "create webhooks",
+		up: `
+	CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+		id {{pk}},
+		url TEXT NOT NULL,
+		event_types TEXT NOT NULL,
+		secret TEXT NOT NULL,
+		status INTEGER NOT NULL,
+		created_at {{timestamp}} NOT NULL,
+		updated_at {{timestamp}}
+	);
+	CREATE TABLE IF NOT EXISTS webhook_deliveries (
+		id {{pk}},
+		subscription_id INTEGER NOT NULL,
+		event_id INTEGER NOT NULL,
+		event_type TEXT NOT NULL,
+		status INTEGER NOT NULL,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		response_status INTEGER,
+		last_error TEXT,
+		next_attempt_at {{timestamp}} NOT NULL,
+		created_at {{timestamp}} NOT NULL,
+		delivered_at {{timestamp}},
+		UNIQUE (subscription_id, event_id),
+		FOREIGN KEY (subscription_id) REFERENCES webhook_subscriptions(id),
+		FOREIGN KEY (event_id) REFERENCES outbox_events(id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_pending ON webhook_deliveries (status, next_attempt_at);
+	`,
+	},
+	{
+		version: 8,
+		name:    "create payment callbacks",
+		up: `
+	ALTER TABLE transactions ADD COLUMN channel TEXT;
+	ALTER TABLE transactions ADD COLUMN external_id TEXT;
+	ALTER TABLE transactions ADD COLUMN rounding {{real}} NOT NULL DEFAULT 0;
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_external ON transactions (channel, external_id);
+	INSERT INTO accounts (code, name, type) VALUES ('5000', 'Rounding Differences', 5);
+	CREATE TABLE IF NOT EXISTS payment_callbacks (
+		id {{pk}},
+		provider TEXT NOT NULL,
+		external_id TEXT,
+		reference TEXT,
+		loan_id INTEGER,
+		amount {{real}},
+		payment_status TEXT,
+		status INTEGER NOT NULL,
+		transaction_id INTEGER,
+		error TEXT,
+		headers TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		received_at {{timestamp}} NOT NULL,
+		processed_at {{timestamp}},
+		FOREIGN KEY (transaction_id) REFERENCES transactions(id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_payment_callbacks_external ON payment_callbacks (provider, external_id);
+	`,
+	},
+	{
+		version: 9,
+		name:    "add loan virtual accounts",
+		up: `
+	ALTER TABLE loans ADD COLUMN virtual_account TEXT;
+	ALTER TABLE loans ADD COLUMN virtual_account_status INTEGER;
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_loans_virtual_account ON loans (virtual_account);
+	`,
+		data: assignMissingVirtualAccounts,
+	},
+	{
+		version: 10,
+		name:    "create bank statements",
+		up: `
+	CREATE TABLE IF NOT EXISTS bank_statements (
+		id {{pk}},
+		account TEXT NOT NULL,
+		format TEXT NOT NULL,
+		file_name TEXT,
+		line_count INTEGER NOT NULL,
+		imported_at {{timestamp}} NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS bank_statement_lines (
+		id {{pk}},
+		statement_id INTEGER NOT NULL,
+		line_no INTEGER NOT NULL,
+		account TEXT NOT NULL,
+		external_id TEXT NOT NULL,
+		booked_at {{timestamp}} NOT NULL,
+		amount {{real}} NOT NULL,
+		reference TEXT,
+		description TEXT,
+		status INTEGER NOT NULL,
+		loan_id INTEGER,
+		transaction_id INTEGER,
+		note TEXT,
+		reviewed_at {{timestamp}},
+		FOREIGN KEY (statement_id) REFERENCES bank_statements(id),
+		FOREIGN KEY (transaction_id) REFERENCES transactions(id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_bank_statement_lines_external ON bank_statement_lines (account, external_id);
+	CREATE INDEX IF NOT EXISTS idx_bank_statement_lines_status ON bank_statement_lines (status);
+	`,
+	},
+	{
+		version: 11,
+		name:    "create autodebit mandates",
+		up: `
+	CREATE TABLE IF NOT EXISTS autodebit_mandates (
+		id {{pk}},
+		loan_id INTEGER NOT NULL,
+		provider TEXT NOT NULL,
+		account_reference TEXT NOT NULL,
+		max_amount {{real}} NOT NULL,
+		status INTEGER NOT NULL,
+		created_at {{timestamp}} NOT NULL,
+		updated_at {{timestamp}},
+		FOREIGN KEY (loan_id) REFERENCES loans(id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_autodebit_mandates_loan ON autodebit_mandates (loan_id, status);
+	CREATE TABLE IF NOT EXISTS collection_attempts (
+		id {{pk}},
+		mandate_id INTEGER NOT NULL,
+		loan_id INTEGER NOT NULL,
+		payment_id INTEGER NOT NULL,
+		due_date {{timestamp}} NOT NULL,
+		amount {{real}} NOT NULL,
+		status INTEGER NOT NULL,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		provider_reference TEXT,
+		transaction_id INTEGER,
+		last_error TEXT,
+		next_attempt_at {{timestamp}} NOT NULL,
+		created_at {{timestamp}} NOT NULL,
+		completed_at {{timestamp}},
+		UNIQUE (mandate_id, payment_id),
+		FOREIGN KEY (mandate_id) REFERENCES autodebit_mandates(id),
+		FOREIGN KEY (transaction_id) REFERENCES transactions(id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_collection_attempts_pending ON collection_attempts (status, next_attempt_at);
+	`,
+	},
+	{
+		version: 12,
+		name:    "create notifications",
+		up: `
+	CREATE TABLE IF NOT EXISTS notification_preferences (
+		user_id INTEGER PRIMARY KEY,
+		locale TEXT NOT NULL,
+		channels TEXT NOT NULL,
+		muted_kinds TEXT NOT NULL,
+		updated_at {{timestamp}},
+		FOREIGN KEY (user_id) REFERENCES users(id)
+	);
+	CREATE TABLE IF NOT EXISTS notifications (
+		id {{pk}},
+		user_id INTEGER NOT NULL,
+		loan_id INTEGER NOT NULL,
+		kind TEXT NOT NULL,
+		channel TEXT NOT NULL,
+		address TEXT NOT NULL,
+		dedupe_key TEXT NOT NULL UNIQUE,
+		subject TEXT NOT NULL,
+		body TEXT NOT NULL,
+		status INTEGER NOT NULL,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		last_error TEXT,
+		created_at {{timestamp}} NOT NULL,
+		sent_at {{timestamp}},
+		FOREIGN KEY (user_id) REFERENCES users(id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_notifications_user ON notifications (user_id, id);
+	CREATE INDEX IF NOT EXISTS idx_notifications_status ON notifications (status);
+	`,
+	},
+	{
+		version: 13,
+		name:    "create loan restructures",
+		up: `
+	ALTER TABLE payments ADD COLUMN closed_at {{timestamp}};
+	CREATE TABLE IF NOT EXISTS loan_restructures (
+		id {{pk}},
+		loan_id INTEGER NOT NULL,
+		reason TEXT NOT NULL,
+		approved_by INTEGER,
+		previous_interest {{real}} NOT NULL,
+		previous_tenure INTEGER NOT NULL,
+		previous_outstanding {{real}} NOT NULL,
+		interest {{real}} NOT NULL,
+		tenure INTEGER NOT NULL,
+		grace_periods INTEGER NOT NULL,
+		principal {{real}} NOT NULL,
+		capitalized_arrears {{real}} NOT NULL,
+		outstanding {{real}} NOT NULL,
+		first_payment_no INTEGER NOT NULL,
+		created_at {{timestamp}} NOT NULL,
+		FOREIGN KEY (loan_id) REFERENCES loans(id),
+		FOREIGN KEY (approved_by) REFERENCES users(id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_loan_restructures_loan ON loan_restructures (loan_id);
+	`,
+	},
+	{
+		version: 14,
+		name:    "add loan grace and interest only periods",
+		up: `
+	ALTER TABLE loans ADD COLUMN grace_periods INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE loans ADD COLUMN grace_interest INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE loans ADD COLUMN interest_only_periods INTEGER NOT NULL DEFAULT 0;
+	INSERT INTO accounts (code, name, type) VALUES ('2100', 'Unearned Interest', 2);
+	`,
+	},
+	{
+		version: 15,
+		name:    "add loan repayment structure",
+		up: `
+	ALTER TABLE loans ADD COLUMN repayment_structure INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE loans ADD COLUMN balloon_percent {{real}} NOT NULL DEFAULT 0;
+	`,
+	},
+	{
+		version: 16,
+		name:    "create holidays and add payment original due date",
+		up: `
+	CREATE TABLE IF NOT EXISTS holidays (
+		id {{pk}},
+		date DATE NOT NULL UNIQUE,
+		name TEXT NOT NULL,
+		created_at {{timestamp}} NOT NULL
+	);
+	ALTER TABLE payments ADD COLUMN original_due_date DATE;
+	`,
+	},
+	{
+		version: 17,
+		name:    "create transaction allocations",
+		up: `
+	CREATE TABLE IF NOT EXISTS transaction_allocations (
+		id {{pk}},
+		transaction_id INTEGER NOT NULL,
+		loan_id INTEGER NOT NULL,
+		amount {{real}} NOT NULL,
+		created_at {{timestamp}} NOT NULL,
+		FOREIGN KEY (transaction_id) REFERENCES transactions(id),
+		FOREIGN KEY (loan_id) REFERENCES loans(id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_transaction_allocations_transaction ON transaction_allocations (transaction_id);
+	`,
+	},
+}
 
-	`
-	_, err := DB.Exec(query)
+// assignMissingVirtualAccounts gives the loans booked before virtual accounts existed theirs, already closed for the
+// loans paid off. Pending loans get theirs when approved, rejected ones never do
+func assignMissingVirtualAccounts(tx *sql.Tx, dialect Dialect) error {
+	query := `SELECT id, status FROM loans WHERE (virtual_account IS NULL OR virtual_account = '') AND status NOT IN (?, ?)`
+	rows, err := tx.Query(dialect.Rebind(query), entity.LoanStatusPending, entity.LoanStatusRejected)
 	if err != nil {
+		return err
+	}
+
+	statuses := map[int64]entity.LoanStatus{}
+	for rows.Next() {
+		var (
+			id     int64
+			status entity.LoanStatus
+		)
+		if err := rows.Scan(&id, &status); err != nil {
+			rows.Close()
+			return err
+		}
+		statuses[id] = status
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	prefix := VirtualAccountPrefix()
+	for id, status := range statuses {
+		virtualAccountStatus := entity.VirtualAccountStatusActive
+		if status == entity.LoanStatusPaid {
+			virtualAccountStatus = entity.VirtualAccountStatusInactive
+		}
+
+		_, err := tx.Exec(dialect.Rebind(`UPDATE loans SET virtual_account = ?, virtual_account_status = ? WHERE id = ?`),
+			entity.NewVirtualAccountNumber(prefix, id), virtualAccountStatus, id)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func Migrate() error {
+	if err := MigrateSchema(DB, DBDialect); err != nil {
 		return fmt.Errorf("Migration is failed: %w", err)
 	}
 
@@ -88,10 +540,100 @@ func Migrate() error {
 	return nil
 }
 
+// MigrateSchema applies every pending migration on the given connection
+func MigrateSchema(db *sql.DB, dialect Dialect) error {
+	_, err := db.Exec(dialect.Schema(`
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT,
+		applied_at {{timestamp}} DEFAULT CURRENT_TIMESTAMP
+	)`))
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		var applied int
+		err := db.QueryRow(dialect.Rebind(`SELECT COUNT(*) FROM schema_migrations WHERE version = ?`), m.version).Scan(&applied)
+		if err != nil {
+			return err
+		}
+		if applied > 0 {
+			continue
+		}
+
+		if err := applyMigration(db, dialect, m); err != nil {
+			return fmt.Errorf("migration %d (%s): %w", m.version, m.name, err)
+		}
+		log.Printf("Applied migration %d: %s", m.version, m.name)
+	}
+
+	return nil
+}
+
+func applyMigration(db *sql.DB, dialect Dialect, m migration) (err error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	for _, statement := range strings.Split(dialect.Schema(m.up), ";") {
+		if strings.TrimSpace(statement) == "" {
+			continue
+		}
+		if _, err = tx.Exec(statement); err != nil {
+			return err
+		}
+	}
+
+	if m.data != nil {
+		if err = m.data(tx, dialect); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(dialect.Rebind(`INSERT INTO schema_migrations (version, name) VALUES (?, ?)`), m.version, m.name)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DropSchema drops all tables on the given connection
+func DropSchema(db *sql.DB) error {
+	for i := len(tables) - 1; i >= 0; i-- {
+		if _, err := db.Exec("DROP TABLE IF EXISTS " + tables[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func Destroy() error {
+	if DBDialect.IsPostgres() {
+		defer CloseDB()
+		if err := DropSchema(DB); err != nil {
+			return fmt.Errorf("Reinitialized DB: %w", err)
+		}
+		return nil
+	}
+
 	CloseDB()
 
-	err := os.Remove("loans")
+	dsn := os.Getenv("DB_DSN")
+	if dsn == "" {
+		dsn = "loans"
+	}
+
+	err := os.Remove(dsn)
 	if err != nil {
 		return fmt.Errorf("Reinitialized DB: %w", err)
 	}
@@ -100,14 +642,31 @@ func Destroy() error {
 }
 
 func Seed() error {
-	query := `
-	INSERT INTO users (email, name) VALUES (?, ?)
-	`
-	_, err := DB.Exec(query, "test@test", "test")
+	// password of the seeded user is "password"
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.DefaultCost)
 	if err != nil {
 		return fmt.Errorf("Failed to seed database: %w", err)
 	}
 
+	query := `
+	INSERT INTO users (email, name, password_hash, role) VALUES (?, ?, ?, ?)
+	`
+	// role 1 is borrower and role 5 is admin, see entity.Role
+	users := []struct {
+		email string
+		name  string
+		role  int
+	}{
+		{"test@test", "test", 1},
+		{"admin@test", "admin", 5},
+	}
+	for _, user := range users {
+		_, err = DB.Exec(DBDialect.Rebind(query), user.email, user.name, string(passwordHash), user.role)
+		if err != nil {
+			return fmt.Errorf("Failed to seed database: %w", err)
+		}
+	}
+
 	log.Println("Database seeded successfully")
 	return nil
 }
@@ -0,0 +1,33 @@
+package infrastructure
+
+import (
+	"context"
+	"loan-management/internal/entity"
+	"strings"
+)
+
+// FakeDebitProvider is a local stand-in for a direct debit scheme. Debits are approved unless the account
+// reference says otherwise: accounts starting with "INSUFFICIENT" are declined with a retryable reason and
+// accounts starting with "CLOSED" are declined for good
+type FakeDebitProvider struct{}
+
+func NewFakeDebitProvider() *FakeDebitProvider {
+	return &FakeDebitProvider{}
+}
+
+func (p *FakeDebitProvider) Name() string {
+	return "fake"
+}
+
+func (p *FakeDebitProvider) Debit(ctx context.Context, request entity.DebitRequest) (*entity.DebitResult, error) {
+	account := strings.ToUpper(request.AccountReference)
+
+	switch {
+	case strings.HasPrefix(account, "INSUFFICIENT"):
+		return &entity.DebitResult{Retryable: true, Reason: "insufficient funds"}, nil
+	case strings.HasPrefix(account, "CLOSED"):
+		return &entity.DebitResult{Reason: "account closed"}, nil
+	default:
+		return &entity.DebitResult{ProviderReference: "fake_" + request.Key, Approved: true}, nil
+	}
+}
@@ -0,0 +1,119 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+)
+
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+// Querier is satisfied by both *sql.DB and *sql.Tx
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// Dialect hides the SQL differences between the supported drivers.
+// Repositories keep writing `?` placeholders and let the dialect rebind them.
+type Dialect struct {
+	driver string
+}
+
+func NewDialect(driver string) Dialect {
+	if driver == "" {
+		driver = DriverSQLite
+	}
+	return Dialect{driver: driver}
+}
+
+func (d Dialect) Driver() string {
+	return d.driver
+}
+
+func (d Dialect) IsPostgres() bool {
+	return d.driver == DriverPostgres
+}
+
+// Rebind converts `?` placeholders into `$1, $2, ...` for postgres
+func (d Dialect) Rebind(query string) string {
+	if !d.IsPostgres() {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// ForUpdate returns the row locking clause, sqlite locks the whole db on write so it is a no-op there
+func (d Dialect) ForUpdate() string {
+	if d.IsPostgres() {
+		return " FOR UPDATE"
+	}
+	return ""
+}
+
+// Month formats a timestamp column as YYYY-MM for grouping, sqlite stores timestamps as text starting with the date
+func (d Dialect) Month(column string) string {
+	if d.IsPostgres() {
+		return "to_char(" + column + ", 'YYYY-MM')"
+	}
+	return "substr(" + column + ", 1, 7)"
+}
+
+// InsertReturningID executes an insert and returns the generated id.
+// Postgres has no LastInsertId so it uses `RETURNING id` instead.
+func (d Dialect) InsertReturningID(ctx context.Context, q Querier, query string, args ...any) (int64, error) {
+	query = strings.TrimRight(strings.TrimSpace(query), ";")
+
+	if d.IsPostgres() {
+		var id int64
+		err := q.QueryRowContext(ctx, d.Rebind(query+" RETURNING id"), args...).Scan(&id)
+		return id, err
+	}
+
+	result, err := q.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+// Schema expands the column type tokens used by the migrations
+func (d Dialect) Schema(ddl string) string {
+	var replacer *strings.Replacer
+	if d.IsPostgres() {
+		replacer = strings.NewReplacer(
+			"{{pk}}", "BIGSERIAL PRIMARY KEY",
+			"{{timestamp}}", "TIMESTAMPTZ",
+			"{{real}}", "DOUBLE PRECISION",
+			"{{bool}}", "BOOLEAN",
+			"{{blob}}", "BYTEA",
+		)
+	} else {
+		replacer = strings.NewReplacer(
+			"{{pk}}", "INTEGER PRIMARY KEY AUTOINCREMENT",
+			"{{timestamp}}", "TIMESTAMP",
+			"{{real}}", "REAL",
+			"{{bool}}", "INTEGER",
+			"{{blob}}", "BLOB",
+		)
+	}
+	return replacer.Replace(ddl)
+}
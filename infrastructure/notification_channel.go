@@ -0,0 +1,131 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"loan-management/internal/entity"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SMTPChannel sends notifications as plain text email
+type SMTPChannel struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewSMTPChannel authenticates with PLAIN auth when a username is given, servers without auth such as a local
+// stub are used as is
+func NewSMTPChannel(host, port, username, password, from string) *SMTPChannel {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &SMTPChannel{addr: net.JoinHostPort(host, port), auth: auth, from: from}
+}
+
+func (c *SMTPChannel) Name() string {
+	return entity.NotificationChannelEmail
+}
+
+func (c *SMTPChannel) Send(ctx context.Context, message *entity.NotificationMessage) error {
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", c.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", message.To)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", message.Subject)
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(message.Body)
+	msg.WriteString("\r\n")
+
+	return smtp.SendMail(c.addr, c.auth, c.from, []string{message.To}, []byte(msg.String()))
+}
+
+// GatewayChannel posts notifications as json to an sms or push gateway, any non 2xx response fails the send
+type GatewayChannel struct {
+	name   string
+	url    string
+	token  string
+	client *http.Client
+}
+
+func NewGatewayChannel(name, url, token string) *GatewayChannel {
+	return &GatewayChannel{name: name, url: url, token: token, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (c *GatewayChannel) Name() string {
+	return c.name
+}
+
+func (c *GatewayChannel) Send(ctx context.Context, message *entity.NotificationMessage) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+	return nil
+}
+
+// WriterChannel writes every notification as a json line instead of sending it, e.g. to a file for local testing
+type WriterChannel struct {
+	mu   *sync.Mutex
+	name string
+	w    io.Writer
+}
+
+// NewWriterChannels returns one channel per name, all writing to w
+func NewWriterChannels(w io.Writer, names ...string) []*WriterChannel {
+	mu := &sync.Mutex{}
+	channels := make([]*WriterChannel, 0, len(names))
+	for _, name := range names {
+		channels = append(channels, &WriterChannel{mu: mu, name: name, w: w})
+	}
+	return channels
+}
+
+func (c *WriterChannel) Name() string {
+	return c.name
+}
+
+func (c *WriterChannel) Send(ctx context.Context, message *entity.NotificationMessage) error {
+	line, err := json.Marshal(struct {
+		Channel string `json:"channel"`
+		*entity.NotificationMessage
+	}{c.name, message})
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, err = c.w.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,85 @@
+package infrastructure
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"loan-management/internal/entity"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const HeaderFakeSignature = "X-Fake-Signature"
+
+// FakePaymentProvider is a local stand-in for a payment gateway. It signs the raw body with a shared secret
+// (hex HMAC-SHA256 in X-Fake-Signature) and posts:
+//
+//	{"id": "pay_123", "reference": "LOAN-1", "amount": 110000, "status": "paid", "paid_at": "2025-01-01T00:00:00Z"}
+type FakePaymentProvider struct {
+	secret []byte
+}
+
+func NewFakePaymentProvider(secret string) *FakePaymentProvider {
+	return &FakePaymentProvider{secret: []byte(secret)}
+}
+
+type fakeCallback struct {
+	ID        string    `json:"id"`
+	Reference string    `json:"reference"`
+	Amount    float64   `json:"amount"`
+	Status    string    `json:"status"`
+	PaidAt    time.Time `json:"paid_at"`
+}
+
+func (p *FakePaymentProvider) Name() string {
+	return "fake"
+}
+
+// Sign returns the signature the provider sends for body
+func (p *FakePaymentProvider) Sign(body []byte) string {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (p *FakePaymentProvider) VerifySignature(headers http.Header, body []byte) bool {
+	signature, err := hex.DecodeString(strings.TrimSpace(headers.Get(HeaderFakeSignature)))
+	if err != nil {
+		return false
+	}
+
+	expected, _ := hex.DecodeString(p.Sign(body))
+	return hmac.Equal(signature, expected)
+}
+
+func (p *FakePaymentProvider) ParseCallback(body []byte) (*entity.GatewayPayment, error) {
+	var callback fakeCallback
+	if err := json.Unmarshal(body, &callback); err != nil {
+		return nil, err
+	}
+
+	if callback.ID == "" {
+		return nil, errors.New("id is required")
+	}
+
+	payment := &entity.GatewayPayment{
+		ExternalID: callback.ID,
+		Reference:  callback.Reference,
+		Amount:     callback.Amount,
+		PaidAt:     callback.PaidAt,
+	}
+
+	switch callback.Status {
+	case "paid", "settled":
+		payment.Status = entity.GatewayPaymentStatusPaid
+	case "pending":
+		payment.Status = entity.GatewayPaymentStatusPending
+	default:
+		payment.Status = entity.GatewayPaymentStatusFailed
+	}
+
+	return payment, nil
+}
@@ -0,0 +1,138 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Kind int8
+
+const (
+	KindInternal Kind = iota
+	KindBadRequest
+	KindValidation
+	KindNotFound
+	KindConflict
+	KindForbidden
+	KindEligibility
+	KindUnauthorized
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindBadRequest:
+		return "Bad Request"
+	case KindValidation:
+		return "Validation"
+	case KindNotFound:
+		return "Not Found"
+	case KindConflict:
+		return "Conflict"
+	case KindForbidden:
+		return "Forbidden"
+	case KindEligibility:
+		return "Eligibility"
+	case KindUnauthorized:
+		return "Unauthorized"
+	default:
+		return "Internal"
+	}
+}
+
+// FieldError describes why a single input field was rejected
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+	// Key is the catalog key of Message, validation.<rule> when empty
+	Key string `json:"-"`
+}
+
+// Error is a domain error, Code is stable and meant to be consumed by clients
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Fields  []FieldError
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches errors of the same code, so wrapped copies still match their sentinel
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	return e.Code == t.Code
+}
+
+// Wrap returns a copy of the error carrying the underlying cause
+func (e *Error) Wrap(err error) *Error {
+	return &Error{Kind: e.Kind, Code: e.Code, Message: e.Message, Fields: e.Fields, Err: err}
+}
+
+// WithFields returns a copy of the error listing the rejected fields
+func (e *Error) WithFields(fields []FieldError) *Error {
+	return &Error{Kind: e.Kind, Code: e.Code, Message: e.Message, Fields: fields, Err: e.Err}
+}
+
+func New(kind Kind, code string, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func BadRequest(code string, message string) *Error {
+	return New(KindBadRequest, code, message)
+}
+
+func Validation(code string, message string) *Error {
+	return New(KindValidation, code, message)
+}
+
+func NotFound(code string, message string) *Error {
+	return New(KindNotFound, code, message)
+}
+
+func Conflict(code string, message string) *Error {
+	return New(KindConflict, code, message)
+}
+
+func Forbidden(code string, message string) *Error {
+	return New(KindForbidden, code, message)
+}
+
+func Eligibility(code string, message string) *Error {
+	return New(KindEligibility, code, message)
+}
+
+func Unauthorized(code string, message string) *Error {
+	return New(KindUnauthorized, code, message)
+}
+
+// As returns the domain error in err's chain, if any
+func As(err error) (*Error, bool) {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
+
+// KindOf returns KindInternal for errors that are not domain errors
+func KindOf(err error) Kind {
+	if appErr, ok := As(err); ok {
+		return appErr.Kind
+	}
+	return KindInternal
+}
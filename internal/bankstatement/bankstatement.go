@@ -0,0 +1,215 @@
+// Package bankstatement reads the mutation files banks hand out, as csv exports or SWIFT MT940 statements
+package bankstatement
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"loan-management/internal/entity"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrUnknownFormat = errors.New("unknown statement format")
+
+// Parse reads the entries of a statement, account is only known for formats that carry it
+func Parse(format entity.BankStatementFormat, content []byte) (account string, entries []entity.BankStatementEntry, err error) {
+	switch format {
+	case entity.BankStatementFormatCSV:
+		entries, err = ParseCSV(bytes.NewReader(content))
+		return "", entries, err
+	case entity.BankStatementFormatMT940:
+		return ParseMT940(bytes.NewReader(content))
+	default:
+		return "", nil, ErrUnknownFormat
+	}
+}
+
+var csvDateLayouts = []string{"2006-01-02", "02/01/2006", time.RFC3339}
+
+// ParseCSV reads a csv export with a header row. The date and amount columns are required, reference and
+// description are optional. Debits either have a negative amount or a type column of D, DB or DR
+func ParseCSV(r io.Reader) ([]entity.BankStatementEntry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"date", "amount"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("missing %s column", required)
+		}
+	}
+
+	column := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var entries []entity.BankStatementEntry
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		bookedAt, err := parseCSVDate(column(record, "date"))
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", row, err)
+		}
+
+		amount, err := strconv.ParseFloat(strings.ReplaceAll(column(record, "amount"), ",", ""), 64)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: invalid amount %q", row, column(record, "amount"))
+		}
+
+		switch strings.ToUpper(column(record, "type")) {
+		case "D", "DB", "DR":
+			amount = -amount
+		}
+
+		entries = append(entries, entity.BankStatementEntry{
+			BookedAt:    bookedAt,
+			Amount:      amount,
+			Reference:   column(record, "reference"),
+			Description: column(record, "description"),
+		})
+	}
+
+	return entries, nil
+}
+
+func parseCSVDate(value string) (time.Time, error) {
+	for _, layout := range csvDateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
+
+// mt940Line is the :61: statement line: value date, optional entry date, debit/credit mark, optional funds code,
+// amount, transaction type, customer reference and optional bank reference
+var mt940Line = regexp.MustCompile(`^(\d{6})(\d{4})?(RC|RD|C|D)([A-Z])?(\d+,\d*)([NFS][A-Z0-9]{3})([^/]*)(?://(.*))?$`)
+
+// ParseMT940 reads the :25: account and every :61: line of a SWIFT MT940 statement, with its :86: information
+// as the description
+func ParseMT940(r io.Reader) (string, []entity.BankStatementEntry, error) {
+	var (
+		account string
+		entries []entity.BankStatementEntry
+		// tag and value of the field being read, :86: and :61: values can span several lines
+		tag, value string
+	)
+
+	flush := func() error {
+		switch tag {
+		case "25":
+			account = strings.TrimSpace(value)
+		case "61":
+			entry, err := parseMT940Line(strings.SplitN(value, "\n", 2)[0])
+			if err != nil {
+				return err
+			}
+			entries = append(entries, entry)
+		case "86":
+			// the information belongs to the statement line right above it
+			if len(entries) > 0 {
+				entries[len(entries)-1].Description = strings.Join(strings.Fields(value), " ")
+			}
+		}
+		tag, value = "", ""
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.HasPrefix(line, ":") {
+			if end := strings.Index(line[1:], ":"); end > 0 {
+				if err := flush(); err != nil {
+					return "", nil, err
+				}
+				tag, value = line[1:end+1], line[end+2:]
+				continue
+			}
+		}
+		// a line starting with - closes the message, anything else continues the current field
+		if strings.HasPrefix(line, "-") {
+			if err := flush(); err != nil {
+				return "", nil, err
+			}
+			continue
+		}
+		if tag != "" {
+			value += "\n" + line
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", nil, err
+	}
+	if err := flush(); err != nil {
+		return "", nil, err
+	}
+
+	if account == "" && len(entries) == 0 {
+		return "", nil, errors.New("no :25: account or :61: statement line")
+	}
+
+	return account, entries, nil
+}
+
+func parseMT940Line(value string) (entity.BankStatementEntry, error) {
+	match := mt940Line.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return entity.BankStatementEntry{}, fmt.Errorf("invalid statement line %q", value)
+	}
+
+	bookedAt, err := time.Parse("060102", match[1])
+	if err != nil {
+		return entity.BankStatementEntry{}, fmt.Errorf("invalid value date %q", match[1])
+	}
+
+	amount, err := strconv.ParseFloat(strings.Replace(match[5], ",", ".", 1), 64)
+	if err != nil {
+		return entity.BankStatementEntry{}, fmt.Errorf("invalid amount %q", match[5])
+	}
+
+	// a reversed credit takes money out, a reversed debit puts it back
+	if match[3] == "D" || match[3] == "RC" {
+		amount = -amount
+	}
+
+	reference := strings.TrimSpace(match[8])
+	if reference == "" {
+		reference = strings.TrimSpace(match[7])
+	}
+	if strings.EqualFold(reference, "NONREF") {
+		reference = ""
+	}
+
+	return entity.BankStatementEntry{
+		BookedAt:  bookedAt,
+		Amount:    amount,
+		Reference: reference,
+	}, nil
+}
@@ -0,0 +1,80 @@
+package bankstatement
+
+import (
+	"loan-management/internal/entity"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCSV(t *testing.T) {
+	t.Run("Success ParseCSV", func(t *testing.T) {
+		content := "Date,Reference,Description,Amount,Type\n" +
+			"2025-01-02,BR001,\"TRF FROM BUDI LOAN-1\",\"1,004.00\",CR\n" +
+			"03/01/2025,,ADMIN FEE,5000,DB\n" +
+			"2025-01-03,,REFUND,-250,\n"
+
+		entries, err := ParseCSV(strings.NewReader(content))
+
+		assert.NoError(t, err)
+		assert.Equal(t, []entity.BankStatementEntry{
+			{BookedAt: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), Amount: 1004, Reference: "BR001", Description: "TRF FROM BUDI LOAN-1"},
+			{BookedAt: time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC), Amount: -5000, Description: "ADMIN FEE"},
+			{BookedAt: time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC), Amount: -250, Description: "REFUND"},
+		}, entries)
+	})
+
+	t.Run("Failed ParseCSV - Missing Amount Column", func(t *testing.T) {
+		_, err := ParseCSV(strings.NewReader("date,description\n2025-01-02,x\n"))
+
+		assert.ErrorContains(t, err, "missing amount column")
+	})
+
+	t.Run("Failed ParseCSV - Invalid Date", func(t *testing.T) {
+		_, err := ParseCSV(strings.NewReader("date,amount\n2nd of january,10\n"))
+
+		assert.ErrorContains(t, err, "row 2")
+	})
+}
+
+func TestParseMT940(t *testing.T) {
+	t.Run("Success ParseMT940", func(t *testing.T) {
+		content := ":20:STMT250102\r\n" +
+			":25:BANKIDJA/1234567890\r\n" +
+			":28C:00001/001\r\n" +
+			":60F:C250101IDR1000000,00\r\n" +
+			":61:2501020102C1004,00NTRFNONREF//BR001\r\n" +
+			"PAYMENT\r\n" +
+			":86:TRF FROM BUDI\r\n" +
+			"VA 880800000000016\r\n" +
+			":61:250102D5000,NCHGFEE\r\n" +
+			":86:ADMIN FEE\r\n" +
+			":61:250103RD250,00NTRFREFUND1\r\n" +
+			":62F:C250103IDR996254,00\r\n" +
+			"-\r\n"
+
+		account, entries, err := ParseMT940(strings.NewReader(content))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "BANKIDJA/1234567890", account)
+		assert.Equal(t, []entity.BankStatementEntry{
+			{BookedAt: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), Amount: 1004, Reference: "BR001", Description: "TRF FROM BUDI VA 880800000000016"},
+			{BookedAt: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), Amount: -5000, Reference: "FEE", Description: "ADMIN FEE"},
+			{BookedAt: time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC), Amount: 250, Reference: "REFUND1"},
+		}, entries)
+	})
+
+	t.Run("Failed ParseMT940 - Invalid Statement Line", func(t *testing.T) {
+		_, _, err := ParseMT940(strings.NewReader(":25:123\n:61:yesterday\n"))
+
+		assert.ErrorContains(t, err, "invalid statement line")
+	})
+
+	t.Run("Failed ParseMT940 - Not A Statement", func(t *testing.T) {
+		_, _, err := ParseMT940(strings.NewReader("date,amount\n"))
+
+		assert.Error(t, err)
+	})
+}
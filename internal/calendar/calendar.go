@@ -0,0 +1,112 @@
+// Package calendar tells business days from weekends and holidays, and moves dates onto business days
+package calendar
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"loan-management/internal/entity"
+	"strings"
+	"time"
+)
+
+// Calendar adjusts dates with its business day convention. A nil calendar has every day as business day
+type Calendar struct {
+	convention entity.BusinessDayConvention
+	holidays   map[entity.Date]bool
+}
+
+func New(convention entity.BusinessDayConvention, holidays []*entity.Holiday) *Calendar {
+	c := &Calendar{convention: convention, holidays: make(map[entity.Date]bool, len(holidays))}
+	for _, holiday := range holidays {
+		c.holidays[holiday.Date] = true
+	}
+	return c
+}
+
+// IsBusinessDay is false on weekends and holidays
+func (c *Calendar) IsBusinessDay(date entity.Date) bool {
+	if c == nil {
+		return true
+	}
+	if weekday := date.Weekday(); weekday == time.Saturday || weekday == time.Sunday {
+		return false
+	}
+	return !c.holidays[date]
+}
+
+// Adjust moves date onto a business day following the convention, business days are kept
+func (c *Calendar) Adjust(date entity.Date) entity.Date {
+	if c.IsBusinessDay(date) {
+		return date
+	}
+
+	switch c.convention {
+	case entity.BusinessDayPreceding:
+		return c.roll(date, -1)
+	case entity.BusinessDayModifiedFollowing:
+		if following := c.roll(date, 1); following.Month() == date.Month() {
+			return following
+		}
+		return c.roll(date, -1)
+	default:
+		return c.roll(date, 1)
+	}
+}
+
+// Next is date when it's a business day, else the first business day after it
+func (c *Calendar) Next(date entity.Date) entity.Date {
+	return c.roll(date, 1)
+}
+
+func (c *Calendar) roll(date entity.Date, days int) entity.Date {
+	for !c.IsBusinessDay(date) {
+		date = date.AddDays(days)
+	}
+	return date
+}
+
+// ParseCSV reads a holiday file with a header row and the date (YYYY-MM-DD) and name columns
+func ParseCSV(r io.Reader) ([]entity.HolidayPayload, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"date", "name"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("missing %s column", required)
+		}
+	}
+
+	var holidays []entity.HolidayPayload
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		holiday := entity.HolidayPayload{
+			Date: strings.TrimSpace(record[columns["date"]]),
+			Name: strings.TrimSpace(record[columns["name"]]),
+		}
+		if _, err := entity.ParseDate(holiday.Date); err != nil {
+			return nil, fmt.Errorf("row %d: invalid date %q", row, holiday.Date)
+		}
+		holidays = append(holidays, holiday)
+	}
+
+	return holidays, nil
+}
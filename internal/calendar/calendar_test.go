@@ -0,0 +1,78 @@
+package calendar
+
+import (
+	"loan-management/internal/entity"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func date(year int, month time.Month, day int) entity.Date {
+	return entity.NewDate(year, month, day)
+}
+
+func TestAdjust(t *testing.T) {
+	// 2026-07-31 is a Friday, 2026-12-25 and 2026-12-31 are Fridays too
+	holidays := []*entity.Holiday{
+		{Date: date(2026, 7, 31), Name: "Company Day"},
+		{Date: date(2026, 12, 25), Name: "Christmas Day"},
+		{Date: date(2026, 12, 28), Name: "Collective Leave"},
+	}
+
+	tests := []struct {
+		name       string
+		convention entity.BusinessDayConvention
+		date       entity.Date
+		expected   entity.Date
+	}{
+		{"Business Day Is Kept", entity.BusinessDayFollowing, date(2026, 12, 24), date(2026, 12, 24)},
+		{"Following Skips Weekend And Holidays", entity.BusinessDayFollowing, date(2026, 12, 25), date(2026, 12, 29)},
+		{"Following Saturday", entity.BusinessDayFollowing, date(2026, 10, 24), date(2026, 10, 26)},
+		{"Preceding", entity.BusinessDayPreceding, date(2026, 12, 28), date(2026, 12, 24)},
+		{"Modified Following In Month", entity.BusinessDayModifiedFollowing, date(2026, 12, 25), date(2026, 12, 29)},
+		{"Modified Following Across Month", entity.BusinessDayModifiedFollowing, date(2026, 7, 31), date(2026, 7, 30)},
+		{"Following Across Month", entity.BusinessDayFollowing, date(2026, 7, 31), date(2026, 8, 3)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, New(tt.convention, holidays).Adjust(tt.date))
+		})
+	}
+
+	t.Run("Nil Calendar", func(t *testing.T) {
+		var calendar *Calendar
+		assert.Equal(t, date(2026, 10, 24), calendar.Adjust(date(2026, 10, 24)))
+	})
+}
+
+func TestParseCSV(t *testing.T) {
+	t.Run("Success ParseCSV", func(t *testing.T) {
+		content := "date,name\n" +
+			"# national holidays\n" +
+			"2026-12-25,Christmas Day\n" +
+			"2027-01-01, New Year's Day\n"
+
+		holidays, err := ParseCSV(strings.NewReader(content))
+
+		assert.NoError(t, err)
+		assert.Equal(t, []entity.HolidayPayload{
+			{Date: "2026-12-25", Name: "Christmas Day"},
+			{Date: "2027-01-01", Name: "New Year's Day"},
+		}, holidays)
+	})
+
+	t.Run("Failed ParseCSV - Missing Name Column", func(t *testing.T) {
+		_, err := ParseCSV(strings.NewReader("date\n2026-12-25\n"))
+
+		assert.ErrorContains(t, err, "missing name column")
+	})
+
+	t.Run("Failed ParseCSV - Invalid Date", func(t *testing.T) {
+		_, err := ParseCSV(strings.NewReader("date,name\n25/12/2026,Christmas Day\n"))
+
+		assert.ErrorContains(t, err, "row 2")
+	})
+}
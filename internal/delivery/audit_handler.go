@@ -0,0 +1,34 @@
+package delivery
+
+import (
+	"loan-management/internal/entity"
+	"loan-management/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AuditHandler struct {
+	auditUsecase *usecase.AuditUsecase
+}
+
+func NewAuditHandler(auditUsecase *usecase.AuditUsecase) *AuditHandler {
+	return &AuditHandler{auditUsecase: auditUsecase}
+}
+
+func (h *AuditHandler) GetAuditLogs(ctx *fiber.Ctx) error {
+	var filter entity.AuditLogFilter
+	if err := ctx.QueryParser(&filter); err != nil {
+		return ErrInvalidRequestBody
+	}
+
+	logs, err := h.auditUsecase.GetAuditLogs(ctx.UserContext(), filter)
+	if err != nil {
+		return err
+	}
+
+	if logs == nil {
+		logs = []*entity.AuditLog{}
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": logs})
+}
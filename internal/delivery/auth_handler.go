@@ -0,0 +1,92 @@
+package delivery
+
+import (
+	"loan-management/internal/apperror"
+	"loan-management/internal/entity"
+	"loan-management/internal/usecase"
+	"loan-management/internal/validation"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	HeaderAPIKey   = "X-API-Key"
+	localsIdentity = "identity"
+)
+
+var ErrUnauthorized = apperror.Unauthorized("UNAUTHORIZED", "Authentication is required")
+
+type AuthHandler struct {
+	authUsecase *usecase.AuthUsecase
+}
+
+func NewAuthHandler(authUsecase *usecase.AuthUsecase) *AuthHandler {
+	return &AuthHandler{authUsecase: authUsecase}
+}
+
+func (h *AuthHandler) Login(ctx *fiber.Ctx) error {
+	var payload entity.LoginPayload
+	if err := ctx.BodyParser(&payload); err != nil {
+		return ErrInvalidRequestBody
+	}
+
+	if err := validation.Struct(payload); err != nil {
+		return err
+	}
+
+	token, err := h.authUsecase.Login(ctx.UserContext(), payload.Email, payload.Password)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": token})
+}
+
+// Authenticate accepts either a borrower JWT (Authorization: Bearer) or a partner api key (X-API-Key)
+// and stores the caller identity in the request context
+func (h *AuthHandler) Authenticate(ctx *fiber.Ctx) error {
+	var (
+		identity *entity.Identity
+		err      error
+	)
+
+	if apiKey := ctx.Get(HeaderAPIKey); apiKey != "" {
+		identity, err = h.authUsecase.AuthenticateAPIKey(ctx.UserContext(), apiKey)
+	} else if token, ok := strings.CutPrefix(ctx.Get(fiber.HeaderAuthorization), "Bearer "); ok {
+		identity, err = h.authUsecase.AuthenticateToken(ctx.UserContext(), strings.TrimSpace(token))
+	} else {
+		err = ErrUnauthorized
+	}
+
+	if err != nil {
+		return err
+	}
+
+	ctx.Locals(localsIdentity, identity)
+	ctx.SetUserContext(entity.WithIdentity(ctx.UserContext(), identity))
+
+	return ctx.Next()
+}
+
+// RequirePermission rejects callers holding none of the given permissions, ownership is checked by the usecases
+func RequirePermission(permissions ...entity.Permission) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		identity := entity.IdentityFromContext(ctx.UserContext())
+		if identity == nil {
+			return ErrUnauthorized
+		}
+
+		for _, permission := range permissions {
+			if identity.Can(permission) {
+				return ctx.Next()
+			}
+		}
+
+		return usecase.ErrForbidden
+	}
+}
+
+func (h *AuthHandler) Me(ctx *fiber.Ctx) error {
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": entity.IdentityFromContext(ctx.UserContext())})
+}
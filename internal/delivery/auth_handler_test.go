@@ -0,0 +1,107 @@
+package delivery
+
+import (
+	"encoding/json"
+	"loan-management/internal/entity"
+	internalMock "loan-management/internal/mock"
+	"loan-management/internal/usecase"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestAuthenticate(t *testing.T) {
+	passwordHash, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	mockUserUsecase := new(internalMock.MockUserUsecase)
+	user := &entity.User{ID: 1, Email: "test@test", Name: "test", PasswordHash: string(passwordHash)}
+	mockUserUsecase.On("GetUserByEmail", mock.Anything, "test@test").Return(user, nil)
+	mockUserUsecase.On("GetUserByID", mock.Anything, int64(1)).Return(user, nil)
+
+	handler := NewAuthHandler(usecase.NewAuthUsecase(mockUserUsecase, nil, []byte("secret"), time.Hour))
+
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Post("/login", handler.Login)
+	app.Get("/me", handler.Authenticate, handler.Me)
+
+	t.Run("Failed Authenticate - Missing Credentials", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/me", nil))
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("Failed Authenticate - Invalid Token", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/me", nil)
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer not-a-token")
+
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("Success Login And Authenticate", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/login", strings.NewReader(`{"email": "test@test", "password": "password"}`))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		var login struct {
+			Data entity.Token `json:"data"`
+		}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&login))
+
+		req = httptest.NewRequest("GET", "/me", nil)
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer "+login.Data.AccessToken)
+
+		resp, err = app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		var me struct {
+			Data entity.Identity `json:"data"`
+		}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&me))
+		assert.Equal(t, entity.Identity{Type: entity.IdentityTypeBorrower, Role: entity.RoleBorrower, UserID: 1, Name: "test"}, me.Data)
+	})
+}
+
+func TestRequirePermission(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Get("/payments", func(ctx *fiber.Ctx) error {
+		if role := ctx.Get("X-Role"); role != "" {
+			r, _ := entity.ParseRole(role)
+			ctx.SetUserContext(entity.WithIdentity(ctx.UserContext(), &entity.Identity{Type: entity.IdentityTypeBorrower, Role: r, UserID: 1}))
+		}
+		return ctx.Next()
+	}, RequirePermission(entity.PermPaymentRead), func(ctx *fiber.Ctx) error {
+		return ctx.SendStatus(fiber.StatusOK)
+	})
+
+	tests := []struct {
+		name       string
+		role       string
+		statusCode int
+	}{
+		{"Failed RequirePermission - Unauthenticated", "", fiber.StatusUnauthorized},
+		{"Failed RequirePermission - Borrower", "borrower", fiber.StatusForbidden},
+		{"Success RequirePermission - Collector", "collector", fiber.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/payments", nil)
+			req.Header.Set("X-Role", tt.role)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.statusCode, resp.StatusCode)
+		})
+	}
+}
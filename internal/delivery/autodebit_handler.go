@@ -0,0 +1,110 @@
+package delivery
+
+import (
+	"loan-management/internal/entity"
+	"loan-management/internal/usecase"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AutodebitHandler struct {
+	autodebitUsecase *usecase.AutodebitUsecase
+}
+
+func NewAutodebitHandler(autodebitUsecase *usecase.AutodebitUsecase) *AutodebitHandler {
+	return &AutodebitHandler{autodebitUsecase: autodebitUsecase}
+}
+
+func (h *AutodebitHandler) CreateMandate(ctx *fiber.Ctx) error {
+	var payload entity.CreateMandatePayload
+	if err := ctx.BodyParser(&payload); err != nil {
+		return ErrInvalidRequestBody
+	}
+
+	mandate, err := h.autodebitUsecase.CreateMandate(ctx.UserContext(), &payload)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{"data": mandate})
+}
+
+func (h *AutodebitHandler) GetMandates(ctx *fiber.Ctx) error {
+	var filter entity.MandateFilter
+	if err := ctx.QueryParser(&filter); err != nil {
+		return ErrInvalidRequestBody
+	}
+
+	mandates, err := h.autodebitUsecase.GetMandates(ctx.UserContext(), filter)
+	if err != nil {
+		return err
+	}
+
+	if mandates == nil {
+		mandates = []*entity.AutodebitMandate{}
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": mandates})
+}
+
+func (h *AutodebitHandler) GetMandateByID(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrInvalidIDFormat
+	}
+
+	mandate, err := h.autodebitUsecase.GetMandateByID(ctx.UserContext(), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": mandate})
+}
+
+func (h *AutodebitHandler) RevokeMandate(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrInvalidIDFormat
+	}
+
+	mandate, err := h.autodebitUsecase.RevokeMandate(ctx.UserContext(), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": mandate})
+}
+
+func (h *AutodebitHandler) GetAttempts(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrInvalidIDFormat
+	}
+
+	var filter entity.CollectionAttemptFilter
+	if err := ctx.QueryParser(&filter); err != nil {
+		return ErrInvalidRequestBody
+	}
+
+	attempts, err := h.autodebitUsecase.GetAttempts(ctx.UserContext(), id, filter)
+	if err != nil {
+		return err
+	}
+
+	if attempts == nil {
+		attempts = []*entity.CollectionAttempt{}
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": attempts})
+}
+
+// Collect runs the collection now instead of waiting for the scheduler
+func (h *AutodebitHandler) Collect(ctx *fiber.Ctx) error {
+	run, err := h.autodebitUsecase.Collect(ctx.UserContext())
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": run})
+}
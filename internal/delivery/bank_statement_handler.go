@@ -0,0 +1,136 @@
+package delivery
+
+import (
+	"io"
+	"loan-management/internal/apperror"
+	"loan-management/internal/entity"
+	"loan-management/internal/usecase"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var ErrBankStatementFileRequired = apperror.BadRequest("BANK_STATEMENT_FILE_REQUIRED", "The statement file is required")
+
+type BankStatementHandler struct {
+	bankStatementUsecase *usecase.BankStatementUsecase
+}
+
+func NewBankStatementHandler(bankStatementUsecase *usecase.BankStatementUsecase) *BankStatementHandler {
+	return &BankStatementHandler{bankStatementUsecase: bankStatementUsecase}
+}
+
+// ImportStatement takes a multipart upload with the statement in "file", its "format" and optionally the "account"
+func (h *BankStatementHandler) ImportStatement(ctx *fiber.Ctx) error {
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		return ErrBankStatementFileRequired
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return err
+	}
+
+	payload := entity.ImportBankStatementPayload{
+		Format:   entity.BankStatementFormat(ctx.FormValue("format")),
+		Account:  ctx.FormValue("account"),
+		FileName: header.Filename,
+	}
+
+	statement, err := h.bankStatementUsecase.Import(ctx.UserContext(), payload, content)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{"data": statement})
+}
+
+func (h *BankStatementHandler) GetStatements(ctx *fiber.Ctx) error {
+	statements, err := h.bankStatementUsecase.GetStatements(ctx.UserContext())
+	if err != nil {
+		return err
+	}
+
+	if statements == nil {
+		statements = []*entity.BankStatement{}
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": statements})
+}
+
+func (h *BankStatementHandler) GetStatementByID(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrInvalidIDFormat
+	}
+
+	statement, err := h.bankStatementUsecase.GetStatementByID(ctx.UserContext(), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": statement})
+}
+
+func (h *BankStatementHandler) GetLines(ctx *fiber.Ctx) error {
+	var filter entity.BankStatementLineFilter
+	if err := ctx.QueryParser(&filter); err != nil {
+		return ErrInvalidRequestBody
+	}
+
+	lines, err := h.bankStatementUsecase.GetLines(ctx.UserContext(), filter)
+	if err != nil {
+		return err
+	}
+
+	if lines == nil {
+		lines = []*entity.BankStatementLine{}
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": lines})
+}
+
+func (h *BankStatementHandler) MatchLine(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrInvalidIDFormat
+	}
+
+	var payload entity.MatchBankStatementLinePayload
+	if err := ctx.BodyParser(&payload); err != nil {
+		return ErrInvalidRequestBody
+	}
+
+	line, err := h.bankStatementUsecase.MatchLine(ctx.UserContext(), id, payload)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": line})
+}
+
+func (h *BankStatementHandler) RejectLine(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrInvalidIDFormat
+	}
+
+	var payload entity.RejectBankStatementLinePayload
+	if err := ctx.BodyParser(&payload); err != nil {
+		return ErrInvalidRequestBody
+	}
+
+	line, err := h.bankStatementUsecase.RejectLine(ctx.UserContext(), id, payload)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": line})
+}
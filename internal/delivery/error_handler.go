@@ -0,0 +1,84 @@
+package delivery
+
+import (
+	"errors"
+	"loan-management/internal/apperror"
+	"loan-management/internal/i18n"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var (
+	ErrInvalidRequestBody = apperror.BadRequest("INVALID_REQUEST_BODY", "Invalid request body")
+	ErrInvalidIDFormat    = apperror.BadRequest("INVALID_ID_FORMAT", "Invalid ID format")
+	ErrInvalidStatus      = apperror.BadRequest("INVALID_STATUS", "Invalid status")
+	ErrInvalidDateFormat  = apperror.BadRequest("INVALID_DATE_FORMAT", "Invalid date format, expected YYYY-MM-DD")
+)
+
+// ErrorResponse is the body returned for every failed request
+type ErrorResponse struct {
+	Code   string                `json:"code"`
+	Error  string                `json:"error"`
+	Fields []apperror.FieldError `json:"fields,omitempty"`
+}
+
+func statusCode(kind apperror.Kind) int {
+	switch kind {
+	case apperror.KindBadRequest:
+		return fiber.StatusBadRequest
+	case apperror.KindValidation, apperror.KindEligibility:
+		return fiber.StatusUnprocessableEntity
+	case apperror.KindNotFound:
+		return fiber.StatusNotFound
+	case apperror.KindConflict:
+		return fiber.StatusConflict
+	case apperror.KindForbidden:
+		return fiber.StatusForbidden
+	case apperror.KindUnauthorized:
+		return fiber.StatusUnauthorized
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// translate renders the error message and its field errors in the requested language,
+// codes missing from the catalogs keep their original message
+func translate(locale i18n.Locale, appErr *apperror.Error) ErrorResponse {
+	message, ok := i18n.Lookup(locale, appErr.Code)
+	if !ok {
+		message = appErr.Message
+	}
+
+	var fields []apperror.FieldError
+	for _, field := range appErr.Fields {
+		key := field.Key
+		if key == "" {
+			key = "validation." + field.Rule
+		}
+		if _, ok := i18n.Lookup(locale, key); !ok {
+			key = "validation.invalid"
+		}
+		field.Message = i18n.T(locale, key, map[string]string{"field": field.Field, "param": field.Param})
+		fields = append(fields, field)
+	}
+
+	return ErrorResponse{Code: appErr.Code, Error: message, Fields: fields}
+}
+
+// ErrorHandler maps errors returned by handlers into a consistent JSON response
+func ErrorHandler(ctx *fiber.Ctx, err error) error {
+	locale := locale(ctx)
+
+	if appErr, ok := apperror.As(err); ok {
+		return ctx.Status(statusCode(appErr.Kind)).JSON(translate(locale, appErr))
+	}
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return ctx.Status(fiberErr.Code).JSON(ErrorResponse{Code: "HTTP_ERROR", Error: fiberErr.Message})
+	}
+
+	log.Printf("%s %s: %v", ctx.Method(), ctx.Path(), err)
+	return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: "INTERNAL_ERROR", Error: i18n.T(locale, "INTERNAL_ERROR", nil)})
+}
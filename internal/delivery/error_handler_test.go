@@ -0,0 +1,63 @@
+package delivery
+
+import (
+	"encoding/json"
+	"errors"
+	"loan-management/internal/apperror"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		acceptLanguage string
+		expectedCode   int
+		expectedBody   ErrorResponse
+	}{
+		{"Not Found", apperror.NotFound("LOAN_NOT_FOUND", "loan not found"), "", fiber.StatusNotFound, ErrorResponse{Code: "LOAN_NOT_FOUND", Error: "Loan not found"}},
+		{"Not Found Indonesian", apperror.NotFound("LOAN_NOT_FOUND", "loan not found"), "id-ID,id;q=0.9,en;q=0.8", fiber.StatusNotFound, ErrorResponse{Code: "LOAN_NOT_FOUND", Error: "Pinjaman tidak ditemukan"}},
+		{"Eligibility", apperror.Eligibility("USER_DELINQUENT", "delinquent"), "", fiber.StatusUnprocessableEntity, ErrorResponse{Code: "USER_DELINQUENT", Error: "Can't create loan because the user is delinquent"}},
+		{"Conflict", apperror.Conflict("EMAIL_ALREADY_USED", "used"), "", fiber.StatusConflict, ErrorResponse{Code: "EMAIL_ALREADY_USED", Error: "Your email is already being used"}},
+		{"Uncatalogued Code", apperror.Conflict("SOMETHING_NEW", "something new"), "id", fiber.StatusConflict, ErrorResponse{Code: "SOMETHING_NEW", Error: "something new"}},
+		{"Bad Request", ErrInvalidIDFormat, "", fiber.StatusBadRequest, ErrorResponse{Code: "INVALID_ID_FORMAT", Error: "Invalid ID format"}},
+		{
+			"Validation Indonesian",
+			apperror.Validation("VALIDATION_FAILED", "Validation failed").WithFields([]apperror.FieldError{{Field: "amount", Rule: "gt", Param: "0", Message: "amount must be greater than 0"}}),
+			"id",
+			fiber.StatusUnprocessableEntity,
+			ErrorResponse{Code: "VALIDATION_FAILED", Error: "Validasi gagal", Fields: []apperror.FieldError{{Field: "amount", Rule: "gt", Param: "0", Message: "amount harus lebih besar dari 0"}}},
+		},
+		{
+			"Validation Items Indonesian",
+			apperror.Validation("VALIDATION_FAILED", "Validation failed").WithFields([]apperror.FieldError{{Field: "event_types", Rule: "min", Param: "1", Message: "event_types must have at least 1 items", Key: "validation.min.items"}}),
+			"id",
+			fiber.StatusUnprocessableEntity,
+			ErrorResponse{Code: "VALIDATION_FAILED", Error: "Validasi gagal", Fields: []apperror.FieldError{{Field: "event_types", Rule: "min", Param: "1", Message: "event_types minimal berisi 1 item"}}},
+		},
+		{"Fiber Error", fiber.ErrMethodNotAllowed, "", fiber.StatusMethodNotAllowed, ErrorResponse{Code: "HTTP_ERROR", Error: "Method Not Allowed"}},
+		{"Internal", errors.New("db is down"), "", fiber.StatusInternalServerError, ErrorResponse{Code: "INTERNAL_ERROR", Error: "Internal server error"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+			app.Get("/", func(ctx *fiber.Ctx) error { return tt.err })
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set(fiber.HeaderAcceptLanguage, tt.acceptLanguage)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode)
+
+			var body ErrorResponse
+			assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+			assert.Equal(t, tt.expectedBody, body)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package delivery
+
+import (
+	"loan-management/internal/entity"
+	"loan-management/internal/usecase"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type HolidayHandler struct {
+	holidayUsecase *usecase.HolidayUsecase
+}
+
+func NewHolidayHandler(holidayUsecase *usecase.HolidayUsecase) *HolidayHandler {
+	return &HolidayHandler{holidayUsecase: holidayUsecase}
+}
+
+func (h *HolidayHandler) GetHolidays(ctx *fiber.Ctx) error {
+	holidays, err := h.holidayUsecase.GetHolidays(ctx.UserContext())
+	if err != nil {
+		return err
+	}
+
+	if holidays == nil {
+		holidays = []*entity.Holiday{}
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": holidays})
+}
+
+func (h *HolidayHandler) CreateHoliday(ctx *fiber.Ctx) error {
+	var payload entity.HolidayPayload
+	if err := ctx.BodyParser(&payload); err != nil {
+		return ErrInvalidRequestBody
+	}
+
+	holiday, err := h.holidayUsecase.CreateHoliday(ctx.UserContext(), &payload)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{"data": holiday})
+}
+
+func (h *HolidayHandler) DeleteHoliday(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrInvalidIDFormat
+	}
+
+	if err := h.holidayUsecase.DeleteHoliday(ctx.UserContext(), id); err != nil {
+		return err
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
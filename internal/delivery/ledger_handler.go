@@ -0,0 +1,35 @@
+package delivery
+
+import (
+	"loan-management/internal/usecase"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type LedgerHandler struct {
+	ledgerUsecase *usecase.LedgerUsecase
+}
+
+func NewLedgerHandler(ledgerUsecase *usecase.LedgerUsecase) *LedgerHandler {
+	return &LedgerHandler{ledgerUsecase: ledgerUsecase}
+}
+
+// GetTrialBalance accepts an optional `as_of` date (YYYY-MM-DD), inclusive of that whole day
+func (h *LedgerHandler) GetTrialBalance(ctx *fiber.Ctx) error {
+	var asOf time.Time
+	if param := ctx.Query("as_of"); param != "" {
+		date, err := time.ParseInLocation(time.DateOnly, param, usecase.BusinessLocation())
+		if err != nil {
+			return ErrInvalidDateFormat
+		}
+		asOf = date.Add(24*time.Hour - time.Nanosecond)
+	}
+
+	trialBalance, err := h.ledgerUsecase.GetTrialBalance(ctx.UserContext(), asOf)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": trialBalance})
+}
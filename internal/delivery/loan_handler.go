@@ -3,6 +3,7 @@ package delivery
 import (
 	"loan-management/internal/entity"
 	"loan-management/internal/usecase"
+	"loan-management/internal/validation"
 	"strconv"
 	"time"
 
@@ -20,7 +21,11 @@ func NewLoanHandler(loanUsecase *usecase.LoanUsecase) *LoanHandler {
 func (h *LoanHandler) CreateLoan(ctx *fiber.Ctx) error {
 	var payload entity.CreateLoanPayload
 	if err := ctx.BodyParser(&payload); err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		return ErrInvalidRequestBody
+	}
+
+	if err := validation.Struct(payload); err != nil {
+		return err
 	}
 
 	loan := entity.Loan{
@@ -30,47 +35,113 @@ func (h *LoanHandler) CreateLoan(ctx *fiber.Ctx) error {
 		InterestType:     payload.InterestType,
 		Tenure:           payload.Tenure,
 		TenureType:       payload.TenureType,
-		Status:           entity.LoanStatusActive,
+		Status:           entity.LoanStatusPending,
 		CreatedAt:        time.Now(),
 		BillingStartDate: payload.BillingStartDate,
+
+		GracePeriods:        payload.GracePeriods,
+		GraceInterest:       payload.GraceInterest,
+		InterestOnlyPeriods: payload.InterestOnlyPeriods,
+		RepaymentStructure:  payload.RepaymentStructure,
+		BalloonPercent:      payload.BalloonPercent,
 	}
 
-	if err := h.loanUsecase.CreateLoanWithPayments(ctx.Context(), &loan); err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	if err := h.loanUsecase.CreateLoanWithPayments(ctx.UserContext(), &loan); err != nil {
+		return err
 	}
 
-	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{"data": loan})
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{"data": newLoanResponse(locale(ctx), &loan)})
 }
 
 func (h *LoanHandler) GetAllLoans(ctx *fiber.Ctx) error {
-	loans, err := h.loanUsecase.GetAllLoans(ctx.Context())
+	loans, err := h.loanUsecase.GetAllLoans(ctx.UserContext())
 	if err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
 
 	if loans == nil {
 		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{"data": []entity.Loan{}})
 	}
 
-	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": loans})
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": newLoanResponses(locale(ctx), loans)})
 }
 
 func (h *LoanHandler) GetLoanByID(ctx *fiber.Ctx) error {
 	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
 
 	if err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID format"})
+		return ErrInvalidIDFormat
 	}
 
-	loan, err := h.loanUsecase.GetLoanByID(ctx.Context(), id, nil)
+	loan, err := h.loanUsecase.GetLoanByID(ctx.UserContext(), id, nil)
+	if err != nil {
+		return err
+	}
 
-	if loan == nil {
-		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Loan not found"})
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": newLoanResponse(locale(ctx), loan)})
+}
+
+func (h *LoanHandler) ApproveLoan(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrInvalidIDFormat
 	}
 
+	loan, err := h.loanUsecase.ApproveLoan(ctx.UserContext(), id)
 	if err != nil {
-		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": newLoanResponse(locale(ctx), loan)})
+}
+
+func (h *LoanHandler) RejectLoan(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrInvalidIDFormat
+	}
+
+	loan, err := h.loanUsecase.RejectLoan(ctx.UserContext(), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": newLoanResponse(locale(ctx), loan)})
+}
+
+func (h *LoanHandler) RestructureLoan(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrInvalidIDFormat
+	}
+
+	var payload entity.RestructureLoanPayload
+	if err := ctx.BodyParser(&payload); err != nil {
+		return ErrInvalidRequestBody
+	}
+
+	restructure, err := h.loanUsecase.RestructureLoan(ctx.UserContext(), id, &payload)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{"data": restructure})
+}
+
+func (h *LoanHandler) GetRestructures(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrInvalidIDFormat
+	}
+
+	restructures, err := h.loanUsecase.GetRestructures(ctx.UserContext(), id)
+	if err != nil {
+		return err
+	}
+
+	if restructures == nil {
+		restructures = []*entity.LoanRestructure{}
 	}
 
-	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": loan})
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": restructures})
 }
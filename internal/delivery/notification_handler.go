@@ -0,0 +1,78 @@
+package delivery
+
+import (
+	"loan-management/internal/entity"
+	"loan-management/internal/usecase"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type NotificationHandler struct {
+	notificationUsecase *usecase.NotificationUsecase
+}
+
+func NewNotificationHandler(notificationUsecase *usecase.NotificationUsecase) *NotificationHandler {
+	return &NotificationHandler{notificationUsecase: notificationUsecase}
+}
+
+func (h *NotificationHandler) GetPreferences(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrInvalidIDFormat
+	}
+
+	preferences, err := h.notificationUsecase.GetPreferences(ctx.UserContext(), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": preferences})
+}
+
+func (h *NotificationHandler) UpdatePreferences(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrInvalidIDFormat
+	}
+
+	var payload entity.UpdateNotificationPreferencesPayload
+	if err := ctx.BodyParser(&payload); err != nil {
+		return ErrInvalidRequestBody
+	}
+
+	preferences, err := h.notificationUsecase.UpdatePreferences(ctx.UserContext(), id, &payload)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": preferences})
+}
+
+func (h *NotificationHandler) GetNotifications(ctx *fiber.Ctx) error {
+	var filter entity.NotificationFilter
+	if err := ctx.QueryParser(&filter); err != nil {
+		return ErrInvalidRequestBody
+	}
+
+	notifications, err := h.notificationUsecase.GetNotifications(ctx.UserContext(), filter)
+	if err != nil {
+		return err
+	}
+
+	if notifications == nil {
+		notifications = []*entity.Notification{}
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": notifications})
+}
+
+// Remind sends the due reminders now instead of waiting for the scheduler
+func (h *NotificationHandler) Remind(ctx *fiber.Ctx) error {
+	run, err := h.notificationUsecase.Remind(ctx.UserContext())
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": run})
+}
@@ -0,0 +1,60 @@
+package delivery
+
+import (
+	"loan-management/internal/entity"
+	"loan-management/internal/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type PaymentCallbackHandler struct {
+	paymentCallbackUsecase *usecase.PaymentCallbackUsecase
+}
+
+func NewPaymentCallbackHandler(paymentCallbackUsecase *usecase.PaymentCallbackUsecase) *PaymentCallbackHandler {
+	return &PaymentCallbackHandler{paymentCallbackUsecase: paymentCallbackUsecase}
+}
+
+// HandleCallback is called by the payment providers, it is authenticated by the provider signature instead of a caller identity
+func (h *PaymentCallbackHandler) HandleCallback(ctx *fiber.Ctx) error {
+	callback, err := h.paymentCallbackUsecase.HandleCallback(ctx.UserContext(), ctx.Params("provider"), http.Header(ctx.GetReqHeaders()), ctx.Body())
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": callback})
+}
+
+func (h *PaymentCallbackHandler) GetPaymentCallbacks(ctx *fiber.Ctx) error {
+	var filter entity.PaymentCallbackFilter
+	if err := ctx.QueryParser(&filter); err != nil {
+		return ErrInvalidRequestBody
+	}
+
+	callbacks, err := h.paymentCallbackUsecase.GetPaymentCallbacks(ctx.UserContext(), filter)
+	if err != nil {
+		return err
+	}
+
+	if callbacks == nil {
+		callbacks = []*entity.PaymentCallback{}
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": callbacks})
+}
+
+func (h *PaymentCallbackHandler) GetPaymentCallbackByID(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrInvalidIDFormat
+	}
+
+	callback, err := h.paymentCallbackUsecase.GetPaymentCallbackByID(ctx.UserContext(), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": callback})
+}
@@ -22,24 +22,24 @@ func (h *PaymentHandler) GetAllPayments(ctx *fiber.Ctx) error {
 	if ctx.Params("status") != "" {
 		_status, err := strconv.ParseInt(ctx.Params("status"), 10, 8)
 		if err != nil {
-			return err
+			return ErrInvalidStatus
 		}
 
 		temp := entity.PaymentStatus(_status)
 		status = &temp
 	}
 
-	payments, err := h.paymentUsecase.GetAllPayments(ctx.Context(), status)
+	payments, err := h.paymentUsecase.GetAllPayments(ctx.UserContext(), status)
 
 	if err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
 
 	if payments == nil {
 		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{"data": []entity.Payment{}})
 	}
 
-	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": payments})
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": newPaymentResponses(locale(ctx), payments)})
 }
 
 // func (h *PaymentHandler) GetPaymentsByLoanID(ctx *fiber.Ctx) error {
@@ -55,9 +55,9 @@ func (h *PaymentHandler) GetAllPayments(ctx *fiber.Ctx) error {
 // 	}
 // 	status := entity.PaymentStatus(_status)
 
-// 	payments, err := h.paymentUsecase.GetPaymentsByLoanID(ctx.Context(), loanId, &status)
+// 	payments, err := h.paymentUsecase.GetPaymentsByLoanID(ctx.UserContext(), loanId, &status)
 // 	if err != nil {
-// 		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+// 		return err
 // 	}
 
 // 	if payments == nil {
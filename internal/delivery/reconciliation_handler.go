@@ -0,0 +1,32 @@
+package delivery
+
+import (
+	"loan-management/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ReconciliationHandler struct {
+	reconciliationUsecase *usecase.ReconciliationUsecase
+}
+
+func NewReconciliationHandler(reconciliationUsecase *usecase.ReconciliationUsecase) *ReconciliationHandler {
+	return &ReconciliationHandler{reconciliationUsecase: reconciliationUsecase}
+}
+
+func (h *ReconciliationHandler) Verify(ctx *fiber.Ctx) error {
+	return h.verify(ctx, false)
+}
+
+func (h *ReconciliationHandler) Repair(ctx *fiber.Ctx) error {
+	return h.verify(ctx, true)
+}
+
+func (h *ReconciliationHandler) verify(ctx *fiber.Ctx, repair bool) error {
+	report, err := h.reconciliationUsecase.Verify(ctx.UserContext(), repair)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": report})
+}
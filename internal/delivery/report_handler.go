@@ -0,0 +1,133 @@
+package delivery
+
+import (
+	"bytes"
+	"loan-management/internal/apperror"
+	"loan-management/internal/report"
+	"loan-management/internal/usecase"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var ErrInvalidReportFormat = apperror.BadRequest("INVALID_REPORT_FORMAT", "The report format must be json or csv")
+
+// ReportHandler serves the portfolio reports. Dates are YYYY-MM-DD and inclusive, `as_of` is the end of that day,
+// and `format=csv` downloads the report instead of returning json
+type ReportHandler struct {
+	reportUsecase *usecase.ReportUsecase
+}
+
+func NewReportHandler(reportUsecase *usecase.ReportUsecase) *ReportHandler {
+	return &ReportHandler{reportUsecase: reportUsecase}
+}
+
+func (h *ReportHandler) GetPortfolio(ctx *fiber.Ctx) error {
+	asOf, err := reportDate(ctx, "as_of")
+	if err != nil {
+		return err
+	}
+
+	portfolio, err := h.reportUsecase.GetPortfolio(ctx.UserContext(), asOf)
+	if err != nil {
+		return err
+	}
+
+	return sendReport(ctx, portfolio, report.Filename("portfolio", portfolio.AsOf), func(body *bytes.Buffer) error {
+		return report.WritePortfolioCSV(body, portfolio)
+	})
+}
+
+func (h *ReportHandler) GetAging(ctx *fiber.Ctx) error {
+	asOf, err := reportDate(ctx, "as_of")
+	if err != nil {
+		return err
+	}
+
+	aging, err := h.reportUsecase.GetAging(ctx.UserContext(), asOf)
+	if err != nil {
+		return err
+	}
+
+	return sendReport(ctx, aging, report.Filename("aging", aging.AsOf), func(body *bytes.Buffer) error {
+		return report.WriteAgingCSV(body, aging)
+	})
+}
+
+func (h *ReportHandler) GetCollections(ctx *fiber.Ctx) error {
+	from, err := reportDate(ctx, "from")
+	if err != nil {
+		return err
+	}
+	to, err := reportDate(ctx, "to")
+	if err != nil {
+		return err
+	}
+
+	collections, err := h.reportUsecase.GetCollections(ctx.UserContext(), from, to)
+	if err != nil {
+		return err
+	}
+
+	return sendReport(ctx, collections, report.Filename("collections", collections.To), func(body *bytes.Buffer) error {
+		return report.WriteCollectionsCSV(body, collections)
+	})
+}
+
+func (h *ReportHandler) GetVintages(ctx *fiber.Ctx) error {
+	from, err := reportDate(ctx, "from")
+	if err != nil {
+		return err
+	}
+	to, err := reportDate(ctx, "to")
+	if err != nil {
+		return err
+	}
+	asOf, err := reportDate(ctx, "as_of")
+	if err != nil {
+		return err
+	}
+
+	vintages, err := h.reportUsecase.GetVintages(ctx.UserContext(), from, to, asOf)
+	if err != nil {
+		return err
+	}
+
+	return sendReport(ctx, vintages, report.Filename("vintages", vintages.AsOf), func(body *bytes.Buffer) error {
+		return report.WriteVintagesCSV(body, vintages)
+	})
+}
+
+// reportDate parses an optional date query param, `from` is the start of the day and the others its end
+func reportDate(ctx *fiber.Ctx, key string) (time.Time, error) {
+	param := ctx.Query(key)
+	if param == "" {
+		return time.Time{}, nil
+	}
+
+	date, err := time.ParseInLocation(time.DateOnly, param, usecase.BusinessLocation())
+	if err != nil {
+		return time.Time{}, ErrInvalidDateFormat
+	}
+	if key != "from" {
+		date = date.Add(24*time.Hour - time.Nanosecond)
+	}
+	return date, nil
+}
+
+func sendReport(ctx *fiber.Ctx, data any, filename string, writeCSV func(body *bytes.Buffer) error) error {
+	switch ctx.Query("format", "json") {
+	case "json":
+		return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": data})
+	case "csv":
+		var body bytes.Buffer
+		if err := writeCSV(&body); err != nil {
+			return err
+		}
+		ctx.Attachment(filename)
+		ctx.Set(fiber.HeaderContentType, report.ContentType)
+		return ctx.Status(fiber.StatusOK).Send(body.Bytes())
+	default:
+		return ErrInvalidReportFormat
+	}
+}
@@ -0,0 +1,21 @@
+package delivery
+
+import (
+	"loan-management/internal/entity"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// RequestID reuses the caller's X-Request-ID or generates one, and exposes it to the usecases for the audit log
+func RequestID(ctx *fiber.Ctx) error {
+	requestID := ctx.Get(fiber.HeaderXRequestID)
+	if requestID == "" {
+		requestID = utils.UUIDv4()
+	}
+
+	ctx.Set(fiber.HeaderXRequestID, requestID)
+	ctx.SetUserContext(entity.WithRequestID(ctx.UserContext(), requestID))
+
+	return ctx.Next()
+}
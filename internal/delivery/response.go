@@ -0,0 +1,121 @@
+package delivery
+
+import (
+	"loan-management/internal/entity"
+	"loan-management/internal/i18n"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// locale resolves the response language from the Accept-Language header
+func locale(ctx *fiber.Ctx) i18n.Locale {
+	locale := i18n.ParseAcceptLanguage(ctx.Get(fiber.HeaderAcceptLanguage))
+	ctx.Set(fiber.HeaderContentLanguage, string(locale))
+	return locale
+}
+
+// LoanResponse is a loan with its enums rendered as labels in the requested language
+type LoanResponse struct {
+	*entity.Loan
+	Reference         string `json:"Reference"`
+	StatusLabel       string `json:"StatusLabel"`
+	InterestTypeLabel string `json:"InterestTypeLabel"`
+	TenureTypeLabel   string `json:"TenureTypeLabel"`
+
+	RepaymentStructureLabel string `json:"RepaymentStructureLabel"`
+}
+
+type PaymentResponse struct {
+	*entity.Payment
+	StatusLabel string `json:"StatusLabel"`
+}
+
+type TransactionResponse struct {
+	*entity.Transaction
+	StatusLabel string `json:"StatusLabel"`
+}
+
+type TransactionInquiryResponse struct {
+	*entity.TransactionInquiry
+	LoanDetail *LoanResponse      `json:"loan_detail"`
+	Bills      []*PaymentResponse `json:"payments"`
+}
+
+// UserInquiryResponse renders the inquiry of every loan of a user like a single loan inquiry
+type UserInquiryResponse struct {
+	*entity.UserInquiry
+	Loans []*TransactionInquiryResponse `json:"loans"`
+}
+
+func newLoanResponse(locale i18n.Locale, loan *entity.Loan) *LoanResponse {
+	if loan == nil {
+		return nil
+	}
+	return &LoanResponse{
+		Loan:              loan,
+		Reference:         loan.Reference(),
+		StatusLabel:       i18n.T(locale, loan.Status.Key(), nil),
+		InterestTypeLabel: i18n.T(locale, loan.InterestType.Key(), nil),
+		TenureTypeLabel:   i18n.T(locale, loan.TenureType.Key(), nil),
+
+		RepaymentStructureLabel: i18n.T(locale, loan.RepaymentStructure.Key(), nil),
+	}
+}
+
+func newLoanResponses(locale i18n.Locale, loans []*entity.Loan) []*LoanResponse {
+	responses := make([]*LoanResponse, len(loans))
+	for i, loan := range loans {
+		responses[i] = newLoanResponse(locale, loan)
+	}
+	return responses
+}
+
+func newPaymentResponse(locale i18n.Locale, payment *entity.Payment) *PaymentResponse {
+	if payment == nil {
+		return nil
+	}
+	return &PaymentResponse{
+		Payment:     payment,
+		StatusLabel: i18n.T(locale, payment.Status.Key(), nil),
+	}
+}
+
+func newPaymentResponses(locale i18n.Locale, payments []*entity.Payment) []*PaymentResponse {
+	responses := make([]*PaymentResponse, len(payments))
+	for i, payment := range payments {
+		responses[i] = newPaymentResponse(locale, payment)
+	}
+	return responses
+}
+
+func newTransactionResponse(locale i18n.Locale, trx *entity.Transaction) *TransactionResponse {
+	if trx == nil {
+		return nil
+	}
+	return &TransactionResponse{
+		Transaction: trx,
+		StatusLabel: i18n.T(locale, trx.Status.Key(), nil),
+	}
+}
+
+func newTransactionInquiryResponse(locale i18n.Locale, inquiry *entity.TransactionInquiry) *TransactionInquiryResponse {
+	if inquiry == nil {
+		return nil
+	}
+	return &TransactionInquiryResponse{
+		TransactionInquiry: inquiry,
+		LoanDetail:         newLoanResponse(locale, inquiry.LoanDetail),
+		Bills:              newPaymentResponses(locale, inquiry.Bills),
+	}
+}
+
+func newUserInquiryResponse(locale i18n.Locale, inquiry *entity.UserInquiry) *UserInquiryResponse {
+	if inquiry == nil {
+		return nil
+	}
+	loans := make([]*TransactionInquiryResponse, len(inquiry.Loans))
+	for i, loan := range inquiry.Loans {
+		loans[i] = newTransactionInquiryResponse(locale, loan)
+	}
+	return &UserInquiryResponse{UserInquiry: inquiry, Loans: loans}
+}
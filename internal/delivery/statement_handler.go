@@ -0,0 +1,63 @@
+package delivery
+
+import (
+	"bytes"
+	"loan-management/internal/apperror"
+	"loan-management/internal/entity"
+	"loan-management/internal/statement"
+	"loan-management/internal/usecase"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var ErrInvalidStatementFormat = apperror.BadRequest("INVALID_STATEMENT_FORMAT", "The statement format must be pdf or csv")
+
+type StatementHandler struct {
+	statementUsecase *usecase.StatementUsecase
+}
+
+func NewStatementHandler(statementUsecase *usecase.StatementUsecase) *StatementHandler {
+	return &StatementHandler{statementUsecase: statementUsecase}
+}
+
+// GetStatement accepts optional `from` and `to` dates (YYYY-MM-DD), both inclusive, and a `format` of pdf (default) or csv
+func (h *StatementHandler) GetStatement(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrInvalidIDFormat
+	}
+
+	format := entity.StatementFormat(ctx.Query("format", string(entity.StatementFormatPDF)))
+	if statement.ContentType(format) == "" {
+		return ErrInvalidStatementFormat
+	}
+
+	var from, to time.Time
+	if param := ctx.Query("from"); param != "" {
+		if from, err = time.ParseInLocation(time.DateOnly, param, usecase.BusinessLocation()); err != nil {
+			return ErrInvalidDateFormat
+		}
+	}
+	if param := ctx.Query("to"); param != "" {
+		if to, err = time.ParseInLocation(time.DateOnly, param, usecase.BusinessLocation()); err != nil {
+			return ErrInvalidDateFormat
+		}
+		to = to.Add(24*time.Hour - time.Nanosecond)
+	}
+
+	loanStatement, err := h.statementUsecase.GetStatement(ctx.UserContext(), id, from, to)
+	if err != nil {
+		return err
+	}
+
+	var body bytes.Buffer
+	if err := statement.Write(&body, format, locale(ctx), loanStatement); err != nil {
+		return err
+	}
+
+	ctx.Attachment(statement.Filename(loanStatement, format))
+	ctx.Set(fiber.HeaderContentType, statement.ContentType(format))
+	return ctx.Status(fiber.StatusOK).Send(body.Bytes())
+}
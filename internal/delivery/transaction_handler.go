@@ -3,6 +3,7 @@ package delivery
 import (
 	"loan-management/internal/entity"
 	"loan-management/internal/usecase"
+	"loan-management/internal/validation"
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
@@ -16,49 +17,111 @@ func NewTransactionHandler(transactionUsecase *usecase.TransactionUsecase) *Tran
 	return &TransactionHandler{transactionUsecase: transactionUsecase}
 }
 
+// InquiryTransaction looks the loan up by either loan_id or virtual_account. Either `installments` or `amount` adds
+// future installments paid ahead of schedule
 func (h *TransactionHandler) InquiryTransaction(ctx *fiber.Ctx) error {
-	loanID, err := strconv.ParseInt(ctx.Query("loan_id"), 10, 64)
+	var (
+		inquiryResult *entity.TransactionInquiry
+		ahead         entity.PayAhead
+		err           error
+	)
+
+	if err := ctx.QueryParser(&ahead); err != nil {
+		return ErrInvalidRequestBody
+	}
 
-	if err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID format"})
+	virtualAccount := ctx.Query("virtual_account")
+	if (ctx.Query("loan_id") == "") == (virtualAccount == "") {
+		return usecase.ErrLoanReferenceRequired
 	}
 
-	inquiryResult, err := h.transactionUsecase.InquiryTransaction(ctx.Context(), loanID)
+	if virtualAccount != "" {
+		inquiryResult, err = h.transactionUsecase.InquiryTransactionByVirtualAccount(ctx.UserContext(), virtualAccount, ahead)
+	} else {
+		var loanID int64
+		loanID, err = strconv.ParseInt(ctx.Query("loan_id"), 10, 64)
+		if err != nil {
+			return ErrInvalidIDFormat
+		}
 
-	if inquiryResult == nil {
-		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Tagihan tidak ditemukan"})
+		inquiryResult, err = h.transactionUsecase.InquiryTransaction(ctx.UserContext(), loanID, ahead)
 	}
-
 	if err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
 
-	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": inquiryResult})
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": newTransactionInquiryResponse(locale(ctx), inquiryResult)})
 }
 
 func (h *TransactionHandler) CreateTransaction(ctx *fiber.Ctx) error {
 	var payload entity.CreateTransactionPayload
 	if err := ctx.BodyParser(&payload); err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return ErrInvalidRequestBody
+	}
+
+	if err := validation.Struct(payload); err != nil {
+		return err
 	}
 
 	createTransactionPayload := &entity.CreateTransactionPayload{
-		LoanID: payload.LoanID,
-		Amount: payload.Amount,
+		LoanID:         payload.LoanID,
+		VirtualAccount: payload.VirtualAccount,
+		Amount:         payload.Amount,
+		Installments:   payload.Installments,
 	}
 
-	trx, err := h.transactionUsecase.CreateTransaction(ctx.Context(), createTransactionPayload)
+	trx, err := h.transactionUsecase.CreateTransaction(ctx.UserContext(), createTransactionPayload)
+	if err != nil {
+		return err
+	}
 
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": newTransactionResponse(locale(ctx), trx)})
+
+}
+
+// InquiryUser gathers the bills due of all the active loans of the user_id, to be paid with CreateUserTransaction
+func (h *TransactionHandler) InquiryUser(ctx *fiber.Ctx) error {
+	userID, err := strconv.ParseInt(ctx.Query("user_id"), 10, 64)
 	if err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return ErrInvalidIDFormat
 	}
 
-	if trx == nil {
-		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Tagihan tidak ditemukan"})
+	inquiry, err := h.transactionUsecase.InquiryUser(ctx.UserContext(), userID)
+	if err != nil {
+		return err
 	}
 
-	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": trx})
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": newUserInquiryResponse(locale(ctx), inquiry)})
+}
+
+func (h *TransactionHandler) CreateUserTransaction(ctx *fiber.Ctx) error {
+	var payload entity.CreateUserTransactionPayload
+	if err := ctx.BodyParser(&payload); err != nil {
+		return ErrInvalidRequestBody
+	}
+
+	trx, err := h.transactionUsecase.CreateUserTransaction(ctx.UserContext(), &entity.CreateUserTransactionPayload{
+		UserID: payload.UserID,
+		Amount: payload.Amount,
+	})
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": newTransactionResponse(locale(ctx), trx)})
+}
+
+func (h *TransactionHandler) ReverseTransaction(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+
+	if err != nil {
+		return ErrInvalidIDFormat
+	}
+
+	trx, err := h.transactionUsecase.ReverseTransaction(ctx.UserContext(), id)
+	if err != nil {
+		return err
+	}
 
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": newTransactionResponse(locale(ctx), trx)})
 }
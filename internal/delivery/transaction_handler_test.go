@@ -0,0 +1,34 @@
+package delivery
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInquiryTransaction(t *testing.T) {
+	handler := NewTransactionHandler(nil)
+
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Get("/inquiry", handler.InquiryTransaction)
+
+	for name, query := range map[string]string{
+		"Failed InquiryTransaction - Loan ID And Virtual Account": "?loan_id=1&virtual_account=880800000000016",
+		"Failed InquiryTransaction - No Loan Reference":           "",
+	} {
+		t.Run(name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest("GET", "/inquiry"+query, nil))
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusUnprocessableEntity, resp.StatusCode)
+
+			var body struct {
+				Code string `json:"code"`
+			}
+			assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+			assert.Equal(t, "LOAN_REFERENCE_REQUIRED", body.Code)
+		})
+	}
+}
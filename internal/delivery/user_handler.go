@@ -3,6 +3,7 @@ package delivery
 import (
 	"loan-management/internal/entity"
 	"loan-management/internal/usecase"
+	"loan-management/internal/validation"
 	"strconv"
 	"time"
 
@@ -20,28 +21,31 @@ func NewUserHandler(userUsecase *usecase.UserUsecase) *UserHandler {
 func (h *UserHandler) RegisterUser(ctx *fiber.Ctx) error {
 	var payload entity.CreateUserPayload
 	if err := ctx.BodyParser(&payload); err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return ErrInvalidRequestBody
+	}
+
+	if err := validation.Struct(payload); err != nil {
+		return err
 	}
 
 	user := &entity.User{
 		Email:     payload.Email,
 		Name:      payload.Name,
+		Password:  payload.Password,
 		CreatedAt: time.Now(),
 	}
 
-	if err := h.userUsecase.RegisterUser(ctx.Context(), user); err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	if err := h.userUsecase.RegisterUser(ctx.UserContext(), user); err != nil {
+		return err
 	}
 
 	return ctx.Status(fiber.StatusCreated).JSON(user)
 }
 
 func (h *UserHandler) GetAllUsers(ctx *fiber.Ctx) error {
-	users, err := h.userUsecase.GetAllUsers(ctx.Context())
+	users, err := h.userUsecase.GetAllUsers(ctx.UserContext())
 	if err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
 
 	if users == nil {
@@ -55,12 +59,12 @@ func (h *UserHandler) GetUserByID(ctx *fiber.Ctx) error {
 	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
 
 	if err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID format"})
+		return ErrInvalidIDFormat
 	}
 
-	user, err := h.userUsecase.GetUserByID(ctx.Context(), int64(id))
+	user, err := h.userUsecase.GetUserByID(ctx.UserContext(), int64(id))
 	if err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
 	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": user})
 }
@@ -69,14 +73,38 @@ func (h *UserHandler) CheckUserDelinquentStatus(ctx *fiber.Ctx) error {
 	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
 
 	if err != nil {
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID format"})
+		return ErrInvalidIDFormat
 	}
 
-	isUserDelinquent, err := h.userUsecase.IsUserDelinquent(ctx.Context(), id)
+	isUserDelinquent, err := h.userUsecase.IsUserDelinquent(ctx.UserContext(), id)
 	if err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": isUserDelinquent})
 
 }
+
+func (h *UserHandler) UpdateUserRole(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+
+	if err != nil {
+		return ErrInvalidIDFormat
+	}
+
+	var payload entity.UpdateUserRolePayload
+	if err := ctx.BodyParser(&payload); err != nil {
+		return ErrInvalidRequestBody
+	}
+
+	if err := validation.Struct(payload); err != nil {
+		return err
+	}
+
+	user, err := h.userUsecase.UpdateUserRole(ctx.UserContext(), id, payload.Role)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": user})
+}
@@ -0,0 +1,127 @@
+package delivery
+
+import (
+	"loan-management/internal/entity"
+	"loan-management/internal/usecase"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type WebhookHandler struct {
+	webhookUsecase *usecase.WebhookUsecase
+}
+
+func NewWebhookHandler(webhookUsecase *usecase.WebhookUsecase) *WebhookHandler {
+	return &WebhookHandler{webhookUsecase: webhookUsecase}
+}
+
+func (h *WebhookHandler) CreateSubscription(ctx *fiber.Ctx) error {
+	var payload entity.WebhookSubscriptionPayload
+	if err := ctx.BodyParser(&payload); err != nil {
+		return ErrInvalidRequestBody
+	}
+
+	created, err := h.webhookUsecase.CreateSubscription(ctx.UserContext(), &payload)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{"data": created})
+}
+
+func (h *WebhookHandler) GetAllSubscriptions(ctx *fiber.Ctx) error {
+	subscriptions, err := h.webhookUsecase.GetAllSubscriptions(ctx.UserContext())
+	if err != nil {
+		return err
+	}
+
+	if subscriptions == nil {
+		subscriptions = []*entity.WebhookSubscription{}
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": subscriptions})
+}
+
+func (h *WebhookHandler) GetSubscriptionByID(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrInvalidIDFormat
+	}
+
+	subscription, err := h.webhookUsecase.GetSubscriptionByID(ctx.UserContext(), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": subscription})
+}
+
+func (h *WebhookHandler) UpdateSubscription(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrInvalidIDFormat
+	}
+
+	var payload entity.UpdateWebhookSubscriptionPayload
+	if err := ctx.BodyParser(&payload); err != nil {
+		return ErrInvalidRequestBody
+	}
+
+	subscription, err := h.webhookUsecase.UpdateSubscription(ctx.UserContext(), id, &payload)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": subscription})
+}
+
+func (h *WebhookHandler) DisableSubscription(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrInvalidIDFormat
+	}
+
+	if err := h.webhookUsecase.DisableSubscription(ctx.UserContext(), id); err != nil {
+		return err
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *WebhookHandler) GetDeliveries(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrInvalidIDFormat
+	}
+
+	var filter entity.WebhookDeliveryFilter
+	if err := ctx.QueryParser(&filter); err != nil {
+		return ErrInvalidRequestBody
+	}
+
+	deliveries, err := h.webhookUsecase.GetDeliveries(ctx.UserContext(), id, filter)
+	if err != nil {
+		return err
+	}
+
+	if deliveries == nil {
+		deliveries = []*entity.WebhookDelivery{}
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": deliveries})
+}
+
+func (h *WebhookHandler) Redeliver(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrInvalidIDFormat
+	}
+
+	delivery, err := h.webhookUsecase.Redeliver(ctx.UserContext(), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"data": delivery})
+}
@@ -0,0 +1,82 @@
+package entity
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+type AuditAction string
+
+const (
+	AuditActionUserRegister        AuditAction = "user.register"
+	AuditActionUserUpdateRole      AuditAction = "user.update_role"
+	AuditActionLoanCreate          AuditAction = "loan.create"
+	AuditActionLoanApprove         AuditAction = "loan.approve"
+	AuditActionLoanReject          AuditAction = "loan.reject"
+	AuditActionLoanPay             AuditAction = "loan.pay"
+	AuditActionLoanReconcile       AuditAction = "loan.reconcile"
+	AuditActionLoanDelinquent      AuditAction = "loan.delinquent"
+	AuditActionLoanRestructure     AuditAction = "loan.restructure"
+	AuditActionTransactionCreate   AuditAction = "transaction.create"
+	AuditActionTransactionReverse  AuditAction = "transaction.reverse"
+	AuditActionWebhookCreate       AuditAction = "webhook.create"
+	AuditActionWebhookUpdate       AuditAction = "webhook.update"
+	AuditActionWebhookDisable      AuditAction = "webhook.disable"
+	AuditActionBankStatementImport AuditAction = "bank_statement.import"
+	AuditActionBankStatementMatch  AuditAction = "bank_statement_line.match"
+	AuditActionBankStatementReject AuditAction = "bank_statement_line.reject"
+	AuditActionMandateCreate       AuditAction = "autodebit_mandate.create"
+	AuditActionMandateRevoke       AuditAction = "autodebit_mandate.revoke"
+	AuditActionPreferencesUpdate   AuditAction = "notification_preferences.update"
+	AuditActionHolidayCreate       AuditAction = "holiday.create"
+	AuditActionHolidayDelete       AuditAction = "holiday.delete"
+)
+
+const (
+	AuditEntityUser                    = "user"
+	AuditEntityLoan                    = "loan"
+	AuditEntityTransaction             = "transaction"
+	AuditEntityWebhook                 = "webhook"
+	AuditEntityBankStatement           = "bank_statement"
+	AuditEntityBankStatementLine       = "bank_statement_line"
+	AuditEntityAutodebitMandate        = "autodebit_mandate"
+	AuditEntityNotificationPreferences = "notification_preferences"
+	AuditEntityHoliday                 = "holiday"
+)
+
+// AuditLog is an append-only record of a state change, Changes maps each changed field to its before/after value
+type AuditLog struct {
+	ID         int64           `db:"id" json:"id"`
+	ActorType  IdentityType    `db:"actor_type" json:"actor_type,omitempty"`
+	ActorID    int64           `db:"actor_id" json:"actor_id,omitempty"`
+	ActorName  string          `db:"actor_name" json:"actor_name"`
+	Action     AuditAction     `db:"action" json:"action"`
+	EntityType string          `db:"entity_type" json:"entity_type"`
+	EntityID   int64           `db:"entity_id" json:"entity_id"`
+	Changes    json.RawMessage `db:"changes" json:"changes"`
+	RequestID  string          `db:"request_id" json:"request_id,omitempty"`
+	CreatedAt  time.Time       `db:"created_at" json:"created_at"`
+}
+
+type AuditChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+type AuditLogFilter struct {
+	EntityType string `json:"entity_type" query:"entity_type" validate:"required"`
+	EntityID   int64  `json:"entity_id" query:"entity_id"`
+	Limit      int    `json:"limit" query:"limit" validate:"omitempty,gte=1,lte=500"`
+}
+
+type requestIDContextKey struct{}
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, requestID)
+}
+
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDContextKey{}).(string)
+	return requestID
+}
@@ -0,0 +1,97 @@
+package entity
+
+import (
+	"context"
+	"time"
+)
+
+type IdentityType int8
+
+const (
+	IdentityTypeBorrower IdentityType = iota + 1
+	IdentityTypePartner
+)
+
+func (it IdentityType) String() string {
+	switch it {
+	case IdentityTypeBorrower:
+		return "Borrower"
+	case IdentityTypePartner:
+		return "Partner"
+	default:
+		return "Unknown"
+	}
+}
+
+// Identity is the authenticated caller of a request
+type Identity struct {
+	Type     IdentityType `json:"type"`
+	Role     Role         `json:"role"`
+	UserID   int64        `json:"user_id,omitempty"`
+	APIKeyID int64        `json:"api_key_id,omitempty"`
+	Name     string       `json:"name"`
+}
+
+func (i *Identity) Can(permission Permission) bool {
+	return i.Role.Can(permission)
+}
+
+// IsOwner reports whether the record owned by userID belongs to this caller
+func (i *Identity) IsOwner(userID int64) bool {
+	return i.Type == IdentityTypeBorrower && i.UserID != 0 && i.UserID == userID
+}
+
+type identityContextKey struct{}
+
+func WithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityContextKey{}, identity)
+}
+
+// IdentityFromContext returns nil for unauthenticated callers (e.g. CLI and background jobs)
+func IdentityFromContext(ctx context.Context) *Identity {
+	identity, _ := ctx.Value(identityContextKey{}).(*Identity)
+	return identity
+}
+
+type APIKeyStatus int8
+
+const (
+	APIKeyStatusActive  APIKeyStatus = 1
+	APIKeyStatusRevoked APIKeyStatus = 99
+)
+
+type APIKey struct {
+	ID         int64        `db:"id"`
+	Name       string       `db:"name"`
+	Prefix     string       `db:"prefix"`
+	KeyHash    string       `db:"key_hash" json:"-"`
+	Status     APIKeyStatus `db:"status"`
+	ExpiresAt  *time.Time   `db:"expires_at"`
+	LastUsedAt *time.Time   `db:"last_used_at"`
+	CreatedAt  time.Time    `db:"created_at"`
+}
+
+// IsUsable reports whether the key can still authenticate at the given time
+func (k *APIKey) IsUsable(at time.Time) bool {
+	if k.Status != APIKeyStatusActive {
+		return false
+	}
+	return k.ExpiresAt == nil || at.Before(*k.ExpiresAt)
+}
+
+// IssuedAPIKey holds the plain key, which is only shown once at creation
+type IssuedAPIKey struct {
+	APIKey *APIKey `json:"api_key"`
+	Key    string  `json:"key"`
+}
+
+type LoginPayload struct {
+	Email    string `json:"email" form:"email" validate:"required,email"`
+	Password string `json:"password" form:"password" validate:"required"`
+}
+
+type Token struct {
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
@@ -0,0 +1,121 @@
+package entity
+
+import "time"
+
+type MandateStatus int8
+
+const (
+	MandateStatusActive  MandateStatus = 1
+	MandateStatusRevoked MandateStatus = 99
+)
+
+func (it MandateStatus) String() string {
+	switch it {
+	case MandateStatusActive:
+		return "Active"
+	case MandateStatusRevoked:
+		return "Revoked"
+	default:
+		return "Unknown"
+	}
+}
+
+// AutodebitMandate is the borrower's authorization to collect the due bills of a loan from AccountReference
+// through Provider, each collection is capped at MaxAmount
+type AutodebitMandate struct {
+	ID               int64         `db:"id" json:"id"`
+	LoanID           int64         `db:"loan_id" json:"loan_id"`
+	Provider         string        `db:"provider" json:"provider"`
+	AccountReference string        `db:"account_reference" json:"account_reference"`
+	MaxAmount        float64       `db:"max_amount" json:"max_amount"`
+	Status           MandateStatus `db:"status" json:"status"`
+	CreatedAt        time.Time     `db:"created_at" json:"created_at"`
+	UpdatedAt        *time.Time    `db:"updated_at" json:"updated_at,omitempty"`
+}
+
+type CreateMandatePayload struct {
+	LoanID           int64   `json:"loan_id" validate:"required,gt=0"`
+	Provider         string  `json:"provider" validate:"required"`
+	AccountReference string  `json:"account_reference" validate:"required,max=64"`
+	MaxAmount        float64 `json:"max_amount" validate:"required,gt=0"`
+}
+
+type MandateFilter struct {
+	LoanID int64         `query:"loan_id" validate:"gte=0"`
+	Status MandateStatus `query:"status" validate:"omitempty,oneof=1 99"`
+}
+
+type CollectionAttemptStatus int8
+
+const (
+	CollectionAttemptStatusPending CollectionAttemptStatus = 1
+	// CollectionAttemptStatusCancelled attempts were no longer needed, the bill was paid otherwise or the mandate revoked
+	CollectionAttemptStatusCancelled CollectionAttemptStatus = 97
+	// CollectionAttemptStatusFailed attempts were declined for good or ran out of retries
+	CollectionAttemptStatusFailed    CollectionAttemptStatus = 98
+	CollectionAttemptStatusSucceeded CollectionAttemptStatus = 99
+)
+
+func (it CollectionAttemptStatus) String() string {
+	switch it {
+	case CollectionAttemptStatusPending:
+		return "Pending"
+	case CollectionAttemptStatusCancelled:
+		return "Cancelled"
+	case CollectionAttemptStatusFailed:
+		return "Failed"
+	case CollectionAttemptStatusSucceeded:
+		return "Succeeded"
+	default:
+		return "Unknown"
+	}
+}
+
+// CollectionAttempt collects the bills of a mandate's loan once PaymentID falls due, retried until it succeeds
+// or the retry policy gives up
+type CollectionAttempt struct {
+	ID                int64                   `db:"id" json:"id"`
+	MandateID         int64                   `db:"mandate_id" json:"mandate_id"`
+	LoanID            int64                   `db:"loan_id" json:"loan_id"`
+	PaymentID         int64                   `db:"payment_id" json:"payment_id"`
+	DueDate           Date                    `db:"due_date" json:"due_date"`
+	Amount            float64                 `db:"amount" json:"amount"`
+	Status            CollectionAttemptStatus `db:"status" json:"status"`
+	Attempts          int                     `db:"attempts" json:"attempts"`
+	ProviderReference string                  `db:"provider_reference" json:"provider_reference,omitempty"`
+	TransactionID     *int64                  `db:"transaction_id" json:"transaction_id,omitempty"`
+	LastError         string                  `db:"last_error" json:"last_error,omitempty"`
+	NextAttemptAt     time.Time               `db:"next_attempt_at" json:"next_attempt_at"`
+	CreatedAt         time.Time               `db:"created_at" json:"created_at"`
+	CompletedAt       *time.Time              `db:"completed_at" json:"completed_at,omitempty"`
+}
+
+type CollectionAttemptFilter struct {
+	Status CollectionAttemptStatus `query:"status" validate:"omitempty,oneof=1 97 98 99"`
+	Limit  int                     `query:"limit" validate:"omitempty,gte=1,lte=500"`
+}
+
+// DebitRequest asks a debit provider to charge an account, Key is the same when a request is repeated so the
+// provider can charge it only once
+type DebitRequest struct {
+	Key              string
+	AccountReference string
+	Amount           float64
+	Description      string
+}
+
+// DebitResult is the answer of the provider, a declined debit is Retryable when the account may be charged
+// later, e.g. on insufficient funds
+type DebitResult struct {
+	ProviderReference string
+	Approved          bool
+	Retryable         bool
+	Reason            string
+}
+
+// AutodebitRun counts what one scheduler run did
+type AutodebitRun struct {
+	Scheduled int `json:"scheduled"`
+	Collected int `json:"collected"`
+	Failed    int `json:"failed"`
+}
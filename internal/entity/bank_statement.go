@@ -0,0 +1,102 @@
+package entity
+
+import "time"
+
+type BankStatementFormat string
+
+const (
+	BankStatementFormatCSV   BankStatementFormat = "csv"
+	BankStatementFormatMT940 BankStatementFormat = "mt940"
+)
+
+// BankStatementEntry is a mutation as read from a statement file, credits have a positive amount
+type BankStatementEntry struct {
+	BookedAt    time.Time
+	Amount      float64
+	Reference   string
+	Description string
+}
+
+type BankStatement struct {
+	ID         int64                `db:"id" json:"id"`
+	Account    string               `db:"account" json:"account"`
+	Format     BankStatementFormat  `db:"format" json:"format"`
+	FileName   string               `db:"file_name" json:"file_name,omitempty"`
+	LineCount  int                  `db:"line_count" json:"line_count"`
+	ImportedAt time.Time            `db:"imported_at" json:"imported_at"`
+	Lines      []*BankStatementLine `db:"-" json:"lines,omitempty"`
+}
+
+type BankStatementLineStatus int8
+
+const (
+	BankStatementLineStatusReceived BankStatementLineStatus = 1
+	// BankStatementLineStatusIgnored lines are debits, only credits can repay a loan
+	BankStatementLineStatusIgnored BankStatementLineStatus = 95
+	// BankStatementLineStatusUnmatched lines wait in the review queue to be matched to a loan or rejected
+	BankStatementLineStatusUnmatched BankStatementLineStatus = 96
+	BankStatementLineStatusRejected  BankStatementLineStatus = 97
+	// BankStatementLineStatusDuplicate lines were already imported with an earlier statement
+	BankStatementLineStatusDuplicate BankStatementLineStatus = 98
+	BankStatementLineStatusPosted    BankStatementLineStatus = 99
+)
+
+func (it BankStatementLineStatus) String() string {
+	switch it {
+	case BankStatementLineStatusReceived:
+		return "Received"
+	case BankStatementLineStatusIgnored:
+		return "Ignored"
+	case BankStatementLineStatusUnmatched:
+		return "Unmatched"
+	case BankStatementLineStatusRejected:
+		return "Rejected"
+	case BankStatementLineStatusDuplicate:
+		return "Duplicate"
+	case BankStatementLineStatusPosted:
+		return "Posted"
+	default:
+		return "Unknown"
+	}
+}
+
+// BankStatementLine is one mutation of an imported statement. ExternalID identifies the mutation within the
+// account across imports: the bank reference, or a hash of the line when the bank gives none
+type BankStatementLine struct {
+	ID            int64                   `db:"id" json:"id"`
+	StatementID   int64                   `db:"statement_id" json:"statement_id"`
+	LineNo        int                     `db:"line_no" json:"line_no"`
+	Account       string                  `db:"account" json:"account"`
+	ExternalID    string                  `db:"external_id" json:"external_id"`
+	BookedAt      time.Time               `db:"booked_at" json:"booked_at"`
+	Amount        float64                 `db:"amount" json:"amount"`
+	Reference     string                  `db:"reference" json:"reference,omitempty"`
+	Description   string                  `db:"description" json:"description,omitempty"`
+	Status        BankStatementLineStatus `db:"status" json:"status"`
+	LoanID        *int64                  `db:"loan_id" json:"loan_id,omitempty"`
+	TransactionID *int64                  `db:"transaction_id" json:"transaction_id,omitempty"`
+	// Note is why the line couldn't be matched, or the reason it was rejected
+	Note       string     `db:"note" json:"note,omitempty"`
+	ReviewedAt *time.Time `db:"reviewed_at" json:"reviewed_at,omitempty"`
+}
+
+type ImportBankStatementPayload struct {
+	Format BankStatementFormat `json:"format" validate:"oneof=csv mt940"`
+	// Account is required for csv files, mt940 files name their account
+	Account  string `json:"account" validate:"max=64"`
+	FileName string `json:"file_name" validate:"max=255"`
+}
+
+type BankStatementLineFilter struct {
+	StatementID int64                   `query:"statement_id" validate:"gte=0"`
+	Status      BankStatementLineStatus `query:"status" validate:"omitempty,oneof=1 95 96 97 98 99"`
+	Limit       int                     `query:"limit" validate:"omitempty,gte=1,lte=500"`
+}
+
+type MatchBankStatementLinePayload struct {
+	LoanID int64 `json:"loan_id" validate:"gt=0"`
+}
+
+type RejectBankStatementLinePayload struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
@@ -0,0 +1,141 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const DateLayout = "2006-01-02"
+
+// Date is a calendar day without time of day, as due dates are. It's kept at midnight UTC, so the same day
+// compares, stores and prints the same whatever the zone of the server or of the client
+type Date struct {
+	t time.Time
+}
+
+func NewDate(year int, month time.Month, day int) Date {
+	return Date{t: time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+// DateOf is the day t falls on in loc
+func DateOf(t time.Time, loc *time.Location) Date {
+	year, month, day := t.In(loc).Date()
+	return NewDate(year, month, day)
+}
+
+// ParseDate reads a YYYY-MM-DD date
+func ParseDate(value string) (Date, error) {
+	t, err := time.Parse(DateLayout, value)
+	if err != nil {
+		return Date{}, err
+	}
+	return Date{t: t}, nil
+}
+
+// Time is the midnight UTC of the day
+func (d Date) Time() time.Time {
+	return d.t
+}
+
+// Start is the midnight of the day in loc
+func (d Date) Start(loc *time.Location) time.Time {
+	year, month, day := d.t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, loc)
+}
+
+func (d Date) AddDays(days int) Date {
+	return Date{t: d.t.AddDate(0, 0, days)}
+}
+
+func (d Date) Before(other Date) bool {
+	return d.t.Before(other.t)
+}
+
+func (d Date) After(other Date) bool {
+	return d.t.After(other.t)
+}
+
+func (d Date) Equal(other Date) bool {
+	return d.t.Equal(other.t)
+}
+
+func (d Date) IsZero() bool {
+	return d.t.IsZero()
+}
+
+func (d Date) Weekday() time.Weekday {
+	return d.t.Weekday()
+}
+
+func (d Date) Month() time.Month {
+	return d.t.Month()
+}
+
+func (d Date) String() string {
+	return d.t.Format(DateLayout)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON reads a YYYY-MM-DD date, or the date part of an RFC 3339 timestamp in its own offset
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	date, err := ParseDate(value)
+	if err != nil {
+		t, rfcErr := time.Parse(time.RFC3339, value)
+		if rfcErr != nil {
+			return err
+		}
+		date = DateOf(t, t.Location())
+	}
+
+	*d = date
+	return nil
+}
+
+// Scan reads DATE columns, the drivers give them as midnight UTC or as text
+func (d *Date) Scan(src any) error {
+	switch value := src.(type) {
+	case nil:
+		*d = Date{}
+	case time.Time:
+		*d = DateOf(value, value.Location())
+	case string:
+		return d.scanText(value)
+	case []byte:
+		return d.scanText(string(value))
+	default:
+		return fmt.Errorf("cannot scan %T into Date", src)
+	}
+	return nil
+}
+
+func (d *Date) scanText(value string) error {
+	if len(value) < len(DateLayout) {
+		return fmt.Errorf("cannot scan %q into Date", value)
+	}
+
+	date, err := ParseDate(value[:len(DateLayout)])
+	if err != nil {
+		return err
+	}
+
+	*d = date
+	return nil
+}
+
+// Value stores the day as its midnight UTC, and the zero day as NULL
+func (d Date) Value() (driver.Value, error) {
+	if d.IsZero() {
+		return nil, nil
+	}
+	return d.t, nil
+}
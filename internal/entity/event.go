@@ -0,0 +1,104 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventLoanCreated          EventType = "loan.created"
+	EventPaymentPosted        EventType = "payment.posted"
+	EventLoanPaidOff          EventType = "loan.paid_off"
+	EventLoanBecameDelinquent EventType = "loan.became_delinquent"
+	EventLoanRestructured     EventType = "loan.restructured"
+)
+
+const (
+	EventAggregateLoan = "loan"
+)
+
+type EventStatus int8
+
+const (
+	EventStatusPending EventStatus = 1
+	// EventStatusFailed events ran out of delivery attempts
+	EventStatusFailed    EventStatus = 98
+	EventStatusDelivered EventStatus = 99
+)
+
+func (it EventStatus) String() string {
+	switch it {
+	case EventStatusPending:
+		return "Pending"
+	case EventStatusFailed:
+		return "Failed"
+	case EventStatusDelivered:
+		return "Delivered"
+	default:
+		return "Unknown"
+	}
+}
+
+// Event is a domain event stored in the outbox with the change that raised it, the json form is what sinks receive
+type Event struct {
+	ID            int64           `db:"id" json:"id"`
+	Type          EventType       `db:"type" json:"type"`
+	AggregateType string          `db:"aggregate_type" json:"aggregate_type"`
+	AggregateID   int64           `db:"aggregate_id" json:"aggregate_id"`
+	Payload       json.RawMessage `db:"payload" json:"payload"`
+	OccurredAt    time.Time       `db:"occurred_at" json:"occurred_at"`
+	Status        EventStatus     `db:"status" json:"-"`
+	Attempts      int             `db:"attempts" json:"-"`
+	LastError     string          `db:"last_error" json:"-"`
+	NextAttemptAt time.Time       `db:"next_attempt_at" json:"-"`
+	DeliveredAt   *time.Time      `db:"delivered_at" json:"-"`
+}
+
+type LoanCreatedPayload struct {
+	LoanID           int64     `json:"loan_id"`
+	UserID           int64     `json:"user_id"`
+	Amount           float64   `json:"amount"`
+	Interest         float64   `json:"interest"`
+	Tenure           int       `json:"tenure"`
+	Outstanding      float64   `json:"outstanding"`
+	BillingStartDate time.Time `json:"billing_start_date"`
+}
+
+type PaymentPostedPayload struct {
+	LoanID        int64   `json:"loan_id"`
+	UserID        int64   `json:"user_id"`
+	TransactionID int64   `json:"transaction_id"`
+	PaymentIDs    []int64 `json:"payment_ids"`
+	Amount        float64 `json:"amount"`
+	Penalty       float64 `json:"penalty"`
+	Outstanding   float64 `json:"outstanding"`
+}
+
+type LoanPaidOffPayload struct {
+	LoanID        int64     `json:"loan_id"`
+	UserID        int64     `json:"user_id"`
+	TransactionID int64     `json:"transaction_id"`
+	PaidAt        time.Time `json:"paid_at"`
+}
+
+type LoanBecameDelinquentPayload struct {
+	LoanID      int64     `json:"loan_id"`
+	UserID      int64     `json:"user_id"`
+	DuePayments int       `json:"due_payments"`
+	AmountDue   float64   `json:"amount_due"`
+	Since       time.Time `json:"since"`
+}
+
+type LoanRestructuredPayload struct {
+	LoanID             int64   `json:"loan_id"`
+	UserID             int64   `json:"user_id"`
+	RestructureID      int64   `json:"restructure_id"`
+	Interest           float64 `json:"interest"`
+	Tenure             int     `json:"tenure"`
+	GracePeriods       int     `json:"grace_periods"`
+	Principal          float64 `json:"principal"`
+	CapitalizedArrears float64 `json:"capitalized_arrears"`
+	Outstanding        float64 `json:"outstanding"`
+}
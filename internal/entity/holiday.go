@@ -0,0 +1,51 @@
+package entity
+
+import "time"
+
+// BusinessDayConvention moves a date that isn't a business day
+type BusinessDayConvention int8
+
+const (
+	// BusinessDayFollowing moves to the next business day
+	BusinessDayFollowing BusinessDayConvention = iota
+	// BusinessDayModifiedFollowing moves to the next business day, unless it's in the next month
+	BusinessDayModifiedFollowing
+	// BusinessDayPreceding moves to the previous business day
+	BusinessDayPreceding
+)
+
+func (it BusinessDayConvention) String() string {
+	switch it {
+	case BusinessDayFollowing:
+		return "following"
+	case BusinessDayModifiedFollowing:
+		return "modified_following"
+	case BusinessDayPreceding:
+		return "preceding"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseBusinessDayConvention resolves a convention from its name (e.g. modified_following)
+func ParseBusinessDayConvention(name string) (BusinessDayConvention, bool) {
+	for convention := BusinessDayFollowing; convention <= BusinessDayPreceding; convention++ {
+		if convention.String() == name {
+			return convention, true
+		}
+	}
+	return 0, false
+}
+
+// Holiday is a day without business, no installment falls due on it
+type Holiday struct {
+	ID        int64     `db:"id" json:"id"`
+	Date      Date      `db:"date" json:"date"`
+	Name      string    `db:"name" json:"name"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+type HolidayPayload struct {
+	Date string `json:"date" validate:"required,datetime=2006-01-02"`
+	Name string `json:"name" validate:"required,max=100"`
+}
@@ -0,0 +1,109 @@
+package entity
+
+import (
+	"math"
+	"time"
+)
+
+type AccountType int8
+
+const (
+	AccountTypeAsset AccountType = iota + 1
+	AccountTypeLiability
+	AccountTypeEquity
+	AccountTypeIncome
+	AccountTypeExpense
+)
+
+func (it AccountType) String() string {
+	switch it {
+	case AccountTypeAsset:
+		return "Asset"
+	case AccountTypeLiability:
+		return "Liability"
+	case AccountTypeEquity:
+		return "Equity"
+	case AccountTypeIncome:
+		return "Income"
+	case AccountTypeExpense:
+		return "Expense"
+	default:
+		return "Unknown"
+	}
+}
+
+// Chart of accounts, seeded by the migrations
+const (
+	AccountCash           = "1000"
+	AccountLoanReceivable = "1100"
+	AccountSuspense       = "2000"
+	// AccountUnearnedInterest holds the interest capitalized into the principal until the principal is repaid
+	AccountUnearnedInterest = "2100"
+	AccountInterestIncome   = "4000"
+	AccountFeeIncome        = "4100"
+	// AccountRounding takes the difference between the cash received and the bills it paid
+	AccountRounding = "5000"
+)
+
+const (
+	JournalReferenceLoan        = "loan"
+	JournalReferenceTransaction = "transaction"
+	// JournalReferenceReconciliation entries are adjustments posted by the reconciliation, referencing the loan
+	JournalReferenceReconciliation = "reconciliation"
+	// JournalReferenceRestructure entries capitalize arrears, referencing the loan restructure
+	JournalReferenceRestructure = "restructure"
+)
+
+// LedgerTolerance absorbs float rounding when comparing money amounts
+const LedgerTolerance = 0.005
+
+type Account struct {
+	Code string      `db:"code" json:"code"`
+	Name string      `db:"name" json:"name"`
+	Type AccountType `db:"type" json:"type"`
+}
+
+type JournalEntry struct {
+	ID            int64          `db:"id" json:"id"`
+	LoanID        *int64         `db:"loan_id" json:"loan_id,omitempty"`
+	ReferenceType string         `db:"reference_type" json:"reference_type"`
+	ReferenceID   int64          `db:"reference_id" json:"reference_id"`
+	Description   string         `db:"description" json:"description"`
+	ReversalOf    *int64         `db:"reversal_of" json:"reversal_of,omitempty"`
+	PostedAt      time.Time      `db:"posted_at" json:"posted_at"`
+	Lines         []*JournalLine `db:"-" json:"lines"`
+}
+
+type JournalLine struct {
+	ID             int64   `db:"id" json:"id"`
+	JournalEntryID int64   `db:"journal_entry_id" json:"journal_entry_id"`
+	AccountCode    string  `db:"account_code" json:"account_code"`
+	Debit          float64 `db:"debit" json:"debit"`
+	Credit         float64 `db:"credit" json:"credit"`
+}
+
+// IsBalanced reports whether total debits equal total credits
+func (e *JournalEntry) IsBalanced() bool {
+	var debit, credit float64
+	for _, line := range e.Lines {
+		debit += line.Debit
+		credit += line.Credit
+	}
+	return len(e.Lines) >= 2 && math.Abs(debit-credit) < LedgerTolerance
+}
+
+type TrialBalanceLine struct {
+	Code   string      `json:"code"`
+	Name   string      `json:"name"`
+	Type   AccountType `json:"type"`
+	Debit  float64     `json:"debit"`
+	Credit float64     `json:"credit"`
+}
+
+type TrialBalance struct {
+	AsOf        time.Time           `json:"as_of"`
+	Accounts    []*TrialBalanceLine `json:"accounts"`
+	TotalDebit  float64             `json:"total_debit"`
+	TotalCredit float64             `json:"total_credit"`
+	Balanced    bool                `json:"balanced"`
+}
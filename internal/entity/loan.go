@@ -2,20 +2,30 @@ package entity
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
 type LoanStatus int8
 
 const (
-	LoanStatusActive LoanStatus = 1
-	LoanStatusPaid   LoanStatus = 99
+	// LoanStatusPending is a loan waiting for approval, it has no installments nor disbursement yet
+	LoanStatusPending LoanStatus = 0
+	LoanStatusActive  LoanStatus = 1
+	// LoanStatusRejected is a pending loan turned down, it's never disbursed
+	LoanStatusRejected LoanStatus = 98
+	LoanStatusPaid     LoanStatus = 99
 )
 
 func (it LoanStatus) String() string {
 	switch it {
+	case LoanStatusPending:
+		return "Pending"
 	case LoanStatusActive:
 		return "Active"
+	case LoanStatusRejected:
+		return "Rejected"
 	case LoanStatusPaid:
 		return "Paid"
 	default:
@@ -23,6 +33,22 @@ func (it LoanStatus) String() string {
 	}
 }
 
+// Key identifies the status label in the i18n catalogs
+func (it LoanStatus) Key() string {
+	switch it {
+	case LoanStatusPending:
+		return "loan_status.pending"
+	case LoanStatusActive:
+		return "loan_status.active"
+	case LoanStatusRejected:
+		return "loan_status.rejected"
+	case LoanStatusPaid:
+		return "loan_status.paid"
+	default:
+		return "loan_status.unknown"
+	}
+}
+
 type InterestType int8
 
 const (
@@ -41,6 +67,63 @@ func (it InterestType) String() string {
 	}
 }
 
+// Key identifies the interest type label in the i18n catalogs
+func (it InterestType) Key() string {
+	switch it {
+	case InterestTypeFlatAnnual:
+		return "interest_type.flat_annual"
+	case InterestTypeReducingAnnual:
+		return "interest_type.reducing_annual"
+	default:
+		return "interest_type.unknown"
+	}
+}
+
+// RepaymentStructure is how the installments of a loan repay its principal
+type RepaymentStructure int8
+
+const (
+	// RepaymentStructureEqualInstallment makes every installment the same amount
+	RepaymentStructureEqualInstallment RepaymentStructure = iota
+	// RepaymentStructureEqualPrincipal repays the same principal with every installment
+	RepaymentStructureEqualPrincipal
+	// RepaymentStructureBullet repays the whole principal with the last installment
+	RepaymentStructureBullet
+	// RepaymentStructureBalloon repays BalloonPercent of the principal with the last installment
+	RepaymentStructureBalloon
+)
+
+func (rs RepaymentStructure) String() string {
+	switch rs {
+	case RepaymentStructureEqualInstallment:
+		return "Equal Installment"
+	case RepaymentStructureEqualPrincipal:
+		return "Equal Principal"
+	case RepaymentStructureBullet:
+		return "Bullet"
+	case RepaymentStructureBalloon:
+		return "Balloon"
+	default:
+		return "Unknown"
+	}
+}
+
+// Key identifies the repayment structure label in the i18n catalogs
+func (rs RepaymentStructure) Key() string {
+	switch rs {
+	case RepaymentStructureEqualInstallment:
+		return "repayment_structure.equal_installment"
+	case RepaymentStructureEqualPrincipal:
+		return "repayment_structure.equal_principal"
+	case RepaymentStructureBullet:
+		return "repayment_structure.bullet"
+	case RepaymentStructureBalloon:
+		return "repayment_structure.balloon"
+	default:
+		return "repayment_structure.unknown"
+	}
+}
+
 type TenureType int8
 
 const (
@@ -59,18 +142,62 @@ func (it TenureType) String() string {
 	}
 }
 
+// Key identifies the tenure type label in the i18n catalogs
+func (it TenureType) Key() string {
+	switch it {
+	case TenureTypeWeekly:
+		return "tenure_type.weekly"
+	default:
+		return "tenure_type.unknown"
+	}
+}
+
+// GraceInterest is what happens to the interest of the grace periods, when no installment falls due
+type GraceInterest int8
+
+const (
+	// GraceInterestAccrued is paid with the installments that repay the principal
+	GraceInterestAccrued GraceInterest = iota
+	// GraceInterestCapitalized is added to the principal and bears interest
+	GraceInterestCapitalized
+)
+
+func (it GraceInterest) String() string {
+	switch it {
+	case GraceInterestAccrued:
+		return "Accrued"
+	case GraceInterestCapitalized:
+		return "Capitalized"
+	default:
+		return "Unknown"
+	}
+}
+
 type Loan struct {
 	ID               int64        `db:"id"`
-	UserID           int64        `db:"user_id"`
-	Interest         float64      `db:"interest"`
-	InterestType     InterestType `db:"interest_type"`
-	Tenure           int          `db:"tenure"`
-	TenureType       TenureType   `db:"tenure_type"`
-	Amount           float64      `db:"amount"`
+	UserID           int64        `db:"user_id" validate:"gt=0"`
+	Interest         float64      `db:"interest" validate:"gte=0,lte=100"`
+	InterestType     InterestType `db:"interest_type" validate:"oneof=0 1"`
+	Tenure           int          `db:"tenure" validate:"gt=0,lte=520"`
+	TenureType       TenureType   `db:"tenure_type" validate:"oneof=0"`
+	Amount           float64      `db:"amount" validate:"gt=0"`
 	Outstanding      float64      `db:"outstanding"`
 	Status           LoanStatus   `db:"status"`
 	CreatedAt        time.Time    `db:"created_at"`
-	BillingStartDate time.Time    `db:"billing_start_date"`
+	BillingStartDate time.Time    `db:"billing_start_date" validate:"required"`
+	DelinquentSince  *time.Time   `db:"delinquent_since"`
+	// DisbursedAt is when the loan was approved and disbursed, nil while it is pending
+	DisbursedAt *time.Time `db:"disbursed_at"`
+	// VirtualAccount is empty for loans booked before virtual accounts existed
+	VirtualAccount       string               `db:"virtual_account"`
+	VirtualAccountStatus VirtualAccountStatus `db:"virtual_account_status"`
+	// GracePeriods are the first weeks of the tenure without installment, InterestOnlyPeriods the following
+	// installments that only pay interest
+	GracePeriods        int                `db:"grace_periods" validate:"gte=0"`
+	GraceInterest       GraceInterest      `db:"grace_interest" validate:"oneof=0 1"`
+	InterestOnlyPeriods int                `db:"interest_only_periods" validate:"gte=0"`
+	RepaymentStructure  RepaymentStructure `db:"repayment_structure" validate:"oneof=0 1 2 3"`
+	BalloonPercent      float64            `db:"balloon_percent" validate:"gte=0,lt=100"`
 }
 
 func (l Loan) String() string {
@@ -82,6 +209,9 @@ func (l Loan) String() string {
 			"Interest: %.2f%%\n"+
 			"Interest Type: %s\n"+
 			"Tenure: %d %s\n"+
+			"Grace Periods: %d (%s interest)\n"+
+			"Interest Only Periods: %d\n"+
+			"Repayment Structure: %s\n"+
 			"Status: %s\n"+
 			"Created At: %s\n"+
 			"Billing Start Date: %s\n",
@@ -93,24 +223,53 @@ func (l Loan) String() string {
 		l.InterestType,
 		l.Tenure,
 		l.TenureType,
+		l.GracePeriods,
+		l.GraceInterest,
+		l.InterestOnlyPeriods,
+		l.RepaymentStructure,
 		l.Status,
 		l.CreatedAt.Format("2006-01-02 15:04:05"),
 		l.BillingStartDate.Format("2006-01-02"),
 	)
 }
 
+const loanReferencePrefix = "LOAN-"
+
+// Reference is what borrowers quote when paying through a payment gateway
+func (l Loan) Reference() string {
+	return loanReferencePrefix + strconv.FormatInt(l.ID, 10)
+}
+
+// ParseLoanReference returns the loan ID of a reference, matched case-insensitively
+func ParseLoanReference(reference string) (int64, bool) {
+	reference = strings.ToUpper(strings.TrimSpace(reference))
+	id, err := strconv.ParseInt(strings.TrimPrefix(reference, loanReferencePrefix), 10, 64)
+	if err != nil || !strings.HasPrefix(reference, loanReferencePrefix) || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
+
 type CreateLoanPayload struct {
-	UserID           int64        `json:"user_id"`
-	Amount           float64      `json:"amount"`
-	Interest         float64      `json:"interest"`
-	InterestType     InterestType `json:"interest_type"`
-	Tenure           int          `json:"tenure"`
-	TenureType       TenureType   `json:"tenure_type"`
-	BillingStartDate time.Time    `json:"billing_start_date"`
+	UserID           int64        `json:"user_id" validate:"gt=0"`
+	Amount           float64      `json:"amount" validate:"gt=0"`
+	Interest         float64      `json:"interest" validate:"gte=0,lte=100"`
+	InterestType     InterestType `json:"interest_type" validate:"oneof=0 1"`
+	Tenure           int          `json:"tenure" validate:"gt=0,lte=520"`
+	TenureType       TenureType   `json:"tenure_type" validate:"oneof=0"`
+	BillingStartDate time.Time    `json:"billing_start_date" validate:"required"`
+	// GracePeriods and InterestOnlyPeriods are part of the tenure
+	GracePeriods        int           `json:"grace_periods" validate:"gte=0"`
+	GraceInterest       GraceInterest `json:"grace_interest" validate:"oneof=0 1"`
+	InterestOnlyPeriods int           `json:"interest_only_periods" validate:"gte=0"`
+	// BalloonPercent is the part of the principal repaid with the last installment of a balloon loan
+	RepaymentStructure RepaymentStructure `json:"repayment_structure" validate:"oneof=0 1 2 3"`
+	BalloonPercent     float64            `json:"balloon_percent" validate:"gte=0,lt=100"`
 }
 
 func NewLoan(userID int64, amount float64, interest float64, tenure int, interestType InterestType, tenureType TenureType, billingStartDate time.Time) *Loan {
 	outstanding := amount + (amount * float64(interest))
+	disbursedAt := time.Now()
 
 	return &Loan{
 		UserID:           userID,
@@ -121,7 +280,8 @@ func NewLoan(userID int64, amount float64, interest float64, tenure int, interes
 		Amount:           amount,
 		Outstanding:      outstanding,
 		Status:           LoanStatusActive,
-		CreatedAt:        time.Now(),
+		CreatedAt:        disbursedAt,
+		DisbursedAt:      &disbursedAt,
 		BillingStartDate: billingStartDate,
 	}
 }
@@ -0,0 +1,113 @@
+package entity
+
+import "time"
+
+type NotificationKind string
+
+const (
+	NotificationUpcomingDue     NotificationKind = "upcoming_due"
+	NotificationOverdue         NotificationKind = "overdue"
+	NotificationPaymentReceived NotificationKind = "payment_received"
+	NotificationLoanPaidOff     NotificationKind = "loan_paid_off"
+)
+
+// Notification channels, a channel is only used when a transport is configured for it
+const (
+	NotificationChannelEmail = "email"
+	NotificationChannelSMS   = "sms"
+	NotificationChannelPush  = "push"
+)
+
+type NotificationStatus int8
+
+const (
+	NotificationStatusPending NotificationStatus = 1
+	// NotificationStatusFailed notifications are resent by the scheduler until they run out of attempts
+	NotificationStatusFailed NotificationStatus = 98
+	NotificationStatusSent   NotificationStatus = 99
+)
+
+func (it NotificationStatus) String() string {
+	switch it {
+	case NotificationStatusPending:
+		return "Pending"
+	case NotificationStatusFailed:
+		return "Failed"
+	case NotificationStatusSent:
+		return "Sent"
+	default:
+		return "Unknown"
+	}
+}
+
+// Notification is the sent-log entry of one message on one channel. DedupeKey names the kind, what it is about
+// and the channel, so the same reminder is never sent twice
+type Notification struct {
+	ID        int64              `db:"id" json:"id"`
+	UserID    int64              `db:"user_id" json:"user_id"`
+	LoanID    int64              `db:"loan_id" json:"loan_id"`
+	Kind      NotificationKind   `db:"kind" json:"kind"`
+	Channel   string             `db:"channel" json:"channel"`
+	Address   string             `db:"address" json:"address"`
+	DedupeKey string             `db:"dedupe_key" json:"dedupe_key"`
+	Subject   string             `db:"subject" json:"subject"`
+	Body      string             `db:"body" json:"body"`
+	Status    NotificationStatus `db:"status" json:"status"`
+	Attempts  int                `db:"attempts" json:"attempts"`
+	LastError string             `db:"last_error" json:"last_error,omitempty"`
+	CreatedAt time.Time          `db:"created_at" json:"created_at"`
+	SentAt    *time.Time         `db:"sent_at" json:"sent_at,omitempty"`
+}
+
+type NotificationFilter struct {
+	UserID int64              `query:"user_id" validate:"gte=0"`
+	LoanID int64              `query:"loan_id" validate:"gte=0"`
+	Kind   NotificationKind   `query:"kind" validate:"omitempty,oneof=upcoming_due overdue payment_received loan_paid_off"`
+	Status NotificationStatus `query:"status" validate:"omitempty,oneof=1 98 99"`
+	Limit  int                `query:"limit" validate:"omitempty,gte=1,lte=500"`
+}
+
+// NotificationMessage is what a channel delivers to To
+type NotificationMessage struct {
+	Kind    NotificationKind `json:"kind"`
+	To      string           `json:"to"`
+	Subject string           `json:"subject"`
+	Body    string           `json:"body"`
+}
+
+// NotificationPreferences are the channels a user is reached on, in Locale. Users without preferences get
+// every notification by email at their account address
+type NotificationPreferences struct {
+	UserID     int64                           `db:"user_id" json:"user_id"`
+	Locale     string                          `db:"locale" json:"locale"`
+	Channels   []NotificationChannelPreference `db:"channels" json:"channels"`
+	MutedKinds []NotificationKind              `db:"muted_kinds" json:"muted_kinds"`
+	UpdatedAt  *time.Time                      `db:"updated_at" json:"updated_at,omitempty"`
+}
+
+type NotificationChannelPreference struct {
+	Channel string `json:"channel" validate:"required,oneof=email sms push"`
+	Address string `json:"address" validate:"required,max=255"`
+	Enabled bool   `json:"enabled"`
+}
+
+func (p *NotificationPreferences) Mutes(kind NotificationKind) bool {
+	for _, muted := range p.MutedKinds {
+		if muted == kind {
+			return true
+		}
+	}
+	return false
+}
+
+type UpdateNotificationPreferencesPayload struct {
+	Locale     string                          `json:"locale" validate:"required,oneof=en id"`
+	Channels   []NotificationChannelPreference `json:"channels" validate:"dive"`
+	MutedKinds []NotificationKind              `json:"muted_kinds" validate:"dive,oneof=upcoming_due overdue payment_received loan_paid_off"`
+}
+
+// NotificationRun counts what one scheduler run did
+type NotificationRun struct {
+	Sent   int `json:"sent"`
+	Failed int `json:"failed"`
+}
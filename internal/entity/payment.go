@@ -6,15 +6,44 @@ type PaymentStatus int8
 
 const (
 	PaymentStatusActive PaymentStatus = 1
-	PaymentStatusPaid   PaymentStatus = 99
+	// PaymentStatusRestructured installments were left unpaid when the loan was restructured, they stay for history
+	PaymentStatusRestructured PaymentStatus = 98
+	PaymentStatusPaid         PaymentStatus = 99
 )
 
+func (it PaymentStatus) String() string {
+	switch it {
+	case PaymentStatusActive:
+		return "Active"
+	case PaymentStatusRestructured:
+		return "Restructured"
+	case PaymentStatusPaid:
+		return "Paid"
+	default:
+		return "Unknown"
+	}
+}
+
+// Key identifies the status label in the i18n catalogs
+func (it PaymentStatus) Key() string {
+	switch it {
+	case PaymentStatusActive:
+		return "payment_status.active"
+	case PaymentStatusRestructured:
+		return "payment_status.restructured"
+	case PaymentStatusPaid:
+		return "payment_status.paid"
+	default:
+		return "payment_status.unknown"
+	}
+}
+
 type Payment struct {
 	ID            int64         `db:"id"`
 	LoanID        int64         `db:"loan_id"`
 	TransactionID *int64        `db:"transaction_id"`
 	PaymentNo     int32         `db:"payment_no"`
-	DueDate       time.Time     `db:"due_date"`
+	DueDate       Date          `db:"due_date"`
 	Amount        float64       `db:"amount"`
 	Interest      float64       `db:"interest"`
 	TotalAmount   float64       `db:"total_amount"`
@@ -24,10 +53,11 @@ type Payment struct {
 }
 
 type CreatePaymentPayload struct {
-	LoanID      int64     `json:"loan_id"`
-	DueDate     time.Time `json:"due_date"`
-	PaymentNo   int32     `json:"payment_no"`
-	Amount      float64   `json:"amount"`
-	Interest    float64   `json:"interest"`
-	TotalAmount float64   `json:"total_amount"`
+	LoanID    int64 `json:"loan_id" validate:"gt=0"`
+	DueDate   Date  `json:"due_date" validate:"required"`
+	PaymentNo int32 `json:"payment_no" validate:"gt=0"`
+	// Amount is the principal, zero on interest only installments
+	Amount      float64 `json:"amount" validate:"gte=0"`
+	Interest    float64 `json:"interest" validate:"gte=0"`
+	TotalAmount float64 `json:"total_amount" validate:"gt=0"`
 }
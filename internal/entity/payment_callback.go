@@ -0,0 +1,80 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type GatewayPaymentStatus string
+
+const (
+	GatewayPaymentStatusPaid    GatewayPaymentStatus = "paid"
+	GatewayPaymentStatusPending GatewayPaymentStatus = "pending"
+	GatewayPaymentStatusFailed  GatewayPaymentStatus = "failed"
+)
+
+// GatewayPayment is a payment as reported by a provider callback, ExternalID is the provider's own payment ID
+type GatewayPayment struct {
+	ExternalID string
+	Reference  string
+	Amount     float64
+	Status     GatewayPaymentStatus
+	PaidAt     time.Time
+}
+
+type PaymentCallbackStatus int8
+
+const (
+	PaymentCallbackStatusReceived PaymentCallbackStatus = 1
+	// PaymentCallbackStatusIgnored callbacks report a payment that isn't paid (yet)
+	PaymentCallbackStatusIgnored PaymentCallbackStatus = 95
+	// PaymentCallbackStatusUnmatched callbacks are paid but couldn't be posted to a loan and need a manual follow up
+	PaymentCallbackStatusUnmatched PaymentCallbackStatus = 96
+	// PaymentCallbackStatusRejected callbacks failed the signature check or couldn't be parsed
+	PaymentCallbackStatusRejected  PaymentCallbackStatus = 97
+	PaymentCallbackStatusDuplicate PaymentCallbackStatus = 98
+	PaymentCallbackStatusProcessed PaymentCallbackStatus = 99
+)
+
+func (it PaymentCallbackStatus) String() string {
+	switch it {
+	case PaymentCallbackStatusReceived:
+		return "Received"
+	case PaymentCallbackStatusIgnored:
+		return "Ignored"
+	case PaymentCallbackStatusUnmatched:
+		return "Unmatched"
+	case PaymentCallbackStatusRejected:
+		return "Rejected"
+	case PaymentCallbackStatusDuplicate:
+		return "Duplicate"
+	case PaymentCallbackStatusProcessed:
+		return "Processed"
+	default:
+		return "Unknown"
+	}
+}
+
+// PaymentCallback keeps every callback as received, with the outcome of posting it, for dispute handling
+type PaymentCallback struct {
+	ID            int64                 `db:"id" json:"id"`
+	Provider      string                `db:"provider" json:"provider"`
+	ExternalID    string                `db:"external_id" json:"external_id,omitempty"`
+	Reference     string                `db:"reference" json:"reference,omitempty"`
+	LoanID        *int64                `db:"loan_id" json:"loan_id,omitempty"`
+	Amount        float64               `db:"amount" json:"amount,omitempty"`
+	PaymentStatus GatewayPaymentStatus  `db:"payment_status" json:"payment_status,omitempty"`
+	Status        PaymentCallbackStatus `db:"status" json:"status"`
+	TransactionID *int64                `db:"transaction_id" json:"transaction_id,omitempty"`
+	Error         string                `db:"error" json:"error,omitempty"`
+	Headers       json.RawMessage       `db:"headers" json:"headers"`
+	Payload       string                `db:"payload" json:"payload"`
+	ReceivedAt    time.Time             `db:"received_at" json:"received_at"`
+	ProcessedAt   *time.Time            `db:"processed_at" json:"processed_at,omitempty"`
+}
+
+type PaymentCallbackFilter struct {
+	Provider string                `query:"provider"`
+	Status   PaymentCallbackStatus `query:"status" validate:"omitempty,oneof=1 95 96 97 98 99"`
+	Limit    int                   `query:"limit" validate:"omitempty,gte=1,lte=500"`
+}
@@ -0,0 +1,49 @@
+package entity
+
+import "time"
+
+type ReconciliationIssueType string
+
+const (
+	// IssueOutstandingMismatch means loans.outstanding differs from the sum of its unpaid payments
+	IssueOutstandingMismatch ReconciliationIssueType = "outstanding_mismatch"
+	// IssueStatusMismatch means a loan is active without anything left to pay, or paid with unpaid payments
+	IssueStatusMismatch ReconciliationIssueType = "status_mismatch"
+	// IssueLedgerMismatch means the loan receivable in the ledger differs from the unpaid principal
+	IssueLedgerMismatch ReconciliationIssueType = "ledger_mismatch"
+	// IssueMissingSchedule means a loan has no payments at all
+	IssueMissingSchedule ReconciliationIssueType = "missing_schedule"
+	// IssueOrphanPayment means a payment points to a missing loan, or is paid without a transaction
+	IssueOrphanPayment ReconciliationIssueType = "orphan_payment"
+)
+
+// LoanBalance is a loan with the totals recomputed from its payments
+type LoanBalance struct {
+	LoanID          int64
+	Outstanding     float64
+	Status          LoanStatus
+	UnpaidTotal     float64
+	UnpaidPrincipal float64
+	PaymentCount    int
+}
+
+type ReconciliationIssue struct {
+	Type      ReconciliationIssueType `json:"type"`
+	LoanID    int64                   `json:"loan_id,omitempty"`
+	PaymentID int64                   `json:"payment_id,omitempty"`
+	Expected  float64                 `json:"expected"`
+	Actual    float64                 `json:"actual"`
+	Repaired  bool                    `json:"repaired"`
+}
+
+type ReconciliationReport struct {
+	CheckedAt    time.Time              `json:"checked_at"`
+	CheckedLoans int                    `json:"checked_loans"`
+	Issues       []*ReconciliationIssue `json:"issues"`
+	Repaired     int                    `json:"repaired"`
+}
+
+// Unresolved counts the issues left after the repair, if any
+func (r *ReconciliationReport) Unresolved() int {
+	return len(r.Issues) - r.Repaired
+}
@@ -0,0 +1,94 @@
+package entity
+
+import "time"
+
+// AgingBuckets are the days past due the aging report groups loans by, a loan is as late as its oldest unpaid
+// installment and falls in the last bucket whose MinDays it reached
+var AgingBuckets = []AgingBucket{
+	{Label: "current", MinDays: 0},
+	{Label: "1-30", MinDays: 1},
+	{Label: "31-60", MinDays: 31},
+	{Label: "61-90", MinDays: 61},
+	{Label: "91-180", MinDays: 91},
+	{Label: "180+", MinDays: 181},
+}
+
+// OutstandingTotals is what the borrowers of LoanCount loans still owe
+type OutstandingTotals struct {
+	LoanCount int     `json:"loan_count"`
+	Principal float64 `json:"outstanding_principal"`
+	Interest  float64 `json:"outstanding_interest"`
+}
+
+type AgingBucket struct {
+	Label   string `json:"bucket"`
+	MinDays int    `json:"min_days_past_due"`
+	OutstandingTotals
+	// PrincipalShare is the part of the outstanding principal of the portfolio in the bucket
+	PrincipalShare float64 `json:"principal_share"`
+}
+
+type AgingReport struct {
+	AsOf    time.Time      `json:"as_of"`
+	Buckets []*AgingBucket `json:"buckets"`
+}
+
+// PortfolioReport sums up the portfolio at AsOf. PAR30 and PAR90 are the outstanding principal of loans more than
+// 30 and 90 days past due, their ratios are the share of the outstanding principal at risk
+type PortfolioReport struct {
+	AsOf                 time.Time `json:"as_of"`
+	LoanCount            int       `json:"loan_count"`
+	ActiveLoanCount      int       `json:"active_loan_count"`
+	TotalDisbursed       float64   `json:"total_disbursed"`
+	OutstandingPrincipal float64   `json:"outstanding_principal"`
+	OutstandingInterest  float64   `json:"outstanding_interest"`
+	PAR30                float64   `json:"par30"`
+	PAR30Ratio           float64   `json:"par30_ratio"`
+	PAR90                float64   `json:"par90"`
+	PAR90Ratio           float64   `json:"par90_ratio"`
+}
+
+// CollectionPeriod compares what fell due in a month with what was collected. Collected only counts the
+// installments due in the month that were paid by its end, Received is every installment paid during the month
+type CollectionPeriod struct {
+	Period    string  `json:"period"`
+	Due       float64 `json:"due"`
+	Collected float64 `json:"collected"`
+	Received  float64 `json:"received"`
+	Rate      float64 `json:"collection_rate"`
+}
+
+type CollectionReport struct {
+	From    time.Time           `json:"from"`
+	To      time.Time           `json:"to"`
+	Periods []*CollectionPeriod `json:"periods"`
+}
+
+// VintageCohort is the loans disbursed in a month with the principal repaid per month, as read from the db
+type VintageCohort struct {
+	Cohort    string
+	LoanCount int
+	Disbursed float64
+	// Repaid is keyed by the month the installments were paid in, YYYY-MM
+	Repaid map[string]float64
+}
+
+// VintagePoint is the principal a cohort repaid by the end of its MonthsOnBook-th month, the month of
+// disbursement being month 0
+type VintagePoint struct {
+	MonthsOnBook int     `json:"months_on_book"`
+	Repaid       float64 `json:"repaid"`
+	RepaidRatio  float64 `json:"repaid_ratio"`
+}
+
+type Vintage struct {
+	Cohort    string          `json:"cohort"`
+	LoanCount int             `json:"loan_count"`
+	Disbursed float64         `json:"disbursed"`
+	Curve     []*VintagePoint `json:"curve"`
+}
+
+type VintageReport struct {
+	AsOf     time.Time  `json:"as_of"`
+	Vintages []*Vintage `json:"vintages"`
+}
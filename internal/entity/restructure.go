@@ -0,0 +1,36 @@
+package entity
+
+import "time"
+
+// LoanRestructure records new terms given to a loan in hardship. The installments left unpaid are closed and
+// replaced by a new schedule from FirstPaymentNo on, computed on the unpaid principal
+type LoanRestructure struct {
+	ID     int64  `db:"id" json:"id"`
+	LoanID int64  `db:"loan_id" json:"loan_id"`
+	Reason string `db:"reason" json:"reason"`
+	// ApprovedBy is the user who approved the new terms, nil when restructured by the system
+	ApprovedBy          *int64  `db:"approved_by" json:"approved_by"`
+	PreviousInterest    float64 `db:"previous_interest" json:"previous_interest"`
+	PreviousTenure      int     `db:"previous_tenure" json:"previous_tenure"`
+	PreviousOutstanding float64 `db:"previous_outstanding" json:"previous_outstanding"`
+	Interest            float64 `db:"interest" json:"interest"`
+	Tenure              int     `db:"tenure" json:"tenure"`
+	GracePeriods        int     `db:"grace_periods" json:"grace_periods"`
+	// Principal is what the new schedule repays, the unpaid principal plus the capitalized arrears
+	Principal float64 `db:"principal" json:"principal"`
+	// CapitalizedArrears is the interest of the past due installments added to the principal
+	CapitalizedArrears float64   `db:"capitalized_arrears" json:"capitalized_arrears"`
+	Outstanding        float64   `db:"outstanding" json:"outstanding"`
+	FirstPaymentNo     int32     `db:"first_payment_no" json:"first_payment_no"`
+	CreatedAt          time.Time `db:"created_at" json:"created_at"`
+}
+
+// RestructureLoanPayload are the new terms, the tenure and grace periods count installments of the loan tenure type.
+// Without capitalization the interest of the past due installments is added to the first new installment
+type RestructureLoanPayload struct {
+	Interest          float64 `json:"interest" validate:"gte=0,lte=100"`
+	Tenure            int     `json:"tenure" validate:"gt=0,lte=520"`
+	GracePeriods      int     `json:"grace_periods" validate:"gte=0,lte=52"`
+	CapitalizeArrears bool    `json:"capitalize_arrears"`
+	Reason            string  `json:"reason" validate:"required,max=500"`
+}
@@ -0,0 +1,184 @@
+package entity
+
+type Role int8
+
+const (
+	RoleBorrower Role = iota + 1
+	RoleCreditOfficer
+	RoleCollector
+	RoleFinance
+	RoleAdmin
+	// RolePartner is given to api key callers, it can't be assigned to users
+	RolePartner
+)
+
+func (it Role) String() string {
+	switch it {
+	case RoleBorrower:
+		return "Borrower"
+	case RoleCreditOfficer:
+		return "Credit Officer"
+	case RoleCollector:
+		return "Collector"
+	case RoleFinance:
+		return "Finance"
+	case RoleAdmin:
+		return "Admin"
+	case RolePartner:
+		return "Partner"
+	default:
+		return "Unknown"
+	}
+}
+
+// Key identifies the role label in the i18n catalogs
+func (it Role) Key() string {
+	switch it {
+	case RoleBorrower:
+		return "role.borrower"
+	case RoleCreditOfficer:
+		return "role.credit_officer"
+	case RoleCollector:
+		return "role.collector"
+	case RoleFinance:
+		return "role.finance"
+	case RoleAdmin:
+		return "role.admin"
+	case RolePartner:
+		return "role.partner"
+	default:
+		return "role.unknown"
+	}
+}
+
+// ParseRole resolves a role from its catalog name (e.g. credit_officer)
+func ParseRole(name string) (Role, bool) {
+	for role := RoleBorrower; role <= RolePartner; role++ {
+		if role.Key() == "role."+name {
+			return role, true
+		}
+	}
+	return 0, false
+}
+
+// Permission names an action, the `.own` variants only apply to the caller's own records
+type Permission string
+
+const (
+	PermUserRead              Permission = "user.read"
+	PermUserReadOwn           Permission = "user.read.own"
+	PermUserManageRole        Permission = "user.manage_role"
+	PermLoanRead              Permission = "loan.read"
+	PermLoanReadOwn           Permission = "loan.read.own"
+	PermLoanCreate            Permission = "loan.create"
+	PermLoanCreateOwn         Permission = "loan.create.own"
+	PermLoanApprove           Permission = "loan.approve"
+	PermPaymentRead           Permission = "payment.read"
+	PermTransactionCreate     Permission = "transaction.create"
+	PermTransactionCreateOwn  Permission = "transaction.create.own"
+	PermTransactionInquiry    Permission = "transaction.inquiry"
+	PermTransactionInquiryOwn Permission = "transaction.inquiry.own"
+	PermTransactionReverse    Permission = "transaction.reverse"
+	PermAuditRead             Permission = "audit.read"
+	PermLedgerRead            Permission = "ledger.read"
+	PermReconcile             Permission = "reconcile"
+	PermWebhookManage         Permission = "webhook.manage"
+	PermPaymentCallbackRead   Permission = "payment_callback.read"
+	PermBankStatementManage   Permission = "bank_statement.manage"
+	PermAutodebitManage       Permission = "autodebit.manage"
+	PermAutodebitManageOwn    Permission = "autodebit.manage.own"
+	PermNotificationRead      Permission = "notification.read"
+	PermNotificationReadOwn   Permission = "notification.read.own"
+	PermNotificationManage    Permission = "notification.manage"
+	PermNotificationManageOwn Permission = "notification.manage.own"
+	PermReportRead            Permission = "report.read"
+	PermHolidayManage         Permission = "holiday.manage"
+)
+
+var rolePermissions = map[Role][]Permission{
+	RoleBorrower: {
+		PermUserReadOwn,
+		PermLoanReadOwn,
+		PermLoanCreateOwn,
+		PermTransactionInquiryOwn,
+		PermTransactionCreateOwn,
+		PermAutodebitManageOwn,
+		PermNotificationReadOwn,
+		PermNotificationManageOwn,
+	},
+	RoleCreditOfficer: {
+		PermUserRead,
+		PermLoanRead,
+		PermLoanCreate,
+		PermLoanApprove,
+		PermPaymentRead,
+		PermTransactionInquiry,
+	},
+	RoleCollector: {
+		PermUserRead,
+		PermLoanRead,
+		PermPaymentRead,
+		PermTransactionInquiry,
+		PermTransactionCreate,
+		PermNotificationRead,
+	},
+	RoleFinance: {
+		PermUserRead,
+		PermLoanRead,
+		PermPaymentRead,
+		PermTransactionInquiry,
+		PermTransactionCreate,
+		PermTransactionReverse,
+		PermLedgerRead,
+		PermPaymentCallbackRead,
+		PermBankStatementManage,
+		PermAutodebitManage,
+		PermNotificationRead,
+		PermReportRead,
+	},
+	RoleAdmin: {
+		PermUserRead,
+		PermUserManageRole,
+		PermLoanRead,
+		PermLoanCreate,
+		PermLoanApprove,
+		PermPaymentRead,
+		PermTransactionInquiry,
+		PermTransactionCreate,
+		PermTransactionReverse,
+		PermAuditRead,
+		PermLedgerRead,
+		PermReconcile,
+		PermWebhookManage,
+		PermPaymentCallbackRead,
+		PermBankStatementManage,
+		PermAutodebitManage,
+		PermNotificationRead,
+		PermNotificationManage,
+		PermReportRead,
+		PermHolidayManage,
+	},
+	RolePartner: {
+		PermLoanRead,
+		PermTransactionInquiry,
+		PermTransactionCreate,
+	},
+}
+
+func (it Role) Permissions() []Permission {
+	return rolePermissions[it]
+}
+
+func (it Role) Can(permission Permission) bool {
+	for _, p := range rolePermissions[it] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAssignable reports whether the role can be given to a user
+func (it Role) IsAssignable() bool {
+	return it >= RoleBorrower && it <= RoleAdmin
+}
@@ -0,0 +1,49 @@
+package entity
+
+import "time"
+
+type StatementFormat string
+
+const (
+	StatementFormatPDF StatementFormat = "pdf"
+	StatementFormatCSV StatementFormat = "csv"
+)
+
+type StatementEntryType string
+
+const (
+	StatementEntryDisbursement    StatementEntryType = "disbursement"
+	StatementEntryPenalty         StatementEntryType = "penalty"
+	StatementEntryPayment         StatementEntryType = "payment"
+	StatementEntryReversal        StatementEntryType = "reversal"
+	StatementEntryPenaltyReversal StatementEntryType = "penalty_reversal"
+	// StatementEntryRestructure moves the balance from the closed installments to the new schedule
+	StatementEntryRestructure StatementEntryType = "restructure"
+)
+
+// StatementEntry moves the outstanding balance, debits raise what the borrower owes and credits lower it
+type StatementEntry struct {
+	Date          time.Time          `json:"date"`
+	Type          StatementEntryType `json:"type"`
+	TransactionID int64              `json:"transaction_id,omitempty"`
+	// Installments are the payment numbers a payment settled, unknown once the payment is reversed
+	Installments []int32 `json:"installments,omitempty"`
+	Debit        float64 `json:"debit"`
+	Credit       float64 `json:"credit"`
+	Balance      float64 `json:"balance"`
+}
+
+// LoanStatement is the activity of a loan between From and To, both inclusive
+type LoanStatement struct {
+	Loan           *Loan             `json:"loan"`
+	From           time.Time         `json:"from"`
+	To             time.Time         `json:"to"`
+	GeneratedAt    time.Time         `json:"generated_at"`
+	OpeningBalance float64           `json:"opening_balance"`
+	ClosingBalance float64           `json:"closing_balance"`
+	TotalPaid      float64           `json:"total_paid"`
+	TotalPenalty   float64           `json:"total_penalty"`
+	Schedule       []*Payment        `json:"schedule"`
+	Transactions   []*Transaction    `json:"transactions"`
+	Entries        []*StatementEntry `json:"entries"`
+}
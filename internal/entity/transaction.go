@@ -5,16 +5,53 @@ import "time"
 type TransactionStatus int8
 
 const (
-	TransactionStatusActive TransactionStatus = 1
-	TransactionStatusPaid   TransactionStatus = 99
+	TransactionStatusActive   TransactionStatus = 1
+	TransactionStatusReversed TransactionStatus = 98
+	TransactionStatusPaid     TransactionStatus = 99
 )
 
+func (it TransactionStatus) String() string {
+	switch it {
+	case TransactionStatusActive:
+		return "Active"
+	case TransactionStatusReversed:
+		return "Reversed"
+	case TransactionStatusPaid:
+		return "Paid"
+	default:
+		return "Unknown"
+	}
+}
+
+// Key identifies the status label in the i18n catalogs
+func (it TransactionStatus) Key() string {
+	switch it {
+	case TransactionStatusActive:
+		return "transaction_status.active"
+	case TransactionStatusReversed:
+		return "transaction_status.reversed"
+	case TransactionStatusPaid:
+		return "transaction_status.paid"
+	default:
+		return "transaction_status.unknown"
+	}
+}
+
 type TransactionInquiry struct {
 	LoanID     int64      `json:"loan_id"`
 	AmountDue  float64    `json:"amount_due"`
-	DueDate    time.Time  `json:"due_date"`
+	DueDate    Date       `json:"due_date"`
 	LoanDetail *Loan      `json:"loan_detail"`
 	Bills      []*Payment `json:"payments"`
+	// InstallmentsAhead are the last bills, paid ahead of schedule
+	InstallmentsAhead int `json:"installments_ahead"`
+}
+
+// PayAhead adds future installments after the bills due, to be paid ahead of schedule: the next Installments of
+// them, or as many as Amount pays in full. The zero value pays the bills due only
+type PayAhead struct {
+	Installments int     `query:"installments" json:"installments" validate:"gte=0,excluded_with=Amount"`
+	Amount       float64 `query:"amount" json:"amount" validate:"gte=0"`
 }
 
 type Transaction struct {
@@ -24,9 +61,49 @@ type Transaction struct {
 	Status      TransactionStatus `db:"status"`
 	PaidAt      *time.Time        `db:"paid_at"`
 	CreatedAt   time.Time         `db:"created_at"`
+	// Channel and ExternalID identify a payment reported by a gateway, a gateway payment is only posted once
+	Channel    string `db:"channel"`
+	ExternalID string `db:"external_id"`
+	// Rounding is the cash received beyond the bills, negative when short of them, as gateways and banks settle
+	// the amount due rounded to whole units
+	Rounding float64 `db:"rounding"`
+	// Allocations split a transaction paying the loans of a user, they're only set on it when it's created
+	Allocations []*TransactionAllocation `db:"-" json:",omitempty"`
 }
 
+// TransactionAllocation is the part of a transaction that paid the bills of one loan
+type TransactionAllocation struct {
+	ID            int64     `db:"id" json:"id"`
+	TransactionID int64     `db:"transaction_id" json:"transaction_id"`
+	LoanID        int64     `db:"loan_id" json:"loan_id"`
+	Amount        float64   `db:"amount" json:"amount"`
+	CreatedAt     time.Time `db:"created_at" json:"created_at"`
+}
+
+// CreateTransactionPayload pays a loan by its ID or by its virtual account, exactly one of them is required
 type CreateTransactionPayload struct {
-	LoanID int64   `json:"loan_id"`
-	Amount float64 `json:"amount"`
+	LoanID         int64   `json:"loan_id" validate:"gte=0"`
+	VirtualAccount string  `json:"virtual_account" validate:"omitempty,numeric"`
+	Amount         float64 `json:"amount" validate:"gt=0"`
+	// Installments pays that many future installments ahead, otherwise Amount pays as many as it covers
+	Installments int    `json:"installments" validate:"gte=0"`
+	Channel      string `json:"-"`
+	ExternalID   string `json:"-"`
+	// Received is the cash a gateway or bank settled, the amount due rounded to whole units, when it isn't Amount
+	Received float64 `json:"-"`
+}
+
+// UserInquiry gathers the bills due of every active loan of a user, to be paid in a single transaction
+type UserInquiry struct {
+	UserID    int64                 `json:"user_id"`
+	AmountDue float64               `json:"amount_due"`
+	Loans     []*TransactionInquiry `json:"loans"`
+}
+
+// CreateUserTransactionPayload pays the bills due of all the active loans of a user at once
+type CreateUserTransactionPayload struct {
+	UserID     int64   `json:"user_id" validate:"gt=0"`
+	Amount     float64 `json:"amount" validate:"gt=0"`
+	Channel    string  `json:"-"`
+	ExternalID string  `json:"-"`
 }
@@ -5,15 +5,25 @@ import (
 )
 
 type User struct {
-	ID        int64     `db:"id"`
-	Email     string    `db:"email"`
-	Name      string    `db:"name"`
-	CreatedAt time.Time `db:"created_at"`
+	ID           int64     `db:"id"`
+	Email        string    `db:"email" validate:"required,email,max=255"`
+	Name         string    `db:"name" validate:"required,max=255"`
+	PasswordHash string    `db:"password_hash" json:"-"`
+	Role         Role      `db:"role"`
+	CreatedAt    time.Time `db:"created_at"`
+
+	// Password is only set on registration and is hashed before being stored
+	Password string `db:"-" json:"-" validate:"omitempty,min=8,max=72"`
 }
 
 type CreateUserPayload struct {
-	Email string `db:"email"`
-	Name  string `db:"name"`
+	Email    string `json:"email" form:"email" validate:"required,email,max=255"`
+	Name     string `json:"name" form:"name" validate:"required,max=255"`
+	Password string `json:"password" form:"password" validate:"omitempty,min=8,max=72"`
+}
+
+type UpdateUserRolePayload struct {
+	Role Role `json:"role" form:"role" validate:"required"`
 }
 
 // func NewUser(email string, name string) *User {
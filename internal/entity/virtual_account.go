@@ -0,0 +1,56 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+)
+
+type VirtualAccountStatus int8
+
+const (
+	VirtualAccountStatusActive VirtualAccountStatus = 1
+	// VirtualAccountStatusInactive accounts no longer accept payments, the loan is paid off
+	VirtualAccountStatusInactive VirtualAccountStatus = 99
+)
+
+func (it VirtualAccountStatus) String() string {
+	switch it {
+	case VirtualAccountStatusActive:
+		return "Active"
+	case VirtualAccountStatusInactive:
+		return "Inactive"
+	default:
+		return "Unknown"
+	}
+}
+
+// NewVirtualAccountNumber builds the virtual account of a loan: the prefix, the zero padded loan ID and a Luhn
+// check digit, so it is unique per loan and mistyped numbers are caught before they're looked up
+func NewVirtualAccountNumber(prefix string, loanID int64) string {
+	number := fmt.Sprintf("%s%010d", prefix, loanID)
+	return number + string(rune('0'+luhnCheckDigit(number)))
+}
+
+// ValidVirtualAccountNumber tells whether number is all digits and its check digit matches
+func ValidVirtualAccountNumber(number string) bool {
+	if len(number) < 2 || strings.Trim(number, "0123456789") != "" {
+		return false
+	}
+	return luhnCheckDigit(number[:len(number)-1]) == int(number[len(number)-1]-'0')
+}
+
+func luhnCheckDigit(digits string) int {
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		digit := int(digits[i] - '0')
+		// double every second digit from the right, the check digit will sit to its right
+		if (len(digits)-1-i)%2 == 0 {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+	}
+	return (10 - sum%10) % 10
+}
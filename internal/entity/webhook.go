@@ -0,0 +1,145 @@
+package entity
+
+import (
+	"strings"
+	"time"
+)
+
+// EventTypes lists every event a webhook can subscribe to
+var EventTypes = []EventType{
+	EventLoanCreated,
+	EventPaymentPosted,
+	EventLoanPaidOff,
+	EventLoanBecameDelinquent,
+	EventLoanRestructured,
+}
+
+func (it EventType) IsValid() bool {
+	for _, eventType := range EventTypes {
+		if it == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+type WebhookStatus int8
+
+const (
+	WebhookStatusActive   WebhookStatus = 1
+	WebhookStatusDisabled WebhookStatus = 99
+)
+
+func (it WebhookStatus) String() string {
+	switch it {
+	case WebhookStatusActive:
+		return "Active"
+	case WebhookStatusDisabled:
+		return "Disabled"
+	default:
+		return "Unknown"
+	}
+}
+
+// WebhookSubscription posts the events of the subscribed types to URL, signed with Secret
+type WebhookSubscription struct {
+	ID         int64         `db:"id" json:"id"`
+	URL        string        `db:"url" json:"url"`
+	EventTypes []EventType   `db:"event_types" json:"event_types"`
+	Secret     string        `db:"secret" json:"-"`
+	Status     WebhookStatus `db:"status" json:"status"`
+	CreatedAt  time.Time     `db:"created_at" json:"created_at"`
+	UpdatedAt  *time.Time    `db:"updated_at" json:"updated_at,omitempty"`
+}
+
+func (s *WebhookSubscription) Accepts(eventType EventType) bool {
+	if s.Status != WebhookStatusActive {
+		return false
+	}
+	for _, it := range s.EventTypes {
+		if it == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// JoinEventTypes and SplitEventTypes convert the subscribed types to and from their stored form
+func JoinEventTypes(eventTypes []EventType) string {
+	names := make([]string, len(eventTypes))
+	for i, eventType := range eventTypes {
+		names[i] = string(eventType)
+	}
+	return strings.Join(names, ",")
+}
+
+func SplitEventTypes(value string) []EventType {
+	var eventTypes []EventType
+	for _, name := range strings.Split(value, ",") {
+		if name != "" {
+			eventTypes = append(eventTypes, EventType(name))
+		}
+	}
+	return eventTypes
+}
+
+// CreatedWebhookSubscription holds the signing secret, which is only shown once at creation
+type CreatedWebhookSubscription struct {
+	Subscription *WebhookSubscription `json:"subscription"`
+	Secret       string               `json:"secret"`
+}
+
+type WebhookSubscriptionPayload struct {
+	URL        string      `json:"url" validate:"required,http_url"`
+	EventTypes []EventType `json:"event_types" validate:"required,min=1"`
+	// Secret is generated when empty
+	Secret string `json:"secret" validate:"omitempty,min=16"`
+}
+
+type UpdateWebhookSubscriptionPayload struct {
+	URL        string        `json:"url" validate:"omitempty,http_url"`
+	EventTypes []EventType   `json:"event_types"`
+	Status     WebhookStatus `json:"status" validate:"omitempty,oneof=1 99"`
+}
+
+type WebhookDeliveryStatus int8
+
+const (
+	WebhookDeliveryStatusPending WebhookDeliveryStatus = 1
+	// WebhookDeliveryStatusFailed deliveries ran out of attempts, they can still be redelivered by hand
+	WebhookDeliveryStatusFailed    WebhookDeliveryStatus = 98
+	WebhookDeliveryStatusDelivered WebhookDeliveryStatus = 99
+)
+
+func (it WebhookDeliveryStatus) String() string {
+	switch it {
+	case WebhookDeliveryStatusPending:
+		return "Pending"
+	case WebhookDeliveryStatusFailed:
+		return "Failed"
+	case WebhookDeliveryStatusDelivered:
+		return "Delivered"
+	default:
+		return "Unknown"
+	}
+}
+
+// WebhookDelivery logs the attempts to send one event to one subscription
+type WebhookDelivery struct {
+	ID             int64                 `db:"id" json:"id"`
+	SubscriptionID int64                 `db:"subscription_id" json:"subscription_id"`
+	EventID        int64                 `db:"event_id" json:"event_id"`
+	EventType      EventType             `db:"event_type" json:"event_type"`
+	Status         WebhookDeliveryStatus `db:"status" json:"status"`
+	Attempts       int                   `db:"attempts" json:"attempts"`
+	ResponseStatus int                   `db:"response_status" json:"response_status,omitempty"`
+	LastError      string                `db:"last_error" json:"last_error,omitempty"`
+	NextAttemptAt  time.Time             `db:"next_attempt_at" json:"next_attempt_at"`
+	CreatedAt      time.Time             `db:"created_at" json:"created_at"`
+	DeliveredAt    *time.Time            `db:"delivered_at" json:"delivered_at,omitempty"`
+}
+
+type WebhookDeliveryFilter struct {
+	Status WebhookDeliveryStatus `query:"status" validate:"omitempty,oneof=1 98 99"`
+	Limit  int                   `query:"limit" validate:"omitempty,gte=1,lte=500"`
+}
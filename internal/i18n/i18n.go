@@ -0,0 +1,106 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Locale string
+
+const (
+	English    Locale = "en"
+	Indonesian Locale = "id"
+
+	DefaultLocale = English
+)
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+var catalogs = loadCatalogs()
+
+func loadCatalogs() map[Locale]map[string]string {
+	result := map[Locale]map[string]string{}
+	for _, locale := range Supported() {
+		content, err := localeFiles.ReadFile(fmt.Sprintf("locales/%s.json", locale))
+		if err != nil {
+			panic(err)
+		}
+
+		catalog := map[string]string{}
+		if err := json.Unmarshal(content, &catalog); err != nil {
+			panic(fmt.Errorf("invalid catalog %s: %w", locale, err))
+		}
+		result[locale] = catalog
+	}
+	return result
+}
+
+func Supported() []Locale {
+	return []Locale{English, Indonesian}
+}
+
+// Lookup returns the message for key, falling back to the default locale
+func Lookup(locale Locale, key string) (string, bool) {
+	if message, ok := catalogs[locale][key]; ok {
+		return message, true
+	}
+	message, ok := catalogs[DefaultLocale][key]
+	return message, ok
+}
+
+// T translates key and fills the {name} placeholders from params, unknown keys are returned as is
+func T(locale Locale, key string, params map[string]string) string {
+	message, ok := Lookup(locale, key)
+	if !ok {
+		return key
+	}
+
+	for name, value := range params {
+		message = strings.ReplaceAll(message, "{"+name+"}", value)
+	}
+	return message
+}
+
+// ParseAcceptLanguage picks the best supported locale from an Accept-Language header, e.g. "id-ID,id;q=0.9,en;q=0.8"
+func ParseAcceptLanguage(header string) Locale {
+	type candidate struct {
+		locale Locale
+		q      float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+
+		primary, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		for _, locale := range Supported() {
+			if primary == string(locale) && q > 0 {
+				candidates = append(candidates, candidate{locale: locale, q: q})
+			}
+		}
+	}
+
+	if len(candidates) == 0 {
+		return DefaultLocale
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	return candidates[0].locale
+}
@@ -0,0 +1,42 @@
+package i18n
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatalogsAreComplete(t *testing.T) {
+	for _, locale := range Supported() {
+		for key := range catalogs[DefaultLocale] {
+			_, ok := catalogs[locale][key]
+			assert.True(t, ok, "%s is missing %s", locale, key)
+		}
+		for key := range catalogs[locale] {
+			_, ok := catalogs[DefaultLocale][key]
+			assert.True(t, ok, "%s has unknown key %s", locale, key)
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	assert.Equal(t, "Pinjaman tidak ditemukan", T(Indonesian, "LOAN_NOT_FOUND", nil))
+	assert.Equal(t, "amount must be greater than 0", T(English, "validation.gt", map[string]string{"field": "amount", "param": "0"}))
+	assert.Equal(t, "UNKNOWN_KEY", T(Indonesian, "UNKNOWN_KEY", nil))
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := map[string]Locale{
+		"":                        English,
+		"id":                      Indonesian,
+		"id-ID,id;q=0.9,en;q=0.8": Indonesian,
+		"en-US,id;q=0.5":          English,
+		"fr-FR,id;q=0.7,en;q=0.3": Indonesian,
+		"fr-FR":                   English,
+		"id;q=0,en":               English,
+	}
+
+	for header, expected := range tests {
+		assert.Equal(t, expected, ParseAcceptLanguage(header), header)
+	}
+}
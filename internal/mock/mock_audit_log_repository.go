@@ -0,0 +1,26 @@
+package mock
+
+import (
+	"context"
+	"database/sql"
+	"loan-management/internal/entity"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAuditLogRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditLogRepository) CreateAuditLog(tx *sql.Tx, log *entity.AuditLog) error {
+	args := m.Called(tx, log)
+	return args.Error(0)
+}
+
+func (m *MockAuditLogRepository) GetAuditLogs(ctx context.Context, filter entity.AuditLogFilter) ([]*entity.AuditLog, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.AuditLog), args.Error(1)
+}
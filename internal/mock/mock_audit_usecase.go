@@ -0,0 +1,26 @@
+package mock
+
+import (
+	"context"
+	"database/sql"
+	"loan-management/internal/entity"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAuditUsecase struct {
+	mock.Mock
+}
+
+func (m *MockAuditUsecase) Record(ctx context.Context, tx *sql.Tx, action entity.AuditAction, entityType string, entityID int64, before any, after any) error {
+	args := m.Called(ctx, tx, action, entityType, entityID, before, after)
+	return args.Error(0)
+}
+
+func (m *MockAuditUsecase) GetAuditLogs(ctx context.Context, filter entity.AuditLogFilter) ([]*entity.AuditLog, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.AuditLog), args.Error(1)
+}
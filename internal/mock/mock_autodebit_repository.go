@@ -0,0 +1,95 @@
+package mock
+
+import (
+	"context"
+	"database/sql"
+	"loan-management/internal/entity"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAutodebitRepository struct {
+	mock.Mock
+}
+
+func (m *MockAutodebitRepository) BeginTx() (*sql.Tx, error) {
+	args := m.Called()
+	if args.Get(0) != nil {
+		return args.Get(0).(*sql.Tx), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAutodebitRepository) CreateMandate(tx *sql.Tx, mandate *entity.AutodebitMandate) error {
+	args := m.Called(tx, mandate)
+	return args.Error(0)
+}
+
+func (m *MockAutodebitRepository) UpdateMandate(tx *sql.Tx, mandate *entity.AutodebitMandate) error {
+	args := m.Called(tx, mandate)
+	return args.Error(0)
+}
+
+func (m *MockAutodebitRepository) GetMandateByID(ctx context.Context, id int64) (*entity.AutodebitMandate, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entity.AutodebitMandate), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAutodebitRepository) GetActiveMandateByLoanID(ctx context.Context, loanID int64) (*entity.AutodebitMandate, error) {
+	args := m.Called(ctx, loanID)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entity.AutodebitMandate), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAutodebitRepository) GetMandates(ctx context.Context, filter entity.MandateFilter) ([]*entity.AutodebitMandate, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entity.AutodebitMandate), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAutodebitRepository) CreateAttempt(ctx context.Context, attempt *entity.CollectionAttempt) error {
+	args := m.Called(ctx, attempt)
+	return args.Error(0)
+}
+
+func (m *MockAutodebitRepository) UpdateAttempt(ctx context.Context, attempt *entity.CollectionAttempt) error {
+	args := m.Called(ctx, attempt)
+	return args.Error(0)
+}
+
+func (m *MockAutodebitRepository) GetAttemptByPaymentID(ctx context.Context, mandateID int64, paymentID int64) (*entity.CollectionAttempt, error) {
+	args := m.Called(ctx, mandateID, paymentID)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entity.CollectionAttempt), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAutodebitRepository) GetAttemptsByMandateID(ctx context.Context, mandateID int64, filter entity.CollectionAttemptFilter) ([]*entity.CollectionAttempt, error) {
+	args := m.Called(ctx, mandateID, filter)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entity.CollectionAttempt), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAutodebitRepository) GetPendingAttempts(ctx context.Context, dueBefore time.Time, limit int) ([]*entity.CollectionAttempt, error) {
+	args := m.Called(ctx, dueBefore, limit)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entity.CollectionAttempt), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAutodebitRepository) CancelPendingAttempts(tx *sql.Tx, mandateID int64, reason string, completedAt time.Time) error {
+	args := m.Called(tx, mandateID, reason, completedAt)
+	return args.Error(0)
+}
@@ -0,0 +1,76 @@
+package mock
+
+import (
+	"context"
+	"database/sql"
+	"loan-management/internal/entity"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockBankStatementRepository struct {
+	mock.Mock
+}
+
+func (m *MockBankStatementRepository) BeginTx() (*sql.Tx, error) {
+	args := m.Called()
+	if args.Get(0) != nil {
+		return args.Get(0).(*sql.Tx), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBankStatementRepository) CreateStatement(tx *sql.Tx, statement *entity.BankStatement) error {
+	args := m.Called(tx, statement)
+	return args.Error(0)
+}
+
+func (m *MockBankStatementRepository) CreateLines(tx *sql.Tx, lines []*entity.BankStatementLine) error {
+	args := m.Called(tx, lines)
+	return args.Error(0)
+}
+
+func (m *MockBankStatementRepository) UpdateLine(tx *sql.Tx, line *entity.BankStatementLine) error {
+	args := m.Called(tx, line)
+	return args.Error(0)
+}
+
+func (m *MockBankStatementRepository) GetStatementByID(ctx context.Context, id int64) (*entity.BankStatement, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entity.BankStatement), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBankStatementRepository) GetStatements(ctx context.Context, limit int) ([]*entity.BankStatement, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entity.BankStatement), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBankStatementRepository) GetLineByID(ctx context.Context, id int64) (*entity.BankStatementLine, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entity.BankStatementLine), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBankStatementRepository) GetLineByExternalID(ctx context.Context, account string, externalID string) (*entity.BankStatementLine, error) {
+	args := m.Called(ctx, account, externalID)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entity.BankStatementLine), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBankStatementRepository) GetLines(ctx context.Context, filter entity.BankStatementLineFilter) ([]*entity.BankStatementLine, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entity.BankStatementLine), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
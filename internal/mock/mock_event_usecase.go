@@ -0,0 +1,18 @@
+package mock
+
+import (
+	"context"
+	"database/sql"
+	"loan-management/internal/entity"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockEventUsecase struct {
+	mock.Mock
+}
+
+func (m *MockEventUsecase) Publish(ctx context.Context, tx *sql.Tx, eventType entity.EventType, aggregateType string, aggregateID int64, payload any) error {
+	args := m.Called(ctx, tx, eventType, aggregateType, aggregateID, payload)
+	return args.Error(0)
+}
@@ -0,0 +1,47 @@
+package mock
+
+import (
+	"context"
+	"database/sql"
+	"loan-management/internal/entity"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockHolidayRepository struct {
+	mock.Mock
+}
+
+func (m *MockHolidayRepository) CreateHoliday(tx *sql.Tx, holiday *entity.Holiday) error {
+	args := m.Called(tx, holiday)
+	return args.Error(0)
+}
+
+func (m *MockHolidayRepository) DeleteHoliday(tx *sql.Tx, id int64) error {
+	args := m.Called(tx, id)
+	return args.Error(0)
+}
+
+func (m *MockHolidayRepository) GetHolidayByID(ctx context.Context, id int64) (*entity.Holiday, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entity.Holiday), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockHolidayRepository) GetAllHolidays(ctx context.Context) ([]*entity.Holiday, error) {
+	args := m.Called(ctx)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entity.Holiday), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockHolidayRepository) BeginTx() (*sql.Tx, error) {
+	args := m.Called()
+	if args.Get(0) != nil {
+		return args.Get(0).(*sql.Tx), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
@@ -0,0 +1,47 @@
+package mock
+
+import (
+	"context"
+	"loan-management/internal/calendar"
+	"loan-management/internal/entity"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockHolidayUsecase struct {
+	mock.Mock
+}
+
+func (m *MockHolidayUsecase) GetHolidays(ctx context.Context) ([]*entity.Holiday, error) {
+	args := m.Called(ctx)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entity.Holiday), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockHolidayUsecase) CreateHoliday(ctx context.Context, payload *entity.HolidayPayload) (*entity.Holiday, error) {
+	args := m.Called(ctx, payload)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entity.Holiday), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockHolidayUsecase) DeleteHoliday(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockHolidayUsecase) ImportHolidays(ctx context.Context, payloads []entity.HolidayPayload) (int, error) {
+	args := m.Called(ctx, payloads)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockHolidayUsecase) Calendar(ctx context.Context) (*calendar.Calendar, error) {
+	args := m.Called(ctx)
+	if args.Get(0) != nil {
+		return args.Get(0).(*calendar.Calendar), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
@@ -0,0 +1,67 @@
+package mock
+
+import (
+	"context"
+	"database/sql"
+	"loan-management/internal/entity"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLedgerRepository struct {
+	mock.Mock
+}
+
+func (m *MockLedgerRepository) CreateJournalEntry(tx *sql.Tx, entry *entity.JournalEntry) error {
+	args := m.Called(tx, entry)
+	return args.Error(0)
+}
+
+func (m *MockLedgerRepository) GetJournalEntriesByReference(ctx context.Context, referenceType string, referenceID int64) ([]*entity.JournalEntry, error) {
+	args := m.Called(ctx, referenceType, referenceID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.JournalEntry), args.Error(1)
+}
+
+func (m *MockLedgerRepository) GetJournalEntriesByLoanID(ctx context.Context, loanID int64) ([]*entity.JournalEntry, error) {
+	args := m.Called(ctx, loanID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.JournalEntry), args.Error(1)
+}
+
+func (m *MockLedgerRepository) GetAccounts(ctx context.Context) ([]*entity.Account, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Account), args.Error(1)
+}
+
+func (m *MockLedgerRepository) GetAccountBalances(ctx context.Context, asOf time.Time) (map[string]float64, error) {
+	args := m.Called(ctx, asOf)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]float64), args.Error(1)
+}
+
+func (m *MockLedgerRepository) GetLoanBalances(ctx context.Context, accountCode string) (map[int64]float64, error) {
+	args := m.Called(ctx, accountCode)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int64]float64), args.Error(1)
+}
+
+func (m *MockLedgerRepository) GetLoanAccountBalances(tx *sql.Tx, loanID int64) (map[string]float64, error) {
+	args := m.Called(tx, loanID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]float64), args.Error(1)
+}
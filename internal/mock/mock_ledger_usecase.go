@@ -0,0 +1,47 @@
+package mock
+
+import (
+	"context"
+	"database/sql"
+	"loan-management/internal/entity"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLedgerUsecase struct {
+	mock.Mock
+}
+
+func (m *MockLedgerUsecase) PostDisbursement(ctx context.Context, tx *sql.Tx, loan *entity.Loan) error {
+	args := m.Called(ctx, tx, loan)
+	return args.Error(0)
+}
+
+func (m *MockLedgerUsecase) PostRepayment(ctx context.Context, tx *sql.Tx, loanID int64, trx *entity.Transaction, payments []*entity.Payment) error {
+	args := m.Called(ctx, tx, loanID, trx, payments)
+	return args.Error(0)
+}
+
+func (m *MockLedgerUsecase) PostReversal(ctx context.Context, tx *sql.Tx, referenceType string, referenceID int64) error {
+	args := m.Called(ctx, tx, referenceType, referenceID)
+	return args.Error(0)
+}
+
+func (m *MockLedgerUsecase) PostAdjustment(ctx context.Context, tx *sql.Tx, loanID int64, amount float64) error {
+	args := m.Called(ctx, tx, loanID, amount)
+	return args.Error(0)
+}
+
+func (m *MockLedgerUsecase) GetTrialBalance(ctx context.Context, asOf time.Time) (*entity.TrialBalance, error) {
+	args := m.Called(ctx, asOf)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.TrialBalance), args.Error(1)
+}
+
+func (m *MockLedgerUsecase) PostCapitalization(ctx context.Context, tx *sql.Tx, loanID int64, referenceType string, referenceID int64, amount float64) error {
+	args := m.Called(ctx, tx, loanID, referenceType, referenceID, amount)
+	return args.Error(0)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"loan-management/internal/entity"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -44,6 +45,14 @@ func (m *MockLoanRepository) GetAllLoans(ctx context.Context) ([]*entity.Loan, e
 	return nil, args.Error(1)
 }
 
+func (m *MockLoanRepository) GetLoanByIDForUpdate(tx *sql.Tx, id int64) (*entity.Loan, error) {
+	args := m.Called(tx, id)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entity.Loan), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 func (m *MockLoanRepository) GetLoansByUserID(ctx context.Context, userId int64, status *entity.LoanStatus) ([]*entity.Loan, error) {
 	args := m.Called(ctx, userId, status)
 	if args.Get(0) != nil {
@@ -56,3 +65,57 @@ func (m *MockLoanRepository) UpdateLoanOutstanding(tx *sql.Tx, outstanding float
 	args := m.Called(tx, outstanding, loanID)
 	return args.Error(0)
 }
+
+func (m *MockLoanRepository) ApproveLoan(tx *sql.Tx, loanID int64, billingStartDate time.Time, disbursedAt time.Time) error {
+	args := m.Called(tx, loanID, billingStartDate, disbursedAt)
+	return args.Error(0)
+}
+
+func (m *MockLoanRepository) RejectLoan(tx *sql.Tx, loanID int64) error {
+	args := m.Called(tx, loanID)
+	return args.Error(0)
+}
+
+func (m *MockLoanRepository) GetLoanBalances(ctx context.Context) ([]*entity.LoanBalance, error) {
+	args := m.Called(ctx)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entity.LoanBalance), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockLoanRepository) UpdateLoanDelinquency(tx *sql.Tx, loanID int64, delinquentSince *time.Time) error {
+	args := m.Called(tx, loanID, delinquentSince)
+	return args.Error(0)
+}
+
+func (m *MockLoanRepository) UpdateLoanTerms(tx *sql.Tx, loanID int64, interest float64, tenure int) error {
+	args := m.Called(tx, loanID, interest, tenure)
+	return args.Error(0)
+}
+
+func (m *MockLoanRepository) GetLoanByVirtualAccount(ctx context.Context, number string) (*entity.Loan, error) {
+	args := m.Called(ctx, number)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entity.Loan), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockLoanRepository) UpdateVirtualAccount(tx *sql.Tx, loanID int64, number string, status entity.VirtualAccountStatus) error {
+	args := m.Called(tx, loanID, number, status)
+	return args.Error(0)
+}
+
+func (m *MockLoanRepository) CreateRestructure(tx *sql.Tx, restructure *entity.LoanRestructure) error {
+	args := m.Called(tx, restructure)
+	return args.Error(0)
+}
+
+func (m *MockLoanRepository) GetRestructuresByLoanID(ctx context.Context, loanID int64) ([]*entity.LoanRestructure, error) {
+	args := m.Called(ctx, loanID)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entity.LoanRestructure), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"loan-management/internal/entity"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -28,6 +29,14 @@ func (m *MockLoanUsecase) GetLoanByID(ctx context.Context, id int64, status *ent
 	return nil, args.Error(1)
 }
 
+func (m *MockLoanUsecase) GetLoanByIDForUpdate(tx *sql.Tx, id int64) (*entity.Loan, error) {
+	args := m.Called(tx, id)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entity.Loan), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 func (m *MockLoanUsecase) GetLoansByUserID(ctx context.Context, userID int64, status entity.LoanStatus) ([]*entity.Loan, error) {
 	args := m.Called(ctx, userID, status)
 	if args.Get(0) != nil {
@@ -58,3 +67,21 @@ func (m *MockLoanUsecase) UpdateLoanOutstanding(tx *sql.Tx, outstanding float64,
 	args := m.Called(tx, outstanding, loanID)
 	return args.Error(0)
 }
+
+func (m *MockLoanUsecase) UpdateLoanDelinquency(tx *sql.Tx, loanID int64, delinquentSince *time.Time) error {
+	args := m.Called(tx, loanID, delinquentSince)
+	return args.Error(0)
+}
+
+func (m *MockLoanUsecase) GetLoanByVirtualAccount(ctx context.Context, number string) (*entity.Loan, error) {
+	args := m.Called(ctx, number)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entity.Loan), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockLoanUsecase) UpdateVirtualAccountStatus(tx *sql.Tx, loan *entity.Loan, status entity.VirtualAccountStatus) error {
+	args := m.Called(tx, loan, status)
+	return args.Error(0)
+}
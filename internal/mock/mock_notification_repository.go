@@ -0,0 +1,68 @@
+package mock
+
+import (
+	"context"
+	"database/sql"
+	"loan-management/internal/entity"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockNotificationRepository struct {
+	mock.Mock
+}
+
+func (m *MockNotificationRepository) BeginTx() (*sql.Tx, error) {
+	args := m.Called()
+	if args.Get(0) != nil {
+		return args.Get(0).(*sql.Tx), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockNotificationRepository) GetPreferences(ctx context.Context, userID int64) (*entity.NotificationPreferences, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entity.NotificationPreferences), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockNotificationRepository) SavePreferences(tx *sql.Tx, preferences *entity.NotificationPreferences) error {
+	args := m.Called(tx, preferences)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) CreateNotification(ctx context.Context, notification *entity.Notification) error {
+	args := m.Called(ctx, notification)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) UpdateNotification(ctx context.Context, notification *entity.Notification) error {
+	args := m.Called(ctx, notification)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) GetNotificationByDedupeKey(ctx context.Context, dedupeKey string) (*entity.Notification, error) {
+	args := m.Called(ctx, dedupeKey)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entity.Notification), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockNotificationRepository) GetNotifications(ctx context.Context, filter entity.NotificationFilter) ([]*entity.Notification, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entity.Notification), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockNotificationRepository) GetFailedNotifications(ctx context.Context, maxAttempts int, limit int) ([]*entity.Notification, error) {
+	args := m.Called(ctx, maxAttempts, limit)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entity.Notification), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
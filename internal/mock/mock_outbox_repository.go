@@ -0,0 +1,40 @@
+package mock
+
+import (
+	"context"
+	"database/sql"
+	"loan-management/internal/entity"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockOutboxRepository struct {
+	mock.Mock
+}
+
+func (m *MockOutboxRepository) CreateEvent(tx *sql.Tx, event *entity.Event) error {
+	args := m.Called(tx, event)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) GetEventByID(ctx context.Context, id int64) (*entity.Event, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Event), args.Error(1)
+}
+
+func (m *MockOutboxRepository) GetPendingEvents(ctx context.Context, dueBefore time.Time, limit int) ([]*entity.Event, error) {
+	args := m.Called(ctx, dueBefore, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Event), args.Error(1)
+}
+
+func (m *MockOutboxRepository) UpdateEventDelivery(ctx context.Context, event *entity.Event) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
@@ -0,0 +1,38 @@
+package mock
+
+import (
+	"context"
+	"loan-management/internal/entity"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPaymentCallbackRepository struct {
+	mock.Mock
+}
+
+func (m *MockPaymentCallbackRepository) CreatePaymentCallback(ctx context.Context, callback *entity.PaymentCallback) error {
+	args := m.Called(ctx, callback)
+	return args.Error(0)
+}
+
+func (m *MockPaymentCallbackRepository) UpdatePaymentCallback(ctx context.Context, callback *entity.PaymentCallback) error {
+	args := m.Called(ctx, callback)
+	return args.Error(0)
+}
+
+func (m *MockPaymentCallbackRepository) GetPaymentCallbackByID(ctx context.Context, id int64) (*entity.PaymentCallback, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entity.PaymentCallback), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockPaymentCallbackRepository) GetPaymentCallbacks(ctx context.Context, filter entity.PaymentCallbackFilter) ([]*entity.PaymentCallback, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entity.PaymentCallback), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
@@ -34,7 +34,7 @@ func (m *MockPaymentRepository) GetAllPayments(ctx context.Context, status *enti
 	return nil, args.Error(1)
 }
 
-func (m *MockPaymentRepository) GetPaymentsByLoanID(ctx context.Context, loanId int64, status *entity.PaymentStatus, dueBefore *time.Time) ([]*entity.Payment, error) {
+func (m *MockPaymentRepository) GetPaymentsByLoanID(ctx context.Context, loanId int64, status *entity.PaymentStatus, dueBefore *entity.Date) ([]*entity.Payment, error) {
 	args := m.Called(ctx, loanId, status, dueBefore)
 	if payments, ok := args.Get(0).([]*entity.Payment); ok {
 		return payments, args.Error(1)
@@ -46,3 +46,47 @@ func (m *MockPaymentRepository) PayPayment(tx *sql.Tx, paymentId int64, transact
 	args := m.Called(tx, paymentId, transactionId, paidAt)
 	return args.Error(0)
 }
+
+func (m *MockPaymentRepository) GetPaymentsByTransactionID(ctx context.Context, transactionID int64) ([]*entity.Payment, error) {
+	args := m.Called(ctx, transactionID)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entity.Payment), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockPaymentRepository) UnpayPayments(tx *sql.Tx, transactionID int64) error {
+	args := m.Called(tx, transactionID)
+	return args.Error(0)
+}
+
+func (m *MockPaymentRepository) GetOrphanPayments(ctx context.Context) ([]*entity.Payment, error) {
+	args := m.Called(ctx)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entity.Payment), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockPaymentRepository) GetUnpaidPaymentsDueBetween(ctx context.Context, after entity.Date, until entity.Date) ([]*entity.Payment, error) {
+	args := m.Called(ctx, after, until)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entity.Payment), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockPaymentRepository) CloseUnpaidPayments(tx *sql.Tx, loanID int64, closedAt time.Time) error {
+	args := m.Called(tx, loanID, closedAt)
+	return args.Error(0)
+}
+
+func (m *MockPaymentRepository) RescheduleUnpaidPayments(tx *sql.Tx, dueDate entity.Date, newDueDate entity.Date) (int64, error) {
+	args := m.Called(tx, dueDate, newDueDate)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockPaymentRepository) RestoreRescheduledPayments(tx *sql.Tx, originalDueDate entity.Date, dueDate entity.Date) (int64, error) {
+	args := m.Called(tx, originalDueDate, dueDate)
+	return args.Get(0).(int64), args.Error(1)
+}
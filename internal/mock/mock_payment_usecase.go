@@ -34,7 +34,7 @@ func (m *MockPaymentUsecase) GetAllPayments(ctx context.Context, status *entity.
 	return nil, args.Error(1)
 }
 
-func (m *MockPaymentUsecase) GetPaymentsByLoanID(ctx context.Context, loanId int64, status *entity.PaymentStatus, dueBefore *time.Time) ([]*entity.Payment, error) {
+func (m *MockPaymentUsecase) GetPaymentsByLoanID(ctx context.Context, loanId int64, status *entity.PaymentStatus, dueBefore *entity.Date) ([]*entity.Payment, error) {
 	args := m.Called(ctx, loanId, status, dueBefore)
 	if args.Get(0) != nil {
 		return args.Get(0).([]*entity.Payment), args.Error(1)
@@ -51,3 +51,21 @@ func (m *MockPaymentUsecase) PayPayment(tx *sql.Tx, paymentID int64, transaction
 	args := m.Called(tx, paymentID, transactionID, paidAt)
 	return args.Error(0)
 }
+
+func (m *MockPaymentUsecase) GetPaymentsByTransactionID(ctx context.Context, transactionID int64) ([]*entity.Payment, error) {
+	args := m.Called(ctx, transactionID)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entity.Payment), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockPaymentUsecase) UnpayPayments(tx *sql.Tx, transactionID int64) error {
+	args := m.Called(tx, transactionID)
+	return args.Error(0)
+}
+
+func (m *MockPaymentUsecase) CloseUnpaidPayments(tx *sql.Tx, loanID int64, closedAt time.Time) error {
+	args := m.Called(tx, loanID, closedAt)
+	return args.Error(0)
+}
@@ -0,0 +1,42 @@
+package mock
+
+import (
+	"context"
+	"loan-management/internal/entity"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockReportRepository struct {
+	mock.Mock
+}
+
+func (m *MockReportRepository) GetDisbursed(ctx context.Context, asOf time.Time) (int, float64, error) {
+	args := m.Called(ctx, asOf)
+	return args.Int(0), args.Get(1).(float64), args.Error(2)
+}
+
+func (m *MockReportRepository) GetOutstandingByDaysPastDue(ctx context.Context, asOf time.Time, day entity.Date, minDays []int) ([]*entity.OutstandingTotals, error) {
+	args := m.Called(ctx, asOf, day, minDays)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entity.OutstandingTotals), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockReportRepository) GetCollectionsByMonth(ctx context.Context, from time.Time, to time.Time) ([]*entity.CollectionPeriod, error) {
+	args := m.Called(ctx, from, to)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entity.CollectionPeriod), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockReportRepository) GetVintageCohorts(ctx context.Context, from time.Time, to time.Time, asOf time.Time) ([]*entity.VintageCohort, error) {
+	args := m.Called(ctx, from, to, asOf)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entity.VintageCohort), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
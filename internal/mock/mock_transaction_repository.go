@@ -25,6 +25,14 @@ func (m *MockTransactionRepository) GetTransactionByID(ctx context.Context, id i
 	return nil, args.Error(1)
 }
 
+func (m *MockTransactionRepository) GetTransactionByExternalID(ctx context.Context, channel string, externalID string) (*entity.Transaction, error) {
+	args := m.Called(ctx, channel, externalID)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entity.Transaction), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 func (m *MockTransactionRepository) BeginTx() (*sql.Tx, error) {
 	args := m.Called()
 	if args.Get(0) != nil {
@@ -32,3 +40,21 @@ func (m *MockTransactionRepository) BeginTx() (*sql.Tx, error) {
 	}
 	return nil, args.Error(1)
 }
+
+func (m *MockTransactionRepository) UpdateTransactionStatus(tx *sql.Tx, id int64, from entity.TransactionStatus, to entity.TransactionStatus) error {
+	args := m.Called(tx, id, from, to)
+	return args.Error(0)
+}
+
+func (m *MockTransactionRepository) CreateAllocation(tx *sql.Tx, allocation *entity.TransactionAllocation) error {
+	args := m.Called(tx, allocation)
+	return args.Error(0)
+}
+
+func (m *MockTransactionRepository) GetAllocationsByTransactionID(ctx context.Context, transactionID int64) ([]*entity.TransactionAllocation, error) {
+	args := m.Called(ctx, transactionID)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entity.TransactionAllocation), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
@@ -0,0 +1,68 @@
+package mock
+
+import (
+	"context"
+	"loan-management/internal/entity"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTransactionUsecase struct {
+	mock.Mock
+}
+
+func (m *MockTransactionUsecase) InquiryTransaction(ctx context.Context, loanID int64, ahead entity.PayAhead) (*entity.TransactionInquiry, error) {
+	args := m.Called(ctx, loanID, ahead)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entity.TransactionInquiry), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTransactionUsecase) InquiryTransactionByVirtualAccount(ctx context.Context, number string, ahead entity.PayAhead) (*entity.TransactionInquiry, error) {
+	args := m.Called(ctx, number, ahead)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entity.TransactionInquiry), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTransactionUsecase) CreateTransaction(ctx context.Context, trxPayload *entity.CreateTransactionPayload) (*entity.Transaction, error) {
+	args := m.Called(ctx, trxPayload)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entity.Transaction), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTransactionUsecase) InquiryUser(ctx context.Context, userID int64) (*entity.UserInquiry, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entity.UserInquiry), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTransactionUsecase) CreateUserTransaction(ctx context.Context, payload *entity.CreateUserTransactionPayload) (*entity.Transaction, error) {
+	args := m.Called(ctx, payload)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entity.Transaction), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTransactionUsecase) GetTransactionByExternalID(ctx context.Context, channel string, externalID string) (*entity.Transaction, error) {
+	args := m.Called(ctx, channel, externalID)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entity.Transaction), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTransactionUsecase) ReverseTransaction(ctx context.Context, id int64) (*entity.Transaction, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entity.Transaction), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
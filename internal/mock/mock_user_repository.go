@@ -2,6 +2,7 @@ package mock
 
 import (
 	"context"
+	"database/sql"
 	"loan-management/internal/entity"
 
 	"github.com/stretchr/testify/mock"
@@ -11,8 +12,8 @@ type MockUserRepository struct {
 	mock.Mock
 }
 
-func (m *MockUserRepository) CreateUser(ctx context.Context, user *entity.User) error {
-	args := m.Called(ctx, user)
+func (m *MockUserRepository) CreateUser(tx *sql.Tx, user *entity.User) error {
+	args := m.Called(tx, user)
 	return args.Error(0)
 }
 
@@ -39,3 +40,16 @@ func (m *MockUserRepository) GetUserByEmail(ctx context.Context, email string) (
 	}
 	return nil, args.Error(1)
 }
+
+func (m *MockUserRepository) UpdateUserRole(tx *sql.Tx, id int64, role entity.Role) error {
+	args := m.Called(tx, id, role)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) BeginTx() (*sql.Tx, error) {
+	args := m.Called()
+	if args.Get(0) != nil {
+		return args.Get(0).(*sql.Tx), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
@@ -44,3 +44,11 @@ func (m *MockUserUsecase) IsUserDelinquent(ctx context.Context, userID int64) (b
 	args := m.Called(ctx, userID)
 	return args.Bool(0), args.Error(1)
 }
+
+func (m *MockUserUsecase) UpdateUserRole(ctx context.Context, id int64, role entity.Role) (*entity.User, error) {
+	args := m.Called(ctx, id, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.User), args.Error(1)
+}
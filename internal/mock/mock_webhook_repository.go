@@ -0,0 +1,82 @@
+package mock
+
+import (
+	"context"
+	"database/sql"
+	"loan-management/internal/entity"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookRepository) CreateSubscription(tx *sql.Tx, subscription *entity.WebhookSubscription) error {
+	args := m.Called(tx, subscription)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) UpdateSubscription(tx *sql.Tx, subscription *entity.WebhookSubscription) error {
+	args := m.Called(tx, subscription)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) GetSubscriptionByID(ctx context.Context, id int64) (*entity.WebhookSubscription, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetAllSubscriptions(ctx context.Context, status *entity.WebhookStatus) ([]*entity.WebhookSubscription, error) {
+	args := m.Called(ctx, status)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookRepository) CreateDeliveries(ctx context.Context, deliveries []*entity.WebhookDelivery) error {
+	args := m.Called(ctx, deliveries)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) GetDeliveryByID(ctx context.Context, id int64) (*entity.WebhookDelivery, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetDeliveriesBySubscriptionID(ctx context.Context, subscriptionID int64, filter entity.WebhookDeliveryFilter) ([]*entity.WebhookDelivery, error) {
+	args := m.Called(ctx, subscriptionID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetPendingDeliveries(ctx context.Context, dueBefore time.Time, limit int) ([]*entity.WebhookDelivery, error) {
+	args := m.Called(ctx, dueBefore, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) UpdateDeliveryAttempt(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	args := m.Called(ctx, delivery)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) BeginTx() (*sql.Tx, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sql.Tx), args.Error(1)
+}
@@ -0,0 +1,93 @@
+// Package report renders the portfolio reports as csv, one row per bucket, period or point of a vintage curve
+package report
+
+import (
+	"encoding/csv"
+	"io"
+	"loan-management/internal/entity"
+	"math"
+	"strconv"
+	"time"
+)
+
+const ContentType = "text/csv; charset=utf-8"
+
+// Filename names an exported report after its kind and date, e.g. aging-20261019.csv
+func Filename(name string, date time.Time) string {
+	return name + "-" + date.Format("20060102") + ".csv"
+}
+
+func WritePortfolioCSV(w io.Writer, report *entity.PortfolioReport) error {
+	return write(w, []string{"as_of", "loan_count", "active_loan_count", "total_disbursed", "outstanding_principal", "outstanding_interest", "par30", "par30_ratio", "par90", "par90_ratio"}, [][]string{{
+		report.AsOf.Format(time.DateOnly),
+		strconv.Itoa(report.LoanCount),
+		strconv.Itoa(report.ActiveLoanCount),
+		money(report.TotalDisbursed),
+		money(report.OutstandingPrincipal),
+		money(report.OutstandingInterest),
+		money(report.PAR30),
+		ratio(report.PAR30Ratio),
+		money(report.PAR90),
+		ratio(report.PAR90Ratio),
+	}})
+}
+
+func WriteAgingCSV(w io.Writer, report *entity.AgingReport) error {
+	rows := make([][]string, len(report.Buckets))
+	for i, bucket := range report.Buckets {
+		rows[i] = []string{
+			report.AsOf.Format(time.DateOnly),
+			bucket.Label,
+			strconv.Itoa(bucket.LoanCount),
+			money(bucket.Principal),
+			money(bucket.Interest),
+			ratio(bucket.PrincipalShare),
+		}
+	}
+	return write(w, []string{"as_of", "bucket", "loan_count", "outstanding_principal", "outstanding_interest", "principal_share"}, rows)
+}
+
+func WriteCollectionsCSV(w io.Writer, report *entity.CollectionReport) error {
+	rows := make([][]string, len(report.Periods))
+	for i, period := range report.Periods {
+		rows[i] = []string{period.Period, money(period.Due), money(period.Collected), money(period.Received), ratio(period.Rate)}
+	}
+	return write(w, []string{"period", "due", "collected", "received", "collection_rate"}, rows)
+}
+
+func WriteVintagesCSV(w io.Writer, report *entity.VintageReport) error {
+	var rows [][]string
+	for _, vintage := range report.Vintages {
+		for _, point := range vintage.Curve {
+			rows = append(rows, []string{
+				vintage.Cohort,
+				strconv.Itoa(vintage.LoanCount),
+				money(vintage.Disbursed),
+				strconv.Itoa(point.MonthsOnBook),
+				money(point.Repaid),
+				ratio(point.RepaidRatio),
+			})
+		}
+	}
+	return write(w, []string{"cohort", "loan_count", "disbursed", "months_on_book", "repaid", "repaid_ratio"}, rows)
+}
+
+func write(w io.Writer, header []string, rows [][]string) error {
+	writer := csv.NewWriter(w)
+	writer.Write(header)
+	writer.WriteAll(rows)
+	return writer.Error()
+}
+
+// money formats an amount to cents, without the minus sign float rounding leaves on a zero amount
+func money(amount float64) string {
+	rounded := math.Round(amount*100) / 100
+	if rounded == 0 {
+		rounded = 0
+	}
+	return strconv.FormatFloat(rounded, 'f', 2, 64)
+}
+
+func ratio(value float64) string {
+	return strconv.FormatFloat(value, 'f', 4, 64)
+}
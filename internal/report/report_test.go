@@ -0,0 +1,46 @@
+package report
+
+import (
+	"bytes"
+	"loan-management/internal/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteAgingCSV(t *testing.T) {
+	var out bytes.Buffer
+	err := WriteAgingCSV(&out, &entity.AgingReport{
+		AsOf: time.Date(2025, 6, 30, 23, 59, 0, 0, time.UTC),
+		Buckets: []*entity.AgingBucket{
+			{Label: "current", OutstandingTotals: entity.OutstandingTotals{LoanCount: 2, Principal: 1000, Interest: 100.005}, PrincipalShare: 2.0 / 3},
+			{Label: "1-30", MinDays: 1, OutstandingTotals: entity.OutstandingTotals{LoanCount: 1, Principal: 500, Interest: 50}, PrincipalShare: 1.0 / 3},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "as_of,bucket,loan_count,outstanding_principal,outstanding_interest,principal_share\n"+
+		"2025-06-30,current,2,1000.00,100.01,0.6667\n"+
+		"2025-06-30,1-30,1,500.00,50.00,0.3333\n", out.String())
+}
+
+func TestWriteVintagesCSV(t *testing.T) {
+	var out bytes.Buffer
+	err := WriteVintagesCSV(&out, &entity.VintageReport{Vintages: []*entity.Vintage{{
+		Cohort:    "2025-02",
+		LoanCount: 2,
+		Disbursed: 1000,
+		Curve: []*entity.VintagePoint{
+			{MonthsOnBook: 0},
+			{MonthsOnBook: 1, Repaid: 100, RepaidRatio: 0.1},
+		},
+	}}})
+	assert.NoError(t, err)
+	assert.Equal(t, "cohort,loan_count,disbursed,months_on_book,repaid,repaid_ratio\n"+
+		"2025-02,2,1000.00,0,0.00,0.0000\n"+
+		"2025-02,2,1000.00,1,100.00,0.1000\n", out.String())
+}
+
+func TestFilename(t *testing.T) {
+	assert.Equal(t, "aging-20250630.csv", Filename("aging", time.Date(2025, 6, 30, 23, 59, 0, 0, time.UTC)))
+}
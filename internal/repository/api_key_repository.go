@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"loan-management/infrastructure"
+	"loan-management/internal/apperror"
+	"loan-management/internal/entity"
+	"time"
+)
+
+var (
+	ErrAPIKeyNotFound = apperror.NotFound("API_KEY_NOT_FOUND", "api key not found")
+)
+
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, key *entity.APIKey) error
+	GetAPIKeyByID(ctx context.Context, id int64) (*entity.APIKey, error)
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (*entity.APIKey, error)
+	GetAllAPIKeys(ctx context.Context) ([]*entity.APIKey, error)
+	UpdateAPIKeyExpiry(ctx context.Context, id int64, expiresAt time.Time) error
+	RevokeAPIKey(ctx context.Context, id int64) error
+	TouchAPIKey(ctx context.Context, id int64, usedAt time.Time) error
+}
+
+type apiKeyRepository struct {
+	db      *sql.DB
+	dialect infrastructure.Dialect
+}
+
+func NewAPIKeyRepository(db *sql.DB, dialect infrastructure.Dialect) APIKeyRepository {
+	return &apiKeyRepository{db: db, dialect: dialect}
+}
+
+const apiKeyColumns = `id, name, prefix, key_hash, status, expires_at, last_used_at, created_at`
+
+func scanAPIKey(scanner interface{ Scan(dest ...any) error }, key *entity.APIKey) error {
+	var expiresAt, lastUsedAt sql.NullTime
+
+	err := scanner.Scan(
+		&key.ID,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		&key.Status,
+		&expiresAt,
+		&lastUsedAt,
+		&key.CreatedAt,
+	)
+
+	if expiresAt.Valid {
+		key.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+
+	return err
+}
+
+func (r *apiKeyRepository) CreateAPIKey(ctx context.Context, key *entity.APIKey) error {
+	query := `
+		INSERT INTO api_keys (name, prefix, key_hash, status, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	id, err := r.dialect.InsertReturningID(ctx, r.db, query, key.Name, key.Prefix, key.KeyHash, key.Status, key.ExpiresAt, key.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	key.ID = id
+	return nil
+}
+
+func (r *apiKeyRepository) getAPIKey(ctx context.Context, where string, arg any) (*entity.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE ` + where
+
+	key := &entity.APIKey{}
+	if err := scanAPIKey(r.db.QueryRowContext(ctx, r.dialect.Rebind(query), arg), key); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func (r *apiKeyRepository) GetAPIKeyByID(ctx context.Context, id int64) (*entity.APIKey, error) {
+	return r.getAPIKey(ctx, `id = ?`, id)
+}
+
+func (r *apiKeyRepository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*entity.APIKey, error) {
+	return r.getAPIKey(ctx, `prefix = ?`, prefix)
+}
+
+func (r *apiKeyRepository) GetAllAPIKeys(ctx context.Context) ([]*entity.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys ORDER BY id`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*entity.APIKey
+	for rows.Next() {
+		key := &entity.APIKey{}
+		if err := scanAPIKey(rows, key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (r *apiKeyRepository) UpdateAPIKeyExpiry(ctx context.Context, id int64, expiresAt time.Time) error {
+	query := `UPDATE api_keys SET expires_at = ? WHERE id = ?`
+
+	_, err := r.db.ExecContext(ctx, r.dialect.Rebind(query), expiresAt, id)
+	return err
+}
+
+func (r *apiKeyRepository) RevokeAPIKey(ctx context.Context, id int64) error {
+	query := `UPDATE api_keys SET status = ? WHERE id = ?`
+
+	_, err := r.db.ExecContext(ctx, r.dialect.Rebind(query), entity.APIKeyStatusRevoked, id)
+	return err
+}
+
+func (r *apiKeyRepository) TouchAPIKey(ctx context.Context, id int64, usedAt time.Time) error {
+	query := `UPDATE api_keys SET last_used_at = ? WHERE id = ?`
+
+	_, err := r.db.ExecContext(ctx, r.dialect.Rebind(query), usedAt, id)
+	return err
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"loan-management/infrastructure"
+	"loan-management/internal/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKeyRepository(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *sql.DB, dialect infrastructure.Dialect) {
+		repo := NewAPIKeyRepository(db, dialect)
+		ctx := context.Background()
+
+		key := &entity.APIKey{Name: "partner", Prefix: "abcd1234", KeyHash: "hash", Status: entity.APIKeyStatusActive, CreatedAt: time.Now()}
+		assert.NoError(t, repo.CreateAPIKey(ctx, key))
+		assert.NotZero(t, key.ID)
+
+		found, err := repo.GetAPIKeyByPrefix(ctx, "abcd1234")
+		assert.NoError(t, err)
+		assert.Equal(t, key.ID, found.ID)
+		assert.Nil(t, found.ExpiresAt)
+
+		expiresAt := time.Now().Add(time.Hour)
+		assert.NoError(t, repo.UpdateAPIKeyExpiry(ctx, key.ID, expiresAt))
+		assert.NoError(t, repo.TouchAPIKey(ctx, key.ID, time.Now()))
+		assert.NoError(t, repo.RevokeAPIKey(ctx, key.ID))
+
+		found, err = repo.GetAPIKeyByID(ctx, key.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, entity.APIKeyStatusRevoked, found.Status)
+		assert.NotNil(t, found.ExpiresAt)
+		assert.NotNil(t, found.LastUsedAt)
+
+		keys, err := repo.GetAllAPIKeys(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, keys, 1)
+
+		_, err = repo.GetAPIKeyByPrefix(ctx, "missing")
+		assert.ErrorIs(t, err, ErrAPIKeyNotFound)
+	})
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"loan-management/infrastructure"
+	"loan-management/internal/entity"
+)
+
+const defaultAuditLogLimit = 100
+
+// AuditLogRepository only appends and reads, audit logs are never updated or deleted
+type AuditLogRepository interface {
+	CreateAuditLog(tx *sql.Tx, log *entity.AuditLog) error
+	GetAuditLogs(ctx context.Context, filter entity.AuditLogFilter) ([]*entity.AuditLog, error)
+}
+
+type auditLogRepository struct {
+	db      *sql.DB
+	dialect infrastructure.Dialect
+}
+
+func NewAuditLogRepository(db *sql.DB, dialect infrastructure.Dialect) AuditLogRepository {
+	return &auditLogRepository{db: db, dialect: dialect}
+}
+
+func (r *auditLogRepository) CreateAuditLog(tx *sql.Tx, log *entity.AuditLog) error {
+	query := `
+	INSERT INTO audit_logs (
+		actor_type,
+		actor_id,
+		actor_name,
+		action,
+		entity_type,
+		entity_id,
+		changes,
+		request_id,
+		created_at
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	id, err := r.dialect.InsertReturningID(
+		context.Background(),
+		tx,
+		query,
+		log.ActorType,
+		log.ActorID,
+		log.ActorName,
+		log.Action,
+		log.EntityType,
+		log.EntityID,
+		string(log.Changes),
+		log.RequestID,
+		log.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	log.ID = id
+	return nil
+}
+
+func (r *auditLogRepository) GetAuditLogs(ctx context.Context, filter entity.AuditLogFilter) ([]*entity.AuditLog, error) {
+	query := `
+	SELECT id, actor_type, actor_id, actor_name, action, entity_type, entity_id, changes, request_id, created_at
+	FROM audit_logs
+	WHERE entity_type = ?
+	`
+	args := []any{filter.EntityType}
+
+	if filter.EntityID != 0 {
+		query += ` AND entity_id = ?`
+		args = append(args, filter.EntityID)
+	}
+
+	limit := filter.Limit
+	if limit == 0 {
+		limit = defaultAuditLogLimit
+	}
+	query += ` ORDER BY id DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, r.dialect.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var logs []*entity.AuditLog
+	for rows.Next() {
+		log := &entity.AuditLog{}
+		var actorName, changes, requestID sql.NullString
+		err := rows.Scan(
+			&log.ID,
+			&log.ActorType,
+			&log.ActorID,
+			&actorName,
+			&log.Action,
+			&log.EntityType,
+			&log.EntityID,
+			&changes,
+			&requestID,
+			&log.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		log.ActorName = actorName.String
+		if changes.String != "" {
+			log.Changes = []byte(changes.String)
+		}
+		log.RequestID = requestID.String
+		logs = append(logs, log)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return logs, nil
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"loan-management/infrastructure"
+	"loan-management/internal/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditLogRepository(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *sql.DB, dialect infrastructure.Dialect) {
+		repo := NewAuditLogRepository(db, dialect)
+
+		tx, err := db.Begin()
+		assert.NoError(t, err)
+
+		for _, entityID := range []int64{1, 1, 2} {
+			err := repo.CreateAuditLog(tx, &entity.AuditLog{
+				ActorType:  entity.IdentityTypeBorrower,
+				ActorID:    1,
+				ActorName:  "test",
+				Action:     entity.AuditActionLoanCreate,
+				EntityType: entity.AuditEntityLoan,
+				EntityID:   entityID,
+				Changes:    []byte(`{"Amount":{"before":null,"after":1000}}`),
+				RequestID:  "request-id",
+				CreatedAt:  time.Now(),
+			})
+			assert.NoError(t, err)
+		}
+		assert.NoError(t, tx.Commit())
+
+		logs, err := repo.GetAuditLogs(context.Background(), entity.AuditLogFilter{EntityType: entity.AuditEntityLoan, EntityID: 1})
+		assert.NoError(t, err)
+		assert.Len(t, logs, 2)
+		assert.Greater(t, logs[0].ID, logs[1].ID)
+		assert.JSONEq(t, `{"Amount":{"before":null,"after":1000}}`, string(logs[0].Changes))
+		assert.Equal(t, "request-id", logs[0].RequestID)
+
+		logs, err = repo.GetAuditLogs(context.Background(), entity.AuditLogFilter{EntityType: entity.AuditEntityLoan, Limit: 1})
+		assert.NoError(t, err)
+		assert.Len(t, logs, 1)
+		assert.Equal(t, int64(2), logs[0].EntityID)
+	})
+}
@@ -0,0 +1,341 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"loan-management/infrastructure"
+	"loan-management/internal/apperror"
+	"loan-management/internal/entity"
+	"time"
+)
+
+var (
+	ErrMandateNotFound           = apperror.NotFound("MANDATE_NOT_FOUND", "autodebit mandate not found")
+	ErrCollectionAttemptNotFound = apperror.NotFound("COLLECTION_ATTEMPT_NOT_FOUND", "collection attempt not found")
+)
+
+const defaultCollectionAttemptLimit = 100
+
+type AutodebitRepository interface {
+	CreateMandate(tx *sql.Tx, mandate *entity.AutodebitMandate) error
+	UpdateMandate(tx *sql.Tx, mandate *entity.AutodebitMandate) error
+	GetMandateByID(ctx context.Context, id int64) (*entity.AutodebitMandate, error)
+	GetActiveMandateByLoanID(ctx context.Context, loanID int64) (*entity.AutodebitMandate, error)
+	GetMandates(ctx context.Context, filter entity.MandateFilter) ([]*entity.AutodebitMandate, error)
+	CreateAttempt(ctx context.Context, attempt *entity.CollectionAttempt) error
+	UpdateAttempt(ctx context.Context, attempt *entity.CollectionAttempt) error
+	GetAttemptByPaymentID(ctx context.Context, mandateID int64, paymentID int64) (*entity.CollectionAttempt, error)
+	GetAttemptsByMandateID(ctx context.Context, mandateID int64, filter entity.CollectionAttemptFilter) ([]*entity.CollectionAttempt, error)
+	GetPendingAttempts(ctx context.Context, dueBefore time.Time, limit int) ([]*entity.CollectionAttempt, error)
+	CancelPendingAttempts(tx *sql.Tx, mandateID int64, reason string, completedAt time.Time) error
+	BeginTx() (*sql.Tx, error)
+}
+
+type autodebitRepository struct {
+	db      *sql.DB
+	dialect infrastructure.Dialect
+}
+
+func NewAutodebitRepository(db *sql.DB, dialect infrastructure.Dialect) AutodebitRepository {
+	return &autodebitRepository{db: db, dialect: dialect}
+}
+
+const (
+	mandateColumns           = `id, loan_id, provider, account_reference, max_amount, status, created_at, updated_at`
+	collectionAttemptColumns = `id, mandate_id, loan_id, payment_id, due_date, amount, status, attempts, provider_reference, transaction_id, last_error, next_attempt_at, created_at, completed_at`
+)
+
+func scanMandate(scanner interface{ Scan(dest ...any) error }, mandate *entity.AutodebitMandate) error {
+	var updatedAt sql.NullTime
+
+	err := scanner.Scan(
+		&mandate.ID,
+		&mandate.LoanID,
+		&mandate.Provider,
+		&mandate.AccountReference,
+		&mandate.MaxAmount,
+		&mandate.Status,
+		&mandate.CreatedAt,
+		&updatedAt,
+	)
+
+	if updatedAt.Valid {
+		mandate.UpdatedAt = &updatedAt.Time
+	}
+
+	return err
+}
+
+func scanCollectionAttempt(scanner interface{ Scan(dest ...any) error }, attempt *entity.CollectionAttempt) error {
+	var (
+		providerReference, lastError sql.NullString
+		transactionID                sql.NullInt64
+		completedAt                  sql.NullTime
+	)
+
+	err := scanner.Scan(
+		&attempt.ID,
+		&attempt.MandateID,
+		&attempt.LoanID,
+		&attempt.PaymentID,
+		&attempt.DueDate,
+		&attempt.Amount,
+		&attempt.Status,
+		&attempt.Attempts,
+		&providerReference,
+		&transactionID,
+		&lastError,
+		&attempt.NextAttemptAt,
+		&attempt.CreatedAt,
+		&completedAt,
+	)
+
+	attempt.ProviderReference = providerReference.String
+	attempt.LastError = lastError.String
+	if transactionID.Valid {
+		attempt.TransactionID = &transactionID.Int64
+	}
+	if completedAt.Valid {
+		attempt.CompletedAt = &completedAt.Time
+	}
+
+	return err
+}
+
+func (r *autodebitRepository) CreateMandate(tx *sql.Tx, mandate *entity.AutodebitMandate) error {
+	query := `
+		INSERT INTO autodebit_mandates (loan_id, provider, account_reference, max_amount, status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	id, err := r.dialect.InsertReturningID(
+		context.Background(),
+		tx,
+		query,
+		mandate.LoanID,
+		mandate.Provider,
+		mandate.AccountReference,
+		mandate.MaxAmount,
+		mandate.Status,
+		mandate.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	mandate.ID = id
+	return nil
+}
+
+func (r *autodebitRepository) UpdateMandate(tx *sql.Tx, mandate *entity.AutodebitMandate) error {
+	query := `UPDATE autodebit_mandates SET status = ?, updated_at = ? WHERE id = ?`
+
+	_, err := tx.Exec(r.dialect.Rebind(query), mandate.Status, mandate.UpdatedAt, mandate.ID)
+	return err
+}
+
+func (r *autodebitRepository) GetMandateByID(ctx context.Context, id int64) (*entity.AutodebitMandate, error) {
+	query := `SELECT ` + mandateColumns + ` FROM autodebit_mandates WHERE id = ?`
+
+	return r.getMandate(ctx, query, id)
+}
+
+func (r *autodebitRepository) GetActiveMandateByLoanID(ctx context.Context, loanID int64) (*entity.AutodebitMandate, error) {
+	query := `SELECT ` + mandateColumns + ` FROM autodebit_mandates WHERE loan_id = ? AND status = ?`
+
+	return r.getMandate(ctx, query, loanID, entity.MandateStatusActive)
+}
+
+func (r *autodebitRepository) getMandate(ctx context.Context, query string, args ...any) (*entity.AutodebitMandate, error) {
+	mandate := &entity.AutodebitMandate{}
+	if err := scanMandate(r.db.QueryRowContext(ctx, r.dialect.Rebind(query), args...), mandate); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrMandateNotFound
+		}
+		return nil, err
+	}
+
+	return mandate, nil
+}
+
+func (r *autodebitRepository) GetMandates(ctx context.Context, filter entity.MandateFilter) ([]*entity.AutodebitMandate, error) {
+	query := `SELECT ` + mandateColumns + ` FROM autodebit_mandates WHERE 1 = 1`
+	var args []any
+
+	if filter.LoanID != 0 {
+		query += ` AND loan_id = ?`
+		args = append(args, filter.LoanID)
+	}
+	if filter.Status != 0 {
+		query += ` AND status = ?`
+		args = append(args, filter.Status)
+	}
+	query += ` ORDER BY id`
+
+	rows, err := r.db.QueryContext(ctx, r.dialect.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var mandates []*entity.AutodebitMandate
+	for rows.Next() {
+		mandate := &entity.AutodebitMandate{}
+		if err := scanMandate(rows, mandate); err != nil {
+			return nil, err
+		}
+		mandates = append(mandates, mandate)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return mandates, nil
+}
+
+func (r *autodebitRepository) CreateAttempt(ctx context.Context, attempt *entity.CollectionAttempt) error {
+	query := `
+		INSERT INTO collection_attempts (mandate_id, loan_id, payment_id, due_date, amount, status, attempts, next_attempt_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	id, err := r.dialect.InsertReturningID(
+		ctx,
+		r.db,
+		query,
+		attempt.MandateID,
+		attempt.LoanID,
+		attempt.PaymentID,
+		attempt.DueDate,
+		attempt.Amount,
+		attempt.Status,
+		attempt.Attempts,
+		attempt.NextAttemptAt,
+		attempt.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	attempt.ID = id
+	return nil
+}
+
+// UpdateAttempt saves the outcome of a try
+func (r *autodebitRepository) UpdateAttempt(ctx context.Context, attempt *entity.CollectionAttempt) error {
+	query := `
+	UPDATE collection_attempts
+	SET	amount = ?,
+		status = ?,
+		attempts = ?,
+		provider_reference = ?,
+		transaction_id = ?,
+		last_error = ?,
+		next_attempt_at = ?,
+		completed_at = ?
+	WHERE id = ?
+	`
+
+	_, err := r.db.ExecContext(ctx, r.dialect.Rebind(query),
+		attempt.Amount,
+		attempt.Status,
+		attempt.Attempts,
+		nullString(attempt.ProviderReference),
+		attempt.TransactionID,
+		nullString(attempt.LastError),
+		attempt.NextAttemptAt,
+		attempt.CompletedAt,
+		attempt.ID,
+	)
+	return err
+}
+
+func (r *autodebitRepository) GetAttemptByPaymentID(ctx context.Context, mandateID int64, paymentID int64) (*entity.CollectionAttempt, error) {
+	query := `SELECT ` + collectionAttemptColumns + ` FROM collection_attempts WHERE mandate_id = ? AND payment_id = ?`
+
+	attempt := &entity.CollectionAttempt{}
+	if err := scanCollectionAttempt(r.db.QueryRowContext(ctx, r.dialect.Rebind(query), mandateID, paymentID), attempt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCollectionAttemptNotFound
+		}
+		return nil, err
+	}
+
+	return attempt, nil
+}
+
+// GetAttemptsByMandateID returns the newest attempts first
+func (r *autodebitRepository) GetAttemptsByMandateID(ctx context.Context, mandateID int64, filter entity.CollectionAttemptFilter) ([]*entity.CollectionAttempt, error) {
+	query := `SELECT ` + collectionAttemptColumns + ` FROM collection_attempts WHERE mandate_id = ?`
+	args := []any{mandateID}
+
+	if filter.Status != 0 {
+		query += ` AND status = ?`
+		args = append(args, filter.Status)
+	}
+
+	limit := filter.Limit
+	if limit == 0 {
+		limit = defaultCollectionAttemptLimit
+	}
+	query += ` ORDER BY id DESC LIMIT ?`
+	args = append(args, limit)
+
+	return r.queryAttempts(ctx, query, args...)
+}
+
+// GetPendingAttempts returns the attempts due for a try, oldest first
+func (r *autodebitRepository) GetPendingAttempts(ctx context.Context, dueBefore time.Time, limit int) ([]*entity.CollectionAttempt, error) {
+	query := `
+	SELECT ` + collectionAttemptColumns + `
+	FROM collection_attempts
+	WHERE status = ? AND next_attempt_at <= ?
+	ORDER BY id
+	LIMIT ?
+	`
+
+	return r.queryAttempts(ctx, query, entity.CollectionAttemptStatusPending, dueBefore, limit)
+}
+
+func (r *autodebitRepository) queryAttempts(ctx context.Context, query string, args ...any) ([]*entity.CollectionAttempt, error) {
+	rows, err := r.db.QueryContext(ctx, r.dialect.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attempts []*entity.CollectionAttempt
+	for rows.Next() {
+		attempt := &entity.CollectionAttempt{}
+		if err := scanCollectionAttempt(rows, attempt); err != nil {
+			return nil, err
+		}
+		attempts = append(attempts, attempt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return attempts, nil
+}
+
+// CancelPendingAttempts stops the retries of a revoked mandate
+func (r *autodebitRepository) CancelPendingAttempts(tx *sql.Tx, mandateID int64, reason string, completedAt time.Time) error {
+	query := `
+	UPDATE collection_attempts
+	SET	status = ?,
+		last_error = ?,
+		completed_at = ?
+	WHERE mandate_id = ? AND status = ?
+	`
+
+	_, err := tx.Exec(r.dialect.Rebind(query), entity.CollectionAttemptStatusCancelled, reason, completedAt, mandateID, entity.CollectionAttemptStatusPending)
+	return err
+}
+
+func (r *autodebitRepository) BeginTx() (*sql.Tx, error) {
+	return r.db.Begin()
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"loan-management/infrastructure"
+	"loan-management/internal/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAutodebitRepository(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *sql.DB, dialect infrastructure.Dialect) {
+		repo := NewAutodebitRepository(db, dialect)
+		ctx := context.Background()
+		createdAt := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+
+		_, err := repo.GetActiveMandateByLoanID(ctx, 1)
+		assert.ErrorIs(t, err, ErrMandateNotFound)
+
+		mandate := &entity.AutodebitMandate{LoanID: 1, Provider: "fake", AccountReference: "ACC-1", MaxAmount: 2000, Status: entity.MandateStatusActive, CreatedAt: createdAt}
+		tx, err := repo.BeginTx()
+		assert.NoError(t, err)
+		assert.NoError(t, repo.CreateMandate(tx, mandate))
+		assert.NoError(t, tx.Commit())
+		assert.NotZero(t, mandate.ID)
+
+		found, err := repo.GetActiveMandateByLoanID(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, mandate.ID, found.ID)
+		assert.Equal(t, "ACC-1", found.AccountReference)
+		assert.Nil(t, found.UpdatedAt)
+
+		_, err = repo.GetAttemptByPaymentID(ctx, mandate.ID, 11)
+		assert.ErrorIs(t, err, ErrCollectionAttemptNotFound)
+
+		first := &entity.CollectionAttempt{MandateID: mandate.ID, LoanID: 1, PaymentID: 11, DueDate: entity.NewDate(2026, 9, 8), Amount: 1003.85, Status: entity.CollectionAttemptStatusPending, NextAttemptAt: createdAt.AddDate(0, 0, 7), CreatedAt: createdAt}
+		second := &entity.CollectionAttempt{MandateID: mandate.ID, LoanID: 1, PaymentID: 12, DueDate: entity.NewDate(2026, 9, 15), Amount: 500, Status: entity.CollectionAttemptStatusPending, NextAttemptAt: createdAt.AddDate(0, 0, 14), CreatedAt: createdAt}
+		assert.NoError(t, repo.CreateAttempt(ctx, first))
+		assert.NoError(t, repo.CreateAttempt(ctx, second))
+		assert.Error(t, repo.CreateAttempt(ctx, &entity.CollectionAttempt{MandateID: mandate.ID, LoanID: 1, PaymentID: 11, DueDate: entity.NewDate(2026, 9, 8), Status: entity.CollectionAttemptStatusPending, NextAttemptAt: createdAt, CreatedAt: createdAt}))
+
+		pending, err := repo.GetPendingAttempts(ctx, createdAt.AddDate(0, 0, 10), 10)
+		assert.NoError(t, err)
+		assert.Len(t, pending, 1)
+		assert.Equal(t, first.ID, pending[0].ID)
+
+		transactionID := int64(9)
+		completedAt := createdAt.AddDate(0, 0, 7)
+		first.Status = entity.CollectionAttemptStatusSucceeded
+		first.Attempts = 1
+		first.ProviderReference = "fake_autodebit-1-1"
+		first.TransactionID = &transactionID
+		first.CompletedAt = &completedAt
+		assert.NoError(t, repo.UpdateAttempt(ctx, first))
+
+		attempt, err := repo.GetAttemptByPaymentID(ctx, mandate.ID, 11)
+		assert.NoError(t, err)
+		assert.Equal(t, entity.CollectionAttemptStatusSucceeded, attempt.Status)
+		assert.Equal(t, transactionID, *attempt.TransactionID)
+		assert.Equal(t, "fake_autodebit-1-1", attempt.ProviderReference)
+		assert.True(t, completedAt.Equal(*attempt.CompletedAt))
+
+		updatedAt := createdAt.AddDate(0, 0, 8)
+		mandate.Status = entity.MandateStatusRevoked
+		mandate.UpdatedAt = &updatedAt
+		tx, err = repo.BeginTx()
+		assert.NoError(t, err)
+		assert.NoError(t, repo.UpdateMandate(tx, mandate))
+		assert.NoError(t, repo.CancelPendingAttempts(tx, mandate.ID, "MANDATE_REVOKED", updatedAt))
+		assert.NoError(t, tx.Commit())
+
+		_, err = repo.GetActiveMandateByLoanID(ctx, 1)
+		assert.ErrorIs(t, err, ErrMandateNotFound)
+
+		attempts, err := repo.GetAttemptsByMandateID(ctx, mandate.ID, entity.CollectionAttemptFilter{})
+		assert.NoError(t, err)
+		assert.Len(t, attempts, 2)
+		assert.Equal(t, second.ID, attempts[0].ID)
+		assert.Equal(t, entity.CollectionAttemptStatusCancelled, attempts[0].Status)
+		assert.Equal(t, "MANDATE_REVOKED", attempts[0].LastError)
+		assert.Equal(t, entity.CollectionAttemptStatusSucceeded, attempts[1].Status)
+
+		mandates, err := repo.GetMandates(ctx, entity.MandateFilter{Status: entity.MandateStatusRevoked})
+		assert.NoError(t, err)
+		assert.Len(t, mandates, 1)
+		assert.NotNil(t, mandates[0].UpdatedAt)
+	})
+}
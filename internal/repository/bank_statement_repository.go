@@ -0,0 +1,294 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"loan-management/infrastructure"
+	"loan-management/internal/apperror"
+	"loan-management/internal/entity"
+)
+
+var (
+	ErrBankStatementNotFound     = apperror.NotFound("BANK_STATEMENT_NOT_FOUND", "bank statement not found")
+	ErrBankStatementLineNotFound = apperror.NotFound("BANK_STATEMENT_LINE_NOT_FOUND", "bank statement line not found")
+)
+
+const defaultBankStatementLimit = 100
+
+type BankStatementRepository interface {
+	CreateStatement(tx *sql.Tx, statement *entity.BankStatement) error
+	CreateLines(tx *sql.Tx, lines []*entity.BankStatementLine) error
+	UpdateLine(tx *sql.Tx, line *entity.BankStatementLine) error
+	GetStatementByID(ctx context.Context, id int64) (*entity.BankStatement, error)
+	GetStatements(ctx context.Context, limit int) ([]*entity.BankStatement, error)
+	GetLineByID(ctx context.Context, id int64) (*entity.BankStatementLine, error)
+	GetLineByExternalID(ctx context.Context, account string, externalID string) (*entity.BankStatementLine, error)
+	GetLines(ctx context.Context, filter entity.BankStatementLineFilter) ([]*entity.BankStatementLine, error)
+	BeginTx() (*sql.Tx, error)
+}
+
+type bankStatementRepository struct {
+	db      *sql.DB
+	dialect infrastructure.Dialect
+}
+
+func NewBankStatementRepository(db *sql.DB, dialect infrastructure.Dialect) BankStatementRepository {
+	return &bankStatementRepository{db: db, dialect: dialect}
+}
+
+const bankStatementColumns = `id, account, format, file_name, line_count, imported_at`
+
+const bankStatementLineColumns = `id, statement_id, line_no, account, external_id, booked_at, amount, reference, description, status, loan_id, transaction_id, note, reviewed_at`
+
+func scanBankStatement(scanner interface{ Scan(dest ...any) error }, statement *entity.BankStatement) error {
+	var fileName sql.NullString
+
+	err := scanner.Scan(
+		&statement.ID,
+		&statement.Account,
+		&statement.Format,
+		&fileName,
+		&statement.LineCount,
+		&statement.ImportedAt,
+	)
+
+	statement.FileName = fileName.String
+
+	return err
+}
+
+func scanBankStatementLine(scanner interface{ Scan(dest ...any) error }, line *entity.BankStatementLine) error {
+	var (
+		reference, description, note sql.NullString
+		loanID, transactionID        sql.NullInt64
+		reviewedAt                   sql.NullTime
+	)
+
+	err := scanner.Scan(
+		&line.ID,
+		&line.StatementID,
+		&line.LineNo,
+		&line.Account,
+		&line.ExternalID,
+		&line.BookedAt,
+		&line.Amount,
+		&reference,
+		&description,
+		&line.Status,
+		&loanID,
+		&transactionID,
+		&note,
+		&reviewedAt,
+	)
+
+	line.Reference = reference.String
+	line.Description = description.String
+	line.Note = note.String
+	if loanID.Valid {
+		line.LoanID = &loanID.Int64
+	}
+	if transactionID.Valid {
+		line.TransactionID = &transactionID.Int64
+	}
+	if reviewedAt.Valid {
+		line.ReviewedAt = &reviewedAt.Time
+	}
+
+	return err
+}
+
+func (r *bankStatementRepository) CreateStatement(tx *sql.Tx, statement *entity.BankStatement) error {
+	query := `
+		INSERT INTO bank_statements (account, format, file_name, line_count, imported_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	id, err := r.dialect.InsertReturningID(context.Background(), tx, query,
+		statement.Account,
+		statement.Format,
+		nullString(statement.FileName),
+		statement.LineCount,
+		statement.ImportedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	statement.ID = id
+	return nil
+}
+
+func (r *bankStatementRepository) CreateLines(tx *sql.Tx, lines []*entity.BankStatementLine) error {
+	query := `
+		INSERT INTO bank_statement_lines (statement_id, line_no, account, external_id, booked_at, amount, reference, description, status, loan_id, transaction_id, note, reviewed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	for _, line := range lines {
+		id, err := r.dialect.InsertReturningID(context.Background(), tx, query,
+			line.StatementID,
+			line.LineNo,
+			line.Account,
+			line.ExternalID,
+			line.BookedAt,
+			line.Amount,
+			nullString(line.Reference),
+			nullString(line.Description),
+			line.Status,
+			line.LoanID,
+			line.TransactionID,
+			nullString(line.Note),
+			line.ReviewedAt,
+		)
+		if err != nil {
+			return err
+		}
+		line.ID = id
+	}
+
+	return nil
+}
+
+// UpdateLine saves the outcome of matching the line
+func (r *bankStatementRepository) UpdateLine(tx *sql.Tx, line *entity.BankStatementLine) error {
+	query := `
+	UPDATE bank_statement_lines
+	SET	status = ?,
+		loan_id = ?,
+		transaction_id = ?,
+		note = ?,
+		reviewed_at = ?
+	WHERE id = ?
+	`
+
+	_, err := tx.Exec(r.dialect.Rebind(query),
+		line.Status,
+		line.LoanID,
+		line.TransactionID,
+		nullString(line.Note),
+		line.ReviewedAt,
+		line.ID,
+	)
+	return err
+}
+
+func (r *bankStatementRepository) GetStatementByID(ctx context.Context, id int64) (*entity.BankStatement, error) {
+	query := `SELECT ` + bankStatementColumns + ` FROM bank_statements WHERE id = ?`
+
+	statement := &entity.BankStatement{}
+	if err := scanBankStatement(r.db.QueryRowContext(ctx, r.dialect.Rebind(query), id), statement); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBankStatementNotFound
+		}
+		return nil, err
+	}
+
+	return statement, nil
+}
+
+// GetStatements returns the latest imports first
+func (r *bankStatementRepository) GetStatements(ctx context.Context, limit int) ([]*entity.BankStatement, error) {
+	if limit == 0 {
+		limit = defaultBankStatementLimit
+	}
+
+	query := `SELECT ` + bankStatementColumns + ` FROM bank_statements ORDER BY id DESC LIMIT ?`
+	rows, err := r.db.QueryContext(ctx, r.dialect.Rebind(query), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var statements []*entity.BankStatement
+	for rows.Next() {
+		statement := &entity.BankStatement{}
+		if err := scanBankStatement(rows, statement); err != nil {
+			return nil, err
+		}
+		statements = append(statements, statement)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return statements, nil
+}
+
+func (r *bankStatementRepository) GetLineByID(ctx context.Context, id int64) (*entity.BankStatementLine, error) {
+	query := `SELECT ` + bankStatementLineColumns + ` FROM bank_statement_lines WHERE id = ?`
+
+	line := &entity.BankStatementLine{}
+	if err := scanBankStatementLine(r.db.QueryRowContext(ctx, r.dialect.Rebind(query), id), line); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBankStatementLineNotFound
+		}
+		return nil, err
+	}
+
+	return line, nil
+}
+
+// GetLineByExternalID finds the first import of a mutation, later imports of it are duplicates
+func (r *bankStatementRepository) GetLineByExternalID(ctx context.Context, account string, externalID string) (*entity.BankStatementLine, error) {
+	query := `SELECT ` + bankStatementLineColumns + ` FROM bank_statement_lines WHERE account = ? AND external_id = ? AND status <> ? ORDER BY id LIMIT 1`
+
+	line := &entity.BankStatementLine{}
+	row := r.db.QueryRowContext(ctx, r.dialect.Rebind(query), account, externalID, entity.BankStatementLineStatusDuplicate)
+	if err := scanBankStatementLine(row, line); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBankStatementLineNotFound
+		}
+		return nil, err
+	}
+
+	return line, nil
+}
+
+// GetLines returns the lines in statement order, the lines of the latest statements first
+func (r *bankStatementRepository) GetLines(ctx context.Context, filter entity.BankStatementLineFilter) ([]*entity.BankStatementLine, error) {
+	query := `SELECT ` + bankStatementLineColumns + ` FROM bank_statement_lines WHERE 1 = 1`
+	var args []any
+
+	if filter.StatementID != 0 {
+		query += ` AND statement_id = ?`
+		args = append(args, filter.StatementID)
+	}
+	if filter.Status != 0 {
+		query += ` AND status = ?`
+		args = append(args, filter.Status)
+	}
+
+	limit := filter.Limit
+	if limit == 0 {
+		limit = defaultBankStatementLimit
+	}
+	query += ` ORDER BY statement_id DESC, line_no LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, r.dialect.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lines []*entity.BankStatementLine
+	for rows.Next() {
+		line := &entity.BankStatementLine{}
+		if err := scanBankStatementLine(rows, line); err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return lines, nil
+}
+
+func (r *bankStatementRepository) BeginTx() (*sql.Tx, error) {
+	return r.db.Begin()
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"loan-management/infrastructure"
+	"loan-management/internal/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBankStatementRepository(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *sql.DB, dialect infrastructure.Dialect) {
+		repo := NewBankStatementRepository(db, dialect)
+		ctx := context.Background()
+		importedAt := time.Date(2026, 9, 2, 0, 0, 0, 0, time.UTC)
+		bookedAt := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+
+		_, err := repo.GetStatementByID(ctx, 1)
+		assert.ErrorIs(t, err, ErrBankStatementNotFound)
+
+		_, err = repo.GetLineByExternalID(ctx, "1234567890", "BNK001")
+		assert.ErrorIs(t, err, ErrBankStatementLineNotFound)
+
+		statement := &entity.BankStatement{Account: "1234567890", Format: entity.BankStatementFormatCSV, FileName: "mutation.csv", LineCount: 2, ImportedAt: importedAt}
+		lines := []*entity.BankStatementLine{
+			{LineNo: 1, Account: "1234567890", ExternalID: "BNK001", BookedAt: bookedAt, Amount: 1004, Reference: "BNK001", Description: "TRF LOAN-1", Status: entity.BankStatementLineStatusReceived},
+			{LineNo: 2, Account: "1234567890", ExternalID: "sha256:abc", BookedAt: bookedAt, Amount: 250, Description: "TRANSFER", Status: entity.BankStatementLineStatusReceived},
+		}
+
+		tx, err := repo.BeginTx()
+		assert.NoError(t, err)
+		assert.NoError(t, repo.CreateStatement(tx, statement))
+		for _, line := range lines {
+			line.StatementID = statement.ID
+		}
+		assert.NoError(t, repo.CreateLines(tx, lines))
+		assert.NoError(t, tx.Commit())
+		assert.NotZero(t, statement.ID)
+		assert.NotZero(t, lines[1].ID)
+
+		loanID, transactionID := int64(1), int64(9)
+		lines[0].Status = entity.BankStatementLineStatusPosted
+		lines[0].LoanID = &loanID
+		lines[0].TransactionID = &transactionID
+		lines[1].Status = entity.BankStatementLineStatusUnmatched
+		lines[1].Note = "UNKNOWN_LOAN_REFERENCE: No loan matches the payment reference"
+
+		tx, err = repo.BeginTx()
+		assert.NoError(t, err)
+		for _, line := range lines {
+			assert.NoError(t, repo.UpdateLine(tx, line))
+		}
+		assert.NoError(t, tx.Commit())
+
+		found, err := repo.GetStatementByID(ctx, statement.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "mutation.csv", found.FileName)
+		assert.Equal(t, 2, found.LineCount)
+
+		line, err := repo.GetLineByID(ctx, lines[0].ID)
+		assert.NoError(t, err)
+		assert.Equal(t, entity.BankStatementLineStatusPosted, line.Status)
+		assert.Equal(t, transactionID, *line.TransactionID)
+		assert.True(t, bookedAt.Equal(line.BookedAt))
+		assert.Nil(t, line.ReviewedAt)
+
+		line, err = repo.GetLineByExternalID(ctx, "1234567890", "BNK001")
+		assert.NoError(t, err)
+		assert.Equal(t, lines[0].ID, line.ID)
+
+		_, err = repo.GetLineByExternalID(ctx, "0987654321", "BNK001")
+		assert.ErrorIs(t, err, ErrBankStatementLineNotFound)
+
+		queue, err := repo.GetLines(ctx, entity.BankStatementLineFilter{Status: entity.BankStatementLineStatusUnmatched})
+		assert.NoError(t, err)
+		assert.Len(t, queue, 1)
+		assert.Equal(t, lines[1].ID, queue[0].ID)
+		assert.Nil(t, queue[0].LoanID)
+
+		all, err := repo.GetLines(ctx, entity.BankStatementLineFilter{StatementID: statement.ID})
+		assert.NoError(t, err)
+		assert.Len(t, all, 2)
+		assert.Equal(t, 1, all[0].LineNo)
+
+		statements, err := repo.GetStatements(ctx, 0)
+		assert.NoError(t, err)
+		assert.Len(t, statements, 1)
+	})
+}
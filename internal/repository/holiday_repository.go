@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"loan-management/infrastructure"
+	"loan-management/internal/apperror"
+	"loan-management/internal/entity"
+)
+
+var ErrHolidayNotFound = apperror.NotFound("HOLIDAY_NOT_FOUND", "holiday not found")
+
+type HolidayRepository interface {
+	CreateHoliday(tx *sql.Tx, holiday *entity.Holiday) error
+	DeleteHoliday(tx *sql.Tx, id int64) error
+	GetHolidayByID(ctx context.Context, id int64) (*entity.Holiday, error)
+	GetAllHolidays(ctx context.Context) ([]*entity.Holiday, error)
+	BeginTx() (*sql.Tx, error)
+}
+
+type holidayRepository struct {
+	db      *sql.DB
+	dialect infrastructure.Dialect
+}
+
+func NewHolidayRepository(db *sql.DB, dialect infrastructure.Dialect) HolidayRepository {
+	return &holidayRepository{db: db, dialect: dialect}
+}
+
+const holidayColumns = `id, date, name, created_at`
+
+func scanHoliday(scanner interface{ Scan(dest ...any) error }, holiday *entity.Holiday) error {
+	return scanner.Scan(&holiday.ID, &holiday.Date, &holiday.Name, &holiday.CreatedAt)
+}
+
+func (r *holidayRepository) CreateHoliday(tx *sql.Tx, holiday *entity.Holiday) error {
+	query := `INSERT INTO holidays (date, name, created_at) VALUES (?, ?, ?)`
+
+	id, err := r.dialect.InsertReturningID(context.Background(), tx, query, holiday.Date, holiday.Name, holiday.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	holiday.ID = id
+	return nil
+}
+
+func (r *holidayRepository) DeleteHoliday(tx *sql.Tx, id int64) error {
+	result, err := tx.Exec(r.dialect.Rebind(`DELETE FROM holidays WHERE id = ?`), id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrHolidayNotFound
+	}
+	return nil
+}
+
+func (r *holidayRepository) GetHolidayByID(ctx context.Context, id int64) (*entity.Holiday, error) {
+	query := `SELECT ` + holidayColumns + ` FROM holidays WHERE id = ?`
+
+	holiday := &entity.Holiday{}
+	if err := scanHoliday(r.db.QueryRowContext(ctx, r.dialect.Rebind(query), id), holiday); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrHolidayNotFound
+		}
+		return nil, err
+	}
+
+	return holiday, nil
+}
+
+// GetAllHolidays returns the holidays by date
+func (r *holidayRepository) GetAllHolidays(ctx context.Context) ([]*entity.Holiday, error) {
+	query := `SELECT ` + holidayColumns + ` FROM holidays ORDER BY date`
+
+	rows, err := r.db.QueryContext(ctx, r.dialect.Rebind(query))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var holidays []*entity.Holiday
+	for rows.Next() {
+		holiday := &entity.Holiday{}
+		if err := scanHoliday(rows, holiday); err != nil {
+			return nil, err
+		}
+		holidays = append(holidays, holiday)
+	}
+
+	return holidays, rows.Err()
+}
+
+func (r *holidayRepository) BeginTx() (*sql.Tx, error) {
+	return r.db.Begin()
+}
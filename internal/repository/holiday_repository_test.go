@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"loan-management/infrastructure"
+	"loan-management/internal/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHolidayRepository(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *sql.DB, dialect infrastructure.Dialect) {
+		repo := NewHolidayRepository(db, dialect)
+		ctx := context.Background()
+		createdAt := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+		christmas := &entity.Holiday{Date: entity.NewDate(2026, 12, 25), Name: "Christmas Day", CreatedAt: createdAt}
+		newYear := &entity.Holiday{Date: entity.NewDate(2027, 1, 1), Name: "New Year's Day", CreatedAt: createdAt}
+
+		tx, err := repo.BeginTx()
+		assert.NoError(t, err)
+		assert.NoError(t, repo.CreateHoliday(tx, newYear))
+		assert.NoError(t, repo.CreateHoliday(tx, christmas))
+		assert.NoError(t, tx.Commit())
+		assert.NotZero(t, christmas.ID)
+
+		// a date is a holiday once
+		tx, err = repo.BeginTx()
+		assert.NoError(t, err)
+		assert.Error(t, repo.CreateHoliday(tx, &entity.Holiday{Date: christmas.Date, Name: "Again", CreatedAt: createdAt}))
+		assert.NoError(t, tx.Rollback())
+
+		holidays, err := repo.GetAllHolidays(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, holidays, 2)
+		assert.Equal(t, "Christmas Day", holidays[0].Name)
+		assert.Equal(t, christmas.Date, holidays[0].Date)
+
+		found, err := repo.GetHolidayByID(ctx, newYear.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "New Year's Day", found.Name)
+
+		tx, err = repo.BeginTx()
+		assert.NoError(t, err)
+		assert.NoError(t, repo.DeleteHoliday(tx, newYear.ID))
+		assert.ErrorIs(t, repo.DeleteHoliday(tx, newYear.ID), ErrHolidayNotFound)
+		assert.NoError(t, tx.Commit())
+
+		_, err = repo.GetHolidayByID(ctx, newYear.ID)
+		assert.ErrorIs(t, err, ErrHolidayNotFound)
+	})
+}
@@ -0,0 +1,285 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"loan-management/infrastructure"
+	"loan-management/internal/entity"
+	"time"
+)
+
+type LedgerRepository interface {
+	CreateJournalEntry(tx *sql.Tx, entry *entity.JournalEntry) error
+	GetJournalEntriesByReference(ctx context.Context, referenceType string, referenceID int64) ([]*entity.JournalEntry, error)
+	GetJournalEntriesByLoanID(ctx context.Context, loanID int64) ([]*entity.JournalEntry, error)
+	GetAccounts(ctx context.Context) ([]*entity.Account, error)
+	GetAccountBalances(ctx context.Context, asOf time.Time) (map[string]float64, error)
+	GetLoanBalances(ctx context.Context, accountCode string) (map[int64]float64, error)
+	GetLoanAccountBalances(tx *sql.Tx, loanID int64) (map[string]float64, error)
+}
+
+type ledgerRepository struct {
+	db      *sql.DB
+	dialect infrastructure.Dialect
+}
+
+func NewLedgerRepository(db *sql.DB, dialect infrastructure.Dialect) LedgerRepository {
+	return &ledgerRepository{db: db, dialect: dialect}
+}
+
+func (r *ledgerRepository) CreateJournalEntry(tx *sql.Tx, entry *entity.JournalEntry) error {
+	query := `
+	INSERT INTO journal_entries (
+		loan_id,
+		reference_type,
+		reference_id,
+		description,
+		reversal_of,
+		posted_at
+	) VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	id, err := r.dialect.InsertReturningID(
+		context.Background(),
+		tx,
+		query,
+		entry.LoanID,
+		entry.ReferenceType,
+		entry.ReferenceID,
+		entry.Description,
+		entry.ReversalOf,
+		entry.PostedAt,
+	)
+	if err != nil {
+		return err
+	}
+	entry.ID = id
+
+	lineQuery := `INSERT INTO journal_lines (journal_entry_id, account_code, debit, credit) VALUES (?, ?, ?, ?)`
+	for _, line := range entry.Lines {
+		line.JournalEntryID = id
+		line.ID, err = r.dialect.InsertReturningID(context.Background(), tx, lineQuery, id, line.AccountCode, line.Debit, line.Credit)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+const journalEntryColumns = `
+		e.id, e.loan_id, e.reference_type, e.reference_id, e.description, e.reversal_of, e.posted_at,
+		l.id, l.account_code, l.debit, l.credit`
+
+func (r *ledgerRepository) GetJournalEntriesByReference(ctx context.Context, referenceType string, referenceID int64) ([]*entity.JournalEntry, error) {
+	query := `
+	SELECT ` + journalEntryColumns + `
+	FROM journal_entries e
+	JOIN journal_lines l ON l.journal_entry_id = e.id
+	WHERE e.reference_type = ? AND e.reference_id = ?
+	ORDER BY e.id, l.id
+	`
+
+	return r.queryJournalEntries(ctx, query, referenceType, referenceID)
+}
+
+// GetJournalEntriesByLoanID returns every entry posted for the loan in posting order, reversals included
+func (r *ledgerRepository) GetJournalEntriesByLoanID(ctx context.Context, loanID int64) ([]*entity.JournalEntry, error) {
+	query := `
+	SELECT ` + journalEntryColumns + `
+	FROM journal_entries e
+	JOIN journal_lines l ON l.journal_entry_id = e.id
+	WHERE e.loan_id = ?
+	ORDER BY e.id, l.id
+	`
+
+	return r.queryJournalEntries(ctx, query, loanID)
+}
+
+// queryJournalEntries reads entries with their lines, the query must order the rows by entry
+func (r *ledgerRepository) queryJournalEntries(ctx context.Context, query string, args ...any) ([]*entity.JournalEntry, error) {
+	rows, err := r.db.QueryContext(ctx, r.dialect.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*entity.JournalEntry
+	for rows.Next() {
+		var (
+			entry       entity.JournalEntry
+			line        entity.JournalLine
+			loanID      sql.NullInt64
+			reversalOf  sql.NullInt64
+			description sql.NullString
+		)
+
+		err := rows.Scan(
+			&entry.ID,
+			&loanID,
+			&entry.ReferenceType,
+			&entry.ReferenceID,
+			&description,
+			&reversalOf,
+			&entry.PostedAt,
+			&line.ID,
+			&line.AccountCode,
+			&line.Debit,
+			&line.Credit,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		// rows are ordered by entry, so lines of the same entry are adjacent
+		if len(entries) == 0 || entries[len(entries)-1].ID != entry.ID {
+			entry.Description = description.String
+			if loanID.Valid {
+				entry.LoanID = &loanID.Int64
+			}
+			if reversalOf.Valid {
+				entry.ReversalOf = &reversalOf.Int64
+			}
+			entries = append(entries, &entry)
+		}
+
+		current := entries[len(entries)-1]
+		line.JournalEntryID = current.ID
+		current.Lines = append(current.Lines, &line)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (r *ledgerRepository) GetAccounts(ctx context.Context) ([]*entity.Account, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT code, name, type FROM accounts ORDER BY code`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accounts []*entity.Account
+	for rows.Next() {
+		account := &entity.Account{}
+		if err := rows.Scan(&account.Code, &account.Name, &account.Type); err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return accounts, nil
+}
+
+// GetAccountBalances returns debit minus credit per account for entries posted up to asOf
+func (r *ledgerRepository) GetAccountBalances(ctx context.Context, asOf time.Time) (map[string]float64, error) {
+	query := `
+	SELECT l.account_code, SUM(l.debit) - SUM(l.credit)
+	FROM journal_lines l
+	JOIN journal_entries e ON e.id = l.journal_entry_id
+	WHERE e.posted_at <= ?
+	GROUP BY l.account_code
+	`
+
+	rows, err := r.db.QueryContext(ctx, r.dialect.Rebind(query), asOf)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	balances := map[string]float64{}
+	for rows.Next() {
+		var (
+			code    string
+			balance float64
+		)
+		if err := rows.Scan(&code, &balance); err != nil {
+			return nil, err
+		}
+		balances[code] = balance
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return balances, nil
+}
+
+// GetLoanBalances returns debit minus credit of the account per loan, for loans with entries on it
+func (r *ledgerRepository) GetLoanBalances(ctx context.Context, accountCode string) (map[int64]float64, error) {
+	query := `
+	SELECT e.loan_id, SUM(l.debit) - SUM(l.credit)
+	FROM journal_lines l
+	JOIN journal_entries e ON e.id = l.journal_entry_id
+	WHERE l.account_code = ? AND e.loan_id IS NOT NULL
+	GROUP BY e.loan_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, r.dialect.Rebind(query), accountCode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	balances := map[int64]float64{}
+	for rows.Next() {
+		var (
+			loanID  int64
+			balance float64
+		)
+		if err := rows.Scan(&loanID, &balance); err != nil {
+			return nil, err
+		}
+		balances[loanID] = balance
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return balances, nil
+}
+
+// GetLoanAccountBalances returns debit minus credit per account of the entries of the loan, read inside tx so the
+// entries it posted count
+func (r *ledgerRepository) GetLoanAccountBalances(tx *sql.Tx, loanID int64) (map[string]float64, error) {
+	query := `
+	SELECT l.account_code, SUM(l.debit) - SUM(l.credit)
+	FROM journal_lines l
+	JOIN journal_entries e ON e.id = l.journal_entry_id
+	WHERE e.loan_id = ?
+	GROUP BY l.account_code
+	`
+
+	rows, err := tx.Query(r.dialect.Rebind(query), loanID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	balances := map[string]float64{}
+	for rows.Next() {
+		var (
+			code    string
+			balance float64
+		)
+		if err := rows.Scan(&code, &balance); err != nil {
+			return nil, err
+		}
+		balances[code] = balance
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return balances, nil
+}
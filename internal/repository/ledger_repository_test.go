@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"loan-management/infrastructure"
+	"loan-management/internal/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLedgerRepository(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *sql.DB, dialect infrastructure.Dialect) {
+		loan := createTestLoan(t, db, dialect)
+		repo := NewLedgerRepository(db, dialect)
+		ctx := context.Background()
+
+		accounts, err := repo.GetAccounts(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, accounts, 7)
+
+		postedAt := time.Date(2025, 2, 18, 0, 0, 0, 0, time.UTC)
+		tx, err := db.Begin()
+		assert.NoError(t, err)
+		err = repo.CreateJournalEntry(tx, &entity.JournalEntry{
+			LoanID:        &loan.ID,
+			ReferenceType: entity.JournalReferenceLoan,
+			ReferenceID:   loan.ID,
+			Description:   "Disbursement",
+			PostedAt:      postedAt,
+			Lines: []*entity.JournalLine{
+				{AccountCode: entity.AccountLoanReceivable, Debit: 5000000},
+				{AccountCode: entity.AccountCash, Credit: 5000000},
+			},
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, tx.Commit())
+
+		entries, err := repo.GetJournalEntriesByReference(ctx, entity.JournalReferenceLoan, loan.ID)
+		assert.NoError(t, err)
+		assert.Len(t, entries, 1)
+		assert.Len(t, entries[0].Lines, 2)
+		assert.Equal(t, loan.ID, *entries[0].LoanID)
+		assert.Nil(t, entries[0].ReversalOf)
+
+		balances, err := repo.GetAccountBalances(ctx, postedAt.Add(time.Hour))
+		assert.NoError(t, err)
+		assert.Equal(t, float64(5000000), balances[entity.AccountLoanReceivable])
+		assert.Equal(t, float64(-5000000), balances[entity.AccountCash])
+
+		balances, err = repo.GetAccountBalances(ctx, postedAt.Add(-time.Hour))
+		assert.NoError(t, err)
+		assert.Empty(t, balances)
+
+		loanBalances, err := repo.GetLoanBalances(ctx, entity.AccountLoanReceivable)
+		assert.NoError(t, err)
+		assert.Equal(t, map[int64]float64{loan.ID: 5000000}, loanBalances)
+
+		tx, err = db.Begin()
+		assert.NoError(t, err)
+		err = repo.CreateJournalEntry(tx, &entity.JournalEntry{
+			LoanID:        &loan.ID,
+			ReferenceType: entity.JournalReferenceLoan,
+			ReferenceID:   loan.ID,
+			Description:   "Capitalized interest",
+			PostedAt:      postedAt,
+			Lines: []*entity.JournalLine{
+				{AccountCode: entity.AccountLoanReceivable, Debit: 20},
+				{AccountCode: entity.AccountUnearnedInterest, Credit: 20},
+			},
+		})
+		assert.NoError(t, err)
+		accountBalances, err := repo.GetLoanAccountBalances(tx, loan.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]float64{entity.AccountLoanReceivable: 5000020, entity.AccountCash: -5000000, entity.AccountUnearnedInterest: -20}, accountBalances)
+		assert.NoError(t, tx.Rollback())
+
+		tx, err = db.Begin()
+		assert.NoError(t, err)
+		err = repo.CreateJournalEntry(tx, &entity.JournalEntry{
+			ReferenceType: entity.JournalReferenceTransaction,
+			ReferenceID:   1,
+			Description:   "Without a loan",
+			PostedAt:      postedAt,
+			Lines: []*entity.JournalLine{
+				{AccountCode: entity.AccountCash, Debit: 10},
+				{AccountCode: entity.AccountSuspense, Credit: 10},
+			},
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, tx.Commit())
+
+		entries, err = repo.GetJournalEntriesByLoanID(ctx, loan.ID)
+		assert.NoError(t, err)
+		assert.Len(t, entries, 1)
+		assert.Equal(t, entity.JournalReferenceLoan, entries[0].ReferenceType)
+		assert.Len(t, entries[0].Lines, 2)
+	})
+}
@@ -4,29 +4,44 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"loan-management/infrastructure"
+	"loan-management/internal/apperror"
 	"loan-management/internal/entity"
 	"time"
 )
 
 var (
-	ErrLoanNotFound = errors.New("loan not found")
+	ErrLoanNotFound           = apperror.NotFound("LOAN_NOT_FOUND", "loan not found")
+	ErrLoanNotPending         = apperror.Conflict("LOAN_NOT_PENDING", "Only pending loans can be approved or rejected")
+	ErrVirtualAccountNotFound = apperror.NotFound("VIRTUAL_ACCOUNT_NOT_FOUND", "virtual account not found")
 )
 
 type LoanRepository interface {
 	CreateLoan(tx *sql.Tx, loan *entity.Loan) (*entity.Loan, error)
 	GetLoanByID(ctx context.Context, id int64, status *entity.LoanStatus) (*entity.Loan, error)
+	GetLoanByIDForUpdate(tx *sql.Tx, id int64) (*entity.Loan, error)
+	GetLoanByVirtualAccount(ctx context.Context, number string) (*entity.Loan, error)
 	GetAllLoans(ctx context.Context) ([]*entity.Loan, error)
 	GetLoansByUserID(ctx context.Context, userId int64, status *entity.LoanStatus) ([]*entity.Loan, error)
 	UpdateLoanOutstanding(tx *sql.Tx, outstanding float64, loanID int64) error
+	UpdateLoanDelinquency(tx *sql.Tx, loanID int64, delinquentSince *time.Time) error
+	ApproveLoan(tx *sql.Tx, loanID int64, billingStartDate time.Time, disbursedAt time.Time) error
+	RejectLoan(tx *sql.Tx, loanID int64) error
+	UpdateLoanTerms(tx *sql.Tx, loanID int64, interest float64, tenure int) error
+	UpdateVirtualAccount(tx *sql.Tx, loanID int64, number string, status entity.VirtualAccountStatus) error
+	GetLoanBalances(ctx context.Context) ([]*entity.LoanBalance, error)
+	CreateRestructure(tx *sql.Tx, restructure *entity.LoanRestructure) error
+	GetRestructuresByLoanID(ctx context.Context, loanID int64) ([]*entity.LoanRestructure, error)
 	BeginTx() (*sql.Tx, error)
 }
 
 type loanRepository struct {
-	db *sql.DB
+	db      *sql.DB
+	dialect infrastructure.Dialect
 }
 
-func NewLoanRepository(db *sql.DB) LoanRepository {
-	return &loanRepository{db: db}
+func NewLoanRepository(db *sql.DB, dialect infrastructure.Dialect) LoanRepository {
+	return &loanRepository{db: db, dialect: dialect}
 }
 
 func (r *loanRepository) CreateLoan(tx *sql.Tx, loan *entity.Loan) (*entity.Loan, error) {
@@ -41,10 +56,16 @@ func (r *loanRepository) CreateLoan(tx *sql.Tx, loan *entity.Loan) (*entity.Loan
 			outstanding,
 			status,
 			created_at,
-			billing_start_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+			billing_start_at,
+			disbursed_at,
+			grace_periods,
+			grace_interest,
+			interest_only_periods,
+			repayment_structure,
+			balloon_percent
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
 	`
-	result, err := tx.Exec(query,
+	id, err := r.dialect.InsertReturningID(context.Background(), tx, query,
 		loan.UserID,
 		loan.Interest,
 		loan.InterestType,
@@ -55,22 +76,30 @@ func (r *loanRepository) CreateLoan(tx *sql.Tx, loan *entity.Loan) (*entity.Loan
 		loan.Status,
 		time.Now(),
 		loan.BillingStartDate,
+		loan.DisbursedAt,
+		loan.GracePeriods,
+		loan.GraceInterest,
+		loan.InterestOnlyPeriods,
+		loan.RepaymentStructure,
+		loan.BalloonPercent,
 	)
 	if err != nil {
 		return nil, err
 	}
-
-	id, err := result.LastInsertId()
-	if err != nil {
-		return nil, err
-	}
 	loan.ID = id
 
 	return loan, nil
 }
 
 func scanLoan(scanner interface{ Scan(dest ...any) error }, loan *entity.Loan) error {
-	return scanner.Scan(
+	var (
+		delinquentSince      sql.NullTime
+		disbursedAt          sql.NullTime
+		virtualAccount       sql.NullString
+		virtualAccountStatus sql.NullInt64
+	)
+
+	err := scanner.Scan(
 		&loan.ID,
 		&loan.UserID,
 		&loan.Interest,
@@ -82,7 +111,27 @@ func scanLoan(scanner interface{ Scan(dest ...any) error }, loan *entity.Loan) e
 		&loan.Status,
 		&loan.CreatedAt,
 		&loan.BillingStartDate,
+		&disbursedAt,
+		&delinquentSince,
+		&virtualAccount,
+		&virtualAccountStatus,
+		&loan.GracePeriods,
+		&loan.GraceInterest,
+		&loan.InterestOnlyPeriods,
+		&loan.RepaymentStructure,
+		&loan.BalloonPercent,
 	)
+
+	if delinquentSince.Valid {
+		loan.DelinquentSince = &delinquentSince.Time
+	}
+	if disbursedAt.Valid {
+		loan.DisbursedAt = &disbursedAt.Time
+	}
+	loan.VirtualAccount = virtualAccount.String
+	loan.VirtualAccountStatus = entity.VirtualAccountStatus(virtualAccountStatus.Int64)
+
+	return err
 }
 
 func (r *loanRepository) GetAllLoans(ctx context.Context) ([]*entity.Loan, error) {
@@ -118,9 +167,41 @@ func (r *loanRepository) GetLoanByID(ctx context.Context, id int64, status *enti
 		args = append(args, *status)
 	}
 
-	row := r.db.QueryRowContext(ctx, query, args...)
+	row := r.db.QueryRowContext(ctx, r.dialect.Rebind(query), args...)
 	loan := entity.Loan{}
 	if err := scanLoan(row, &loan); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrLoanNotFound
+		}
+		return nil, err
+	}
+
+	return &loan, nil
+}
+
+// GetLoanByIDForUpdate reads the loan inside tx and locks the row until the tx ends
+func (r *loanRepository) GetLoanByIDForUpdate(tx *sql.Tx, id int64) (*entity.Loan, error) {
+	query := `SELECT * FROM loans WHERE id = ?` + r.dialect.ForUpdate()
+
+	loan := entity.Loan{}
+	if err := scanLoan(tx.QueryRow(r.dialect.Rebind(query), id), &loan); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrLoanNotFound
+		}
+		return nil, err
+	}
+
+	return &loan, nil
+}
+
+func (r *loanRepository) GetLoanByVirtualAccount(ctx context.Context, number string) (*entity.Loan, error) {
+	query := `SELECT * FROM loans WHERE virtual_account = ?`
+
+	loan := entity.Loan{}
+	if err := scanLoan(r.db.QueryRowContext(ctx, r.dialect.Rebind(query), number), &loan); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrVirtualAccountNotFound
+		}
 		return nil, err
 	}
 
@@ -136,7 +217,7 @@ func (r *loanRepository) GetLoansByUserID(ctx context.Context, userID int64, sta
 		args = append(args, *status)
 	}
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := r.db.QueryContext(ctx, r.dialect.Rebind(query), args...)
 
 	if err != nil {
 		return nil, err
@@ -165,16 +246,215 @@ func (r *loanRepository) UpdateLoanOutstanding(tx *sql.Tx, outstanding float64,
 	if outstanding == 0 {
 		query = `UPDATE loans SET outstanding = ?, status = 99 WHERE id = ?`
 	} else {
-		query = `UPDATE loans SET outstanding = ? WHERE id = ?`
+		// a reversed payment can reopen a paid loan
+		query = `UPDATE loans SET outstanding = ?, status = CASE WHEN status = 99 THEN 1 ELSE status END WHERE id = ?`
+	}
+
+	_, err := tx.Exec(r.dialect.Rebind(query), outstanding, loanID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+
+}
+
+func (r *loanRepository) UpdateLoanDelinquency(tx *sql.Tx, loanID int64, delinquentSince *time.Time) error {
+	_, err := tx.Exec(r.dialect.Rebind(`UPDATE loans SET delinquent_since = ? WHERE id = ?`), delinquentSince, loanID)
+	return err
+}
+
+// ApproveLoan activates a pending loan billed from billingStartDate, ErrLoanNotPending when it was approved or
+// rejected meanwhile
+func (r *loanRepository) ApproveLoan(tx *sql.Tx, loanID int64, billingStartDate time.Time, disbursedAt time.Time) error {
+	query := `UPDATE loans SET status = ?, billing_start_at = ?, disbursed_at = ? WHERE id = ? AND status = ?`
+	result, err := tx.Exec(r.dialect.Rebind(query), entity.LoanStatusActive, billingStartDate, disbursedAt, loanID, entity.LoanStatusPending)
+	if err != nil {
+		return err
+	}
+
+	return requirePending(result)
+}
+
+// RejectLoan closes a pending loan and clears its outstanding, ErrLoanNotPending when it was approved or rejected
+// meanwhile
+func (r *loanRepository) RejectLoan(tx *sql.Tx, loanID int64) error {
+	query := `UPDATE loans SET status = ?, outstanding = 0 WHERE id = ? AND status = ?`
+	result, err := tx.Exec(r.dialect.Rebind(query), entity.LoanStatusRejected, loanID, entity.LoanStatusPending)
+	if err != nil {
+		return err
+	}
+
+	return requirePending(result)
+}
+
+func requirePending(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected != 1 {
+		return ErrLoanNotPending
+	}
+
+	return nil
+}
+
+// UpdateLoanTerms sets the rate and tenure of the current schedule of the loan, as a restructure changes them
+func (r *loanRepository) UpdateLoanTerms(tx *sql.Tx, loanID int64, interest float64, tenure int) error {
+	_, err := tx.Exec(r.dialect.Rebind(`UPDATE loans SET interest = ?, tenure = ? WHERE id = ?`), interest, tenure, loanID)
+	return err
+}
+
+func (r *loanRepository) UpdateVirtualAccount(tx *sql.Tx, loanID int64, number string, status entity.VirtualAccountStatus) error {
+	_, err := tx.Exec(r.dialect.Rebind(`UPDATE loans SET virtual_account = ?, virtual_account_status = ? WHERE id = ?`), number, status, loanID)
+	return err
+}
+
+// GetLoanBalances recomputes the unpaid total and principal of every loan from its payments, pending and rejected
+// loans have none
+func (r *loanRepository) GetLoanBalances(ctx context.Context) ([]*entity.LoanBalance, error) {
+	query := `
+	SELECT
+		l.id,
+		l.outstanding,
+		l.status,
+		COALESCE(SUM(CASE WHEN p.status = ? THEN p.total_amount ELSE 0 END), 0),
+		COALESCE(SUM(CASE WHEN p.status = ? THEN p.amount ELSE 0 END), 0),
+		COUNT(p.id)
+	FROM loans l
+	LEFT JOIN payments p ON p.loan_id = l.id
+	WHERE l.status NOT IN (?, ?)
+	GROUP BY l.id, l.outstanding, l.status
+	ORDER BY l.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, r.dialect.Rebind(query), entity.PaymentStatusActive, entity.PaymentStatusActive, entity.LoanStatusPending, entity.LoanStatusRejected)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var balances []*entity.LoanBalance
+	for rows.Next() {
+		balance := entity.LoanBalance{}
+		err := rows.Scan(
+			&balance.LoanID,
+			&balance.Outstanding,
+			&balance.Status,
+			&balance.UnpaidTotal,
+			&balance.UnpaidPrincipal,
+			&balance.PaymentCount,
+		)
+		if err != nil {
+			return nil, err
+		}
+		balances = append(balances, &balance)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	_, err := tx.Exec(query, outstanding, loanID)
+	return balances, nil
+}
+
+func (r *loanRepository) CreateRestructure(tx *sql.Tx, restructure *entity.LoanRestructure) error {
+	query := `
+	INSERT INTO loan_restructures (
+		loan_id,
+		reason,
+		approved_by,
+		previous_interest,
+		previous_tenure,
+		previous_outstanding,
+		interest,
+		tenure,
+		grace_periods,
+		principal,
+		capitalized_arrears,
+		outstanding,
+		first_payment_no,
+		created_at
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	id, err := r.dialect.InsertReturningID(context.Background(), tx, query,
+		restructure.LoanID,
+		restructure.Reason,
+		restructure.ApprovedBy,
+		restructure.PreviousInterest,
+		restructure.PreviousTenure,
+		restructure.PreviousOutstanding,
+		restructure.Interest,
+		restructure.Tenure,
+		restructure.GracePeriods,
+		restructure.Principal,
+		restructure.CapitalizedArrears,
+		restructure.Outstanding,
+		restructure.FirstPaymentNo,
+		restructure.CreatedAt,
+	)
 	if err != nil {
 		return err
 	}
+	restructure.ID = id
 
 	return nil
+}
+
+// GetRestructuresByLoanID returns the restructures of the loan, oldest first
+func (r *loanRepository) GetRestructuresByLoanID(ctx context.Context, loanID int64) ([]*entity.LoanRestructure, error) {
+	query := `
+	SELECT id, loan_id, reason, approved_by, previous_interest, previous_tenure, previous_outstanding, interest, tenure,
+		grace_periods, principal, capitalized_arrears, outstanding, first_payment_no, created_at
+	FROM loan_restructures
+	WHERE loan_id = ?
+	ORDER BY id
+	`
+
+	rows, err := r.db.QueryContext(ctx, r.dialect.Rebind(query), loanID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var restructures []*entity.LoanRestructure
+	for rows.Next() {
+		var (
+			restructure entity.LoanRestructure
+			approvedBy  sql.NullInt64
+		)
+		err := rows.Scan(
+			&restructure.ID,
+			&restructure.LoanID,
+			&restructure.Reason,
+			&approvedBy,
+			&restructure.PreviousInterest,
+			&restructure.PreviousTenure,
+			&restructure.PreviousOutstanding,
+			&restructure.Interest,
+			&restructure.Tenure,
+			&restructure.GracePeriods,
+			&restructure.Principal,
+			&restructure.CapitalizedArrears,
+			&restructure.Outstanding,
+			&restructure.FirstPaymentNo,
+			&restructure.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if approvedBy.Valid {
+			restructure.ApprovedBy = &approvedBy.Int64
+		}
+		restructures = append(restructures, &restructure)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
+	return restructures, nil
 }
 
 func (r *loanRepository) BeginTx() (*sql.Tx, error) {
@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"loan-management/infrastructure"
+	"loan-management/internal/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestLoan(t *testing.T, db *sql.DB, dialect infrastructure.Dialect) *entity.Loan {
+	repo := NewLoanRepository(db, dialect)
+	tx, err := repo.BeginTx()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := &entity.User{Email: "test@test", Name: "test", CreatedAt: time.Now()}
+	if err := NewUserRepository(db, dialect).CreateUser(tx, user); err != nil {
+		tx.Rollback()
+		t.Fatal(err)
+	}
+
+	loan := entity.NewLoan(user.ID, 5000000, 10, 52, entity.InterestTypeFlatAnnual, entity.TenureTypeWeekly, time.Date(2025, 2, 18, 0, 0, 0, 0, time.UTC))
+	loan.Outstanding = 5500000
+	if _, err := repo.CreateLoan(tx, loan); err != nil {
+		tx.Rollback()
+		t.Fatal(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	return loan
+}
+
+func TestLoanRepository(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *sql.DB, dialect infrastructure.Dialect) {
+		repo := NewLoanRepository(db, dialect)
+		ctx := context.Background()
+
+		loan := createTestLoan(t, db, dialect)
+		assert.NotZero(t, loan.ID)
+
+		found, err := repo.GetLoanByID(ctx, loan.ID, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, loan.Outstanding, found.Outstanding)
+		assert.True(t, loan.BillingStartDate.Equal(found.BillingStartDate))
+
+		activeStatus := entity.LoanStatusActive
+		loans, err := repo.GetLoansByUserID(ctx, loan.UserID, &activeStatus)
+		assert.NoError(t, err)
+		assert.Len(t, loans, 1)
+
+		tx, err := repo.BeginTx()
+		assert.NoError(t, err)
+
+		locked, err := repo.GetLoanByIDForUpdate(tx, loan.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, loan.ID, locked.ID)
+
+		delinquentSince := time.Date(2025, 3, 18, 0, 0, 0, 0, time.UTC)
+		assert.NoError(t, repo.UpdateLoanDelinquency(tx, loan.ID, &delinquentSince))
+		assert.NoError(t, tx.Commit())
+
+		found, err = repo.GetLoanByID(ctx, loan.ID, nil)
+		assert.NoError(t, err)
+		assert.True(t, delinquentSince.Equal(*found.DelinquentSince))
+
+		tx, err = repo.BeginTx()
+		assert.NoError(t, err)
+		assert.NoError(t, repo.UpdateLoanOutstanding(tx, 0, loan.ID))
+		assert.NoError(t, repo.UpdateLoanDelinquency(tx, loan.ID, nil))
+		assert.NoError(t, repo.UpdateLoanTerms(tx, loan.ID, 5.2, 26))
+		assert.NoError(t, tx.Commit())
+
+		found, err = repo.GetLoanByID(ctx, loan.ID, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, entity.LoanStatusPaid, found.Status)
+		assert.Equal(t, 5.2, found.Interest)
+		assert.Equal(t, 26, found.Tenure)
+		assert.Nil(t, found.DelinquentSince)
+
+		loans, err = repo.GetAllLoans(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, loans, 1)
+
+		_, err = repo.GetLoanByID(ctx, 69, nil)
+		assert.ErrorIs(t, err, ErrLoanNotFound)
+
+		assert.Empty(t, found.VirtualAccount)
+		number := entity.NewVirtualAccountNumber("8808", loan.ID)
+		tx, err = repo.BeginTx()
+		assert.NoError(t, err)
+		assert.NoError(t, repo.UpdateVirtualAccount(tx, loan.ID, number, entity.VirtualAccountStatusInactive))
+		assert.NoError(t, tx.Commit())
+
+		found, err = repo.GetLoanByVirtualAccount(ctx, number)
+		assert.NoError(t, err)
+		assert.Equal(t, loan.ID, found.ID)
+		assert.Equal(t, entity.VirtualAccountStatusInactive, found.VirtualAccountStatus)
+
+		_, err = repo.GetLoanByVirtualAccount(ctx, entity.NewVirtualAccountNumber("8808", 69))
+		assert.ErrorIs(t, err, ErrVirtualAccountNotFound)
+
+		pending := entity.NewLoan(loan.UserID, 1000000, 10, 26, entity.InterestTypeFlatAnnual, entity.TenureTypeWeekly, loan.BillingStartDate)
+		pending.Status = entity.LoanStatusPending
+		pending.DisbursedAt = nil
+		tx, err = repo.BeginTx()
+		assert.NoError(t, err)
+		_, err = repo.CreateLoan(tx, pending)
+		assert.NoError(t, err)
+		assert.NoError(t, tx.Commit())
+
+		found, err = repo.GetLoanByID(ctx, pending.ID, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, entity.LoanStatusPending, found.Status)
+		assert.Nil(t, found.DisbursedAt)
+
+		balances, err := repo.GetLoanBalances(ctx)
+		assert.NoError(t, err)
+		for _, balance := range balances {
+			assert.NotEqual(t, pending.ID, balance.LoanID)
+		}
+
+		billingStartDate := time.Date(2025, 2, 12, 0, 0, 0, 0, time.UTC)
+		disbursedAt := time.Date(2025, 2, 11, 0, 0, 0, 0, time.UTC)
+		tx, err = repo.BeginTx()
+		assert.NoError(t, err)
+		assert.NoError(t, repo.ApproveLoan(tx, pending.ID, billingStartDate, disbursedAt))
+		assert.ErrorIs(t, repo.ApproveLoan(tx, pending.ID, billingStartDate, disbursedAt), ErrLoanNotPending)
+		assert.ErrorIs(t, repo.RejectLoan(tx, pending.ID), ErrLoanNotPending)
+		assert.NoError(t, tx.Commit())
+
+		found, err = repo.GetLoanByID(ctx, pending.ID, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, entity.LoanStatusActive, found.Status)
+		assert.True(t, billingStartDate.Equal(found.BillingStartDate))
+		assert.True(t, disbursedAt.Equal(*found.DisbursedAt))
+
+		rejected := entity.NewLoan(loan.UserID, 1000000, 10, 26, entity.InterestTypeFlatAnnual, entity.TenureTypeWeekly, loan.BillingStartDate)
+		rejected.Status = entity.LoanStatusPending
+		rejected.DisbursedAt = nil
+		tx, err = repo.BeginTx()
+		assert.NoError(t, err)
+		_, err = repo.CreateLoan(tx, rejected)
+		assert.NoError(t, err)
+		assert.NoError(t, repo.RejectLoan(tx, rejected.ID))
+		assert.ErrorIs(t, repo.RejectLoan(tx, rejected.ID), ErrLoanNotPending)
+		assert.NoError(t, tx.Commit())
+
+		found, err = repo.GetLoanByID(ctx, rejected.ID, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, entity.LoanStatusRejected, found.Status)
+		assert.Zero(t, found.Outstanding)
+
+		balances, err = repo.GetLoanBalances(ctx)
+		assert.NoError(t, err)
+		for _, balance := range balances {
+			assert.NotEqual(t, rejected.ID, balance.LoanID)
+		}
+
+		restructure := &entity.LoanRestructure{
+			LoanID:              loan.ID,
+			Reason:              "hardship",
+			ApprovedBy:          &loan.UserID,
+			PreviousInterest:    10,
+			PreviousTenure:      52,
+			PreviousOutstanding: 2750000,
+			Interest:            5,
+			Tenure:              26,
+			GracePeriods:        4,
+			Principal:           2500000,
+			CapitalizedArrears:  50000,
+			Outstanding:         2562500,
+			FirstPaymentNo:      27,
+			CreatedAt:           time.Date(2025, 8, 18, 0, 0, 0, 0, time.UTC),
+		}
+		tx, err = repo.BeginTx()
+		assert.NoError(t, err)
+		assert.NoError(t, repo.CreateRestructure(tx, restructure))
+		assert.NoError(t, tx.Commit())
+		assert.NotZero(t, restructure.ID)
+
+		restructures, err := repo.GetRestructuresByLoanID(ctx, loan.ID)
+		assert.NoError(t, err)
+		assert.Len(t, restructures, 1)
+		assert.True(t, restructure.CreatedAt.Equal(restructures[0].CreatedAt))
+		restructures[0].CreatedAt = restructure.CreatedAt
+		assert.Equal(t, restructure, restructures[0])
+
+		graced := entity.NewLoan(loan.UserID, 1000000, 10, 26, entity.InterestTypeFlatAnnual, entity.TenureTypeWeekly, loan.BillingStartDate)
+		graced.GracePeriods = 4
+		graced.GraceInterest = entity.GraceInterestCapitalized
+		graced.InterestOnlyPeriods = 6
+		graced.RepaymentStructure = entity.RepaymentStructureBalloon
+		graced.BalloonPercent = 30
+		tx, err = repo.BeginTx()
+		assert.NoError(t, err)
+		_, err = repo.CreateLoan(tx, graced)
+		assert.NoError(t, err)
+		assert.NoError(t, tx.Commit())
+
+		found, err = repo.GetLoanByID(ctx, graced.ID, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 4, found.GracePeriods)
+		assert.Equal(t, entity.GraceInterestCapitalized, found.GraceInterest)
+		assert.Equal(t, 6, found.InterestOnlyPeriods)
+		assert.Equal(t, entity.RepaymentStructureBalloon, found.RepaymentStructure)
+		assert.Equal(t, float64(30), found.BalloonPercent)
+	})
+}
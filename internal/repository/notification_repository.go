@@ -0,0 +1,261 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"loan-management/infrastructure"
+	"loan-management/internal/apperror"
+	"loan-management/internal/entity"
+	"strings"
+)
+
+var (
+	ErrNotificationNotFound            = apperror.NotFound("NOTIFICATION_NOT_FOUND", "notification not found")
+	ErrNotificationPreferencesNotFound = apperror.NotFound("NOTIFICATION_PREFERENCES_NOT_FOUND", "notification preferences not found")
+)
+
+const defaultNotificationLimit = 100
+
+type NotificationRepository interface {
+	GetPreferences(ctx context.Context, userID int64) (*entity.NotificationPreferences, error)
+	SavePreferences(tx *sql.Tx, preferences *entity.NotificationPreferences) error
+	CreateNotification(ctx context.Context, notification *entity.Notification) error
+	UpdateNotification(ctx context.Context, notification *entity.Notification) error
+	GetNotificationByDedupeKey(ctx context.Context, dedupeKey string) (*entity.Notification, error)
+	GetNotifications(ctx context.Context, filter entity.NotificationFilter) ([]*entity.Notification, error)
+	GetFailedNotifications(ctx context.Context, maxAttempts int, limit int) ([]*entity.Notification, error)
+	BeginTx() (*sql.Tx, error)
+}
+
+type notificationRepository struct {
+	db      *sql.DB
+	dialect infrastructure.Dialect
+}
+
+func NewNotificationRepository(db *sql.DB, dialect infrastructure.Dialect) NotificationRepository {
+	return &notificationRepository{db: db, dialect: dialect}
+}
+
+const notificationColumns = `id, user_id, loan_id, kind, channel, address, dedupe_key, subject, body, status, attempts, last_error, created_at, sent_at`
+
+func scanNotification(scanner interface{ Scan(dest ...any) error }, notification *entity.Notification) error {
+	var (
+		lastError sql.NullString
+		sentAt    sql.NullTime
+	)
+
+	err := scanner.Scan(
+		&notification.ID,
+		&notification.UserID,
+		&notification.LoanID,
+		&notification.Kind,
+		&notification.Channel,
+		&notification.Address,
+		&notification.DedupeKey,
+		&notification.Subject,
+		&notification.Body,
+		&notification.Status,
+		&notification.Attempts,
+		&lastError,
+		&notification.CreatedAt,
+		&sentAt,
+	)
+
+	notification.LastError = lastError.String
+	if sentAt.Valid {
+		notification.SentAt = &sentAt.Time
+	}
+
+	return err
+}
+
+func (r *notificationRepository) GetPreferences(ctx context.Context, userID int64) (*entity.NotificationPreferences, error) {
+	query := `SELECT user_id, locale, channels, muted_kinds, updated_at FROM notification_preferences WHERE user_id = ?`
+
+	var (
+		channels, mutedKinds string
+		updatedAt            sql.NullTime
+	)
+	preferences := &entity.NotificationPreferences{}
+	err := r.db.QueryRowContext(ctx, r.dialect.Rebind(query), userID).Scan(&preferences.UserID, &preferences.Locale, &channels, &mutedKinds, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotificationPreferencesNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(channels), &preferences.Channels); err != nil {
+		return nil, err
+	}
+	for _, kind := range strings.Split(mutedKinds, ",") {
+		if kind != "" {
+			preferences.MutedKinds = append(preferences.MutedKinds, entity.NotificationKind(kind))
+		}
+	}
+	if updatedAt.Valid {
+		preferences.UpdatedAt = &updatedAt.Time
+	}
+
+	return preferences, nil
+}
+
+// SavePreferences replaces the preferences of the user
+func (r *notificationRepository) SavePreferences(tx *sql.Tx, preferences *entity.NotificationPreferences) error {
+	channels, err := json.Marshal(preferences.Channels)
+	if err != nil {
+		return err
+	}
+
+	mutedKinds := make([]string, len(preferences.MutedKinds))
+	for i, kind := range preferences.MutedKinds {
+		mutedKinds[i] = string(kind)
+	}
+
+	query := `
+	INSERT INTO notification_preferences (user_id, locale, channels, muted_kinds, updated_at)
+	VALUES (?, ?, ?, ?, ?)
+	ON CONFLICT (user_id) DO UPDATE
+	SET	locale = excluded.locale,
+		channels = excluded.channels,
+		muted_kinds = excluded.muted_kinds,
+		updated_at = excluded.updated_at
+	`
+
+	_, err = tx.Exec(r.dialect.Rebind(query), preferences.UserID, preferences.Locale, string(channels), strings.Join(mutedKinds, ","), preferences.UpdatedAt)
+	return err
+}
+
+// CreateNotification logs a notification before it is sent, the unique dedupe key rejects a second one
+func (r *notificationRepository) CreateNotification(ctx context.Context, notification *entity.Notification) error {
+	query := `
+		INSERT INTO notifications (user_id, loan_id, kind, channel, address, dedupe_key, subject, body, status, attempts, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	id, err := r.dialect.InsertReturningID(
+		ctx,
+		r.db,
+		query,
+		notification.UserID,
+		notification.LoanID,
+		notification.Kind,
+		notification.Channel,
+		notification.Address,
+		notification.DedupeKey,
+		notification.Subject,
+		notification.Body,
+		notification.Status,
+		notification.Attempts,
+		notification.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	notification.ID = id
+	return nil
+}
+
+// UpdateNotification saves the outcome of a send
+func (r *notificationRepository) UpdateNotification(ctx context.Context, notification *entity.Notification) error {
+	query := `
+	UPDATE notifications
+	SET	status = ?,
+		attempts = ?,
+		last_error = ?,
+		sent_at = ?
+	WHERE id = ?
+	`
+
+	_, err := r.db.ExecContext(ctx, r.dialect.Rebind(query),
+		notification.Status,
+		notification.Attempts,
+		nullString(notification.LastError),
+		notification.SentAt,
+		notification.ID,
+	)
+	return err
+}
+
+func (r *notificationRepository) GetNotificationByDedupeKey(ctx context.Context, dedupeKey string) (*entity.Notification, error) {
+	query := `SELECT ` + notificationColumns + ` FROM notifications WHERE dedupe_key = ?`
+
+	notification := &entity.Notification{}
+	if err := scanNotification(r.db.QueryRowContext(ctx, r.dialect.Rebind(query), dedupeKey), notification); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotificationNotFound
+		}
+		return nil, err
+	}
+
+	return notification, nil
+}
+
+// GetNotifications returns the newest notifications first
+func (r *notificationRepository) GetNotifications(ctx context.Context, filter entity.NotificationFilter) ([]*entity.Notification, error) {
+	query := `SELECT ` + notificationColumns + ` FROM notifications WHERE 1 = 1`
+	var args []any
+
+	if filter.UserID != 0 {
+		query += ` AND user_id = ?`
+		args = append(args, filter.UserID)
+	}
+	if filter.LoanID != 0 {
+		query += ` AND loan_id = ?`
+		args = append(args, filter.LoanID)
+	}
+	if filter.Kind != "" {
+		query += ` AND kind = ?`
+		args = append(args, filter.Kind)
+	}
+	if filter.Status != 0 {
+		query += ` AND status = ?`
+		args = append(args, filter.Status)
+	}
+
+	limit := filter.Limit
+	if limit == 0 {
+		limit = defaultNotificationLimit
+	}
+	query += ` ORDER BY id DESC LIMIT ?`
+	args = append(args, limit)
+
+	return r.queryNotifications(ctx, query, args...)
+}
+
+// GetFailedNotifications returns the failed notifications that have attempts left, oldest first
+func (r *notificationRepository) GetFailedNotifications(ctx context.Context, maxAttempts int, limit int) ([]*entity.Notification, error) {
+	query := `SELECT ` + notificationColumns + ` FROM notifications WHERE status = ? AND attempts < ? ORDER BY id LIMIT ?`
+
+	return r.queryNotifications(ctx, query, entity.NotificationStatusFailed, maxAttempts, limit)
+}
+
+func (r *notificationRepository) queryNotifications(ctx context.Context, query string, args ...any) ([]*entity.Notification, error) {
+	rows, err := r.db.QueryContext(ctx, r.dialect.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []*entity.Notification
+	for rows.Next() {
+		notification := &entity.Notification{}
+		if err := scanNotification(rows, notification); err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, notification)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+func (r *notificationRepository) BeginTx() (*sql.Tx, error) {
+	return r.db.Begin()
+}
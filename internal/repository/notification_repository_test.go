@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"loan-management/infrastructure"
+	"loan-management/internal/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotificationRepository(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *sql.DB, dialect infrastructure.Dialect) {
+		repo := NewNotificationRepository(db, dialect)
+		ctx := context.Background()
+		createdAt := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+
+		_, err := repo.GetPreferences(ctx, 1)
+		assert.ErrorIs(t, err, ErrNotificationPreferencesNotFound)
+
+		preferences := &entity.NotificationPreferences{
+			UserID:     1,
+			Locale:     "en",
+			Channels:   []entity.NotificationChannelPreference{{Channel: entity.NotificationChannelEmail, Address: "budi@example.com", Enabled: true}},
+			MutedKinds: []entity.NotificationKind{},
+			UpdatedAt:  &createdAt,
+		}
+		tx, err := repo.BeginTx()
+		assert.NoError(t, err)
+		assert.NoError(t, repo.SavePreferences(tx, preferences))
+		assert.NoError(t, tx.Commit())
+
+		preferences.Locale = "id"
+		preferences.Channels = append(preferences.Channels, entity.NotificationChannelPreference{Channel: entity.NotificationChannelSMS, Address: "+628123", Enabled: false})
+		preferences.MutedKinds = []entity.NotificationKind{entity.NotificationOverdue, entity.NotificationLoanPaidOff}
+		tx, err = repo.BeginTx()
+		assert.NoError(t, err)
+		assert.NoError(t, repo.SavePreferences(tx, preferences))
+		assert.NoError(t, tx.Commit())
+
+		found, err := repo.GetPreferences(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, "id", found.Locale)
+		assert.Equal(t, preferences.Channels, found.Channels)
+		assert.Equal(t, preferences.MutedKinds, found.MutedKinds)
+		assert.True(t, createdAt.Equal(*found.UpdatedAt))
+
+		_, err = repo.GetNotificationByDedupeKey(ctx, "upcoming_due:11:email")
+		assert.ErrorIs(t, err, ErrNotificationNotFound)
+
+		first := &entity.Notification{UserID: 1, LoanID: 1, Kind: entity.NotificationUpcomingDue, Channel: entity.NotificationChannelEmail, Address: "budi@example.com", DedupeKey: "upcoming_due:11:email", Subject: "Due soon", Body: "Pay up", Status: entity.NotificationStatusPending, CreatedAt: createdAt}
+		second := &entity.Notification{UserID: 2, LoanID: 2, Kind: entity.NotificationOverdue, Channel: entity.NotificationChannelEmail, Address: "siti@example.com", DedupeKey: "overdue:12:email", Subject: "Overdue", Body: "Pay now", Status: entity.NotificationStatusPending, CreatedAt: createdAt}
+		assert.NoError(t, repo.CreateNotification(ctx, first))
+		assert.NoError(t, repo.CreateNotification(ctx, second))
+		assert.Error(t, repo.CreateNotification(ctx, &entity.Notification{UserID: 1, LoanID: 1, Kind: entity.NotificationUpcomingDue, Channel: entity.NotificationChannelEmail, DedupeKey: "upcoming_due:11:email", Status: entity.NotificationStatusPending, CreatedAt: createdAt}))
+
+		sentAt := createdAt.Add(time.Minute)
+		first.Status = entity.NotificationStatusSent
+		first.Attempts = 1
+		first.SentAt = &sentAt
+		assert.NoError(t, repo.UpdateNotification(ctx, first))
+
+		second.Status = entity.NotificationStatusFailed
+		second.Attempts = 1
+		second.LastError = "connection refused"
+		assert.NoError(t, repo.UpdateNotification(ctx, second))
+
+		sent, err := repo.GetNotificationByDedupeKey(ctx, "upcoming_due:11:email")
+		assert.NoError(t, err)
+		assert.Equal(t, entity.NotificationStatusSent, sent.Status)
+		assert.True(t, sentAt.Equal(*sent.SentAt))
+		assert.Empty(t, sent.LastError)
+
+		failed, err := repo.GetFailedNotifications(ctx, 3, 10)
+		assert.NoError(t, err)
+		assert.Len(t, failed, 1)
+		assert.Equal(t, "connection refused", failed[0].LastError)
+
+		failed, err = repo.GetFailedNotifications(ctx, 1, 10)
+		assert.NoError(t, err)
+		assert.Empty(t, failed)
+
+		notifications, err := repo.GetNotifications(ctx, entity.NotificationFilter{})
+		assert.NoError(t, err)
+		assert.Len(t, notifications, 2)
+		assert.Equal(t, second.ID, notifications[0].ID)
+
+		notifications, err = repo.GetNotifications(ctx, entity.NotificationFilter{UserID: 1, Kind: entity.NotificationUpcomingDue})
+		assert.NoError(t, err)
+		assert.Len(t, notifications, 1)
+		assert.Equal(t, first.ID, notifications[0].ID)
+	})
+}
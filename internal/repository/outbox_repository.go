@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"loan-management/infrastructure"
+	"loan-management/internal/apperror"
+	"loan-management/internal/entity"
+	"time"
+)
+
+var (
+	ErrEventNotFound = apperror.NotFound("EVENT_NOT_FOUND", "event not found")
+)
+
+type OutboxRepository interface {
+	CreateEvent(tx *sql.Tx, event *entity.Event) error
+	GetEventByID(ctx context.Context, id int64) (*entity.Event, error)
+	GetPendingEvents(ctx context.Context, dueBefore time.Time, limit int) ([]*entity.Event, error)
+	UpdateEventDelivery(ctx context.Context, event *entity.Event) error
+}
+
+type outboxRepository struct {
+	db      *sql.DB
+	dialect infrastructure.Dialect
+}
+
+func NewOutboxRepository(db *sql.DB, dialect infrastructure.Dialect) OutboxRepository {
+	return &outboxRepository{db: db, dialect: dialect}
+}
+
+func (r *outboxRepository) CreateEvent(tx *sql.Tx, event *entity.Event) error {
+	query := `
+	INSERT INTO outbox_events (
+		type,
+		aggregate_type,
+		aggregate_id,
+		payload,
+		status,
+		attempts,
+		next_attempt_at,
+		occurred_at
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	id, err := r.dialect.InsertReturningID(
+		context.Background(),
+		tx,
+		query,
+		event.Type,
+		event.AggregateType,
+		event.AggregateID,
+		string(event.Payload),
+		event.Status,
+		event.Attempts,
+		event.NextAttemptAt,
+		event.OccurredAt,
+	)
+	if err != nil {
+		return err
+	}
+	event.ID = id
+
+	return nil
+}
+
+const eventColumns = `id, type, aggregate_type, aggregate_id, payload, status, attempts, last_error, next_attempt_at, occurred_at, delivered_at`
+
+func scanEvent(scanner interface{ Scan(dest ...any) error }, event *entity.Event) error {
+	var (
+		payload     string
+		lastError   sql.NullString
+		deliveredAt sql.NullTime
+	)
+
+	err := scanner.Scan(
+		&event.ID,
+		&event.Type,
+		&event.AggregateType,
+		&event.AggregateID,
+		&payload,
+		&event.Status,
+		&event.Attempts,
+		&lastError,
+		&event.NextAttemptAt,
+		&event.OccurredAt,
+		&deliveredAt,
+	)
+
+	event.Payload = []byte(payload)
+	event.LastError = lastError.String
+	if deliveredAt.Valid {
+		event.DeliveredAt = &deliveredAt.Time
+	}
+
+	return err
+}
+
+func (r *outboxRepository) GetEventByID(ctx context.Context, id int64) (*entity.Event, error) {
+	query := `SELECT ` + eventColumns + ` FROM outbox_events WHERE id = ?`
+
+	event := &entity.Event{}
+	if err := scanEvent(r.db.QueryRowContext(ctx, r.dialect.Rebind(query), id), event); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrEventNotFound
+		}
+		return nil, err
+	}
+
+	return event, nil
+}
+
+// GetPendingEvents returns the oldest pending events due for a delivery attempt
+func (r *outboxRepository) GetPendingEvents(ctx context.Context, dueBefore time.Time, limit int) ([]*entity.Event, error) {
+	query := `
+	SELECT ` + eventColumns + `
+	FROM outbox_events
+	WHERE status = ? AND next_attempt_at <= ?
+	ORDER BY id
+	LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, r.dialect.Rebind(query), entity.EventStatusPending, dueBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*entity.Event
+	for rows.Next() {
+		event := &entity.Event{}
+		if err := scanEvent(rows, event); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// UpdateEventDelivery saves the outcome of a delivery attempt
+func (r *outboxRepository) UpdateEventDelivery(ctx context.Context, event *entity.Event) error {
+	query := `
+	UPDATE outbox_events
+	SET	status = ?,
+		attempts = ?,
+		last_error = ?,
+		next_attempt_at = ?,
+		delivered_at = ?
+	WHERE id = ?
+	`
+
+	_, err := r.db.ExecContext(ctx, r.dialect.Rebind(query),
+		event.Status,
+		event.Attempts,
+		event.LastError,
+		event.NextAttemptAt,
+		event.DeliveredAt,
+		event.ID,
+	)
+	return err
+}
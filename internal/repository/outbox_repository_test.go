@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"loan-management/infrastructure"
+	"loan-management/internal/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutboxRepository(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *sql.DB, dialect infrastructure.Dialect) {
+		repo := NewOutboxRepository(db, dialect)
+		ctx := context.Background()
+		occurredAt := time.Date(2025, 2, 18, 0, 0, 0, 0, time.UTC)
+
+		tx, err := db.Begin()
+		assert.NoError(t, err)
+		event := &entity.Event{
+			Type:          entity.EventLoanCreated,
+			AggregateType: entity.EventAggregateLoan,
+			AggregateID:   1,
+			Payload:       json.RawMessage(`{"loan_id":1}`),
+			Status:        entity.EventStatusPending,
+			NextAttemptAt: occurredAt,
+			OccurredAt:    occurredAt,
+		}
+		assert.NoError(t, repo.CreateEvent(tx, event))
+		assert.NoError(t, tx.Commit())
+		assert.NotZero(t, event.ID)
+
+		pending, err := repo.GetPendingEvents(ctx, occurredAt.Add(-time.Second), 10)
+		assert.NoError(t, err)
+		assert.Empty(t, pending)
+
+		pending, err = repo.GetPendingEvents(ctx, occurredAt, 10)
+		assert.NoError(t, err)
+		assert.Len(t, pending, 1)
+		assert.JSONEq(t, `{"loan_id":1}`, string(pending[0].Payload))
+		assert.Nil(t, pending[0].DeliveredAt)
+
+		// a failed attempt postpones the event
+		pending[0].Attempts = 1
+		pending[0].LastError = "webhook: timeout"
+		pending[0].NextAttemptAt = occurredAt.Add(time.Minute)
+		assert.NoError(t, repo.UpdateEventDelivery(ctx, pending[0]))
+
+		retried, err := repo.GetPendingEvents(ctx, occurredAt.Add(time.Minute), 10)
+		assert.NoError(t, err)
+		assert.Len(t, retried, 1)
+		assert.Equal(t, 1, retried[0].Attempts)
+		assert.Equal(t, "webhook: timeout", retried[0].LastError)
+
+		deliveredAt := occurredAt.Add(time.Minute)
+		retried[0].Status = entity.EventStatusDelivered
+		retried[0].DeliveredAt = &deliveredAt
+		assert.NoError(t, repo.UpdateEventDelivery(ctx, retried[0]))
+
+		pending, err = repo.GetPendingEvents(ctx, occurredAt.Add(time.Hour), 10)
+		assert.NoError(t, err)
+		assert.Empty(t, pending)
+
+		found, err := repo.GetEventByID(ctx, event.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, entity.EventStatusDelivered, found.Status)
+
+		_, err = repo.GetEventByID(ctx, 69)
+		assert.ErrorIs(t, err, ErrEventNotFound)
+	})
+}
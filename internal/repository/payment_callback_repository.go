@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"loan-management/infrastructure"
+	"loan-management/internal/apperror"
+	"loan-management/internal/entity"
+)
+
+var (
+	ErrPaymentCallbackNotFound = apperror.NotFound("PAYMENT_CALLBACK_NOT_FOUND", "payment callback not found")
+)
+
+const defaultPaymentCallbackLimit = 100
+
+type PaymentCallbackRepository interface {
+	CreatePaymentCallback(ctx context.Context, callback *entity.PaymentCallback) error
+	UpdatePaymentCallback(ctx context.Context, callback *entity.PaymentCallback) error
+	GetPaymentCallbackByID(ctx context.Context, id int64) (*entity.PaymentCallback, error)
+	GetPaymentCallbacks(ctx context.Context, filter entity.PaymentCallbackFilter) ([]*entity.PaymentCallback, error)
+}
+
+type paymentCallbackRepository struct {
+	db      *sql.DB
+	dialect infrastructure.Dialect
+}
+
+func NewPaymentCallbackRepository(db *sql.DB, dialect infrastructure.Dialect) PaymentCallbackRepository {
+	return &paymentCallbackRepository{db: db, dialect: dialect}
+}
+
+const paymentCallbackColumns = `id, provider, external_id, reference, loan_id, amount, payment_status, status, transaction_id, error, headers, payload, received_at, processed_at`
+
+func scanPaymentCallback(scanner interface{ Scan(dest ...any) error }, callback *entity.PaymentCallback) error {
+	var (
+		externalID, reference, paymentStatus, callbackError sql.NullString
+		loanID, transactionID                               sql.NullInt64
+		amount                                              sql.NullFloat64
+		headers                                             string
+		processedAt                                         sql.NullTime
+	)
+
+	err := scanner.Scan(
+		&callback.ID,
+		&callback.Provider,
+		&externalID,
+		&reference,
+		&loanID,
+		&amount,
+		&paymentStatus,
+		&callback.Status,
+		&transactionID,
+		&callbackError,
+		&headers,
+		&callback.Payload,
+		&callback.ReceivedAt,
+		&processedAt,
+	)
+
+	callback.ExternalID = externalID.String
+	callback.Reference = reference.String
+	callback.Amount = amount.Float64
+	callback.PaymentStatus = entity.GatewayPaymentStatus(paymentStatus.String)
+	callback.Error = callbackError.String
+	callback.Headers = []byte(headers)
+	if loanID.Valid {
+		callback.LoanID = &loanID.Int64
+	}
+	if transactionID.Valid {
+		callback.TransactionID = &transactionID.Int64
+	}
+	if processedAt.Valid {
+		callback.ProcessedAt = &processedAt.Time
+	}
+
+	return err
+}
+
+// CreatePaymentCallback stores the raw callback before it is verified, so rejected callbacks are kept as well
+func (r *paymentCallbackRepository) CreatePaymentCallback(ctx context.Context, callback *entity.PaymentCallback) error {
+	query := `
+		INSERT INTO payment_callbacks (provider, status, headers, payload, received_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	id, err := r.dialect.InsertReturningID(ctx, r.db, query, callback.Provider, callback.Status, string(callback.Headers), callback.Payload, callback.ReceivedAt)
+	if err != nil {
+		return err
+	}
+
+	callback.ID = id
+	return nil
+}
+
+// UpdatePaymentCallback saves what was parsed from the callback and the outcome of posting it
+func (r *paymentCallbackRepository) UpdatePaymentCallback(ctx context.Context, callback *entity.PaymentCallback) error {
+	query := `
+	UPDATE payment_callbacks
+	SET	external_id = ?,
+		reference = ?,
+		loan_id = ?,
+		amount = ?,
+		payment_status = ?,
+		status = ?,
+		transaction_id = ?,
+		error = ?,
+		processed_at = ?
+	WHERE id = ?
+	`
+
+	_, err := r.db.ExecContext(ctx, r.dialect.Rebind(query),
+		nullString(callback.ExternalID),
+		nullString(callback.Reference),
+		callback.LoanID,
+		callback.Amount,
+		nullString(string(callback.PaymentStatus)),
+		callback.Status,
+		callback.TransactionID,
+		nullString(callback.Error),
+		callback.ProcessedAt,
+		callback.ID,
+	)
+	return err
+}
+
+func (r *paymentCallbackRepository) GetPaymentCallbackByID(ctx context.Context, id int64) (*entity.PaymentCallback, error) {
+	query := `SELECT ` + paymentCallbackColumns + ` FROM payment_callbacks WHERE id = ?`
+
+	callback := &entity.PaymentCallback{}
+	if err := scanPaymentCallback(r.db.QueryRowContext(ctx, r.dialect.Rebind(query), id), callback); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPaymentCallbackNotFound
+		}
+		return nil, err
+	}
+
+	return callback, nil
+}
+
+// GetPaymentCallbacks returns the newest callbacks first
+func (r *paymentCallbackRepository) GetPaymentCallbacks(ctx context.Context, filter entity.PaymentCallbackFilter) ([]*entity.PaymentCallback, error) {
+	query := `SELECT ` + paymentCallbackColumns + ` FROM payment_callbacks WHERE 1 = 1`
+	var args []any
+
+	if filter.Provider != "" {
+		query += ` AND provider = ?`
+		args = append(args, filter.Provider)
+	}
+	if filter.Status != 0 {
+		query += ` AND status = ?`
+		args = append(args, filter.Status)
+	}
+
+	limit := filter.Limit
+	if limit == 0 {
+		limit = defaultPaymentCallbackLimit
+	}
+	query += ` ORDER BY id DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, r.dialect.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var callbacks []*entity.PaymentCallback
+	for rows.Next() {
+		callback := &entity.PaymentCallback{}
+		if err := scanPaymentCallback(rows, callback); err != nil {
+			return nil, err
+		}
+		callbacks = append(callbacks, callback)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return callbacks, nil
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"loan-management/infrastructure"
+	"loan-management/internal/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaymentCallbackRepository(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *sql.DB, dialect infrastructure.Dialect) {
+		repo := NewPaymentCallbackRepository(db, dialect)
+		ctx := context.Background()
+		receivedAt := time.Date(2025, 2, 18, 0, 0, 0, 0, time.UTC)
+
+		_, err := repo.GetPaymentCallbackByID(ctx, 1)
+		assert.ErrorIs(t, err, ErrPaymentCallbackNotFound)
+
+		first := &entity.PaymentCallback{
+			Provider:   "fake",
+			Status:     entity.PaymentCallbackStatusReceived,
+			Headers:    json.RawMessage(`{"X-Fake-Signature":["abc"]}`),
+			Payload:    `{"id":"pay_1"}`,
+			ReceivedAt: receivedAt,
+		}
+		assert.NoError(t, repo.CreatePaymentCallback(ctx, first))
+		assert.NotZero(t, first.ID)
+
+		second := &entity.PaymentCallback{
+			Provider:   "fake",
+			Status:     entity.PaymentCallbackStatusReceived,
+			Headers:    json.RawMessage(`{}`),
+			Payload:    `not json`,
+			ReceivedAt: receivedAt.Add(time.Minute),
+		}
+		assert.NoError(t, repo.CreatePaymentCallback(ctx, second))
+
+		loanID, transactionID := int64(3), int64(5)
+		processedAt := receivedAt.Add(time.Second)
+		first.ExternalID = "pay_1"
+		first.Reference = "LOAN-3"
+		first.LoanID = &loanID
+		first.Amount = 110
+		first.PaymentStatus = entity.GatewayPaymentStatusPaid
+		first.Status = entity.PaymentCallbackStatusProcessed
+		first.TransactionID = &transactionID
+		first.ProcessedAt = &processedAt
+		assert.NoError(t, repo.UpdatePaymentCallback(ctx, first))
+
+		found, err := repo.GetPaymentCallbackByID(ctx, first.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "pay_1", found.ExternalID)
+		assert.Equal(t, loanID, *found.LoanID)
+		assert.Equal(t, transactionID, *found.TransactionID)
+		assert.Equal(t, entity.PaymentCallbackStatusProcessed, found.Status)
+		assert.JSONEq(t, `{"X-Fake-Signature":["abc"]}`, string(found.Headers))
+		assert.True(t, processedAt.Equal(*found.ProcessedAt))
+
+		callbacks, err := repo.GetPaymentCallbacks(ctx, entity.PaymentCallbackFilter{Provider: "fake"})
+		assert.NoError(t, err)
+		assert.Len(t, callbacks, 2)
+		assert.Equal(t, second.ID, callbacks[0].ID)
+		assert.Nil(t, callbacks[0].LoanID)
+
+		callbacks, err = repo.GetPaymentCallbacks(ctx, entity.PaymentCallbackFilter{Status: entity.PaymentCallbackStatusProcessed})
+		assert.NoError(t, err)
+		assert.Len(t, callbacks, 1)
+		assert.Equal(t, first.ID, callbacks[0].ID)
+	})
+}
@@ -4,28 +4,39 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"loan-management/infrastructure"
+	"loan-management/internal/apperror"
 	"loan-management/internal/entity"
 	"time"
 )
 
 var (
-	ErrPaymentNotFound = errors.New("loan not found")
+	ErrPaymentNotFound    = apperror.NotFound("PAYMENT_NOT_FOUND", "payment not found")
+	ErrPaymentAlreadyPaid = apperror.Conflict("PAYMENT_ALREADY_PAID", "The payment is no longer due")
 )
 
 type PaymentRepository interface {
 	CreatePayment(tx *sql.Tx, payments []*entity.Payment) error
 	GetPaymentByID(ctx context.Context, id int64) (*entity.Payment, error)
 	GetAllPayments(ctx context.Context, status *entity.PaymentStatus) ([]*entity.Payment, error)
-	GetPaymentsByLoanID(ctx context.Context, loanId int64, status *entity.PaymentStatus, dueBefore *time.Time) ([]*entity.Payment, error)
+	GetPaymentsByLoanID(ctx context.Context, loanId int64, status *entity.PaymentStatus, dueBefore *entity.Date) ([]*entity.Payment, error)
+	GetPaymentsByTransactionID(ctx context.Context, transactionID int64) ([]*entity.Payment, error)
 	PayPayment(tx *sql.Tx, paymentId int64, transactionId int64, paidAt time.Time) error
+	UnpayPayments(tx *sql.Tx, transactionID int64) error
+	CloseUnpaidPayments(tx *sql.Tx, loanID int64, closedAt time.Time) error
+	RescheduleUnpaidPayments(tx *sql.Tx, dueDate entity.Date, newDueDate entity.Date) (int64, error)
+	RestoreRescheduledPayments(tx *sql.Tx, originalDueDate entity.Date, dueDate entity.Date) (int64, error)
+	GetOrphanPayments(ctx context.Context) ([]*entity.Payment, error)
+	GetUnpaidPaymentsDueBetween(ctx context.Context, after entity.Date, until entity.Date) ([]*entity.Payment, error)
 }
 
 type paymentRepository struct {
-	db *sql.DB
+	db      *sql.DB
+	dialect infrastructure.Dialect
 }
 
-func NewPaymentRepository(db *sql.DB) PaymentRepository {
-	return &paymentRepository{db: db}
+func NewPaymentRepository(db *sql.DB, dialect infrastructure.Dialect) PaymentRepository {
+	return &paymentRepository{db: db, dialect: dialect}
 }
 
 func (r *paymentRepository) CreatePayment(tx *sql.Tx, payments []*entity.Payment) error {
@@ -47,7 +58,7 @@ func (r *paymentRepository) CreatePayment(tx *sql.Tx, payments []*entity.Payment
 		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?);
 	`
 
-	stmt, err := tx.Prepare(query)
+	stmt, err := tx.Prepare(r.dialect.Rebind(query))
 	if err != nil {
 		return err
 	}
@@ -107,7 +118,7 @@ func (r *paymentRepository) GetPaymentByID(ctx context.Context, id int64) (*enti
 
 	payment := &entity.Payment{}
 
-	err := scanPayment(r.db.QueryRowContext(ctx, query, id), payment)
+	err := scanPayment(r.db.QueryRowContext(ctx, r.dialect.Rebind(query), id), payment)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -131,7 +142,7 @@ func (r *paymentRepository) GetAllPayments(ctx context.Context, status *entity.P
 		args = append(args, *status)
 	}
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := r.db.QueryContext(ctx, r.dialect.Rebind(query), args...)
 	if err != nil {
 		return nil, err
 	}
@@ -153,7 +164,7 @@ func (r *paymentRepository) GetAllPayments(ctx context.Context, status *entity.P
 	return payments, nil
 }
 
-func (r *paymentRepository) GetPaymentsByLoanID(ctx context.Context, loanId int64, status *entity.PaymentStatus, dueBefore *time.Time) ([]*entity.Payment, error) {
+func (r *paymentRepository) GetPaymentsByLoanID(ctx context.Context, loanId int64, status *entity.PaymentStatus, dueBefore *entity.Date) ([]*entity.Payment, error) {
 	query := `
 		SELECT id, loan_id, transaction_id, due_date, payment_no, amount, interest, total_amount, status, paid_at, created_at
 		FROM payments
@@ -168,10 +179,12 @@ func (r *paymentRepository) GetPaymentsByLoanID(ctx context.Context, loanId int6
 
 	if dueBefore != nil {
 		query += ` AND due_date <= ?`
-		args = append(args, dueBefore)
+		args = append(args, *dueBefore)
 	}
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	query += ` ORDER BY payment_no`
+
+	rows, err := r.db.QueryContext(ctx, r.dialect.Rebind(query), args...)
 	if err != nil {
 		return nil, err
 	}
@@ -193,18 +206,178 @@ func (r *paymentRepository) GetPaymentsByLoanID(ctx context.Context, loanId int6
 	return payments, nil
 }
 
+func (r *paymentRepository) GetPaymentsByTransactionID(ctx context.Context, transactionID int64) ([]*entity.Payment, error) {
+	query := `
+		SELECT id, loan_id, transaction_id, due_date, payment_no, amount, interest, total_amount, status, paid_at, created_at
+		FROM payments
+		WHERE transaction_id = ?
+		ORDER BY loan_id, payment_no
+	`
+
+	rows, err := r.db.QueryContext(ctx, r.dialect.Rebind(query), transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payments []*entity.Payment
+	for rows.Next() {
+		payment := entity.Payment{}
+		if err := scanPayment(rows, &payment); err != nil {
+			return nil, err
+		}
+		payments = append(payments, &payment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return payments, nil
+}
+
+// PayPayment settles an unpaid payment, a payment paid or closed meanwhile is ErrPaymentAlreadyPaid
 func (r *paymentRepository) PayPayment(tx *sql.Tx, paymentID int64, transactionID int64, paidAt time.Time) error {
 	query := `
 	UPDATE payments 
 	SET	transaction_id = ?,
 			status = ?,
 			paid_at = ?
-	WHERE id = ?;
+	WHERE id = ? AND status = ?;
 	`
-	_, err := tx.Exec(query, transactionID, entity.PaymentStatusPaid, paidAt, paymentID)
+	result, err := tx.Exec(r.dialect.Rebind(query), transactionID, entity.PaymentStatusPaid, paidAt, paymentID, entity.PaymentStatusActive)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
 	if err != nil {
 		return err
 	}
+	if affected != 1 {
+		return ErrPaymentAlreadyPaid
+	}
 
 	return nil
 }
+
+// UnpayPayments reopens every payment settled by the transaction
+func (r *paymentRepository) UnpayPayments(tx *sql.Tx, transactionID int64) error {
+	query := `
+	UPDATE payments
+	SET	transaction_id = NULL,
+			status = ?,
+			paid_at = NULL
+	WHERE transaction_id = ?
+	`
+	_, err := tx.Exec(r.dialect.Rebind(query), entity.PaymentStatusActive, transactionID)
+	return err
+}
+
+// CloseUnpaidPayments marks the unpaid payments of the loan as restructured, they are kept but no longer due
+func (r *paymentRepository) CloseUnpaidPayments(tx *sql.Tx, loanID int64, closedAt time.Time) error {
+	query := `UPDATE payments SET status = ?, closed_at = ? WHERE loan_id = ? AND status = ?`
+	_, err := tx.Exec(r.dialect.Rebind(query), entity.PaymentStatusRestructured, closedAt, loanID, entity.PaymentStatusActive)
+	return err
+}
+
+// RescheduleUnpaidPayments moves the unpaid payments due on the day of dueDate to newDueDate, keeping the date they
+// were first due on as original due date
+func (r *paymentRepository) RescheduleUnpaidPayments(tx *sql.Tx, dueDate entity.Date, newDueDate entity.Date) (int64, error) {
+	query := `UPDATE payments SET due_date = ?, original_due_date = COALESCE(original_due_date, due_date) WHERE status = ? AND due_date >= ? AND due_date < ?`
+	result, err := tx.Exec(r.dialect.Rebind(query), newDueDate, entity.PaymentStatusActive, dueDate, dueDate.AddDays(1))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// RestoreRescheduledPayments moves the unpaid payments rescheduled off the day of originalDueDate to dueDate, those
+// back on it are no longer rescheduled
+func (r *paymentRepository) RestoreRescheduledPayments(tx *sql.Tx, originalDueDate entity.Date, dueDate entity.Date) (int64, error) {
+	// a zero original due date is stored as NULL
+	original := originalDueDate
+	if dueDate.Equal(originalDueDate) {
+		original = entity.Date{}
+	}
+
+	query := `UPDATE payments SET due_date = ?, original_due_date = ? WHERE status = ? AND original_due_date >= ? AND original_due_date < ?`
+	result, err := tx.Exec(r.dialect.Rebind(query), dueDate, original, entity.PaymentStatusActive, originalDueDate, originalDueDate.AddDays(1))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// GetOrphanPayments returns payments of a missing loan, and paid payments without an existing transaction
+func (r *paymentRepository) GetOrphanPayments(ctx context.Context) ([]*entity.Payment, error) {
+	query := `
+	SELECT p.id, p.loan_id, p.transaction_id, p.due_date, p.payment_no, p.amount, p.interest, p.total_amount, p.status, p.paid_at, p.created_at
+	FROM payments p
+	LEFT JOIN loans l ON l.id = p.loan_id
+	LEFT JOIN transactions t ON t.id = p.transaction_id
+	WHERE l.id IS NULL OR (p.status = ? AND t.id IS NULL)
+	ORDER BY p.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, r.dialect.Rebind(query), entity.PaymentStatusPaid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payments []*entity.Payment
+	for rows.Next() {
+		payment := entity.Payment{}
+		if err := scanPayment(rows, &payment); err != nil {
+			return nil, err
+		}
+		payments = append(payments, &payment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return payments, nil
+}
+
+// GetUnpaidPaymentsDueBetween returns the unpaid payments of active loans due after `after` and up to `until`,
+// a zero `after` doesn't bound them
+func (r *paymentRepository) GetUnpaidPaymentsDueBetween(ctx context.Context, after entity.Date, until entity.Date) ([]*entity.Payment, error) {
+	query := `
+	SELECT p.id, p.loan_id, p.transaction_id, p.due_date, p.payment_no, p.amount, p.interest, p.total_amount, p.status, p.paid_at, p.created_at
+	FROM payments p
+	JOIN loans l ON l.id = p.loan_id
+	WHERE p.status = ? AND l.status = ? AND p.due_date <= ?
+	`
+	args := []interface{}{entity.PaymentStatusActive, entity.LoanStatusActive, until}
+
+	if !after.IsZero() {
+		query += ` AND p.due_date > ?`
+		args = append(args, after)
+	}
+
+	query += ` ORDER BY p.due_date, p.id`
+
+	rows, err := r.db.QueryContext(ctx, r.dialect.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payments []*entity.Payment
+	for rows.Next() {
+		payment := &entity.Payment{}
+		if err := scanPayment(rows, payment); err != nil {
+			return nil, err
+		}
+		payments = append(payments, payment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return payments, nil
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"loan-management/infrastructure"
+	"loan-management/internal/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaymentRepository(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *sql.DB, dialect infrastructure.Dialect) {
+		repo := NewPaymentRepository(db, dialect)
+		trxRepo := NewTransactionRepository(db, dialect)
+		ctx := context.Background()
+
+		loan := createTestLoan(t, db, dialect)
+		start := entity.DateOf(loan.BillingStartDate, time.UTC)
+
+		payments := make([]*entity.Payment, 3)
+		for i := range payments {
+			payments[i] = &entity.Payment{
+				LoanID:      loan.ID,
+				PaymentNo:   int32(i + 1),
+				DueDate:     start.AddDays((i + 1) * 7),
+				Amount:      100,
+				Interest:    10,
+				TotalAmount: 110,
+				Status:      entity.PaymentStatusActive,
+				CreatedAt:   time.Now(),
+			}
+		}
+
+		tx, err := trxRepo.BeginTx()
+		assert.NoError(t, err)
+		assert.NoError(t, repo.CreatePayment(tx, payments))
+		assert.NoError(t, tx.Commit())
+
+		activeStatus := entity.PaymentStatusActive
+		dueBefore := start.AddDays(14)
+		due, err := repo.GetPaymentsByLoanID(ctx, loan.ID, &activeStatus, &dueBefore)
+		assert.NoError(t, err)
+		assert.Len(t, due, 2)
+		assert.Equal(t, int32(1), due[0].PaymentNo)
+
+		tx, err = trxRepo.BeginTx()
+		assert.NoError(t, err)
+		paidAt := time.Now()
+		trxID, err := trxRepo.CreateTransaction(tx, &entity.Transaction{TotalAmount: 110, Status: entity.TransactionStatusPaid, PaidAt: &paidAt, CreatedAt: paidAt})
+		assert.NoError(t, err)
+		assert.NoError(t, repo.PayPayment(tx, due[0].ID, trxID, paidAt))
+		assert.NoError(t, tx.Commit())
+
+		paid, err := repo.GetPaymentByID(ctx, due[0].ID)
+		assert.NoError(t, err)
+		assert.Equal(t, entity.PaymentStatusPaid, paid.Status)
+		assert.Equal(t, trxID, *paid.TransactionID)
+		assert.NotNil(t, paid.PaidAt)
+
+		// a payment already paid can't be paid again
+		tx, err = trxRepo.BeginTx()
+		assert.NoError(t, err)
+		assert.ErrorIs(t, repo.PayPayment(tx, due[0].ID, trxID, paidAt), ErrPaymentAlreadyPaid)
+		assert.NoError(t, tx.Rollback())
+
+		all, err := repo.GetAllPayments(ctx, &activeStatus)
+		assert.NoError(t, err)
+		assert.Len(t, all, 2)
+
+		balances, err := NewLoanRepository(db, dialect).GetLoanBalances(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []*entity.LoanBalance{{
+			LoanID:          loan.ID,
+			Outstanding:     loan.Outstanding,
+			Status:          entity.LoanStatusActive,
+			UnpaidTotal:     220,
+			UnpaidPrincipal: 200,
+			PaymentCount:    3,
+		}}, balances)
+
+		upcoming, err := repo.GetUnpaidPaymentsDueBetween(ctx, start.AddDays(7), start.AddDays(14))
+		assert.NoError(t, err)
+		assert.Len(t, upcoming, 1)
+		assert.Equal(t, int32(2), upcoming[0].PaymentNo)
+
+		unpaid, err := repo.GetUnpaidPaymentsDueBetween(ctx, entity.Date{}, start.AddDays(21))
+		assert.NoError(t, err)
+		assert.Len(t, unpaid, 2)
+		assert.Equal(t, int32(3), unpaid[1].PaymentNo)
+
+		// the third installment moves to the next day, the paid first one stays
+		tx, err = trxRepo.BeginTx()
+		assert.NoError(t, err)
+		rescheduled, err := repo.RescheduleUnpaidPayments(tx, start.AddDays(21), start.AddDays(22))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), rescheduled)
+		rescheduled, err = repo.RescheduleUnpaidPayments(tx, start.AddDays(7), start.AddDays(8))
+		assert.NoError(t, err)
+		assert.Zero(t, rescheduled)
+		assert.NoError(t, tx.Commit())
+
+		moved, err := repo.GetPaymentByID(ctx, unpaid[1].ID)
+		assert.NoError(t, err)
+		assert.Equal(t, start.AddDays(22), moved.DueDate)
+
+		// moved again, then back to where it was first due
+		tx, err = trxRepo.BeginTx()
+		assert.NoError(t, err)
+		rescheduled, err = repo.RescheduleUnpaidPayments(tx, start.AddDays(22), start.AddDays(23))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), rescheduled)
+		restored, err := repo.RestoreRescheduledPayments(tx, start.AddDays(22), start.AddDays(22))
+		assert.NoError(t, err)
+		assert.Zero(t, restored)
+		restored, err = repo.RestoreRescheduledPayments(tx, start.AddDays(21), start.AddDays(21))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), restored)
+		assert.NoError(t, tx.Commit())
+
+		moved, err = repo.GetPaymentByID(ctx, unpaid[1].ID)
+		assert.NoError(t, err)
+		assert.Equal(t, start.AddDays(21), moved.DueDate)
+
+		orphans, err := repo.GetOrphanPayments(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, orphans)
+
+		// paid without a transaction
+		orphan := *payments[2]
+		orphan.PaymentNo = 4
+		orphan.Status = entity.PaymentStatusPaid
+		tx, err = trxRepo.BeginTx()
+		assert.NoError(t, err)
+		assert.NoError(t, repo.CreatePayment(tx, []*entity.Payment{&orphan}))
+		assert.NoError(t, tx.Commit())
+
+		orphans, err = repo.GetOrphanPayments(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, orphans, 1)
+		assert.Equal(t, int32(4), orphans[0].PaymentNo)
+
+		tx, err = trxRepo.BeginTx()
+		assert.NoError(t, err)
+		assert.NoError(t, repo.CloseUnpaidPayments(tx, loan.ID, time.Now()))
+		assert.NoError(t, tx.Commit())
+
+		all, err = repo.GetAllPayments(ctx, &activeStatus)
+		assert.NoError(t, err)
+		assert.Empty(t, all)
+
+		restructuredStatus := entity.PaymentStatusRestructured
+		closed, err := repo.GetPaymentsByLoanID(ctx, loan.ID, &restructuredStatus, nil)
+		assert.NoError(t, err)
+		assert.Len(t, closed, 2)
+
+		_, err = repo.GetPaymentByID(ctx, 69)
+		assert.ErrorIs(t, err, ErrPaymentNotFound)
+	})
+}
@@ -0,0 +1,250 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"loan-management/infrastructure"
+	"loan-management/internal/entity"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ReportRepository aggregates loans and payments for the portfolio reports. Payments paid or closed by a restructure
+// after asOf count as unpaid and the schedule of a later restructure is left out, so a report can be run for a past date
+type ReportRepository interface {
+	GetDisbursed(ctx context.Context, asOf time.Time) (loanCount int, amount float64, err error)
+	GetOutstandingByDaysPastDue(ctx context.Context, asOf time.Time, day entity.Date, minDays []int) ([]*entity.OutstandingTotals, error)
+	GetCollectionsByMonth(ctx context.Context, from time.Time, to time.Time) ([]*entity.CollectionPeriod, error)
+	GetVintageCohorts(ctx context.Context, from time.Time, to time.Time, asOf time.Time) ([]*entity.VintageCohort, error)
+}
+
+type reportRepository struct {
+	db      *sql.DB
+	dialect infrastructure.Dialect
+}
+
+func NewReportRepository(db *sql.DB, dialect infrastructure.Dialect) ReportRepository {
+	return &reportRepository{db: db, dialect: dialect}
+}
+
+func (r *reportRepository) GetDisbursed(ctx context.Context, asOf time.Time) (int, float64, error) {
+	query := `SELECT COUNT(*), COALESCE(SUM(amount), 0) FROM loans WHERE disbursed_at <= ?`
+
+	var (
+		loanCount int
+		amount    float64
+	)
+	err := r.db.QueryRowContext(ctx, r.dialect.Rebind(query), asOf).Scan(&loanCount, &amount)
+	return loanCount, amount, err
+}
+
+// GetOutstandingByDaysPastDue groups the loans with unpaid installments at asOf by the days their oldest unpaid
+// installment is past due on day, the business day of asOf. minDays are the ascending lower bounds of the groups,
+// starting at 0 for loans that are current, and the result has one totals per bound
+func (r *reportRepository) GetOutstandingByDaysPastDue(ctx context.Context, asOf time.Time, day entity.Date, minDays []int) ([]*entity.OutstandingTotals, error) {
+	// an installment is a day past due the day after its due date
+	var (
+		cases strings.Builder
+		args  []any
+	)
+	for i := len(minDays) - 1; i > 0; i-- {
+		fmt.Fprintf(&cases, "WHEN a.oldest_due < ? THEN %d ", i)
+		args = append(args, day.AddDays(1-minDays[i]))
+	}
+
+	query := `
+	SELECT
+		CASE ` + cases.String() + `ELSE 0 END AS days_past_due,
+		COUNT(*),
+		SUM(a.principal),
+		SUM(a.interest)
+	FROM (
+		SELECT
+			p.loan_id,
+			SUM(p.amount) AS principal,
+			SUM(p.interest) AS interest,
+			MIN(CASE WHEN p.due_date < ? THEN p.due_date END) AS oldest_due
+		FROM payments p
+		JOIN loans l ON l.id = p.loan_id
+		WHERE l.disbursed_at <= ? AND p.created_at <= ?
+			AND (p.paid_at IS NULL OR p.paid_at > ?)
+			AND (p.closed_at IS NULL OR p.closed_at > ?)
+		GROUP BY p.loan_id
+	) a
+	GROUP BY 1
+	`
+	args = append(args, day, asOf, asOf, asOf, asOf)
+
+	rows, err := r.db.QueryContext(ctx, r.dialect.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	totals := make([]*entity.OutstandingTotals, len(minDays))
+	for i := range totals {
+		totals[i] = &entity.OutstandingTotals{}
+	}
+
+	for rows.Next() {
+		var (
+			group  int
+			bucket entity.OutstandingTotals
+		)
+		if err := rows.Scan(&group, &bucket.LoanCount, &bucket.Principal, &bucket.Interest); err != nil {
+			return nil, err
+		}
+		totals[group] = &bucket
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return totals, nil
+}
+
+// GetCollectionsByMonth returns the months between from and to that had installments due or paid, in order.
+// Installments closed by a restructure before their due date never fell due
+func (r *reportRepository) GetCollectionsByMonth(ctx context.Context, from time.Time, to time.Time) ([]*entity.CollectionPeriod, error) {
+	dueMonth := r.dialect.Month("due_date")
+	paidMonth := r.dialect.Month("paid_at")
+
+	dueQuery := `
+	SELECT
+		` + dueMonth + ` AS period,
+		SUM(total_amount),
+		SUM(CASE WHEN paid_at IS NOT NULL AND ` + paidMonth + ` <= ` + dueMonth + ` THEN total_amount ELSE 0 END)
+	FROM payments
+	WHERE due_date >= ? AND due_date <= ? AND (closed_at IS NULL OR closed_at > due_date)
+	GROUP BY 1
+	`
+
+	byPeriod := map[string]*entity.CollectionPeriod{}
+	period := func(name string) *entity.CollectionPeriod {
+		if _, ok := byPeriod[name]; !ok {
+			byPeriod[name] = &entity.CollectionPeriod{Period: name}
+		}
+		return byPeriod[name]
+	}
+
+	err := r.queryRows(ctx, dueQuery, []any{from, to}, func(rows *sql.Rows) error {
+		var (
+			name           string
+			due, collected float64
+		)
+		if err := rows.Scan(&name, &due, &collected); err != nil {
+			return err
+		}
+		period(name).Due = due
+		period(name).Collected = collected
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	receivedQuery := `
+	SELECT ` + paidMonth + ` AS period, SUM(total_amount)
+	FROM payments
+	WHERE paid_at >= ? AND paid_at <= ?
+	GROUP BY 1
+	`
+
+	err = r.queryRows(ctx, receivedQuery, []any{from, to}, func(rows *sql.Rows) error {
+		var (
+			name     string
+			received float64
+		)
+		if err := rows.Scan(&name, &received); err != nil {
+			return err
+		}
+		period(name).Received = received
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	periods := make([]*entity.CollectionPeriod, 0, len(byPeriod))
+	for _, p := range byPeriod {
+		periods = append(periods, p)
+	}
+	sort.Slice(periods, func(i, j int) bool { return periods[i].Period < periods[j].Period })
+
+	return periods, nil
+}
+
+// GetVintageCohorts groups the loans disbursed between from and to by month, with the principal repaid by asOf
+func (r *reportRepository) GetVintageCohorts(ctx context.Context, from time.Time, to time.Time, asOf time.Time) ([]*entity.VintageCohort, error) {
+	cohortMonth := r.dialect.Month("l.disbursed_at")
+
+	disbursedQuery := `
+	SELECT ` + cohortMonth + ` AS cohort, COUNT(*), SUM(l.amount)
+	FROM loans l
+	WHERE l.disbursed_at >= ? AND l.disbursed_at <= ?
+	GROUP BY 1
+	ORDER BY 1
+	`
+
+	var cohorts []*entity.VintageCohort
+	byCohort := map[string]*entity.VintageCohort{}
+
+	err := r.queryRows(ctx, disbursedQuery, []any{from, to}, func(rows *sql.Rows) error {
+		cohort := &entity.VintageCohort{Repaid: map[string]float64{}}
+		if err := rows.Scan(&cohort.Cohort, &cohort.LoanCount, &cohort.Disbursed); err != nil {
+			return err
+		}
+		cohorts = append(cohorts, cohort)
+		byCohort[cohort.Cohort] = cohort
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	repaidQuery := `
+	SELECT ` + cohortMonth + ` AS cohort, ` + r.dialect.Month("p.paid_at") + ` AS paid, SUM(p.amount)
+	FROM payments p
+	JOIN loans l ON l.id = p.loan_id
+	WHERE l.disbursed_at >= ? AND l.disbursed_at <= ? AND p.paid_at IS NOT NULL AND p.paid_at <= ?
+	GROUP BY 1, 2
+	`
+
+	err = r.queryRows(ctx, repaidQuery, []any{from, to, asOf}, func(rows *sql.Rows) error {
+		var (
+			cohort, paid string
+			repaid       float64
+		)
+		if err := rows.Scan(&cohort, &paid, &repaid); err != nil {
+			return err
+		}
+		if c, ok := byCohort[cohort]; ok {
+			c.Repaid[paid] = repaid
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cohorts, nil
+}
+
+func (r *reportRepository) queryRows(ctx context.Context, query string, args []any, scan func(rows *sql.Rows) error) error {
+	rows, err := r.db.QueryContext(ctx, r.dialect.Rebind(query), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
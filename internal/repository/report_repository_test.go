@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"loan-management/infrastructure"
+	"loan-management/internal/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportRepository(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *sql.DB, dialect infrastructure.Dialect) {
+		repo := NewReportRepository(db, dialect)
+		paymentRepo := NewPaymentRepository(db, dialect)
+		trxRepo := NewTransactionRepository(db, dialect)
+		ctx := context.Background()
+
+		asOf := time.Now().UTC()
+		day := asOf.Truncate(24 * time.Hour)
+
+		loan := createTestLoan(t, db, dialect)
+		_, err := db.Exec(dialect.Rebind(`UPDATE loans SET created_at = ?, disbursed_at = ? WHERE id = ?`), day.AddDate(0, 0, -200), day.AddDate(0, 0, -200), loan.ID)
+		assert.NoError(t, err)
+
+		dueDays := []int{-100, -45, -20, 7}
+		payments := make([]*entity.Payment, len(dueDays))
+		for i, dueDay := range dueDays {
+			payments[i] = &entity.Payment{
+				LoanID:      loan.ID,
+				PaymentNo:   int32(i + 1),
+				DueDate:     entity.DateOf(day, time.UTC).AddDays(dueDay),
+				Amount:      100,
+				Interest:    10,
+				TotalAmount: 110,
+				Status:      entity.PaymentStatusActive,
+				CreatedAt:   day.AddDate(0, 0, -200),
+			}
+		}
+
+		tx, err := trxRepo.BeginTx()
+		assert.NoError(t, err)
+		assert.NoError(t, paymentRepo.CreatePayment(tx, payments))
+		assert.NoError(t, tx.Commit())
+
+		created, err := paymentRepo.GetPaymentsByLoanID(ctx, loan.ID, nil, nil)
+		assert.NoError(t, err)
+
+		// the first installment is paid late, the third on its due date
+		paidAt := map[int]time.Time{0: day.AddDate(0, 0, -5).Add(time.Hour), 2: day.AddDate(0, 0, -20).Add(time.Hour)}
+		for i, at := range paidAt {
+			tx, err := trxRepo.BeginTx()
+			assert.NoError(t, err)
+			trxID, err := trxRepo.CreateTransaction(tx, &entity.Transaction{TotalAmount: 110, Status: entity.TransactionStatusPaid, PaidAt: &at, CreatedAt: at})
+			assert.NoError(t, err)
+			assert.NoError(t, paymentRepo.PayPayment(tx, created[i].ID, trxID, at))
+			assert.NoError(t, tx.Commit())
+		}
+
+		t.Run("disbursed", func(t *testing.T) {
+			count, amount, err := repo.GetDisbursed(ctx, asOf)
+			assert.NoError(t, err)
+			assert.Equal(t, 1, count)
+			assert.Equal(t, loan.Amount, amount)
+
+			count, amount, err = repo.GetDisbursed(ctx, day.AddDate(0, 0, -300))
+			assert.NoError(t, err)
+			assert.Equal(t, 0, count)
+			assert.Equal(t, 0.0, amount)
+		})
+
+		t.Run("outstanding by days past due", func(t *testing.T) {
+			minDays := []int{0, 1, 31, 61, 91, 181}
+
+			totals, err := repo.GetOutstandingByDaysPastDue(ctx, asOf, entity.DateOf(asOf, time.UTC), minDays)
+			assert.NoError(t, err)
+			assert.Len(t, totals, len(minDays))
+			assert.Equal(t, &entity.OutstandingTotals{LoanCount: 1, Principal: 200, Interest: 20}, totals[2])
+			assert.Equal(t, &entity.OutstandingTotals{}, totals[0])
+
+			// ten days ago the first installment was still unpaid and exactly 90 days late
+			tenDaysAgo := day.AddDate(0, 0, -10).Add(12 * time.Hour)
+			totals, err = repo.GetOutstandingByDaysPastDue(ctx, tenDaysAgo, entity.DateOf(tenDaysAgo, time.UTC), minDays)
+			assert.NoError(t, err)
+			assert.Equal(t, &entity.OutstandingTotals{LoanCount: 1, Principal: 300, Interest: 30}, totals[3])
+			assert.Equal(t, &entity.OutstandingTotals{}, totals[4])
+		})
+
+		t.Run("collections by month", func(t *testing.T) {
+			expected := map[string]*entity.CollectionPeriod{}
+			period := func(date time.Time) *entity.CollectionPeriod {
+				name := date.Format("2006-01")
+				if _, ok := expected[name]; !ok {
+					expected[name] = &entity.CollectionPeriod{Period: name}
+				}
+				return expected[name]
+			}
+			for _, payment := range payments {
+				period(payment.DueDate.Time()).Due += 110
+			}
+			period(payments[2].DueDate.Time()).Collected += 110
+			for _, at := range paidAt {
+				period(at).Received += 110
+			}
+
+			periods, err := repo.GetCollectionsByMonth(ctx, day.AddDate(0, 0, -120), day.AddDate(0, 0, 30))
+			assert.NoError(t, err)
+			assert.Len(t, periods, len(expected))
+			for i, p := range periods {
+				assert.Equal(t, expected[p.Period], p)
+				if i > 0 {
+					assert.Less(t, periods[i-1].Period, p.Period)
+				}
+			}
+		})
+
+		t.Run("vintage cohorts", func(t *testing.T) {
+			repaid := map[string]float64{}
+			for _, at := range paidAt {
+				repaid[at.Format("2006-01")] += 100
+			}
+
+			cohorts, err := repo.GetVintageCohorts(ctx, day.AddDate(0, 0, -300), asOf, asOf)
+			assert.NoError(t, err)
+			assert.Equal(t, []*entity.VintageCohort{{
+				Cohort:    day.AddDate(0, 0, -200).Format("2006-01"),
+				LoanCount: 1,
+				Disbursed: loan.Amount,
+				Repaid:    repaid,
+			}}, cohorts)
+
+			cohorts, err = repo.GetVintageCohorts(ctx, day.AddDate(0, 0, -300), asOf, day.AddDate(0, 0, -10))
+			assert.NoError(t, err)
+			assert.Equal(t, map[string]float64{paidAt[2].Format("2006-01"): 100}, cohorts[0].Repaid)
+		})
+	})
+}
@@ -3,21 +3,34 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"loan-management/infrastructure"
+	"loan-management/internal/apperror"
 	"loan-management/internal/entity"
 )
 
+var (
+	ErrTransactionNotFound      = apperror.NotFound("TRANSACTION_NOT_FOUND", "transaction not found")
+	ErrTransactionStatusChanged = apperror.Conflict("TRANSACTION_STATUS_CHANGED", "The transaction status changed meanwhile")
+)
+
 type transactionRepository struct {
-	db *sql.DB
+	db      *sql.DB
+	dialect infrastructure.Dialect
 }
 
 type TransactionRepository interface {
 	CreateTransaction(tx *sql.Tx, transaction *entity.Transaction) (int64, error)
 	GetTransactionByID(ctx context.Context, id int64) (*entity.Transaction, error)
+	GetTransactionByExternalID(ctx context.Context, channel string, externalID string) (*entity.Transaction, error)
+	UpdateTransactionStatus(tx *sql.Tx, id int64, from entity.TransactionStatus, to entity.TransactionStatus) error
+	CreateAllocation(tx *sql.Tx, allocation *entity.TransactionAllocation) error
+	GetAllocationsByTransactionID(ctx context.Context, transactionID int64) ([]*entity.TransactionAllocation, error)
 	BeginTx() (*sql.Tx, error)
 }
 
-func NewTransactionRepository(db *sql.DB) TransactionRepository {
-	return &transactionRepository{db: db}
+func NewTransactionRepository(db *sql.DB, dialect infrastructure.Dialect) TransactionRepository {
+	return &transactionRepository{db: db, dialect: dialect}
 }
 
 func (r *transactionRepository) CreateTransaction(tx *sql.Tx, transaction *entity.Transaction) (int64, error) {
@@ -25,50 +38,126 @@ func (r *transactionRepository) CreateTransaction(tx *sql.Tx, transaction *entit
 	INSERT INTO transactions (
 		total_amount,
 		penalty,
+		rounding,
 		status,
 		paid_at,
-		created_at
-	) VALUES (?, ?, ?, ?, ?)
+		created_at,
+		channel,
+		external_id
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	result, err := tx.Exec(
+	return r.dialect.InsertReturningID(
+		context.Background(),
+		tx,
 		query,
 		transaction.TotalAmount,
 		transaction.Penalty,
+		transaction.Rounding,
 		transaction.Status,
 		transaction.PaidAt,
 		transaction.CreatedAt,
+		nullString(transaction.Channel),
+		nullString(transaction.ExternalID),
 	)
-	if err != nil {
-		return 0, err
-	}
-
-	return result.LastInsertId()
 }
 
-func (r *transactionRepository) GetTransactionByID(ctx context.Context, id int64) (*entity.Transaction, error) {
-	query := `
-	SELECT id, total_amount, penalty, status, paid_at, created_at
-	FROM transactions
-	WHERE id = ?
-	`
-	row := r.db.QueryRowContext(ctx, query, id)
+const transactionColumns = `id, total_amount, penalty, rounding, status, paid_at, created_at, channel, external_id`
+
+func (r *transactionRepository) getTransaction(ctx context.Context, where string, args ...any) (*entity.Transaction, error) {
+	query := `SELECT ` + transactionColumns + ` FROM transactions WHERE ` + where
+	row := r.db.QueryRowContext(ctx, r.dialect.Rebind(query), args...)
 
 	transaction := &entity.Transaction{}
-	var paidAt sql.NullTime
+	var (
+		paidAt              sql.NullTime
+		channel, externalID sql.NullString
+	)
 
-	err := row.Scan(&transaction.ID, &transaction.TotalAmount, &transaction.Penalty, &transaction.Status, &paidAt, &transaction.CreatedAt)
+	err := row.Scan(&transaction.ID, &transaction.TotalAmount, &transaction.Penalty, &transaction.Rounding, &transaction.Status, &paidAt, &transaction.CreatedAt, &channel, &externalID)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTransactionNotFound
+		}
 		return nil, err
 	}
 
 	if paidAt.Valid {
 		transaction.PaidAt = &paidAt.Time
 	}
+	transaction.Channel = channel.String
+	transaction.ExternalID = externalID.String
 
 	return transaction, nil
 }
 
+func (r *transactionRepository) GetTransactionByID(ctx context.Context, id int64) (*entity.Transaction, error) {
+	return r.getTransaction(ctx, `id = ?`, id)
+}
+
+func (r *transactionRepository) GetTransactionByExternalID(ctx context.Context, channel string, externalID string) (*entity.Transaction, error) {
+	return r.getTransaction(ctx, `channel = ? AND external_id = ?`, channel, externalID)
+}
+
+// UpdateTransactionStatus moves a transaction from one status to another, ErrTransactionStatusChanged when it's no
+// longer in from, so two concurrent updates can't both apply
+func (r *transactionRepository) UpdateTransactionStatus(tx *sql.Tx, id int64, from entity.TransactionStatus, to entity.TransactionStatus) error {
+	query := `UPDATE transactions SET status = ? WHERE id = ? AND status = ?`
+
+	result, err := tx.Exec(r.dialect.Rebind(query), to, id, from)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrTransactionStatusChanged
+	}
+	return nil
+}
+
+func (r *transactionRepository) CreateAllocation(tx *sql.Tx, allocation *entity.TransactionAllocation) error {
+	query := `INSERT INTO transaction_allocations (transaction_id, loan_id, amount, created_at) VALUES (?, ?, ?, ?)`
+
+	id, err := r.dialect.InsertReturningID(context.Background(), tx, query, allocation.TransactionID, allocation.LoanID, allocation.Amount, allocation.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	allocation.ID = id
+	return nil
+}
+
+// GetAllocationsByTransactionID returns the allocations of a transaction by loan
+func (r *transactionRepository) GetAllocationsByTransactionID(ctx context.Context, transactionID int64) ([]*entity.TransactionAllocation, error) {
+	query := `SELECT id, transaction_id, loan_id, amount, created_at FROM transaction_allocations WHERE transaction_id = ? ORDER BY loan_id`
+
+	rows, err := r.db.QueryContext(ctx, r.dialect.Rebind(query), transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var allocations []*entity.TransactionAllocation
+	for rows.Next() {
+		allocation := &entity.TransactionAllocation{}
+		if err := rows.Scan(&allocation.ID, &allocation.TransactionID, &allocation.LoanID, &allocation.Amount, &allocation.CreatedAt); err != nil {
+			return nil, err
+		}
+		allocations = append(allocations, allocation)
+	}
+
+	return allocations, rows.Err()
+}
+
 func (r *transactionRepository) BeginTx() (*sql.Tx, error) {
 	return r.db.Begin()
 }
+
+// nullString stores empty strings as NULL, so optional columns under a unique index don't collide
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"loan-management/infrastructure"
+	"loan-management/internal/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactionRepository(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *sql.DB, dialect infrastructure.Dialect) {
+		repo := NewTransactionRepository(db, dialect)
+
+		tx, err := repo.BeginTx()
+		assert.NoError(t, err)
+
+		paidAt := time.Now()
+		id, err := repo.CreateTransaction(tx, &entity.Transaction{TotalAmount: 110, Status: entity.TransactionStatusPaid, PaidAt: &paidAt, CreatedAt: paidAt})
+		assert.NoError(t, err)
+		assert.NotZero(t, id)
+		assert.NoError(t, tx.Commit())
+
+		trx, err := repo.GetTransactionByID(context.Background(), id)
+		assert.NoError(t, err)
+		assert.Equal(t, float64(110), trx.TotalAmount)
+		assert.NotNil(t, trx.PaidAt)
+		assert.Empty(t, trx.Channel)
+
+		_, err = repo.GetTransactionByExternalID(context.Background(), "fake", "pay_1")
+		assert.ErrorIs(t, err, ErrTransactionNotFound)
+
+		// a provider payment can only be posted once
+		tx, err = repo.BeginTx()
+		assert.NoError(t, err)
+		gatewayID, err := repo.CreateTransaction(tx, &entity.Transaction{TotalAmount: 110, Status: entity.TransactionStatusPaid, PaidAt: &paidAt, CreatedAt: paidAt, Channel: "fake", ExternalID: "pay_1"})
+		assert.NoError(t, err)
+		assert.NoError(t, tx.Commit())
+
+		tx, err = repo.BeginTx()
+		assert.NoError(t, err)
+		_, err = repo.CreateTransaction(tx, &entity.Transaction{TotalAmount: 110, Status: entity.TransactionStatusPaid, PaidAt: &paidAt, CreatedAt: paidAt, Channel: "fake", ExternalID: "pay_1"})
+		assert.Error(t, err)
+		assert.NoError(t, tx.Rollback())
+
+		trx, err = repo.GetTransactionByExternalID(context.Background(), "fake", "pay_1")
+		assert.NoError(t, err)
+		assert.Equal(t, gatewayID, trx.ID)
+		assert.Equal(t, "pay_1", trx.ExternalID)
+
+		// only the first of two reversals finds the transaction paid
+		tx, err = repo.BeginTx()
+		assert.NoError(t, err)
+		assert.NoError(t, repo.UpdateTransactionStatus(tx, id, entity.TransactionStatusPaid, entity.TransactionStatusReversed))
+		assert.ErrorIs(t, repo.UpdateTransactionStatus(tx, id, entity.TransactionStatusPaid, entity.TransactionStatusReversed), ErrTransactionStatusChanged)
+		assert.NoError(t, tx.Commit())
+	})
+}
+
+func TestTransactionAllocations(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *sql.DB, dialect infrastructure.Dialect) {
+		repo := NewTransactionRepository(db, dialect)
+		loan := createTestLoan(t, db, dialect)
+
+		tx, err := repo.BeginTx()
+		assert.NoError(t, err)
+
+		paidAt := time.Now()
+		id, err := repo.CreateTransaction(tx, &entity.Transaction{TotalAmount: 220, Status: entity.TransactionStatusPaid, PaidAt: &paidAt, CreatedAt: paidAt})
+		assert.NoError(t, err)
+
+		allocation := &entity.TransactionAllocation{TransactionID: id, LoanID: loan.ID, Amount: 220, CreatedAt: paidAt}
+		assert.NoError(t, repo.CreateAllocation(tx, allocation))
+		assert.NotZero(t, allocation.ID)
+		assert.NoError(t, tx.Commit())
+
+		allocations, err := repo.GetAllocationsByTransactionID(context.Background(), id)
+		assert.NoError(t, err)
+		if assert.Len(t, allocations, 1) {
+			assert.Equal(t, loan.ID, allocations[0].LoanID)
+			assert.Equal(t, float64(220), allocations[0].Amount)
+		}
+
+		allocations, err = repo.GetAllocationsByTransactionID(context.Background(), id+1)
+		assert.NoError(t, err)
+		assert.Empty(t, allocations)
+	})
+}
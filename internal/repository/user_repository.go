@@ -4,37 +4,64 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"loan-management/infrastructure"
+	"loan-management/internal/apperror"
 	"loan-management/internal/entity"
 )
 
 var (
-	ErrUserNotFound = errors.New("user not found")
+	ErrUserNotFound = apperror.NotFound("USER_NOT_FOUND", "user not found")
 )
 
 type UserRepository interface {
-	CreateUser(ctx context.Context, user *entity.User) error
+	CreateUser(tx *sql.Tx, user *entity.User) error
 	GetAllUsers(ctx context.Context) ([]*entity.User, error)
 	GetUserByID(ctx context.Context, id int64) (*entity.User, error)
 	GetUserByEmail(ctx context.Context, email string) (*entity.User, error)
+	UpdateUserRole(tx *sql.Tx, id int64, role entity.Role) error
+	BeginTx() (*sql.Tx, error)
 }
 
 type userRepository struct {
-	db *sql.DB
+	db      *sql.DB
+	dialect infrastructure.Dialect
 }
 
-func NewUserRepository(db *sql.DB) UserRepository {
-	return &userRepository{db: db}
+func NewUserRepository(db *sql.DB, dialect infrastructure.Dialect) UserRepository {
+	return &userRepository{db: db, dialect: dialect}
 }
 
-func (r *userRepository) CreateUser(ctx context.Context, user *entity.User) error {
-	query := `INSERT INTO users (email, name, created_at) VALUES (?, ?, ?)`
+const userColumns = `id, email, name, password_hash, role, created_at`
 
-	result, err := r.db.ExecContext(ctx, query, user.Email, user.Name, user.CreatedAt)
-	if err != nil {
-		return err
+func scanUser(scanner interface{ Scan(dest ...any) error }, user *entity.User) error {
+	var passwordHash sql.NullString
+
+	err := scanner.Scan(
+		&user.ID,
+		&user.Email,
+		&user.Name,
+		&passwordHash,
+		&user.Role,
+		&user.CreatedAt,
+	)
+	user.PasswordHash = passwordHash.String
+
+	return err
+}
+
+func (r *userRepository) CreateUser(tx *sql.Tx, user *entity.User) error {
+	query := `INSERT INTO users (email, name, password_hash, role, created_at) VALUES (?, ?, ?, ?, ?)`
+
+	var passwordHash *string
+	if user.PasswordHash != "" {
+		passwordHash = &user.PasswordHash
 	}
 
-	id, err := result.LastInsertId()
+	if user.Role == 0 {
+		user.Role = entity.RoleBorrower
+	}
+
+	id, err := r.dialect.InsertReturningID(context.Background(), tx, query, user.Email, user.Name, passwordHash, user.Role, user.CreatedAt)
 	if err != nil {
 		return err
 	}
@@ -44,7 +71,7 @@ func (r *userRepository) CreateUser(ctx context.Context, user *entity.User) erro
 }
 
 func (r *userRepository) GetAllUsers(ctx context.Context) ([]*entity.User, error) {
-	query := `SELECT * FROM users`
+	query := `SELECT ` + userColumns + ` FROM users`
 	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
@@ -55,8 +82,7 @@ func (r *userRepository) GetAllUsers(ctx context.Context) ([]*entity.User, error
 
 	for rows.Next() {
 		user := &entity.User{}
-		err := rows.Scan(&user.ID, &user.Email, &user.Name, &user.CreatedAt)
-		if err != nil {
+		if err := scanUser(rows, user); err != nil {
 			return nil, err
 		}
 		users = append(users, user)
@@ -70,15 +96,10 @@ func (r *userRepository) GetAllUsers(ctx context.Context) ([]*entity.User, error
 }
 
 func (r *userRepository) GetUserByID(ctx context.Context, id int64) (*entity.User, error) {
-	query := `SELECT * FROM users WHERE id = ?`
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = ?`
 
 	var user entity.User
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&user.ID,
-		&user.Email,
-		&user.Name,
-		&user.CreatedAt,
-	)
+	err := scanUser(r.db.QueryRowContext(ctx, r.dialect.Rebind(query), id), &user)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -91,15 +112,10 @@ func (r *userRepository) GetUserByID(ctx context.Context, id int64) (*entity.Use
 }
 
 func (r *userRepository) GetUserByEmail(ctx context.Context, email string) (*entity.User, error) {
-	query := `SELECT * FROM users WHERE email = ?`
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = ?`
 
 	var user entity.User
-	err := r.db.QueryRowContext(ctx, query, email).Scan(
-		&user.ID,
-		&user.Email,
-		&user.Name,
-		&user.CreatedAt,
-	)
+	err := scanUser(r.db.QueryRowContext(ctx, r.dialect.Rebind(query), email), &user)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -110,3 +126,26 @@ func (r *userRepository) GetUserByEmail(ctx context.Context, email string) (*ent
 
 	return &user, nil
 }
+
+func (r *userRepository) UpdateUserRole(tx *sql.Tx, id int64, role entity.Role) error {
+	query := `UPDATE users SET role = ? WHERE id = ?`
+
+	result, err := tx.Exec(r.dialect.Rebind(query), role, id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *userRepository) BeginTx() (*sql.Tx, error) {
+	return r.db.Begin()
+}
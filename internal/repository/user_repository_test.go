@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"loan-management/infrastructure"
+	"loan-management/internal/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserRepository(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *sql.DB, dialect infrastructure.Dialect) {
+		repo := NewUserRepository(db, dialect)
+		ctx := context.Background()
+
+		tx, err := repo.BeginTx()
+		assert.NoError(t, err)
+
+		user := &entity.User{Email: "test@test", Name: "test", CreatedAt: time.Now()}
+		err = repo.CreateUser(tx, user)
+		assert.NoError(t, err)
+		assert.NotZero(t, user.ID)
+		assert.NoError(t, tx.Commit())
+
+		found, err := repo.GetUserByID(ctx, user.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, user.Email, found.Email)
+		assert.Equal(t, entity.RoleBorrower, found.Role)
+
+		found, err = repo.GetUserByEmail(ctx, "test@test")
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID, found.ID)
+
+		users, err := repo.GetAllUsers(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, users, 1)
+
+		_, err = repo.GetUserByID(ctx, 69)
+		assert.ErrorIs(t, err, ErrUserNotFound)
+
+		tx, err = repo.BeginTx()
+		assert.NoError(t, err)
+		err = repo.UpdateUserRole(tx, user.ID, entity.RoleCollector)
+		assert.NoError(t, err)
+		err = repo.UpdateUserRole(tx, 69, entity.RoleCollector)
+		assert.ErrorIs(t, err, ErrUserNotFound)
+		assert.NoError(t, tx.Commit())
+
+		found, err = repo.GetUserByID(ctx, user.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, entity.RoleCollector, found.Role)
+	})
+}
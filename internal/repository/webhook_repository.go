@@ -0,0 +1,322 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"loan-management/infrastructure"
+	"loan-management/internal/apperror"
+	"loan-management/internal/entity"
+	"time"
+)
+
+var (
+	ErrWebhookSubscriptionNotFound = apperror.NotFound("WEBHOOK_SUBSCRIPTION_NOT_FOUND", "webhook subscription not found")
+	ErrWebhookDeliveryNotFound     = apperror.NotFound("WEBHOOK_DELIVERY_NOT_FOUND", "webhook delivery not found")
+)
+
+const defaultWebhookDeliveryLimit = 100
+
+type WebhookRepository interface {
+	CreateSubscription(tx *sql.Tx, subscription *entity.WebhookSubscription) error
+	UpdateSubscription(tx *sql.Tx, subscription *entity.WebhookSubscription) error
+	GetSubscriptionByID(ctx context.Context, id int64) (*entity.WebhookSubscription, error)
+	GetAllSubscriptions(ctx context.Context, status *entity.WebhookStatus) ([]*entity.WebhookSubscription, error)
+	CreateDeliveries(ctx context.Context, deliveries []*entity.WebhookDelivery) error
+	GetDeliveryByID(ctx context.Context, id int64) (*entity.WebhookDelivery, error)
+	GetDeliveriesBySubscriptionID(ctx context.Context, subscriptionID int64, filter entity.WebhookDeliveryFilter) ([]*entity.WebhookDelivery, error)
+	GetPendingDeliveries(ctx context.Context, dueBefore time.Time, limit int) ([]*entity.WebhookDelivery, error)
+	UpdateDeliveryAttempt(ctx context.Context, delivery *entity.WebhookDelivery) error
+	BeginTx() (*sql.Tx, error)
+}
+
+type webhookRepository struct {
+	db      *sql.DB
+	dialect infrastructure.Dialect
+}
+
+func NewWebhookRepository(db *sql.DB, dialect infrastructure.Dialect) WebhookRepository {
+	return &webhookRepository{db: db, dialect: dialect}
+}
+
+const (
+	webhookSubscriptionColumns = `id, url, event_types, secret, status, created_at, updated_at`
+	webhookDeliveryColumns     = `id, subscription_id, event_id, event_type, status, attempts, response_status, last_error, next_attempt_at, created_at, delivered_at`
+)
+
+func scanWebhookSubscription(scanner interface{ Scan(dest ...any) error }, subscription *entity.WebhookSubscription) error {
+	var (
+		eventTypes string
+		updatedAt  sql.NullTime
+	)
+
+	err := scanner.Scan(
+		&subscription.ID,
+		&subscription.URL,
+		&eventTypes,
+		&subscription.Secret,
+		&subscription.Status,
+		&subscription.CreatedAt,
+		&updatedAt,
+	)
+
+	subscription.EventTypes = entity.SplitEventTypes(eventTypes)
+	if updatedAt.Valid {
+		subscription.UpdatedAt = &updatedAt.Time
+	}
+
+	return err
+}
+
+func scanWebhookDelivery(scanner interface{ Scan(dest ...any) error }, delivery *entity.WebhookDelivery) error {
+	var (
+		responseStatus sql.NullInt64
+		lastError      sql.NullString
+		deliveredAt    sql.NullTime
+	)
+
+	err := scanner.Scan(
+		&delivery.ID,
+		&delivery.SubscriptionID,
+		&delivery.EventID,
+		&delivery.EventType,
+		&delivery.Status,
+		&delivery.Attempts,
+		&responseStatus,
+		&lastError,
+		&delivery.NextAttemptAt,
+		&delivery.CreatedAt,
+		&deliveredAt,
+	)
+
+	delivery.ResponseStatus = int(responseStatus.Int64)
+	delivery.LastError = lastError.String
+	if deliveredAt.Valid {
+		delivery.DeliveredAt = &deliveredAt.Time
+	}
+
+	return err
+}
+
+func (r *webhookRepository) CreateSubscription(tx *sql.Tx, subscription *entity.WebhookSubscription) error {
+	query := `
+		INSERT INTO webhook_subscriptions (url, event_types, secret, status, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	id, err := r.dialect.InsertReturningID(
+		context.Background(),
+		tx,
+		query,
+		subscription.URL,
+		entity.JoinEventTypes(subscription.EventTypes),
+		subscription.Secret,
+		subscription.Status,
+		subscription.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	subscription.ID = id
+	return nil
+}
+
+func (r *webhookRepository) UpdateSubscription(tx *sql.Tx, subscription *entity.WebhookSubscription) error {
+	query := `
+	UPDATE webhook_subscriptions
+	SET	url = ?,
+		event_types = ?,
+		status = ?,
+		updated_at = ?
+	WHERE id = ?
+	`
+
+	_, err := tx.Exec(r.dialect.Rebind(query),
+		subscription.URL,
+		entity.JoinEventTypes(subscription.EventTypes),
+		subscription.Status,
+		subscription.UpdatedAt,
+		subscription.ID,
+	)
+	return err
+}
+
+func (r *webhookRepository) GetSubscriptionByID(ctx context.Context, id int64) (*entity.WebhookSubscription, error) {
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions WHERE id = ?`
+
+	subscription := &entity.WebhookSubscription{}
+	if err := scanWebhookSubscription(r.db.QueryRowContext(ctx, r.dialect.Rebind(query), id), subscription); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrWebhookSubscriptionNotFound
+		}
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+func (r *webhookRepository) GetAllSubscriptions(ctx context.Context, status *entity.WebhookStatus) ([]*entity.WebhookSubscription, error) {
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions`
+	var args []any
+
+	if status != nil {
+		query += ` WHERE status = ?`
+		args = append(args, *status)
+	}
+	query += ` ORDER BY id`
+
+	rows, err := r.db.QueryContext(ctx, r.dialect.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subscriptions []*entity.WebhookSubscription
+	for rows.Next() {
+		subscription := &entity.WebhookSubscription{}
+		if err := scanWebhookSubscription(rows, subscription); err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+// CreateDeliveries skips deliveries that already exist for the same subscription and event,
+// so fanning out an event again is harmless
+func (r *webhookRepository) CreateDeliveries(ctx context.Context, deliveries []*entity.WebhookDelivery) error {
+	query := `
+	INSERT INTO webhook_deliveries (
+		subscription_id,
+		event_id,
+		event_type,
+		status,
+		attempts,
+		next_attempt_at,
+		created_at
+	) VALUES (?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT (subscription_id, event_id) DO NOTHING
+	`
+
+	for _, delivery := range deliveries {
+		_, err := r.db.ExecContext(ctx, r.dialect.Rebind(query),
+			delivery.SubscriptionID,
+			delivery.EventID,
+			delivery.EventType,
+			delivery.Status,
+			delivery.Attempts,
+			delivery.NextAttemptAt,
+			delivery.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *webhookRepository) GetDeliveryByID(ctx context.Context, id int64) (*entity.WebhookDelivery, error) {
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE id = ?`
+
+	delivery := &entity.WebhookDelivery{}
+	if err := scanWebhookDelivery(r.db.QueryRowContext(ctx, r.dialect.Rebind(query), id), delivery); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrWebhookDeliveryNotFound
+		}
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+// GetDeliveriesBySubscriptionID returns the delivery log of a subscription, newest first
+func (r *webhookRepository) GetDeliveriesBySubscriptionID(ctx context.Context, subscriptionID int64, filter entity.WebhookDeliveryFilter) ([]*entity.WebhookDelivery, error) {
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE subscription_id = ?`
+	args := []any{subscriptionID}
+
+	if filter.Status != 0 {
+		query += ` AND status = ?`
+		args = append(args, filter.Status)
+	}
+	limit := filter.Limit
+	if limit == 0 {
+		limit = defaultWebhookDeliveryLimit
+	}
+	query += ` ORDER BY id DESC LIMIT ?`
+	args = append(args, limit)
+
+	return r.queryDeliveries(ctx, query, args...)
+}
+
+// GetPendingDeliveries returns the oldest pending deliveries of active subscriptions that are due for an attempt
+func (r *webhookRepository) GetPendingDeliveries(ctx context.Context, dueBefore time.Time, limit int) ([]*entity.WebhookDelivery, error) {
+	query := `
+	SELECT ` + webhookDeliveryColumns + `
+	FROM webhook_deliveries
+	WHERE status = ? AND next_attempt_at <= ?
+		AND subscription_id IN (SELECT id FROM webhook_subscriptions WHERE status = ?)
+	ORDER BY id
+	LIMIT ?
+	`
+
+	return r.queryDeliveries(ctx, query, entity.WebhookDeliveryStatusPending, dueBefore, entity.WebhookStatusActive, limit)
+}
+
+func (r *webhookRepository) queryDeliveries(ctx context.Context, query string, args ...any) ([]*entity.WebhookDelivery, error) {
+	rows, err := r.db.QueryContext(ctx, r.dialect.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*entity.WebhookDelivery
+	for rows.Next() {
+		delivery := &entity.WebhookDelivery{}
+		if err := scanWebhookDelivery(rows, delivery); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// UpdateDeliveryAttempt saves the outcome of a delivery attempt
+func (r *webhookRepository) UpdateDeliveryAttempt(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	query := `
+	UPDATE webhook_deliveries
+	SET	status = ?,
+		attempts = ?,
+		response_status = ?,
+		last_error = ?,
+		next_attempt_at = ?,
+		delivered_at = ?
+	WHERE id = ?
+	`
+
+	_, err := r.db.ExecContext(ctx, r.dialect.Rebind(query),
+		delivery.Status,
+		delivery.Attempts,
+		delivery.ResponseStatus,
+		delivery.LastError,
+		delivery.NextAttemptAt,
+		delivery.DeliveredAt,
+		delivery.ID,
+	)
+	return err
+}
+
+func (r *webhookRepository) BeginTx() (*sql.Tx, error) {
+	return r.db.Begin()
+}
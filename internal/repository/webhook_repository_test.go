@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"loan-management/infrastructure"
+	"loan-management/internal/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookRepository(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *sql.DB, dialect infrastructure.Dialect) {
+		repo := NewWebhookRepository(db, dialect)
+		outboxRepo := NewOutboxRepository(db, dialect)
+		ctx := context.Background()
+		createdAt := time.Date(2025, 2, 18, 0, 0, 0, 0, time.UTC)
+
+		tx, err := repo.BeginTx()
+		assert.NoError(t, err)
+		subscription := &entity.WebhookSubscription{
+			URL:        "https://partner.test/hooks",
+			EventTypes: []entity.EventType{entity.EventPaymentPosted, entity.EventLoanBecameDelinquent},
+			Secret:     "whsec_test",
+			Status:     entity.WebhookStatusActive,
+			CreatedAt:  createdAt,
+		}
+		assert.NoError(t, repo.CreateSubscription(tx, subscription))
+		event := &entity.Event{
+			Type:          entity.EventPaymentPosted,
+			AggregateType: entity.EventAggregateLoan,
+			AggregateID:   1,
+			Payload:       json.RawMessage(`{"loan_id":1}`),
+			Status:        entity.EventStatusPending,
+			NextAttemptAt: createdAt,
+			OccurredAt:    createdAt,
+		}
+		assert.NoError(t, outboxRepo.CreateEvent(tx, event))
+		assert.NoError(t, tx.Commit())
+
+		found, err := repo.GetSubscriptionByID(ctx, subscription.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, subscription.EventTypes, found.EventTypes)
+		assert.Equal(t, "whsec_test", found.Secret)
+		assert.Nil(t, found.UpdatedAt)
+
+		// fanning out the same event twice keeps a single delivery
+		delivery := &entity.WebhookDelivery{
+			SubscriptionID: subscription.ID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			Status:         entity.WebhookDeliveryStatusPending,
+			NextAttemptAt:  createdAt,
+			CreatedAt:      createdAt,
+		}
+		assert.NoError(t, repo.CreateDeliveries(ctx, []*entity.WebhookDelivery{delivery}))
+		assert.NoError(t, repo.CreateDeliveries(ctx, []*entity.WebhookDelivery{delivery}))
+
+		pending, err := repo.GetPendingDeliveries(ctx, createdAt, 10)
+		assert.NoError(t, err)
+		assert.Len(t, pending, 1)
+		assert.Equal(t, event.ID, pending[0].EventID)
+
+		deliveryID := pending[0].ID
+		pending[0].Attempts = 1
+		pending[0].ResponseStatus = 500
+		pending[0].LastError = "unexpected status 500: boom"
+		pending[0].NextAttemptAt = createdAt.Add(2 * time.Second)
+		assert.NoError(t, repo.UpdateDeliveryAttempt(ctx, pending[0]))
+
+		pending, err = repo.GetPendingDeliveries(ctx, createdAt.Add(time.Second), 10)
+		assert.NoError(t, err)
+		assert.Empty(t, pending)
+
+		failed, err := repo.GetDeliveryByID(ctx, deliveryID)
+		if assert.NoError(t, err) {
+			assert.Equal(t, 500, failed.ResponseStatus)
+			assert.Equal(t, "unexpected status 500: boom", failed.LastError)
+		}
+
+		// deliveries of disabled subscriptions are held back
+		tx, err = repo.BeginTx()
+		assert.NoError(t, err)
+		updatedAt := createdAt.Add(time.Minute)
+		found.Status = entity.WebhookStatusDisabled
+		found.UpdatedAt = &updatedAt
+		assert.NoError(t, repo.UpdateSubscription(tx, found))
+		assert.NoError(t, tx.Commit())
+
+		pending, err = repo.GetPendingDeliveries(ctx, createdAt.Add(time.Hour), 10)
+		assert.NoError(t, err)
+		assert.Empty(t, pending)
+
+		active := entity.WebhookStatusActive
+		subscriptions, err := repo.GetAllSubscriptions(ctx, &active)
+		assert.NoError(t, err)
+		assert.Empty(t, subscriptions)
+
+		subscriptions, err = repo.GetAllSubscriptions(ctx, nil)
+		assert.NoError(t, err)
+		assert.Len(t, subscriptions, 1)
+
+		deliveries, err := repo.GetDeliveriesBySubscriptionID(ctx, subscription.ID, entity.WebhookDeliveryFilter{Status: entity.WebhookDeliveryStatusPending})
+		assert.NoError(t, err)
+		assert.Len(t, deliveries, 1)
+
+		deliveries, err = repo.GetDeliveriesBySubscriptionID(ctx, subscription.ID, entity.WebhookDeliveryFilter{Status: entity.WebhookDeliveryStatusDelivered})
+		assert.NoError(t, err)
+		assert.Empty(t, deliveries)
+
+		_, err = repo.GetSubscriptionByID(ctx, 69)
+		assert.ErrorIs(t, err, ErrWebhookSubscriptionNotFound)
+
+		_, err = repo.GetDeliveryByID(ctx, 69)
+		assert.ErrorIs(t, err, ErrWebhookDeliveryNotFound)
+	})
+}
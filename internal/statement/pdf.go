@@ -0,0 +1,275 @@
+package statement
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"loan-management/internal/entity"
+	"loan-management/internal/i18n"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A4 in points, the pdf unit
+const (
+	pageWidth    = 595.28
+	pageHeight   = 841.89
+	margin       = 40.0
+	footerHeight = 20.0
+	rowHeight    = 13.0
+)
+
+const (
+	fontRegular = "F1"
+	fontBold    = "F2"
+)
+
+// WritePDF lays the statement out on A4 pages with the standard Helvetica fonts, so no font is embedded and
+// text outside latin-1 is replaced
+func WritePDF(w io.Writer, locale i18n.Locale, statement *entity.LoanStatement) error {
+	t := func(key string, params map[string]string) string { return i18n.T(locale, key, params) }
+	loan := statement.Loan
+	doc := newPDFDocument()
+
+	doc.text(margin, doc.y, fontBold, 16, t("statement.title", nil))
+	doc.y -= 24
+
+	details := [][2]string{
+		{t("statement.loan", nil), loan.Reference()},
+		{t("statement.period", nil), statement.From.Format(time.DateOnly) + " - " + statement.To.Format(time.DateOnly)},
+		{t("statement.principal", nil), money(loan.Amount)},
+		{t("statement.interest", nil), strconv.FormatFloat(loan.Interest, 'f', -1, 64) + "% " + t(loan.InterestType.Key(), nil)},
+		{t("statement.tenure", nil), strconv.Itoa(int(loan.Tenure)) + " " + t(loan.TenureType.Key(), nil)},
+		{t("statement.status", nil), t(loan.Status.Key(), nil)},
+		{t("statement.generated_at", nil), statement.GeneratedAt.Format("2006-01-02 15:04 MST")},
+	}
+	summary := [][2]string{
+		{t("statement.opening_balance", nil), money(statement.OpeningBalance)},
+		{t("statement.total_paid", nil), money(statement.TotalPaid)},
+		{t("statement.total_penalty", nil), money(statement.TotalPenalty)},
+		{t("statement.closing_balance", nil), money(statement.ClosingBalance)},
+	}
+	for i := 0; i < len(details) || i < len(summary); i++ {
+		if i < len(details) {
+			doc.text(margin, doc.y, fontRegular, 10, details[i][0]+": "+details[i][1])
+		}
+		if i < len(summary) {
+			doc.text(340, doc.y, fontRegular, 10, summary[i][0])
+			doc.textRight(pageWidth-margin, doc.y, fontBold, 10, summary[i][1])
+		}
+		doc.y -= rowHeight
+	}
+
+	schedule := []pdfColumn{
+		{x: margin, title: t("statement.column.installment", nil)},
+		{x: 95, title: t("statement.column.due_date", nil)},
+		{x: 250, right: true, title: t("statement.column.principal", nil)},
+		{x: 320, right: true, title: t("statement.column.interest", nil)},
+		{x: 390, right: true, title: t("statement.column.total", nil)},
+		{x: 405, title: t("statement.column.status", nil)},
+		{x: 485, title: t("statement.column.paid_at", nil)},
+	}
+	doc.section(t("statement.schedule", nil), schedule)
+	for _, payment := range statement.Schedule {
+		doc.row(schedule,
+			strconv.Itoa(int(payment.PaymentNo)),
+			payment.DueDate.String(),
+			money(payment.Amount),
+			money(payment.Interest),
+			money(payment.TotalAmount),
+			t(payment.Status.Key(), nil),
+			optionalDate(payment.PaidAt),
+		)
+	}
+
+	activity := []pdfColumn{
+		{x: margin, title: t("statement.column.date", nil)},
+		{x: 105, title: t("statement.column.description", nil), width: 60},
+		{x: 405, right: true, title: t("statement.column.debit", nil)},
+		{x: 480, right: true, title: t("statement.column.credit", nil)},
+		{x: pageWidth - margin, right: true, title: t("statement.column.balance", nil)},
+	}
+	doc.section(t("statement.activity", nil), activity)
+	doc.row(activity, statement.From.Format(time.DateOnly), t("statement.opening_balance", nil), "", "", money(statement.OpeningBalance))
+	for _, entry := range statement.Entries {
+		doc.row(activity, entry.Date.Format(time.DateOnly), Describe(locale, entry), optionalMoney(entry.Debit), optionalMoney(entry.Credit), money(entry.Balance))
+	}
+	doc.row(activity, statement.To.Format(time.DateOnly), t("statement.closing_balance", nil), "", "", money(statement.ClosingBalance))
+
+	return doc.write(w, func(page, pages int) string {
+		return t("statement.page", map[string]string{"page": strconv.Itoa(page), "pages": strconv.Itoa(pages)})
+	})
+}
+
+type pdfColumn struct {
+	x     float64
+	right bool
+	title string
+	// width truncates longer values to that many characters
+	width int
+}
+
+// pdfDocument draws text top to bottom, y is the baseline of the next row and a row that doesn't fit starts a page
+type pdfDocument struct {
+	pages   []*bytes.Buffer
+	y       float64
+	columns []pdfColumn
+}
+
+func newPDFDocument() *pdfDocument {
+	doc := &pdfDocument{}
+	doc.newPage()
+	return doc
+}
+
+func (d *pdfDocument) newPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = pageHeight - margin - 12
+}
+
+func (d *pdfDocument) page() *bytes.Buffer {
+	return d.pages[len(d.pages)-1]
+}
+
+// ensure starts a new page unless height fits above the footer, repeating the header of the current table
+func (d *pdfDocument) ensure(height float64) {
+	if d.y-height >= margin+footerHeight {
+		return
+	}
+
+	d.newPage()
+	if d.columns != nil {
+		d.header(d.columns)
+	}
+}
+
+func (d *pdfDocument) section(title string, columns []pdfColumn) {
+	d.columns = nil
+	d.y -= 10
+	d.ensure(3 * rowHeight)
+	d.text(margin, d.y, fontBold, 12, title)
+	d.y -= 18
+	d.header(columns)
+	d.columns = columns
+}
+
+func (d *pdfDocument) header(columns []pdfColumn) {
+	for _, column := range columns {
+		d.cell(column, fontBold, column.title)
+	}
+	d.y -= 4
+	fmt.Fprintf(d.page(), "0.5 w %.2f %.2f m %.2f %.2f l S\n", margin, d.y, pageWidth-margin, d.y)
+	d.y -= rowHeight
+}
+
+func (d *pdfDocument) row(columns []pdfColumn, values ...string) {
+	d.ensure(rowHeight)
+	for i, column := range columns {
+		d.cell(column, fontRegular, values[i])
+	}
+	d.y -= rowHeight
+}
+
+func (d *pdfDocument) cell(column pdfColumn, font string, value string) {
+	if column.width > 0 && len([]rune(value)) > column.width {
+		value = string([]rune(value)[:column.width-3]) + "..."
+	}
+
+	if column.right {
+		d.textRight(column.x, d.y, font, 9, value)
+	} else {
+		d.text(column.x, d.y, font, 9, value)
+	}
+}
+
+func (d *pdfDocument) text(x, y float64, font string, size float64, s string) {
+	drawText(d.page(), x, y, font, size, s)
+}
+
+// textRight ends the text at x, measured with the Helvetica widths of the characters amounts are made of
+func (d *pdfDocument) textRight(x, y float64, font string, size float64, s string) {
+	d.text(x-textWidth(s, size), y, font, size, s)
+}
+
+func drawText(page *bytes.Buffer, x, y float64, font string, size float64, s string) {
+	fmt.Fprintf(page, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, escapePDF(s))
+}
+
+func textWidth(s string, size float64) float64 {
+	var width float64
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			width += 556
+		case r == '.' || r == ',' || r == ' ':
+			width += 278
+		case r == '-':
+			width += 333
+		default:
+			width += 600
+		}
+	}
+	return width * size / 1000
+}
+
+// escapePDF encodes s as a WinAnsi string literal
+func escapePDF(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// write assembles the catalog, the fonts and the pages with their footers, followed by the cross-reference table
+func (d *pdfDocument) write(w io.Writer, footer func(page, pages int) string) error {
+	var (
+		out     bytes.Buffer
+		offsets []int
+	)
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	// objects 1 to 4 are fixed, every page adds a page and its content stream
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+
+	out.WriteString("%PDF-1.4\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range d.pages {
+		text := footer(i+1, len(d.pages))
+		drawText(page, pageWidth-margin-textWidth(text, 8), margin, fontRegular, 8, text)
+
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /%s 3 0 R /%s 4 0 R >> >> /Contents %d 0 R >>", pageWidth, pageHeight, fontRegular, fontBold, 6+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := w.Write(out.Bytes())
+	return err
+}
@@ -0,0 +1,139 @@
+// Package statement renders loan statements as csv for spreadsheets or pdf for borrowers
+package statement
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"loan-management/internal/entity"
+	"loan-management/internal/i18n"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrUnknownFormat = errors.New("unknown statement format")
+
+// ContentType is the media type of the rendered format
+func ContentType(format entity.StatementFormat) string {
+	switch format {
+	case entity.StatementFormatPDF:
+		return "application/pdf"
+	case entity.StatementFormatCSV:
+		return "text/csv; charset=utf-8"
+	default:
+		return ""
+	}
+}
+
+// Filename names a rendered statement after its loan and period, e.g. statement-LOAN-1-20260901-20261019.pdf
+func Filename(statement *entity.LoanStatement, format entity.StatementFormat) string {
+	return fmt.Sprintf("statement-%s-%s-%s.%s", statement.Loan.Reference(), statement.From.Format("20060102"), statement.To.Format("20060102"), format)
+}
+
+// Write renders the statement with its labels in locale
+func Write(w io.Writer, format entity.StatementFormat, locale i18n.Locale, statement *entity.LoanStatement) error {
+	switch format {
+	case entity.StatementFormatPDF:
+		return WritePDF(w, locale, statement)
+	case entity.StatementFormatCSV:
+		return WriteCSV(w, locale, statement)
+	default:
+		return ErrUnknownFormat
+	}
+}
+
+// WriteCSV writes the schedule and the activity as two tables separated by an empty line. Column names stay the
+// same in every locale so exports can be processed, only descriptions and statuses are translated
+func WriteCSV(w io.Writer, locale i18n.Locale, statement *entity.LoanStatement) error {
+	writer := csv.NewWriter(w)
+
+	writer.Write([]string{"installment", "due_date", "principal", "interest", "total", "status", "paid_at", "transaction_id"})
+	for _, payment := range statement.Schedule {
+		writer.Write([]string{
+			strconv.Itoa(int(payment.PaymentNo)),
+			payment.DueDate.String(),
+			money(payment.Amount),
+			money(payment.Interest),
+			money(payment.TotalAmount),
+			i18n.T(locale, payment.Status.Key(), nil),
+			optionalDate(payment.PaidAt),
+			optionalID(payment.TransactionID),
+		})
+	}
+
+	writer.Write(nil)
+	writer.Write([]string{"date", "type", "description", "transaction_id", "debit", "credit", "balance"})
+	writer.Write([]string{statement.From.Format(time.DateOnly), "opening_balance", i18n.T(locale, "statement.opening_balance", nil), "", "", "", money(statement.OpeningBalance)})
+	for _, entry := range statement.Entries {
+		writer.Write([]string{
+			entry.Date.Format(time.DateOnly),
+			string(entry.Type),
+			Describe(locale, entry),
+			optionalID(nonZero(entry.TransactionID)),
+			optionalMoney(entry.Debit),
+			optionalMoney(entry.Credit),
+			money(entry.Balance),
+		})
+	}
+	writer.Write([]string{statement.To.Format(time.DateOnly), "closing_balance", i18n.T(locale, "statement.closing_balance", nil), "", money(statement.TotalPenalty), money(statement.TotalPaid), money(statement.ClosingBalance)})
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// Describe is the translated description of an activity entry
+func Describe(locale i18n.Locale, entry *entity.StatementEntry) string {
+	params := map[string]string{"transaction": "#" + strconv.FormatInt(entry.TransactionID, 10)}
+
+	key := "statement.entry." + string(entry.Type)
+	if entry.Type == entity.StatementEntryPayment && len(entry.Installments) > 0 {
+		installments := make([]string, len(entry.Installments))
+		for i, no := range entry.Installments {
+			installments[i] = strconv.Itoa(int(no))
+		}
+		params["installments"] = strings.Join(installments, ", ")
+		key = "statement.entry.payment_installments"
+	}
+
+	return i18n.T(locale, key, params)
+}
+
+// money formats an amount to cents, without the minus sign float rounding leaves on a settled balance
+func money(amount float64) string {
+	rounded := math.Round(amount*100) / 100
+	if rounded == 0 {
+		rounded = 0
+	}
+	return strconv.FormatFloat(rounded, 'f', 2, 64)
+}
+
+func optionalMoney(amount float64) string {
+	if amount == 0 {
+		return ""
+	}
+	return money(amount)
+}
+
+func optionalDate(date *time.Time) string {
+	if date == nil {
+		return ""
+	}
+	return date.Format(time.DateOnly)
+}
+
+func optionalID(id *int64) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.FormatInt(*id, 10)
+}
+
+func nonZero(id int64) *int64 {
+	if id == 0 {
+		return nil
+	}
+	return &id
+}
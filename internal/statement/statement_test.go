@@ -0,0 +1,113 @@
+package statement
+
+import (
+	"bytes"
+	"fmt"
+	"loan-management/internal/entity"
+	"loan-management/internal/i18n"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testStatement(entries int) *entity.LoanStatement {
+	createdAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	paidAt := time.Date(2025, 1, 8, 0, 0, 0, 0, time.UTC)
+	transactionID := int64(2)
+
+	statement := &entity.LoanStatement{
+		Loan:           &entity.Loan{ID: 1, Amount: 1000, Interest: 10, Tenure: 2, Status: entity.LoanStatusActive, CreatedAt: createdAt},
+		From:           createdAt,
+		To:             time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC),
+		GeneratedAt:    time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+		ClosingBalance: 501.92,
+		TotalPaid:      501.92,
+		Schedule: []*entity.Payment{
+			{PaymentNo: 1, DueDate: entity.NewDate(2025, 1, 8), Amount: 500, Interest: 1.92, TotalAmount: 501.92, Status: entity.PaymentStatusPaid, PaidAt: &paidAt, TransactionID: &transactionID},
+			{PaymentNo: 2, DueDate: entity.NewDate(2025, 1, 15), Amount: 500, Interest: 1.92, TotalAmount: 501.92, Status: entity.PaymentStatusActive},
+		},
+		Entries: []*entity.StatementEntry{
+			{Date: createdAt, Type: entity.StatementEntryDisbursement, Debit: 1003.84, Balance: 1003.84},
+			{Date: paidAt, Type: entity.StatementEntryPayment, TransactionID: 2, Installments: []int32{1}, Credit: 501.92, Balance: 501.92},
+		},
+	}
+
+	for i := 0; i < entries; i++ {
+		statement.Entries = append(statement.Entries, &entity.StatementEntry{Date: paidAt, Type: entity.StatementEntryReversal, TransactionID: int64(i), Debit: 1, Balance: 1})
+	}
+
+	return statement
+}
+
+func TestWriteCSV(t *testing.T) {
+	var out bytes.Buffer
+
+	err := WriteCSV(&out, i18n.Indonesian, testStatement(0))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "installment,due_date,principal,interest,total,status,paid_at,transaction_id\n"+
+		"1,2025-01-08,500.00,1.92,501.92,Lunas,2025-01-08,2\n"+
+		"2,2025-01-15,500.00,1.92,501.92,Belum Dibayar,,\n"+
+		"\n"+
+		"date,type,description,transaction_id,debit,credit,balance\n"+
+		"2025-01-01,opening_balance,Saldo awal,,,,0.00\n"+
+		"2025-01-01,disbursement,\"Pencairan pinjaman, pokok dan bunga\",,1003.84,,1003.84\n"+
+		"2025-01-08,payment,\"Pembayaran #2, angsuran 1\",2,,501.92,501.92\n"+
+		"2025-01-31,closing_balance,Saldo akhir,,0.00,501.92,501.92\n", out.String())
+}
+
+func TestWritePDF(t *testing.T) {
+	t.Run("Success WritePDF", func(t *testing.T) {
+		var out bytes.Buffer
+
+		err := WritePDF(&out, i18n.English, testStatement(0))
+
+		assert.NoError(t, err)
+		assertValidPDF(t, out.Bytes())
+		assert.Contains(t, out.String(), "/Count 1")
+		assert.Contains(t, out.String(), "(Payment #2, installment 1) Tj")
+		assert.Contains(t, out.String(), "(Page 1 of 1) Tj")
+	})
+
+	t.Run("Success WritePDF - Several Pages", func(t *testing.T) {
+		var out bytes.Buffer
+
+		err := WritePDF(&out, i18n.English, testStatement(150))
+
+		assert.NoError(t, err)
+		assertValidPDF(t, out.Bytes())
+		assert.Contains(t, out.String(), "/Count 4")
+		assert.Contains(t, out.String(), "(Page 4 of 4) Tj")
+		assert.Equal(t, 4, strings.Count(out.String(), "(Balance) Tj"), "the activity header is repeated on every page")
+	})
+}
+
+// assertValidPDF checks that the cross-reference table points at the objects and the stream lengths are right
+func assertValidPDF(t *testing.T, pdf []byte) {
+	assert.True(t, bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")))
+
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(pdf)
+	if !assert.NotNil(t, startxref) {
+		return
+	}
+	xref, _ := strconv.Atoi(string(startxref[1]))
+	assert.True(t, bytes.HasPrefix(pdf[xref:], []byte("xref\n")))
+
+	for i, offset := range regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(pdf[xref:], -1) {
+		at, _ := strconv.Atoi(string(offset[1]))
+		assert.True(t, bytes.HasPrefix(pdf[at:], []byte(fmt.Sprintf("%d 0 obj\n", i+1))), "object %d", i+1)
+	}
+
+	for _, stream := range regexp.MustCompile(`<< /Length (\d+) >>\nstream\n`).FindAllSubmatchIndex(pdf, -1) {
+		length, _ := strconv.Atoi(string(pdf[stream[2]:stream[3]]))
+		assert.True(t, bytes.HasPrefix(pdf[stream[1]+length:], []byte("endstream")))
+	}
+}
+
+func TestEscapePDF(t *testing.T) {
+	assert.Equal(t, `Pay \(now\) \\ caf\351 ?`, escapePDF("Pay (now) \\ café 日"))
+}
@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"loan-management/internal/entity"
+	"loan-management/internal/repository"
+	"loan-management/internal/validation"
+	"reflect"
+)
+
+type AuditUsecaseInterface interface {
+	Record(ctx context.Context, tx *sql.Tx, action entity.AuditAction, entityType string, entityID int64, before any, after any) error
+	GetAuditLogs(ctx context.Context, filter entity.AuditLogFilter) ([]*entity.AuditLog, error)
+}
+
+type AuditUsecase struct {
+	auditLogRepo repository.AuditLogRepository
+}
+
+func NewAuditUsecase(auditLogRepo repository.AuditLogRepository) *AuditUsecase {
+	return &AuditUsecase{
+		auditLogRepo: auditLogRepo,
+	}
+}
+
+// Record writes the audit log inside tx so it is only kept when the change itself commits,
+// before is nil on creation
+func (u *AuditUsecase) Record(ctx context.Context, tx *sql.Tx, action entity.AuditAction, entityType string, entityID int64, before any, after any) error {
+	changes, err := diff(before, after)
+	if err != nil {
+		return err
+	}
+
+	log := &entity.AuditLog{
+		ActorName:  "system",
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Changes:    changes,
+		RequestID:  entity.RequestIDFromContext(ctx),
+		CreatedAt:  now(),
+	}
+
+	if identity := entity.IdentityFromContext(ctx); identity != nil {
+		log.ActorType = identity.Type
+		log.ActorName = identity.Name
+		log.ActorID = identity.UserID
+		if identity.Type == entity.IdentityTypePartner {
+			log.ActorID = identity.APIKeyID
+		}
+	}
+
+	return u.auditLogRepo.CreateAuditLog(tx, log)
+}
+
+func (u *AuditUsecase) GetAuditLogs(ctx context.Context, filter entity.AuditLogFilter) ([]*entity.AuditLog, error) {
+	if err := authorize(ctx, entity.PermAuditRead); err != nil {
+		return nil, err
+	}
+
+	if err := validation.Struct(filter); err != nil {
+		return nil, err
+	}
+
+	return u.auditLogRepo.GetAuditLogs(ctx, filter)
+}
+
+// diff compares the json form of both snapshots, so fields hidden from json (e.g. password hash) never reach the log
+func diff(before any, after any) (json.RawMessage, error) {
+	beforeFields, err := toFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := toFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]entity.AuditChange{}
+	for field, value := range afterFields {
+		if previous, ok := beforeFields[field]; !ok || !reflect.DeepEqual(previous, value) {
+			changes[field] = entity.AuditChange{Before: previous, After: value}
+		}
+	}
+	for field, previous := range beforeFields {
+		if _, ok := afterFields[field]; !ok {
+			changes[field] = entity.AuditChange{Before: previous}
+		}
+	}
+
+	return json.Marshal(changes)
+}
+
+func toFields(snapshot any) (map[string]any, error) {
+	fields := map[string]any{}
+	if snapshot == nil || reflect.ValueOf(snapshot).IsZero() {
+		return fields, nil
+	}
+
+	b, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"context"
+	"loan-management/internal/entity"
+	internalMock "loan-management/internal/mock"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRecord(t *testing.T) {
+	mockTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return mockTime }
+	defer func() { now = time.Now }()
+
+	t.Run("Success Record - Only Changed Fields", func(t *testing.T) {
+		mockRepo := new(internalMock.MockAuditLogRepository)
+		auditUsecase := NewAuditUsecase(mockRepo)
+
+		ctx := entity.WithIdentity(context.Background(), &entity.Identity{Type: entity.IdentityTypeBorrower, Role: entity.RoleAdmin, UserID: 1, Name: "admin"})
+		ctx = entity.WithRequestID(ctx, "request-id")
+
+		before := &entity.User{ID: 2, Email: "test@test", Name: "test", PasswordHash: "secret", Role: entity.RoleBorrower}
+		after := *before
+		after.Role = entity.RoleFinance
+
+		var recorded *entity.AuditLog
+		mockRepo.On("CreateAuditLog", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			recorded = args.Get(1).(*entity.AuditLog)
+		}).Return(nil)
+
+		err := auditUsecase.Record(ctx, nil, entity.AuditActionUserUpdateRole, entity.AuditEntityUser, 2, before, &after)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), recorded.ActorID)
+		assert.Equal(t, "admin", recorded.ActorName)
+		assert.Equal(t, "request-id", recorded.RequestID)
+		assert.Equal(t, mockTime, recorded.CreatedAt)
+		assert.JSONEq(t, `{"Role":{"before":1,"after":4}}`, string(recorded.Changes))
+	})
+
+	t.Run("Success Record - Creation By System", func(t *testing.T) {
+		mockRepo := new(internalMock.MockAuditLogRepository)
+		auditUsecase := NewAuditUsecase(mockRepo)
+
+		var recorded *entity.AuditLog
+		mockRepo.On("CreateAuditLog", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			recorded = args.Get(1).(*entity.AuditLog)
+		}).Return(nil)
+
+		user := &entity.User{ID: 2, Email: "test@test", PasswordHash: "secret"}
+		err := auditUsecase.Record(context.Background(), nil, entity.AuditActionUserRegister, entity.AuditEntityUser, 2, nil, user)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "system", recorded.ActorName)
+		assert.Contains(t, string(recorded.Changes), `"Email":{"before":null,"after":"test@test"}`)
+		assert.NotContains(t, string(recorded.Changes), "secret")
+	})
+}
+
+func TestGetAuditLogs(t *testing.T) {
+	t.Run("Failed GetAuditLogs - Not Admin", func(t *testing.T) {
+		mockRepo := new(internalMock.MockAuditLogRepository)
+		auditUsecase := NewAuditUsecase(mockRepo)
+		ctx := entity.WithIdentity(context.Background(), &entity.Identity{Type: entity.IdentityTypeBorrower, Role: entity.RoleFinance, UserID: 1})
+
+		_, err := auditUsecase.GetAuditLogs(ctx, entity.AuditLogFilter{EntityType: entity.AuditEntityLoan})
+
+		assert.ErrorIs(t, err, ErrForbidden)
+		mockRepo.AssertNotCalled(t, "GetAuditLogs", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failed GetAuditLogs - Missing Entity Type", func(t *testing.T) {
+		mockRepo := new(internalMock.MockAuditLogRepository)
+		auditUsecase := NewAuditUsecase(mockRepo)
+
+		_, err := auditUsecase.GetAuditLogs(context.Background(), entity.AuditLogFilter{})
+
+		assert.Error(t, err)
+		mockRepo.AssertNotCalled(t, "GetAuditLogs", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,251 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"loan-management/internal/apperror"
+	"loan-management/internal/entity"
+	"loan-management/internal/repository"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	apiKeyPrefix = "lm"
+	tokenIssuer  = "loan-management"
+)
+
+var (
+	ErrInvalidCredentials = apperror.Unauthorized("INVALID_CREDENTIALS", "Invalid email or password")
+	ErrInvalidToken       = apperror.Unauthorized("INVALID_TOKEN", "Invalid or expired token")
+	ErrInvalidAPIKey      = apperror.Unauthorized("INVALID_API_KEY", "Invalid or expired api key")
+)
+
+type AuthUsecaseInterface interface {
+	Login(ctx context.Context, email string, password string) (*entity.Token, error)
+	AuthenticateToken(ctx context.Context, token string) (*entity.Identity, error)
+	AuthenticateAPIKey(ctx context.Context, key string) (*entity.Identity, error)
+	GetAllAPIKeys(ctx context.Context) ([]*entity.APIKey, error)
+	CreateAPIKey(ctx context.Context, name string) (*entity.IssuedAPIKey, error)
+	RotateAPIKey(ctx context.Context, id int64, gracePeriod time.Duration) (*entity.IssuedAPIKey, error)
+	RevokeAPIKey(ctx context.Context, id int64) error
+}
+
+type AuthUsecase struct {
+	userUsecase UserUsecaseInterface
+	apiKeyRepo  repository.APIKeyRepository
+	secret      []byte
+	tokenTTL    time.Duration
+}
+
+func NewAuthUsecase(userUsecase UserUsecaseInterface, apiKeyRepo repository.APIKeyRepository, secret []byte, tokenTTL time.Duration) *AuthUsecase {
+	return &AuthUsecase{
+		userUsecase: userUsecase,
+		apiKeyRepo:  apiKeyRepo,
+		secret:      secret,
+		tokenTTL:    tokenTTL,
+	}
+}
+
+type borrowerClaims struct {
+	Name string      `json:"name"`
+	Role entity.Role `json:"role"`
+	jwt.RegisteredClaims
+}
+
+func (u *AuthUsecase) Login(ctx context.Context, email string, password string) (*entity.Token, error) {
+	user, err := u.userUsecase.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	// users registered without a password can't login
+	if user.PasswordHash == "" || bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	return u.issueToken(user)
+}
+
+func (u *AuthUsecase) issueToken(user *entity.User) (*entity.Token, error) {
+	issuedAt := now()
+	expiresAt := issuedAt.Add(u.tokenTTL)
+
+	claims := borrowerClaims{
+		Name: user.Name,
+		Role: user.Role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    tokenIssuer,
+			Subject:   strconv.FormatInt(user.ID, 10),
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(u.secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.Token{AccessToken: signed, TokenType: "Bearer", ExpiresAt: expiresAt}, nil
+}
+
+func (u *AuthUsecase) AuthenticateToken(ctx context.Context, token string) (*entity.Identity, error) {
+	claims := &borrowerClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return u.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(tokenIssuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(now),
+	)
+	if err != nil {
+		return nil, ErrInvalidToken.Wrap(err)
+	}
+
+	userID, err := strconv.ParseInt(claims.Subject, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidToken.Wrap(err)
+	}
+
+	// the role is read from the user instead of the token claim, so a role change applies to the tokens already
+	// issued
+	user, err := u.userUsecase.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil, ErrInvalidToken.Wrap(err)
+		}
+		return nil, err
+	}
+
+	role := user.Role
+	if role == 0 {
+		role = entity.RoleBorrower
+	}
+
+	return &entity.Identity{Type: entity.IdentityTypeBorrower, Role: role, UserID: userID, Name: claims.Name}, nil
+}
+
+func (u *AuthUsecase) AuthenticateAPIKey(ctx context.Context, key string) (*entity.Identity, error) {
+	parts := strings.Split(key, "_")
+	if len(parts) != 3 || parts[0] != apiKeyPrefix {
+		return nil, ErrInvalidAPIKey
+	}
+
+	apiKey, err := u.apiKeyRepo.GetAPIKeyByPrefix(ctx, parts[1])
+	if err != nil {
+		if errors.Is(err, repository.ErrAPIKeyNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashAPIKey(key)), []byte(apiKey.KeyHash)) != 1 || !apiKey.IsUsable(now()) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if err := u.apiKeyRepo.TouchAPIKey(ctx, apiKey.ID, now()); err != nil {
+		return nil, err
+	}
+
+	return &entity.Identity{Type: entity.IdentityTypePartner, Role: entity.RolePartner, APIKeyID: apiKey.ID, Name: apiKey.Name}, nil
+}
+
+func (u *AuthUsecase) GetAllAPIKeys(ctx context.Context) ([]*entity.APIKey, error) {
+	return u.apiKeyRepo.GetAllAPIKeys(ctx)
+}
+
+func (u *AuthUsecase) CreateAPIKey(ctx context.Context, name string) (*entity.IssuedAPIKey, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, ErrMissingRequiredField
+	}
+
+	prefix, err := randomHex(4)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := randomHex(24)
+	if err != nil {
+		return nil, err
+	}
+
+	key := strings.Join([]string{apiKeyPrefix, prefix, secret}, "_")
+	apiKey := &entity.APIKey{
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   hashAPIKey(key),
+		Status:    entity.APIKeyStatusActive,
+		CreatedAt: now(),
+	}
+
+	if err := u.apiKeyRepo.CreateAPIKey(ctx, apiKey); err != nil {
+		return nil, err
+	}
+
+	return &entity.IssuedAPIKey{APIKey: apiKey, Key: key}, nil
+}
+
+// RotateAPIKey issues a new key for the same partner, the old one keeps working during the grace period
+func (u *AuthUsecase) RotateAPIKey(ctx context.Context, id int64, gracePeriod time.Duration) (*entity.IssuedAPIKey, error) {
+	oldKey, err := u.apiKeyRepo.GetAPIKeyByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !oldKey.IsUsable(now()) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	issued, err := u.CreateAPIKey(ctx, oldKey.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.apiKeyRepo.UpdateAPIKeyExpiry(ctx, oldKey.ID, now().Add(gracePeriod)); err != nil {
+		return nil, err
+	}
+
+	return issued, nil
+}
+
+func (u *AuthUsecase) RevokeAPIKey(ctx context.Context, id int64) error {
+	if _, err := u.apiKeyRepo.GetAPIKeyByID(ctx, id); err != nil {
+		return err
+	}
+
+	return u.apiKeyRepo.RevokeAPIKey(ctx, id)
+}
+
+// api keys are high entropy so a fast hash is enough, unlike passwords
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,156 @@
+package usecase
+
+import (
+	"context"
+	"loan-management/internal/entity"
+	internalMock "loan-management/internal/mock"
+	"loan-management/internal/repository"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func setupAuthMocks() (*AuthUsecase, *internalMock.MockUserUsecase, *internalMock.MockAPIKeyRepository) {
+	mockUserUsecase := new(internalMock.MockUserUsecase)
+	mockAPIKeyRepo := new(internalMock.MockAPIKeyRepository)
+
+	mockUsecase := NewAuthUsecase(mockUserUsecase, mockAPIKeyRepo, []byte("secret"), time.Hour)
+
+	return mockUsecase, mockUserUsecase, mockAPIKeyRepo
+}
+
+func TestLogin(t *testing.T) {
+	passwordHash, _ := hashPassword("password")
+	mockUser := &entity.User{ID: 1, Email: "test@test", Name: "test", PasswordHash: passwordHash, Role: entity.RoleCreditOfficer}
+
+	t.Run("Success Login", func(t *testing.T) {
+		mockUsecase, mockUserUsecase, _ := setupAuthMocks()
+		mockUserUsecase.On("GetUserByEmail", mock.Anything, "test@test").Return(mockUser, nil)
+		mockUserUsecase.On("GetUserByID", mock.Anything, int64(1)).Return(mockUser, nil)
+
+		token, err := mockUsecase.Login(context.Background(), "test@test", "password")
+		assert.NoError(t, err)
+		assert.Equal(t, "Bearer", token.TokenType)
+
+		identity, err := mockUsecase.AuthenticateToken(context.Background(), token.AccessToken)
+		assert.NoError(t, err)
+		assert.Equal(t, &entity.Identity{Type: entity.IdentityTypeBorrower, Role: entity.RoleCreditOfficer, UserID: 1, Name: "test"}, identity)
+	})
+
+	t.Run("Failed Login - Wrong Password", func(t *testing.T) {
+		mockUsecase, mockUserUsecase, _ := setupAuthMocks()
+		mockUserUsecase.On("GetUserByEmail", mock.Anything, "test@test").Return(mockUser, nil)
+
+		_, err := mockUsecase.Login(context.Background(), "test@test", "wrong password")
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+
+	t.Run("Failed Login - Unknown Email", func(t *testing.T) {
+		mockUsecase, mockUserUsecase, _ := setupAuthMocks()
+		mockUserUsecase.On("GetUserByEmail", mock.Anything, "who@test").Return(nil, repository.ErrUserNotFound)
+
+		_, err := mockUsecase.Login(context.Background(), "who@test", "password")
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+}
+
+func TestAuthenticateToken(t *testing.T) {
+	t.Run("Success AuthenticateToken - Role Changed After Login", func(t *testing.T) {
+		mockUsecase, mockUserUsecase, _ := setupAuthMocks()
+		token, err := mockUsecase.issueToken(&entity.User{ID: 1, Name: "test", Role: entity.RoleAdmin})
+		assert.NoError(t, err)
+		mockUserUsecase.On("GetUserByID", mock.Anything, int64(1)).Return(&entity.User{ID: 1, Name: "test", Role: entity.RoleBorrower}, nil)
+
+		identity, err := mockUsecase.AuthenticateToken(context.Background(), token.AccessToken)
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.RoleBorrower, identity.Role)
+	})
+
+	t.Run("Failed AuthenticateToken - Deleted User", func(t *testing.T) {
+		mockUsecase, mockUserUsecase, _ := setupAuthMocks()
+		token, err := mockUsecase.issueToken(&entity.User{ID: 1, Name: "test"})
+		assert.NoError(t, err)
+		mockUserUsecase.On("GetUserByID", mock.Anything, int64(1)).Return(nil, repository.ErrUserNotFound)
+
+		_, err = mockUsecase.AuthenticateToken(context.Background(), token.AccessToken)
+
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("Failed AuthenticateToken - Expired", func(t *testing.T) {
+		mockUsecase, _, _ := setupAuthMocks()
+		token, err := mockUsecase.issueToken(&entity.User{ID: 1, Name: "test"})
+		assert.NoError(t, err)
+
+		now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+		defer func() { now = time.Now }()
+
+		_, err = mockUsecase.AuthenticateToken(context.Background(), token.AccessToken)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("Failed AuthenticateToken - Wrong Secret", func(t *testing.T) {
+		mockUsecase, _, _ := setupAuthMocks()
+		token, _ := mockUsecase.issueToken(&entity.User{ID: 1, Name: "test"})
+
+		otherUsecase := NewAuthUsecase(nil, nil, []byte("other secret"), time.Hour)
+		_, err := otherUsecase.AuthenticateToken(context.Background(), token.AccessToken)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+}
+
+func TestAPIKey(t *testing.T) {
+	t.Run("Success Create And Authenticate APIKey", func(t *testing.T) {
+		mockUsecase, _, mockAPIKeyRepo := setupAuthMocks()
+
+		var stored *entity.APIKey
+		mockAPIKeyRepo.On("CreateAPIKey", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			stored = args.Get(1).(*entity.APIKey)
+			stored.ID = 1
+		}).Return(nil)
+
+		issued, err := mockUsecase.CreateAPIKey(context.Background(), "partner")
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(issued.Key, "lm_"+stored.Prefix+"_"))
+		assert.NotContains(t, stored.KeyHash, issued.Key)
+
+		mockAPIKeyRepo.On("GetAPIKeyByPrefix", mock.Anything, stored.Prefix).Return(stored, nil)
+		mockAPIKeyRepo.On("TouchAPIKey", mock.Anything, int64(1), mock.Anything).Return(nil)
+
+		identity, err := mockUsecase.AuthenticateAPIKey(context.Background(), issued.Key)
+		assert.NoError(t, err)
+		assert.Equal(t, &entity.Identity{Type: entity.IdentityTypePartner, Role: entity.RolePartner, APIKeyID: 1, Name: "partner"}, identity)
+
+		_, err = mockUsecase.AuthenticateAPIKey(context.Background(), issued.Key+"x")
+		assert.ErrorIs(t, err, ErrInvalidAPIKey)
+	})
+
+	t.Run("Failed AuthenticateAPIKey - Revoked", func(t *testing.T) {
+		mockUsecase, _, mockAPIKeyRepo := setupAuthMocks()
+		key := "lm_abcd1234_secret"
+		mockAPIKeyRepo.On("GetAPIKeyByPrefix", mock.Anything, "abcd1234").Return(&entity.APIKey{ID: 1, KeyHash: hashAPIKey(key), Status: entity.APIKeyStatusRevoked}, nil)
+
+		_, err := mockUsecase.AuthenticateAPIKey(context.Background(), key)
+		assert.ErrorIs(t, err, ErrInvalidAPIKey)
+	})
+
+	t.Run("Success RotateAPIKey", func(t *testing.T) {
+		mockTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		now = func() time.Time { return mockTime }
+		defer func() { now = time.Now }()
+
+		mockUsecase, _, mockAPIKeyRepo := setupAuthMocks()
+		mockAPIKeyRepo.On("GetAPIKeyByID", mock.Anything, int64(1)).Return(&entity.APIKey{ID: 1, Name: "partner", Status: entity.APIKeyStatusActive}, nil)
+		mockAPIKeyRepo.On("CreateAPIKey", mock.Anything, mock.Anything).Return(nil)
+		mockAPIKeyRepo.On("UpdateAPIKeyExpiry", mock.Anything, int64(1), mockTime.Add(time.Hour)).Return(nil)
+
+		issued, err := mockUsecase.RotateAPIKey(context.Background(), 1, time.Hour)
+		assert.NoError(t, err)
+		assert.Equal(t, "partner", issued.APIKey.Name)
+		mockAPIKeyRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,30 @@
+package usecase
+
+import (
+	"context"
+	"loan-management/internal/apperror"
+	"loan-management/internal/entity"
+)
+
+var ErrForbidden = apperror.Forbidden("FORBIDDEN", "You don't have permission to perform this action")
+
+// authorize checks the caller has the permission, callers without identity (CLI, background jobs) are trusted
+func authorize(ctx context.Context, permission entity.Permission) error {
+	identity := entity.IdentityFromContext(ctx)
+	if identity == nil || identity.Can(permission) {
+		return nil
+	}
+	return ErrForbidden
+}
+
+// authorizeOwner allows callers with the `any` permission, or the `own` permission on a record owned by ownerID
+func authorizeOwner(ctx context.Context, any entity.Permission, own entity.Permission, ownerID int64) error {
+	identity := entity.IdentityFromContext(ctx)
+	if identity == nil || identity.Can(any) {
+		return nil
+	}
+	if identity.Can(own) && identity.IsOwner(ownerID) {
+		return nil
+	}
+	return ErrForbidden
+}
@@ -0,0 +1,466 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"loan-management/internal/apperror"
+	"loan-management/internal/entity"
+	"loan-management/internal/repository"
+	"loan-management/internal/validation"
+	"log"
+	"math"
+	"time"
+)
+
+const (
+	autodebitBatchSize = 100
+	// AutodebitChannel is the channel of the transactions posted from collected debits
+	AutodebitChannel = "autodebit"
+)
+
+var (
+	ErrDebitProviderNotFound = apperror.Validation("DEBIT_PROVIDER_NOT_FOUND", "Debit provider is not supported")
+	ErrMandateAlreadyActive  = apperror.Conflict("MANDATE_ALREADY_ACTIVE", "The loan already has an active autodebit mandate")
+	ErrMandateRevoked        = apperror.Conflict("MANDATE_REVOKED", "The autodebit mandate is revoked")
+	ErrMandateLimitExceeded  = apperror.Validation("MANDATE_LIMIT_EXCEEDED", "The amount due is above the mandate limit")
+	ErrMandateNotFound       = repository.ErrMandateNotFound
+)
+
+// DebitProvider charges the borrower accounts of one direct debit scheme
+type DebitProvider interface {
+	Name() string
+	// Debit returns an error when the provider can't be reached, a declined debit is a result
+	Debit(ctx context.Context, request entity.DebitRequest) (*entity.DebitResult, error)
+}
+
+// AutodebitRetryPolicy retries a declined or failed collection every Interval until MaxAttempts tries were made
+type AutodebitRetryPolicy struct {
+	MaxAttempts int
+	Interval    time.Duration
+}
+
+type AutodebitUsecaseInterface interface {
+	CreateMandate(ctx context.Context, payload *entity.CreateMandatePayload) (*entity.AutodebitMandate, error)
+	GetMandates(ctx context.Context, filter entity.MandateFilter) ([]*entity.AutodebitMandate, error)
+	GetMandateByID(ctx context.Context, id int64) (*entity.AutodebitMandate, error)
+	RevokeMandate(ctx context.Context, id int64) (*entity.AutodebitMandate, error)
+	GetAttempts(ctx context.Context, mandateID int64, filter entity.CollectionAttemptFilter) ([]*entity.CollectionAttempt, error)
+	Collect(ctx context.Context) (*entity.AutodebitRun, error)
+}
+
+// AutodebitUsecase collects the bills of loans with an active mandate. A collection attempt is scheduled when an
+// installment falls due, it is then tried against the mandate's debit provider and retried per the policy
+type AutodebitUsecase struct {
+	autodebitRepo      repository.AutodebitRepository
+	loanUsecase        LoanUsecaseInterface
+	transactionUsecase TransactionUsecaseInterface
+	auditUsecase       AuditUsecaseInterface
+	policy             AutodebitRetryPolicy
+	providers          map[string]DebitProvider
+}
+
+func NewAutodebitUsecase(autodebitRepo repository.AutodebitRepository, loanUsecase LoanUsecaseInterface, transactionUsecase TransactionUsecaseInterface, auditUsecase AuditUsecaseInterface, policy AutodebitRetryPolicy, providers ...DebitProvider) *AutodebitUsecase {
+	byName := make(map[string]DebitProvider, len(providers))
+	for _, provider := range providers {
+		byName[provider.Name()] = provider
+	}
+
+	return &AutodebitUsecase{
+		autodebitRepo:      autodebitRepo,
+		loanUsecase:        loanUsecase,
+		transactionUsecase: transactionUsecase,
+		auditUsecase:       auditUsecase,
+		policy:             policy,
+		providers:          byName,
+	}
+}
+
+func (u *AutodebitUsecase) CreateMandate(ctx context.Context, payload *entity.CreateMandatePayload) (mandate *entity.AutodebitMandate, err error) {
+	if err := validation.Struct(payload); err != nil {
+		return nil, err
+	}
+
+	if _, ok := u.providers[payload.Provider]; !ok {
+		return nil, ErrDebitProviderNotFound
+	}
+
+	loanStatusActive := entity.LoanStatusActive
+	loan, err := u.loanUsecase.GetLoanByID(ctx, payload.LoanID, &loanStatusActive)
+	if err != nil {
+		return nil, err
+	}
+
+	if loan == nil {
+		return nil, ErrLoanNotFound
+	}
+
+	if err := authorizeOwner(ctx, entity.PermAutodebitManage, entity.PermAutodebitManageOwn, loan.UserID); err != nil {
+		return nil, err
+	}
+
+	_, err = u.autodebitRepo.GetActiveMandateByLoanID(ctx, loan.ID)
+	if err == nil {
+		return nil, ErrMandateAlreadyActive
+	}
+	if !errors.Is(err, ErrMandateNotFound) {
+		return nil, err
+	}
+
+	mandate = &entity.AutodebitMandate{
+		LoanID:           loan.ID,
+		Provider:         payload.Provider,
+		AccountReference: payload.AccountReference,
+		MaxAmount:        payload.MaxAmount,
+		Status:           entity.MandateStatusActive,
+		CreatedAt:        now(),
+	}
+
+	tx, err := u.autodebitRepo.BeginTx()
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = u.autodebitRepo.CreateMandate(tx, mandate); err != nil {
+		return nil, err
+	}
+
+	if err = u.auditUsecase.Record(ctx, tx, entity.AuditActionMandateCreate, entity.AuditEntityAutodebitMandate, mandate.ID, nil, mandate); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return mandate, nil
+}
+
+func (u *AutodebitUsecase) GetMandates(ctx context.Context, filter entity.MandateFilter) ([]*entity.AutodebitMandate, error) {
+	if err := authorize(ctx, entity.PermAutodebitManage); err != nil {
+		return nil, err
+	}
+
+	if err := validation.Struct(filter); err != nil {
+		return nil, err
+	}
+
+	return u.autodebitRepo.GetMandates(ctx, filter)
+}
+
+func (u *AutodebitUsecase) GetMandateByID(ctx context.Context, id int64) (*entity.AutodebitMandate, error) {
+	mandate, err := u.autodebitRepo.GetMandateByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.authorizeMandate(ctx, mandate); err != nil {
+		return nil, err
+	}
+
+	return mandate, nil
+}
+
+// authorizeMandate lets borrowers manage the mandates of their own loans
+func (u *AutodebitUsecase) authorizeMandate(ctx context.Context, mandate *entity.AutodebitMandate) error {
+	if err := authorize(ctx, entity.PermAutodebitManage); err == nil {
+		return nil
+	}
+
+	loan, err := u.loanUsecase.GetLoanByID(ctx, mandate.LoanID, nil)
+	if err != nil {
+		return err
+	}
+
+	if loan == nil {
+		return ErrMandateNotFound
+	}
+
+	return authorizeOwner(ctx, entity.PermAutodebitManage, entity.PermAutodebitManageOwn, loan.UserID)
+}
+
+// RevokeMandate stops the collections of the mandate, attempts waiting for a retry are cancelled
+func (u *AutodebitUsecase) RevokeMandate(ctx context.Context, id int64) (mandate *entity.AutodebitMandate, err error) {
+	mandate, err = u.GetMandateByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if mandate.Status == entity.MandateStatusRevoked {
+		return nil, ErrMandateRevoked
+	}
+
+	before := *mandate
+	updatedAt := now()
+	mandate.Status = entity.MandateStatusRevoked
+	mandate.UpdatedAt = &updatedAt
+
+	tx, err := u.autodebitRepo.BeginTx()
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = u.autodebitRepo.UpdateMandate(tx, mandate); err != nil {
+		return nil, err
+	}
+
+	if err = u.autodebitRepo.CancelPendingAttempts(tx, mandate.ID, ErrMandateRevoked.Code, updatedAt); err != nil {
+		return nil, err
+	}
+
+	if err = u.auditUsecase.Record(ctx, tx, entity.AuditActionMandateRevoke, entity.AuditEntityAutodebitMandate, mandate.ID, &before, mandate); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return mandate, nil
+}
+
+func (u *AutodebitUsecase) GetAttempts(ctx context.Context, mandateID int64, filter entity.CollectionAttemptFilter) ([]*entity.CollectionAttempt, error) {
+	if _, err := u.GetMandateByID(ctx, mandateID); err != nil {
+		return nil, err
+	}
+
+	if err := validation.Struct(filter); err != nil {
+		return nil, err
+	}
+
+	return u.autodebitRepo.GetAttemptsByMandateID(ctx, mandateID, filter)
+}
+
+// Run schedules and collects due bills every interval until ctx is done
+func (u *AutodebitUsecase) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := u.Collect(ctx); err != nil {
+			log.Printf("Failed to collect autodebits: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Collect schedules an attempt for every installment that fell due on an active mandate, then tries the
+// attempts that are due
+func (u *AutodebitUsecase) Collect(ctx context.Context) (*entity.AutodebitRun, error) {
+	if err := authorize(ctx, entity.PermAutodebitManage); err != nil {
+		return nil, err
+	}
+
+	run := &entity.AutodebitRun{}
+
+	scheduled, err := u.schedule(ctx)
+	run.Scheduled = scheduled
+	if err != nil {
+		return run, err
+	}
+
+	attempts, err := u.autodebitRepo.GetPendingAttempts(ctx, now(), autodebitBatchSize)
+	if err != nil {
+		return run, err
+	}
+
+	for _, attempt := range attempts {
+		if err := u.attempt(ctx, attempt); err != nil {
+			return run, err
+		}
+
+		switch attempt.Status {
+		case entity.CollectionAttemptStatusSucceeded:
+			run.Collected++
+		case entity.CollectionAttemptStatusFailed:
+			run.Failed++
+		}
+	}
+
+	return run, nil
+}
+
+// schedule creates one attempt per mandate and installment, an installment is collected from its due date even
+// though bills can be paid a week ahead
+func (u *AutodebitUsecase) schedule(ctx context.Context) (int, error) {
+	mandates, err := u.autodebitRepo.GetMandates(ctx, entity.MandateFilter{Status: entity.MandateStatusActive})
+	if err != nil {
+		return 0, err
+	}
+
+	scheduled := 0
+	for _, mandate := range mandates {
+		inquiry, err := u.transactionUsecase.InquiryTransaction(ctx, mandate.LoanID, entity.PayAhead{})
+		if errors.Is(err, ErrBillingNotFound) || errors.Is(err, ErrLoanNotFound) {
+			continue
+		}
+		if err != nil {
+			return scheduled, err
+		}
+
+		bill := lastBillDue(inquiry.Bills, today())
+		if bill == nil {
+			continue
+		}
+
+		_, err = u.autodebitRepo.GetAttemptByPaymentID(ctx, mandate.ID, bill.ID)
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, repository.ErrCollectionAttemptNotFound) {
+			return scheduled, err
+		}
+
+		attempt := &entity.CollectionAttempt{
+			MandateID:     mandate.ID,
+			LoanID:        mandate.LoanID,
+			PaymentID:     bill.ID,
+			DueDate:       bill.DueDate,
+			Amount:        inquiry.AmountDue,
+			Status:        entity.CollectionAttemptStatusPending,
+			NextAttemptAt: now(),
+			CreatedAt:     now(),
+		}
+		if err := u.autodebitRepo.CreateAttempt(ctx, attempt); err != nil {
+			return scheduled, err
+		}
+		scheduled++
+	}
+
+	return scheduled, nil
+}
+
+// lastBillDue returns the latest bill whose due date has come, bills are in due date order
+func lastBillDue(bills []*entity.Payment, at entity.Date) *entity.Payment {
+	var last *entity.Payment
+	for _, bill := range bills {
+		if bill.DueDate.After(at) {
+			break
+		}
+		last = bill
+	}
+	return last
+}
+
+// attempt tries to collect the amount currently due and saves the outcome, only failing to save is returned as
+// an error
+func (u *AutodebitUsecase) attempt(ctx context.Context, attempt *entity.CollectionAttempt) error {
+	mandate, err := u.autodebitRepo.GetMandateByID(ctx, attempt.MandateID)
+	if err != nil {
+		return err
+	}
+
+	if mandate.Status != entity.MandateStatusActive {
+		u.finish(attempt, entity.CollectionAttemptStatusCancelled, ErrMandateRevoked.Code)
+		return u.autodebitRepo.UpdateAttempt(ctx, attempt)
+	}
+
+	inquiry, err := u.transactionUsecase.InquiryTransaction(ctx, attempt.LoanID, entity.PayAhead{})
+	if errors.Is(err, ErrBillingNotFound) || errors.Is(err, ErrLoanNotFound) {
+		u.finish(attempt, entity.CollectionAttemptStatusCancelled, "bill is no longer due")
+		return u.autodebitRepo.UpdateAttempt(ctx, attempt)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !includesBill(inquiry.Bills, attempt.PaymentID) {
+		u.finish(attempt, entity.CollectionAttemptStatusCancelled, "bill is no longer due")
+		return u.autodebitRepo.UpdateAttempt(ctx, attempt)
+	}
+
+	// bills that fell due since the attempt was scheduled are collected with it
+	attempt.Amount = inquiry.AmountDue
+	if attempt.Amount > mandate.MaxAmount+0.005 {
+		u.finish(attempt, entity.CollectionAttemptStatusFailed, ErrMandateLimitExceeded.Code+": "+ErrMandateLimitExceeded.Error())
+		return u.autodebitRepo.UpdateAttempt(ctx, attempt)
+	}
+
+	attempt.Attempts++
+	provider, ok := u.providers[mandate.Provider]
+	if !ok {
+		u.retry(attempt, ErrDebitProviderNotFound.Code+": "+mandate.Provider, true)
+		return u.autodebitRepo.UpdateAttempt(ctx, attempt)
+	}
+
+	result, err := provider.Debit(ctx, entity.DebitRequest{
+		Key:              fmt.Sprintf("autodebit-%d-%d", attempt.ID, attempt.Attempts),
+		AccountReference: mandate.AccountReference,
+		Amount:           math.Round(attempt.Amount*100) / 100,
+		Description:      inquiry.LoanDetail.Reference(),
+	})
+	switch {
+	case err != nil:
+		u.retry(attempt, err.Error(), true)
+	case !result.Approved:
+		u.retry(attempt, "declined: "+result.Reason, result.Retryable)
+	default:
+		u.post(ctx, attempt, inquiry, result.ProviderReference)
+	}
+
+	return u.autodebitRepo.UpdateAttempt(ctx, attempt)
+}
+
+// post books the collected debit, the money is already taken so a failure is left to finance
+func (u *AutodebitUsecase) post(ctx context.Context, attempt *entity.CollectionAttempt, inquiry *entity.TransactionInquiry, providerReference string) {
+	attempt.ProviderReference = providerReference
+
+	trx, err := u.transactionUsecase.CreateTransaction(ctx, &entity.CreateTransactionPayload{
+		LoanID:     inquiry.LoanID,
+		Amount:     inquiry.AmountDue,
+		Channel:    AutodebitChannel,
+		ExternalID: providerReference,
+	})
+	if err != nil {
+		log.Printf("Collected autodebit %s for loan %d but failed to post it: %v", providerReference, inquiry.LoanID, err)
+		u.finish(attempt, entity.CollectionAttemptStatusFailed, "debited but not posted: "+err.Error())
+		return
+	}
+
+	attempt.TransactionID = &trx.ID
+	u.finish(attempt, entity.CollectionAttemptStatusSucceeded, "")
+}
+
+// retry schedules the next try, or gives up when the failure is final or the policy ran out of attempts
+func (u *AutodebitUsecase) retry(attempt *entity.CollectionAttempt, reason string, retryable bool) {
+	if retryable && attempt.Attempts < u.policy.MaxAttempts {
+		attempt.LastError = reason
+		attempt.NextAttemptAt = now().Add(u.policy.Interval)
+		return
+	}
+
+	u.finish(attempt, entity.CollectionAttemptStatusFailed, reason)
+}
+
+func (u *AutodebitUsecase) finish(attempt *entity.CollectionAttempt, status entity.CollectionAttemptStatus, reason string) {
+	completedAt := now()
+	attempt.Status = status
+	attempt.LastError = reason
+	attempt.CompletedAt = &completedAt
+}
+
+func includesBill(bills []*entity.Payment, paymentID int64) bool {
+	for _, bill := range bills {
+		if bill.ID == paymentID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,324 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"loan-management/internal/entity"
+	internalMock "loan-management/internal/mock"
+	"loan-management/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// stubDebitProvider answers every debit with result, or err when set
+type stubDebitProvider struct {
+	result *entity.DebitResult
+	err    error
+}
+
+func (stubDebitProvider) Name() string { return "stub" }
+
+func (p stubDebitProvider) Debit(ctx context.Context, request entity.DebitRequest) (*entity.DebitResult, error) {
+	return p.result, p.err
+}
+
+var approvedDebit = stubDebitProvider{result: &entity.DebitResult{ProviderReference: "dbt_1", Approved: true}}
+
+func setupAutodebitMocks(provider stubDebitProvider) (*AutodebitUsecase, *internalMock.MockAutodebitRepository, *internalMock.MockLoanUsecase, *internalMock.MockTransactionUsecase, *internalMock.MockAuditUsecase) {
+	mockAutodebitRepo := new(internalMock.MockAutodebitRepository)
+	mockLoanUsecase := new(internalMock.MockLoanUsecase)
+	mockTransactionUsecase := new(internalMock.MockTransactionUsecase)
+	mockAudit := new(internalMock.MockAuditUsecase)
+
+	policy := AutodebitRetryPolicy{MaxAttempts: 3, Interval: 24 * time.Hour}
+	autodebitUsecase := NewAutodebitUsecase(mockAutodebitRepo, mockLoanUsecase, mockTransactionUsecase, mockAudit, policy, provider)
+
+	return autodebitUsecase, mockAutodebitRepo, mockLoanUsecase, mockTransactionUsecase, mockAudit
+}
+
+func TestCreateMandate(t *testing.T) {
+	payload := &entity.CreateMandatePayload{LoanID: 1, Provider: "stub", AccountReference: "ACC-1", MaxAmount: 2000}
+	borrower := entity.WithIdentity(context.Background(), &entity.Identity{Type: entity.IdentityTypeBorrower, Role: entity.RoleBorrower, UserID: 1})
+
+	t.Run("Success CreateMandate - Own Loan", func(t *testing.T) {
+		autodebitUsecase, mockAutodebitRepo, mockLoanUsecase, _, mockAudit := setupAutodebitMocks(approvedDebit)
+
+		mockLoanUsecase.On("GetLoanByID", mock.Anything, int64(1), mock.Anything).Return(&entity.Loan{ID: 1, UserID: 1, Status: entity.LoanStatusActive}, nil)
+		mockAutodebitRepo.On("GetActiveMandateByLoanID", mock.Anything, int64(1)).Return(nil, ErrMandateNotFound)
+		mockAutodebitRepo.On("BeginTx").Return(newMockTx(t, true), nil)
+		mockAutodebitRepo.On("CreateMandate", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			args.Get(1).(*entity.AutodebitMandate).ID = 4
+		}).Return(nil)
+		mockAudit.On("Record", mock.Anything, mock.Anything, entity.AuditActionMandateCreate, entity.AuditEntityAutodebitMandate, int64(4), nil, mock.Anything).Return(nil)
+
+		mandate, err := autodebitUsecase.CreateMandate(borrower, payload)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(4), mandate.ID)
+		assert.Equal(t, entity.MandateStatusActive, mandate.Status)
+		mockAudit.AssertExpectations(t)
+	})
+
+	t.Run("Failed CreateMandate - Another Borrower's Loan", func(t *testing.T) {
+		autodebitUsecase, mockAutodebitRepo, mockLoanUsecase, _, _ := setupAutodebitMocks(approvedDebit)
+
+		mockLoanUsecase.On("GetLoanByID", mock.Anything, int64(1), mock.Anything).Return(&entity.Loan{ID: 1, UserID: 2, Status: entity.LoanStatusActive}, nil)
+
+		mandate, err := autodebitUsecase.CreateMandate(borrower, payload)
+
+		assert.Nil(t, mandate)
+		assert.ErrorIs(t, err, ErrForbidden)
+		mockAutodebitRepo.AssertNotCalled(t, "BeginTx")
+	})
+
+	t.Run("Failed CreateMandate - Already Active", func(t *testing.T) {
+		autodebitUsecase, mockAutodebitRepo, mockLoanUsecase, _, _ := setupAutodebitMocks(approvedDebit)
+
+		mockLoanUsecase.On("GetLoanByID", mock.Anything, int64(1), mock.Anything).Return(&entity.Loan{ID: 1, UserID: 1, Status: entity.LoanStatusActive}, nil)
+		mockAutodebitRepo.On("GetActiveMandateByLoanID", mock.Anything, int64(1)).Return(&entity.AutodebitMandate{ID: 3}, nil)
+
+		mandate, err := autodebitUsecase.CreateMandate(context.Background(), payload)
+
+		assert.Nil(t, mandate)
+		assert.ErrorIs(t, err, ErrMandateAlreadyActive)
+	})
+
+	t.Run("Failed CreateMandate - Unknown Provider", func(t *testing.T) {
+		autodebitUsecase, _, mockLoanUsecase, _, _ := setupAutodebitMocks(approvedDebit)
+
+		mandate, err := autodebitUsecase.CreateMandate(context.Background(), &entity.CreateMandatePayload{LoanID: 1, Provider: "other", AccountReference: "ACC-1", MaxAmount: 2000})
+
+		assert.Nil(t, mandate)
+		assert.ErrorIs(t, err, ErrDebitProviderNotFound)
+		mockLoanUsecase.AssertNotCalled(t, "GetLoanByID", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failed CreateMandate - Loan Not Active", func(t *testing.T) {
+		autodebitUsecase, _, mockLoanUsecase, _, _ := setupAutodebitMocks(approvedDebit)
+
+		mockLoanUsecase.On("GetLoanByID", mock.Anything, int64(1), mock.Anything).Return(nil, nil)
+
+		mandate, err := autodebitUsecase.CreateMandate(context.Background(), payload)
+
+		assert.Nil(t, mandate)
+		assert.ErrorIs(t, err, ErrLoanNotFound)
+	})
+}
+
+func TestRevokeMandate(t *testing.T) {
+	t.Run("Success RevokeMandate", func(t *testing.T) {
+		autodebitUsecase, mockAutodebitRepo, _, _, mockAudit := setupAutodebitMocks(approvedDebit)
+
+		mockAutodebitRepo.On("GetMandateByID", mock.Anything, int64(4)).Return(&entity.AutodebitMandate{ID: 4, LoanID: 1, Status: entity.MandateStatusActive}, nil)
+		mockAutodebitRepo.On("BeginTx").Return(newMockTx(t, true), nil)
+		mockAutodebitRepo.On("UpdateMandate", mock.Anything, mock.Anything).Return(nil)
+		mockAutodebitRepo.On("CancelPendingAttempts", mock.Anything, int64(4), "MANDATE_REVOKED", mock.Anything).Return(nil)
+		mockAudit.On("Record", mock.Anything, mock.Anything, entity.AuditActionMandateRevoke, entity.AuditEntityAutodebitMandate, int64(4), mock.Anything, mock.Anything).Return(nil)
+
+		mandate, err := autodebitUsecase.RevokeMandate(context.Background(), 4)
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.MandateStatusRevoked, mandate.Status)
+		assert.NotNil(t, mandate.UpdatedAt)
+		mockAutodebitRepo.AssertExpectations(t)
+	})
+
+	t.Run("Failed RevokeMandate - Already Revoked", func(t *testing.T) {
+		autodebitUsecase, mockAutodebitRepo, _, _, _ := setupAutodebitMocks(approvedDebit)
+
+		mockAutodebitRepo.On("GetMandateByID", mock.Anything, int64(4)).Return(&entity.AutodebitMandate{ID: 4, LoanID: 1, Status: entity.MandateStatusRevoked}, nil)
+
+		mandate, err := autodebitUsecase.RevokeMandate(context.Background(), 4)
+
+		assert.Nil(t, mandate)
+		assert.ErrorIs(t, err, ErrMandateRevoked)
+	})
+}
+
+func TestCollect(t *testing.T) {
+	mockTime := time.Date(2026, 9, 10, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return mockTime }
+	defer func() { now = time.Now }()
+
+	mandate := &entity.AutodebitMandate{ID: 4, LoanID: 1, Provider: "stub", AccountReference: "ACC-1", MaxAmount: 2000, Status: entity.MandateStatusActive}
+	inquiry := &entity.TransactionInquiry{
+		LoanID:     1,
+		AmountDue:  1003.85,
+		LoanDetail: &entity.Loan{ID: 1},
+		Bills: []*entity.Payment{
+			{ID: 11, DueDate: entity.DateOf(mockTime, time.UTC).AddDays(-1)},
+			{ID: 12, DueDate: entity.DateOf(mockTime, time.UTC).AddDays(6)},
+		},
+	}
+	pending := func() *entity.CollectionAttempt {
+		return &entity.CollectionAttempt{ID: 7, MandateID: 4, LoanID: 1, PaymentID: 11, Amount: 1003.85, Status: entity.CollectionAttemptStatusPending}
+	}
+
+	t.Run("Success Collect - Schedules And Posts", func(t *testing.T) {
+		autodebitUsecase, mockAutodebitRepo, _, mockTransactionUsecase, _ := setupAutodebitMocks(approvedDebit)
+
+		mockAutodebitRepo.On("GetMandates", mock.Anything, entity.MandateFilter{Status: entity.MandateStatusActive}).Return([]*entity.AutodebitMandate{mandate}, nil)
+		mockTransactionUsecase.On("InquiryTransaction", mock.Anything, int64(1), entity.PayAhead{}).Return(inquiry, nil)
+		mockAutodebitRepo.On("GetAttemptByPaymentID", mock.Anything, int64(4), int64(11)).Return(nil, repository.ErrCollectionAttemptNotFound)
+		mockAutodebitRepo.On("CreateAttempt", mock.Anything, mock.MatchedBy(func(attempt *entity.CollectionAttempt) bool {
+			return attempt.PaymentID == 11 && attempt.Status == entity.CollectionAttemptStatusPending && attempt.NextAttemptAt.Equal(mockTime)
+		})).Return(nil)
+
+		attempt := pending()
+		mockAutodebitRepo.On("GetPendingAttempts", mock.Anything, mockTime, autodebitBatchSize).Return([]*entity.CollectionAttempt{attempt}, nil)
+		mockAutodebitRepo.On("GetMandateByID", mock.Anything, int64(4)).Return(mandate, nil)
+		mockTransactionUsecase.On("CreateTransaction", mock.Anything, &entity.CreateTransactionPayload{
+			LoanID:     1,
+			Amount:     1003.85,
+			Channel:    AutodebitChannel,
+			ExternalID: "dbt_1",
+		}).Return(&entity.Transaction{ID: 9}, nil)
+		mockAutodebitRepo.On("UpdateAttempt", mock.Anything, attempt).Return(nil)
+
+		run, err := autodebitUsecase.Collect(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, &entity.AutodebitRun{Scheduled: 1, Collected: 1}, run)
+		assert.Equal(t, entity.CollectionAttemptStatusSucceeded, attempt.Status)
+		assert.Equal(t, int64(9), *attempt.TransactionID)
+		assert.Equal(t, 1, attempt.Attempts)
+		assert.Equal(t, "dbt_1", attempt.ProviderReference)
+		mockAutodebitRepo.AssertExpectations(t)
+	})
+
+	t.Run("Success Collect - Already Scheduled", func(t *testing.T) {
+		autodebitUsecase, mockAutodebitRepo, _, mockTransactionUsecase, _ := setupAutodebitMocks(approvedDebit)
+
+		mockAutodebitRepo.On("GetMandates", mock.Anything, mock.Anything).Return([]*entity.AutodebitMandate{mandate}, nil)
+		mockTransactionUsecase.On("InquiryTransaction", mock.Anything, int64(1), entity.PayAhead{}).Return(inquiry, nil)
+		mockAutodebitRepo.On("GetAttemptByPaymentID", mock.Anything, int64(4), int64(11)).Return(&entity.CollectionAttempt{ID: 7}, nil)
+		mockAutodebitRepo.On("GetPendingAttempts", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+
+		run, err := autodebitUsecase.Collect(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, run.Scheduled)
+		mockAutodebitRepo.AssertNotCalled(t, "CreateAttempt", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Success Collect - Nothing Due Yet", func(t *testing.T) {
+		autodebitUsecase, mockAutodebitRepo, _, mockTransactionUsecase, _ := setupAutodebitMocks(approvedDebit)
+
+		upcoming := &entity.TransactionInquiry{LoanID: 1, AmountDue: 500, Bills: []*entity.Payment{{ID: 12, DueDate: entity.DateOf(mockTime, time.UTC).AddDays(6)}}}
+		mockAutodebitRepo.On("GetMandates", mock.Anything, mock.Anything).Return([]*entity.AutodebitMandate{mandate}, nil)
+		mockTransactionUsecase.On("InquiryTransaction", mock.Anything, int64(1), entity.PayAhead{}).Return(upcoming, nil)
+		mockAutodebitRepo.On("GetPendingAttempts", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+
+		run, err := autodebitUsecase.Collect(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, run.Scheduled)
+		mockAutodebitRepo.AssertNotCalled(t, "GetAttemptByPaymentID", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Success Collect - Retryable Decline", func(t *testing.T) {
+		autodebitUsecase, mockAutodebitRepo, _, mockTransactionUsecase, _ := setupAutodebitMocks(stubDebitProvider{result: &entity.DebitResult{Retryable: true, Reason: "insufficient funds"}})
+
+		attempt := pending()
+		mockAutodebitRepo.On("GetMandates", mock.Anything, mock.Anything).Return(nil, nil)
+		mockAutodebitRepo.On("GetPendingAttempts", mock.Anything, mock.Anything, mock.Anything).Return([]*entity.CollectionAttempt{attempt}, nil)
+		mockAutodebitRepo.On("GetMandateByID", mock.Anything, int64(4)).Return(mandate, nil)
+		mockTransactionUsecase.On("InquiryTransaction", mock.Anything, int64(1), entity.PayAhead{}).Return(inquiry, nil)
+		mockAutodebitRepo.On("UpdateAttempt", mock.Anything, attempt).Return(nil)
+
+		run, err := autodebitUsecase.Collect(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, run.Collected)
+		assert.Equal(t, entity.CollectionAttemptStatusPending, attempt.Status)
+		assert.Equal(t, "declined: insufficient funds", attempt.LastError)
+		assert.Equal(t, mockTime.Add(24*time.Hour), attempt.NextAttemptAt)
+		mockTransactionUsecase.AssertNotCalled(t, "CreateTransaction", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Success Collect - Gives Up After Max Attempts", func(t *testing.T) {
+		autodebitUsecase, mockAutodebitRepo, _, mockTransactionUsecase, _ := setupAutodebitMocks(stubDebitProvider{err: errors.New("connection refused")})
+
+		attempt := pending()
+		attempt.Attempts = 2
+		mockAutodebitRepo.On("GetMandates", mock.Anything, mock.Anything).Return(nil, nil)
+		mockAutodebitRepo.On("GetPendingAttempts", mock.Anything, mock.Anything, mock.Anything).Return([]*entity.CollectionAttempt{attempt}, nil)
+		mockAutodebitRepo.On("GetMandateByID", mock.Anything, int64(4)).Return(mandate, nil)
+		mockTransactionUsecase.On("InquiryTransaction", mock.Anything, int64(1), entity.PayAhead{}).Return(inquiry, nil)
+		mockAutodebitRepo.On("UpdateAttempt", mock.Anything, attempt).Return(nil)
+
+		run, err := autodebitUsecase.Collect(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, run.Failed)
+		assert.Equal(t, entity.CollectionAttemptStatusFailed, attempt.Status)
+		assert.Equal(t, 3, attempt.Attempts)
+		assert.NotNil(t, attempt.CompletedAt)
+	})
+
+	t.Run("Success Collect - Final Decline", func(t *testing.T) {
+		autodebitUsecase, mockAutodebitRepo, _, mockTransactionUsecase, _ := setupAutodebitMocks(stubDebitProvider{result: &entity.DebitResult{Reason: "account closed"}})
+
+		attempt := pending()
+		mockAutodebitRepo.On("GetMandates", mock.Anything, mock.Anything).Return(nil, nil)
+		mockAutodebitRepo.On("GetPendingAttempts", mock.Anything, mock.Anything, mock.Anything).Return([]*entity.CollectionAttempt{attempt}, nil)
+		mockAutodebitRepo.On("GetMandateByID", mock.Anything, int64(4)).Return(mandate, nil)
+		mockTransactionUsecase.On("InquiryTransaction", mock.Anything, int64(1), entity.PayAhead{}).Return(inquiry, nil)
+		mockAutodebitRepo.On("UpdateAttempt", mock.Anything, attempt).Return(nil)
+
+		_, err := autodebitUsecase.Collect(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.CollectionAttemptStatusFailed, attempt.Status)
+		assert.Equal(t, 1, attempt.Attempts)
+	})
+
+	t.Run("Success Collect - Above Mandate Limit", func(t *testing.T) {
+		autodebitUsecase, mockAutodebitRepo, _, mockTransactionUsecase, _ := setupAutodebitMocks(approvedDebit)
+
+		limited := *mandate
+		limited.MaxAmount = 1000
+		attempt := pending()
+		mockAutodebitRepo.On("GetMandates", mock.Anything, mock.Anything).Return(nil, nil)
+		mockAutodebitRepo.On("GetPendingAttempts", mock.Anything, mock.Anything, mock.Anything).Return([]*entity.CollectionAttempt{attempt}, nil)
+		mockAutodebitRepo.On("GetMandateByID", mock.Anything, int64(4)).Return(&limited, nil)
+		mockTransactionUsecase.On("InquiryTransaction", mock.Anything, int64(1), entity.PayAhead{}).Return(inquiry, nil)
+		mockAutodebitRepo.On("UpdateAttempt", mock.Anything, attempt).Return(nil)
+
+		_, err := autodebitUsecase.Collect(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.CollectionAttemptStatusFailed, attempt.Status)
+		assert.Contains(t, attempt.LastError, "MANDATE_LIMIT_EXCEEDED")
+		assert.Equal(t, 0, attempt.Attempts)
+	})
+
+	t.Run("Success Collect - Bill Paid Otherwise", func(t *testing.T) {
+		autodebitUsecase, mockAutodebitRepo, _, mockTransactionUsecase, _ := setupAutodebitMocks(approvedDebit)
+
+		attempt := pending()
+		mockAutodebitRepo.On("GetMandates", mock.Anything, mock.Anything).Return(nil, nil)
+		mockAutodebitRepo.On("GetPendingAttempts", mock.Anything, mock.Anything, mock.Anything).Return([]*entity.CollectionAttempt{attempt}, nil)
+		mockAutodebitRepo.On("GetMandateByID", mock.Anything, int64(4)).Return(mandate, nil)
+		mockTransactionUsecase.On("InquiryTransaction", mock.Anything, int64(1), entity.PayAhead{}).Return(nil, ErrBillingNotFound)
+		mockAutodebitRepo.On("UpdateAttempt", mock.Anything, attempt).Return(nil)
+
+		_, err := autodebitUsecase.Collect(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.CollectionAttemptStatusCancelled, attempt.Status)
+	})
+
+	t.Run("Failed Collect - Forbidden", func(t *testing.T) {
+		autodebitUsecase, _, _, _, _ := setupAutodebitMocks(approvedDebit)
+		ctx := entity.WithIdentity(context.Background(), &entity.Identity{Type: entity.IdentityTypeBorrower, Role: entity.RoleBorrower, UserID: 1})
+
+		run, err := autodebitUsecase.Collect(ctx)
+
+		assert.Nil(t, run)
+		assert.ErrorIs(t, err, ErrForbidden)
+	})
+}
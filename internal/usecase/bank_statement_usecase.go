@@ -0,0 +1,447 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"loan-management/internal/apperror"
+	"loan-management/internal/bankstatement"
+	"loan-management/internal/entity"
+	"loan-management/internal/repository"
+	"loan-management/internal/validation"
+	"math"
+	"regexp"
+	"strconv"
+)
+
+var (
+	ErrInvalidBankStatement           = apperror.Validation("INVALID_BANK_STATEMENT", "The bank statement can't be read")
+	ErrBankStatementAccountRequired   = apperror.Validation("BANK_STATEMENT_ACCOUNT_REQUIRED", "The account is required for this statement format")
+	ErrBankStatementLineNotReviewable = apperror.Conflict("BANK_STATEMENT_LINE_NOT_REVIEWABLE", "Only unmatched statement lines can be matched or rejected")
+	ErrBankStatementNotFound          = repository.ErrBankStatementNotFound
+	ErrBankStatementLineNotFound      = repository.ErrBankStatementLineNotFound
+)
+
+// BankStatementChannel is the channel of the transactions posted from statement lines
+const BankStatementChannel = "bank_statement"
+
+// bankReferenceCandidate finds what may be a loan reference or a virtual account in the free text of a line
+var bankReferenceCandidate = regexp.MustCompile(`(?i)LOAN-\d+|\d{10,}`)
+
+type BankStatementUsecaseInterface interface {
+	Import(ctx context.Context, payload entity.ImportBankStatementPayload, content []byte) (*entity.BankStatement, error)
+	GetStatements(ctx context.Context) ([]*entity.BankStatement, error)
+	GetStatementByID(ctx context.Context, id int64) (*entity.BankStatement, error)
+	GetLines(ctx context.Context, filter entity.BankStatementLineFilter) ([]*entity.BankStatementLine, error)
+	MatchLine(ctx context.Context, id int64, payload entity.MatchBankStatementLinePayload) (*entity.BankStatementLine, error)
+	RejectLine(ctx context.Context, id int64, payload entity.RejectBankStatementLinePayload) (*entity.BankStatementLine, error)
+}
+
+type BankStatementUsecase struct {
+	statementRepo      repository.BankStatementRepository
+	transactionUsecase TransactionUsecaseInterface
+	auditUsecase       AuditUsecaseInterface
+}
+
+func NewBankStatementUsecase(statementRepo repository.BankStatementRepository, transactionUsecase TransactionUsecaseInterface, auditUsecase AuditUsecaseInterface) *BankStatementUsecase {
+	return &BankStatementUsecase{
+		statementRepo:      statementRepo,
+		transactionUsecase: transactionUsecase,
+		auditUsecase:       auditUsecase,
+	}
+}
+
+// Import stores a statement and posts every credit that names a loan with its exact due amount. Lines that
+// can't be posted are left unmatched for review, mutations already imported with an earlier statement are
+// kept as duplicates and never posted twice
+func (u *BankStatementUsecase) Import(ctx context.Context, payload entity.ImportBankStatementPayload, content []byte) (*entity.BankStatement, error) {
+	if err := authorize(ctx, entity.PermBankStatementManage); err != nil {
+		return nil, err
+	}
+
+	if err := validation.Struct(payload); err != nil {
+		return nil, err
+	}
+
+	account, entries, err := bankstatement.Parse(payload.Format, content)
+	if err != nil {
+		return nil, ErrInvalidBankStatement.Wrap(err)
+	}
+	if payload.Account != "" {
+		account = payload.Account
+	}
+	if account == "" {
+		return nil, ErrBankStatementAccountRequired
+	}
+
+	statement := &entity.BankStatement{
+		Account:    account,
+		Format:     payload.Format,
+		FileName:   payload.FileName,
+		LineCount:  len(entries),
+		ImportedAt: now(),
+	}
+
+	lines, err := u.newLines(ctx, account, entries)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.save(ctx, statement, lines); err != nil {
+		return nil, err
+	}
+
+	for _, line := range lines {
+		if line.Status == entity.BankStatementLineStatusReceived {
+			u.post(ctx, line)
+		}
+	}
+
+	tx, err := u.statementRepo.BeginTx()
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	for _, line := range lines {
+		if err = u.statementRepo.UpdateLine(tx, line); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	statement.Lines = lines
+	return statement, nil
+}
+
+// newLines keys every entry by the bank reference, or a hash of the entry when there is none. Identical
+// entries of one file are told apart by their position
+func (u *BankStatementUsecase) newLines(ctx context.Context, account string, entries []entity.BankStatementEntry) ([]*entity.BankStatementLine, error) {
+	seen := map[string]int{}
+	lines := make([]*entity.BankStatementLine, len(entries))
+
+	for i, entry := range entries {
+		key := entry.Reference
+		if key == "" {
+			sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%.2f|%s", entry.BookedAt.Format("2006-01-02"), entry.Amount, entry.Description)))
+			key = "sha256:" + hex.EncodeToString(sum[:12])
+		}
+		seen[key]++
+		if seen[key] > 1 {
+			key += "#" + strconv.Itoa(seen[key])
+		}
+
+		line := &entity.BankStatementLine{
+			LineNo:      i + 1,
+			Account:     account,
+			ExternalID:  key,
+			BookedAt:    entry.BookedAt,
+			Amount:      entry.Amount,
+			Reference:   entry.Reference,
+			Description: entry.Description,
+			Status:      entity.BankStatementLineStatusReceived,
+		}
+
+		first, err := u.statementRepo.GetLineByExternalID(ctx, account, key)
+		if err != nil && !errors.Is(err, ErrBankStatementLineNotFound) {
+			return nil, err
+		}
+		if first != nil {
+			line.Status = entity.BankStatementLineStatusDuplicate
+			line.Note = "first imported as line " + strconv.FormatInt(first.ID, 10)
+		}
+
+		lines[i] = line
+	}
+
+	return lines, nil
+}
+
+func (u *BankStatementUsecase) save(ctx context.Context, statement *entity.BankStatement, lines []*entity.BankStatementLine) (err error) {
+	tx, err := u.statementRepo.BeginTx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = u.statementRepo.CreateStatement(tx, statement); err != nil {
+		return err
+	}
+
+	for _, line := range lines {
+		line.StatementID = statement.ID
+	}
+	if err = u.statementRepo.CreateLines(tx, lines); err != nil {
+		return err
+	}
+
+	if err = u.auditUsecase.Record(ctx, tx, entity.AuditActionBankStatementImport, entity.AuditEntityBankStatement, statement.ID, nil, statement); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// post matches a credit to the loan named in its reference or description, any failure leaves it unmatched
+func (u *BankStatementUsecase) post(ctx context.Context, line *entity.BankStatementLine) {
+	if line.Amount <= 0 {
+		line.Status = entity.BankStatementLineStatusIgnored
+		return
+	}
+
+	if posted, err := u.findPosted(ctx, line); posted || err != nil {
+		leaveUnmatched(line, err)
+		return
+	}
+
+	inquiry, err := u.inquire(ctx, line)
+	if err != nil {
+		leaveUnmatched(line, err)
+		return
+	}
+	line.LoanID = &inquiry.LoanID
+
+	leaveUnmatched(line, u.pay(ctx, line, inquiry))
+}
+
+// inquire tries every reference candidate of the line, the first one naming a loan wins
+func (u *BankStatementUsecase) inquire(ctx context.Context, line *entity.BankStatementLine) (*entity.TransactionInquiry, error) {
+	for _, candidate := range bankReferenceCandidate.FindAllString(line.Reference+" "+line.Description, -1) {
+		inquiry, err := inquireByReference(ctx, u.transactionUsecase, candidate)
+		switch {
+		case err == nil:
+			return inquiry, nil
+		case errors.Is(err, ErrUnknownLoanReference), errors.Is(err, ErrInvalidVirtualAccount), errors.Is(err, ErrVirtualAccountNotFound), errors.Is(err, ErrLoanNotFound):
+			continue
+		default:
+			return nil, err
+		}
+	}
+
+	return nil, ErrUnknownLoanReference
+}
+
+// pay posts the line as the payment of every due bill, a bank transfer has to match the due amount once rounded.
+// The cash posted is the line amount, so the ledger agrees with the statement
+func (u *BankStatementUsecase) pay(ctx context.Context, line *entity.BankStatementLine, inquiry *entity.TransactionInquiry) error {
+	if !sameAmount(math.Round(inquiry.AmountDue), line.Amount) {
+		return ErrAmountMismatch
+	}
+
+	trx, err := u.transactionUsecase.CreateTransaction(ctx, &entity.CreateTransactionPayload{
+		LoanID:     inquiry.LoanID,
+		Amount:     inquiry.AmountDue,
+		Channel:    BankStatementChannel,
+		ExternalID: transactionExternalID(line),
+		Received:   line.Amount,
+	})
+	if err != nil {
+		// the line was posted since it was looked up
+		if posted, findErr := u.findPosted(ctx, line); posted || findErr != nil {
+			return findErr
+		}
+		return err
+	}
+
+	line.Status = entity.BankStatementLineStatusPosted
+	line.TransactionID = &trx.ID
+	line.Note = ""
+	return nil
+}
+
+// findPosted marks the line as posted when its mutation already paid a loan
+func (u *BankStatementUsecase) findPosted(ctx context.Context, line *entity.BankStatementLine) (bool, error) {
+	trx, err := u.transactionUsecase.GetTransactionByExternalID(ctx, BankStatementChannel, transactionExternalID(line))
+	if errors.Is(err, ErrTransactionNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	line.Status = entity.BankStatementLineStatusPosted
+	line.TransactionID = &trx.ID
+	line.Note = ""
+	return true, nil
+}
+
+func transactionExternalID(line *entity.BankStatementLine) string {
+	return line.Account + ":" + line.ExternalID
+}
+
+// leaveUnmatched puts the line in the review queue with the reason it couldn't be posted
+func leaveUnmatched(line *entity.BankStatementLine, err error) {
+	if err == nil {
+		return
+	}
+
+	line.Status = entity.BankStatementLineStatusUnmatched
+	if appErr, ok := apperror.As(err); ok {
+		line.Note = appErr.Code + ": " + appErr.Error()
+	} else {
+		line.Note = err.Error()
+	}
+}
+
+// reviewable lines are unmatched, or were left received by an import that failed half way
+func reviewable(line *entity.BankStatementLine) bool {
+	return line.Status == entity.BankStatementLineStatusUnmatched || line.Status == entity.BankStatementLineStatusReceived
+}
+
+// MatchLine posts an unmatched credit to the loan picked by the reviewer
+func (u *BankStatementUsecase) MatchLine(ctx context.Context, id int64, payload entity.MatchBankStatementLinePayload) (*entity.BankStatementLine, error) {
+	if err := authorize(ctx, entity.PermBankStatementManage); err != nil {
+		return nil, err
+	}
+
+	if err := validation.Struct(payload); err != nil {
+		return nil, err
+	}
+
+	line, err := u.statementRepo.GetLineByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !reviewable(line) || line.Amount <= 0 {
+		return nil, ErrBankStatementLineNotReviewable
+	}
+
+	before := *line
+
+	posted, err := u.findPosted(ctx, line)
+	if err != nil {
+		return nil, err
+	}
+
+	if !posted {
+		inquiry, err := u.transactionUsecase.InquiryTransaction(ctx, payload.LoanID, entity.PayAhead{})
+		if err != nil {
+			return nil, err
+		}
+
+		line.LoanID = &inquiry.LoanID
+		if err := u.pay(ctx, line, inquiry); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := u.review(ctx, entity.AuditActionBankStatementMatch, &before, line); err != nil {
+		return nil, err
+	}
+
+	return line, nil
+}
+
+// RejectLine takes a line out of the review queue without posting it, e.g. a transfer that isn't a repayment
+func (u *BankStatementUsecase) RejectLine(ctx context.Context, id int64, payload entity.RejectBankStatementLinePayload) (*entity.BankStatementLine, error) {
+	if err := authorize(ctx, entity.PermBankStatementManage); err != nil {
+		return nil, err
+	}
+
+	if err := validation.Struct(payload); err != nil {
+		return nil, err
+	}
+
+	line, err := u.statementRepo.GetLineByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !reviewable(line) {
+		return nil, ErrBankStatementLineNotReviewable
+	}
+
+	before := *line
+	line.Status = entity.BankStatementLineStatusRejected
+	line.Note = payload.Reason
+
+	if err := u.review(ctx, entity.AuditActionBankStatementReject, &before, line); err != nil {
+		return nil, err
+	}
+
+	return line, nil
+}
+
+func (u *BankStatementUsecase) review(ctx context.Context, action entity.AuditAction, before *entity.BankStatementLine, line *entity.BankStatementLine) (err error) {
+	reviewedAt := now()
+	line.ReviewedAt = &reviewedAt
+
+	tx, err := u.statementRepo.BeginTx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = u.statementRepo.UpdateLine(tx, line); err != nil {
+		return err
+	}
+
+	if err = u.auditUsecase.Record(ctx, tx, action, entity.AuditEntityBankStatementLine, line.ID, before, line); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (u *BankStatementUsecase) GetStatements(ctx context.Context) ([]*entity.BankStatement, error) {
+	if err := authorize(ctx, entity.PermBankStatementManage); err != nil {
+		return nil, err
+	}
+
+	return u.statementRepo.GetStatements(ctx, 0)
+}
+
+// GetStatementByID returns the statement with all of its lines
+func (u *BankStatementUsecase) GetStatementByID(ctx context.Context, id int64) (*entity.BankStatement, error) {
+	if err := authorize(ctx, entity.PermBankStatementManage); err != nil {
+		return nil, err
+	}
+
+	statement, err := u.statementRepo.GetStatementByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if statement.LineCount > 0 {
+		statement.Lines, err = u.statementRepo.GetLines(ctx, entity.BankStatementLineFilter{StatementID: statement.ID, Limit: statement.LineCount})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return statement, nil
+}
+
+// GetLines lists statement lines, filtering on the unmatched status gives the review queue
+func (u *BankStatementUsecase) GetLines(ctx context.Context, filter entity.BankStatementLineFilter) ([]*entity.BankStatementLine, error) {
+	if err := authorize(ctx, entity.PermBankStatementManage); err != nil {
+		return nil, err
+	}
+
+	if err := validation.Struct(filter); err != nil {
+		return nil, err
+	}
+
+	return u.statementRepo.GetLines(ctx, filter)
+}
@@ -0,0 +1,244 @@
+package usecase
+
+import (
+	"context"
+	"loan-management/internal/entity"
+	internalMock "loan-management/internal/mock"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testStatementCSV = `date,description,reference,amount,type
+2026-09-01,TRF LOAN-1 BUDI,BNK001,"1,004",C
+2026-09-01,TRF 880800000000024,BNK002,500,C
+2026-09-01,ADMIN FEE,BNK003,5000,D
+2026-09-02,TRANSFER,,250,C
+`
+
+func setupBankStatementMocks() (*BankStatementUsecase, *internalMock.MockBankStatementRepository, *internalMock.MockTransactionUsecase, *internalMock.MockAuditUsecase) {
+	mockStatementRepo := new(internalMock.MockBankStatementRepository)
+	mockTransactionUsecase := new(internalMock.MockTransactionUsecase)
+	mockAudit := new(internalMock.MockAuditUsecase)
+
+	return NewBankStatementUsecase(mockStatementRepo, mockTransactionUsecase, mockAudit), mockStatementRepo, mockTransactionUsecase, mockAudit
+}
+
+func TestImportBankStatement(t *testing.T) {
+	payload := entity.ImportBankStatementPayload{Format: entity.BankStatementFormatCSV, Account: "1234567890", FileName: "mutation.csv"}
+
+	t.Run("Success Import - Posts Matched And Queues The Rest", func(t *testing.T) {
+		statementUsecase, mockStatementRepo, mockTransactionUsecase, mockAudit := setupBankStatementMocks()
+
+		mockStatementRepo.On("GetLineByExternalID", mock.Anything, "1234567890", mock.Anything).Return(nil, ErrBankStatementLineNotFound)
+		mockStatementRepo.On("BeginTx").Return(newMockTx(t, true), nil).Once()
+		mockStatementRepo.On("CreateStatement", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			args.Get(1).(*entity.BankStatement).ID = 3
+		}).Return(nil)
+		mockStatementRepo.On("CreateLines", mock.Anything, mock.Anything).Return(nil)
+		mockAudit.On("Record", mock.Anything, mock.Anything, entity.AuditActionBankStatementImport, entity.AuditEntityBankStatement, int64(3), nil, mock.Anything).Return(nil)
+
+		mockTransactionUsecase.On("GetTransactionByExternalID", mock.Anything, BankStatementChannel, mock.Anything).Return(nil, ErrTransactionNotFound)
+		mockTransactionUsecase.On("InquiryTransaction", mock.Anything, int64(1), entity.PayAhead{}).Return(&entity.TransactionInquiry{LoanID: 1, AmountDue: 1003.85}, nil)
+		mockTransactionUsecase.On("CreateTransaction", mock.Anything, &entity.CreateTransactionPayload{
+			LoanID:     1,
+			Amount:     1003.85,
+			Channel:    BankStatementChannel,
+			ExternalID: "1234567890:BNK001",
+			Received:   1004,
+		}).Return(&entity.Transaction{ID: 9}, nil)
+		mockTransactionUsecase.On("InquiryTransactionByVirtualAccount", mock.Anything, "880800000000024", entity.PayAhead{}).Return(&entity.TransactionInquiry{LoanID: 2, AmountDue: 750}, nil)
+
+		mockStatementRepo.On("BeginTx").Return(newMockTx(t, true), nil).Once()
+		mockStatementRepo.On("UpdateLine", mock.Anything, mock.Anything).Return(nil).Times(4)
+
+		statement, err := statementUsecase.Import(context.Background(), payload, []byte(testStatementCSV))
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), statement.ID)
+		assert.Equal(t, 4, statement.LineCount)
+		assert.Len(t, statement.Lines, 4)
+
+		posted := statement.Lines[0]
+		assert.Equal(t, entity.BankStatementLineStatusPosted, posted.Status)
+		assert.Equal(t, int64(9), *posted.TransactionID)
+		assert.Equal(t, int64(3), posted.StatementID)
+
+		mismatch := statement.Lines[1]
+		assert.Equal(t, entity.BankStatementLineStatusUnmatched, mismatch.Status)
+		assert.Equal(t, int64(2), *mismatch.LoanID)
+		assert.Contains(t, mismatch.Note, "AMOUNT_MISMATCH")
+
+		assert.Equal(t, entity.BankStatementLineStatusIgnored, statement.Lines[2].Status)
+
+		unknown := statement.Lines[3]
+		assert.Equal(t, entity.BankStatementLineStatusUnmatched, unknown.Status)
+		assert.Contains(t, unknown.Note, "UNKNOWN_LOAN_REFERENCE")
+		assert.Contains(t, unknown.ExternalID, "sha256:")
+
+		mockStatementRepo.AssertExpectations(t)
+		mockAudit.AssertExpectations(t)
+	})
+
+	t.Run("Success Import - Duplicate Line", func(t *testing.T) {
+		statementUsecase, mockStatementRepo, mockTransactionUsecase, mockAudit := setupBankStatementMocks()
+
+		content := []byte("date,reference,description,amount\n2026-09-01,BNK001,TRF LOAN-1,1004\n")
+		mockStatementRepo.On("GetLineByExternalID", mock.Anything, "1234567890", "BNK001").Return(&entity.BankStatementLine{ID: 5}, nil)
+		mockStatementRepo.On("BeginTx").Return(newMockTx(t, true), nil).Once()
+		mockStatementRepo.On("BeginTx").Return(newMockTx(t, true), nil).Once()
+		mockStatementRepo.On("CreateStatement", mock.Anything, mock.Anything).Return(nil)
+		mockStatementRepo.On("CreateLines", mock.Anything, mock.Anything).Return(nil)
+		mockStatementRepo.On("UpdateLine", mock.Anything, mock.Anything).Return(nil)
+		mockAudit.On("Record", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		statement, err := statementUsecase.Import(context.Background(), payload, content)
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.BankStatementLineStatusDuplicate, statement.Lines[0].Status)
+		mockTransactionUsecase.AssertNotCalled(t, "CreateTransaction", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Success Import - Already Posted", func(t *testing.T) {
+		statementUsecase, mockStatementRepo, mockTransactionUsecase, mockAudit := setupBankStatementMocks()
+
+		content := []byte("date,reference,description,amount\n2026-09-01,BNK001,TRF LOAN-1,1004\n")
+		mockStatementRepo.On("GetLineByExternalID", mock.Anything, mock.Anything, mock.Anything).Return(nil, ErrBankStatementLineNotFound)
+		mockStatementRepo.On("BeginTx").Return(newMockTx(t, true), nil).Once()
+		mockStatementRepo.On("BeginTx").Return(newMockTx(t, true), nil).Once()
+		mockStatementRepo.On("CreateStatement", mock.Anything, mock.Anything).Return(nil)
+		mockStatementRepo.On("CreateLines", mock.Anything, mock.Anything).Return(nil)
+		mockStatementRepo.On("UpdateLine", mock.Anything, mock.Anything).Return(nil)
+		mockAudit.On("Record", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		mockTransactionUsecase.On("GetTransactionByExternalID", mock.Anything, BankStatementChannel, "1234567890:BNK001").Return(&entity.Transaction{ID: 9}, nil)
+
+		statement, err := statementUsecase.Import(context.Background(), payload, content)
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.BankStatementLineStatusPosted, statement.Lines[0].Status)
+		assert.Equal(t, int64(9), *statement.Lines[0].TransactionID)
+		mockTransactionUsecase.AssertNotCalled(t, "InquiryTransaction", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failed Import - Invalid File", func(t *testing.T) {
+		statementUsecase, mockStatementRepo, _, _ := setupBankStatementMocks()
+
+		statement, err := statementUsecase.Import(context.Background(), payload, []byte("when,how much\n"))
+
+		assert.Nil(t, statement)
+		assert.ErrorIs(t, err, ErrInvalidBankStatement)
+		mockStatementRepo.AssertNotCalled(t, "BeginTx")
+	})
+
+	t.Run("Failed Import - Account Required", func(t *testing.T) {
+		statementUsecase, _, _, _ := setupBankStatementMocks()
+
+		statement, err := statementUsecase.Import(context.Background(), entity.ImportBankStatementPayload{Format: entity.BankStatementFormatCSV}, []byte(testStatementCSV))
+
+		assert.Nil(t, statement)
+		assert.ErrorIs(t, err, ErrBankStatementAccountRequired)
+	})
+
+	t.Run("Failed Import - Forbidden", func(t *testing.T) {
+		statementUsecase, _, _, _ := setupBankStatementMocks()
+		ctx := entity.WithIdentity(context.Background(), &entity.Identity{Type: entity.IdentityTypeBorrower, Role: entity.RoleBorrower, UserID: 1})
+
+		statement, err := statementUsecase.Import(ctx, payload, []byte(testStatementCSV))
+
+		assert.Nil(t, statement)
+		assert.ErrorIs(t, err, ErrForbidden)
+	})
+}
+
+func TestMatchBankStatementLine(t *testing.T) {
+	unmatchedLine := func() *entity.BankStatementLine {
+		return &entity.BankStatementLine{ID: 4, Account: "1234567890", ExternalID: "BNK002", Amount: 500, Status: entity.BankStatementLineStatusUnmatched}
+	}
+
+	t.Run("Success MatchLine", func(t *testing.T) {
+		statementUsecase, mockStatementRepo, mockTransactionUsecase, mockAudit := setupBankStatementMocks()
+
+		mockStatementRepo.On("GetLineByID", mock.Anything, int64(4)).Return(unmatchedLine(), nil)
+		mockTransactionUsecase.On("GetTransactionByExternalID", mock.Anything, BankStatementChannel, "1234567890:BNK002").Return(nil, ErrTransactionNotFound)
+		mockTransactionUsecase.On("InquiryTransaction", mock.Anything, int64(2), entity.PayAhead{}).Return(&entity.TransactionInquiry{LoanID: 2, AmountDue: 500}, nil)
+		mockTransactionUsecase.On("CreateTransaction", mock.Anything, mock.Anything).Return(&entity.Transaction{ID: 10}, nil)
+		mockStatementRepo.On("BeginTx").Return(newMockTx(t, true), nil)
+		mockStatementRepo.On("UpdateLine", mock.Anything, mock.Anything).Return(nil)
+		mockAudit.On("Record", mock.Anything, mock.Anything, entity.AuditActionBankStatementMatch, entity.AuditEntityBankStatementLine, int64(4), mock.Anything, mock.Anything).Return(nil)
+
+		line, err := statementUsecase.MatchLine(context.Background(), 4, entity.MatchBankStatementLinePayload{LoanID: 2})
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.BankStatementLineStatusPosted, line.Status)
+		assert.Equal(t, int64(10), *line.TransactionID)
+		assert.NotNil(t, line.ReviewedAt)
+		mockAudit.AssertExpectations(t)
+	})
+
+	t.Run("Failed MatchLine - Amount Mismatch", func(t *testing.T) {
+		statementUsecase, mockStatementRepo, mockTransactionUsecase, _ := setupBankStatementMocks()
+
+		mockStatementRepo.On("GetLineByID", mock.Anything, int64(4)).Return(unmatchedLine(), nil)
+		mockTransactionUsecase.On("GetTransactionByExternalID", mock.Anything, BankStatementChannel, mock.Anything).Return(nil, ErrTransactionNotFound)
+		mockTransactionUsecase.On("InquiryTransaction", mock.Anything, int64(2), entity.PayAhead{}).Return(&entity.TransactionInquiry{LoanID: 2, AmountDue: 750}, nil)
+
+		line, err := statementUsecase.MatchLine(context.Background(), 4, entity.MatchBankStatementLinePayload{LoanID: 2})
+
+		assert.Nil(t, line)
+		assert.ErrorIs(t, err, ErrAmountMismatch)
+		mockStatementRepo.AssertNotCalled(t, "UpdateLine", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failed MatchLine - Not Reviewable", func(t *testing.T) {
+		statementUsecase, mockStatementRepo, _, _ := setupBankStatementMocks()
+
+		posted := unmatchedLine()
+		posted.Status = entity.BankStatementLineStatusPosted
+		mockStatementRepo.On("GetLineByID", mock.Anything, int64(4)).Return(posted, nil)
+
+		line, err := statementUsecase.MatchLine(context.Background(), 4, entity.MatchBankStatementLinePayload{LoanID: 2})
+
+		assert.Nil(t, line)
+		assert.ErrorIs(t, err, ErrBankStatementLineNotReviewable)
+	})
+
+	t.Run("Failed MatchLine - Line Not Found", func(t *testing.T) {
+		statementUsecase, mockStatementRepo, _, _ := setupBankStatementMocks()
+
+		mockStatementRepo.On("GetLineByID", mock.Anything, int64(4)).Return(nil, ErrBankStatementLineNotFound)
+
+		line, err := statementUsecase.MatchLine(context.Background(), 4, entity.MatchBankStatementLinePayload{LoanID: 2})
+
+		assert.Nil(t, line)
+		assert.ErrorIs(t, err, ErrBankStatementLineNotFound)
+	})
+}
+
+func TestRejectBankStatementLine(t *testing.T) {
+	t.Run("Success RejectLine", func(t *testing.T) {
+		statementUsecase, mockStatementRepo, _, mockAudit := setupBankStatementMocks()
+
+		mockStatementRepo.On("GetLineByID", mock.Anything, int64(4)).Return(&entity.BankStatementLine{ID: 4, Amount: 250, Status: entity.BankStatementLineStatusUnmatched}, nil)
+		mockStatementRepo.On("BeginTx").Return(newMockTx(t, true), nil)
+		mockStatementRepo.On("UpdateLine", mock.Anything, mock.Anything).Return(nil)
+		mockAudit.On("Record", mock.Anything, mock.Anything, entity.AuditActionBankStatementReject, entity.AuditEntityBankStatementLine, int64(4), mock.Anything, mock.Anything).Return(nil)
+
+		line, err := statementUsecase.RejectLine(context.Background(), 4, entity.RejectBankStatementLinePayload{Reason: "refund to sender"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.BankStatementLineStatusRejected, line.Status)
+		assert.Equal(t, "refund to sender", line.Note)
+		assert.NotNil(t, line.ReviewedAt)
+	})
+
+	t.Run("Failed RejectLine - Reason Required", func(t *testing.T) {
+		statementUsecase, mockStatementRepo, _, _ := setupBankStatementMocks()
+
+		line, err := statementUsecase.RejectLine(context.Background(), 4, entity.RejectBankStatementLinePayload{})
+
+		assert.Nil(t, line)
+		assert.Error(t, err)
+		mockStatementRepo.AssertNotCalled(t, "GetLineByID", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,147 @@
+package usecase
+
+import (
+	"context"
+	"loan-management/internal/entity"
+	"loan-management/internal/repository"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	eventBatchSize       = 100
+	eventMaxAttempts     = 10
+	eventMaxRetryBackoff = time.Hour
+)
+
+// EventSink receives dispatched events. Events are delivered at least once, so sinks must tolerate duplicates
+// and can dedupe on the event ID
+type EventSink interface {
+	Name() string
+	Deliver(ctx context.Context, event *entity.Event) error
+}
+
+// EventDispatcher polls the outbox and hands pending events to every sink,
+// an event is retried with a growing backoff until all sinks accept it
+type EventDispatcher struct {
+	outboxRepo repository.OutboxRepository
+	sinks      []EventSink
+}
+
+func NewEventDispatcher(outboxRepo repository.OutboxRepository, sinks ...EventSink) *EventDispatcher {
+	return &EventDispatcher{
+		outboxRepo: outboxRepo,
+		sinks:      sinks,
+	}
+}
+
+// Run dispatches every interval until ctx is done
+func (d *EventDispatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.DispatchPending(ctx); err != nil {
+			log.Printf("Failed to dispatch events: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchPending delivers one batch of due events and returns how many were delivered
+func (d *EventDispatcher) DispatchPending(ctx context.Context) (int, error) {
+	events, err := d.outboxRepo.GetPendingEvents(ctx, now(), eventBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, event := range events {
+		if err := d.deliver(ctx, event); err != nil {
+			return delivered, err
+		}
+		if event.Status == entity.EventStatusDelivered {
+			delivered++
+		}
+	}
+
+	return delivered, nil
+}
+
+func (d *EventDispatcher) deliver(ctx context.Context, event *entity.Event) error {
+	var failures []string
+	for _, sink := range d.sinks {
+		if err := sink.Deliver(ctx, event); err != nil {
+			failures = append(failures, sink.Name()+": "+err.Error())
+		}
+	}
+
+	event.Attempts++
+	if len(failures) == 0 {
+		deliveredAt := now()
+		event.Status = entity.EventStatusDelivered
+		event.DeliveredAt = &deliveredAt
+		event.LastError = ""
+	} else {
+		event.LastError = strings.Join(failures, "; ")
+		event.NextAttemptAt = now().Add(retryBackoff(event.Attempts))
+		if event.Attempts >= eventMaxAttempts {
+			event.Status = entity.EventStatusFailed
+			log.Printf("Giving up on event %d (%s): %s", event.ID, event.Type, event.LastError)
+		}
+	}
+
+	return d.outboxRepo.UpdateEventDelivery(ctx, event)
+}
+
+// retryBackoff doubles from 2s per attempt, capped at eventMaxRetryBackoff
+func retryBackoff(attempts int) time.Duration {
+	backoff := 2 * time.Second
+	for i := 1; i < attempts && backoff < eventMaxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, eventMaxRetryBackoff)
+}
+
+// EventSubscriber handles an event in process, returning an error retries the delivery
+type EventSubscriber func(ctx context.Context, event *entity.Event) error
+
+// SubscriberSink fans events out to the in-process subscribers of their type
+type SubscriberSink struct {
+	mu          sync.RWMutex
+	subscribers map[entity.EventType][]EventSubscriber
+}
+
+func NewSubscriberSink() *SubscriberSink {
+	return &SubscriberSink{subscribers: map[entity.EventType][]EventSubscriber{}}
+}
+
+func (s *SubscriberSink) Subscribe(eventType entity.EventType, subscriber EventSubscriber) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscribers[eventType] = append(s.subscribers[eventType], subscriber)
+}
+
+func (s *SubscriberSink) Name() string {
+	return "subscribers"
+}
+
+func (s *SubscriberSink) Deliver(ctx context.Context, event *entity.Event) error {
+	s.mu.RLock()
+	subscribers := s.subscribers[event.Type]
+	s.mu.RUnlock()
+
+	for _, subscriber := range subscribers {
+		if err := subscriber(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"loan-management/internal/entity"
+	"loan-management/internal/repository"
+)
+
+type EventUsecaseInterface interface {
+	Publish(ctx context.Context, tx *sql.Tx, eventType entity.EventType, aggregateType string, aggregateID int64, payload any) error
+}
+
+type EventUsecase struct {
+	outboxRepo repository.OutboxRepository
+}
+
+func NewEventUsecase(outboxRepo repository.OutboxRepository) *EventUsecase {
+	return &EventUsecase{
+		outboxRepo: outboxRepo,
+	}
+}
+
+// Publish stores the event in the outbox inside tx, so it is only dispatched when the change itself commits
+func (u *EventUsecase) Publish(ctx context.Context, tx *sql.Tx, eventType entity.EventType, aggregateType string, aggregateID int64, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	occurredAt := now()
+	return u.outboxRepo.CreateEvent(tx, &entity.Event{
+		Type:          eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Payload:       data,
+		Status:        entity.EventStatusPending,
+		NextAttemptAt: occurredAt,
+		OccurredAt:    occurredAt,
+	})
+}
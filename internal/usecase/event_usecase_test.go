@@ -0,0 +1,129 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"loan-management/internal/entity"
+	internalMock "loan-management/internal/mock"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type stubSink struct {
+	name      string
+	err       error
+	delivered []*entity.Event
+}
+
+func (s *stubSink) Name() string {
+	return s.name
+}
+
+func (s *stubSink) Deliver(ctx context.Context, event *entity.Event) error {
+	s.delivered = append(s.delivered, event)
+	return s.err
+}
+
+func TestPublish(t *testing.T) {
+	t.Run("Success Publish", func(t *testing.T) {
+		mockRepo := new(internalMock.MockOutboxRepository)
+		eventUsecase := NewEventUsecase(mockRepo)
+		mockTx := newMockTx(t, true)
+
+		mockTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		now = func() time.Time { return mockTime }
+		defer func() { now = time.Now }()
+
+		mockRepo.On("CreateEvent", mockTx, &entity.Event{
+			Type:          entity.EventLoanPaidOff,
+			AggregateType: entity.EventAggregateLoan,
+			AggregateID:   1,
+			Payload:       json.RawMessage(`{"loan_id":1,"user_id":2,"transaction_id":3,"paid_at":"2025-01-01T00:00:00Z"}`),
+			Status:        entity.EventStatusPending,
+			NextAttemptAt: mockTime,
+			OccurredAt:    mockTime,
+		}).Return(nil)
+
+		err := eventUsecase.Publish(context.Background(), mockTx, entity.EventLoanPaidOff, entity.EventAggregateLoan, 1, entity.LoanPaidOffPayload{
+			LoanID:        1,
+			UserID:        2,
+			TransactionID: 3,
+			PaidAt:        mockTime,
+		})
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestDispatchPending(t *testing.T) {
+	mockTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Success DispatchPending - Delivered To Every Sink", func(t *testing.T) {
+		mockRepo := new(internalMock.MockOutboxRepository)
+		now = func() time.Time { return mockTime }
+		defer func() { now = time.Now }()
+
+		subscribers := NewSubscriberSink()
+		var received []entity.EventType
+		subscribers.Subscribe(entity.EventLoanCreated, func(ctx context.Context, event *entity.Event) error {
+			received = append(received, event.Type)
+			return nil
+		})
+		sink := &stubSink{name: "stub"}
+		dispatcher := NewEventDispatcher(mockRepo, subscribers, sink)
+
+		events := []*entity.Event{
+			{ID: 1, Type: entity.EventLoanCreated, Status: entity.EventStatusPending},
+			{ID: 2, Type: entity.EventPaymentPosted, Status: entity.EventStatusPending},
+		}
+		mockRepo.On("GetPendingEvents", mock.Anything, mockTime, eventBatchSize).Return(events, nil)
+		mockRepo.On("UpdateEventDelivery", mock.Anything, mock.Anything).Return(nil)
+
+		delivered, err := dispatcher.DispatchPending(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, delivered)
+		assert.Equal(t, []entity.EventType{entity.EventLoanCreated}, received)
+		assert.Len(t, sink.delivered, 2)
+		for _, event := range events {
+			assert.Equal(t, entity.EventStatusDelivered, event.Status)
+			assert.Equal(t, 1, event.Attempts)
+			assert.Equal(t, &mockTime, event.DeliveredAt)
+		}
+	})
+
+	t.Run("Success DispatchPending - Failed Sink Retries With Backoff", func(t *testing.T) {
+		mockRepo := new(internalMock.MockOutboxRepository)
+		now = func() time.Time { return mockTime }
+		defer func() { now = time.Now }()
+
+		dispatcher := NewEventDispatcher(mockRepo, &stubSink{name: "ok"}, &stubSink{name: "webhook", err: errors.New("timeout")})
+
+		retried := &entity.Event{ID: 1, Type: entity.EventLoanCreated, Status: entity.EventStatusPending, Attempts: 2}
+		exhausted := &entity.Event{ID: 2, Type: entity.EventLoanCreated, Status: entity.EventStatusPending, Attempts: eventMaxAttempts - 1}
+		mockRepo.On("GetPendingEvents", mock.Anything, mockTime, eventBatchSize).Return([]*entity.Event{retried, exhausted}, nil)
+		mockRepo.On("UpdateEventDelivery", mock.Anything, mock.Anything).Return(nil)
+
+		delivered, err := dispatcher.DispatchPending(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, delivered)
+		assert.Equal(t, entity.EventStatusPending, retried.Status)
+		assert.Equal(t, 3, retried.Attempts)
+		assert.Equal(t, "webhook: timeout", retried.LastError)
+		assert.Equal(t, mockTime.Add(8*time.Second), retried.NextAttemptAt)
+		assert.Equal(t, entity.EventStatusFailed, exhausted.Status)
+		mockRepo.AssertNumberOfCalls(t, "UpdateEventDelivery", 2)
+	})
+}
+
+func TestRetryBackoff(t *testing.T) {
+	assert.Equal(t, 2*time.Second, retryBackoff(1))
+	assert.Equal(t, 4*time.Second, retryBackoff(2))
+	assert.Equal(t, eventMaxRetryBackoff, retryBackoff(20))
+}
@@ -0,0 +1,187 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"loan-management/internal/apperror"
+	"loan-management/internal/calendar"
+	"loan-management/internal/entity"
+	"loan-management/internal/repository"
+	"loan-management/internal/validation"
+)
+
+var (
+	ErrHolidayExists   = apperror.Conflict("HOLIDAY_EXISTS", "The date is already a holiday")
+	ErrHolidayNotFound = repository.ErrHolidayNotFound
+)
+
+type HolidayUsecaseInterface interface {
+	GetHolidays(ctx context.Context) ([]*entity.Holiday, error)
+	CreateHoliday(ctx context.Context, payload *entity.HolidayPayload) (*entity.Holiday, error)
+	DeleteHoliday(ctx context.Context, id int64) error
+	ImportHolidays(ctx context.Context, payloads []entity.HolidayPayload) (int, error)
+	Calendar(ctx context.Context) (*calendar.Calendar, error)
+}
+
+// HolidayUsecase keeps the holiday calendar, due dates falling on weekends or holidays are moved with convention
+type HolidayUsecase struct {
+	holidayRepo  repository.HolidayRepository
+	paymentRepo  repository.PaymentRepository
+	auditUsecase AuditUsecaseInterface
+	convention   entity.BusinessDayConvention
+}
+
+func NewHolidayUsecase(holidayRepo repository.HolidayRepository, paymentRepo repository.PaymentRepository, auditUsecase AuditUsecaseInterface, convention entity.BusinessDayConvention) *HolidayUsecase {
+	return &HolidayUsecase{
+		holidayRepo:  holidayRepo,
+		paymentRepo:  paymentRepo,
+		auditUsecase: auditUsecase,
+		convention:   convention,
+	}
+}
+
+func (u *HolidayUsecase) GetHolidays(ctx context.Context) ([]*entity.Holiday, error) {
+	if err := authorize(ctx, entity.PermHolidayManage); err != nil {
+		return nil, err
+	}
+
+	return u.holidayRepo.GetAllHolidays(ctx)
+}
+
+// CreateHoliday adds a date to the calendar. The unpaid installments already due on it move to the business day
+// given by the convention, so they aren't overdue before then
+func (u *HolidayUsecase) CreateHoliday(ctx context.Context, payload *entity.HolidayPayload) (*entity.Holiday, error) {
+	if err := authorize(ctx, entity.PermHolidayManage); err != nil {
+		return nil, err
+	}
+
+	if err := validation.Struct(payload); err != nil {
+		return nil, err
+	}
+
+	date, err := entity.ParseDate(payload.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	holidays, err := u.holidayRepo.GetAllHolidays(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, holiday := range holidays {
+		if holiday.Date.Equal(date) {
+			return nil, ErrHolidayExists
+		}
+	}
+
+	holiday := &entity.Holiday{Date: date, Name: payload.Name, CreatedAt: now()}
+
+	tx, err := u.holidayRepo.BeginTx()
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = u.holidayRepo.CreateHoliday(tx, holiday); err != nil {
+		return nil, err
+	}
+
+	if adjusted := calendar.New(u.convention, append(holidays, holiday)).Adjust(date); !adjusted.Equal(date) {
+		if _, err = u.paymentRepo.RescheduleUnpaidPayments(tx, date, adjusted); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = u.auditUsecase.Record(ctx, tx, entity.AuditActionHolidayCreate, entity.AuditEntityHoliday, holiday.ID, nil, holiday); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return holiday, nil
+}
+
+// DeleteHoliday removes a date from the calendar. The unpaid installments moved off it move back, or to the
+// business day given by the convention when it's a weekend or still a holiday by another name
+func (u *HolidayUsecase) DeleteHoliday(ctx context.Context, id int64) error {
+	if err := authorize(ctx, entity.PermHolidayManage); err != nil {
+		return err
+	}
+
+	holiday, err := u.holidayRepo.GetHolidayByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	holidays, err := u.holidayRepo.GetAllHolidays(ctx)
+	if err != nil {
+		return err
+	}
+	others := make([]*entity.Holiday, 0, len(holidays))
+	for _, h := range holidays {
+		if h.ID != id {
+			others = append(others, h)
+		}
+	}
+
+	tx, err := u.holidayRepo.BeginTx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = u.holidayRepo.DeleteHoliday(tx, id); err != nil {
+		return err
+	}
+
+	adjusted := calendar.New(u.convention, others).Adjust(holiday.Date)
+	if _, err = u.paymentRepo.RestoreRescheduledPayments(tx, holiday.Date, adjusted); err != nil {
+		return err
+	}
+
+	if err = u.auditUsecase.Record(ctx, tx, entity.AuditActionHolidayDelete, entity.AuditEntityHoliday, id, holiday, nil); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ImportHolidays adds the holidays of a calendar file, skipping the dates already in the calendar, and returns
+// how many were added
+func (u *HolidayUsecase) ImportHolidays(ctx context.Context, payloads []entity.HolidayPayload) (int, error) {
+	imported := 0
+	for i := range payloads {
+		_, err := u.CreateHoliday(ctx, &payloads[i])
+		if errors.Is(err, ErrHolidayExists) {
+			continue
+		}
+		if err != nil {
+			return imported, err
+		}
+		imported++
+	}
+
+	return imported, nil
+}
+
+// Calendar is the current holiday calendar with the configured convention
+func (u *HolidayUsecase) Calendar(ctx context.Context) (*calendar.Calendar, error) {
+	holidays, err := u.holidayRepo.GetAllHolidays(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return calendar.New(u.convention, holidays), nil
+}
@@ -0,0 +1,168 @@
+package usecase
+
+import (
+	"context"
+	"loan-management/internal/entity"
+	internalMock "loan-management/internal/mock"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func setupHolidayMocks(convention entity.BusinessDayConvention) (*HolidayUsecase, *internalMock.MockHolidayRepository, *internalMock.MockPaymentRepository, *internalMock.MockAuditUsecase) {
+	mockHolidayRepo := new(internalMock.MockHolidayRepository)
+	mockPaymentRepo := new(internalMock.MockPaymentRepository)
+	mockAuditUsecase := new(internalMock.MockAuditUsecase)
+
+	holidayUsecase := NewHolidayUsecase(mockHolidayRepo, mockPaymentRepo, mockAuditUsecase, convention)
+
+	return holidayUsecase, mockHolidayRepo, mockPaymentRepo, mockAuditUsecase
+}
+
+func TestCreateHoliday(t *testing.T) {
+	// 2026-12-25 is a Friday
+	christmas := entity.NewDate(2026, 12, 25)
+	newYear := &entity.Holiday{ID: 1, Date: entity.NewDate(2027, 1, 1), Name: "New Year's Day"}
+
+	t.Run("Success CreateHoliday - Reschedules Unpaid Installments", func(t *testing.T) {
+		holidayUsecase, mockHolidayRepo, mockPaymentRepo, mockAuditUsecase := setupHolidayMocks(entity.BusinessDayFollowing)
+		mockTx := newMockTx(t, true)
+
+		mockHolidayRepo.On("GetAllHolidays", mock.Anything).Return([]*entity.Holiday{newYear}, nil)
+		mockHolidayRepo.On("BeginTx").Return(mockTx, nil)
+		mockHolidayRepo.On("CreateHoliday", mockTx, mock.Anything).Run(func(args mock.Arguments) {
+			args.Get(1).(*entity.Holiday).ID = 2
+		}).Return(nil)
+		mockPaymentRepo.On("RescheduleUnpaidPayments", mockTx, christmas, christmas.AddDays(3)).Return(int64(4), nil)
+		mockAuditUsecase.On("Record", mock.Anything, mockTx, entity.AuditActionHolidayCreate, entity.AuditEntityHoliday, int64(2), nil, mock.Anything).Return(nil)
+
+		holiday, err := holidayUsecase.CreateHoliday(context.Background(), &entity.HolidayPayload{Date: "2026-12-25", Name: "Christmas Day"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, christmas, holiday.Date)
+		assert.Equal(t, "Christmas Day", holiday.Name)
+		mockPaymentRepo.AssertExpectations(t)
+		mockAuditUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Success CreateHoliday - Preceding", func(t *testing.T) {
+		holidayUsecase, mockHolidayRepo, mockPaymentRepo, mockAuditUsecase := setupHolidayMocks(entity.BusinessDayPreceding)
+		mockTx := newMockTx(t, true)
+
+		mockHolidayRepo.On("GetAllHolidays", mock.Anything).Return(nil, nil)
+		mockHolidayRepo.On("BeginTx").Return(mockTx, nil)
+		mockHolidayRepo.On("CreateHoliday", mockTx, mock.Anything).Return(nil)
+		mockPaymentRepo.On("RescheduleUnpaidPayments", mockTx, christmas, christmas.AddDays(-1)).Return(int64(0), nil)
+		mockAuditUsecase.On("Record", mock.Anything, mockTx, entity.AuditActionHolidayCreate, entity.AuditEntityHoliday, mock.Anything, nil, mock.Anything).Return(nil)
+
+		_, err := holidayUsecase.CreateHoliday(context.Background(), &entity.HolidayPayload{Date: "2026-12-25", Name: "Christmas Day"})
+
+		assert.NoError(t, err)
+		mockPaymentRepo.AssertExpectations(t)
+	})
+
+	t.Run("Failed CreateHoliday - Already A Holiday", func(t *testing.T) {
+		holidayUsecase, mockHolidayRepo, _, _ := setupHolidayMocks(entity.BusinessDayFollowing)
+
+		mockHolidayRepo.On("GetAllHolidays", mock.Anything).Return([]*entity.Holiday{newYear}, nil)
+
+		_, err := holidayUsecase.CreateHoliday(context.Background(), &entity.HolidayPayload{Date: "2027-01-01", Name: "New Year"})
+
+		assert.ErrorIs(t, err, ErrHolidayExists)
+		mockHolidayRepo.AssertNotCalled(t, "BeginTx")
+	})
+
+	t.Run("Failed CreateHoliday - Invalid Date", func(t *testing.T) {
+		holidayUsecase, mockHolidayRepo, _, _ := setupHolidayMocks(entity.BusinessDayFollowing)
+
+		_, err := holidayUsecase.CreateHoliday(context.Background(), &entity.HolidayPayload{Date: "25/12/2026", Name: "Christmas Day"})
+
+		assert.Error(t, err)
+		mockHolidayRepo.AssertNotCalled(t, "GetAllHolidays", mock.Anything)
+	})
+
+	t.Run("Failed CreateHoliday - Forbidden", func(t *testing.T) {
+		holidayUsecase, _, _, _ := setupHolidayMocks(entity.BusinessDayFollowing)
+		borrower := entity.WithIdentity(context.Background(), &entity.Identity{Type: entity.IdentityTypeBorrower, Role: entity.RoleBorrower, UserID: 1})
+
+		_, err := holidayUsecase.CreateHoliday(borrower, &entity.HolidayPayload{Date: "2026-12-25", Name: "Christmas Day"})
+
+		assert.ErrorIs(t, err, ErrForbidden)
+	})
+}
+
+func TestImportHolidays(t *testing.T) {
+	holidayUsecase, mockHolidayRepo, mockPaymentRepo, mockAuditUsecase := setupHolidayMocks(entity.BusinessDayFollowing)
+	mockTx := newMockTx(t, true)
+	existing := &entity.Holiday{ID: 1, Date: entity.NewDate(2027, 1, 1), Name: "New Year's Day"}
+
+	mockHolidayRepo.On("GetAllHolidays", mock.Anything).Return([]*entity.Holiday{existing}, nil)
+	mockHolidayRepo.On("BeginTx").Return(mockTx, nil).Once()
+	mockHolidayRepo.On("CreateHoliday", mockTx, mock.Anything).Return(nil)
+	mockPaymentRepo.On("RescheduleUnpaidPayments", mockTx, mock.Anything, mock.Anything).Return(int64(0), nil)
+	mockAuditUsecase.On("Record", mock.Anything, mockTx, entity.AuditActionHolidayCreate, entity.AuditEntityHoliday, mock.Anything, nil, mock.Anything).Return(nil)
+
+	imported, err := holidayUsecase.ImportHolidays(context.Background(), []entity.HolidayPayload{
+		{Date: "2027-01-01", Name: "New Year's Day"},
+		{Date: "2026-12-25", Name: "Christmas Day"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, imported)
+	mockHolidayRepo.AssertNumberOfCalls(t, "CreateHoliday", 1)
+}
+
+func TestDeleteHoliday(t *testing.T) {
+	// 2027-01-01 is a Friday
+	holiday := &entity.Holiday{ID: 1, Date: entity.NewDate(2027, 1, 1), Name: "New Year's Day"}
+
+	t.Run("Success DeleteHoliday - Moves Installments Back", func(t *testing.T) {
+		holidayUsecase, mockHolidayRepo, mockPaymentRepo, mockAuditUsecase := setupHolidayMocks(entity.BusinessDayFollowing)
+		mockTx := newMockTx(t, true)
+
+		mockHolidayRepo.On("GetHolidayByID", mock.Anything, int64(1)).Return(holiday, nil)
+		mockHolidayRepo.On("GetAllHolidays", mock.Anything).Return([]*entity.Holiday{holiday}, nil)
+		mockHolidayRepo.On("BeginTx").Return(mockTx, nil)
+		mockHolidayRepo.On("DeleteHoliday", mockTx, int64(1)).Return(nil)
+		mockPaymentRepo.On("RestoreRescheduledPayments", mockTx, holiday.Date, holiday.Date).Return(int64(3), nil)
+		mockAuditUsecase.On("Record", mock.Anything, mockTx, entity.AuditActionHolidayDelete, entity.AuditEntityHoliday, int64(1), holiday, nil).Return(nil)
+
+		err := holidayUsecase.DeleteHoliday(context.Background(), 1)
+
+		assert.NoError(t, err)
+		mockHolidayRepo.AssertExpectations(t)
+		mockPaymentRepo.AssertExpectations(t)
+		mockAuditUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Success DeleteHoliday - Still A Holiday By Another Name", func(t *testing.T) {
+		holidayUsecase, mockHolidayRepo, mockPaymentRepo, mockAuditUsecase := setupHolidayMocks(entity.BusinessDayFollowing)
+		mockTx := newMockTx(t, true)
+		bankHoliday := &entity.Holiday{ID: 2, Date: entity.NewDate(2027, 1, 1), Name: "Bank Holiday"}
+
+		mockHolidayRepo.On("GetHolidayByID", mock.Anything, int64(1)).Return(holiday, nil)
+		mockHolidayRepo.On("GetAllHolidays", mock.Anything).Return([]*entity.Holiday{holiday, bankHoliday}, nil)
+		mockHolidayRepo.On("BeginTx").Return(mockTx, nil)
+		mockHolidayRepo.On("DeleteHoliday", mockTx, int64(1)).Return(nil)
+		mockPaymentRepo.On("RestoreRescheduledPayments", mockTx, holiday.Date, entity.NewDate(2027, 1, 4)).Return(int64(0), nil)
+		mockAuditUsecase.On("Record", mock.Anything, mockTx, entity.AuditActionHolidayDelete, entity.AuditEntityHoliday, int64(1), holiday, nil).Return(nil)
+
+		err := holidayUsecase.DeleteHoliday(context.Background(), 1)
+
+		assert.NoError(t, err)
+		mockPaymentRepo.AssertExpectations(t)
+	})
+
+	t.Run("Failed DeleteHoliday - Not Found", func(t *testing.T) {
+		holidayUsecase, mockHolidayRepo, mockPaymentRepo, _ := setupHolidayMocks(entity.BusinessDayFollowing)
+
+		mockHolidayRepo.On("GetHolidayByID", mock.Anything, int64(9)).Return(nil, ErrHolidayNotFound)
+
+		err := holidayUsecase.DeleteHoliday(context.Background(), 9)
+
+		assert.ErrorIs(t, err, ErrHolidayNotFound)
+		mockHolidayRepo.AssertNotCalled(t, "BeginTx")
+		mockPaymentRepo.AssertNotCalled(t, "RestoreRescheduledPayments", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
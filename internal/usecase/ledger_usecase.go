@@ -0,0 +1,249 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"loan-management/internal/apperror"
+	"loan-management/internal/entity"
+	"loan-management/internal/repository"
+	"math"
+	"time"
+)
+
+var ErrUnbalancedEntry = apperror.New(apperror.KindInternal, "UNBALANCED_JOURNAL_ENTRY", "Journal entry debits and credits don't match")
+
+type LedgerUsecaseInterface interface {
+	PostDisbursement(ctx context.Context, tx *sql.Tx, loan *entity.Loan) error
+	PostRepayment(ctx context.Context, tx *sql.Tx, loanID int64, trx *entity.Transaction, payments []*entity.Payment) error
+	PostReversal(ctx context.Context, tx *sql.Tx, referenceType string, referenceID int64) error
+	PostAdjustment(ctx context.Context, tx *sql.Tx, loanID int64, amount float64) error
+	PostCapitalization(ctx context.Context, tx *sql.Tx, loanID int64, referenceType string, referenceID int64, amount float64) error
+	GetTrialBalance(ctx context.Context, asOf time.Time) (*entity.TrialBalance, error)
+}
+
+type LedgerUsecase struct {
+	ledgerRepo repository.LedgerRepository
+}
+
+func NewLedgerUsecase(ledgerRepo repository.LedgerRepository) *LedgerUsecase {
+	return &LedgerUsecase{
+		ledgerRepo: ledgerRepo,
+	}
+}
+
+// PostDisbursement moves the principal from cash to the loan receivable
+func (u *LedgerUsecase) PostDisbursement(ctx context.Context, tx *sql.Tx, loan *entity.Loan) error {
+	return u.post(tx, &entity.JournalEntry{
+		LoanID:        &loan.ID,
+		ReferenceType: entity.JournalReferenceLoan,
+		ReferenceID:   loan.ID,
+		Description:   fmt.Sprintf("Disbursement of loan %d", loan.ID),
+		Lines: []*entity.JournalLine{
+			{AccountCode: entity.AccountLoanReceivable, Debit: loan.Amount},
+			{AccountCode: entity.AccountCash, Credit: loan.Amount},
+		},
+	})
+}
+
+// PostRepayment recognizes the interest of the paid installments and the capitalized interest their principal
+// repays, then settles them and the penalty with cash
+func (u *LedgerUsecase) PostRepayment(ctx context.Context, tx *sql.Tx, loanID int64, trx *entity.Transaction, payments []*entity.Payment) error {
+	var principal, interest, total float64
+	for _, payment := range payments {
+		principal += payment.Amount
+		interest += payment.Interest
+		total += payment.TotalAmount
+	}
+
+	// the balances are read before this repayment posts anything
+	earned, err := u.earnedCapitalizedInterest(tx, loanID, principal)
+	if err != nil {
+		return err
+	}
+
+	if earned > 0 {
+		err := u.post(tx, &entity.JournalEntry{
+			LoanID:        &loanID,
+			ReferenceType: entity.JournalReferenceTransaction,
+			ReferenceID:   trx.ID,
+			Description:   fmt.Sprintf("Capitalized interest recognition of loan %d", loanID),
+			Lines: []*entity.JournalLine{
+				{AccountCode: entity.AccountUnearnedInterest, Debit: earned},
+				{AccountCode: entity.AccountInterestIncome, Credit: earned},
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if interest > 0 {
+		err := u.post(tx, &entity.JournalEntry{
+			LoanID:        &loanID,
+			ReferenceType: entity.JournalReferenceTransaction,
+			ReferenceID:   trx.ID,
+			Description:   fmt.Sprintf("Interest recognition of loan %d", loanID),
+			Lines: []*entity.JournalLine{
+				{AccountCode: entity.AccountLoanReceivable, Debit: interest},
+				{AccountCode: entity.AccountInterestIncome, Credit: interest},
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	repayment := &entity.JournalEntry{
+		LoanID:        &loanID,
+		ReferenceType: entity.JournalReferenceTransaction,
+		ReferenceID:   trx.ID,
+		Description:   fmt.Sprintf("Repayment of loan %d", loanID),
+		Lines: []*entity.JournalLine{
+			{AccountCode: entity.AccountCash, Debit: total + trx.Penalty + trx.Rounding},
+			{AccountCode: entity.AccountLoanReceivable, Credit: total},
+		},
+	}
+	if trx.Penalty > 0 {
+		repayment.Lines = append(repayment.Lines, &entity.JournalLine{AccountCode: entity.AccountFeeIncome, Credit: trx.Penalty})
+	}
+	// the cash is what was received, the bills are settled in full whichever way it was rounded
+	switch {
+	case trx.Rounding > 0:
+		repayment.Lines = append(repayment.Lines, &entity.JournalLine{AccountCode: entity.AccountRounding, Credit: trx.Rounding})
+	case trx.Rounding < 0:
+		repayment.Lines = append(repayment.Lines, &entity.JournalLine{AccountCode: entity.AccountRounding, Debit: -trx.Rounding})
+	}
+
+	return u.post(tx, repayment)
+}
+
+// PostReversal posts a mirror entry for every entry of the reference that isn't a reversal itself
+func (u *LedgerUsecase) PostReversal(ctx context.Context, tx *sql.Tx, referenceType string, referenceID int64) error {
+	entries, err := u.ledgerRepo.GetJournalEntriesByReference(ctx, referenceType, referenceID)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.ReversalOf != nil {
+			continue
+		}
+
+		reversal := &entity.JournalEntry{
+			LoanID:        entry.LoanID,
+			ReferenceType: entry.ReferenceType,
+			ReferenceID:   entry.ReferenceID,
+			Description:   "Reversal of " + entry.Description,
+			ReversalOf:    &entry.ID,
+		}
+		for _, line := range entry.Lines {
+			reversal.Lines = append(reversal.Lines, &entity.JournalLine{AccountCode: line.AccountCode, Debit: line.Credit, Credit: line.Debit})
+		}
+
+		if err := u.post(tx, reversal); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PostAdjustment moves the loan receivable by amount against the suspense account, until the difference is explained
+func (u *LedgerUsecase) PostAdjustment(ctx context.Context, tx *sql.Tx, loanID int64, amount float64) error {
+	receivable := &entity.JournalLine{AccountCode: entity.AccountLoanReceivable, Debit: amount}
+	suspense := &entity.JournalLine{AccountCode: entity.AccountSuspense, Credit: amount}
+	if amount < 0 {
+		receivable = &entity.JournalLine{AccountCode: entity.AccountLoanReceivable, Credit: -amount}
+		suspense = &entity.JournalLine{AccountCode: entity.AccountSuspense, Debit: -amount}
+	}
+
+	return u.post(tx, &entity.JournalEntry{
+		LoanID:        &loanID,
+		ReferenceType: entity.JournalReferenceReconciliation,
+		ReferenceID:   loanID,
+		Description:   fmt.Sprintf("Reconciliation adjustment of loan %d", loanID),
+		Lines:         []*entity.JournalLine{receivable, suspense},
+	})
+}
+
+// PostCapitalization adds interest to the principal of the loan, the grace interest of a new loan or the arrears of
+// a restructure. It isn't paid yet, so it stays unearned until PostRepayment recognizes it with the principal
+func (u *LedgerUsecase) PostCapitalization(ctx context.Context, tx *sql.Tx, loanID int64, referenceType string, referenceID int64, amount float64) error {
+	return u.post(tx, &entity.JournalEntry{
+		LoanID:        &loanID,
+		ReferenceType: referenceType,
+		ReferenceID:   referenceID,
+		Description:   fmt.Sprintf("Capitalized interest of loan %d", loanID),
+		Lines: []*entity.JournalLine{
+			{AccountCode: entity.AccountLoanReceivable, Debit: amount},
+			{AccountCode: entity.AccountUnearnedInterest, Credit: amount},
+		},
+	})
+}
+
+// earnedCapitalizedInterest is the part of the unearned interest of the loan repaid with principal: the capitalized
+// interest is spread over the principal still receivable, so it's earned in proportion, and in full with the last
+// principal
+func (u *LedgerUsecase) earnedCapitalizedInterest(tx *sql.Tx, loanID int64, principal float64) (float64, error) {
+	balances, err := u.ledgerRepo.GetLoanAccountBalances(tx, loanID)
+	if err != nil {
+		return 0, err
+	}
+
+	unearned, receivable := -balances[entity.AccountUnearnedInterest], balances[entity.AccountLoanReceivable]
+	if unearned < entity.LedgerTolerance || principal <= 0 || receivable <= 0 {
+		return 0, nil
+	}
+
+	if principal >= receivable-entity.LedgerTolerance {
+		return unearned, nil
+	}
+	return unearned * principal / receivable, nil
+}
+
+func (u *LedgerUsecase) GetTrialBalance(ctx context.Context, asOf time.Time) (*entity.TrialBalance, error) {
+	if err := authorize(ctx, entity.PermLedgerRead); err != nil {
+		return nil, err
+	}
+
+	if asOf.IsZero() {
+		asOf = now()
+	}
+
+	accounts, err := u.ledgerRepo.GetAccounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	balances, err := u.ledgerRepo.GetAccountBalances(ctx, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	trialBalance := &entity.TrialBalance{AsOf: asOf, Accounts: make([]*entity.TrialBalanceLine, len(accounts))}
+	for i, account := range accounts {
+		line := &entity.TrialBalanceLine{Code: account.Code, Name: account.Name, Type: account.Type}
+		if balance := balances[account.Code]; balance >= 0 {
+			line.Debit = balance
+		} else {
+			line.Credit = -balance
+		}
+
+		trialBalance.Accounts[i] = line
+		trialBalance.TotalDebit += line.Debit
+		trialBalance.TotalCredit += line.Credit
+	}
+	trialBalance.Balanced = math.Abs(trialBalance.TotalDebit-trialBalance.TotalCredit) < entity.LedgerTolerance
+
+	return trialBalance, nil
+}
+
+func (u *LedgerUsecase) post(tx *sql.Tx, entry *entity.JournalEntry) error {
+	if !entry.IsBalanced() {
+		return ErrUnbalancedEntry
+	}
+
+	entry.PostedAt = now()
+	return u.ledgerRepo.CreateJournalEntry(tx, entry)
+}
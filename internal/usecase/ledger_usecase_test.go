@@ -0,0 +1,203 @@
+package usecase
+
+import (
+	"context"
+	"loan-management/internal/entity"
+	internalMock "loan-management/internal/mock"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPostRepayment(t *testing.T) {
+	t.Run("Success PostRepayment - Balanced Entries", func(t *testing.T) {
+		mockRepo := new(internalMock.MockLedgerRepository)
+		ledgerUsecase := NewLedgerUsecase(mockRepo)
+
+		var entries []*entity.JournalEntry
+		mockRepo.On("GetLoanAccountBalances", mock.Anything, int64(1)).Return(map[string]float64{entity.AccountLoanReceivable: 1000, entity.AccountCash: -1000}, nil)
+		mockRepo.On("CreateJournalEntry", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			entries = append(entries, args.Get(1).(*entity.JournalEntry))
+		}).Return(nil)
+
+		payments := []*entity.Payment{
+			{LoanID: 1, Amount: 100, Interest: 10, TotalAmount: 110},
+			{LoanID: 1, Amount: 100, Interest: 10, TotalAmount: 110},
+		}
+		err := ledgerUsecase.PostRepayment(context.Background(), nil, 1, &entity.Transaction{ID: 7, Penalty: 5}, payments)
+
+		assert.NoError(t, err)
+		assert.Len(t, entries, 2)
+		for _, entry := range entries {
+			assert.True(t, entry.IsBalanced())
+			assert.Equal(t, int64(7), entry.ReferenceID)
+		}
+		assert.Equal(t, &entity.JournalLine{AccountCode: entity.AccountInterestIncome, Credit: 20}, entries[0].Lines[1])
+		assert.Equal(t, &entity.JournalLine{AccountCode: entity.AccountCash, Debit: 225}, entries[1].Lines[0])
+		assert.Equal(t, &entity.JournalLine{AccountCode: entity.AccountFeeIncome, Credit: 5}, entries[1].Lines[2])
+	})
+
+	t.Run("Success PostRepayment - Books The Rounding Of The Cash Received", func(t *testing.T) {
+		mockRepo := new(internalMock.MockLedgerRepository)
+		ledgerUsecase := NewLedgerUsecase(mockRepo)
+
+		var entries []*entity.JournalEntry
+		mockRepo.On("GetLoanAccountBalances", mock.Anything, int64(1)).Return(map[string]float64{entity.AccountLoanReceivable: 1000, entity.AccountCash: -1000}, nil)
+		mockRepo.On("CreateJournalEntry", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			entries = append(entries, args.Get(1).(*entity.JournalEntry))
+		}).Return(nil)
+
+		// 110 received for a bill of 110.25
+		payments := []*entity.Payment{{LoanID: 1, Amount: 100, Interest: 10.25, TotalAmount: 110.25}}
+		err := ledgerUsecase.PostRepayment(context.Background(), nil, 1, &entity.Transaction{ID: 7, Rounding: -0.25}, payments)
+
+		assert.NoError(t, err)
+		assert.Len(t, entries, 2)
+		assert.True(t, entries[1].IsBalanced())
+		assert.Equal(t, []*entity.JournalLine{
+			{AccountCode: entity.AccountCash, Debit: 110},
+			{AccountCode: entity.AccountLoanReceivable, Credit: 110.25},
+			{AccountCode: entity.AccountRounding, Debit: 0.25},
+		}, entries[1].Lines)
+	})
+
+	t.Run("Success PostRepayment - Recognizes Capitalized Interest With The Principal", func(t *testing.T) {
+		mockRepo := new(internalMock.MockLedgerRepository)
+		ledgerUsecase := NewLedgerUsecase(mockRepo)
+
+		var entries []*entity.JournalEntry
+		// 5200 disbursed with 20 of grace interest capitalized, 2610 of principal repaid since
+		mockRepo.On("GetLoanAccountBalances", mock.Anything, int64(1)).Return(map[string]float64{entity.AccountLoanReceivable: 2610, entity.AccountUnearnedInterest: -10}, nil)
+		mockRepo.On("CreateJournalEntry", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			entries = append(entries, args.Get(1).(*entity.JournalEntry))
+		}).Return(nil)
+
+		payments := []*entity.Payment{{LoanID: 1, Amount: 1305, Interest: 5, TotalAmount: 1310}}
+		err := ledgerUsecase.PostRepayment(context.Background(), nil, 1, &entity.Transaction{ID: 7}, payments)
+
+		assert.NoError(t, err)
+		assert.Len(t, entries, 3)
+		assert.Equal(t, []*entity.JournalLine{
+			{AccountCode: entity.AccountUnearnedInterest, Debit: 5},
+			{AccountCode: entity.AccountInterestIncome, Credit: 5},
+		}, entries[0].Lines)
+	})
+
+	t.Run("Success PostRepayment - Last Principal Recognizes The Rest", func(t *testing.T) {
+		mockRepo := new(internalMock.MockLedgerRepository)
+		ledgerUsecase := NewLedgerUsecase(mockRepo)
+
+		var entries []*entity.JournalEntry
+		mockRepo.On("GetLoanAccountBalances", mock.Anything, int64(1)).Return(map[string]float64{entity.AccountLoanReceivable: 1305, entity.AccountUnearnedInterest: -5.001}, nil)
+		mockRepo.On("CreateJournalEntry", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			entries = append(entries, args.Get(1).(*entity.JournalEntry))
+		}).Return(nil)
+
+		payments := []*entity.Payment{{LoanID: 1, Amount: 1305, Interest: 5, TotalAmount: 1310}}
+		err := ledgerUsecase.PostRepayment(context.Background(), nil, 1, &entity.Transaction{ID: 7}, payments)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &entity.JournalLine{AccountCode: entity.AccountInterestIncome, Credit: 5.001}, entries[0].Lines[1])
+	})
+}
+
+func TestPostCapitalization(t *testing.T) {
+	t.Run("Success PostCapitalization - Unearned Until Repaid", func(t *testing.T) {
+		mockRepo := new(internalMock.MockLedgerRepository)
+		ledgerUsecase := NewLedgerUsecase(mockRepo)
+
+		var entry *entity.JournalEntry
+		mockRepo.On("CreateJournalEntry", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			entry = args.Get(1).(*entity.JournalEntry)
+		}).Return(nil)
+
+		err := ledgerUsecase.PostCapitalization(context.Background(), nil, 1, entity.JournalReferenceLoan, 1, 20)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []*entity.JournalLine{
+			{AccountCode: entity.AccountLoanReceivable, Debit: 20},
+			{AccountCode: entity.AccountUnearnedInterest, Credit: 20},
+		}, entry.Lines)
+	})
+}
+
+func TestPostReversal(t *testing.T) {
+	t.Run("Success PostReversal - Skips Reversed Entries", func(t *testing.T) {
+		mockRepo := new(internalMock.MockLedgerRepository)
+		ledgerUsecase := NewLedgerUsecase(mockRepo)
+
+		reversalOf := int64(1)
+		mockRepo.On("GetJournalEntriesByReference", mock.Anything, entity.JournalReferenceTransaction, int64(7)).Return([]*entity.JournalEntry{
+			{ID: 1, ReferenceType: entity.JournalReferenceTransaction, ReferenceID: 7, Lines: []*entity.JournalLine{
+				{AccountCode: entity.AccountCash, Debit: 110},
+				{AccountCode: entity.AccountLoanReceivable, Credit: 110},
+			}},
+			{ID: 2, ReversalOf: &reversalOf},
+		}, nil)
+
+		var reversal *entity.JournalEntry
+		mockRepo.On("CreateJournalEntry", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			reversal = args.Get(1).(*entity.JournalEntry)
+		}).Return(nil).Once()
+
+		err := ledgerUsecase.PostReversal(context.Background(), nil, entity.JournalReferenceTransaction, 7)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &reversalOf, reversal.ReversalOf)
+		assert.Equal(t, &entity.JournalLine{AccountCode: entity.AccountCash, Credit: 110}, reversal.Lines[0])
+		assert.Equal(t, &entity.JournalLine{AccountCode: entity.AccountLoanReceivable, Debit: 110}, reversal.Lines[1])
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Failed Post - Unbalanced Entry", func(t *testing.T) {
+		mockRepo := new(internalMock.MockLedgerRepository)
+		ledgerUsecase := NewLedgerUsecase(mockRepo)
+
+		err := ledgerUsecase.post(nil, &entity.JournalEntry{Lines: []*entity.JournalLine{
+			{AccountCode: entity.AccountCash, Debit: 110},
+			{AccountCode: entity.AccountLoanReceivable, Credit: 100},
+		}})
+
+		assert.ErrorIs(t, err, ErrUnbalancedEntry)
+		mockRepo.AssertNotCalled(t, "CreateJournalEntry", mock.Anything, mock.Anything)
+	})
+}
+
+func TestGetTrialBalance(t *testing.T) {
+	t.Run("Success GetTrialBalance", func(t *testing.T) {
+		mockRepo := new(internalMock.MockLedgerRepository)
+		ledgerUsecase := NewLedgerUsecase(mockRepo)
+		asOf := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		mockRepo.On("GetAccounts", mock.Anything).Return([]*entity.Account{
+			{Code: entity.AccountCash, Type: entity.AccountTypeAsset},
+			{Code: entity.AccountLoanReceivable, Type: entity.AccountTypeAsset},
+			{Code: entity.AccountInterestIncome, Type: entity.AccountTypeIncome},
+		}, nil)
+		mockRepo.On("GetAccountBalances", mock.Anything, asOf).Return(map[string]float64{
+			entity.AccountCash:           -890,
+			entity.AccountLoanReceivable: 900,
+			entity.AccountInterestIncome: -10,
+		}, nil)
+
+		trialBalance, err := ledgerUsecase.GetTrialBalance(context.Background(), asOf)
+
+		assert.NoError(t, err)
+		assert.Equal(t, float64(900), trialBalance.TotalDebit)
+		assert.Equal(t, float64(900), trialBalance.TotalCredit)
+		assert.True(t, trialBalance.Balanced)
+		assert.Equal(t, float64(890), trialBalance.Accounts[0].Credit)
+	})
+
+	t.Run("Failed GetTrialBalance - Borrower", func(t *testing.T) {
+		mockRepo := new(internalMock.MockLedgerRepository)
+		ledgerUsecase := NewLedgerUsecase(mockRepo)
+		ctx := entity.WithIdentity(context.Background(), &entity.Identity{Type: entity.IdentityTypeBorrower, Role: entity.RoleBorrower, UserID: 1})
+
+		_, err := ledgerUsecase.GetTrialBalance(ctx, time.Time{})
+
+		assert.ErrorIs(t, err, ErrForbidden)
+	})
+}
@@ -3,52 +3,127 @@ package usecase
 import (
 	"context"
 	"database/sql"
-	"errors"
+	"loan-management/internal/apperror"
 	"loan-management/internal/entity"
 	"loan-management/internal/repository"
+	"loan-management/internal/validation"
+	"log"
 	"os"
 	"strconv"
 	"time"
 )
 
 var (
-	ErrInvalidBillingStartDate = errors.New("billing start date cannot be in the past")
-	ErrStillHasActiveLoan      = errors.New("Can't create loan because you still have an active loans")
+	ErrInvalidBillingStartDate = apperror.Validation("INVALID_BILLING_START_DATE", "billing start date cannot be in the past")
+	ErrStillHasActiveLoan      = apperror.Eligibility("STILL_HAS_ACTIVE_LOAN", "Can't create loan because you still have an active loans")
+	ErrUserDelinquent          = apperror.Eligibility("USER_DELINQUENT", "Can't create loan due to user is delinquent")
+	ErrLoanNotFound            = repository.ErrLoanNotFound
+	ErrLoanNotPending          = repository.ErrLoanNotPending
+	ErrLoanNotRestructurable   = apperror.Conflict("LOAN_NOT_RESTRUCTURABLE", "Only active loans with unpaid installments can be restructured")
+	ErrVirtualAccountNotFound  = repository.ErrVirtualAccountNotFound
+	ErrInvalidVirtualAccount   = apperror.Validation("INVALID_VIRTUAL_ACCOUNT", "The virtual account number is invalid")
+	ErrVirtualAccountInactive  = apperror.Conflict("VIRTUAL_ACCOUNT_INACTIVE", "The virtual account no longer accepts payments")
+	ErrInvalidRepaymentPeriods = apperror.Validation("INVALID_REPAYMENT_PERIODS", "Grace and interest only periods must leave at least one installment repaying the principal, and every installment must have something to pay")
+	ErrInvalidBalloonPercent   = apperror.Validation("INVALID_BALLOON_PERCENT", "Balloon loans need a balloon percent, other repayment structures can't have one")
 )
 
 type LoanUsecaseInterface interface {
 	GetAllLoans(ctx context.Context) ([]*entity.Loan, error)
 	GetLoanByID(ctx context.Context, id int64, status *entity.LoanStatus) (*entity.Loan, error)
+	GetLoanByIDForUpdate(tx *sql.Tx, id int64) (*entity.Loan, error)
+	GetLoanByVirtualAccount(ctx context.Context, number string) (*entity.Loan, error)
 	GetLoansByUserID(ctx context.Context, userID int64, status entity.LoanStatus) ([]*entity.Loan, error)
 	CheckCreateLoanEligibility(ctx context.Context, loan *entity.Loan) error
 	CreateLoanWithPayments(ctx context.Context, loan *entity.Loan) error
 	GetLoanDuePayments(ctx context.Context, loan *entity.Loan) ([]*entity.Payment, error)
 	UpdateLoanOutstanding(tx *sql.Tx, outstanding float64, loanID int64) error
+	UpdateLoanDelinquency(tx *sql.Tx, loanID int64, delinquentSince *time.Time) error
+	UpdateVirtualAccountStatus(tx *sql.Tx, loan *entity.Loan, status entity.VirtualAccountStatus) error
 }
 
 type LoanUsecase struct {
 	loanRepo       repository.LoanRepository
 	userUsecase    UserUsecaseInterface
 	paymentUsecase PaymentUsecaseInterface
+	auditUsecase   AuditUsecaseInterface
+	ledgerUsecase  LedgerUsecaseInterface
+	eventUsecase   EventUsecaseInterface
+	holidayUsecase HolidayUsecaseInterface
+	// virtualAccountPrefix starts every virtual account number, usually the bank and company code
+	virtualAccountPrefix string
 }
 
-func NewLoanUsecase(loanRepo repository.LoanRepository, userUsecase UserUsecaseInterface, paymentUsecase PaymentUsecaseInterface) *LoanUsecase {
+func NewLoanUsecase(loanRepo repository.LoanRepository, userUsecase UserUsecaseInterface, paymentUsecase PaymentUsecaseInterface, auditUsecase AuditUsecaseInterface, ledgerUsecase LedgerUsecaseInterface, eventUsecase EventUsecaseInterface, holidayUsecase HolidayUsecaseInterface, virtualAccountPrefix string) *LoanUsecase {
 	return &LoanUsecase{
-		loanRepo:       loanRepo,
-		userUsecase:    userUsecase,
-		paymentUsecase: paymentUsecase,
+		loanRepo:             loanRepo,
+		userUsecase:          userUsecase,
+		paymentUsecase:       paymentUsecase,
+		auditUsecase:         auditUsecase,
+		ledgerUsecase:        ledgerUsecase,
+		eventUsecase:         eventUsecase,
+		holidayUsecase:       holidayUsecase,
+		virtualAccountPrefix: virtualAccountPrefix,
 	}
 }
 
+// GetAllLoans lists every loan for staff, borrowers only get their own loans
 func (u *LoanUsecase) GetAllLoans(ctx context.Context) ([]*entity.Loan, error) {
+	if identity := entity.IdentityFromContext(ctx); identity != nil && !identity.Can(entity.PermLoanRead) {
+		if !identity.Can(entity.PermLoanReadOwn) {
+			return nil, ErrForbidden
+		}
+		return u.loanRepo.GetLoansByUserID(ctx, identity.UserID, nil)
+	}
+
 	return u.loanRepo.GetAllLoans(ctx)
 }
 
 func (u *LoanUsecase) GetLoanByID(ctx context.Context, id int64, status *entity.LoanStatus) (*entity.Loan, error) {
-	return u.loanRepo.GetLoanByID(ctx, id, status)
+	loan, err := u.loanRepo.GetLoanByID(ctx, id, status)
+	if err != nil {
+		return nil, err
+	}
+
+	if loan != nil {
+		if err := authorizeOwner(ctx, entity.PermLoanRead, entity.PermLoanReadOwn, loan.UserID); err != nil {
+			return nil, err
+		}
+	}
+
+	return loan, nil
+}
+
+func (u *LoanUsecase) GetLoanByIDForUpdate(tx *sql.Tx, id int64) (*entity.Loan, error) {
+	return u.loanRepo.GetLoanByIDForUpdate(tx, id)
+}
+
+// GetLoanByVirtualAccount finds the loan paid through an active virtual account
+func (u *LoanUsecase) GetLoanByVirtualAccount(ctx context.Context, number string) (*entity.Loan, error) {
+	if !entity.ValidVirtualAccountNumber(number) {
+		return nil, ErrInvalidVirtualAccount
+	}
+
+	loan, err := u.loanRepo.GetLoanByVirtualAccount(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeOwner(ctx, entity.PermLoanRead, entity.PermLoanReadOwn, loan.UserID); err != nil {
+		return nil, err
+	}
+
+	if loan.VirtualAccountStatus != entity.VirtualAccountStatusActive {
+		return nil, ErrVirtualAccountInactive
+	}
+
+	return loan, nil
 }
 
 func (u *LoanUsecase) GetLoansByUserID(ctx context.Context, userID int64, status entity.LoanStatus) ([]*entity.Loan, error) {
+	if err := authorizeOwner(ctx, entity.PermLoanRead, entity.PermLoanReadOwn, userID); err != nil {
+		return nil, err
+	}
+
 	return u.loanRepo.GetLoansByUserID(ctx, userID, &status)
 }
 
@@ -66,14 +141,24 @@ func (u *LoanUsecase) CheckCreateLoanEligibility(ctx context.Context, loan *enti
 		return err
 	}
 	if isUserDelinquent {
-		return errors.New("Can't create loan due to user is delinquent")
+		return ErrUserDelinquent
 	}
 
 	return nil
 
 }
 
+// CreateLoanWithPayments books the loan as pending. Its outstanding is the total it will be repaid with, but the
+// installments, virtual account and disbursement only come with ApproveLoan
 func (u *LoanUsecase) CreateLoanWithPayments(ctx context.Context, loan *entity.Loan) error {
+	if err := authorizeOwner(ctx, entity.PermLoanCreate, entity.PermLoanCreateOwn, loan.UserID); err != nil {
+		return err
+	}
+
+	if err := validation.Struct(loan); err != nil {
+		return err
+	}
+
 	if err := u.validateBillingStartDate(loan.BillingStartDate); err != nil {
 		return err
 	}
@@ -86,12 +171,13 @@ func (u *LoanUsecase) CreateLoanWithPayments(ctx context.Context, loan *entity.L
 		return err
 	}
 
-	if loan.InterestType == entity.InterestTypeFlatAnnual {
-		loan.Outstanding = u.calculateTotalOutstanding(loan)
-	} else {
-		// TODO: Implement Reduce Annual Type
+	if _, _, err := u.schedule(ctx, loan); err != nil {
+		return err
 	}
 
+	loan.Status = entity.LoanStatusPending
+	loan.DisbursedAt = nil
+
 	tx, err := u.loanRepo.BeginTx()
 	if err != nil {
 		return err
@@ -108,46 +194,427 @@ func (u *LoanUsecase) CreateLoanWithPayments(ctx context.Context, loan *entity.L
 		return err
 	}
 
-	// calculate payment details
-	totalInterest := u.calculateInterest(loan)
-	amountPerInstallment := loan.Amount / float64(loan.Tenure)
-	interestPerInstallment := totalInterest / float64(loan.Tenure)
+	err = u.auditUsecase.Record(ctx, tx, entity.AuditActionLoanCreate, entity.AuditEntityLoan, loan.ID, nil, loan)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ApproveLoan disburses a pending loan: it creates its installments from the billing start date, assigns its
+// virtual account and posts the disbursement. The borrower must still be eligible. A billing start already past
+// moves to the first business day from the approval, so no installment falls due before the disbursement
+func (u *LoanUsecase) ApproveLoan(ctx context.Context, loanID int64) (*entity.Loan, error) {
+	if err := authorize(ctx, entity.PermLoanApprove); err != nil {
+		return nil, err
+	}
 
-	// generate payments
-	paymentsPayload := make([]entity.CreatePaymentPayload, loan.Tenure)
-	for i := 0; i < loan.Tenure; i++ {
+	businessDays, err := u.holidayUsecase.Calendar(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-		dueDate := loan.BillingStartDate.AddDate(0, 0, ((i + 1) * 7))
+	tx, err := u.loanRepo.BeginTx()
+	if err != nil {
+		return nil, err
+	}
 
-		paymentsPayload[i] = entity.CreatePaymentPayload{
-			LoanID:      loan.ID,
-			DueDate:     dueDate,
-			PaymentNo:   int32(i + 1),
-			Amount:      amountPerInstallment,
-			Interest:    interestPerInstallment,
-			TotalAmount: amountPerInstallment + interestPerInstallment,
+	defer func() {
+		if err != nil {
+			tx.Rollback()
 		}
+	}()
+
+	loan, err := u.loanRepo.GetLoanByIDForUpdate(tx, loanID)
+	if err != nil {
+		return nil, err
+	}
+
+	if loan.Status != entity.LoanStatusPending {
+		err = ErrLoanNotPending
+		return nil, err
+	}
+
+	if err = u.CheckCreateLoanEligibility(ctx, loan); err != nil {
+		return nil, err
+	}
+
+	before := *loan
+
+	if u.validateBillingStartDate(loan.BillingStartDate) != nil {
+		loan.BillingStartDate = businessDays.Next(today()).Start(businessLocation)
+	}
+
+	// the outstanding is computed again, as holidays added since the creation can move the due dates
+	paymentsPayload, capitalized, err := u.schedule(ctx, loan)
+	if err != nil {
+		return nil, err
+	}
+
+	disbursedAt := now()
+	if err = u.loanRepo.ApproveLoan(tx, loan.ID, loan.BillingStartDate, disbursedAt); err != nil {
+		return nil, err
+	}
+	loan.Status = entity.LoanStatusActive
+	loan.DisbursedAt = &disbursedAt
+
+	if err = u.loanRepo.UpdateLoanOutstanding(tx, loan.Outstanding, loan.ID); err != nil {
+		return nil, err
+	}
+
+	// the virtual account derives from the loan ID, so it's only known once the loan is approved
+	loan.VirtualAccount = entity.NewVirtualAccountNumber(u.virtualAccountPrefix, loan.ID)
+	loan.VirtualAccountStatus = entity.VirtualAccountStatusActive
+	err = u.loanRepo.UpdateVirtualAccount(tx, loan.ID, loan.VirtualAccount, loan.VirtualAccountStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range paymentsPayload {
+		paymentsPayload[i].LoanID = loan.ID
 	}
 
 	err = u.paymentUsecase.CreatePayment(tx, paymentsPayload)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	err = u.ledgerUsecase.PostDisbursement(ctx, tx, loan)
+	if err != nil {
+		return nil, err
+	}
+
+	if capitalized > 0 {
+		err = u.ledgerUsecase.PostCapitalization(ctx, tx, loan.ID, entity.JournalReferenceLoan, loan.ID, capitalized)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = u.auditUsecase.Record(ctx, tx, entity.AuditActionLoanApprove, entity.AuditEntityLoan, loan.ID, &before, loan)
+	if err != nil {
+		return nil, err
+	}
+
+	// subscribers only hear of the loan once it can be paid
+	err = u.eventUsecase.Publish(ctx, tx, entity.EventLoanCreated, entity.EventAggregateLoan, loan.ID, entity.LoanCreatedPayload{
+		LoanID:           loan.ID,
+		UserID:           loan.UserID,
+		Amount:           loan.Amount,
+		Interest:         loan.Interest,
+		Tenure:           loan.Tenure,
+		Outstanding:      loan.Outstanding,
+		BillingStartDate: loan.BillingStartDate,
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	err = tx.Commit()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return loan, nil
+}
+
+// RejectLoan closes a pending loan without disbursing it. Its outstanding is cleared, so it isn't collected nor
+// counted in the borrower's balance
+func (u *LoanUsecase) RejectLoan(ctx context.Context, loanID int64) (*entity.Loan, error) {
+	if err := authorize(ctx, entity.PermLoanApprove); err != nil {
+		return nil, err
+	}
+
+	tx, err := u.loanRepo.BeginTx()
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	loan, err := u.loanRepo.GetLoanByIDForUpdate(tx, loanID)
+	if err != nil {
+		return nil, err
+	}
+
+	if loan.Status != entity.LoanStatusPending {
+		err = ErrLoanNotPending
+		return nil, err
+	}
+
+	before := *loan
+
+	if err = u.loanRepo.RejectLoan(tx, loan.ID); err != nil {
+		return nil, err
+	}
+	loan.Status = entity.LoanStatusRejected
+	loan.Outstanding = 0
+
+	err = u.auditUsecase.Record(ctx, tx, entity.AuditActionLoanReject, entity.AuditEntityLoan, loan.ID, &before, loan)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return loan, nil
+}
+
+// schedule computes the installments of a new loan and sets its outstanding to their total, with the grace
+// interest capitalized into the principal
+func (u *LoanUsecase) schedule(ctx context.Context, loan *entity.Loan) ([]entity.CreatePaymentPayload, float64, error) {
+	if loan.GracePeriods+loan.InterestOnlyPeriods >= loan.Tenure {
+		return nil, 0, ErrInvalidRepaymentPeriods
+	}
+
+	if (loan.RepaymentStructure == entity.RepaymentStructureBalloon) != (loan.BalloonPercent > 0) {
+		return nil, 0, ErrInvalidBalloonPercent
+	}
+
+	businessDays, err := u.holidayUsecase.Calendar(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	terms := repaymentTerms{
+		principal:           loan.Amount,
+		rate:                loan.Interest,
+		interestType:        loan.InterestType,
+		strategy:            newScheduleStrategy(loan.RepaymentStructure, loan.BalloonPercent),
+		tenure:              loan.Tenure,
+		gracePeriods:        loan.GracePeriods,
+		graceInterest:       loan.GraceInterest,
+		interestOnlyPeriods: loan.InterestOnlyPeriods,
+		start:               entity.DateOf(loan.BillingStartDate, businessLocation),
+		firstPaymentNo:      1,
+		businessDays:        businessDays,
+	}
+	paymentsPayload, capitalized := terms.schedule()
+	// without interest, interest only installments and the installments before a bullet have nothing to pay
+	for _, payment := range paymentsPayload {
+		if payment.TotalAmount <= 0 {
+			return nil, 0, ErrInvalidRepaymentPeriods
+		}
+	}
+	loan.Outstanding = scheduleTotal(paymentsPayload)
+
+	return paymentsPayload, capitalized, nil
+}
+
+// RestructureLoan closes the unpaid installments of an active loan and replaces them with a new schedule on the
+// unpaid principal at the new rate and tenure, the first installment falling due a week after the grace periods.
+// The closed installments are kept for history and the caller is recorded as approver
+func (u *LoanUsecase) RestructureLoan(ctx context.Context, loanID int64, payload *entity.RestructureLoanPayload) (*entity.LoanRestructure, error) {
+	if err := authorize(ctx, entity.PermLoanApprove); err != nil {
+		return nil, err
+	}
+
+	if err := validation.Struct(payload); err != nil {
+		return nil, err
+	}
+
+	businessDays, err := u.holidayUsecase.Calendar(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := u.loanRepo.BeginTx()
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	loan, err := u.loanRepo.GetLoanByIDForUpdate(tx, loanID)
+	if err != nil {
+		return nil, err
+	}
+
+	// the payments are read under the lock, so a payment committed meanwhile isn't taken as unpaid
+	payments, err := u.paymentUsecase.GetPaymentsByLoanID(ctx, loanID, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	restructuredAt := now()
+	today := entity.DateOf(restructuredAt, businessLocation)
+
+	var (
+		principal, arrears float64
+		unpaid             int
+		lastPaymentNo      int32
+	)
+	for _, payment := range payments {
+		if payment.PaymentNo > lastPaymentNo {
+			lastPaymentNo = payment.PaymentNo
+		}
+		if payment.Status != entity.PaymentStatusActive {
+			continue
+		}
+		unpaid++
+		principal += payment.Amount
+		if payment.DueDate.Before(today) {
+			arrears += payment.Interest
+		}
+	}
+
+	if loan.Status != entity.LoanStatusActive || unpaid == 0 {
+		err = ErrLoanNotRestructurable
+		return nil, err
+	}
+
+	restructure := &entity.LoanRestructure{
+		LoanID:              loan.ID,
+		Reason:              payload.Reason,
+		PreviousInterest:    loan.Interest,
+		PreviousTenure:      loan.Tenure,
+		PreviousOutstanding: loan.Outstanding,
+		Interest:            payload.Interest,
+		Tenure:              payload.Tenure,
+		GracePeriods:        payload.GracePeriods,
+		FirstPaymentNo:      lastPaymentNo + 1,
+		CreatedAt:           restructuredAt,
+	}
+	if identity := entity.IdentityFromContext(ctx); identity != nil && identity.UserID != 0 {
+		restructure.ApprovedBy = &identity.UserID
+	}
+
+	// arrears interest that isn't capitalized stays due with the first new installment, without bearing interest
+	var carried float64
+	if payload.CapitalizeArrears {
+		restructure.CapitalizedArrears = arrears
+		principal += arrears
+	} else {
+		carried = arrears
+	}
+	restructure.Principal = principal
+
+	// the grace periods of a restructure postpone the new schedule instead of being part of its tenure, the new
+	// schedule keeps the interest type and repayment structure of the loan
+	terms := repaymentTerms{
+		principal:      principal,
+		rate:           payload.Interest,
+		interestType:   loan.InterestType,
+		strategy:       newScheduleStrategy(loan.RepaymentStructure, loan.BalloonPercent),
+		tenure:         payload.Tenure,
+		start:          today.AddDays(7 * payload.GracePeriods),
+		firstPaymentNo: restructure.FirstPaymentNo,
+		businessDays:   businessDays,
+	}
+	schedule, _ := terms.schedule()
+	for i := range schedule {
+		schedule[i].LoanID = loan.ID
+	}
+	schedule[0].Interest += carried
+	schedule[0].TotalAmount += carried
+	for _, payment := range schedule {
+		if payment.TotalAmount <= 0 {
+			err = ErrInvalidRepaymentPeriods
+			return nil, err
+		}
+	}
+	restructure.Outstanding = scheduleTotal(schedule)
+
+	if err = u.paymentUsecase.CloseUnpaidPayments(tx, loan.ID, restructuredAt); err != nil {
+		return nil, err
+	}
+
+	if err = u.paymentUsecase.CreatePayment(tx, schedule); err != nil {
+		return nil, err
+	}
+
+	if err = u.loanRepo.UpdateLoanOutstanding(tx, restructure.Outstanding, loan.ID); err != nil {
+		return nil, err
+	}
+
+	if err = u.loanRepo.UpdateLoanTerms(tx, loan.ID, restructure.Interest, restructure.Tenure); err != nil {
+		return nil, err
+	}
+
+	// the past due installments are rescheduled, so the loan is no longer delinquent
+	if loan.DelinquentSince != nil {
+		if err = u.loanRepo.UpdateLoanDelinquency(tx, loan.ID, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = u.loanRepo.CreateRestructure(tx, restructure); err != nil {
+		return nil, err
+	}
+
+	if restructure.CapitalizedArrears > 0 {
+		if err = u.ledgerUsecase.PostCapitalization(ctx, tx, loan.ID, entity.JournalReferenceRestructure, restructure.ID, restructure.CapitalizedArrears); err != nil {
+			return nil, err
+		}
+	}
+
+	restructured := *loan
+	restructured.Outstanding = restructure.Outstanding
+	restructured.Interest = restructure.Interest
+	restructured.Tenure = restructure.Tenure
+	restructured.DelinquentSince = nil
+	if err = u.auditUsecase.Record(ctx, tx, entity.AuditActionLoanRestructure, entity.AuditEntityLoan, loan.ID, loan, &restructured); err != nil {
+		return nil, err
+	}
+
+	err = u.eventUsecase.Publish(ctx, tx, entity.EventLoanRestructured, entity.EventAggregateLoan, loan.ID, entity.LoanRestructuredPayload{
+		LoanID:             loan.ID,
+		UserID:             loan.UserID,
+		RestructureID:      restructure.ID,
+		Interest:           restructure.Interest,
+		Tenure:             restructure.Tenure,
+		GracePeriods:       restructure.GracePeriods,
+		Principal:          restructure.Principal,
+		CapitalizedArrears: restructure.CapitalizedArrears,
+		Outstanding:        restructure.Outstanding,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return restructure, nil
+}
+
+// GetRestructures lists the restructures of a loan, oldest first
+func (u *LoanUsecase) GetRestructures(ctx context.Context, loanID int64) ([]*entity.LoanRestructure, error) {
+	loan, err := u.GetLoanByID(ctx, loanID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if loan == nil {
+		return nil, ErrLoanNotFound
+	}
+
+	return u.loanRepo.GetRestructuresByLoanID(ctx, loanID)
 }
 
 func (u *LoanUsecase) GetLoanDuePayments(ctx context.Context, loan *entity.Loan) ([]*entity.Payment, error) {
 
-	var dueBefore time.Time
+	var dueBefore entity.Date
 	if loan.TenureType == entity.TenureTypeWeekly {
 		// added 7 days to include next due payments
-		dueBefore = time.Now().AddDate(0, 0, 7)
+		dueBefore = today().AddDays(7)
 	} else {
 		// TODO: implement monthly calculation
 	}
@@ -166,30 +633,136 @@ func (u *LoanUsecase) UpdateLoanOutstanding(tx *sql.Tx, outstanding float64, loa
 	return u.loanRepo.UpdateLoanOutstanding(tx, outstanding, loanID)
 }
 
-func (u *LoanUsecase) validateBillingStartDate(billingStartDate time.Time) error {
-	// for testing purpose: enable loan creating with start billing date that already in the past
-	allowPastDate, err := strconv.ParseBool(os.Getenv("ALLOW_CREATE_LOAN_PAST_DATE"))
+func (u *LoanUsecase) UpdateLoanDelinquency(tx *sql.Tx, loanID int64, delinquentSince *time.Time) error {
+	return u.loanRepo.UpdateLoanDelinquency(tx, loanID, delinquentSince)
+}
+
+// UpdateVirtualAccountStatus opens or closes the virtual account of a loan, loans without one are left alone
+func (u *LoanUsecase) UpdateVirtualAccountStatus(tx *sql.Tx, loan *entity.Loan, status entity.VirtualAccountStatus) error {
+	if loan.VirtualAccount == "" {
+		return nil
+	}
+	return u.loanRepo.UpdateVirtualAccount(tx, loan.ID, loan.VirtualAccount, status)
+}
+
+// WatchDelinquency flags delinquent loans every interval until ctx is done
+func (u *LoanUsecase) WatchDelinquency(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := u.FlagDelinquentLoans(ctx); err != nil {
+			log.Printf("Failed to flag delinquent loans: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// FlagDelinquentLoans marks the active loans that became delinquent and publishes an event for each.
+// The mark is cleared by the next payment, so a loan is only reported again after it recovered
+func (u *LoanUsecase) FlagDelinquentLoans(ctx context.Context) (int, error) {
+	loans, err := u.loanRepo.GetAllLoans(ctx)
 	if err != nil {
-		allowPastDate = false
+		return 0, err
 	}
-	if !allowPastDate && billingStartDate.Before(time.Now().Truncate(24*time.Hour)) {
-		return ErrInvalidBillingStartDate
+
+	flagged := 0
+	for _, loan := range loans {
+		if loan.Status != entity.LoanStatusActive || loan.DelinquentSince != nil {
+			continue
+		}
+
+		duePayments, err := u.GetLoanDuePayments(ctx, loan)
+		if err != nil {
+			return flagged, err
+		}
+
+		if len(duePayments) <= delinquentDuePayments {
+			continue
+		}
+
+		ok, err := u.flagDelinquentLoan(ctx, loan.ID, duePayments)
+		if err != nil {
+			return flagged, err
+		}
+		if ok {
+			flagged++
+		}
 	}
-	return nil
+
+	return flagged, nil
 }
 
-func (u *LoanUsecase) calculateTotalOutstanding(loan *entity.Loan) float64 {
-	totalInterest := u.calculateInterest(loan)
+func (u *LoanUsecase) flagDelinquentLoan(ctx context.Context, loanID int64, duePayments []*entity.Payment) (bool, error) {
+	tx, err := u.loanRepo.BeginTx()
+	if err != nil {
+		return false, err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	loan, err := u.loanRepo.GetLoanByIDForUpdate(tx, loanID)
+	if err != nil {
+		return false, err
+	}
+
+	// paid or flagged since the loans were read
+	if loan.Status != entity.LoanStatusActive || loan.DelinquentSince != nil {
+		return false, tx.Rollback()
+	}
+
+	since := now()
+	if err = u.loanRepo.UpdateLoanDelinquency(tx, loan.ID, &since); err != nil {
+		return false, err
+	}
+
+	flagged := *loan
+	flagged.DelinquentSince = &since
+	if err = u.auditUsecase.Record(ctx, tx, entity.AuditActionLoanDelinquent, entity.AuditEntityLoan, loan.ID, loan, &flagged); err != nil {
+		return false, err
+	}
+
+	var amountDue float64
+	for _, payment := range duePayments {
+		amountDue += payment.TotalAmount
+	}
 
-	totalOutstanding := loan.Amount + totalInterest
+	err = u.eventUsecase.Publish(ctx, tx, entity.EventLoanBecameDelinquent, entity.EventAggregateLoan, loan.ID, entity.LoanBecameDelinquentPayload{
+		LoanID:      loan.ID,
+		UserID:      loan.UserID,
+		DuePayments: len(duePayments),
+		AmountDue:   amountDue,
+		Since:       since,
+	})
+	if err != nil {
+		return false, err
+	}
 
-	return totalOutstanding
+	if err = tx.Commit(); err != nil {
+		return false, err
+	}
+
+	return true, nil
 }
 
-func (u *LoanUsecase) calculateInterest(loan *entity.Loan) float64 {
-	tenureInYears := float64(loan.Tenure) / 52
-	// if loan.TenureType == entity.TenureTypeMonthly {
-	// 	tenureInYears = float64(loan.Tenure) / 12
-	// }
-	return loan.Amount * (loan.Interest / 100) * tenureInYears
+// validateBillingStartDate rejects billing that starts before today, both taken as business days
+func (u *LoanUsecase) validateBillingStartDate(billingStartDate time.Time) error {
+	// for testing purpose: enable loan creating with start billing date that already in the past
+	allowPastDate, err := strconv.ParseBool(os.Getenv("ALLOW_CREATE_LOAN_PAST_DATE"))
+	if err != nil {
+		allowPastDate = false
+	}
+	if !allowPastDate && entity.DateOf(billingStartDate, businessLocation).Before(today()) {
+		return ErrInvalidBillingStartDate
+	}
+	return nil
 }
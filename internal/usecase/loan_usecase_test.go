@@ -4,12 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"loan-management/internal/calendar"
 	"loan-management/internal/entity"
 	internalMock "loan-management/internal/mock"
+	"loan-management/internal/validation"
 	"testing"
 	"time"
 
-	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -26,20 +27,26 @@ var MockLoan = &entity.Loan{
 	BillingStartDate: time.Now(),
 }
 
-func setupMocks() (*internalMock.MockLoanRepository, *internalMock.MockUserUsecase, *internalMock.MockPaymentUsecase, *LoanUsecase) {
+func setupMocks() (*internalMock.MockLoanRepository, *internalMock.MockUserUsecase, *internalMock.MockPaymentUsecase, *internalMock.MockAuditUsecase, *internalMock.MockLedgerUsecase, *internalMock.MockEventUsecase, *LoanUsecase) {
 	mockRepo := new(internalMock.MockLoanRepository)
 	mockUserUsecase := new(internalMock.MockUserUsecase)
 	mockPaymentUsecase := new(internalMock.MockPaymentUsecase)
+	mockAuditUsecase := new(internalMock.MockAuditUsecase)
+	mockLedgerUsecase := new(internalMock.MockLedgerUsecase)
+	mockEventUsecase := new(internalMock.MockEventUsecase)
+	// a nil calendar keeps the due dates on the day of their period
+	mockHolidayUsecase := new(internalMock.MockHolidayUsecase)
+	mockHolidayUsecase.On("Calendar", mock.Anything).Return(nil, nil)
 
-	mockUsecase := NewLoanUsecase(mockRepo, mockUserUsecase, mockPaymentUsecase)
+	mockUsecase := NewLoanUsecase(mockRepo, mockUserUsecase, mockPaymentUsecase, mockAuditUsecase, mockLedgerUsecase, mockEventUsecase, mockHolidayUsecase, "8808")
 
-	return mockRepo, mockUserUsecase, mockPaymentUsecase, mockUsecase
+	return mockRepo, mockUserUsecase, mockPaymentUsecase, mockAuditUsecase, mockLedgerUsecase, mockEventUsecase, mockUsecase
 }
 
 func TestGetAllLoans(t *testing.T) {
 
 	t.Run("Success GetAllLoans", func(t *testing.T) {
-		mockRepo, _, _, mockUsecase := setupMocks()
+		mockRepo, _, _, _, _, _, mockUsecase := setupMocks()
 		expectedLoans := []*entity.Loan{MockLoan}
 
 		mockRepo.On("GetAllLoans", mock.Anything).Return(expectedLoans, nil)
@@ -50,11 +57,25 @@ func TestGetAllLoans(t *testing.T) {
 		assert.Equal(t, expectedLoans, loans)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("Success GetAllLoans - Borrower Only Gets Own Loans", func(t *testing.T) {
+		mockRepo, _, _, _, _, _, mockUsecase := setupMocks()
+		expectedLoans := []*entity.Loan{MockLoan}
+		ctx := entity.WithIdentity(context.Background(), &entity.Identity{Type: entity.IdentityTypeBorrower, Role: entity.RoleBorrower, UserID: 1})
+
+		mockRepo.On("GetLoansByUserID", mock.Anything, int64(1), (*entity.LoanStatus)(nil)).Return(expectedLoans, nil)
+
+		loans, err := mockUsecase.GetAllLoans(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expectedLoans, loans)
+		mockRepo.AssertNotCalled(t, "GetAllLoans", mock.Anything)
+	})
 }
 
 func TestGetLoanByID(t *testing.T) {
 	t.Run("Success GetLoanByID", func(t *testing.T) {
-		mockRepo, _, _, mockUsecase := setupMocks()
+		mockRepo, _, _, _, _, _, mockUsecase := setupMocks()
 
 		loanStatusActive := entity.LoanStatusActive
 		mockRepo.On("GetLoanByID", mock.Anything, mock.Anything, mock.Anything).Return(MockLoan, nil)
@@ -65,11 +86,79 @@ func TestGetLoanByID(t *testing.T) {
 		assert.Equal(t, MockLoan, loan)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("Failed GetLoanByID - Other Borrower", func(t *testing.T) {
+		mockRepo, _, _, _, _, _, mockUsecase := setupMocks()
+		ctx := entity.WithIdentity(context.Background(), &entity.Identity{Type: entity.IdentityTypeBorrower, Role: entity.RoleBorrower, UserID: 2})
+
+		mockRepo.On("GetLoanByID", mock.Anything, mock.Anything, mock.Anything).Return(MockLoan, nil)
+
+		_, err := mockUsecase.GetLoanByID(ctx, 1, nil)
+
+		assert.ErrorIs(t, err, ErrForbidden)
+	})
+
+	t.Run("Success GetLoanByID - Collector", func(t *testing.T) {
+		mockRepo, _, _, _, _, _, mockUsecase := setupMocks()
+		ctx := entity.WithIdentity(context.Background(), &entity.Identity{Type: entity.IdentityTypeBorrower, Role: entity.RoleCollector, UserID: 2})
+
+		mockRepo.On("GetLoanByID", mock.Anything, mock.Anything, mock.Anything).Return(MockLoan, nil)
+
+		loan, err := mockUsecase.GetLoanByID(ctx, 1, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, MockLoan, loan)
+	})
+}
+
+func TestGetLoanByVirtualAccount(t *testing.T) {
+	vaLoan := &entity.Loan{ID: 1, UserID: 1, Status: entity.LoanStatusActive, VirtualAccount: "880800000000016", VirtualAccountStatus: entity.VirtualAccountStatusActive}
+
+	t.Run("Success GetLoanByVirtualAccount", func(t *testing.T) {
+		mockRepo, _, _, _, _, _, mockUsecase := setupMocks()
+		mockRepo.On("GetLoanByVirtualAccount", mock.Anything, "880800000000016").Return(vaLoan, nil)
+
+		loan, err := mockUsecase.GetLoanByVirtualAccount(context.Background(), "880800000000016")
+
+		assert.NoError(t, err)
+		assert.Equal(t, vaLoan, loan)
+	})
+
+	t.Run("Failed GetLoanByVirtualAccount - Wrong Check Digit", func(t *testing.T) {
+		mockRepo, _, _, _, _, _, mockUsecase := setupMocks()
+
+		_, err := mockUsecase.GetLoanByVirtualAccount(context.Background(), "880800000000017")
+
+		assert.ErrorIs(t, err, ErrInvalidVirtualAccount)
+		mockRepo.AssertNotCalled(t, "GetLoanByVirtualAccount", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failed GetLoanByVirtualAccount - Inactive", func(t *testing.T) {
+		mockRepo, _, _, _, _, _, mockUsecase := setupMocks()
+		paidOffLoan := *vaLoan
+		paidOffLoan.Status = entity.LoanStatusPaid
+		paidOffLoan.VirtualAccountStatus = entity.VirtualAccountStatusInactive
+		mockRepo.On("GetLoanByVirtualAccount", mock.Anything, "880800000000016").Return(&paidOffLoan, nil)
+
+		_, err := mockUsecase.GetLoanByVirtualAccount(context.Background(), "880800000000016")
+
+		assert.ErrorIs(t, err, ErrVirtualAccountInactive)
+	})
+
+	t.Run("Failed GetLoanByVirtualAccount - Other Borrower", func(t *testing.T) {
+		mockRepo, _, _, _, _, _, mockUsecase := setupMocks()
+		ctx := entity.WithIdentity(context.Background(), &entity.Identity{Type: entity.IdentityTypeBorrower, Role: entity.RoleBorrower, UserID: 2})
+		mockRepo.On("GetLoanByVirtualAccount", mock.Anything, "880800000000016").Return(vaLoan, nil)
+
+		_, err := mockUsecase.GetLoanByVirtualAccount(ctx, "880800000000016")
+
+		assert.ErrorIs(t, err, ErrForbidden)
+	})
 }
 
 func TestGetLoansByUserID(t *testing.T) {
 	t.Run("Success GetLoanByUserID", func(t *testing.T) {
-		mockRepo, _, _, mockUsecase := setupMocks()
+		mockRepo, _, _, _, _, _, mockUsecase := setupMocks()
 
 		loanStatusActive := entity.LoanStatusActive
 		mockLoans := []*entity.Loan{MockLoan}
@@ -85,7 +174,7 @@ func TestGetLoansByUserID(t *testing.T) {
 
 func TestCheckCreateLoanEligibility(t *testing.T) {
 	t.Run("Success CheckCreateLoanEligibility ", func(t *testing.T) {
-		mockRepo, mockUserUsecase, _, mockUsecase := setupMocks()
+		mockRepo, mockUserUsecase, _, _, _, _, mockUsecase := setupMocks()
 		mockUserUsecase.On("IsUserDelinquent", mock.Anything, mock.Anything).Return(false, nil)
 
 		err := mockUsecase.CheckCreateLoanEligibility(context.Background(), MockLoan)
@@ -100,54 +189,115 @@ func TestCreateLoanWithPayments(t *testing.T) {
 
 	t.Run("Success CreateLoan", func(t *testing.T) {
 		// setup dbMock to get mock of sql.tx
-		db, dbMock, _ := sqlmock.New()
-		defer db.Close()
-		dbMock.ExpectBegin()
-		mockTx, _ := db.Begin()
-		dbMock.ExpectCommit()
+		mockTx := newMockTx(t, true)
 
 		mockTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
 		now = func() time.Time {
 			return mockTime
 		}
 
-		mockRepo, mockUserUsecase, mockPaymentUsecase, mockUsecase := setupMocks()
+		mockRepo, mockUserUsecase, mockPaymentUsecase, mockAuditUsecase, mockLedgerUsecase, mockEventUsecase, mockUsecase := setupMocks()
+		loan := *MockLoan
+		loan.ID = 12
 
-		mockRepo.On("CreateLoan", mock.Anything, mock.Anything).Return(MockLoan, nil)
+		mockRepo.On("CreateLoan", mockTx, &loan).Return(&loan, nil)
+		mockAuditUsecase.On("Record", mock.Anything, mockTx, entity.AuditActionLoanCreate, entity.AuditEntityLoan, int64(12), nil, &loan).Return(nil)
 		mockRepo.On("BeginTx").Return(mockTx, nil)
 		mockUserUsecase.On("IsUserDelinquent", mock.Anything, mock.Anything).Return(false, nil)
 		mockUserUsecase.On("GetUserByID", mock.Anything, mock.Anything).Return(MockUser, nil)
 
-		expectedInterest := float64((MockLoan.Amount * (MockLoan.Interest / 100)) / 52)
-		customMockPaymentPayload := entity.CreatePaymentPayload{
-			LoanID:      MockLoan.ID,
-			DueDate:     MockLoan.BillingStartDate.AddDate(0, 0, 7),
-			PaymentNo:   int32(1),
-			Amount:      MockLoan.Amount,
-			Interest:    expectedInterest,
-			TotalAmount: MockLoan.Amount/float64(MockLoan.Tenure) + expectedInterest,
-		}
-		expectedPaymentPayload := []*entity.CreatePaymentPayload{&customMockPaymentPayload}
-		mockPaymentUsecase.On("CreatePayment", mock.Anything, mock.MatchedBy(func(payloads []entity.CreatePaymentPayload) bool {
-			t.Logf("Payloads: %+v", expectedPaymentPayload)
-
-			// Convert payloads to slice of pointers
-			payloadPtrs := make([]*entity.CreatePaymentPayload, len(payloads))
-			for i := range payloads {
-				payloadPtrs[i] = &payloads[i]
-			}
-
-			// ensuring correct payloads when creating payments
-			return assert.EqualValues(t, expectedPaymentPayload, payloadPtrs)
-		})).Return(nil)
-		err := mockUsecase.CreateLoanWithPayments(context.Background(), MockLoan)
+		err := mockUsecase.CreateLoanWithPayments(context.Background(), &loan)
 
+		// the loan waits for approval, without installments, virtual account nor disbursement
+		expectedInterest := float64((MockLoan.Amount * (MockLoan.Interest / 100)) / 52)
 		assert.NoError(t, err)
+		assert.Equal(t, entity.LoanStatusPending, loan.Status)
+		assert.Nil(t, loan.DisbursedAt)
+		assert.InDelta(t, MockLoan.Amount+expectedInterest, loan.Outstanding, 1e-9)
+		assert.Empty(t, loan.VirtualAccount)
+		mockRepo.AssertExpectations(t)
+		mockAuditUsecase.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "UpdateVirtualAccount", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mockPaymentUsecase.AssertNotCalled(t, "CreatePayment", mock.Anything, mock.Anything)
+		mockLedgerUsecase.AssertNotCalled(t, "PostDisbursement", mock.Anything, mock.Anything, mock.Anything)
+		mockEventUsecase.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failed CreateLoan - Invalid Tenure", func(t *testing.T) {
+		mockRepo, mockUserUsecase, _, _, _, _, mockUsecase := setupMocks()
+		customMockLoan := *MockLoan
+		customMockLoan.Tenure = 0
+
+		err := mockUsecase.CreateLoanWithPayments(context.Background(), &customMockLoan)
+
+		assert.ErrorIs(t, err, validation.ErrValidationFailed)
+		mockUserUsecase.AssertNotCalled(t, "GetUserByID", mock.Anything, mock.Anything)
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("Failed CreateLoan - Invalid Repayment Periods", func(t *testing.T) {
+		mockRepo, mockUserUsecase, _, _, _, _, mockUsecase := setupMocks()
+		mockUserUsecase.On("GetUserByID", mock.Anything, mock.Anything).Return(MockUser, nil)
+		mockUserUsecase.On("IsUserDelinquent", mock.Anything, mock.Anything).Return(false, nil)
+		customMockLoan := *MockLoan
+		customMockLoan.Tenure = 4
+		customMockLoan.GracePeriods = 2
+		customMockLoan.InterestOnlyPeriods = 2
+
+		err := mockUsecase.CreateLoanWithPayments(context.Background(), &customMockLoan)
+
+		assert.ErrorIs(t, err, ErrInvalidRepaymentPeriods)
+		mockRepo.AssertNotCalled(t, "BeginTx")
+	})
+
+	t.Run("Failed CreateLoan - Bullet Without Interest", func(t *testing.T) {
+		mockRepo, mockUserUsecase, _, _, _, _, mockUsecase := setupMocks()
+		mockUserUsecase.On("GetUserByID", mock.Anything, mock.Anything).Return(MockUser, nil)
+		mockUserUsecase.On("IsUserDelinquent", mock.Anything, mock.Anything).Return(false, nil)
+		customMockLoan := *MockLoan
+		customMockLoan.Interest = 0
+		customMockLoan.Tenure = 4
+		customMockLoan.RepaymentStructure = entity.RepaymentStructureBullet
+
+		err := mockUsecase.CreateLoanWithPayments(context.Background(), &customMockLoan)
+
+		assert.ErrorIs(t, err, ErrInvalidRepaymentPeriods)
+		mockRepo.AssertNotCalled(t, "BeginTx")
+	})
+
+	t.Run("Failed CreateLoan - Balloon Without Percent", func(t *testing.T) {
+		mockRepo, mockUserUsecase, _, _, _, _, mockUsecase := setupMocks()
+		mockUserUsecase.On("GetUserByID", mock.Anything, mock.Anything).Return(MockUser, nil)
+		mockUserUsecase.On("IsUserDelinquent", mock.Anything, mock.Anything).Return(false, nil)
+		customMockLoan := *MockLoan
+		customMockLoan.Tenure = 4
+		customMockLoan.RepaymentStructure = entity.RepaymentStructureBalloon
+
+		err := mockUsecase.CreateLoanWithPayments(context.Background(), &customMockLoan)
+
+		assert.ErrorIs(t, err, ErrInvalidBalloonPercent)
+		mockRepo.AssertNotCalled(t, "BeginTx")
+	})
+
+	t.Run("Failed CreateLoan - Billing Starts Before The Business Day", func(t *testing.T) {
+		t.Setenv("ALLOW_CREATE_LOAN_PAST_DATE", "false")
+		// 20:00 UTC is already the next morning in Jakarta, where billing would start the day before
+		businessLocation = time.FixedZone("WIB", 7*60*60)
+		now = func() time.Time { return time.Date(2025, 1, 1, 20, 0, 0, 0, time.UTC) }
+		defer func() { businessLocation, now = time.UTC, time.Now }()
+
+		mockRepo, _, _, _, _, _, mockUsecase := setupMocks()
+		customMockLoan := *MockLoan
+		customMockLoan.BillingStartDate = time.Date(2025, 1, 1, 10, 0, 0, 0, businessLocation)
+
+		err := mockUsecase.CreateLoanWithPayments(context.Background(), &customMockLoan)
+
+		assert.ErrorIs(t, err, ErrInvalidBillingStartDate)
+		mockRepo.AssertNotCalled(t, "BeginTx")
+	})
+
 	t.Run("Failed CreateLoan - User Not Found", func(t *testing.T) {
-		mockRepo, mockUserUsecase, _, mockUsecase := setupMocks()
+		mockRepo, mockUserUsecase, _, _, _, _, mockUsecase := setupMocks()
 		mockUserUsecase.On("GetUserByID", mock.Anything, mock.Anything).Return(nil, errors.New(""))
 		err := mockUsecase.CreateLoanWithPayments(context.Background(), MockLoan)
 		assert.Error(t, err)
@@ -156,7 +306,7 @@ func TestCreateLoanWithPayments(t *testing.T) {
 	})
 
 	t.Run("Failed CreateLoan - User Not Eligible", func(t *testing.T) {
-		mockRepo, mockUserUsecase, _, mockUsecase := setupMocks()
+		mockRepo, mockUserUsecase, _, _, _, _, mockUsecase := setupMocks()
 		mockUserUsecase.On("GetUserByID", mock.Anything, mock.Anything).Return(MockUser, nil)
 		mockUserUsecase.On("IsUserDelinquent", mock.Anything, mock.Anything).Return(true, errors.New(""))
 		err := mockUsecase.CreateLoanWithPayments(context.Background(), MockLoan)
@@ -167,9 +317,233 @@ func TestCreateLoanWithPayments(t *testing.T) {
 
 }
 
+func TestApproveLoan(t *testing.T) {
+	officer := entity.WithIdentity(context.Background(), &entity.Identity{Type: entity.IdentityTypeBorrower, Role: entity.RoleCreditOfficer, UserID: 7})
+	now = func() time.Time { return time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	pending := *MockLoan
+	pending.ID = 12
+	pending.Status = entity.LoanStatusPending
+	pending.BillingStartDate = time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Success ApproveLoan", func(t *testing.T) {
+		mockTx := newMockTx(t, true)
+		mockRepo, mockUserUsecase, mockPaymentUsecase, mockAuditUsecase, mockLedgerUsecase, mockEventUsecase, mockUsecase := setupMocks()
+		loan := pending
+		disbursedAt := now()
+
+		mockRepo.On("BeginTx").Return(mockTx, nil)
+		mockRepo.On("GetLoanByIDForUpdate", mockTx, int64(12)).Return(&loan, nil)
+		mockUserUsecase.On("IsUserDelinquent", mock.Anything, int64(1)).Return(false, nil)
+		mockRepo.On("ApproveLoan", mockTx, int64(12), pending.BillingStartDate, disbursedAt).Return(nil)
+		mockRepo.On("UpdateLoanOutstanding", mockTx, mock.AnythingOfType("float64"), int64(12)).Return(nil)
+		mockRepo.On("UpdateVirtualAccount", mockTx, int64(12), "880800000000123", entity.VirtualAccountStatusActive).Return(nil)
+
+		expectedInterest := float64((MockLoan.Amount * (MockLoan.Interest / 100)) / 52)
+		expectedPaymentPayload := []entity.CreatePaymentPayload{{
+			LoanID:      12,
+			DueDate:     entity.DateOf(pending.BillingStartDate, time.UTC).AddDays(7),
+			PaymentNo:   int32(1),
+			Amount:      MockLoan.Amount,
+			Interest:    expectedInterest,
+			TotalAmount: MockLoan.Amount/float64(MockLoan.Tenure) + expectedInterest,
+		}}
+		mockPaymentUsecase.On("CreatePayment", mockTx, expectedPaymentPayload).Return(nil)
+		mockLedgerUsecase.On("PostDisbursement", mock.Anything, mockTx, &loan).Return(nil)
+		mockAuditUsecase.On("Record", mock.Anything, mockTx, entity.AuditActionLoanApprove, entity.AuditEntityLoan, int64(12), mock.Anything, &loan).Return(nil)
+		mockEventUsecase.On("Publish", mock.Anything, mockTx, entity.EventLoanCreated, entity.EventAggregateLoan, int64(12), mock.AnythingOfType("entity.LoanCreatedPayload")).Return(nil)
+
+		approved, err := mockUsecase.ApproveLoan(officer, 12)
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.LoanStatusActive, approved.Status)
+		assert.Equal(t, disbursedAt, *approved.DisbursedAt)
+		assert.Equal(t, "880800000000123", approved.VirtualAccount)
+		assert.True(t, entity.ValidVirtualAccountNumber(approved.VirtualAccount))
+		assert.InDelta(t, MockLoan.Amount+expectedInterest, approved.Outstanding, 1e-9)
+		mockRepo.AssertExpectations(t)
+		mockPaymentUsecase.AssertExpectations(t)
+		mockAuditUsecase.AssertExpectations(t)
+		mockLedgerUsecase.AssertExpectations(t)
+		mockEventUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Success ApproveLoan - Capitalized Grace Interest", func(t *testing.T) {
+		mockTx := newMockTx(t, true)
+		mockRepo, mockUserUsecase, mockPaymentUsecase, mockAuditUsecase, mockLedgerUsecase, mockEventUsecase, mockUsecase := setupMocks()
+		loan := pending
+		loan.Amount = 5200
+		loan.Tenure = 6
+		loan.GracePeriods = 2
+		loan.GraceInterest = entity.GraceInterestCapitalized
+		loan.InterestOnlyPeriods = 1
+
+		var schedule []entity.CreatePaymentPayload
+		mockRepo.On("BeginTx").Return(mockTx, nil)
+		mockRepo.On("GetLoanByIDForUpdate", mockTx, int64(12)).Return(&loan, nil)
+		mockUserUsecase.On("IsUserDelinquent", mock.Anything, mock.Anything).Return(false, nil)
+		mockRepo.On("ApproveLoan", mockTx, int64(12), mock.Anything, mock.Anything).Return(nil)
+		mockRepo.On("UpdateLoanOutstanding", mockTx, mock.AnythingOfType("float64"), int64(12)).Return(nil)
+		mockRepo.On("UpdateVirtualAccount", mockTx, int64(12), mock.Anything, entity.VirtualAccountStatusActive).Return(nil)
+		mockPaymentUsecase.On("CreatePayment", mockTx, mock.Anything).Run(func(args mock.Arguments) {
+			schedule = args.Get(1).([]entity.CreatePaymentPayload)
+		}).Return(nil)
+		mockLedgerUsecase.On("PostDisbursement", mock.Anything, mockTx, &loan).Return(nil)
+		mockLedgerUsecase.On("PostCapitalization", mock.Anything, mockTx, int64(12), entity.JournalReferenceLoan, int64(12), float64(20)).Return(nil)
+		mockAuditUsecase.On("Record", mock.Anything, mockTx, entity.AuditActionLoanApprove, entity.AuditEntityLoan, int64(12), mock.Anything, &loan).Return(nil)
+		mockEventUsecase.On("Publish", mock.Anything, mockTx, entity.EventLoanCreated, entity.EventAggregateLoan, int64(12), mock.AnythingOfType("entity.LoanCreatedPayload")).Return(nil)
+
+		_, err := mockUsecase.ApproveLoan(officer, 12)
+
+		assert.NoError(t, err)
+		assert.Len(t, schedule, 4)
+		assert.Equal(t, int64(12), schedule[0].LoanID)
+		assert.Equal(t, int32(1), schedule[0].PaymentNo)
+		assert.Equal(t, entity.DateOf(loan.BillingStartDate, time.UTC).AddDays(21), schedule[0].DueDate)
+		assert.Zero(t, schedule[0].Amount)
+		assert.InDelta(t, 5220*0.1/52, schedule[0].Interest, 1e-9)
+		assert.InDelta(t, 1740, schedule[1].Amount, 1e-9)
+		assert.InDelta(t, 5220+4*5220*0.1/52, loan.Outstanding, 1e-9)
+		mockRepo.AssertCalled(t, "UpdateLoanOutstanding", mockTx, loan.Outstanding, int64(12))
+		mockLedgerUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Success ApproveLoan - Billing Start Passed", func(t *testing.T) {
+		// approved on Saturday 2025-01-11, after the billing start of Monday 2025-01-06
+		now = func() time.Time { return time.Date(2025, 1, 11, 9, 0, 0, 0, time.UTC) }
+		defer func() { now = func() time.Time { return time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC) } }()
+		mockTx := newMockTx(t, true)
+		mockRepo, mockUserUsecase, mockPaymentUsecase, mockAuditUsecase, mockLedgerUsecase, mockEventUsecase, mockUsecase := setupMocks()
+		mockHolidayUsecase := new(internalMock.MockHolidayUsecase)
+		mockHolidayUsecase.On("Calendar", mock.Anything).Return(calendar.New(entity.BusinessDayFollowing, nil), nil)
+		mockUsecase.holidayUsecase = mockHolidayUsecase
+		loan := pending
+		monday := time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC)
+
+		var schedule []entity.CreatePaymentPayload
+		mockRepo.On("BeginTx").Return(mockTx, nil)
+		mockRepo.On("GetLoanByIDForUpdate", mockTx, int64(12)).Return(&loan, nil)
+		mockUserUsecase.On("IsUserDelinquent", mock.Anything, int64(1)).Return(false, nil)
+		mockRepo.On("ApproveLoan", mockTx, int64(12), monday, now()).Return(nil)
+		mockRepo.On("UpdateLoanOutstanding", mockTx, mock.AnythingOfType("float64"), int64(12)).Return(nil)
+		mockRepo.On("UpdateVirtualAccount", mockTx, int64(12), "880800000000123", entity.VirtualAccountStatusActive).Return(nil)
+		mockPaymentUsecase.On("CreatePayment", mockTx, mock.Anything).Run(func(args mock.Arguments) {
+			schedule = args.Get(1).([]entity.CreatePaymentPayload)
+		}).Return(nil)
+		mockLedgerUsecase.On("PostDisbursement", mock.Anything, mockTx, &loan).Return(nil)
+		mockAuditUsecase.On("Record", mock.Anything, mockTx, entity.AuditActionLoanApprove, entity.AuditEntityLoan, int64(12), mock.Anything, &loan).Return(nil)
+		mockEventUsecase.On("Publish", mock.Anything, mockTx, entity.EventLoanCreated, entity.EventAggregateLoan, int64(12), mock.AnythingOfType("entity.LoanCreatedPayload")).Return(nil)
+
+		approved, err := mockUsecase.ApproveLoan(officer, 12)
+
+		assert.NoError(t, err)
+		assert.Equal(t, monday, approved.BillingStartDate)
+		assert.Equal(t, entity.NewDate(2025, 1, 20), schedule[0].DueDate)
+		mockRepo.AssertExpectations(t)
+		mockLedgerUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Failed ApproveLoan - Not Pending", func(t *testing.T) {
+		mockTx := newMockTx(t, false)
+		mockRepo, _, mockPaymentUsecase, _, mockLedgerUsecase, _, mockUsecase := setupMocks()
+		active := pending
+		active.Status = entity.LoanStatusActive
+
+		mockRepo.On("BeginTx").Return(mockTx, nil)
+		mockRepo.On("GetLoanByIDForUpdate", mockTx, int64(12)).Return(&active, nil)
+
+		approved, err := mockUsecase.ApproveLoan(officer, 12)
+
+		assert.Nil(t, approved)
+		assert.ErrorIs(t, err, ErrLoanNotPending)
+		mockPaymentUsecase.AssertNotCalled(t, "CreatePayment", mock.Anything, mock.Anything)
+		mockLedgerUsecase.AssertNotCalled(t, "PostDisbursement", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failed ApproveLoan - Borrower Became Delinquent", func(t *testing.T) {
+		mockTx := newMockTx(t, false)
+		mockRepo, mockUserUsecase, mockPaymentUsecase, _, _, _, mockUsecase := setupMocks()
+		loan := pending
+
+		mockRepo.On("BeginTx").Return(mockTx, nil)
+		mockRepo.On("GetLoanByIDForUpdate", mockTx, int64(12)).Return(&loan, nil)
+		mockUserUsecase.On("IsUserDelinquent", mock.Anything, int64(1)).Return(true, nil)
+
+		_, err := mockUsecase.ApproveLoan(officer, 12)
+
+		assert.ErrorIs(t, err, ErrUserDelinquent)
+		mockRepo.AssertNotCalled(t, "ApproveLoan", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mockPaymentUsecase.AssertNotCalled(t, "CreatePayment", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failed ApproveLoan - Borrower", func(t *testing.T) {
+		mockRepo, _, _, _, _, _, mockUsecase := setupMocks()
+		borrower := entity.WithIdentity(context.Background(), &entity.Identity{Type: entity.IdentityTypeBorrower, Role: entity.RoleBorrower, UserID: 1})
+
+		_, err := mockUsecase.ApproveLoan(borrower, 12)
+
+		assert.ErrorIs(t, err, ErrForbidden)
+		mockRepo.AssertNotCalled(t, "BeginTx")
+	})
+}
+
+func TestRejectLoan(t *testing.T) {
+	officer := entity.WithIdentity(context.Background(), &entity.Identity{Type: entity.IdentityTypeBorrower, Role: entity.RoleCreditOfficer, UserID: 7})
+
+	pending := *MockLoan
+	pending.ID = 12
+	pending.Status = entity.LoanStatusPending
+
+	t.Run("Success RejectLoan", func(t *testing.T) {
+		mockTx := newMockTx(t, true)
+		mockRepo, _, _, mockAuditUsecase, _, _, mockUsecase := setupMocks()
+		loan := pending
+
+		mockRepo.On("BeginTx").Return(mockTx, nil)
+		mockRepo.On("GetLoanByIDForUpdate", mockTx, int64(12)).Return(&loan, nil)
+		mockRepo.On("RejectLoan", mockTx, int64(12)).Return(nil)
+		mockAuditUsecase.On("Record", mock.Anything, mockTx, entity.AuditActionLoanReject, entity.AuditEntityLoan, int64(12), mock.Anything, &loan).Return(nil)
+
+		rejected, err := mockUsecase.RejectLoan(officer, 12)
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.LoanStatusRejected, rejected.Status)
+		assert.Zero(t, rejected.Outstanding)
+		mockRepo.AssertExpectations(t)
+		mockAuditUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Failed RejectLoan - Not Pending", func(t *testing.T) {
+		mockTx := newMockTx(t, false)
+		mockRepo, _, _, mockAuditUsecase, _, _, mockUsecase := setupMocks()
+		active := pending
+		active.Status = entity.LoanStatusActive
+
+		mockRepo.On("BeginTx").Return(mockTx, nil)
+		mockRepo.On("GetLoanByIDForUpdate", mockTx, int64(12)).Return(&active, nil)
+
+		_, err := mockUsecase.RejectLoan(officer, 12)
+
+		assert.ErrorIs(t, err, ErrLoanNotPending)
+		mockRepo.AssertNotCalled(t, "RejectLoan", mock.Anything, mock.Anything)
+		mockAuditUsecase.AssertNotCalled(t, "Record", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failed RejectLoan - Borrower", func(t *testing.T) {
+		mockRepo, _, _, _, _, _, mockUsecase := setupMocks()
+		borrower := entity.WithIdentity(context.Background(), &entity.Identity{Type: entity.IdentityTypeBorrower, Role: entity.RoleBorrower, UserID: 1})
+
+		_, err := mockUsecase.RejectLoan(borrower, 12)
+
+		assert.ErrorIs(t, err, ErrForbidden)
+		mockRepo.AssertNotCalled(t, "BeginTx")
+	})
+}
+
 func TestGetLoanDuePayments(t *testing.T) {
 	t.Run("Success GetLoanDuePayments", func(t *testing.T) {
-		mockRepo, _, mockPaymentUsecase, mockUsecase := setupMocks()
+		mockRepo, _, mockPaymentUsecase, _, _, _, mockUsecase := setupMocks()
 
 		mockPayments := []*entity.Payment{MockPayment}
 		mockPaymentUsecase.On("GetPaymentsByLoanID", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(mockPayments, nil)
@@ -180,12 +554,28 @@ func TestGetLoanDuePayments(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 
 	})
+
+	t.Run("Success GetLoanDuePayments - Week From The Business Day", func(t *testing.T) {
+		businessLocation = time.FixedZone("WIB", 7*60*60)
+		now = func() time.Time { return time.Date(2025, 1, 1, 20, 0, 0, 0, time.UTC) }
+		defer func() { businessLocation, now = time.UTC, time.Now }()
+
+		_, _, mockPaymentUsecase, _, _, _, mockUsecase := setupMocks()
+		status := entity.PaymentStatusActive
+		dueBefore := entity.NewDate(2025, 1, 9)
+		mockPaymentUsecase.On("GetPaymentsByLoanID", mock.Anything, MockLoan.ID, &status, &dueBefore).Return([]*entity.Payment{}, nil)
+
+		_, err := mockUsecase.GetLoanDuePayments(context.Background(), MockLoan)
+
+		assert.NoError(t, err)
+		mockPaymentUsecase.AssertExpectations(t)
+	})
 }
 
 func TestUpdateLoanOutstanding(t *testing.T) {
 	t.Run("Success UpdateLoanOutstanding", func(t *testing.T) {
 
-		mockRepo, _, _, mockUsecase := setupMocks()
+		mockRepo, _, _, _, _, _, mockUsecase := setupMocks()
 		mockRepo.On("UpdateLoanOutstanding", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 		outstanding := float64(69)
 		err := mockUsecase.UpdateLoanOutstanding(&sql.Tx{}, outstanding, 1)
@@ -196,6 +586,253 @@ func TestUpdateLoanOutstanding(t *testing.T) {
 	})
 }
 
+func TestFlagDelinquentLoans(t *testing.T) {
+	t.Run("Success FlagDelinquentLoans", func(t *testing.T) {
+		mockTx := newMockTx(t, true)
+		mockRepo, _, mockPaymentUsecase, mockAuditUsecase, _, mockEventUsecase, mockUsecase := setupMocks()
+
+		mockTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		now = func() time.Time { return mockTime }
+		defer func() { now = time.Now }()
+
+		flaggedAt := mockTime.AddDate(0, 0, -7)
+		delinquent := &entity.Loan{ID: 1, UserID: 1, Status: entity.LoanStatusActive}
+		current := &entity.Loan{ID: 2, UserID: 1, Status: entity.LoanStatusActive}
+		alreadyFlagged := &entity.Loan{ID: 3, UserID: 1, Status: entity.LoanStatusActive, DelinquentSince: &flaggedAt}
+		paid := &entity.Loan{ID: 4, UserID: 1, Status: entity.LoanStatusPaid}
+
+		mockRepo.On("GetAllLoans", mock.Anything).Return([]*entity.Loan{delinquent, current, alreadyFlagged, paid}, nil)
+		mockPaymentUsecase.On("GetPaymentsByLoanID", mock.Anything, int64(1), mock.Anything, mock.Anything).Return([]*entity.Payment{MockPayment, MockPayment, MockPayment}, nil)
+		mockPaymentUsecase.On("GetPaymentsByLoanID", mock.Anything, int64(2), mock.Anything, mock.Anything).Return([]*entity.Payment{MockPayment, MockPayment}, nil)
+		mockRepo.On("BeginTx").Return(mockTx, nil).Once()
+		mockRepo.On("GetLoanByIDForUpdate", mockTx, int64(1)).Return(delinquent, nil)
+		mockRepo.On("UpdateLoanDelinquency", mockTx, int64(1), &mockTime).Return(nil)
+		mockAuditUsecase.On("Record", mock.Anything, mockTx, entity.AuditActionLoanDelinquent, entity.AuditEntityLoan, int64(1), delinquent, mock.Anything).Return(nil)
+		mockEventUsecase.On("Publish", mock.Anything, mockTx, entity.EventLoanBecameDelinquent, entity.EventAggregateLoan, int64(1), entity.LoanBecameDelinquentPayload{
+			LoanID:      1,
+			UserID:      1,
+			DuePayments: 3,
+			AmountDue:   3 * MockPayment.TotalAmount,
+			Since:       mockTime,
+		}).Return(nil)
+
+		flagged, err := mockUsecase.FlagDelinquentLoans(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, flagged)
+		mockRepo.AssertExpectations(t)
+		mockEventUsecase.AssertExpectations(t)
+		mockPaymentUsecase.AssertNotCalled(t, "GetPaymentsByLoanID", mock.Anything, int64(3), mock.Anything, mock.Anything)
+	})
+}
+
+func TestRestructureLoan(t *testing.T) {
+	mockTime := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return mockTime }
+	defer func() { now = time.Now }()
+
+	today := entity.NewDate(2025, 3, 1)
+	paidAt := time.Date(2025, 1, 25, 0, 0, 0, 0, time.UTC)
+	delinquentSince := time.Date(2025, 2, 20, 0, 0, 0, 0, time.UTC)
+	loan := &entity.Loan{ID: 1, UserID: 1, Interest: 10, Tenure: 4, Outstanding: 660, Status: entity.LoanStatusActive, DelinquentSince: &delinquentSince}
+	payments := []*entity.Payment{
+		{ID: 1, LoanID: 1, PaymentNo: 1, DueDate: entity.NewDate(2025, 1, 25), Amount: 200, Interest: 20, TotalAmount: 220, Status: entity.PaymentStatusPaid, PaidAt: &paidAt},
+		{ID: 2, LoanID: 1, PaymentNo: 2, DueDate: entity.NewDate(2025, 2, 1), Amount: 200, Interest: 20, TotalAmount: 220, Status: entity.PaymentStatusActive},
+		{ID: 3, LoanID: 1, PaymentNo: 3, DueDate: entity.NewDate(2025, 3, 8), Amount: 200, Interest: 20, TotalAmount: 220, Status: entity.PaymentStatusActive},
+		{ID: 4, LoanID: 1, PaymentNo: 4, DueDate: entity.NewDate(2025, 3, 15), Amount: 200, Interest: 20, TotalAmount: 220, Status: entity.PaymentStatusActive},
+	}
+	officer := entity.WithIdentity(context.Background(), &entity.Identity{Type: entity.IdentityTypeBorrower, Role: entity.RoleCreditOfficer, UserID: 7})
+
+	t.Run("Success RestructureLoan - Capitalized Arrears", func(t *testing.T) {
+		mockTx := newMockTx(t, true)
+		mockRepo, _, mockPaymentUsecase, mockAuditUsecase, mockLedgerUsecase, mockEventUsecase, mockUsecase := setupMocks()
+
+		var schedule []entity.CreatePaymentPayload
+		mockPaymentUsecase.On("GetPaymentsByLoanID", mock.Anything, int64(1), (*entity.PaymentStatus)(nil), (*entity.Date)(nil)).Return(payments, nil)
+		mockRepo.On("BeginTx").Return(mockTx, nil)
+		mockRepo.On("GetLoanByIDForUpdate", mockTx, int64(1)).Return(loan, nil)
+		mockPaymentUsecase.On("CloseUnpaidPayments", mockTx, int64(1), mockTime).Return(nil)
+		mockPaymentUsecase.On("CreatePayment", mockTx, mock.Anything).Run(func(args mock.Arguments) {
+			schedule = args.Get(1).([]entity.CreatePaymentPayload)
+		}).Return(nil)
+		mockRepo.On("UpdateLoanOutstanding", mockTx, mock.Anything, int64(1)).Return(nil)
+		mockRepo.On("UpdateLoanTerms", mockTx, int64(1), 5.2, 10).Return(nil)
+		mockRepo.On("UpdateLoanDelinquency", mockTx, int64(1), (*time.Time)(nil)).Return(nil)
+		mockRepo.On("CreateRestructure", mockTx, mock.Anything).Run(func(args mock.Arguments) {
+			args.Get(1).(*entity.LoanRestructure).ID = 5
+		}).Return(nil)
+		mockLedgerUsecase.On("PostCapitalization", mock.Anything, mockTx, int64(1), entity.JournalReferenceRestructure, mock.Anything, float64(20)).Return(nil)
+		mockAuditUsecase.On("Record", mock.Anything, mockTx, entity.AuditActionLoanRestructure, entity.AuditEntityLoan, int64(1), loan, mock.Anything).Return(nil)
+		mockEventUsecase.On("Publish", mock.Anything, mockTx, entity.EventLoanRestructured, entity.EventAggregateLoan, int64(1), mock.AnythingOfType("entity.LoanRestructuredPayload")).Return(nil)
+
+		restructure, err := mockUsecase.RestructureLoan(officer, 1, &entity.RestructureLoanPayload{
+			Interest:          5.2,
+			Tenure:            10,
+			GracePeriods:      2,
+			CapitalizeArrears: true,
+			Reason:            "job loss",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(5), restructure.ID)
+		assert.Equal(t, int64(7), *restructure.ApprovedBy)
+		assert.Equal(t, float64(660), restructure.PreviousOutstanding)
+		assert.Equal(t, float64(20), restructure.CapitalizedArrears)
+		assert.Equal(t, float64(620), restructure.Principal)
+		assert.InDelta(t, 626.2, restructure.Outstanding, 1e-9)
+		assert.Equal(t, int32(5), restructure.FirstPaymentNo)
+
+		assert.Len(t, schedule, 10)
+		assert.Equal(t, int32(5), schedule[0].PaymentNo)
+		assert.Equal(t, today.AddDays(21), schedule[0].DueDate)
+		assert.Equal(t, int32(14), schedule[9].PaymentNo)
+		assert.InDelta(t, 62.62, schedule[0].TotalAmount, 1e-9)
+
+		mockRepo.AssertCalled(t, "UpdateLoanOutstanding", mockTx, restructure.Outstanding, int64(1))
+		mockLedgerUsecase.AssertCalled(t, "PostCapitalization", mock.Anything, mockTx, int64(1), entity.JournalReferenceRestructure, restructure.ID, restructure.CapitalizedArrears)
+		mockRepo.AssertExpectations(t)
+		mockEventUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Success RestructureLoan - Arrears Carried To First Installment", func(t *testing.T) {
+		mockTx := newMockTx(t, true)
+		mockRepo, _, mockPaymentUsecase, mockAuditUsecase, mockLedgerUsecase, mockEventUsecase, mockUsecase := setupMocks()
+
+		current := *loan
+		current.DelinquentSince = nil
+
+		var schedule []entity.CreatePaymentPayload
+		mockPaymentUsecase.On("GetPaymentsByLoanID", mock.Anything, int64(1), (*entity.PaymentStatus)(nil), (*entity.Date)(nil)).Return(payments, nil)
+		mockRepo.On("BeginTx").Return(mockTx, nil)
+		mockRepo.On("GetLoanByIDForUpdate", mockTx, int64(1)).Return(&current, nil)
+		mockPaymentUsecase.On("CloseUnpaidPayments", mockTx, int64(1), mockTime).Return(nil)
+		mockPaymentUsecase.On("CreatePayment", mockTx, mock.Anything).Run(func(args mock.Arguments) {
+			schedule = args.Get(1).([]entity.CreatePaymentPayload)
+		}).Return(nil)
+		mockRepo.On("UpdateLoanOutstanding", mockTx, float64(620), int64(1)).Return(nil)
+		mockRepo.On("UpdateLoanTerms", mockTx, int64(1), float64(0), 6).Return(nil)
+		mockRepo.On("CreateRestructure", mockTx, mock.Anything).Return(nil)
+		mockAuditUsecase.On("Record", mock.Anything, mockTx, entity.AuditActionLoanRestructure, entity.AuditEntityLoan, int64(1), &current, mock.Anything).Return(nil)
+		mockEventUsecase.On("Publish", mock.Anything, mockTx, entity.EventLoanRestructured, entity.EventAggregateLoan, int64(1), mock.AnythingOfType("entity.LoanRestructuredPayload")).Return(nil)
+
+		restructure, err := mockUsecase.RestructureLoan(officer, 1, &entity.RestructureLoanPayload{Tenure: 6, Reason: "illness"})
+
+		assert.NoError(t, err)
+		assert.Zero(t, restructure.CapitalizedArrears)
+		assert.Equal(t, float64(600), restructure.Principal)
+		assert.Len(t, schedule, 6)
+		assert.Equal(t, today.AddDays(7), schedule[0].DueDate)
+		assert.Equal(t, float64(20), schedule[0].Interest)
+		assert.Equal(t, float64(120), schedule[0].TotalAmount)
+		assert.Equal(t, float64(100), schedule[1].TotalAmount)
+		mockRepo.AssertNotCalled(t, "UpdateLoanDelinquency", mock.Anything, mock.Anything, mock.Anything)
+		mockLedgerUsecase.AssertNotCalled(t, "PostCapitalization", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Success RestructureLoan - Keeps Repayment Structure", func(t *testing.T) {
+		mockTx := newMockTx(t, true)
+		mockRepo, _, mockPaymentUsecase, mockAuditUsecase, _, mockEventUsecase, mockUsecase := setupMocks()
+
+		bullet := *loan
+		bullet.DelinquentSince = nil
+		bullet.InterestType = entity.InterestTypeReducingAnnual
+		bullet.RepaymentStructure = entity.RepaymentStructureBullet
+
+		var schedule []entity.CreatePaymentPayload
+		mockPaymentUsecase.On("GetPaymentsByLoanID", mock.Anything, int64(1), (*entity.PaymentStatus)(nil), (*entity.Date)(nil)).Return(payments, nil)
+		mockRepo.On("BeginTx").Return(mockTx, nil)
+		mockRepo.On("GetLoanByIDForUpdate", mockTx, int64(1)).Return(&bullet, nil)
+		mockPaymentUsecase.On("CloseUnpaidPayments", mockTx, int64(1), mockTime).Return(nil)
+		mockPaymentUsecase.On("CreatePayment", mockTx, mock.Anything).Run(func(args mock.Arguments) {
+			schedule = args.Get(1).([]entity.CreatePaymentPayload)
+		}).Return(nil)
+		mockRepo.On("UpdateLoanOutstanding", mockTx, mock.Anything, int64(1)).Return(nil)
+		mockRepo.On("UpdateLoanTerms", mockTx, int64(1), 5.2, 4).Return(nil)
+		mockRepo.On("CreateRestructure", mockTx, mock.Anything).Return(nil)
+		mockAuditUsecase.On("Record", mock.Anything, mockTx, entity.AuditActionLoanRestructure, entity.AuditEntityLoan, int64(1), &bullet, mock.Anything).Return(nil)
+		mockEventUsecase.On("Publish", mock.Anything, mockTx, entity.EventLoanRestructured, entity.EventAggregateLoan, int64(1), mock.AnythingOfType("entity.LoanRestructuredPayload")).Return(nil)
+
+		_, err := mockUsecase.RestructureLoan(officer, 1, &entity.RestructureLoanPayload{Interest: 5.2, Tenure: 4, Reason: "illness"})
+
+		assert.NoError(t, err)
+		if assert.Len(t, schedule, 4) {
+			// the principal is repaid with the last installment, the others only pay the interest on it
+			assert.Zero(t, schedule[0].Amount)
+			assert.Zero(t, schedule[2].Amount)
+			assert.Equal(t, float64(600), schedule[3].Amount)
+			assert.InDelta(t, 0.6, schedule[2].Interest, 1e-9)
+			assert.InDelta(t, 20.6, schedule[0].Interest, 1e-9)
+		}
+	})
+
+	t.Run("Failed RestructureLoan - Bullet Without Interest", func(t *testing.T) {
+		mockTx := newMockTx(t, false)
+		mockRepo, _, mockPaymentUsecase, _, _, _, mockUsecase := setupMocks()
+
+		bullet := *loan
+		bullet.RepaymentStructure = entity.RepaymentStructureBullet
+
+		mockPaymentUsecase.On("GetPaymentsByLoanID", mock.Anything, int64(1), (*entity.PaymentStatus)(nil), (*entity.Date)(nil)).Return(payments, nil)
+		mockRepo.On("BeginTx").Return(mockTx, nil)
+		mockRepo.On("GetLoanByIDForUpdate", mockTx, int64(1)).Return(&bullet, nil)
+
+		_, err := mockUsecase.RestructureLoan(officer, 1, &entity.RestructureLoanPayload{Tenure: 4, Reason: "illness"})
+
+		assert.ErrorIs(t, err, ErrInvalidRepaymentPeriods)
+		mockPaymentUsecase.AssertNotCalled(t, "CloseUnpaidPayments", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failed RestructureLoan - Paid Loan", func(t *testing.T) {
+		mockTx := newMockTx(t, false)
+		mockRepo, _, mockPaymentUsecase, _, _, _, mockUsecase := setupMocks()
+
+		paid := *loan
+		paid.Status = entity.LoanStatusPaid
+
+		mockPaymentUsecase.On("GetPaymentsByLoanID", mock.Anything, int64(1), (*entity.PaymentStatus)(nil), (*entity.Date)(nil)).Return(payments[:1], nil)
+		mockRepo.On("BeginTx").Return(mockTx, nil)
+		mockRepo.On("GetLoanByIDForUpdate", mockTx, int64(1)).Return(&paid, nil)
+
+		restructure, err := mockUsecase.RestructureLoan(officer, 1, &entity.RestructureLoanPayload{Tenure: 6, Reason: "illness"})
+
+		assert.Nil(t, restructure)
+		assert.ErrorIs(t, err, ErrLoanNotRestructurable)
+		mockPaymentUsecase.AssertNotCalled(t, "CloseUnpaidPayments", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failed RestructureLoan - Reads Payments Under The Lock", func(t *testing.T) {
+		mockTx := newMockTx(t, false)
+		mockRepo, _, mockPaymentUsecase, _, _, _, mockUsecase := setupMocks()
+
+		mockRepo.On("BeginTx").Return(mockTx, nil)
+		mockRepo.On("GetLoanByIDForUpdate", mockTx, int64(1)).Return(nil, ErrLoanNotFound)
+
+		_, err := mockUsecase.RestructureLoan(officer, 1, &entity.RestructureLoanPayload{Tenure: 6, Reason: "illness"})
+
+		assert.ErrorIs(t, err, ErrLoanNotFound)
+		mockPaymentUsecase.AssertNotCalled(t, "GetPaymentsByLoanID", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failed RestructureLoan - Missing Reason", func(t *testing.T) {
+		mockRepo, _, _, _, _, _, mockUsecase := setupMocks()
+
+		_, err := mockUsecase.RestructureLoan(officer, 1, &entity.RestructureLoanPayload{Tenure: 6})
+
+		assert.ErrorIs(t, err, validation.ErrValidationFailed)
+		mockRepo.AssertNotCalled(t, "BeginTx")
+	})
+
+	t.Run("Failed RestructureLoan - Borrower", func(t *testing.T) {
+		_, _, _, _, _, _, mockUsecase := setupMocks()
+		borrower := entity.WithIdentity(context.Background(), &entity.Identity{Type: entity.IdentityTypeBorrower, Role: entity.RoleBorrower, UserID: 1})
+
+		_, err := mockUsecase.RestructureLoan(borrower, 1, &entity.RestructureLoanPayload{Tenure: 6, Reason: "illness"})
+
+		assert.ErrorIs(t, err, ErrForbidden)
+	})
+}
+
 // func Test(t *testing.T) {
 // 	t.Run("Success ", func(t *testing.T) {
 
@@ -0,0 +1,403 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"loan-management/internal/entity"
+	"loan-management/internal/i18n"
+	"loan-management/internal/repository"
+	"loan-management/internal/validation"
+	"log"
+	"strconv"
+	"time"
+)
+
+const (
+	notificationBatchSize   = 100
+	notificationMaxAttempts = 3
+)
+
+var ErrNotificationPreferencesNotFound = repository.ErrNotificationPreferencesNotFound
+
+// NotificationChannel delivers messages on one medium, e.g. email or sms
+type NotificationChannel interface {
+	Name() string
+	Send(ctx context.Context, message *entity.NotificationMessage) error
+}
+
+type NotificationUsecaseInterface interface {
+	GetPreferences(ctx context.Context, userID int64) (*entity.NotificationPreferences, error)
+	UpdatePreferences(ctx context.Context, userID int64, payload *entity.UpdateNotificationPreferencesPayload) (*entity.NotificationPreferences, error)
+	GetNotifications(ctx context.Context, filter entity.NotificationFilter) ([]*entity.Notification, error)
+	Remind(ctx context.Context) (*entity.NotificationRun, error)
+}
+
+// NotificationUsecase tells borrowers about their bills. Reminders are sent by the scheduler from the payment due
+// dates, payment receipts follow the domain events. Messages are rendered from the i18n catalogs in the locale of
+// the borrower and logged per channel, so each of them is sent once
+type NotificationUsecase struct {
+	notificationRepo repository.NotificationRepository
+	userRepo         repository.UserRepository
+	loanRepo         repository.LoanRepository
+	paymentRepo      repository.PaymentRepository
+	auditUsecase     AuditUsecaseInterface
+	reminderDays     int
+	channels         map[string]NotificationChannel
+}
+
+func NewNotificationUsecase(notificationRepo repository.NotificationRepository, userRepo repository.UserRepository, loanRepo repository.LoanRepository, paymentRepo repository.PaymentRepository, auditUsecase AuditUsecaseInterface, reminderDays int, channels ...NotificationChannel) *NotificationUsecase {
+	byName := make(map[string]NotificationChannel, len(channels))
+	for _, channel := range channels {
+		byName[channel.Name()] = channel
+	}
+
+	return &NotificationUsecase{
+		notificationRepo: notificationRepo,
+		userRepo:         userRepo,
+		loanRepo:         loanRepo,
+		paymentRepo:      paymentRepo,
+		auditUsecase:     auditUsecase,
+		reminderDays:     reminderDays,
+		channels:         byName,
+	}
+}
+
+func (u *NotificationUsecase) GetPreferences(ctx context.Context, userID int64) (*entity.NotificationPreferences, error) {
+	if err := authorizeOwner(ctx, entity.PermNotificationManage, entity.PermNotificationManageOwn, userID); err != nil {
+		return nil, err
+	}
+
+	user, err := u.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.preferences(ctx, user)
+}
+
+// preferences falls back to email at the account address
+func (u *NotificationUsecase) preferences(ctx context.Context, user *entity.User) (*entity.NotificationPreferences, error) {
+	preferences, err := u.notificationRepo.GetPreferences(ctx, user.ID)
+	if errors.Is(err, ErrNotificationPreferencesNotFound) {
+		return &entity.NotificationPreferences{
+			UserID:     user.ID,
+			Locale:     string(i18n.DefaultLocale),
+			Channels:   []entity.NotificationChannelPreference{{Channel: entity.NotificationChannelEmail, Address: user.Email, Enabled: true}},
+			MutedKinds: []entity.NotificationKind{},
+		}, nil
+	}
+
+	return preferences, err
+}
+
+func (u *NotificationUsecase) UpdatePreferences(ctx context.Context, userID int64, payload *entity.UpdateNotificationPreferencesPayload) (preferences *entity.NotificationPreferences, err error) {
+	before, err := u.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validation.Struct(payload); err != nil {
+		return nil, err
+	}
+
+	updatedAt := now()
+	preferences = &entity.NotificationPreferences{
+		UserID:     userID,
+		Locale:     payload.Locale,
+		Channels:   payload.Channels,
+		MutedKinds: payload.MutedKinds,
+		UpdatedAt:  &updatedAt,
+	}
+	if preferences.Channels == nil {
+		preferences.Channels = []entity.NotificationChannelPreference{}
+	}
+	if preferences.MutedKinds == nil {
+		preferences.MutedKinds = []entity.NotificationKind{}
+	}
+
+	tx, err := u.notificationRepo.BeginTx()
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = u.notificationRepo.SavePreferences(tx, preferences); err != nil {
+		return nil, err
+	}
+
+	if err = u.auditUsecase.Record(ctx, tx, entity.AuditActionPreferencesUpdate, entity.AuditEntityNotificationPreferences, userID, before, preferences); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return preferences, nil
+}
+
+// GetNotifications lists the sent-log, borrowers only get their own notifications
+func (u *NotificationUsecase) GetNotifications(ctx context.Context, filter entity.NotificationFilter) ([]*entity.Notification, error) {
+	if identity := entity.IdentityFromContext(ctx); identity != nil && !identity.Can(entity.PermNotificationRead) {
+		if !identity.Can(entity.PermNotificationReadOwn) {
+			return nil, ErrForbidden
+		}
+		filter.UserID = identity.UserID
+	}
+
+	if err := validation.Struct(filter); err != nil {
+		return nil, err
+	}
+
+	return u.notificationRepo.GetNotifications(ctx, filter)
+}
+
+// Run sends the reminders every interval until ctx is done
+func (u *NotificationUsecase) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := u.Remind(ctx); err != nil {
+			log.Printf("Failed to send reminders: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Remind sends a reminder for every installment falling due from today to the reminder days, an overdue notice
+// when another installment of a loan went past its due date, and resends the notifications that failed
+func (u *NotificationUsecase) Remind(ctx context.Context) (*entity.NotificationRun, error) {
+	if err := authorize(ctx, entity.PermNotificationManage); err != nil {
+		return nil, err
+	}
+
+	run := &entity.NotificationRun{}
+	loans := map[int64]*entity.Loan{}
+
+	// a bill is overdue only after its due date, so the ones due today are still reminded
+	upcoming, err := u.paymentRepo.GetUnpaidPaymentsDueBetween(ctx, today().AddDays(-1), today().AddDays(u.reminderDays))
+	if err != nil {
+		return run, err
+	}
+
+	for _, payment := range upcoming {
+		loan, err := u.loan(ctx, loans, payment.LoanID)
+		if err != nil {
+			return run, err
+		}
+
+		params := map[string]string{
+			"loan":       loan.Reference(),
+			"payment_no": strconv.Itoa(int(payment.PaymentNo)),
+			"amount":     formatAmount(payment.TotalAmount),
+			"due_date":   payment.DueDate.String(),
+		}
+		if err := u.notify(ctx, run, loan, entity.NotificationUpcomingDue, payment.ID, params); err != nil {
+			return run, err
+		}
+	}
+
+	overdue, err := u.paymentRepo.GetUnpaidPaymentsDueBetween(ctx, entity.Date{}, today().AddDays(-1))
+	if err != nil {
+		return run, err
+	}
+
+	// one notice per loan with everything past due, sent again when another installment goes past due
+	byLoan := map[int64][]*entity.Payment{}
+	var loanIDs []int64
+	for _, payment := range overdue {
+		if _, ok := byLoan[payment.LoanID]; !ok {
+			loanIDs = append(loanIDs, payment.LoanID)
+		}
+		byLoan[payment.LoanID] = append(byLoan[payment.LoanID], payment)
+	}
+
+	for _, loanID := range loanIDs {
+		loan, err := u.loan(ctx, loans, loanID)
+		if err != nil {
+			return run, err
+		}
+
+		payments := byLoan[loanID]
+		var amount float64
+		for _, payment := range payments {
+			amount += payment.TotalAmount
+		}
+		latest := payments[len(payments)-1]
+
+		params := map[string]string{
+			"loan":     loan.Reference(),
+			"count":    strconv.Itoa(len(payments)),
+			"amount":   formatAmount(amount),
+			"due_date": latest.DueDate.String(),
+		}
+		if err := u.notify(ctx, run, loan, entity.NotificationOverdue, latest.ID, params); err != nil {
+			return run, err
+		}
+	}
+
+	failed, err := u.notificationRepo.GetFailedNotifications(ctx, notificationMaxAttempts, notificationBatchSize)
+	if err != nil {
+		return run, err
+	}
+
+	for _, notification := range failed {
+		channel, ok := u.channels[notification.Channel]
+		if !ok {
+			continue
+		}
+		if err := u.send(ctx, run, channel, notification); err != nil {
+			return run, err
+		}
+	}
+
+	return run, nil
+}
+
+func (u *NotificationUsecase) loan(ctx context.Context, loans map[int64]*entity.Loan, loanID int64) (*entity.Loan, error) {
+	if loan, ok := loans[loanID]; ok {
+		return loan, nil
+	}
+
+	loan, err := u.loanRepo.GetLoanByID(ctx, loanID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if loan == nil {
+		return nil, ErrLoanNotFound
+	}
+
+	loans[loanID] = loan
+	return loan, nil
+}
+
+// OnPaymentPosted thanks the borrower for a payment, it subscribes to EventPaymentPosted
+func (u *NotificationUsecase) OnPaymentPosted(ctx context.Context, event *entity.Event) error {
+	var payload entity.PaymentPostedPayload
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return err
+	}
+
+	params := map[string]string{
+		"loan":        entity.Loan{ID: payload.LoanID}.Reference(),
+		"amount":      formatAmount(payload.Amount),
+		"outstanding": formatAmount(payload.Outstanding),
+	}
+	loan := &entity.Loan{ID: payload.LoanID, UserID: payload.UserID}
+	return u.notify(ctx, &entity.NotificationRun{}, loan, entity.NotificationPaymentReceived, payload.TransactionID, params)
+}
+
+// OnLoanPaidOff congratulates the borrower, it subscribes to EventLoanPaidOff
+func (u *NotificationUsecase) OnLoanPaidOff(ctx context.Context, event *entity.Event) error {
+	var payload entity.LoanPaidOffPayload
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return err
+	}
+
+	params := map[string]string{
+		"loan": entity.Loan{ID: payload.LoanID}.Reference(),
+	}
+	loan := &entity.Loan{ID: payload.LoanID, UserID: payload.UserID}
+	return u.notify(ctx, &entity.NotificationRun{}, loan, entity.NotificationLoanPaidOff, payload.TransactionID, params)
+}
+
+// notify sends the kind of notification about ref on every channel the borrower enabled, unless it was sent
+// before. Only failing to read or log a notification is returned as an error
+func (u *NotificationUsecase) notify(ctx context.Context, run *entity.NotificationRun, loan *entity.Loan, kind entity.NotificationKind, ref int64, params map[string]string) error {
+	user, err := u.userRepo.GetUserByID(ctx, loan.UserID)
+	if err != nil {
+		return err
+	}
+
+	preferences, err := u.preferences(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	if preferences.Mutes(kind) {
+		return nil
+	}
+
+	params["name"] = user.Name
+	locale := i18n.Locale(preferences.Locale)
+
+	for _, preference := range preferences.Channels {
+		channel, ok := u.channels[preference.Channel]
+		if !preference.Enabled || !ok {
+			continue
+		}
+
+		dedupeKey := fmt.Sprintf("%s:%d:%s", kind, ref, preference.Channel)
+		_, err := u.notificationRepo.GetNotificationByDedupeKey(ctx, dedupeKey)
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, repository.ErrNotificationNotFound) {
+			return err
+		}
+
+		notification := &entity.Notification{
+			UserID:    user.ID,
+			LoanID:    loan.ID,
+			Kind:      kind,
+			Channel:   preference.Channel,
+			Address:   preference.Address,
+			DedupeKey: dedupeKey,
+			Subject:   i18n.T(locale, "notification."+string(kind)+".subject", params),
+			Body:      i18n.T(locale, "notification."+string(kind)+".body", params),
+			Status:    entity.NotificationStatusPending,
+			CreatedAt: now(),
+		}
+		if err := u.notificationRepo.CreateNotification(ctx, notification); err != nil {
+			return err
+		}
+
+		if err := u.send(ctx, run, channel, notification); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// send delivers the notification and saves the outcome, only failing to save is returned as an error
+func (u *NotificationUsecase) send(ctx context.Context, run *entity.NotificationRun, channel NotificationChannel, notification *entity.Notification) error {
+	notification.Attempts++
+
+	err := channel.Send(ctx, &entity.NotificationMessage{
+		Kind:    notification.Kind,
+		To:      notification.Address,
+		Subject: notification.Subject,
+		Body:    notification.Body,
+	})
+	if err != nil {
+		notification.Status = entity.NotificationStatusFailed
+		notification.LastError = err.Error()
+		run.Failed++
+	} else {
+		sentAt := now()
+		notification.Status = entity.NotificationStatusSent
+		notification.LastError = ""
+		notification.SentAt = &sentAt
+		run.Sent++
+	}
+
+	return u.notificationRepo.UpdateNotification(ctx, notification)
+}
+
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
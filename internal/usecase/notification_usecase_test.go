@@ -0,0 +1,305 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"loan-management/internal/entity"
+	internalMock "loan-management/internal/mock"
+	"loan-management/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// recordingChannel keeps every message it is asked to send, failing them when err is set
+type recordingChannel struct {
+	name     string
+	err      error
+	messages []*entity.NotificationMessage
+}
+
+func (c *recordingChannel) Name() string { return c.name }
+
+func (c *recordingChannel) Send(ctx context.Context, message *entity.NotificationMessage) error {
+	c.messages = append(c.messages, message)
+	return c.err
+}
+
+func setupNotificationMocks(channels ...NotificationChannel) (*NotificationUsecase, *internalMock.MockNotificationRepository, *internalMock.MockUserRepository, *internalMock.MockLoanRepository, *internalMock.MockPaymentRepository, *internalMock.MockAuditUsecase) {
+	mockNotificationRepo := new(internalMock.MockNotificationRepository)
+	mockUserRepo := new(internalMock.MockUserRepository)
+	mockLoanRepo := new(internalMock.MockLoanRepository)
+	mockPaymentRepo := new(internalMock.MockPaymentRepository)
+	mockAudit := new(internalMock.MockAuditUsecase)
+
+	notificationUsecase := NewNotificationUsecase(mockNotificationRepo, mockUserRepo, mockLoanRepo, mockPaymentRepo, mockAudit, 3, channels...)
+
+	return notificationUsecase, mockNotificationRepo, mockUserRepo, mockLoanRepo, mockPaymentRepo, mockAudit
+}
+
+func TestNotificationRemind(t *testing.T) {
+	mockTime := time.Date(2025, 1, 10, 8, 0, 0, 0, time.UTC)
+	now = func() time.Time { return mockTime }
+	defer func() { now = time.Now }()
+	today := entity.NewDate(2025, 1, 10)
+
+	user := &entity.User{ID: 1, Name: "Budi", Email: "budi@example.com"}
+	loan := &entity.Loan{ID: 7, UserID: 1}
+	upcoming := []*entity.Payment{{ID: 21, LoanID: 7, PaymentNo: 3, DueDate: entity.NewDate(2025, 1, 12), TotalAmount: 250}}
+	overdue := []*entity.Payment{
+		{ID: 19, LoanID: 7, PaymentNo: 1, DueDate: entity.NewDate(2024, 12, 29), TotalAmount: 250},
+		{ID: 20, LoanID: 7, PaymentNo: 2, DueDate: entity.NewDate(2025, 1, 5), TotalAmount: 250.5},
+	}
+
+	t.Run("Success Remind - Upcoming And Overdue", func(t *testing.T) {
+		email := &recordingChannel{name: entity.NotificationChannelEmail}
+		notificationUsecase, mockNotificationRepo, mockUserRepo, mockLoanRepo, mockPaymentRepo, _ := setupNotificationMocks(email)
+
+		mockPaymentRepo.On("GetUnpaidPaymentsDueBetween", mock.Anything, today.AddDays(-1), today.AddDays(3)).Return(upcoming, nil)
+		mockPaymentRepo.On("GetUnpaidPaymentsDueBetween", mock.Anything, entity.Date{}, today.AddDays(-1)).Return(overdue, nil)
+		mockLoanRepo.On("GetLoanByID", mock.Anything, int64(7), (*entity.LoanStatus)(nil)).Return(loan, nil).Once()
+		mockUserRepo.On("GetUserByID", mock.Anything, int64(1)).Return(user, nil)
+		mockNotificationRepo.On("GetPreferences", mock.Anything, int64(1)).Return(nil, ErrNotificationPreferencesNotFound)
+		mockNotificationRepo.On("GetNotificationByDedupeKey", mock.Anything, mock.Anything).Return(nil, repository.ErrNotificationNotFound)
+		mockNotificationRepo.On("CreateNotification", mock.Anything, mock.Anything).Return(nil)
+		mockNotificationRepo.On("UpdateNotification", mock.Anything, mock.Anything).Return(nil)
+		mockNotificationRepo.On("GetFailedNotifications", mock.Anything, notificationMaxAttempts, notificationBatchSize).Return([]*entity.Notification{}, nil)
+
+		run, err := notificationUsecase.Remind(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, &entity.NotificationRun{Sent: 2}, run)
+		assert.Len(t, email.messages, 2)
+		assert.Equal(t, "budi@example.com", email.messages[0].To)
+		assert.Equal(t, "Installment 3 of loan LOAN-7 is due on 2025-01-12", email.messages[0].Subject)
+		assert.Equal(t, entity.NotificationOverdue, email.messages[1].Kind)
+		assert.Contains(t, email.messages[1].Body, "2 overdue installment(s) totalling 500.50")
+		mockNotificationRepo.AssertCalled(t, "GetNotificationByDedupeKey", mock.Anything, "upcoming_due:21:email")
+		mockNotificationRepo.AssertCalled(t, "GetNotificationByDedupeKey", mock.Anything, "overdue:20:email")
+		mockLoanRepo.AssertExpectations(t)
+	})
+
+	t.Run("Success Remind - Due Today Is Not Overdue", func(t *testing.T) {
+		email := &recordingChannel{name: entity.NotificationChannelEmail}
+		notificationUsecase, mockNotificationRepo, mockUserRepo, mockLoanRepo, mockPaymentRepo, _ := setupNotificationMocks(email)
+		payments := []*entity.Payment{
+			{ID: 30, LoanID: 7, PaymentNo: 1, DueDate: today.AddDays(-1), TotalAmount: 250},
+			{ID: 31, LoanID: 7, PaymentNo: 2, DueDate: today, TotalAmount: 250},
+			{ID: 32, LoanID: 7, PaymentNo: 3, DueDate: today.AddDays(3), TotalAmount: 250},
+			{ID: 33, LoanID: 7, PaymentNo: 4, DueDate: today.AddDays(4), TotalAmount: 250},
+		}
+
+		// the repository bounds: due after `after`, a zero one unbounded, and up to `until`
+		var dueBetween *mock.Call
+		dueBetween = mockPaymentRepo.On("GetUnpaidPaymentsDueBetween", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			after, until := args.Get(1).(entity.Date), args.Get(2).(entity.Date)
+			due := []*entity.Payment{}
+			for _, payment := range payments {
+				if (after.IsZero() || payment.DueDate.After(after)) && !payment.DueDate.After(until) {
+					due = append(due, payment)
+				}
+			}
+			dueBetween.ReturnArguments = mock.Arguments{due, nil}
+		})
+		mockLoanRepo.On("GetLoanByID", mock.Anything, int64(7), (*entity.LoanStatus)(nil)).Return(loan, nil)
+		mockUserRepo.On("GetUserByID", mock.Anything, int64(1)).Return(user, nil)
+		mockNotificationRepo.On("GetPreferences", mock.Anything, int64(1)).Return(nil, ErrNotificationPreferencesNotFound)
+		mockNotificationRepo.On("GetNotificationByDedupeKey", mock.Anything, mock.Anything).Return(nil, repository.ErrNotificationNotFound)
+		mockNotificationRepo.On("CreateNotification", mock.Anything, mock.Anything).Return(nil)
+		mockNotificationRepo.On("UpdateNotification", mock.Anything, mock.Anything).Return(nil)
+		mockNotificationRepo.On("GetFailedNotifications", mock.Anything, notificationMaxAttempts, notificationBatchSize).Return([]*entity.Notification{}, nil)
+
+		run, err := notificationUsecase.Remind(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, &entity.NotificationRun{Sent: 3}, run)
+		assert.Equal(t, "Installment 2 of loan LOAN-7 is due on 2025-01-10", email.messages[0].Subject)
+		assert.Equal(t, "Installment 3 of loan LOAN-7 is due on 2025-01-13", email.messages[1].Subject)
+		assert.Equal(t, entity.NotificationOverdue, email.messages[2].Kind)
+		assert.Contains(t, email.messages[2].Body, "1 overdue installment(s) totalling 250.00")
+		mockNotificationRepo.AssertCalled(t, "GetNotificationByDedupeKey", mock.Anything, "overdue:30:email")
+		mockNotificationRepo.AssertNotCalled(t, "GetNotificationByDedupeKey", mock.Anything, "overdue:31:email")
+	})
+
+	t.Run("Success Remind - Already Sent", func(t *testing.T) {
+		email := &recordingChannel{name: entity.NotificationChannelEmail}
+		notificationUsecase, mockNotificationRepo, mockUserRepo, mockLoanRepo, mockPaymentRepo, _ := setupNotificationMocks(email)
+
+		mockPaymentRepo.On("GetUnpaidPaymentsDueBetween", mock.Anything, today.AddDays(-1), today.AddDays(3)).Return(upcoming, nil)
+		mockPaymentRepo.On("GetUnpaidPaymentsDueBetween", mock.Anything, entity.Date{}, today.AddDays(-1)).Return([]*entity.Payment{}, nil)
+		mockLoanRepo.On("GetLoanByID", mock.Anything, int64(7), (*entity.LoanStatus)(nil)).Return(loan, nil)
+		mockUserRepo.On("GetUserByID", mock.Anything, int64(1)).Return(user, nil)
+		mockNotificationRepo.On("GetPreferences", mock.Anything, int64(1)).Return(nil, ErrNotificationPreferencesNotFound)
+		mockNotificationRepo.On("GetNotificationByDedupeKey", mock.Anything, "upcoming_due:21:email").Return(&entity.Notification{ID: 3}, nil)
+		mockNotificationRepo.On("GetFailedNotifications", mock.Anything, notificationMaxAttempts, notificationBatchSize).Return([]*entity.Notification{}, nil)
+
+		run, err := notificationUsecase.Remind(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, &entity.NotificationRun{}, run)
+		assert.Empty(t, email.messages)
+		mockNotificationRepo.AssertNotCalled(t, "CreateNotification", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Success Remind - Preferences", func(t *testing.T) {
+		email := &recordingChannel{name: entity.NotificationChannelEmail}
+		sms := &recordingChannel{name: entity.NotificationChannelSMS}
+		notificationUsecase, mockNotificationRepo, mockUserRepo, mockLoanRepo, mockPaymentRepo, _ := setupNotificationMocks(email, sms)
+
+		preferences := &entity.NotificationPreferences{
+			UserID: 1,
+			Locale: "id",
+			Channels: []entity.NotificationChannelPreference{
+				{Channel: entity.NotificationChannelEmail, Address: "budi@example.com", Enabled: false},
+				{Channel: entity.NotificationChannelSMS, Address: "+628123", Enabled: true},
+				{Channel: entity.NotificationChannelPush, Address: "device-1", Enabled: true},
+			},
+			MutedKinds: []entity.NotificationKind{entity.NotificationOverdue},
+		}
+
+		mockPaymentRepo.On("GetUnpaidPaymentsDueBetween", mock.Anything, today.AddDays(-1), today.AddDays(3)).Return(upcoming, nil)
+		mockPaymentRepo.On("GetUnpaidPaymentsDueBetween", mock.Anything, entity.Date{}, today.AddDays(-1)).Return(overdue, nil)
+		mockLoanRepo.On("GetLoanByID", mock.Anything, int64(7), (*entity.LoanStatus)(nil)).Return(loan, nil)
+		mockUserRepo.On("GetUserByID", mock.Anything, int64(1)).Return(user, nil)
+		mockNotificationRepo.On("GetPreferences", mock.Anything, int64(1)).Return(preferences, nil)
+		mockNotificationRepo.On("GetNotificationByDedupeKey", mock.Anything, "upcoming_due:21:sms").Return(nil, repository.ErrNotificationNotFound)
+		mockNotificationRepo.On("CreateNotification", mock.Anything, mock.Anything).Return(nil)
+		mockNotificationRepo.On("UpdateNotification", mock.Anything, mock.Anything).Return(nil)
+		mockNotificationRepo.On("GetFailedNotifications", mock.Anything, notificationMaxAttempts, notificationBatchSize).Return([]*entity.Notification{}, nil)
+
+		run, err := notificationUsecase.Remind(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, run.Sent)
+		assert.Empty(t, email.messages)
+		assert.Len(t, sms.messages, 1)
+		assert.Equal(t, "+628123", sms.messages[0].To)
+		assert.Equal(t, "Angsuran 3 pinjaman LOAN-7 jatuh tempo pada 2025-01-12", sms.messages[0].Subject)
+	})
+
+	t.Run("Success Remind - Failed Send Is Logged And Resent", func(t *testing.T) {
+		email := &recordingChannel{name: entity.NotificationChannelEmail, err: errors.New("connection refused")}
+		notificationUsecase, mockNotificationRepo, _, _, mockPaymentRepo, _ := setupNotificationMocks(email)
+
+		failed := &entity.Notification{ID: 5, Kind: entity.NotificationOverdue, Channel: entity.NotificationChannelEmail, Address: "budi@example.com", Status: entity.NotificationStatusFailed, Attempts: 1}
+
+		mockPaymentRepo.On("GetUnpaidPaymentsDueBetween", mock.Anything, mock.Anything, mock.Anything).Return([]*entity.Payment{}, nil)
+		mockNotificationRepo.On("GetFailedNotifications", mock.Anything, notificationMaxAttempts, notificationBatchSize).Return([]*entity.Notification{failed}, nil)
+		mockNotificationRepo.On("UpdateNotification", mock.Anything, failed).Return(nil)
+
+		run, err := notificationUsecase.Remind(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, &entity.NotificationRun{Failed: 1}, run)
+		assert.Equal(t, 2, failed.Attempts)
+		assert.Equal(t, "connection refused", failed.LastError)
+		assert.Nil(t, failed.SentAt)
+	})
+
+	t.Run("Failed Remind - Forbidden", func(t *testing.T) {
+		notificationUsecase, _, _, _, mockPaymentRepo, _ := setupNotificationMocks()
+
+		borrower := entity.WithIdentity(context.Background(), &entity.Identity{Type: entity.IdentityTypeBorrower, Role: entity.RoleBorrower, UserID: 1})
+		run, err := notificationUsecase.Remind(borrower)
+
+		assert.Nil(t, run)
+		assert.ErrorIs(t, err, ErrForbidden)
+		mockPaymentRepo.AssertNotCalled(t, "GetUnpaidPaymentsDueBetween", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestNotificationOnPaymentPosted(t *testing.T) {
+	email := &recordingChannel{name: entity.NotificationChannelEmail}
+	notificationUsecase, mockNotificationRepo, mockUserRepo, _, _, _ := setupNotificationMocks(email)
+
+	payload, _ := json.Marshal(entity.PaymentPostedPayload{LoanID: 7, UserID: 1, TransactionID: 40, Amount: 250, Outstanding: 750})
+	event := &entity.Event{Type: entity.EventPaymentPosted, Payload: payload}
+
+	mockUserRepo.On("GetUserByID", mock.Anything, int64(1)).Return(&entity.User{ID: 1, Name: "Budi", Email: "budi@example.com"}, nil)
+	mockNotificationRepo.On("GetPreferences", mock.Anything, int64(1)).Return(nil, ErrNotificationPreferencesNotFound)
+	mockNotificationRepo.On("GetNotificationByDedupeKey", mock.Anything, "payment_received:40:email").Return(nil, repository.ErrNotificationNotFound)
+	mockNotificationRepo.On("CreateNotification", mock.Anything, mock.MatchedBy(func(notification *entity.Notification) bool {
+		return notification.LoanID == 7 && notification.Status == entity.NotificationStatusPending
+	})).Return(nil)
+	mockNotificationRepo.On("UpdateNotification", mock.Anything, mock.MatchedBy(func(notification *entity.Notification) bool {
+		return notification.Status == entity.NotificationStatusSent && notification.Attempts == 1
+	})).Return(nil)
+
+	err := notificationUsecase.OnPaymentPosted(context.Background(), event)
+
+	assert.NoError(t, err)
+	assert.Len(t, email.messages, 1)
+	assert.Contains(t, email.messages[0].Body, "payment of 250.00 for loan LOAN-7. Your outstanding balance is now 750.00")
+	mockNotificationRepo.AssertExpectations(t)
+}
+
+func TestUpdateNotificationPreferences(t *testing.T) {
+	borrower := entity.WithIdentity(context.Background(), &entity.Identity{Type: entity.IdentityTypeBorrower, Role: entity.RoleBorrower, UserID: 1})
+	payload := &entity.UpdateNotificationPreferencesPayload{
+		Locale:     "id",
+		Channels:   []entity.NotificationChannelPreference{{Channel: entity.NotificationChannelSMS, Address: "+628123", Enabled: true}},
+		MutedKinds: []entity.NotificationKind{entity.NotificationLoanPaidOff},
+	}
+
+	t.Run("Success UpdatePreferences - Own Preferences", func(t *testing.T) {
+		notificationUsecase, mockNotificationRepo, mockUserRepo, _, _, mockAudit := setupNotificationMocks()
+
+		mockUserRepo.On("GetUserByID", mock.Anything, int64(1)).Return(&entity.User{ID: 1, Email: "budi@example.com"}, nil)
+		mockNotificationRepo.On("GetPreferences", mock.Anything, int64(1)).Return(nil, ErrNotificationPreferencesNotFound)
+		mockNotificationRepo.On("BeginTx").Return(newMockTx(t, true), nil)
+		mockNotificationRepo.On("SavePreferences", mock.Anything, mock.Anything).Return(nil)
+		mockAudit.On("Record", mock.Anything, mock.Anything, entity.AuditActionPreferencesUpdate, entity.AuditEntityNotificationPreferences, int64(1), mock.Anything, mock.Anything).Return(nil)
+
+		preferences, err := notificationUsecase.UpdatePreferences(borrower, 1, payload)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "id", preferences.Locale)
+		assert.True(t, preferences.Mutes(entity.NotificationLoanPaidOff))
+		mockAudit.AssertExpectations(t)
+	})
+
+	t.Run("Failed UpdatePreferences - Another Borrower", func(t *testing.T) {
+		notificationUsecase, mockNotificationRepo, _, _, _, _ := setupNotificationMocks()
+
+		preferences, err := notificationUsecase.UpdatePreferences(borrower, 2, payload)
+
+		assert.Nil(t, preferences)
+		assert.ErrorIs(t, err, ErrForbidden)
+		mockNotificationRepo.AssertNotCalled(t, "BeginTx")
+	})
+
+	t.Run("Failed UpdatePreferences - Unknown Channel", func(t *testing.T) {
+		notificationUsecase, mockNotificationRepo, mockUserRepo, _, _, _ := setupNotificationMocks()
+
+		mockUserRepo.On("GetUserByID", mock.Anything, int64(1)).Return(&entity.User{ID: 1, Email: "budi@example.com"}, nil)
+		mockNotificationRepo.On("GetPreferences", mock.Anything, int64(1)).Return(nil, ErrNotificationPreferencesNotFound)
+
+		invalid := &entity.UpdateNotificationPreferencesPayload{
+			Locale:   "en",
+			Channels: []entity.NotificationChannelPreference{{Channel: "fax", Address: "123", Enabled: true}},
+		}
+		preferences, err := notificationUsecase.UpdatePreferences(borrower, 1, invalid)
+
+		assert.Nil(t, preferences)
+		assert.Error(t, err)
+		mockNotificationRepo.AssertNotCalled(t, "BeginTx")
+	})
+}
+
+func TestGetNotifications(t *testing.T) {
+	t.Run("Success GetNotifications - Borrower Sees Own", func(t *testing.T) {
+		notificationUsecase, mockNotificationRepo, _, _, _, _ := setupNotificationMocks()
+
+		borrower := entity.WithIdentity(context.Background(), &entity.Identity{Type: entity.IdentityTypeBorrower, Role: entity.RoleBorrower, UserID: 1})
+		mockNotificationRepo.On("GetNotifications", mock.Anything, entity.NotificationFilter{UserID: 1}).Return([]*entity.Notification{{ID: 1, UserID: 1}}, nil)
+
+		notifications, err := notificationUsecase.GetNotifications(borrower, entity.NotificationFilter{UserID: 2})
+
+		assert.NoError(t, err)
+		assert.Len(t, notifications, 1)
+		mockNotificationRepo.AssertExpectations(t)
+	})
+}
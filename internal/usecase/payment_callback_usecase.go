@@ -0,0 +1,219 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"loan-management/internal/apperror"
+	"loan-management/internal/entity"
+	"loan-management/internal/repository"
+	"loan-management/internal/validation"
+	"math"
+	"net/http"
+)
+
+var (
+	ErrPaymentProviderNotFound  = apperror.NotFound("PAYMENT_PROVIDER_NOT_FOUND", "Payment provider not found")
+	ErrInvalidCallbackSignature = apperror.Unauthorized("INVALID_CALLBACK_SIGNATURE", "Invalid callback signature")
+	ErrInvalidCallbackPayload   = apperror.Validation("INVALID_CALLBACK_PAYLOAD", "Callback payload can't be parsed")
+	ErrUnknownLoanReference     = apperror.NotFound("UNKNOWN_LOAN_REFERENCE", "No loan matches the payment reference")
+)
+
+// PaymentProvider adapts the callbacks of one payment gateway
+type PaymentProvider interface {
+	Name() string
+	// VerifySignature checks the callback was sent by the provider, body is the raw request body
+	VerifySignature(headers http.Header, body []byte) bool
+	ParseCallback(body []byte) (*entity.GatewayPayment, error)
+}
+
+type PaymentCallbackUsecaseInterface interface {
+	HandleCallback(ctx context.Context, providerName string, headers http.Header, body []byte) (*entity.PaymentCallback, error)
+	GetPaymentCallbacks(ctx context.Context, filter entity.PaymentCallbackFilter) ([]*entity.PaymentCallback, error)
+	GetPaymentCallbackByID(ctx context.Context, id int64) (*entity.PaymentCallback, error)
+}
+
+type PaymentCallbackUsecase struct {
+	callbackRepo       repository.PaymentCallbackRepository
+	transactionUsecase TransactionUsecaseInterface
+	providers          map[string]PaymentProvider
+}
+
+func NewPaymentCallbackUsecase(callbackRepo repository.PaymentCallbackRepository, transactionUsecase TransactionUsecaseInterface, providers ...PaymentProvider) *PaymentCallbackUsecase {
+	byName := make(map[string]PaymentProvider, len(providers))
+	for _, provider := range providers {
+		byName[provider.Name()] = provider
+	}
+
+	return &PaymentCallbackUsecase{
+		callbackRepo:       callbackRepo,
+		transactionUsecase: transactionUsecase,
+		providers:          byName,
+	}
+}
+
+// HandleCallback stores the raw callback, verifies it and posts a paid payment through the transaction flow.
+// A payment is posted once per provider payment ID, repeated callbacks are recorded as duplicates.
+// Only a bad signature, an unparsable payload or an internal failure return an error, payments that can't be
+// matched to a due bill are recorded as unmatched so the provider stops retrying
+func (u *PaymentCallbackUsecase) HandleCallback(ctx context.Context, providerName string, headers http.Header, body []byte) (*entity.PaymentCallback, error) {
+	provider, ok := u.providers[providerName]
+	if !ok {
+		return nil, ErrPaymentProviderNotFound
+	}
+
+	rawHeaders, err := json.Marshal(headers)
+	if err != nil {
+		return nil, err
+	}
+
+	callback := &entity.PaymentCallback{
+		Provider:   providerName,
+		Status:     entity.PaymentCallbackStatusReceived,
+		Headers:    rawHeaders,
+		Payload:    string(body),
+		ReceivedAt: now(),
+	}
+	if err := u.callbackRepo.CreatePaymentCallback(ctx, callback); err != nil {
+		return nil, err
+	}
+
+	if !provider.VerifySignature(headers, body) {
+		return nil, u.reject(ctx, callback, ErrInvalidCallbackSignature)
+	}
+
+	payment, err := provider.ParseCallback(body)
+	if err != nil {
+		return nil, u.reject(ctx, callback, ErrInvalidCallbackPayload.Wrap(err))
+	}
+
+	callback.ExternalID = payment.ExternalID
+	callback.Reference = payment.Reference
+	callback.Amount = payment.Amount
+	callback.PaymentStatus = payment.Status
+
+	if err := u.post(ctx, provider, callback, payment); err != nil {
+		return nil, err
+	}
+
+	processedAt := now()
+	callback.ProcessedAt = &processedAt
+	if err := u.callbackRepo.UpdatePaymentCallback(ctx, callback); err != nil {
+		return nil, err
+	}
+
+	return callback, nil
+}
+
+func (u *PaymentCallbackUsecase) reject(ctx context.Context, callback *entity.PaymentCallback, reason *apperror.Error) error {
+	callback.Status = entity.PaymentCallbackStatusRejected
+	callback.Error = reason.Code + ": " + reason.Error()
+	if err := u.callbackRepo.UpdatePaymentCallback(ctx, callback); err != nil {
+		return err
+	}
+	return reason
+}
+
+// post sets the callback outcome, only internal failures are returned
+func (u *PaymentCallbackUsecase) post(ctx context.Context, provider PaymentProvider, callback *entity.PaymentCallback, payment *entity.GatewayPayment) error {
+	if payment.Status != entity.GatewayPaymentStatusPaid {
+		callback.Status = entity.PaymentCallbackStatusIgnored
+		return nil
+	}
+
+	if duplicate, err := u.findPosted(ctx, provider, callback); duplicate || err != nil {
+		return err
+	}
+
+	inquiry, err := inquireByReference(ctx, u.transactionUsecase, payment.Reference)
+	if err != nil {
+		return unmatched(callback, err)
+	}
+	callback.LoanID = &inquiry.LoanID
+
+	// gateways settle in whole currency units, so the paid amount only has to match the due amount once rounded.
+	// The cash posted is the amount paid, the difference goes to the rounding account
+	if !sameAmount(math.Round(inquiry.AmountDue), payment.Amount) {
+		return unmatched(callback, ErrAmountMismatch)
+	}
+
+	trx, err := u.transactionUsecase.CreateTransaction(ctx, &entity.CreateTransactionPayload{
+		LoanID:     inquiry.LoanID,
+		Amount:     inquiry.AmountDue,
+		Channel:    provider.Name(),
+		ExternalID: payment.ExternalID,
+		Received:   payment.Amount,
+	})
+	if err != nil {
+		// a concurrent callback of the same payment won the unique external id
+		if duplicate, findErr := u.findPosted(ctx, provider, callback); duplicate || findErr != nil {
+			return findErr
+		}
+		return unmatched(callback, err)
+	}
+
+	callback.Status = entity.PaymentCallbackStatusProcessed
+	callback.TransactionID = &trx.ID
+	return nil
+}
+
+// inquireByReference runs the inquiry of the loan a payment reference points at, either the loan reference
+// or its virtual account
+func inquireByReference(ctx context.Context, transactionUsecase TransactionUsecaseInterface, reference string) (*entity.TransactionInquiry, error) {
+	if loanID, ok := entity.ParseLoanReference(reference); ok {
+		return transactionUsecase.InquiryTransaction(ctx, loanID, entity.PayAhead{})
+	}
+
+	if entity.ValidVirtualAccountNumber(reference) {
+		return transactionUsecase.InquiryTransactionByVirtualAccount(ctx, reference, entity.PayAhead{})
+	}
+
+	return nil, ErrUnknownLoanReference
+}
+
+// findPosted marks the callback as a duplicate when its payment was already posted
+func (u *PaymentCallbackUsecase) findPosted(ctx context.Context, provider PaymentProvider, callback *entity.PaymentCallback) (bool, error) {
+	trx, err := u.transactionUsecase.GetTransactionByExternalID(ctx, provider.Name(), callback.ExternalID)
+	if errors.Is(err, ErrTransactionNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	callback.Status = entity.PaymentCallbackStatusDuplicate
+	callback.TransactionID = &trx.ID
+	return true, nil
+}
+
+// unmatched records business errors on the callback and returns internal ones
+func unmatched(callback *entity.PaymentCallback, err error) error {
+	appErr, ok := apperror.As(err)
+	if !ok || appErr.Kind == apperror.KindInternal {
+		return err
+	}
+
+	callback.Status = entity.PaymentCallbackStatusUnmatched
+	callback.Error = appErr.Code + ": " + appErr.Error()
+	return nil
+}
+
+func (u *PaymentCallbackUsecase) GetPaymentCallbacks(ctx context.Context, filter entity.PaymentCallbackFilter) ([]*entity.PaymentCallback, error) {
+	if err := authorize(ctx, entity.PermPaymentCallbackRead); err != nil {
+		return nil, err
+	}
+
+	if err := validation.Struct(filter); err != nil {
+		return nil, err
+	}
+
+	return u.callbackRepo.GetPaymentCallbacks(ctx, filter)
+}
+
+func (u *PaymentCallbackUsecase) GetPaymentCallbackByID(ctx context.Context, id int64) (*entity.PaymentCallback, error) {
+	if err := authorize(ctx, entity.PermPaymentCallbackRead); err != nil {
+		return nil, err
+	}
+
+	return u.callbackRepo.GetPaymentCallbackByID(ctx, id)
+}
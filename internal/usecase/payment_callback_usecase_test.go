@@ -0,0 +1,239 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"loan-management/internal/entity"
+	internalMock "loan-management/internal/mock"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// stubPaymentProvider accepts callbacks signed with "valid" and reads a JSON GatewayPayment
+type stubPaymentProvider struct{}
+
+func (stubPaymentProvider) Name() string { return "stub" }
+
+func (stubPaymentProvider) VerifySignature(headers http.Header, body []byte) bool {
+	return headers.Get("X-Signature") == "valid"
+}
+
+func (stubPaymentProvider) ParseCallback(body []byte) (*entity.GatewayPayment, error) {
+	var payment entity.GatewayPayment
+	if err := json.Unmarshal(body, &payment); err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+func setupPaymentCallbackMocks() (*PaymentCallbackUsecase, *internalMock.MockPaymentCallbackRepository, *internalMock.MockTransactionUsecase) {
+	mockCallbackRepo := new(internalMock.MockPaymentCallbackRepository)
+	mockTransactionUsecase := new(internalMock.MockTransactionUsecase)
+
+	callbackUsecase := NewPaymentCallbackUsecase(mockCallbackRepo, mockTransactionUsecase, stubPaymentProvider{})
+
+	mockCallbackRepo.On("CreatePaymentCallback", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(1).(*entity.PaymentCallback).ID = 1
+	}).Return(nil)
+	mockCallbackRepo.On("UpdatePaymentCallback", mock.Anything, mock.Anything).Return(nil)
+
+	return callbackUsecase, mockCallbackRepo, mockTransactionUsecase
+}
+
+func signedHeaders() http.Header {
+	return http.Header{"X-Signature": []string{"valid"}}
+}
+
+func TestHandleCallback(t *testing.T) {
+	paidBody := []byte(`{"ExternalID":"pay_1","Reference":"LOAN-1","Amount":110000,"Status":"paid"}`)
+	inquiry := &entity.TransactionInquiry{LoanID: 1, AmountDue: 109999.6}
+
+	t.Run("Success HandleCallback - Posted", func(t *testing.T) {
+		callbackUsecase, mockCallbackRepo, mockTransactionUsecase := setupPaymentCallbackMocks()
+
+		mockTransactionUsecase.On("GetTransactionByExternalID", mock.Anything, "stub", "pay_1").Return(nil, ErrTransactionNotFound).Once()
+		mockTransactionUsecase.On("InquiryTransaction", mock.Anything, int64(1), entity.PayAhead{}).Return(inquiry, nil)
+		mockTransactionUsecase.On("CreateTransaction", mock.Anything, &entity.CreateTransactionPayload{
+			LoanID:     1,
+			Amount:     inquiry.AmountDue,
+			Channel:    "stub",
+			ExternalID: "pay_1",
+			Received:   110000,
+		}).Return(&entity.Transaction{ID: 7}, nil)
+
+		callback, err := callbackUsecase.HandleCallback(context.Background(), "stub", signedHeaders(), paidBody)
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.PaymentCallbackStatusProcessed, callback.Status)
+		assert.Equal(t, int64(7), *callback.TransactionID)
+		assert.Equal(t, int64(1), *callback.LoanID)
+		assert.Equal(t, string(paidBody), callback.Payload)
+		assert.NotNil(t, callback.ProcessedAt)
+		mockCallbackRepo.AssertExpectations(t)
+	})
+
+	t.Run("Success HandleCallback - Virtual Account Reference", func(t *testing.T) {
+		callbackUsecase, _, mockTransactionUsecase := setupPaymentCallbackMocks()
+
+		mockTransactionUsecase.On("GetTransactionByExternalID", mock.Anything, "stub", "pay_3").Return(nil, ErrTransactionNotFound).Once()
+		mockTransactionUsecase.On("InquiryTransactionByVirtualAccount", mock.Anything, "880800000000016", entity.PayAhead{}).Return(inquiry, nil)
+		mockTransactionUsecase.On("CreateTransaction", mock.Anything, &entity.CreateTransactionPayload{
+			LoanID:     1,
+			Amount:     inquiry.AmountDue,
+			Channel:    "stub",
+			ExternalID: "pay_3",
+			Received:   110000,
+		}).Return(&entity.Transaction{ID: 8}, nil)
+
+		callback, err := callbackUsecase.HandleCallback(context.Background(), "stub", signedHeaders(), []byte(`{"ExternalID":"pay_3","Reference":"880800000000016","Amount":110000,"Status":"paid"}`))
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.PaymentCallbackStatusProcessed, callback.Status)
+		assert.Equal(t, int64(1), *callback.LoanID)
+		mockTransactionUsecase.AssertNotCalled(t, "InquiryTransaction", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Success HandleCallback - Duplicate", func(t *testing.T) {
+		callbackUsecase, _, mockTransactionUsecase := setupPaymentCallbackMocks()
+
+		mockTransactionUsecase.On("GetTransactionByExternalID", mock.Anything, "stub", "pay_1").Return(&entity.Transaction{ID: 7}, nil)
+
+		callback, err := callbackUsecase.HandleCallback(context.Background(), "stub", signedHeaders(), paidBody)
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.PaymentCallbackStatusDuplicate, callback.Status)
+		assert.Equal(t, int64(7), *callback.TransactionID)
+		mockTransactionUsecase.AssertNotCalled(t, "CreateTransaction", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Success HandleCallback - Concurrent Duplicate", func(t *testing.T) {
+		callbackUsecase, _, mockTransactionUsecase := setupPaymentCallbackMocks()
+
+		mockTransactionUsecase.On("GetTransactionByExternalID", mock.Anything, "stub", "pay_1").Return(nil, ErrTransactionNotFound).Once()
+		mockTransactionUsecase.On("InquiryTransaction", mock.Anything, int64(1), entity.PayAhead{}).Return(inquiry, nil)
+		mockTransactionUsecase.On("CreateTransaction", mock.Anything, mock.Anything).Return(nil, errors.New("UNIQUE constraint failed"))
+		mockTransactionUsecase.On("GetTransactionByExternalID", mock.Anything, "stub", "pay_1").Return(&entity.Transaction{ID: 7}, nil).Once()
+
+		callback, err := callbackUsecase.HandleCallback(context.Background(), "stub", signedHeaders(), paidBody)
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.PaymentCallbackStatusDuplicate, callback.Status)
+	})
+
+	t.Run("Success HandleCallback - Amount Mismatch", func(t *testing.T) {
+		callbackUsecase, _, mockTransactionUsecase := setupPaymentCallbackMocks()
+
+		mockTransactionUsecase.On("GetTransactionByExternalID", mock.Anything, "stub", "pay_1").Return(nil, ErrTransactionNotFound)
+		mockTransactionUsecase.On("InquiryTransaction", mock.Anything, int64(1), entity.PayAhead{}).Return(&entity.TransactionInquiry{LoanID: 1, AmountDue: 220000}, nil)
+
+		callback, err := callbackUsecase.HandleCallback(context.Background(), "stub", signedHeaders(), paidBody)
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.PaymentCallbackStatusUnmatched, callback.Status)
+		assert.True(t, strings.HasPrefix(callback.Error, "AMOUNT_MISMATCH"))
+		mockTransactionUsecase.AssertNotCalled(t, "CreateTransaction", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Success HandleCallback - Unknown Reference", func(t *testing.T) {
+		callbackUsecase, _, mockTransactionUsecase := setupPaymentCallbackMocks()
+
+		mockTransactionUsecase.On("GetTransactionByExternalID", mock.Anything, "stub", "pay_2").Return(nil, ErrTransactionNotFound)
+
+		callback, err := callbackUsecase.HandleCallback(context.Background(), "stub", signedHeaders(), []byte(`{"ExternalID":"pay_2","Reference":"INV-9","Amount":1,"Status":"paid"}`))
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.PaymentCallbackStatusUnmatched, callback.Status)
+		assert.True(t, strings.HasPrefix(callback.Error, "UNKNOWN_LOAN_REFERENCE"))
+		assert.Nil(t, callback.LoanID)
+	})
+
+	t.Run("Success HandleCallback - Not Paid", func(t *testing.T) {
+		callbackUsecase, _, mockTransactionUsecase := setupPaymentCallbackMocks()
+
+		callback, err := callbackUsecase.HandleCallback(context.Background(), "stub", signedHeaders(), []byte(`{"ExternalID":"pay_1","Reference":"LOAN-1","Amount":110000,"Status":"pending"}`))
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.PaymentCallbackStatusIgnored, callback.Status)
+		mockTransactionUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Failed HandleCallback - Invalid Signature", func(t *testing.T) {
+		callbackUsecase, mockCallbackRepo, mockTransactionUsecase := setupPaymentCallbackMocks()
+
+		_, err := callbackUsecase.HandleCallback(context.Background(), "stub", http.Header{}, paidBody)
+
+		assert.ErrorIs(t, err, ErrInvalidCallbackSignature)
+		mockCallbackRepo.AssertCalled(t, "UpdatePaymentCallback", mock.Anything, mock.MatchedBy(func(callback *entity.PaymentCallback) bool {
+			return callback.Status == entity.PaymentCallbackStatusRejected
+		}))
+		mockTransactionUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Failed HandleCallback - Invalid Payload", func(t *testing.T) {
+		callbackUsecase, _, _ := setupPaymentCallbackMocks()
+
+		_, err := callbackUsecase.HandleCallback(context.Background(), "stub", signedHeaders(), []byte(`not json`))
+
+		assert.ErrorIs(t, err, ErrInvalidCallbackPayload)
+	})
+
+	t.Run("Failed HandleCallback - Unknown Provider", func(t *testing.T) {
+		callbackUsecase, mockCallbackRepo, _ := setupPaymentCallbackMocks()
+
+		_, err := callbackUsecase.HandleCallback(context.Background(), "other", signedHeaders(), paidBody)
+
+		assert.ErrorIs(t, err, ErrPaymentProviderNotFound)
+		mockCallbackRepo.AssertNotCalled(t, "CreatePaymentCallback", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failed HandleCallback - Internal Error", func(t *testing.T) {
+		callbackUsecase, mockCallbackRepo, mockTransactionUsecase := setupPaymentCallbackMocks()
+		dbErr := errors.New("connection refused")
+
+		mockTransactionUsecase.On("GetTransactionByExternalID", mock.Anything, "stub", "pay_1").Return(nil, dbErr)
+
+		_, err := callbackUsecase.HandleCallback(context.Background(), "stub", signedHeaders(), paidBody)
+
+		assert.ErrorIs(t, err, dbErr)
+		mockCallbackRepo.AssertNumberOfCalls(t, "UpdatePaymentCallback", 0)
+	})
+}
+
+func TestGetPaymentCallbacks(t *testing.T) {
+	t.Run("Success GetPaymentCallbacks", func(t *testing.T) {
+		callbackUsecase, mockCallbackRepo, _ := setupPaymentCallbackMocks()
+		ctx := entity.WithIdentity(context.Background(), &entity.Identity{Type: entity.IdentityTypeBorrower, Role: entity.RoleFinance, UserID: 1})
+		filter := entity.PaymentCallbackFilter{Status: entity.PaymentCallbackStatusUnmatched}
+
+		mockCallbackRepo.On("GetPaymentCallbacks", ctx, filter).Return([]*entity.PaymentCallback{{ID: 1, ReceivedAt: time.Now()}}, nil)
+
+		callbacks, err := callbackUsecase.GetPaymentCallbacks(ctx, filter)
+
+		assert.NoError(t, err)
+		assert.Len(t, callbacks, 1)
+	})
+
+	t.Run("Failed GetPaymentCallbacks - Forbidden", func(t *testing.T) {
+		callbackUsecase, mockCallbackRepo, _ := setupPaymentCallbackMocks()
+		ctx := entity.WithIdentity(context.Background(), &entity.Identity{Type: entity.IdentityTypeBorrower, Role: entity.RoleBorrower, UserID: 1})
+
+		_, err := callbackUsecase.GetPaymentCallbacks(ctx, entity.PaymentCallbackFilter{})
+
+		assert.ErrorIs(t, err, ErrForbidden)
+		mockCallbackRepo.AssertNotCalled(t, "GetPaymentCallbacks", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failed GetPaymentCallbacks - Invalid Status", func(t *testing.T) {
+		callbackUsecase, _, _ := setupPaymentCallbackMocks()
+
+		_, err := callbackUsecase.GetPaymentCallbacks(context.Background(), entity.PaymentCallbackFilter{Status: 5})
+
+		assert.Error(t, err)
+	})
+}
@@ -6,15 +6,19 @@ import (
 	"errors"
 	"loan-management/internal/entity"
 	"loan-management/internal/repository"
+	"loan-management/internal/validation"
 	"time"
 )
 
 type PaymentUsecaseInterface interface {
 	GetPaymentByID(ctx context.Context, id int64) (*entity.Payment, error)
 	GetAllPayments(ctx context.Context, status *entity.PaymentStatus) ([]*entity.Payment, error)
-	GetPaymentsByLoanID(ctx context.Context, loanId int64, status *entity.PaymentStatus, dueBefore *time.Time) ([]*entity.Payment, error)
+	GetPaymentsByLoanID(ctx context.Context, loanId int64, status *entity.PaymentStatus, dueBefore *entity.Date) ([]*entity.Payment, error)
 	CreatePayment(tx *sql.Tx, payments []entity.CreatePaymentPayload) error
+	GetPaymentsByTransactionID(ctx context.Context, transactionID int64) ([]*entity.Payment, error)
 	PayPayment(tx *sql.Tx, paymentID int64, transactionID int64, paidAt time.Time) error
+	UnpayPayments(tx *sql.Tx, transactionID int64) error
+	CloseUnpaidPayments(tx *sql.Tx, loanID int64, closedAt time.Time) error
 }
 
 type PaymentUsecase struct {
@@ -31,12 +35,19 @@ func (u *PaymentUsecase) GetPaymentByID(ctx context.Context, id int64) (*entity.
 	return u.paymentRepo.GetPaymentByID(ctx, id)
 }
 func (u *PaymentUsecase) GetAllPayments(ctx context.Context, status *entity.PaymentStatus) ([]*entity.Payment, error) {
+	if err := authorize(ctx, entity.PermPaymentRead); err != nil {
+		return nil, err
+	}
 	return u.paymentRepo.GetAllPayments(ctx, status)
 }
-func (u *PaymentUsecase) GetPaymentsByLoanID(ctx context.Context, loanId int64, status *entity.PaymentStatus, dueBefore *time.Time) ([]*entity.Payment, error) {
+func (u *PaymentUsecase) GetPaymentsByLoanID(ctx context.Context, loanId int64, status *entity.PaymentStatus, dueBefore *entity.Date) ([]*entity.Payment, error) {
 	return u.paymentRepo.GetPaymentsByLoanID(ctx, loanId, status, dueBefore)
 }
 
+func (u *PaymentUsecase) GetPaymentsByTransactionID(ctx context.Context, transactionID int64) ([]*entity.Payment, error) {
+	return u.paymentRepo.GetPaymentsByTransactionID(ctx, transactionID)
+}
+
 func (u *PaymentUsecase) CreatePayment(tx *sql.Tx, payments []entity.CreatePaymentPayload) error {
 	if len(payments) == 0 {
 		return errors.New("no payments to create")
@@ -68,22 +79,14 @@ func (u *PaymentUsecase) PayPayment(tx *sql.Tx, paymentID int64, transactionID i
 	return u.paymentRepo.PayPayment(tx, paymentID, transactionID, paidAt)
 }
 
-func (u *PaymentUsecase) validatePaymentPayload(req entity.CreatePaymentPayload) error {
-	if req.LoanID <= 0 {
-		return errors.New("invalid loan ID")
-	}
-
-	if req.PaymentNo <= 0 {
-		return errors.New("invalid payment number")
-	}
-
-	if req.Amount <= 0 {
-		return errors.New("payment amount must be positive")
-	}
+func (u *PaymentUsecase) UnpayPayments(tx *sql.Tx, transactionID int64) error {
+	return u.paymentRepo.UnpayPayments(tx, transactionID)
+}
 
-	if req.DueDate.IsZero() {
-		return errors.New("due date is required")
-	}
+func (u *PaymentUsecase) CloseUnpaidPayments(tx *sql.Tx, loanID int64, closedAt time.Time) error {
+	return u.paymentRepo.CloseUnpaidPayments(tx, loanID, closedAt)
+}
 
-	return nil
+func (u *PaymentUsecase) validatePaymentPayload(req entity.CreatePaymentPayload) error {
+	return validation.Struct(req)
 }
@@ -16,7 +16,7 @@ var MockPayment = &entity.Payment{
 	LoanID:        1,
 	TransactionID: nil,
 	PaymentNo:     1,
-	DueDate:       time.Time{},
+	DueDate:       entity.Date{},
 	Amount:        float64(1000000),
 	Interest:      float64(100000),
 	TotalAmount:   float64(1100000),
@@ -63,7 +63,7 @@ func TestGetPaymentsByLoanID(t *testing.T) {
 		mockPayments := []*entity.Payment{MockPayment}
 		mockRepo.On("GetPaymentsByLoanID", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(mockPayments, nil)
 		paymentStatusActive := entity.PaymentStatusActive
-		payments, err := mockUsecase.GetPaymentsByLoanID(context.Background(), int64(1), &paymentStatusActive, &time.Time{})
+		payments, err := mockUsecase.GetPaymentsByLoanID(context.Background(), int64(1), &paymentStatusActive, &entity.Date{})
 
 		assert.NoError(t, err)
 		assert.Equal(t, payments, mockPayments)
@@ -79,7 +79,7 @@ func TestCreatePayment(t *testing.T) {
 		mockRepo.On("CreatePayment", mock.Anything, mock.Anything).Return(nil)
 		mockCreatePaymentPayload := []entity.CreatePaymentPayload{{
 			LoanID:      1,
-			DueDate:     time.Now(),
+			DueDate:     entity.DateOf(time.Now(), time.UTC),
 			PaymentNo:   1,
 			Amount:      1000000,
 			Interest:    100000,
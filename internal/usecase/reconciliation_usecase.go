@@ -0,0 +1,213 @@
+package usecase
+
+import (
+	"context"
+	"loan-management/internal/entity"
+	"loan-management/internal/repository"
+	"math"
+)
+
+type ReconciliationUsecaseInterface interface {
+	Verify(ctx context.Context, repair bool) (*entity.ReconciliationReport, error)
+}
+
+type ReconciliationUsecase struct {
+	loanRepo      repository.LoanRepository
+	paymentRepo   repository.PaymentRepository
+	ledgerRepo    repository.LedgerRepository
+	auditUsecase  AuditUsecaseInterface
+	ledgerUsecase LedgerUsecaseInterface
+}
+
+func NewReconciliationUsecase(loanRepo repository.LoanRepository, paymentRepo repository.PaymentRepository, ledgerRepo repository.LedgerRepository, auditUsecase AuditUsecaseInterface, ledgerUsecase LedgerUsecaseInterface) *ReconciliationUsecase {
+	return &ReconciliationUsecase{
+		loanRepo:      loanRepo,
+		paymentRepo:   paymentRepo,
+		ledgerRepo:    ledgerRepo,
+		auditUsecase:  auditUsecase,
+		ledgerUsecase: ledgerUsecase,
+	}
+}
+
+// Verify recomputes every loan from its payments and ledger entries and reports where they disagree.
+// With repair, outstanding and status are rewritten from the payments and the ledger is adjusted against suspense,
+// missing schedules and orphan payments are only reported
+func (u *ReconciliationUsecase) Verify(ctx context.Context, repair bool) (*entity.ReconciliationReport, error) {
+	if err := authorize(ctx, entity.PermReconcile); err != nil {
+		return nil, err
+	}
+
+	balances, err := u.loanRepo.GetLoanBalances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	receivables, err := u.ledgerRepo.GetLoanBalances(ctx, entity.AccountLoanReceivable)
+	if err != nil {
+		return nil, err
+	}
+
+	orphans, err := u.paymentRepo.GetOrphanPayments(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &entity.ReconciliationReport{
+		CheckedAt:    now(),
+		CheckedLoans: len(balances),
+		Issues:       []*entity.ReconciliationIssue{},
+	}
+
+	for _, balance := range balances {
+		issues := checkLoanBalance(balance, receivables)
+		if repair && len(issues) > 0 {
+			if err := u.repairLoan(ctx, balance, issues); err != nil {
+				return nil, err
+			}
+		}
+		report.Issues = append(report.Issues, issues...)
+	}
+
+	for _, payment := range orphans {
+		report.Issues = append(report.Issues, &entity.ReconciliationIssue{
+			Type:      entity.IssueOrphanPayment,
+			LoanID:    payment.LoanID,
+			PaymentID: payment.ID,
+			Actual:    payment.TotalAmount,
+		})
+	}
+
+	for _, issue := range report.Issues {
+		if issue.Repaired {
+			report.Repaired++
+		}
+	}
+
+	return report, nil
+}
+
+func checkLoanBalance(balance *entity.LoanBalance, receivables map[int64]float64) []*entity.ReconciliationIssue {
+	if balance.PaymentCount == 0 {
+		return []*entity.ReconciliationIssue{{
+			Type:   entity.IssueMissingSchedule,
+			LoanID: balance.LoanID,
+			Actual: balance.Outstanding,
+		}}
+	}
+
+	var issues []*entity.ReconciliationIssue
+	if !sameAmount(balance.Outstanding, balance.UnpaidTotal) {
+		issues = append(issues, &entity.ReconciliationIssue{
+			Type:     entity.IssueOutstandingMismatch,
+			LoanID:   balance.LoanID,
+			Expected: balance.UnpaidTotal,
+			Actual:   balance.Outstanding,
+		})
+	}
+
+	if expected := expectedLoanStatus(balance); balance.Status != expected {
+		issues = append(issues, &entity.ReconciliationIssue{
+			Type:     entity.IssueStatusMismatch,
+			LoanID:   balance.LoanID,
+			Expected: float64(expected),
+			Actual:   float64(balance.Status),
+		})
+	}
+
+	// loans created before the ledger have no entries to compare with
+	if receivable, ok := receivables[balance.LoanID]; ok && !sameAmount(receivable, balance.UnpaidPrincipal) {
+		issues = append(issues, &entity.ReconciliationIssue{
+			Type:     entity.IssueLedgerMismatch,
+			LoanID:   balance.LoanID,
+			Expected: balance.UnpaidPrincipal,
+			Actual:   receivable,
+		})
+	}
+
+	return issues
+}
+
+func (u *ReconciliationUsecase) repairLoan(ctx context.Context, balance *entity.LoanBalance, issues []*entity.ReconciliationIssue) error {
+	var repaired []*entity.ReconciliationIssue
+	for _, issue := range issues {
+		switch issue.Type {
+		case entity.IssueOutstandingMismatch, entity.IssueStatusMismatch, entity.IssueLedgerMismatch:
+			repaired = append(repaired, issue)
+		}
+	}
+
+	if len(repaired) == 0 {
+		return nil
+	}
+
+	tx, err := u.loanRepo.BeginTx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	loan, err := u.loanRepo.GetLoanByIDForUpdate(tx, balance.LoanID)
+	if err != nil {
+		return err
+	}
+
+	// paid or reversed since the balances were read, the next run will see the new state
+	if loan.Outstanding != balance.Outstanding || loan.Status != balance.Status {
+		return tx.Rollback()
+	}
+
+	fixLoan := false
+	for _, issue := range repaired {
+		if issue.Type != entity.IssueLedgerMismatch {
+			fixLoan = true
+			continue
+		}
+
+		if err = u.ledgerUsecase.PostAdjustment(ctx, tx, loan.ID, issue.Expected-issue.Actual); err != nil {
+			return err
+		}
+	}
+
+	if fixLoan {
+		after := *loan
+		after.Outstanding = balance.UnpaidTotal
+		if sameAmount(after.Outstanding, 0) {
+			after.Outstanding = 0
+		}
+		after.Status = expectedLoanStatus(balance)
+
+		if err = u.loanRepo.UpdateLoanOutstanding(tx, after.Outstanding, loan.ID); err != nil {
+			return err
+		}
+
+		if err = u.auditUsecase.Record(ctx, tx, entity.AuditActionLoanReconcile, entity.AuditEntityLoan, loan.ID, loan, &after); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	for _, issue := range repaired {
+		issue.Repaired = true
+	}
+
+	return nil
+}
+
+func expectedLoanStatus(balance *entity.LoanBalance) entity.LoanStatus {
+	if sameAmount(balance.UnpaidTotal, 0) {
+		return entity.LoanStatusPaid
+	}
+	return entity.LoanStatusActive
+}
+
+func sameAmount(a float64, b float64) bool {
+	return math.Abs(a-b) < entity.LedgerTolerance
+}
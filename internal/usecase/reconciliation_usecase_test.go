@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"context"
+	"loan-management/internal/entity"
+	internalMock "loan-management/internal/mock"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func setupReconciliationMocks() (*ReconciliationUsecase, *internalMock.MockLoanRepository, *internalMock.MockPaymentRepository, *internalMock.MockLedgerRepository, *internalMock.MockAuditUsecase, *internalMock.MockLedgerUsecase) {
+	mockLoanRepo := new(internalMock.MockLoanRepository)
+	mockPaymentRepo := new(internalMock.MockPaymentRepository)
+	mockLedgerRepo := new(internalMock.MockLedgerRepository)
+	mockAuditUsecase := new(internalMock.MockAuditUsecase)
+	mockLedgerUsecase := new(internalMock.MockLedgerUsecase)
+
+	reconciliationUsecase := NewReconciliationUsecase(mockLoanRepo, mockPaymentRepo, mockLedgerRepo, mockAuditUsecase, mockLedgerUsecase)
+
+	return reconciliationUsecase, mockLoanRepo, mockPaymentRepo, mockLedgerRepo, mockAuditUsecase, mockLedgerUsecase
+}
+
+func TestVerify(t *testing.T) {
+	// loan 1 is consistent, loan 2 is fully paid but still active with a drifted outstanding and ledger
+	balances := []*entity.LoanBalance{
+		{LoanID: 1, Outstanding: 220, Status: entity.LoanStatusActive, UnpaidTotal: 220.0000000001, UnpaidPrincipal: 200, PaymentCount: 2},
+		{LoanID: 2, Outstanding: 10, Status: entity.LoanStatusActive, UnpaidTotal: 0, UnpaidPrincipal: 0, PaymentCount: 2},
+		{LoanID: 3, Outstanding: 110, Status: entity.LoanStatusActive, PaymentCount: 0},
+	}
+	receivables := map[int64]float64{1: 200, 2: 5}
+	orphans := []*entity.Payment{{ID: 9, LoanID: 69, TotalAmount: 110}}
+
+	t.Run("Success Verify - Report Only", func(t *testing.T) {
+		reconciliationUsecase, mockLoanRepo, mockPaymentRepo, mockLedgerRepo, _, _ := setupReconciliationMocks()
+		mockLoanRepo.On("GetLoanBalances", mock.Anything).Return(balances, nil)
+		mockLedgerRepo.On("GetLoanBalances", mock.Anything, entity.AccountLoanReceivable).Return(receivables, nil)
+		mockPaymentRepo.On("GetOrphanPayments", mock.Anything).Return(orphans, nil)
+
+		report, err := reconciliationUsecase.Verify(context.Background(), false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, report.CheckedLoans)
+		assert.Equal(t, []*entity.ReconciliationIssue{
+			{Type: entity.IssueOutstandingMismatch, LoanID: 2, Expected: 0, Actual: 10},
+			{Type: entity.IssueStatusMismatch, LoanID: 2, Expected: float64(entity.LoanStatusPaid), Actual: float64(entity.LoanStatusActive)},
+			{Type: entity.IssueLedgerMismatch, LoanID: 2, Expected: 0, Actual: 5},
+			{Type: entity.IssueMissingSchedule, LoanID: 3, Actual: 110},
+			{Type: entity.IssueOrphanPayment, LoanID: 69, PaymentID: 9, Actual: 110},
+		}, report.Issues)
+		assert.Equal(t, 5, report.Unresolved())
+		mockLoanRepo.AssertNotCalled(t, "BeginTx")
+	})
+
+	t.Run("Success Verify - Repair", func(t *testing.T) {
+		reconciliationUsecase, mockLoanRepo, mockPaymentRepo, mockLedgerRepo, mockAuditUsecase, mockLedgerUsecase := setupReconciliationMocks()
+		mockTx := newMockTx(t, true)
+		loan := &entity.Loan{ID: 2, Outstanding: 10, Status: entity.LoanStatusActive}
+		repaired := &entity.Loan{ID: 2, Outstanding: 0, Status: entity.LoanStatusPaid}
+
+		mockLoanRepo.On("GetLoanBalances", mock.Anything).Return(balances, nil)
+		mockLedgerRepo.On("GetLoanBalances", mock.Anything, entity.AccountLoanReceivable).Return(receivables, nil)
+		mockPaymentRepo.On("GetOrphanPayments", mock.Anything).Return(orphans, nil)
+		mockLoanRepo.On("BeginTx").Return(mockTx, nil).Once()
+		mockLoanRepo.On("GetLoanByIDForUpdate", mockTx, int64(2)).Return(loan, nil)
+		mockLedgerUsecase.On("PostAdjustment", mock.Anything, mockTx, int64(2), float64(-5)).Return(nil)
+		mockLoanRepo.On("UpdateLoanOutstanding", mockTx, float64(0), int64(2)).Return(nil)
+		mockAuditUsecase.On("Record", mock.Anything, mockTx, entity.AuditActionLoanReconcile, entity.AuditEntityLoan, int64(2), loan, repaired).Return(nil)
+
+		report, err := reconciliationUsecase.Verify(context.Background(), true)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, report.Repaired)
+		assert.Equal(t, 2, report.Unresolved())
+		mockLoanRepo.AssertExpectations(t)
+		mockLedgerUsecase.AssertExpectations(t)
+		mockAuditUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Success Verify - Skip Loan Changed Meanwhile", func(t *testing.T) {
+		reconciliationUsecase, mockLoanRepo, mockPaymentRepo, mockLedgerRepo, _, mockLedgerUsecase := setupReconciliationMocks()
+		mockTx := newMockTx(t, false)
+
+		mockLoanRepo.On("GetLoanBalances", mock.Anything).Return(balances[1:2], nil)
+		mockLedgerRepo.On("GetLoanBalances", mock.Anything, entity.AccountLoanReceivable).Return(map[int64]float64{}, nil)
+		mockPaymentRepo.On("GetOrphanPayments", mock.Anything).Return(nil, nil)
+		mockLoanRepo.On("BeginTx").Return(mockTx, nil)
+		mockLoanRepo.On("GetLoanByIDForUpdate", mockTx, int64(2)).Return(&entity.Loan{ID: 2, Outstanding: 0, Status: entity.LoanStatusPaid}, nil)
+
+		report, err := reconciliationUsecase.Verify(context.Background(), true)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, report.Repaired)
+		mockLoanRepo.AssertNotCalled(t, "UpdateLoanOutstanding", mock.Anything, mock.Anything, mock.Anything)
+		mockLedgerUsecase.AssertNotCalled(t, "PostAdjustment", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failed Verify - Finance", func(t *testing.T) {
+		reconciliationUsecase, mockLoanRepo, _, _, _, _ := setupReconciliationMocks()
+		ctx := entity.WithIdentity(context.Background(), &entity.Identity{Type: entity.IdentityTypeBorrower, Role: entity.RoleFinance, UserID: 1})
+
+		_, err := reconciliationUsecase.Verify(ctx, false)
+
+		assert.ErrorIs(t, err, ErrForbidden)
+		mockLoanRepo.AssertNotCalled(t, "GetLoanBalances", mock.Anything)
+	})
+}
@@ -0,0 +1,208 @@
+package usecase
+
+import (
+	"context"
+	"loan-management/internal/apperror"
+	"loan-management/internal/entity"
+	"loan-management/internal/repository"
+	"time"
+)
+
+var ErrInvalidReportPeriod = apperror.Validation("INVALID_REPORT_PERIOD", "The report period ends before it starts")
+
+type ReportUsecaseInterface interface {
+	GetPortfolio(ctx context.Context, asOf time.Time) (*entity.PortfolioReport, error)
+	GetAging(ctx context.Context, asOf time.Time) (*entity.AgingReport, error)
+	GetCollections(ctx context.Context, from time.Time, to time.Time) (*entity.CollectionReport, error)
+	GetVintages(ctx context.Context, from time.Time, to time.Time, asOf time.Time) (*entity.VintageReport, error)
+}
+
+// ReportUsecase computes the portfolio reports. A zero asOf is now, a zero period is the twelve months up to now
+type ReportUsecase struct {
+	reportRepo repository.ReportRepository
+}
+
+func NewReportUsecase(reportRepo repository.ReportRepository) *ReportUsecase {
+	return &ReportUsecase{reportRepo: reportRepo}
+}
+
+func (u *ReportUsecase) GetPortfolio(ctx context.Context, asOf time.Time) (*entity.PortfolioReport, error) {
+	if err := authorize(ctx, entity.PermReportRead); err != nil {
+		return nil, err
+	}
+	asOf = reportAsOf(asOf)
+
+	loanCount, disbursed, err := u.reportRepo.GetDisbursed(ctx, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets, err := u.agingBuckets(ctx, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &entity.PortfolioReport{
+		AsOf:           asOf,
+		LoanCount:      loanCount,
+		TotalDisbursed: disbursed,
+	}
+	for _, bucket := range buckets {
+		report.ActiveLoanCount += bucket.LoanCount
+		report.OutstandingPrincipal += bucket.Principal
+		report.OutstandingInterest += bucket.Interest
+		if bucket.MinDays > 30 {
+			report.PAR30 += bucket.Principal
+		}
+		if bucket.MinDays > 90 {
+			report.PAR90 += bucket.Principal
+		}
+	}
+	report.PAR30Ratio = ratio(report.PAR30, report.OutstandingPrincipal)
+	report.PAR90Ratio = ratio(report.PAR90, report.OutstandingPrincipal)
+
+	return report, nil
+}
+
+func (u *ReportUsecase) GetAging(ctx context.Context, asOf time.Time) (*entity.AgingReport, error) {
+	if err := authorize(ctx, entity.PermReportRead); err != nil {
+		return nil, err
+	}
+	asOf = reportAsOf(asOf)
+
+	buckets, err := u.agingBuckets(ctx, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.AgingReport{AsOf: asOf, Buckets: buckets}, nil
+}
+
+// GetCollections reports the collection rate of every month of the period, the share of the installments due in
+// the month that were paid by its end
+func (u *ReportUsecase) GetCollections(ctx context.Context, from time.Time, to time.Time) (*entity.CollectionReport, error) {
+	if err := authorize(ctx, entity.PermReportRead); err != nil {
+		return nil, err
+	}
+	from, to, err := reportPeriod(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	periods, err := u.reportRepo.GetCollectionsByMonth(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	for _, period := range periods {
+		period.Rate = ratio(period.Collected, period.Due)
+	}
+
+	return &entity.CollectionReport{From: from, To: to, Periods: periods}, nil
+}
+
+// GetVintages groups the loans disbursed during the period by month and follows how much of their principal was
+// repaid month after month, up to asOf
+func (u *ReportUsecase) GetVintages(ctx context.Context, from time.Time, to time.Time, asOf time.Time) (*entity.VintageReport, error) {
+	if err := authorize(ctx, entity.PermReportRead); err != nil {
+		return nil, err
+	}
+	from, to, err := reportPeriod(from, to)
+	if err != nil {
+		return nil, err
+	}
+	asOf = reportAsOf(asOf)
+
+	cohorts, err := u.reportRepo.GetVintageCohorts(ctx, from, to, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &entity.VintageReport{AsOf: asOf, Vintages: make([]*entity.Vintage, 0, len(cohorts))}
+	for _, cohort := range cohorts {
+		start, err := time.Parse("2006-01", cohort.Cohort)
+		if err != nil {
+			return nil, err
+		}
+
+		vintage := &entity.Vintage{
+			Cohort:    cohort.Cohort,
+			LoanCount: cohort.LoanCount,
+			Disbursed: cohort.Disbursed,
+			Curve:     []*entity.VintagePoint{},
+		}
+
+		var repaid float64
+		end := asOf.Format("2006-01")
+		for mob := 0; ; mob++ {
+			month := start.AddDate(0, mob, 0).Format("2006-01")
+			if month > end {
+				break
+			}
+			repaid += cohort.Repaid[month]
+			vintage.Curve = append(vintage.Curve, &entity.VintagePoint{
+				MonthsOnBook: mob,
+				Repaid:       repaid,
+				RepaidRatio:  ratio(repaid, cohort.Disbursed),
+			})
+		}
+
+		report.Vintages = append(report.Vintages, vintage)
+	}
+
+	return report, nil
+}
+
+func (u *ReportUsecase) agingBuckets(ctx context.Context, asOf time.Time) ([]*entity.AgingBucket, error) {
+	minDays := make([]int, len(entity.AgingBuckets))
+	for i, bucket := range entity.AgingBuckets {
+		minDays[i] = bucket.MinDays
+	}
+
+	totals, err := u.reportRepo.GetOutstandingByDaysPastDue(ctx, asOf, entity.DateOf(asOf, businessLocation), minDays)
+	if err != nil {
+		return nil, err
+	}
+
+	var principal float64
+	for _, t := range totals {
+		principal += t.Principal
+	}
+
+	buckets := make([]*entity.AgingBucket, len(entity.AgingBuckets))
+	for i := range entity.AgingBuckets {
+		bucket := entity.AgingBuckets[i]
+		bucket.OutstandingTotals = *totals[i]
+		bucket.PrincipalShare = ratio(bucket.Principal, principal)
+		buckets[i] = &bucket
+	}
+
+	return buckets, nil
+}
+
+func reportAsOf(asOf time.Time) time.Time {
+	if asOf.IsZero() {
+		return now()
+	}
+	return asOf
+}
+
+// reportPeriod defaults the period to the twelve months up to now, starting on the first of the month
+func reportPeriod(from time.Time, to time.Time) (time.Time, time.Time, error) {
+	if to.IsZero() {
+		to = now()
+	}
+	if from.IsZero() {
+		from = time.Date(to.Year(), to.Month(), 1, 0, 0, 0, 0, to.Location()).AddDate(0, -11, 0)
+	}
+	if to.Before(from) {
+		return from, to, ErrInvalidReportPeriod
+	}
+	return from, to, nil
+}
+
+func ratio(part float64, total float64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return part / total
+}
@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"context"
+	"loan-management/internal/entity"
+	internalMock "loan-management/internal/mock"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetPortfolio(t *testing.T) {
+	asOf := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+	minDays := []int{0, 1, 31, 61, 91, 181}
+	totals := []*entity.OutstandingTotals{
+		{LoanCount: 5, Principal: 500, Interest: 50},
+		{LoanCount: 2, Principal: 200, Interest: 20},
+		{LoanCount: 1, Principal: 100, Interest: 10},
+		{},
+		{LoanCount: 2, Principal: 200, Interest: 20},
+		{},
+	}
+
+	t.Run("sums up the buckets", func(t *testing.T) {
+		mockReportRepo := new(internalMock.MockReportRepository)
+		mockReportRepo.On("GetDisbursed", mock.Anything, asOf).Return(12, 1500.0, nil)
+		mockReportRepo.On("GetOutstandingByDaysPastDue", mock.Anything, asOf, entity.NewDate(2025, 6, 30), minDays).Return(totals, nil)
+
+		report, err := NewReportUsecase(mockReportRepo).GetPortfolio(context.Background(), asOf)
+		assert.NoError(t, err)
+		assert.Equal(t, &entity.PortfolioReport{
+			AsOf:                 asOf,
+			LoanCount:            12,
+			ActiveLoanCount:      10,
+			TotalDisbursed:       1500,
+			OutstandingPrincipal: 1000,
+			OutstandingInterest:  100,
+			PAR30:                300,
+			PAR30Ratio:           0.3,
+			PAR90:                200,
+			PAR90Ratio:           0.2,
+		}, report)
+	})
+
+	t.Run("defaults to now", func(t *testing.T) {
+		now = func() time.Time { return asOf }
+		defer func() { now = time.Now }()
+
+		mockReportRepo := new(internalMock.MockReportRepository)
+		mockReportRepo.On("GetDisbursed", mock.Anything, asOf).Return(0, 0.0, nil)
+		mockReportRepo.On("GetOutstandingByDaysPastDue", mock.Anything, asOf, entity.NewDate(2025, 6, 30), minDays).Return([]*entity.OutstandingTotals{{}, {}, {}, {}, {}, {}}, nil)
+
+		report, err := NewReportUsecase(mockReportRepo).GetPortfolio(context.Background(), time.Time{})
+		assert.NoError(t, err)
+		assert.Equal(t, asOf, report.AsOf)
+		assert.Zero(t, report.PAR30Ratio)
+	})
+
+	t.Run("forbidden for borrowers", func(t *testing.T) {
+		ctx := entity.WithIdentity(context.Background(), &entity.Identity{Type: entity.IdentityTypeBorrower, Role: entity.RoleBorrower, UserID: 1})
+
+		_, err := NewReportUsecase(new(internalMock.MockReportRepository)).GetPortfolio(ctx, asOf)
+		assert.ErrorIs(t, err, ErrForbidden)
+	})
+}
+
+func TestGetAging(t *testing.T) {
+	// late in the evening UTC is already the next business day in Jakarta
+	businessLocation = time.FixedZone("WIB", 7*60*60)
+	defer func() { businessLocation = time.UTC }()
+	asOf := time.Date(2025, 6, 30, 20, 0, 0, 0, time.UTC)
+
+	mockReportRepo := new(internalMock.MockReportRepository)
+	mockReportRepo.On("GetOutstandingByDaysPastDue", mock.Anything, asOf, entity.NewDate(2025, 7, 1), mock.Anything).Return([]*entity.OutstandingTotals{
+		{LoanCount: 3, Principal: 300, Interest: 30},
+		{LoanCount: 1, Principal: 100, Interest: 10},
+		{}, {}, {}, {},
+	}, nil)
+
+	report, err := NewReportUsecase(mockReportRepo).GetAging(context.Background(), asOf)
+	assert.NoError(t, err)
+	assert.Len(t, report.Buckets, len(entity.AgingBuckets))
+	assert.Equal(t, "current", report.Buckets[0].Label)
+	assert.Equal(t, 0.75, report.Buckets[0].PrincipalShare)
+	assert.Equal(t, "1-30", report.Buckets[1].Label)
+	assert.Equal(t, 1, report.Buckets[1].LoanCount)
+	assert.Equal(t, 0.25, report.Buckets[1].PrincipalShare)
+	assert.Zero(t, entity.AgingBuckets[0].LoanCount)
+}
+
+func TestGetCollections(t *testing.T) {
+	mockTime := time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return mockTime }
+	defer func() { now = time.Now }()
+
+	t.Run("computes the collection rate over the last twelve months", func(t *testing.T) {
+		from := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+
+		mockReportRepo := new(internalMock.MockReportRepository)
+		mockReportRepo.On("GetCollectionsByMonth", mock.Anything, from, mockTime).Return([]*entity.CollectionPeriod{
+			{Period: "2025-05", Due: 400, Collected: 300, Received: 350},
+			{Period: "2025-06", Received: 100},
+		}, nil)
+
+		report, err := NewReportUsecase(mockReportRepo).GetCollections(context.Background(), time.Time{}, time.Time{})
+		assert.NoError(t, err)
+		assert.Equal(t, from, report.From)
+		assert.Equal(t, 0.75, report.Periods[0].Rate)
+		assert.Zero(t, report.Periods[1].Rate)
+	})
+
+	t.Run("invalid period", func(t *testing.T) {
+		_, err := NewReportUsecase(new(internalMock.MockReportRepository)).GetCollections(context.Background(), mockTime, mockTime.AddDate(0, -1, 0))
+		assert.ErrorIs(t, err, ErrInvalidReportPeriod)
+	})
+}
+
+func TestGetVintages(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)
+	asOf := time.Date(2025, 4, 10, 0, 0, 0, 0, time.UTC)
+
+	mockReportRepo := new(internalMock.MockReportRepository)
+	mockReportRepo.On("GetVintageCohorts", mock.Anything, from, to, asOf).Return([]*entity.VintageCohort{
+		{Cohort: "2025-02", LoanCount: 2, Disbursed: 1000, Repaid: map[string]float64{"2025-03": 100, "2025-04": 150}},
+	}, nil)
+
+	report, err := NewReportUsecase(mockReportRepo).GetVintages(context.Background(), from, to, asOf)
+	assert.NoError(t, err)
+	assert.Equal(t, []*entity.Vintage{{
+		Cohort:    "2025-02",
+		LoanCount: 2,
+		Disbursed: 1000,
+		Curve: []*entity.VintagePoint{
+			{MonthsOnBook: 0, Repaid: 0, RepaidRatio: 0},
+			{MonthsOnBook: 1, Repaid: 100, RepaidRatio: 0.1},
+			{MonthsOnBook: 2, Repaid: 250, RepaidRatio: 0.25},
+		},
+	}}, report.Vintages)
+}
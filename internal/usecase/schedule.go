@@ -0,0 +1,154 @@
+package usecase
+
+import (
+	"loan-management/internal/calendar"
+	"loan-management/internal/entity"
+	"math"
+)
+
+// scheduleStrategy splits the principal of a loan between its installments
+type scheduleStrategy interface {
+	// amortize returns the principal repaid by each of n installments, with interest at rate per installment on the
+	// remaining balance when reducing
+	amortize(principal float64, n int, rate float64, reducing bool) []float64
+}
+
+func newScheduleStrategy(structure entity.RepaymentStructure, balloonPercent float64) scheduleStrategy {
+	switch structure {
+	case entity.RepaymentStructureEqualPrincipal:
+		return equalPrincipal{}
+	case entity.RepaymentStructureBullet:
+		return bullet{}
+	case entity.RepaymentStructureBalloon:
+		return balloon{percent: balloonPercent}
+	default:
+		return equalInstallment{}
+	}
+}
+
+// equalInstallment is an annuity on a reducing balance. A flat interest is the same every installment, so the
+// principal is repaid evenly
+type equalInstallment struct{}
+
+func (equalInstallment) amortize(principal float64, n int, rate float64, reducing bool) []float64 {
+	if !reducing || rate == 0 {
+		return equalPrincipal{}.amortize(principal, n, rate, reducing)
+	}
+
+	payment := principal * rate / (1 - math.Pow(1+rate, -float64(n)))
+	amounts := make([]float64, n)
+	balance := principal
+	for i := range amounts {
+		amounts[i] = payment - balance*rate
+		balance -= amounts[i]
+	}
+	return amounts
+}
+
+type equalPrincipal struct{}
+
+func (equalPrincipal) amortize(principal float64, n int, rate float64, reducing bool) []float64 {
+	amounts := make([]float64, n)
+	for i := range amounts {
+		amounts[i] = principal / float64(n)
+	}
+	return amounts
+}
+
+type bullet struct{}
+
+func (bullet) amortize(principal float64, n int, rate float64, reducing bool) []float64 {
+	amounts := make([]float64, n)
+	amounts[n-1] = principal
+	return amounts
+}
+
+// balloon repays the principal but the balloon in equal installments, the balloon with the last one
+type balloon struct {
+	percent float64
+}
+
+func (b balloon) amortize(principal float64, n int, rate float64, reducing bool) []float64 {
+	last := principal * b.percent / 100
+	amounts := equalInstallment{}.amortize(principal-last, n, rate, reducing)
+	amounts[n-1] += last
+	return amounts
+}
+
+// repaymentTerms describe a weekly schedule at an annual rate. The tenure counts the grace periods, which have no
+// installment, and the interest only periods that follow them; the remaining installments repay the principal
+type repaymentTerms struct {
+	principal    float64
+	rate         float64
+	interestType entity.InterestType
+	// strategy defaults to equal installments
+	strategy            scheduleStrategy
+	tenure              int
+	gracePeriods        int
+	graceInterest       entity.GraceInterest
+	interestOnlyPeriods int
+	// start is a week before the first period, firstPaymentNo numbers its installment
+	start          entity.Date
+	firstPaymentNo int32
+	// businessDays moves the due dates off weekends and holidays, they stay on the period's day when nil
+	businessDays *calendar.Calendar
+}
+
+// schedule generates the installments of the terms, without loan ID, and the grace interest capitalized into the
+// principal they repay
+func (t repaymentTerms) schedule() ([]entity.CreatePaymentPayload, float64) {
+	weeklyRate := t.rate / 100 / 52
+	graceInterest := t.principal * weeklyRate * float64(t.gracePeriods)
+
+	principal := t.principal
+	var capitalized, accrued float64
+	if t.graceInterest == entity.GraceInterestCapitalized {
+		capitalized = graceInterest
+		principal += capitalized
+	} else {
+		accrued = graceInterest
+	}
+
+	strategy := t.strategy
+	if strategy == nil {
+		strategy = equalInstallment{}
+	}
+	reducing := t.interestType == entity.InterestTypeReducingAnnual
+	amortizing := t.tenure - t.gracePeriods - t.interestOnlyPeriods
+	amounts := strategy.amortize(principal, amortizing, weeklyRate, reducing)
+
+	payments := make([]entity.CreatePaymentPayload, 0, t.tenure-t.gracePeriods)
+	balance := principal
+	for week := t.gracePeriods + 1; week <= t.tenure; week++ {
+		payment := entity.CreatePaymentPayload{
+			DueDate:   t.businessDays.Adjust(t.start.AddDays(week * 7)),
+			PaymentNo: t.firstPaymentNo + int32(len(payments)),
+			Interest:  principal * weeklyRate,
+		}
+		if reducing {
+			payment.Interest = balance * weeklyRate
+		}
+		if i := week - t.gracePeriods - t.interestOnlyPeriods - 1; i >= 0 {
+			payment.Amount = amounts[i]
+			// the last installment repays what rounding left of the balance
+			if i == amortizing-1 {
+				payment.Amount = balance
+			}
+			payment.Interest += accrued / float64(amortizing)
+		}
+		balance -= payment.Amount
+		payment.TotalAmount = payment.Amount + payment.Interest
+		payments = append(payments, payment)
+	}
+
+	return payments, capitalized
+}
+
+// scheduleTotal is the outstanding of a new schedule
+func scheduleTotal(payments []entity.CreatePaymentPayload) float64 {
+	var total float64
+	for _, payment := range payments {
+		total += payment.TotalAmount
+	}
+	return total
+}
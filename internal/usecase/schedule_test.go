@@ -0,0 +1,163 @@
+package usecase
+
+import (
+	"loan-management/internal/calendar"
+	"loan-management/internal/entity"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepaymentTermsSchedule(t *testing.T) {
+	start := entity.NewDate(2025, 1, 1)
+	// 5200 at 10% is 10 of interest a week
+	terms := repaymentTerms{principal: 5200, rate: 10, tenure: 6, gracePeriods: 2, interestOnlyPeriods: 1, start: start, firstPaymentNo: 1}
+
+	t.Run("Accrued Grace Interest", func(t *testing.T) {
+		schedule, capitalized := terms.schedule()
+
+		assert.Zero(t, capitalized)
+		assert.Len(t, schedule, 4)
+		assert.Equal(t, start.AddDays(21), schedule[0].DueDate)
+		assert.Zero(t, schedule[0].Amount)
+		assert.InDelta(t, 10, schedule[0].TotalAmount, 1e-9)
+		for _, payment := range schedule[1:] {
+			assert.InDelta(t, 5200.0/3, payment.Amount, 1e-9)
+			assert.InDelta(t, 10+20.0/3, payment.Interest, 1e-9)
+		}
+		assert.Equal(t, int32(4), schedule[3].PaymentNo)
+		assert.Equal(t, start.AddDays(42), schedule[3].DueDate)
+		assert.InDelta(t, 5260, scheduleTotal(schedule), 1e-9)
+	})
+
+	t.Run("Capitalized Grace Interest", func(t *testing.T) {
+		capitalizedTerms := terms
+		capitalizedTerms.graceInterest = entity.GraceInterestCapitalized
+
+		schedule, capitalized := capitalizedTerms.schedule()
+
+		assert.InDelta(t, 20, capitalized, 1e-9)
+		assert.Len(t, schedule, 4)
+		assert.InDelta(t, 1740, schedule[3].Amount, 1e-9)
+		assert.InDelta(t, 5220*0.1/52, schedule[3].Interest, 1e-9)
+	})
+
+	t.Run("Without Grace", func(t *testing.T) {
+		schedule, _ := repaymentTerms{principal: 5200, rate: 10, tenure: 2, start: start, firstPaymentNo: 3}.schedule()
+
+		assert.Len(t, schedule, 2)
+		assert.Equal(t, int32(3), schedule[0].PaymentNo)
+		assert.Equal(t, start.AddDays(7), schedule[0].DueDate)
+		assert.InDelta(t, 2610, schedule[1].TotalAmount, 1e-9)
+	})
+}
+
+func TestRepaymentStructures(t *testing.T) {
+	start := entity.NewDate(2025, 1, 1)
+	// 5200 at 10% is 10 of interest a week on the full principal
+	terms := repaymentTerms{principal: 5200, rate: 10, tenure: 4, start: start, firstPaymentNo: 1}
+
+	principal := func(schedule []entity.CreatePaymentPayload) float64 {
+		var total float64
+		for _, payment := range schedule {
+			total += payment.Amount
+		}
+		return total
+	}
+
+	t.Run("Equal Installment Reducing", func(t *testing.T) {
+		reducing := terms
+		reducing.interestType = entity.InterestTypeReducingAnnual
+
+		schedule, _ := reducing.schedule()
+
+		assert.Len(t, schedule, 4)
+		assert.InDelta(t, 10, schedule[0].Interest, 1e-9)
+		for _, payment := range schedule[1:] {
+			assert.InDelta(t, schedule[0].TotalAmount, payment.TotalAmount, 1e-9)
+		}
+		assert.Less(t, schedule[3].Interest, schedule[2].Interest)
+		assert.InDelta(t, 5200, principal(schedule), 1e-9)
+	})
+
+	t.Run("Equal Principal Reducing", func(t *testing.T) {
+		reducing := terms
+		reducing.interestType = entity.InterestTypeReducingAnnual
+		reducing.strategy = newScheduleStrategy(entity.RepaymentStructureEqualPrincipal, 0)
+
+		schedule, _ := reducing.schedule()
+
+		for i, interest := range []float64{10, 7.5, 5, 2.5} {
+			assert.InDelta(t, 1300, schedule[i].Amount, 1e-9)
+			assert.InDelta(t, interest, schedule[i].Interest, 1e-9)
+		}
+	})
+
+	t.Run("Bullet", func(t *testing.T) {
+		bulletTerms := terms
+		bulletTerms.strategy = newScheduleStrategy(entity.RepaymentStructureBullet, 0)
+
+		schedule, _ := bulletTerms.schedule()
+
+		for _, payment := range schedule[:3] {
+			assert.Zero(t, payment.Amount)
+			assert.InDelta(t, 10, payment.TotalAmount, 1e-9)
+		}
+		assert.InDelta(t, 5210, schedule[3].TotalAmount, 1e-9)
+		assert.InDelta(t, 5240, scheduleTotal(schedule), 1e-9)
+	})
+
+	t.Run("Balloon", func(t *testing.T) {
+		balloonTerms := terms
+		balloonTerms.strategy = newScheduleStrategy(entity.RepaymentStructureBalloon, 40)
+
+		schedule, _ := balloonTerms.schedule()
+
+		for _, payment := range schedule[:3] {
+			assert.InDelta(t, 780, payment.Amount, 1e-9)
+		}
+		assert.InDelta(t, 2860, schedule[3].Amount, 1e-9)
+		assert.InDelta(t, 5200, principal(schedule), 1e-9)
+	})
+
+	t.Run("Balloon Reducing", func(t *testing.T) {
+		balloonTerms := terms
+		balloonTerms.interestType = entity.InterestTypeReducingAnnual
+		balloonTerms.strategy = newScheduleStrategy(entity.RepaymentStructureBalloon, 40)
+
+		schedule, _ := balloonTerms.schedule()
+
+		for _, payment := range schedule[1:3] {
+			assert.InDelta(t, schedule[0].TotalAmount, payment.TotalAmount, 1e-9)
+		}
+		assert.Greater(t, schedule[3].Amount, float64(2080))
+		assert.InDelta(t, 5200, principal(schedule), 1e-9)
+	})
+}
+
+func TestRepaymentTermsBusinessDays(t *testing.T) {
+	// installments fall due on Fridays, the second one on Christmas Day
+	start := entity.NewDate(2026, 12, 11)
+	holidays := []*entity.Holiday{{Date: entity.NewDate(2026, 12, 25), Name: "Christmas Day"}}
+	terms := repaymentTerms{principal: 5200, rate: 10, tenure: 3, start: start, firstPaymentNo: 1}
+
+	t.Run("Following", func(t *testing.T) {
+		withCalendar := terms
+		withCalendar.businessDays = calendar.New(entity.BusinessDayFollowing, holidays)
+
+		schedule, _ := withCalendar.schedule()
+
+		assert.Equal(t, entity.NewDate(2026, 12, 18), schedule[0].DueDate)
+		assert.Equal(t, entity.NewDate(2026, 12, 28), schedule[1].DueDate)
+		assert.Equal(t, entity.NewDate(2027, 1, 1), schedule[2].DueDate)
+	})
+
+	t.Run("Preceding", func(t *testing.T) {
+		withCalendar := terms
+		withCalendar.businessDays = calendar.New(entity.BusinessDayPreceding, holidays)
+
+		schedule, _ := withCalendar.schedule()
+
+		assert.Equal(t, entity.NewDate(2026, 12, 24), schedule[1].DueDate)
+	})
+}
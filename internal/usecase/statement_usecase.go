@@ -0,0 +1,246 @@
+package usecase
+
+import (
+	"context"
+	"loan-management/internal/apperror"
+	"loan-management/internal/entity"
+	"loan-management/internal/repository"
+	"sort"
+	"time"
+)
+
+var ErrInvalidStatementPeriod = apperror.Validation("INVALID_STATEMENT_PERIOD", "The statement period ends before it starts")
+
+type StatementUsecaseInterface interface {
+	GetStatement(ctx context.Context, loanID int64, from time.Time, to time.Time) (*entity.LoanStatement, error)
+}
+
+// StatementUsecase replays the history of a loan into a statement. Transactions are found through the ledger,
+// the only place a reversed transaction stays linked to its loan
+type StatementUsecase struct {
+	loanRepo        repository.LoanRepository
+	paymentRepo     repository.PaymentRepository
+	transactionRepo repository.TransactionRepository
+	ledgerRepo      repository.LedgerRepository
+}
+
+func NewStatementUsecase(loanRepo repository.LoanRepository, paymentRepo repository.PaymentRepository, transactionRepo repository.TransactionRepository, ledgerRepo repository.LedgerRepository) *StatementUsecase {
+	return &StatementUsecase{
+		loanRepo:        loanRepo,
+		paymentRepo:     paymentRepo,
+		transactionRepo: transactionRepo,
+		ledgerRepo:      ledgerRepo,
+	}
+}
+
+// GetStatement lists the installments due and the activity between from and to, inclusive. Without from and to
+// the statement covers the whole life of the loan, from the disbursement or first due date to the last due date or now
+func (u *StatementUsecase) GetStatement(ctx context.Context, loanID int64, from time.Time, to time.Time) (*entity.LoanStatement, error) {
+	loan, err := u.loanRepo.GetLoanByID(ctx, loanID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if loan == nil {
+		return nil, ErrLoanNotFound
+	}
+
+	if err := authorizeOwner(ctx, entity.PermLoanRead, entity.PermLoanReadOwn, loan.UserID); err != nil {
+		return nil, err
+	}
+
+	payments, err := u.paymentRepo.GetPaymentsByLoanID(ctx, loanID, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// loans booked before approvals existed have no disbursement date
+	disbursedAt := loan.CreatedAt
+	if loan.DisbursedAt != nil {
+		disbursedAt = *loan.DisbursedAt
+	}
+
+	// a restructured schedule can end before the installments it closed
+	defaultFrom, defaultTo := disbursedAt, now()
+	for _, payment := range payments {
+		dueAt := payment.DueDate.Start(businessLocation)
+		if dueAt.Before(defaultFrom) {
+			defaultFrom = dueAt
+		}
+		if dueAt.After(defaultTo) {
+			defaultTo = dueAt
+		}
+	}
+	if from.IsZero() {
+		from = defaultFrom
+	}
+	if to.IsZero() {
+		to = defaultTo
+	}
+	if to.Before(from) {
+		return nil, ErrInvalidStatementPeriod
+	}
+
+	transactionIDs, reversedAt, err := u.transactionIDs(ctx, loanID, payments)
+	if err != nil {
+		return nil, err
+	}
+
+	statement := &entity.LoanStatement{
+		Loan:         loan,
+		From:         from,
+		To:           to,
+		GeneratedAt:  now(),
+		Schedule:     []*entity.Payment{},
+		Transactions: []*entity.Transaction{},
+		Entries:      []*entity.StatementEntry{},
+	}
+
+	restructures, err := u.loanRepo.GetRestructuresByLoanID(ctx, loanID)
+	if err != nil {
+		return nil, err
+	}
+
+	// the disbursement covers the original schedule, the installments of a restructure come with it
+	var total float64
+	for _, payment := range payments {
+		if len(restructures) == 0 || payment.PaymentNo < restructures[0].FirstPaymentNo {
+			total += payment.TotalAmount
+		}
+		if dueAt := payment.DueDate.Start(businessLocation); !dueAt.Before(from) && !dueAt.After(to) {
+			statement.Schedule = append(statement.Schedule, payment)
+		}
+	}
+
+	entries := []*entity.StatementEntry{{Date: disbursedAt, Type: entity.StatementEntryDisbursement, Debit: total}}
+	for _, restructure := range restructures {
+		entry := &entity.StatementEntry{Date: restructure.CreatedAt, Type: entity.StatementEntryRestructure}
+		if change := restructure.Outstanding - restructure.PreviousOutstanding; change > 0 {
+			entry.Debit = change
+		} else {
+			entry.Credit = -change
+		}
+		entries = append(entries, entry)
+	}
+
+	for _, id := range transactionIDs {
+		trx, err := u.transactionRepo.GetTransactionByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		paidAt := trx.CreatedAt
+		if trx.PaidAt != nil {
+			paidAt = *trx.PaidAt
+		}
+		if !paidAt.Before(from) && !paidAt.After(to) {
+			statement.Transactions = append(statement.Transactions, trx)
+		}
+
+		// a transaction paying several loans only credits this one with its allocation
+		amount := trx.TotalAmount
+		allocations, err := u.transactionRepo.GetAllocationsByTransactionID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		for _, allocation := range allocations {
+			if allocation.LoanID == loanID {
+				amount = allocation.Amount
+			}
+		}
+
+		var installments []int32
+		for _, payment := range payments {
+			if payment.TransactionID != nil && *payment.TransactionID == trx.ID {
+				installments = append(installments, payment.PaymentNo)
+			}
+		}
+
+		if trx.Penalty > 0 {
+			entries = append(entries, &entity.StatementEntry{Date: paidAt, Type: entity.StatementEntryPenalty, TransactionID: trx.ID, Debit: trx.Penalty})
+		}
+		entries = append(entries, &entity.StatementEntry{Date: paidAt, Type: entity.StatementEntryPayment, TransactionID: trx.ID, Installments: installments, Credit: amount + trx.Penalty})
+
+		if trx.Status != entity.TransactionStatusReversed {
+			continue
+		}
+
+		date, ok := reversedAt[trx.ID]
+		if !ok {
+			date = paidAt
+		}
+		entries = append(entries, &entity.StatementEntry{Date: date, Type: entity.StatementEntryReversal, TransactionID: trx.ID, Debit: amount + trx.Penalty})
+		if trx.Penalty > 0 {
+			entries = append(entries, &entity.StatementEntry{Date: date, Type: entity.StatementEntryPenaltyReversal, TransactionID: trx.ID, Credit: trx.Penalty})
+		}
+	}
+
+	// a stable sort keeps a penalty before its payment and a reversal after it
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Date.Before(entries[j].Date) })
+
+	var balance float64
+	for _, entry := range entries {
+		if entry.Date.After(to) {
+			break
+		}
+
+		balance += entry.Debit - entry.Credit
+		entry.Balance = balance
+		if entry.Date.Before(from) {
+			statement.OpeningBalance = balance
+			continue
+		}
+
+		switch entry.Type {
+		case entity.StatementEntryPayment:
+			statement.TotalPaid += entry.Credit
+		case entity.StatementEntryReversal:
+			statement.TotalPaid -= entry.Debit
+		case entity.StatementEntryPenalty:
+			statement.TotalPenalty += entry.Debit
+		case entity.StatementEntryPenaltyReversal:
+			statement.TotalPenalty -= entry.Credit
+		}
+		statement.Entries = append(statement.Entries, entry)
+	}
+	statement.ClosingBalance = balance
+
+	return statement, nil
+}
+
+// transactionIDs returns the transactions of the loan in posting order with the time each reversed one was
+// reversed. Transactions settled before the ledger existed are only known through their payments
+func (u *StatementUsecase) transactionIDs(ctx context.Context, loanID int64, payments []*entity.Payment) ([]int64, map[int64]time.Time, error) {
+	journal, err := u.ledgerRepo.GetJournalEntriesByLoanID(ctx, loanID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var ids []int64
+	seen := map[int64]bool{}
+	reversedAt := map[int64]time.Time{}
+
+	for _, entry := range journal {
+		if entry.ReferenceType != entity.JournalReferenceTransaction {
+			continue
+		}
+		if entry.ReversalOf != nil {
+			if _, ok := reversedAt[entry.ReferenceID]; !ok {
+				reversedAt[entry.ReferenceID] = entry.PostedAt
+			}
+			continue
+		}
+		if !seen[entry.ReferenceID] {
+			seen[entry.ReferenceID] = true
+			ids = append(ids, entry.ReferenceID)
+		}
+	}
+
+	for _, payment := range payments {
+		if payment.TransactionID != nil && !seen[*payment.TransactionID] {
+			seen[*payment.TransactionID] = true
+			ids = append(ids, *payment.TransactionID)
+		}
+	}
+
+	return ids, reversedAt, nil
+}
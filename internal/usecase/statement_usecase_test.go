@@ -0,0 +1,191 @@
+package usecase
+
+import (
+	"context"
+	"loan-management/internal/entity"
+	internalMock "loan-management/internal/mock"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func setupStatementMocks() (*StatementUsecase, *internalMock.MockLoanRepository, *internalMock.MockPaymentRepository, *internalMock.MockTransactionRepository, *internalMock.MockLedgerRepository) {
+	mockLoanRepo := new(internalMock.MockLoanRepository)
+	mockPaymentRepo := new(internalMock.MockPaymentRepository)
+	mockTransactionRepo := new(internalMock.MockTransactionRepository)
+	mockLedgerRepo := new(internalMock.MockLedgerRepository)
+
+	statementUsecase := NewStatementUsecase(mockLoanRepo, mockPaymentRepo, mockTransactionRepo, mockLedgerRepo)
+
+	return statementUsecase, mockLoanRepo, mockPaymentRepo, mockTransactionRepo, mockLedgerRepo
+}
+
+func TestGetStatement(t *testing.T) {
+	mockTime := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return mockTime }
+	defer func() { now = time.Now }()
+
+	createdAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	firstPaidAt := time.Date(2025, 1, 8, 10, 0, 0, 0, time.UTC)
+	reversedAt := time.Date(2025, 1, 9, 10, 0, 0, 0, time.UTC)
+	secondPaidAt := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	secondID := int64(2)
+
+	loan := &entity.Loan{ID: 1, UserID: 1, Amount: 1000, CreatedAt: createdAt}
+	payments := []*entity.Payment{
+		{ID: 11, LoanID: 1, PaymentNo: 1, DueDate: entity.DateOf(createdAt, time.UTC).AddDays(7), TotalAmount: 500, Status: entity.PaymentStatusPaid, TransactionID: &secondID, PaidAt: &secondPaidAt},
+		{ID: 12, LoanID: 1, PaymentNo: 2, DueDate: entity.DateOf(createdAt, time.UTC).AddDays(14), TotalAmount: 500, Status: entity.PaymentStatusActive},
+	}
+	journal := []*entity.JournalEntry{
+		{ID: 1, ReferenceType: entity.JournalReferenceLoan, ReferenceID: 1, PostedAt: createdAt},
+		{ID: 2, ReferenceType: entity.JournalReferenceTransaction, ReferenceID: 1, PostedAt: firstPaidAt},
+		{ID: 3, ReferenceType: entity.JournalReferenceTransaction, ReferenceID: 1, PostedAt: reversedAt, ReversalOf: &[]int64{2}[0]},
+		{ID: 4, ReferenceType: entity.JournalReferenceTransaction, ReferenceID: 2, PostedAt: secondPaidAt},
+	}
+	first := &entity.Transaction{ID: 1, TotalAmount: 500, Penalty: 20, Status: entity.TransactionStatusReversed, PaidAt: &firstPaidAt}
+	second := &entity.Transaction{ID: 2, TotalAmount: 500, Status: entity.TransactionStatusPaid, PaidAt: &secondPaidAt}
+
+	setup := func() (*StatementUsecase, *internalMock.MockLoanRepository) {
+		statementUsecase, mockLoanRepo, mockPaymentRepo, mockTransactionRepo, mockLedgerRepo := setupStatementMocks()
+
+		mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1), (*entity.LoanStatus)(nil)).Return(loan, nil)
+		mockLoanRepo.On("GetRestructuresByLoanID", mock.Anything, int64(1)).Return(nil, nil)
+		mockPaymentRepo.On("GetPaymentsByLoanID", mock.Anything, int64(1), (*entity.PaymentStatus)(nil), (*entity.Date)(nil)).Return(payments, nil)
+		mockLedgerRepo.On("GetJournalEntriesByLoanID", mock.Anything, int64(1)).Return(journal, nil)
+		mockTransactionRepo.On("GetTransactionByID", mock.Anything, int64(1)).Return(first, nil)
+		mockTransactionRepo.On("GetTransactionByID", mock.Anything, int64(2)).Return(second, nil)
+		mockTransactionRepo.On("GetAllocationsByTransactionID", mock.Anything, mock.Anything).Return(nil, nil)
+
+		return statementUsecase, mockLoanRepo
+	}
+
+	t.Run("Success GetStatement - Whole Loan", func(t *testing.T) {
+		statementUsecase, _ := setup()
+
+		statement, err := statementUsecase.GetStatement(context.Background(), 1, time.Time{}, time.Time{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, createdAt, statement.From)
+		assert.Equal(t, mockTime, statement.To)
+		assert.Len(t, statement.Schedule, 2)
+		assert.Len(t, statement.Transactions, 2)
+		assert.Equal(t, []*entity.StatementEntry{
+			{Date: createdAt, Type: entity.StatementEntryDisbursement, Debit: 1000, Balance: 1000},
+			{Date: firstPaidAt, Type: entity.StatementEntryPenalty, TransactionID: 1, Debit: 20, Balance: 1020},
+			{Date: firstPaidAt, Type: entity.StatementEntryPayment, TransactionID: 1, Credit: 520, Balance: 500},
+			{Date: reversedAt, Type: entity.StatementEntryReversal, TransactionID: 1, Debit: 520, Balance: 1020},
+			{Date: reversedAt, Type: entity.StatementEntryPenaltyReversal, TransactionID: 1, Credit: 20, Balance: 1000},
+			{Date: secondPaidAt, Type: entity.StatementEntryPayment, TransactionID: 2, Installments: []int32{1}, Credit: 500, Balance: 500},
+		}, statement.Entries)
+		assert.Equal(t, float64(0), statement.OpeningBalance)
+		assert.Equal(t, float64(500), statement.ClosingBalance)
+		assert.Equal(t, float64(500), statement.TotalPaid)
+		assert.Equal(t, float64(0), statement.TotalPenalty)
+	})
+
+	t.Run("Success GetStatement - Period", func(t *testing.T) {
+		statementUsecase, _ := setup()
+
+		from := time.Date(2025, 1, 9, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2025, 1, 15, 5, 0, 0, 0, time.UTC)
+		statement, err := statementUsecase.GetStatement(context.Background(), 1, from, to)
+
+		assert.NoError(t, err)
+		assert.Equal(t, float64(500), statement.OpeningBalance)
+		assert.Len(t, statement.Entries, 2)
+		assert.Equal(t, entity.StatementEntryReversal, statement.Entries[0].Type)
+		assert.Equal(t, float64(1000), statement.ClosingBalance)
+		assert.Equal(t, float64(-520), statement.TotalPaid)
+		assert.Equal(t, []*entity.Payment{payments[1]}, statement.Schedule)
+		assert.Empty(t, statement.Transactions)
+	})
+
+	t.Run("Success GetStatement - Restructured", func(t *testing.T) {
+		statementUsecase, mockLoanRepo, mockPaymentRepo, mockTransactionRepo, mockLedgerRepo := setupStatementMocks()
+
+		restructuredAt := time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC)
+		restructured := []*entity.Payment{
+			payments[0],
+			{ID: 12, LoanID: 1, PaymentNo: 2, DueDate: entity.DateOf(createdAt, time.UTC).AddDays(14), TotalAmount: 500, Status: entity.PaymentStatusRestructured},
+			{ID: 13, LoanID: 1, PaymentNo: 3, DueDate: entity.DateOf(restructuredAt, time.UTC).AddDays(7), TotalAmount: 300, Status: entity.PaymentStatusActive},
+			{ID: 14, LoanID: 1, PaymentNo: 4, DueDate: entity.DateOf(restructuredAt, time.UTC).AddDays(14), TotalAmount: 300, Status: entity.PaymentStatusActive},
+		}
+
+		mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1), (*entity.LoanStatus)(nil)).Return(loan, nil)
+		mockLoanRepo.On("GetRestructuresByLoanID", mock.Anything, int64(1)).Return([]*entity.LoanRestructure{
+			{ID: 1, LoanID: 1, PreviousOutstanding: 500, Outstanding: 600, FirstPaymentNo: 3, CreatedAt: restructuredAt},
+		}, nil)
+		mockPaymentRepo.On("GetPaymentsByLoanID", mock.Anything, int64(1), (*entity.PaymentStatus)(nil), (*entity.Date)(nil)).Return(restructured, nil)
+		mockLedgerRepo.On("GetJournalEntriesByLoanID", mock.Anything, int64(1)).Return(journal[3:], nil)
+		mockTransactionRepo.On("GetTransactionByID", mock.Anything, int64(2)).Return(second, nil)
+		mockTransactionRepo.On("GetAllocationsByTransactionID", mock.Anything, int64(2)).Return(nil, nil)
+
+		statement, err := statementUsecase.GetStatement(context.Background(), 1, time.Time{}, time.Time{})
+
+		assert.NoError(t, err)
+		assert.Len(t, statement.Schedule, 4)
+		assert.Equal(t, []*entity.StatementEntry{
+			{Date: createdAt, Type: entity.StatementEntryDisbursement, Debit: 1000, Balance: 1000},
+			{Date: secondPaidAt, Type: entity.StatementEntryPayment, TransactionID: 2, Installments: []int32{1}, Credit: 500, Balance: 500},
+			{Date: restructuredAt, Type: entity.StatementEntryRestructure, Debit: 100, Balance: 600},
+		}, statement.Entries)
+		assert.Equal(t, float64(600), statement.ClosingBalance)
+	})
+
+	t.Run("Success GetStatement - Consolidated Transaction", func(t *testing.T) {
+		statementUsecase, mockLoanRepo, mockPaymentRepo, mockTransactionRepo, mockLedgerRepo := setupStatementMocks()
+		consolidated := &entity.Transaction{ID: 2, TotalAmount: 800, Status: entity.TransactionStatusPaid, PaidAt: &secondPaidAt}
+
+		mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1), (*entity.LoanStatus)(nil)).Return(loan, nil)
+		mockLoanRepo.On("GetRestructuresByLoanID", mock.Anything, int64(1)).Return(nil, nil)
+		mockPaymentRepo.On("GetPaymentsByLoanID", mock.Anything, int64(1), (*entity.PaymentStatus)(nil), (*entity.Date)(nil)).Return(payments, nil)
+		mockLedgerRepo.On("GetJournalEntriesByLoanID", mock.Anything, int64(1)).Return(journal[3:], nil)
+		mockTransactionRepo.On("GetTransactionByID", mock.Anything, int64(2)).Return(consolidated, nil)
+		mockTransactionRepo.On("GetAllocationsByTransactionID", mock.Anything, int64(2)).Return([]*entity.TransactionAllocation{
+			{ID: 1, TransactionID: 2, LoanID: 1, Amount: 500},
+			{ID: 2, TransactionID: 2, LoanID: 2, Amount: 300},
+		}, nil)
+
+		statement, err := statementUsecase.GetStatement(context.Background(), 1, time.Time{}, time.Time{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, float64(500), statement.Entries[1].Credit)
+		assert.Equal(t, float64(500), statement.ClosingBalance)
+		assert.Equal(t, float64(500), statement.TotalPaid)
+	})
+
+	t.Run("Failed GetStatement - Invalid Period", func(t *testing.T) {
+		statementUsecase, _ := setup()
+
+		statement, err := statementUsecase.GetStatement(context.Background(), 1, mockTime, createdAt)
+
+		assert.Nil(t, statement)
+		assert.ErrorIs(t, err, ErrInvalidStatementPeriod)
+	})
+
+	t.Run("Failed GetStatement - Another Borrower's Loan", func(t *testing.T) {
+		statementUsecase, mockLoanRepo, mockPaymentRepo, _, _ := setupStatementMocks()
+
+		mockLoanRepo.On("GetLoanByID", mock.Anything, int64(1), (*entity.LoanStatus)(nil)).Return(loan, nil)
+
+		borrower := entity.WithIdentity(context.Background(), &entity.Identity{Type: entity.IdentityTypeBorrower, Role: entity.RoleBorrower, UserID: 2})
+		statement, err := statementUsecase.GetStatement(borrower, 1, time.Time{}, time.Time{})
+
+		assert.Nil(t, statement)
+		assert.ErrorIs(t, err, ErrForbidden)
+		mockPaymentRepo.AssertNotCalled(t, "GetPaymentsByLoanID", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failed GetStatement - Loan Not Found", func(t *testing.T) {
+		statementUsecase, mockLoanRepo, _, _, _ := setupStatementMocks()
+
+		mockLoanRepo.On("GetLoanByID", mock.Anything, int64(9), (*entity.LoanStatus)(nil)).Return(nil, nil)
+
+		statement, err := statementUsecase.GetStatement(context.Background(), 9, time.Time{}, time.Time{})
+
+		assert.Nil(t, statement)
+		assert.ErrorIs(t, err, ErrLoanNotFound)
+	})
+}
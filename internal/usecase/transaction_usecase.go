@@ -2,29 +2,82 @@ package usecase
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"loan-management/internal/apperror"
 	"loan-management/internal/entity"
 	"loan-management/internal/repository"
+	"loan-management/internal/validation"
+	"math"
+	"sort"
 	"time"
 )
 
 var now = time.Now
 
+// businessLocation is the zone of the business days, due dates fall and overdue checks run on its calendar
+var businessLocation = time.UTC
+
+// SetBusinessLocation sets the zone the business days are counted in, before any usecase runs
+func SetBusinessLocation(loc *time.Location) {
+	businessLocation = loc
+}
+
+// BusinessLocation is the zone the business days are counted in, the dates of the API are its days
+func BusinessLocation() *time.Location {
+	return businessLocation
+}
+
+// today is the business day of now
+func today() entity.Date {
+	return entity.DateOf(now(), businessLocation)
+}
+
+var (
+	ErrBillingNotFound          = apperror.NotFound("BILLING_NOT_FOUND", "No billing available")
+	ErrAmountMismatch           = apperror.Validation("AMOUNT_MISMATCH", "The amount is different with the due amount")
+	ErrTransactionNotFound      = repository.ErrTransactionNotFound
+	ErrTransactionNotReversible = apperror.Conflict("TRANSACTION_NOT_REVERSIBLE", "Only paid transactions can be reversed")
+	ErrLoanReferenceRequired    = apperror.Validation("LOAN_REFERENCE_REQUIRED", "Either loan_id or virtual_account is required")
+	ErrBillsChanged             = apperror.Conflict("BILLS_CHANGED", "The bills changed while being paid, inquire again")
+)
+
+type TransactionUsecaseInterface interface {
+	InquiryTransaction(ctx context.Context, loanID int64, ahead entity.PayAhead) (*entity.TransactionInquiry, error)
+	InquiryTransactionByVirtualAccount(ctx context.Context, number string, ahead entity.PayAhead) (*entity.TransactionInquiry, error)
+	CreateTransaction(ctx context.Context, trxPayload *entity.CreateTransactionPayload) (*entity.Transaction, error)
+	InquiryUser(ctx context.Context, userID int64) (*entity.UserInquiry, error)
+	CreateUserTransaction(ctx context.Context, payload *entity.CreateUserTransactionPayload) (*entity.Transaction, error)
+	GetTransactionByExternalID(ctx context.Context, channel string, externalID string) (*entity.Transaction, error)
+	ReverseTransaction(ctx context.Context, id int64) (*entity.Transaction, error)
+}
+
 type TransactionUsecase struct {
 	transactionRepository repository.TransactionRepository
 	loanUsecase           LoanUsecaseInterface
 	paymentUsecase        PaymentUsecaseInterface
+	auditUsecase          AuditUsecaseInterface
+	ledgerUsecase         LedgerUsecaseInterface
+	eventUsecase          EventUsecaseInterface
 }
 
-func NewTransactionUsecase(transactionRepository repository.TransactionRepository, loanUsecase LoanUsecaseInterface, paymentUsecase PaymentUsecaseInterface) *TransactionUsecase {
+func NewTransactionUsecase(transactionRepository repository.TransactionRepository, loanUsecase LoanUsecaseInterface, paymentUsecase PaymentUsecaseInterface, auditUsecase AuditUsecaseInterface, ledgerUsecase LedgerUsecaseInterface, eventUsecase EventUsecaseInterface) *TransactionUsecase {
 	return &TransactionUsecase{
 		transactionRepository: transactionRepository,
 		loanUsecase:           loanUsecase,
 		paymentUsecase:        paymentUsecase,
+		auditUsecase:          auditUsecase,
+		ledgerUsecase:         ledgerUsecase,
+		eventUsecase:          eventUsecase,
 	}
 }
 
-func (u *TransactionUsecase) InquiryTransaction(ctx context.Context, loanID int64) (*entity.TransactionInquiry, error) {
+// InquiryTransaction returns the bills due of an active loan, followed by the installments paid ahead when asked
+func (u *TransactionUsecase) InquiryTransaction(ctx context.Context, loanID int64, ahead entity.PayAhead) (*entity.TransactionInquiry, error) {
+	if err := validation.Struct(ahead); err != nil {
+		return nil, err
+	}
+
 	loanStatusActive := entity.LoanStatusActive
 	loan, err := u.loanUsecase.GetLoanByID(ctx, loanID, &loanStatusActive)
 	if err != nil {
@@ -32,77 +85,195 @@ func (u *TransactionUsecase) InquiryTransaction(ctx context.Context, loanID int6
 	}
 
 	if loan == nil {
-		return nil, nil
+		return nil, ErrLoanNotFound
 	}
 
-	duePayments, err := u.loanUsecase.GetLoanDuePayments(ctx, loan)
+	if err := authorizeOwner(ctx, entity.PermTransactionInquiry, entity.PermTransactionInquiryOwn, loan.UserID); err != nil {
+		return nil, err
+	}
 
+	bills, billsAhead, err := u.bills(ctx, loan, ahead)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(duePayments) <= 0 {
-		return nil, nil
+	if len(bills) <= 0 {
+		return nil, ErrBillingNotFound
 	}
 
 	var amountDue float64
-	for _, payment := range duePayments {
+	for _, payment := range bills {
 		amountDue += payment.TotalAmount
 	}
 
 	// assume the payments is in asc order
-	latestDueDate := duePayments[len(duePayments)-1].DueDate
+	latestDueDate := bills[len(bills)-1].DueDate
 
 	inquiryResult := &entity.TransactionInquiry{
-		LoanID:     loan.ID,
-		AmountDue:  amountDue,
-		DueDate:    latestDueDate,
-		LoanDetail: loan,
-		Bills:      duePayments,
+		LoanID:            loan.ID,
+		AmountDue:         amountDue,
+		DueDate:           latestDueDate,
+		LoanDetail:        loan,
+		Bills:             bills,
+		InstallmentsAhead: billsAhead,
 	}
 
 	return inquiryResult, nil
 }
 
+func (u *TransactionUsecase) InquiryTransactionByVirtualAccount(ctx context.Context, number string, ahead entity.PayAhead) (*entity.TransactionInquiry, error) {
+	loan, err := u.loanUsecase.GetLoanByVirtualAccount(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.InquiryTransaction(ctx, loan.ID, ahead)
+}
+
+// bills are the bills due of a loan followed by the unpaid installments ahead picks, in schedule order, and how
+// many of them are ahead. The installments after the last one picked stay untouched
+func (u *TransactionUsecase) bills(ctx context.Context, loan *entity.Loan, ahead entity.PayAhead) ([]*entity.Payment, int, error) {
+	duePayments, err := u.loanUsecase.GetLoanDuePayments(ctx, loan)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var amount float64
+	for _, payment := range duePayments {
+		amount += payment.TotalAmount
+	}
+
+	// an amount that doesn't go beyond the bills due pays nothing ahead
+	if ahead.Installments == 0 && ahead.Amount <= amount+entity.LedgerTolerance {
+		return duePayments, 0, nil
+	}
+
+	paymentStatusActive := entity.PaymentStatusActive
+	unpaid, err := u.paymentUsecase.GetPaymentsByLoanID(ctx, loan.ID, &paymentStatusActive, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	due := make(map[int64]bool, len(duePayments))
+	for _, payment := range duePayments {
+		due[payment.ID] = true
+	}
+
+	bills := append([]*entity.Payment{}, duePayments...)
+	for _, payment := range unpaid {
+		if due[payment.ID] {
+			continue
+		}
+		if ahead.Installments > 0 && len(bills)-len(duePayments) == ahead.Installments {
+			break
+		}
+		if ahead.Installments == 0 && amount+payment.TotalAmount > ahead.Amount+entity.LedgerTolerance {
+			break
+		}
+		bills = append(bills, payment)
+		amount += payment.TotalAmount
+	}
+
+	return bills, len(bills) - len(duePayments), nil
+}
+
+// billsPaid are the bills amount pays and their total, ErrBillingNotFound without any and ErrAmountMismatch when
+// amount doesn't add up to them
+func (u *TransactionUsecase) billsPaid(ctx context.Context, loan *entity.Loan, ahead entity.PayAhead, amount float64) ([]*entity.Payment, float64, error) {
+	bills, _, err := u.bills(ctx, loan, ahead)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if len(bills) <= 0 {
+		return nil, 0, ErrBillingNotFound
+	}
+
+	var amountDue float64
+	for _, payment := range bills {
+		amountDue += payment.TotalAmount
+	}
+
+	// summed up by the payer the installments can be off by a float residue
+	if math.Abs(amountDue-amount) > entity.LedgerTolerance {
+		return nil, 0, ErrAmountMismatch
+	}
+
+	return bills, amountDue, nil
+}
+
+// sameBills tells whether two reads of the bills of a loan are the same installments
+func sameBills(bills []*entity.Payment, other []*entity.Payment) bool {
+	if len(bills) != len(other) {
+		return false
+	}
+	for i := range bills {
+		if bills[i].ID != other[i].ID {
+			return false
+		}
+	}
+	return true
+}
+
 func (u *TransactionUsecase) CreateTransaction(ctx context.Context, trxPayload *entity.CreateTransactionPayload) (*entity.Transaction, error) {
+	if err := validation.Struct(trxPayload); err != nil {
+		return nil, err
+	}
+
+	if (trxPayload.LoanID == 0) == (trxPayload.VirtualAccount == "") {
+		return nil, ErrLoanReferenceRequired
+	}
+
+	loanID := trxPayload.LoanID
+	if trxPayload.VirtualAccount != "" {
+		vaLoan, err := u.loanUsecase.GetLoanByVirtualAccount(ctx, trxPayload.VirtualAccount)
+		if err != nil {
+			return nil, err
+		}
+		loanID = vaLoan.ID
+	}
 
 	// get active loan based on payload LoanID
 	loanStatusActive := entity.LoanStatusActive
-	loan, err := u.loanUsecase.GetLoanByID(ctx, trxPayload.LoanID, &loanStatusActive)
+	loan, err := u.loanUsecase.GetLoanByID(ctx, loanID, &loanStatusActive)
 
 	if err != nil {
 		return nil, err
 	}
 
 	if loan == nil {
-		return nil, nil
+		return nil, ErrLoanNotFound
 	}
-	// get all due payments that will be paid in this trx
-	duePayments, err := u.loanUsecase.GetLoanDuePayments(ctx, loan)
 
-	if err != nil {
+	if err := authorizeOwner(ctx, entity.PermTransactionCreate, entity.PermTransactionCreateOwn, loan.UserID); err != nil {
 		return nil, err
 	}
 
-	if len(duePayments) <= 0 {
-		return nil, nil
+	// get all due payments that will be paid in this trx, with the installments paid ahead
+	ahead := entity.PayAhead{Installments: trxPayload.Installments}
+	if ahead.Installments == 0 {
+		ahead.Amount = trxPayload.Amount
 	}
-
-	var amountDue float64
-	for _, payment := range duePayments {
-		amountDue += payment.TotalAmount
+	duePayments, amountDue, err := u.billsPaid(ctx, loan, ahead, trxPayload.Amount)
+	if err != nil {
+		return nil, err
 	}
 
-	// validate amount
-	if amountDue != trxPayload.Amount {
-		return nil, errors.New("The amount is different with the due amount")
+	// gateways and banks settle the amount due rounded to whole units, the difference is booked as rounding
+	var rounding float64
+	if trxPayload.Received > 0 && !sameAmount(trxPayload.Received, amountDue) {
+		if !sameAmount(math.Round(amountDue), trxPayload.Received) {
+			return nil, ErrAmountMismatch
+		}
+		rounding = trxPayload.Received - amountDue
 	}
 
 	/**
 	 * Begin the DB trx; steps:
-	 * 1. Create transaction
-	 * 2. Pay all payments (set status = paid, etc)
-	 * 3. Update Loan (outstanding, status, etc)
+	 * 1. Lock the loan row and read its bills again
+	 * 2. Create transaction
+	 * 3. Pay all payments (set status = paid, etc)
+	 * 4. Update Loan (outstanding, status, etc)
 	 */
 
 	tx, err := u.transactionRepository.BeginTx()
@@ -116,6 +287,23 @@ func (u *TransactionUsecase) CreateTransaction(ctx context.Context, trxPayload *
 		}
 	}()
 
+	// re-read the loan under lock so concurrent payments can't both decrement the same outstanding
+	loan, err = u.loanUsecase.GetLoanByIDForUpdate(tx, loan.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// the bills are read again under the lock, a payment committed before it was taken settled some of them
+	var current []*entity.Payment
+	current, _, err = u.bills(ctx, loan, ahead)
+	if err != nil {
+		return nil, err
+	}
+	if !sameBills(duePayments, current) {
+		err = ErrBillsChanged
+		return nil, err
+	}
+
 	// Create transaction step
 	timeNow := now()
 	trxStatusPaid := entity.TransactionStatusPaid
@@ -124,9 +312,12 @@ func (u *TransactionUsecase) CreateTransaction(ctx context.Context, trxPayload *
 	trx := &entity.Transaction{
 		TotalAmount: amountDue,
 		Penalty:     0,
+		Rounding:    rounding,
 		Status:      trxStatusPaid,
 		PaidAt:      &timeNow,
 		CreatedAt:   timeNow,
+		Channel:     trxPayload.Channel,
+		ExternalID:  trxPayload.ExternalID,
 	}
 	trxID, err := u.transactionRepository.CreateTransaction(tx, trx)
 	if err != nil {
@@ -139,24 +330,353 @@ func (u *TransactionUsecase) CreateTransaction(ctx context.Context, trxPayload *
 
 	trx.ID = trxID
 
-	// Pay all payments step
-	for _, payment := range duePayments {
-		err := u.paymentUsecase.PayPayment(tx, payment.ID, trxID, timeNow)
-		if err != nil {
+	// Pay the loan step
+	if _, err = u.settle(ctx, tx, loan, trx, duePayments); err != nil {
+		return nil, err
+	}
+
+	// Audit step
+	if err = u.auditUsecase.Record(ctx, tx, entity.AuditActionTransactionCreate, entity.AuditEntityTransaction, trx.ID, nil, trx); err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return trx, nil
+}
+
+// settle pays the bills of a loan, locked in tx, with the transaction: the bills are paid, the loan outstanding goes
+// down by their total and the part of the transaction the loan got is recorded
+func (u *TransactionUsecase) settle(ctx context.Context, tx *sql.Tx, loan *entity.Loan, trx *entity.Transaction, bills []*entity.Payment) (*entity.TransactionAllocation, error) {
+	paidAt := trx.CreatedAt
+	if trx.PaidAt != nil {
+		paidAt = *trx.PaidAt
+	}
+
+	var amount float64
+	paymentIDs := make([]int64, len(bills))
+	for i, payment := range bills {
+		if err := u.paymentUsecase.PayPayment(tx, payment.ID, trx.ID, paidAt); err != nil {
 			return nil, err
 		}
+		paymentIDs[i] = payment.ID
+		amount += payment.TotalAmount
 	}
 
-	// Update loan step
-	outstanding := loan.Outstanding - amountDue
+	// the installments don't add up exactly to the outstanding so drop the float residue
+	outstanding := loan.Outstanding - amount
+	if math.Abs(outstanding) < entity.LedgerTolerance {
+		outstanding = 0
+	}
 	if err := u.loanUsecase.UpdateLoanOutstanding(tx, outstanding, loan.ID); err != nil {
 		return nil, err
 	}
 
-	err = tx.Commit()
+	// a paid off loan stops accepting payments on its virtual account
+	if outstanding == 0 {
+		if err := u.loanUsecase.UpdateVirtualAccountStatus(tx, loan, entity.VirtualAccountStatusInactive); err != nil {
+			return nil, err
+		}
+	}
+
+	// every due payment is settled, so the loan is no longer delinquent
+	if loan.DelinquentSince != nil {
+		if err := u.loanUsecase.UpdateLoanDelinquency(tx, loan.ID, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := u.ledgerUsecase.PostRepayment(ctx, tx, loan.ID, trx, bills); err != nil {
+		return nil, err
+	}
+
+	allocation := &entity.TransactionAllocation{TransactionID: trx.ID, LoanID: loan.ID, Amount: amount, CreatedAt: paidAt}
+	if err := u.transactionRepository.CreateAllocation(tx, allocation); err != nil {
+		return nil, err
+	}
+
+	paidLoan := *loan
+	paidLoan.Outstanding = outstanding
+	paidLoan.DelinquentSince = nil
+	if outstanding == 0 && loan.VirtualAccount != "" {
+		paidLoan.VirtualAccountStatus = entity.VirtualAccountStatusInactive
+	}
+	if err := u.auditUsecase.Record(ctx, tx, entity.AuditActionLoanPay, entity.AuditEntityLoan, loan.ID, loan, &paidLoan); err != nil {
+		return nil, err
+	}
+
+	err := u.eventUsecase.Publish(ctx, tx, entity.EventPaymentPosted, entity.EventAggregateLoan, loan.ID, entity.PaymentPostedPayload{
+		LoanID:        loan.ID,
+		UserID:        loan.UserID,
+		TransactionID: trx.ID,
+		PaymentIDs:    paymentIDs,
+		Amount:        amount,
+		Penalty:       trx.Penalty,
+		Outstanding:   outstanding,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if outstanding == 0 {
+		err = u.eventUsecase.Publish(ctx, tx, entity.EventLoanPaidOff, entity.EventAggregateLoan, loan.ID, entity.LoanPaidOffPayload{
+			LoanID:        loan.ID,
+			UserID:        loan.UserID,
+			TransactionID: trx.ID,
+			PaidAt:        paidAt,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return allocation, nil
+}
+
+// InquiryUser returns the bills due of every active loan of a user, so they can be paid in one transaction
+func (u *TransactionUsecase) InquiryUser(ctx context.Context, userID int64) (*entity.UserInquiry, error) {
+	if err := authorizeOwner(ctx, entity.PermTransactionInquiry, entity.PermTransactionInquiryOwn, userID); err != nil {
+		return nil, err
+	}
+
+	inquiry, err := u.userBills(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(inquiry.Loans) == 0 {
+		return nil, ErrBillingNotFound
+	}
+
+	return inquiry, nil
+}
+
+// userBills gathers the loans of a user with bills due by loan ID, the order their rows are locked in
+func (u *TransactionUsecase) userBills(ctx context.Context, userID int64) (*entity.UserInquiry, error) {
+	loans, err := u.loanUsecase.GetLoansByUserID(ctx, userID, entity.LoanStatusActive)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(loans, func(i, j int) bool { return loans[i].ID < loans[j].ID })
+
+	inquiry := &entity.UserInquiry{UserID: userID, Loans: []*entity.TransactionInquiry{}}
+	for _, loan := range loans {
+		bills, _, err := u.bills(ctx, loan, entity.PayAhead{})
+		if err != nil {
+			return nil, err
+		}
+		if len(bills) == 0 {
+			continue
+		}
+
+		var amountDue float64
+		for _, payment := range bills {
+			amountDue += payment.TotalAmount
+		}
+
+		inquiry.Loans = append(inquiry.Loans, &entity.TransactionInquiry{
+			LoanID:     loan.ID,
+			AmountDue:  amountDue,
+			DueDate:    bills[len(bills)-1].DueDate,
+			LoanDetail: loan,
+			Bills:      bills,
+		})
+		inquiry.AmountDue += amountDue
+	}
+
+	return inquiry, nil
+}
+
+// CreateUserTransaction pays the bills due of all the active loans of a user with a single transaction, allocated
+// to each loan. The amount must match the total due, and either every loan is paid or none is
+func (u *TransactionUsecase) CreateUserTransaction(ctx context.Context, payload *entity.CreateUserTransactionPayload) (*entity.Transaction, error) {
+	if err := validation.Struct(payload); err != nil {
+		return nil, err
+	}
+
+	if err := authorizeOwner(ctx, entity.PermTransactionCreate, entity.PermTransactionCreateOwn, payload.UserID); err != nil {
+		return nil, err
+	}
+
+	inquiry, err := u.userBills(ctx, payload.UserID)
 	if err != nil {
 		return nil, err
 	}
 
+	if len(inquiry.Loans) == 0 {
+		return nil, ErrBillingNotFound
+	}
+
+	if math.Abs(inquiry.AmountDue-payload.Amount) > entity.LedgerTolerance {
+		return nil, ErrAmountMismatch
+	}
+
+	tx, err := u.transactionRepository.BeginTx()
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// lock every loan before paying any, in ID order so two consolidated payments can't deadlock, and read its
+	// bills again under the lock in case a payment committed before it was taken
+	loans := make([]*entity.Loan, len(inquiry.Loans))
+	for i, loanInquiry := range inquiry.Loans {
+		loans[i], err = u.loanUsecase.GetLoanByIDForUpdate(tx, loanInquiry.LoanID)
+		if err != nil {
+			return nil, err
+		}
+
+		var current []*entity.Payment
+		current, _, err = u.bills(ctx, loans[i], entity.PayAhead{})
+		if err != nil {
+			return nil, err
+		}
+		if !sameBills(loanInquiry.Bills, current) {
+			err = ErrBillsChanged
+			return nil, err
+		}
+	}
+
+	timeNow := now()
+	trx := &entity.Transaction{
+		TotalAmount: inquiry.AmountDue,
+		Status:      entity.TransactionStatusPaid,
+		PaidAt:      &timeNow,
+		CreatedAt:   timeNow,
+		Channel:     payload.Channel,
+		ExternalID:  payload.ExternalID,
+	}
+	trx.ID, err = u.transactionRepository.CreateTransaction(tx, trx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, loanInquiry := range inquiry.Loans {
+		var allocation *entity.TransactionAllocation
+		allocation, err = u.settle(ctx, tx, loans[i], trx, loanInquiry.Bills)
+		if err != nil {
+			return nil, err
+		}
+		trx.Allocations = append(trx.Allocations, allocation)
+	}
+
+	if err = u.auditUsecase.Record(ctx, tx, entity.AuditActionTransactionCreate, entity.AuditEntityTransaction, trx.ID, nil, trx); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
 	return trx, nil
 }
+
+func (u *TransactionUsecase) GetTransactionByExternalID(ctx context.Context, channel string, externalID string) (*entity.Transaction, error) {
+	return u.transactionRepository.GetTransactionByExternalID(ctx, channel, externalID)
+}
+
+// ReverseTransaction undoes a paid transaction: its payments are due again, the loan outstanding is restored
+// and the ledger entries are reversed
+func (u *TransactionUsecase) ReverseTransaction(ctx context.Context, id int64) (*entity.Transaction, error) {
+	if err := authorize(ctx, entity.PermTransactionReverse); err != nil {
+		return nil, err
+	}
+
+	trx, err := u.transactionRepository.GetTransactionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if trx.Status != entity.TransactionStatusPaid {
+		return nil, ErrTransactionNotReversible
+	}
+
+	tx, err := u.transactionRepository.BeginTx()
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// claim the transaction first, a concurrent reversal waits on it and then finds it no longer paid
+	err = u.transactionRepository.UpdateTransactionStatus(tx, trx.ID, entity.TransactionStatusPaid, entity.TransactionStatusReversed)
+	if errors.Is(err, repository.ErrTransactionStatusChanged) {
+		err = ErrTransactionNotReversible
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var payments []*entity.Payment
+	payments, err = u.paymentUsecase.GetPaymentsByTransactionID(ctx, trx.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(payments) == 0 {
+		err = ErrTransactionNotReversible
+		return nil, err
+	}
+
+	// a transaction can settle payments of several loans
+	amountByLoan := map[int64]float64{}
+	var loanIDs []int64
+	for _, payment := range payments {
+		if _, ok := amountByLoan[payment.LoanID]; !ok {
+			loanIDs = append(loanIDs, payment.LoanID)
+		}
+		amountByLoan[payment.LoanID] += payment.TotalAmount
+	}
+
+	for _, loanID := range loanIDs {
+		var loan *entity.Loan
+		loan, err = u.loanUsecase.GetLoanByIDForUpdate(tx, loanID)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = u.loanUsecase.UpdateLoanOutstanding(tx, loan.Outstanding+amountByLoan[loanID], loanID); err != nil {
+			return nil, err
+		}
+
+		// the reversal reopens a paid off loan, so its virtual account takes payments again
+		if loan.Status == entity.LoanStatusPaid {
+			if err = u.loanUsecase.UpdateVirtualAccountStatus(tx, loan, entity.VirtualAccountStatusActive); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err = u.paymentUsecase.UnpayPayments(tx, trx.ID); err != nil {
+		return nil, err
+	}
+
+	if err = u.ledgerUsecase.PostReversal(ctx, tx, entity.JournalReferenceTransaction, trx.ID); err != nil {
+		return nil, err
+	}
+
+	reversed := *trx
+	reversed.Status = entity.TransactionStatusReversed
+	if err = u.auditUsecase.Record(ctx, tx, entity.AuditActionTransactionReverse, entity.AuditEntityTransaction, trx.ID, trx, &reversed); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &reversed, nil
+}
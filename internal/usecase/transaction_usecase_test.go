@@ -2,9 +2,10 @@ package usecase
 
 import (
 	"context"
-	"errors"
 	"loan-management/internal/entity"
 	internalMock "loan-management/internal/mock"
+	"loan-management/internal/repository"
+	"loan-management/internal/validation"
 	"testing"
 	"time"
 
@@ -26,36 +27,127 @@ var MockTransaction = &entity.Transaction{
 	CreatedAt:   time.Time{},
 }
 
-func setupTransactionMocks() (*TransactionUsecase, *internalMock.MockTransactionRepository, *internalMock.MockLoanUsecase, *internalMock.MockPaymentUsecase) {
+func setupTransactionMocks() (*TransactionUsecase, *internalMock.MockTransactionRepository, *internalMock.MockLoanUsecase, *internalMock.MockPaymentUsecase, *internalMock.MockAuditUsecase, *internalMock.MockLedgerUsecase, *internalMock.MockEventUsecase) {
 	mockRepo := new(internalMock.MockTransactionRepository)
 	mockLoanUsecase := new(internalMock.MockLoanUsecase)
 	mockPaymentUsecase := new(internalMock.MockPaymentUsecase)
+	mockAuditUsecase := new(internalMock.MockAuditUsecase)
+	mockLedgerUsecase := new(internalMock.MockLedgerUsecase)
+	mockEventUsecase := new(internalMock.MockEventUsecase)
 
-	mockUsecase := NewTransactionUsecase(mockRepo, mockLoanUsecase, mockPaymentUsecase)
+	mockUsecase := NewTransactionUsecase(mockRepo, mockLoanUsecase, mockPaymentUsecase, mockAuditUsecase, mockLedgerUsecase, mockEventUsecase)
 
-	return mockUsecase, mockRepo, mockLoanUsecase, mockPaymentUsecase
+	return mockUsecase, mockRepo, mockLoanUsecase, mockPaymentUsecase, mockAuditUsecase, mockLedgerUsecase, mockEventUsecase
 }
 
 func TestInquiryTransaction(t *testing.T) {
 	t.Run("Success InquiryTransaction", func(t *testing.T) {
-		mockUsecase, mockRepo, mockLoanUsecase, _ := setupTransactionMocks()
+		mockUsecase, mockRepo, mockLoanUsecase, _, _, _, _ := setupTransactionMocks()
 
 		mockPayments := []*entity.Payment{MockPayment}
 		mockTransactionInquiry := entity.TransactionInquiry{
 			LoanID:     int64(0),
 			AmountDue:  float64(1100000),
-			DueDate:    time.Time{},
+			DueDate:    entity.Date{},
 			LoanDetail: MockLoan,
 			Bills:      mockPayments,
 		}
 		mockLoanUsecase.On("GetLoanByID", mock.Anything, mock.Anything, mock.Anything).Return(mockTransactionInquiry.LoanDetail, nil)
 		mockLoanUsecase.On("GetLoanDuePayments", mock.Anything, mock.Anything).Return(mockPayments, nil)
-		inquiryResult, err := mockUsecase.InquiryTransaction(context.Background(), 1)
+		inquiryResult, err := mockUsecase.InquiryTransaction(context.Background(), 1, entity.PayAhead{})
 
 		assert.NoError(t, err)
 		assert.Equal(t, *inquiryResult, mockTransactionInquiry)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("Failed InquiryTransaction - Role Can't Inquiry", func(t *testing.T) {
+		mockUsecase, _, mockLoanUsecase, _, _, _, _ := setupTransactionMocks()
+		ctx := entity.WithIdentity(context.Background(), &entity.Identity{Type: entity.IdentityTypeBorrower, Role: entity.RoleBorrower, UserID: 2})
+
+		mockLoanUsecase.On("GetLoanByID", mock.Anything, mock.Anything, mock.Anything).Return(MockLoan, nil)
+		_, err := mockUsecase.InquiryTransaction(ctx, 1, entity.PayAhead{})
+
+		assert.ErrorIs(t, err, ErrForbidden)
+		mockLoanUsecase.AssertNotCalled(t, "GetLoanDuePayments", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Success InquiryTransaction - Pay Ahead Installments", func(t *testing.T) {
+		mockUsecase, _, mockLoanUsecase, mockPaymentUsecase, _, _, _ := setupTransactionMocks()
+		schedule := payAheadSchedule()
+		status := entity.PaymentStatusActive
+
+		mockLoanUsecase.On("GetLoanByID", mock.Anything, int64(1), mock.Anything).Return(MockLoan, nil)
+		mockLoanUsecase.On("GetLoanDuePayments", mock.Anything, MockLoan).Return(schedule[:1], nil)
+		mockPaymentUsecase.On("GetPaymentsByLoanID", mock.Anything, MockLoan.ID, &status, (*entity.Date)(nil)).Return(schedule, nil)
+
+		inquiry, err := mockUsecase.InquiryTransaction(context.Background(), 1, entity.PayAhead{Installments: 2})
+
+		assert.NoError(t, err)
+		assert.Equal(t, schedule[:3], inquiry.Bills)
+		assert.Equal(t, 2, inquiry.InstallmentsAhead)
+		assert.Equal(t, float64(330), inquiry.AmountDue)
+		assert.Equal(t, schedule[2].DueDate, inquiry.DueDate)
+	})
+
+	t.Run("Success InquiryTransaction - Pay Ahead Amount", func(t *testing.T) {
+		mockUsecase, _, mockLoanUsecase, mockPaymentUsecase, _, _, _ := setupTransactionMocks()
+		schedule := payAheadSchedule()
+
+		mockLoanUsecase.On("GetLoanByID", mock.Anything, int64(1), mock.Anything).Return(MockLoan, nil)
+		mockLoanUsecase.On("GetLoanDuePayments", mock.Anything, MockLoan).Return(schedule[:1], nil)
+		mockPaymentUsecase.On("GetPaymentsByLoanID", mock.Anything, MockLoan.ID, mock.Anything, mock.Anything).Return(schedule, nil)
+
+		// the third installment isn't paid in full, so it stays for later
+		inquiry, err := mockUsecase.InquiryTransaction(context.Background(), 1, entity.PayAhead{Amount: 300})
+
+		assert.NoError(t, err)
+		assert.Equal(t, schedule[:2], inquiry.Bills)
+		assert.Equal(t, 1, inquiry.InstallmentsAhead)
+		assert.Equal(t, float64(220), inquiry.AmountDue)
+	})
+
+	t.Run("Success InquiryTransaction - Pay Ahead Without Bills Due", func(t *testing.T) {
+		mockUsecase, _, mockLoanUsecase, mockPaymentUsecase, _, _, _ := setupTransactionMocks()
+		schedule := payAheadSchedule()
+
+		mockLoanUsecase.On("GetLoanByID", mock.Anything, int64(1), mock.Anything).Return(MockLoan, nil)
+		mockLoanUsecase.On("GetLoanDuePayments", mock.Anything, MockLoan).Return([]*entity.Payment{}, nil)
+		mockPaymentUsecase.On("GetPaymentsByLoanID", mock.Anything, MockLoan.ID, mock.Anything, mock.Anything).Return(schedule, nil)
+
+		inquiry, err := mockUsecase.InquiryTransaction(context.Background(), 1, entity.PayAhead{Installments: 10})
+
+		assert.NoError(t, err)
+		assert.Equal(t, schedule, inquiry.Bills)
+		assert.Equal(t, 4, inquiry.InstallmentsAhead)
+	})
+
+	t.Run("Failed InquiryTransaction - Installments And Amount", func(t *testing.T) {
+		mockUsecase, _, mockLoanUsecase, _, _, _, _ := setupTransactionMocks()
+
+		_, err := mockUsecase.InquiryTransaction(context.Background(), 1, entity.PayAhead{Installments: 1, Amount: 300})
+
+		assert.ErrorIs(t, err, validation.ErrValidationFailed)
+		mockLoanUsecase.AssertNotCalled(t, "GetLoanByID", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// payAheadSchedule is a weekly schedule of 110 installments, the first one due
+func payAheadSchedule() []*entity.Payment {
+	schedule := make([]*entity.Payment, 4)
+	for i := range schedule {
+		schedule[i] = &entity.Payment{
+			ID:          int64(i + 1),
+			LoanID:      1,
+			PaymentNo:   int32(i + 1),
+			DueDate:     entity.NewDate(2025, 1, 8).AddDays(7 * i),
+			Amount:      100,
+			Interest:    10,
+			TotalAmount: 110,
+			Status:      entity.PaymentStatusActive,
+		}
+	}
+	return schedule
 }
 
 func TestCreateTransaction(t *testing.T) {
@@ -80,18 +172,31 @@ func TestCreateTransaction(t *testing.T) {
 
 		defer func() { now = time.Now }()
 
-		mockUsecase, mockRepo, mockLoanUsecase, mockPaymentUsecase := setupTransactionMocks()
+		mockUsecase, mockRepo, mockLoanUsecase, mockPaymentUsecase, mockAuditUsecase, mockLedgerUsecase, mockEventUsecase := setupTransactionMocks()
 
 		mockPayments := []*entity.Payment{MockPayment}
 		mockLoanUsecase.On("GetLoanByID", mock.Anything, mock.Anything, mock.Anything).Return(MockLoan, nil)
 		mockLoanUsecase.On("GetLoanDuePayments", mock.Anything, mock.Anything).Return(mockPayments, nil)
+		mockLoanUsecase.On("GetLoanByIDForUpdate", mock.Anything, mock.Anything).Return(MockLoan, nil)
 		mockLoanUsecase.On("UpdateLoanOutstanding", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
 		mockRepo.On("BeginTx").Return(mockTx, nil)
 		mockRepo.On("CreateTransaction", mock.Anything, mock.Anything).Return(int64(1), nil)
+		mockRepo.On("CreateAllocation", mockTx, mock.MatchedBy(func(allocation *entity.TransactionAllocation) bool {
+			return allocation.TransactionID == 1 && allocation.LoanID == MockLoan.ID && allocation.Amount == MockPayment.TotalAmount
+		})).Return(nil)
 
 		mockPaymentUsecase.On("PayPayment", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
+		mockLedgerUsecase.On("PostRepayment", mock.Anything, mockTx, MockLoan.ID, mock.Anything, mockPayments).Return(nil)
+		mockAuditUsecase.On("Record", mock.Anything, mockTx, entity.AuditActionTransactionCreate, entity.AuditEntityTransaction, int64(1), nil, mock.Anything).Return(nil)
+		mockAuditUsecase.On("Record", mock.Anything, mockTx, entity.AuditActionLoanPay, entity.AuditEntityLoan, MockLoan.ID, MockLoan, mock.MatchedBy(func(loan *entity.Loan) bool {
+			return loan.Outstanding == MockLoan.Outstanding-MockPayment.TotalAmount
+		})).Return(nil)
+		mockEventUsecase.On("Publish", mock.Anything, mockTx, entity.EventPaymentPosted, entity.EventAggregateLoan, MockLoan.ID, mock.MatchedBy(func(payload entity.PaymentPostedPayload) bool {
+			return payload.TransactionID == 1 && payload.Amount == MockPayment.TotalAmount
+		})).Return(nil)
+
 		trx, err := mockUsecase.CreateTransaction(context.Background(), &createTrxPayload)
 		mockPaidTransaction := MockTransaction
 		mockPaidTransaction.Status = entity.TransactionStatusPaid
@@ -102,35 +207,227 @@ func TestCreateTransaction(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, trx, MockTransaction)
 		mockRepo.AssertExpectations(t)
+		mockAuditUsecase.AssertExpectations(t)
+		mockLedgerUsecase.AssertExpectations(t)
+		mockEventUsecase.AssertExpectations(t)
+		mockLoanUsecase.AssertNotCalled(t, "UpdateLoanDelinquency", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Success CreateTransaction - Last Payment Pays Off Delinquent Loan", func(t *testing.T) {
+		mockTx := newMockTx(t, true)
+		mockUsecase, mockRepo, mockLoanUsecase, mockPaymentUsecase, mockAuditUsecase, mockLedgerUsecase, mockEventUsecase := setupTransactionMocks()
+
+		delinquentSince := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		// the installments add up to a hair more than the outstanding
+		delinquentLoan := &entity.Loan{ID: 1, UserID: 1, Outstanding: MockPayment.TotalAmount - 1e-9, Status: entity.LoanStatusActive, DelinquentSince: &delinquentSince, VirtualAccount: "880800000000016", VirtualAccountStatus: entity.VirtualAccountStatusActive}
+		mockPayments := []*entity.Payment{MockPayment}
+
+		mockLoanUsecase.On("GetLoanByID", mock.Anything, mock.Anything, mock.Anything).Return(delinquentLoan, nil)
+		mockLoanUsecase.On("GetLoanDuePayments", mock.Anything, mock.Anything).Return(mockPayments, nil)
+		mockLoanUsecase.On("GetLoanByIDForUpdate", mockTx, int64(1)).Return(delinquentLoan, nil)
+		mockLoanUsecase.On("UpdateLoanOutstanding", mockTx, float64(0), int64(1)).Return(nil)
+		mockLoanUsecase.On("UpdateLoanDelinquency", mockTx, int64(1), (*time.Time)(nil)).Return(nil)
+		mockLoanUsecase.On("UpdateVirtualAccountStatus", mockTx, delinquentLoan, entity.VirtualAccountStatusInactive).Return(nil)
+		mockRepo.On("BeginTx").Return(mockTx, nil)
+		mockRepo.On("CreateTransaction", mockTx, mock.Anything).Return(int64(1), nil)
+		mockRepo.On("CreateAllocation", mockTx, mock.Anything).Return(nil)
+		mockPaymentUsecase.On("PayPayment", mockTx, mock.Anything, int64(1), mock.Anything).Return(nil)
+		mockLedgerUsecase.On("PostRepayment", mock.Anything, mockTx, int64(1), mock.Anything, mockPayments).Return(nil)
+		mockAuditUsecase.On("Record", mock.Anything, mockTx, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		mockEventUsecase.On("Publish", mock.Anything, mockTx, entity.EventPaymentPosted, entity.EventAggregateLoan, int64(1), mock.Anything).Return(nil)
+		mockEventUsecase.On("Publish", mock.Anything, mockTx, entity.EventLoanPaidOff, entity.EventAggregateLoan, int64(1), mock.MatchedBy(func(payload entity.LoanPaidOffPayload) bool {
+			return payload.TransactionID == 1 && payload.UserID == 1
+		})).Return(nil)
+
+		_, err := mockUsecase.CreateTransaction(context.Background(), &entity.CreateTransactionPayload{LoanID: 1, Amount: MockPayment.TotalAmount})
+
+		assert.NoError(t, err)
+		mockLoanUsecase.AssertExpectations(t)
+		mockEventUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Success CreateTransaction - By Virtual Account", func(t *testing.T) {
+		mockTx := newMockTx(t, true)
+		mockUsecase, mockRepo, mockLoanUsecase, mockPaymentUsecase, mockAuditUsecase, mockLedgerUsecase, mockEventUsecase := setupTransactionMocks()
+
+		vaLoan := &entity.Loan{ID: 1, UserID: 1, Outstanding: 2 * MockPayment.TotalAmount, Status: entity.LoanStatusActive, VirtualAccount: "880800000000016", VirtualAccountStatus: entity.VirtualAccountStatusActive}
+		mockPayments := []*entity.Payment{MockPayment}
+
+		mockLoanUsecase.On("GetLoanByVirtualAccount", mock.Anything, "880800000000016").Return(vaLoan, nil)
+		mockLoanUsecase.On("GetLoanByID", mock.Anything, int64(1), mock.Anything).Return(vaLoan, nil)
+		mockLoanUsecase.On("GetLoanDuePayments", mock.Anything, mock.Anything).Return(mockPayments, nil)
+		mockLoanUsecase.On("GetLoanByIDForUpdate", mockTx, int64(1)).Return(vaLoan, nil)
+		mockLoanUsecase.On("UpdateLoanOutstanding", mockTx, MockPayment.TotalAmount, int64(1)).Return(nil)
+		mockRepo.On("BeginTx").Return(mockTx, nil)
+		mockRepo.On("CreateTransaction", mockTx, mock.Anything).Return(int64(1), nil)
+		mockRepo.On("CreateAllocation", mockTx, mock.Anything).Return(nil)
+		mockPaymentUsecase.On("PayPayment", mockTx, mock.Anything, int64(1), mock.Anything).Return(nil)
+		mockLedgerUsecase.On("PostRepayment", mock.Anything, mockTx, int64(1), mock.Anything, mockPayments).Return(nil)
+		mockAuditUsecase.On("Record", mock.Anything, mockTx, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		mockEventUsecase.On("Publish", mock.Anything, mockTx, entity.EventPaymentPosted, entity.EventAggregateLoan, int64(1), mock.Anything).Return(nil)
+
+		_, err := mockUsecase.CreateTransaction(context.Background(), &entity.CreateTransactionPayload{VirtualAccount: "880800000000016", Amount: MockPayment.TotalAmount})
+
+		assert.NoError(t, err)
+		mockLoanUsecase.AssertExpectations(t)
+		mockLoanUsecase.AssertNotCalled(t, "UpdateVirtualAccountStatus", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Success CreateTransaction - Pays Ahead By Amount", func(t *testing.T) {
+		mockTx := newMockTx(t, true)
+		mockUsecase, mockRepo, mockLoanUsecase, mockPaymentUsecase, mockAuditUsecase, mockLedgerUsecase, mockEventUsecase := setupTransactionMocks()
+		schedule := payAheadSchedule()
+		loan := &entity.Loan{ID: 1, UserID: 1, Outstanding: 440, Status: entity.LoanStatusActive}
+
+		mockLoanUsecase.On("GetLoanByID", mock.Anything, int64(1), mock.Anything).Return(loan, nil)
+		mockLoanUsecase.On("GetLoanDuePayments", mock.Anything, loan).Return(schedule[:1], nil)
+		mockPaymentUsecase.On("GetPaymentsByLoanID", mock.Anything, int64(1), mock.Anything, mock.Anything).Return(schedule, nil)
+		mockLoanUsecase.On("GetLoanByIDForUpdate", mockTx, int64(1)).Return(loan, nil)
+		mockLoanUsecase.On("UpdateLoanOutstanding", mockTx, float64(220), int64(1)).Return(nil)
+		mockRepo.On("BeginTx").Return(mockTx, nil)
+		mockRepo.On("CreateTransaction", mockTx, mock.Anything).Return(int64(1), nil)
+		mockRepo.On("CreateAllocation", mockTx, mock.Anything).Return(nil)
+		mockPaymentUsecase.On("PayPayment", mockTx, int64(1), int64(1), mock.Anything).Return(nil)
+		mockPaymentUsecase.On("PayPayment", mockTx, int64(2), int64(1), mock.Anything).Return(nil)
+		mockLedgerUsecase.On("PostRepayment", mock.Anything, mockTx, int64(1), mock.Anything, schedule[:2]).Return(nil)
+		mockAuditUsecase.On("Record", mock.Anything, mockTx, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		mockEventUsecase.On("Publish", mock.Anything, mockTx, entity.EventPaymentPosted, entity.EventAggregateLoan, int64(1), mock.MatchedBy(func(payload entity.PaymentPostedPayload) bool {
+			return payload.Amount == 220 && len(payload.PaymentIDs) == 2
+		})).Return(nil)
+
+		trx, err := mockUsecase.CreateTransaction(context.Background(), &entity.CreateTransactionPayload{LoanID: 1, Amount: 220})
+
+		assert.NoError(t, err)
+		assert.Equal(t, float64(220), trx.TotalAmount)
+		mockPaymentUsecase.AssertNumberOfCalls(t, "PayPayment", 2)
+		mockLedgerUsecase.AssertExpectations(t)
+		mockEventUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Success CreateTransaction - Received Rounded Amount", func(t *testing.T) {
+		mockTx := newMockTx(t, true)
+		mockUsecase, mockRepo, mockLoanUsecase, mockPaymentUsecase, mockAuditUsecase, mockLedgerUsecase, mockEventUsecase := setupTransactionMocks()
+		loan := &entity.Loan{ID: 1, UserID: 1, Outstanding: 439, Status: entity.LoanStatusActive}
+		bills := []*entity.Payment{{ID: 1, LoanID: 1, PaymentNo: 1, Amount: 100, Interest: 9.75, TotalAmount: 109.75, Status: entity.PaymentStatusActive}}
+
+		mockLoanUsecase.On("GetLoanByID", mock.Anything, int64(1), mock.Anything).Return(loan, nil)
+		mockLoanUsecase.On("GetLoanDuePayments", mock.Anything, loan).Return(bills, nil)
+		mockPaymentUsecase.On("GetPaymentsByLoanID", mock.Anything, int64(1), mock.Anything, mock.Anything).Return(bills, nil)
+		mockLoanUsecase.On("GetLoanByIDForUpdate", mockTx, int64(1)).Return(loan, nil)
+		mockLoanUsecase.On("UpdateLoanOutstanding", mockTx, 439-109.75, int64(1)).Return(nil)
+		mockRepo.On("BeginTx").Return(mockTx, nil)
+		mockRepo.On("CreateTransaction", mockTx, mock.MatchedBy(func(trx *entity.Transaction) bool {
+			return trx.TotalAmount == 109.75 && trx.Rounding == 0.25
+		})).Return(int64(1), nil)
+		mockRepo.On("CreateAllocation", mockTx, mock.Anything).Return(nil)
+		mockPaymentUsecase.On("PayPayment", mockTx, int64(1), int64(1), mock.Anything).Return(nil)
+		mockLedgerUsecase.On("PostRepayment", mock.Anything, mockTx, int64(1), mock.Anything, bills).Return(nil)
+		mockAuditUsecase.On("Record", mock.Anything, mockTx, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		mockEventUsecase.On("Publish", mock.Anything, mockTx, entity.EventPaymentPosted, entity.EventAggregateLoan, int64(1), mock.Anything).Return(nil)
+
+		trx, err := mockUsecase.CreateTransaction(context.Background(), &entity.CreateTransactionPayload{LoanID: 1, Amount: 109.75, Received: 110})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0.25, trx.Rounding)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Failed CreateTransaction - Received More Than Rounding", func(t *testing.T) {
+		mockUsecase, mockRepo, mockLoanUsecase, mockPaymentUsecase, _, _, _ := setupTransactionMocks()
+		bills := []*entity.Payment{{ID: 1, LoanID: 1, PaymentNo: 1, Amount: 100, Interest: 9.75, TotalAmount: 109.75, Status: entity.PaymentStatusActive}}
+
+		mockLoanUsecase.On("GetLoanByID", mock.Anything, int64(1), mock.Anything).Return(MockLoan, nil)
+		mockLoanUsecase.On("GetLoanDuePayments", mock.Anything, MockLoan).Return(bills, nil)
+		mockPaymentUsecase.On("GetPaymentsByLoanID", mock.Anything, MockLoan.ID, mock.Anything, mock.Anything).Return(bills, nil)
+
+		_, err := mockUsecase.CreateTransaction(context.Background(), &entity.CreateTransactionPayload{LoanID: 1, Amount: 109.75, Received: 111})
+
+		assert.ErrorIs(t, err, ErrAmountMismatch)
+		mockRepo.AssertNotCalled(t, "BeginTx")
+	})
+
+	t.Run("Failed CreateTransaction - Pay Ahead Amount Splits An Installment", func(t *testing.T) {
+		mockUsecase, mockRepo, mockLoanUsecase, mockPaymentUsecase, _, _, _ := setupTransactionMocks()
+		schedule := payAheadSchedule()
+
+		mockLoanUsecase.On("GetLoanByID", mock.Anything, int64(1), mock.Anything).Return(MockLoan, nil)
+		mockLoanUsecase.On("GetLoanDuePayments", mock.Anything, MockLoan).Return(schedule[:1], nil)
+		mockPaymentUsecase.On("GetPaymentsByLoanID", mock.Anything, MockLoan.ID, mock.Anything, mock.Anything).Return(schedule, nil)
+
+		_, err := mockUsecase.CreateTransaction(context.Background(), &entity.CreateTransactionPayload{LoanID: 1, Amount: 300})
+
+		assert.ErrorIs(t, err, ErrAmountMismatch)
+		mockRepo.AssertNotCalled(t, "BeginTx")
+	})
+
+	t.Run("Failed CreateTransaction - Paid Before The Lock", func(t *testing.T) {
+		mockTx := newMockTx(t, false)
+		mockUsecase, mockRepo, mockLoanUsecase, mockPaymentUsecase, _, _, _ := setupTransactionMocks()
+		loan := &entity.Loan{ID: 1, UserID: 1, Outstanding: 440, Status: entity.LoanStatusActive}
+
+		mockLoanUsecase.On("GetLoanByID", mock.Anything, int64(1), mock.Anything).Return(loan, nil)
+		// a concurrent payment settles the bill between the check and the lock
+		mockLoanUsecase.On("GetLoanDuePayments", mock.Anything, loan).Return(payAheadSchedule()[:1], nil).Once()
+		mockLoanUsecase.On("GetLoanDuePayments", mock.Anything, loan).Return([]*entity.Payment{}, nil).Once()
+		mockRepo.On("BeginTx").Return(mockTx, nil)
+		mockLoanUsecase.On("GetLoanByIDForUpdate", mockTx, int64(1)).Return(loan, nil)
+		mockPaymentUsecase.On("GetPaymentsByLoanID", mock.Anything, int64(1), mock.Anything, (*entity.Date)(nil)).Return(payAheadSchedule()[1:], nil)
+
+		_, err := mockUsecase.CreateTransaction(context.Background(), &entity.CreateTransactionPayload{LoanID: 1, Amount: 110})
+
+		assert.ErrorIs(t, err, ErrBillsChanged)
+		mockRepo.AssertNotCalled(t, "CreateTransaction", mock.Anything, mock.Anything)
+		mockPaymentUsecase.AssertNotCalled(t, "PayPayment", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failed CreateTransaction - Inactive Virtual Account", func(t *testing.T) {
+		mockUsecase, mockRepo, mockLoanUsecase, _, _, _, _ := setupTransactionMocks()
+		mockLoanUsecase.On("GetLoanByVirtualAccount", mock.Anything, "880800000000016").Return(nil, ErrVirtualAccountInactive)
+
+		_, err := mockUsecase.CreateTransaction(context.Background(), &entity.CreateTransactionPayload{VirtualAccount: "880800000000016", Amount: MockPayment.TotalAmount})
+
+		assert.ErrorIs(t, err, ErrVirtualAccountInactive)
+		mockRepo.AssertNotCalled(t, "BeginTx")
+	})
+
+	t.Run("Failed CreateTransaction - Both Loan ID And Virtual Account", func(t *testing.T) {
+		mockUsecase, _, mockLoanUsecase, _, _, _, _ := setupTransactionMocks()
+
+		_, err := mockUsecase.CreateTransaction(context.Background(), &entity.CreateTransactionPayload{LoanID: 1, VirtualAccount: "880800000000016", Amount: MockPayment.TotalAmount})
+
+		assert.ErrorIs(t, err, ErrLoanReferenceRequired)
+		mockLoanUsecase.AssertNotCalled(t, "GetLoanByVirtualAccount", mock.Anything, mock.Anything)
 	})
 
 	t.Run("Failed CreateTransaction - Loan Not Found", func(t *testing.T) {
-		mockUsecase, mockRepo, mockLoanUsecase, _ := setupTransactionMocks()
+		mockUsecase, mockRepo, mockLoanUsecase, _, _, _, _ := setupTransactionMocks()
 		mockLoanUsecase.On("GetLoanByID", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
 
 		trx, err := mockUsecase.CreateTransaction(context.Background(), &createTrxPayload)
 
-		assert.NoError(t, err)
+		assert.ErrorIs(t, err, ErrLoanNotFound)
 		assert.Equal(t, trx, (*entity.Transaction)(nil))
 		mockRepo.AssertExpectations(t)
 	})
 
 	t.Run("Failed CreateTransaction - No Due Payment", func(t *testing.T) {
-		mockUsecase, mockRepo, mockLoanUsecase, _ := setupTransactionMocks()
-		mockPayments := []*entity.Payment{MockPayment}
+		mockUsecase, mockRepo, mockLoanUsecase, mockPaymentUsecase, _, _, _ := setupTransactionMocks()
+		mockPayments := []*entity.Payment{}
 		mockLoanUsecase.On("GetLoanByID", mock.Anything, mock.Anything, mock.Anything).Return(MockLoan, nil)
 		mockLoanUsecase.On("GetLoanDuePayments", mock.Anything, mock.Anything).Return(mockPayments, nil)
+		// nothing left to pay ahead either
+		mockPaymentUsecase.On("GetPaymentsByLoanID", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(mockPayments, nil)
 		mockLoanUsecase.On("UpdateLoanOutstanding", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
 		trx, err := mockUsecase.CreateTransaction(context.Background(), &createTrxPayload)
 
-		assert.NoError(t, err)
+		assert.ErrorIs(t, err, ErrBillingNotFound)
 		assert.Equal(t, trx, (*entity.Transaction)(nil))
 		mockRepo.AssertExpectations(t)
 	})
 
 	t.Run("Failed CreateTransaction - Total Amount Not Match", func(t *testing.T) {
-		mockUsecase, mockRepo, mockLoanUsecase, _ := setupTransactionMocks()
+		mockUsecase, mockRepo, mockLoanUsecase, _, _, _, _ := setupTransactionMocks()
 
 		mockPayments := []*entity.Payment{MockPayment}
 		mockLoanUsecase.On("GetLoanByID", mock.Anything, mock.Anything, mock.Anything).Return(MockLoan, nil)
@@ -142,8 +439,283 @@ func TestCreateTransaction(t *testing.T) {
 		trx, err := mockUsecase.CreateTransaction(context.Background(), &customCreateTrxPayload)
 
 		assert.Error(t, err)
-		assert.Equal(t, err, errors.New("The amount is different with the due amount"))
+		assert.ErrorIs(t, err, ErrAmountMismatch)
 		assert.Equal(t, trx, (*entity.Transaction)(nil))
 		mockRepo.AssertExpectations(t)
 	})
 }
+
+// userLoans are three active loans of user 1 out of ID order: loan 1 has an installment due, loan 2 its last
+// installment and loan 3 nothing due
+func userLoans() ([]*entity.Loan, map[int64][]*entity.Payment) {
+	loans := []*entity.Loan{
+		{ID: 2, UserID: 1, Outstanding: 110, Status: entity.LoanStatusActive, VirtualAccount: "880800000000024", VirtualAccountStatus: entity.VirtualAccountStatusActive},
+		{ID: 1, UserID: 1, Outstanding: 440, Status: entity.LoanStatusActive},
+		{ID: 3, UserID: 1, Outstanding: 220, Status: entity.LoanStatusActive},
+	}
+	bills := map[int64][]*entity.Payment{
+		1: payAheadSchedule()[:1],
+		2: {{ID: 21, LoanID: 2, PaymentNo: 4, DueDate: entity.NewDate(2025, 1, 8), Amount: 100, Interest: 10, TotalAmount: 110, Status: entity.PaymentStatusActive}},
+		3: {},
+	}
+	return loans, bills
+}
+
+func TestInquiryUser(t *testing.T) {
+	t.Run("Success InquiryUser", func(t *testing.T) {
+		mockUsecase, _, mockLoanUsecase, _, _, _, _ := setupTransactionMocks()
+		loans, bills := userLoans()
+
+		mockLoanUsecase.On("GetLoansByUserID", mock.Anything, int64(1), entity.LoanStatusActive).Return(loans, nil)
+		for _, loan := range loans {
+			mockLoanUsecase.On("GetLoanDuePayments", mock.Anything, loan).Return(bills[loan.ID], nil)
+		}
+
+		inquiry, err := mockUsecase.InquiryUser(context.Background(), 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), inquiry.UserID)
+		assert.Equal(t, float64(220), inquiry.AmountDue)
+		if assert.Len(t, inquiry.Loans, 2) {
+			assert.Equal(t, int64(1), inquiry.Loans[0].LoanID)
+			assert.Equal(t, int64(2), inquiry.Loans[1].LoanID)
+			assert.Equal(t, float64(110), inquiry.Loans[1].AmountDue)
+		}
+	})
+
+	t.Run("Failed InquiryUser - No Bills Due", func(t *testing.T) {
+		mockUsecase, _, mockLoanUsecase, _, _, _, _ := setupTransactionMocks()
+
+		mockLoanUsecase.On("GetLoansByUserID", mock.Anything, int64(1), entity.LoanStatusActive).Return(nil, nil)
+
+		_, err := mockUsecase.InquiryUser(context.Background(), 1)
+
+		assert.ErrorIs(t, err, ErrBillingNotFound)
+	})
+
+	t.Run("Failed InquiryUser - Another Borrower", func(t *testing.T) {
+		mockUsecase, _, mockLoanUsecase, _, _, _, _ := setupTransactionMocks()
+		borrower := entity.WithIdentity(context.Background(), &entity.Identity{Type: entity.IdentityTypeBorrower, Role: entity.RoleBorrower, UserID: 2})
+
+		_, err := mockUsecase.InquiryUser(borrower, 1)
+
+		assert.ErrorIs(t, err, ErrForbidden)
+		mockLoanUsecase.AssertNotCalled(t, "GetLoansByUserID", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestCreateUserTransaction(t *testing.T) {
+	mockTime := time.Date(2025, 1, 8, 10, 0, 0, 0, time.UTC)
+	now = func() time.Time { return mockTime }
+	defer func() { now = time.Now }()
+
+	t.Run("Success CreateUserTransaction", func(t *testing.T) {
+		mockTx := newMockTx(t, true)
+		mockUsecase, mockRepo, mockLoanUsecase, mockPaymentUsecase, mockAuditUsecase, mockLedgerUsecase, mockEventUsecase := setupTransactionMocks()
+		loans, bills := userLoans()
+
+		mockLoanUsecase.On("GetLoansByUserID", mock.Anything, int64(1), entity.LoanStatusActive).Return(loans, nil)
+		for _, loan := range loans {
+			mockLoanUsecase.On("GetLoanDuePayments", mock.Anything, loan).Return(bills[loan.ID], nil)
+		}
+		mockRepo.On("BeginTx").Return(mockTx, nil)
+		mockLoanUsecase.On("GetLoanByIDForUpdate", mockTx, int64(1)).Return(loans[1], nil)
+		mockLoanUsecase.On("GetLoanByIDForUpdate", mockTx, int64(2)).Return(loans[0], nil)
+		mockRepo.On("CreateTransaction", mockTx, mock.MatchedBy(func(trx *entity.Transaction) bool {
+			return trx.TotalAmount == 220 && trx.Status == entity.TransactionStatusPaid
+		})).Return(int64(7), nil)
+		mockPaymentUsecase.On("PayPayment", mockTx, int64(1), int64(7), mockTime).Return(nil)
+		mockPaymentUsecase.On("PayPayment", mockTx, int64(21), int64(7), mockTime).Return(nil)
+		mockLoanUsecase.On("UpdateLoanOutstanding", mockTx, float64(330), int64(1)).Return(nil)
+		mockLoanUsecase.On("UpdateLoanOutstanding", mockTx, float64(0), int64(2)).Return(nil)
+		mockLoanUsecase.On("UpdateVirtualAccountStatus", mockTx, loans[0], entity.VirtualAccountStatusInactive).Return(nil)
+		mockLedgerUsecase.On("PostRepayment", mock.Anything, mockTx, int64(1), mock.Anything, bills[1]).Return(nil)
+		mockLedgerUsecase.On("PostRepayment", mock.Anything, mockTx, int64(2), mock.Anything, bills[2]).Return(nil)
+		mockRepo.On("CreateAllocation", mockTx, mock.Anything).Return(nil)
+		mockAuditUsecase.On("Record", mock.Anything, mockTx, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		mockEventUsecase.On("Publish", mock.Anything, mockTx, entity.EventPaymentPosted, entity.EventAggregateLoan, int64(1), mock.Anything).Return(nil)
+		mockEventUsecase.On("Publish", mock.Anything, mockTx, entity.EventPaymentPosted, entity.EventAggregateLoan, int64(2), mock.Anything).Return(nil)
+		mockEventUsecase.On("Publish", mock.Anything, mockTx, entity.EventLoanPaidOff, entity.EventAggregateLoan, int64(2), mock.Anything).Return(nil)
+
+		trx, err := mockUsecase.CreateUserTransaction(context.Background(), &entity.CreateUserTransactionPayload{UserID: 1, Amount: 220})
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(7), trx.ID)
+		assert.Equal(t, []*entity.TransactionAllocation{
+			{TransactionID: 7, LoanID: 1, Amount: 110, CreatedAt: mockTime},
+			{TransactionID: 7, LoanID: 2, Amount: 110, CreatedAt: mockTime},
+		}, trx.Allocations)
+		mockLoanUsecase.AssertExpectations(t)
+		mockLedgerUsecase.AssertExpectations(t)
+		mockEventUsecase.AssertExpectations(t)
+		mockEventUsecase.AssertNotCalled(t, "Publish", mock.Anything, mockTx, entity.EventLoanPaidOff, entity.EventAggregateLoan, int64(1), mock.Anything)
+	})
+
+	t.Run("Failed CreateUserTransaction - Amount Not Match", func(t *testing.T) {
+		mockUsecase, mockRepo, mockLoanUsecase, _, _, _, _ := setupTransactionMocks()
+		loans, bills := userLoans()
+
+		mockLoanUsecase.On("GetLoansByUserID", mock.Anything, int64(1), entity.LoanStatusActive).Return(loans, nil)
+		for _, loan := range loans {
+			mockLoanUsecase.On("GetLoanDuePayments", mock.Anything, loan).Return(bills[loan.ID], nil)
+		}
+
+		_, err := mockUsecase.CreateUserTransaction(context.Background(), &entity.CreateUserTransactionPayload{UserID: 1, Amount: 110})
+
+		assert.ErrorIs(t, err, ErrAmountMismatch)
+		mockRepo.AssertNotCalled(t, "BeginTx")
+	})
+
+	t.Run("Failed CreateUserTransaction - A Loan Fails", func(t *testing.T) {
+		mockTx := newMockTx(t, false)
+		mockUsecase, mockRepo, mockLoanUsecase, mockPaymentUsecase, mockAuditUsecase, mockLedgerUsecase, mockEventUsecase := setupTransactionMocks()
+		loans, bills := userLoans()
+
+		mockLoanUsecase.On("GetLoansByUserID", mock.Anything, int64(1), entity.LoanStatusActive).Return(loans, nil)
+		for _, loan := range loans {
+			mockLoanUsecase.On("GetLoanDuePayments", mock.Anything, loan).Return(bills[loan.ID], nil)
+		}
+		mockRepo.On("BeginTx").Return(mockTx, nil)
+		mockLoanUsecase.On("GetLoanByIDForUpdate", mockTx, int64(1)).Return(loans[1], nil)
+		mockLoanUsecase.On("GetLoanByIDForUpdate", mockTx, int64(2)).Return(loans[0], nil)
+		mockRepo.On("CreateTransaction", mockTx, mock.Anything).Return(int64(7), nil)
+		mockPaymentUsecase.On("PayPayment", mockTx, int64(1), int64(7), mockTime).Return(nil)
+		mockPaymentUsecase.On("PayPayment", mockTx, int64(21), int64(7), mockTime).Return(repository.ErrPaymentNotFound)
+		mockLoanUsecase.On("UpdateLoanOutstanding", mockTx, float64(330), int64(1)).Return(nil)
+		mockLedgerUsecase.On("PostRepayment", mock.Anything, mockTx, int64(1), mock.Anything, bills[1]).Return(nil)
+		mockRepo.On("CreateAllocation", mockTx, mock.Anything).Return(nil)
+		mockAuditUsecase.On("Record", mock.Anything, mockTx, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		mockEventUsecase.On("Publish", mock.Anything, mockTx, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		_, err := mockUsecase.CreateUserTransaction(context.Background(), &entity.CreateUserTransactionPayload{UserID: 1, Amount: 220})
+
+		assert.ErrorIs(t, err, repository.ErrPaymentNotFound)
+		mockAuditUsecase.AssertNotCalled(t, "Record", mock.Anything, mockTx, entity.AuditActionTransactionCreate, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failed CreateUserTransaction - A Loan Paid Before The Lock", func(t *testing.T) {
+		mockTx := newMockTx(t, false)
+		mockUsecase, mockRepo, mockLoanUsecase, mockPaymentUsecase, _, _, _ := setupTransactionMocks()
+		loans, bills := userLoans()
+
+		mockLoanUsecase.On("GetLoansByUserID", mock.Anything, int64(1), entity.LoanStatusActive).Return(loans, nil)
+		mockLoanUsecase.On("GetLoanDuePayments", mock.Anything, loans[1]).Return(bills[1], nil)
+		mockLoanUsecase.On("GetLoanDuePayments", mock.Anything, loans[2]).Return(bills[3], nil)
+		// loan 2 is paid on its own between the inquiry and the lock
+		mockLoanUsecase.On("GetLoanDuePayments", mock.Anything, loans[0]).Return(bills[2], nil).Once()
+		mockLoanUsecase.On("GetLoanDuePayments", mock.Anything, loans[0]).Return([]*entity.Payment{}, nil).Once()
+		mockRepo.On("BeginTx").Return(mockTx, nil)
+		mockLoanUsecase.On("GetLoanByIDForUpdate", mockTx, int64(1)).Return(loans[1], nil)
+		mockLoanUsecase.On("GetLoanByIDForUpdate", mockTx, int64(2)).Return(loans[0], nil)
+
+		_, err := mockUsecase.CreateUserTransaction(context.Background(), &entity.CreateUserTransactionPayload{UserID: 1, Amount: 220})
+
+		assert.ErrorIs(t, err, ErrBillsChanged)
+		mockRepo.AssertNotCalled(t, "CreateTransaction", mock.Anything, mock.Anything)
+		mockPaymentUsecase.AssertNotCalled(t, "PayPayment", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failed CreateUserTransaction - Another Borrower", func(t *testing.T) {
+		mockUsecase, _, mockLoanUsecase, _, _, _, _ := setupTransactionMocks()
+		borrower := entity.WithIdentity(context.Background(), &entity.Identity{Type: entity.IdentityTypeBorrower, Role: entity.RoleBorrower, UserID: 2})
+
+		_, err := mockUsecase.CreateUserTransaction(borrower, &entity.CreateUserTransactionPayload{UserID: 1, Amount: 220})
+
+		assert.ErrorIs(t, err, ErrForbidden)
+		mockLoanUsecase.AssertNotCalled(t, "GetLoansByUserID", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestReverseTransaction(t *testing.T) {
+	financeCtx := entity.WithIdentity(context.Background(), &entity.Identity{Type: entity.IdentityTypeBorrower, Role: entity.RoleFinance, UserID: 1})
+
+	t.Run("Success ReverseTransaction", func(t *testing.T) {
+		mockTx := newMockTx(t, true)
+		mockUsecase, mockRepo, mockLoanUsecase, mockPaymentUsecase, mockAuditUsecase, mockLedgerUsecase, _ := setupTransactionMocks()
+
+		paidTransaction := &entity.Transaction{ID: 1, TotalAmount: MockPayment.TotalAmount, Status: entity.TransactionStatusPaid}
+		paidPayment := *MockPayment
+		paidPayment.LoanID = 1
+		paidLoan := &entity.Loan{ID: 1, Outstanding: 100}
+
+		mockRepo.On("GetTransactionByID", mock.Anything, int64(1)).Return(paidTransaction, nil)
+		mockRepo.On("BeginTx").Return(mockTx, nil)
+		mockRepo.On("UpdateTransactionStatus", mockTx, int64(1), entity.TransactionStatusPaid, entity.TransactionStatusReversed).Return(nil)
+		mockPaymentUsecase.On("GetPaymentsByTransactionID", mock.Anything, int64(1)).Return([]*entity.Payment{&paidPayment}, nil)
+		mockPaymentUsecase.On("UnpayPayments", mockTx, int64(1)).Return(nil)
+		mockLoanUsecase.On("GetLoanByIDForUpdate", mockTx, int64(1)).Return(paidLoan, nil)
+		mockLoanUsecase.On("UpdateLoanOutstanding", mockTx, 100+paidPayment.TotalAmount, int64(1)).Return(nil)
+		mockLedgerUsecase.On("PostReversal", mock.Anything, mockTx, entity.JournalReferenceTransaction, int64(1)).Return(nil)
+		mockAuditUsecase.On("Record", mock.Anything, mockTx, entity.AuditActionTransactionReverse, entity.AuditEntityTransaction, int64(1), paidTransaction, mock.Anything).Return(nil)
+
+		trx, err := mockUsecase.ReverseTransaction(financeCtx, 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.TransactionStatusReversed, trx.Status)
+		mockRepo.AssertExpectations(t)
+		mockPaymentUsecase.AssertExpectations(t)
+		mockLoanUsecase.AssertExpectations(t)
+		mockLedgerUsecase.AssertExpectations(t)
+		mockLoanUsecase.AssertNotCalled(t, "UpdateVirtualAccountStatus", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Success ReverseTransaction - Reopens Paid Off Loan", func(t *testing.T) {
+		mockTx := newMockTx(t, true)
+		mockUsecase, mockRepo, mockLoanUsecase, mockPaymentUsecase, mockAuditUsecase, mockLedgerUsecase, _ := setupTransactionMocks()
+
+		paidTransaction := &entity.Transaction{ID: 1, TotalAmount: MockPayment.TotalAmount, Status: entity.TransactionStatusPaid}
+		paidPayment := *MockPayment
+		paidPayment.LoanID = 1
+		paidOffLoan := &entity.Loan{ID: 1, Status: entity.LoanStatusPaid, VirtualAccount: "880800000000016", VirtualAccountStatus: entity.VirtualAccountStatusInactive}
+
+		mockRepo.On("GetTransactionByID", mock.Anything, int64(1)).Return(paidTransaction, nil)
+		mockRepo.On("BeginTx").Return(mockTx, nil)
+		mockRepo.On("UpdateTransactionStatus", mockTx, int64(1), entity.TransactionStatusPaid, entity.TransactionStatusReversed).Return(nil)
+		mockPaymentUsecase.On("GetPaymentsByTransactionID", mock.Anything, int64(1)).Return([]*entity.Payment{&paidPayment}, nil)
+		mockPaymentUsecase.On("UnpayPayments", mockTx, int64(1)).Return(nil)
+		mockLoanUsecase.On("GetLoanByIDForUpdate", mockTx, int64(1)).Return(paidOffLoan, nil)
+		mockLoanUsecase.On("UpdateLoanOutstanding", mockTx, paidPayment.TotalAmount, int64(1)).Return(nil)
+		mockLoanUsecase.On("UpdateVirtualAccountStatus", mockTx, paidOffLoan, entity.VirtualAccountStatusActive).Return(nil)
+		mockLedgerUsecase.On("PostReversal", mock.Anything, mockTx, entity.JournalReferenceTransaction, int64(1)).Return(nil)
+		mockAuditUsecase.On("Record", mock.Anything, mockTx, entity.AuditActionTransactionReverse, entity.AuditEntityTransaction, int64(1), paidTransaction, mock.Anything).Return(nil)
+
+		_, err := mockUsecase.ReverseTransaction(financeCtx, 1)
+
+		assert.NoError(t, err)
+		mockLoanUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Failed ReverseTransaction - Not Finance", func(t *testing.T) {
+		mockUsecase, mockRepo, _, _, _, _, _ := setupTransactionMocks()
+		ctx := entity.WithIdentity(context.Background(), &entity.Identity{Type: entity.IdentityTypeBorrower, Role: entity.RoleCollector, UserID: 1})
+
+		_, err := mockUsecase.ReverseTransaction(ctx, 1)
+
+		assert.ErrorIs(t, err, ErrForbidden)
+		mockRepo.AssertNotCalled(t, "GetTransactionByID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failed ReverseTransaction - Already Reversed", func(t *testing.T) {
+		mockUsecase, mockRepo, _, _, _, _, _ := setupTransactionMocks()
+		mockRepo.On("GetTransactionByID", mock.Anything, int64(1)).Return(&entity.Transaction{ID: 1, Status: entity.TransactionStatusReversed}, nil)
+
+		_, err := mockUsecase.ReverseTransaction(financeCtx, 1)
+
+		assert.ErrorIs(t, err, ErrTransactionNotReversible)
+		mockRepo.AssertNotCalled(t, "BeginTx")
+	})
+	t.Run("Failed ReverseTransaction - Reversed Concurrently", func(t *testing.T) {
+		mockTx := newMockTx(t, false)
+		mockUsecase, mockRepo, mockLoanUsecase, mockPaymentUsecase, _, mockLedgerUsecase, _ := setupTransactionMocks()
+		mockRepo.On("GetTransactionByID", mock.Anything, int64(1)).Return(&entity.Transaction{ID: 1, Status: entity.TransactionStatusPaid}, nil)
+		mockRepo.On("BeginTx").Return(mockTx, nil)
+		mockRepo.On("UpdateTransactionStatus", mockTx, int64(1), entity.TransactionStatusPaid, entity.TransactionStatusReversed).Return(repository.ErrTransactionStatusChanged)
+
+		_, err := mockUsecase.ReverseTransaction(financeCtx, 1)
+
+		assert.ErrorIs(t, err, ErrTransactionNotReversible)
+		mockPaymentUsecase.AssertNotCalled(t, "GetPaymentsByTransactionID", mock.Anything, mock.Anything)
+		mockLoanUsecase.AssertNotCalled(t, "UpdateLoanOutstanding", mock.Anything, mock.Anything, mock.Anything)
+		mockLedgerUsecase.AssertNotCalled(t, "PostReversal", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -2,33 +2,41 @@ package usecase
 
 import (
 	"context"
-	"errors"
+	"loan-management/internal/apperror"
 	"loan-management/internal/entity"
 	"loan-management/internal/repository"
+	"loan-management/internal/validation"
 )
 
 var (
-	ErrUserNotFound         = errors.New("User not found")
-	ErrEmailAlreadyUsed     = errors.New("Your email is already being used")
-	ErrMissingRequiredField = errors.New("Name & Email is required")
+	ErrUserNotFound         = repository.ErrUserNotFound
+	ErrEmailAlreadyUsed     = apperror.Conflict("EMAIL_ALREADY_USED", "Your email is already being used")
+	ErrMissingRequiredField = apperror.Validation("MISSING_REQUIRED_FIELD", "Name & Email is required")
+	ErrInvalidRole          = apperror.Validation("INVALID_ROLE", "Role can't be assigned to a user")
 )
 
+// a loan with more due payments than this is delinquent
+const delinquentDuePayments = 2
+
 type UserUsecaseInterface interface {
 	RegisterUser(ctx context.Context, user *entity.User) error
 	GetAllUsers(ctx context.Context) ([]*entity.User, error)
 	GetUserByID(ctx context.Context, id int64) (*entity.User, error)
 	GetUserByEmail(ctx context.Context, email string) (*entity.User, error)
 	IsUserDelinquent(ctx context.Context, userID int64) (bool, error)
+	UpdateUserRole(ctx context.Context, id int64, role entity.Role) (*entity.User, error)
 }
 
 type UserUsecase struct {
-	userRepo    repository.UserRepository
-	loanUsecase LoanUsecase
+	userRepo     repository.UserRepository
+	auditUsecase AuditUsecaseInterface
+	loanUsecase  LoanUsecase
 }
 
-func NewUserUsecase(userRepo repository.UserRepository) *UserUsecase {
+func NewUserUsecase(userRepo repository.UserRepository, auditUsecase AuditUsecaseInterface) *UserUsecase {
 	return &UserUsecase{
-		userRepo: userRepo,
+		userRepo:     userRepo,
+		auditUsecase: auditUsecase,
 	}
 }
 
@@ -41,18 +49,61 @@ func (u *UserUsecase) RegisterUser(ctx context.Context, user *entity.User) error
 		return ErrMissingRequiredField
 	}
 
+	if err := validation.Struct(user); err != nil {
+		return err
+	}
+
+	// roles are granted by an admin afterwards, registration always creates a borrower
+	user.Role = entity.RoleBorrower
+
+	if user.Password != "" {
+		hash, err := hashPassword(user.Password)
+		if err != nil {
+			return err
+		}
+		user.PasswordHash = hash
+		user.Password = ""
+	}
+
 	if user, _ := u.GetUserByEmail(ctx, user.Email); user != nil {
 		return ErrEmailAlreadyUsed
 	}
 
-	return u.userRepo.CreateUser(ctx, user)
+	tx, err := u.userRepo.BeginTx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = u.userRepo.CreateUser(tx, user); err != nil {
+		return err
+	}
+
+	if err = u.auditUsecase.Record(ctx, tx, entity.AuditActionUserRegister, entity.AuditEntityUser, user.ID, nil, user); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func (u *UserUsecase) GetAllUsers(ctx context.Context) ([]*entity.User, error) {
+	if err := authorize(ctx, entity.PermUserRead); err != nil {
+		return nil, err
+	}
+
 	return u.userRepo.GetAllUsers(ctx)
 }
 
 func (u *UserUsecase) GetUserByID(ctx context.Context, id int64) (*entity.User, error) {
+	if err := authorizeOwner(ctx, entity.PermUserRead, entity.PermUserReadOwn, id); err != nil {
+		return nil, err
+	}
+
 	return u.userRepo.GetUserByID(ctx, id)
 }
 
@@ -61,6 +112,9 @@ func (u *UserUsecase) GetUserByEmail(ctx context.Context, email string) (*entity
 }
 
 func (u *UserUsecase) IsUserDelinquent(ctx context.Context, userID int64) (bool, error) {
+	if err := authorizeOwner(ctx, entity.PermUserRead, entity.PermUserReadOwn, userID); err != nil {
+		return false, err
+	}
 
 	loanStatusActive := entity.LoanStatusActive
 	activeLoans, err := u.loanUsecase.GetLoansByUserID(ctx, userID, loanStatusActive)
@@ -74,10 +128,52 @@ func (u *UserUsecase) IsUserDelinquent(ctx context.Context, userID int64) (bool,
 	}
 
 	for _, loan := range activeLoans {
-		if numOfDuePayments, _ := u.loanUsecase.GetLoanDuePayments(ctx, loan); len(numOfDuePayments) > 2 {
+		if numOfDuePayments, _ := u.loanUsecase.GetLoanDuePayments(ctx, loan); len(numOfDuePayments) > delinquentDuePayments {
 			return true, nil
 		}
 	}
 
 	return false, nil
 }
+
+func (u *UserUsecase) UpdateUserRole(ctx context.Context, id int64, role entity.Role) (*entity.User, error) {
+	if err := authorize(ctx, entity.PermUserManageRole); err != nil {
+		return nil, err
+	}
+
+	if !role.IsAssignable() {
+		return nil, ErrInvalidRole
+	}
+
+	before, err := u.userRepo.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := u.userRepo.BeginTx()
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = u.userRepo.UpdateUserRole(tx, id, role); err != nil {
+		return nil, err
+	}
+
+	after := *before
+	after.Role = role
+	if err = u.auditUsecase.Record(ctx, tx, entity.AuditActionUserUpdateRole, entity.AuditEntityUser, id, before, &after); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &after, nil
+}
@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"testing"
 	"time"
@@ -10,8 +11,10 @@ import (
 
 	internalMock "loan-management/internal/mock"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
 )
 
 var MockUser = &entity.User{
@@ -20,27 +23,76 @@ var MockUser = &entity.User{
 	CreatedAt: time.Now(),
 }
 
+// newMockTx returns a tx expected to end with a commit, or a rollback when commit is false
+func newMockTx(t *testing.T, commit bool) *sql.Tx {
+	db, dbMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	dbMock.ExpectBegin()
+	if commit {
+		dbMock.ExpectCommit()
+	} else {
+		dbMock.ExpectRollback()
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
 func TestRegisterUser(t *testing.T) {
 	t.Run("Success User Register", func(t *testing.T) {
 		mockRepo := new(internalMock.MockUserRepository)
-		userUsecase := NewUserUsecase(mockRepo)
+		mockAudit := new(internalMock.MockAuditUsecase)
+		userUsecase := NewUserUsecase(mockRepo, mockAudit)
 
 		mockRepo.On("GetUserByEmail", mock.Anything, MockUser.Email).Return(nil, nil)
+		mockRepo.On("BeginTx").Return(newMockTx(t, true), nil)
 		mockRepo.On("CreateUser", mock.Anything, MockUser).Return(nil)
+		mockAudit.On("Record", mock.Anything, mock.Anything, entity.AuditActionUserRegister, entity.AuditEntityUser, MockUser.ID, nil, MockUser).Return(nil)
 
 		err := userUsecase.RegisterUser(context.Background(), MockUser)
 
 		assert.NoError(t, err)
 		mockRepo.AssertExpectations(t)
+		mockAudit.AssertExpectations(t)
+	})
+
+	t.Run("Success User Register - With Password", func(t *testing.T) {
+		mockRepo := new(internalMock.MockUserRepository)
+		mockAudit := new(internalMock.MockAuditUsecase)
+		userUsecase := NewUserUsecase(mockRepo, mockAudit)
+
+		customMockUser := *MockUser
+		customMockUser.Password = "password"
+
+		mockRepo.On("GetUserByEmail", mock.Anything, MockUser.Email).Return(nil, nil)
+		mockRepo.On("BeginTx").Return(newMockTx(t, true), nil)
+		mockRepo.On("CreateUser", mock.Anything, &customMockUser).Return(nil)
+		mockAudit.On("Record", mock.Anything, mock.Anything, entity.AuditActionUserRegister, entity.AuditEntityUser, mock.Anything, nil, &customMockUser).Return(nil)
+
+		err := userUsecase.RegisterUser(context.Background(), &customMockUser)
+
+		assert.NoError(t, err)
+		assert.Empty(t, customMockUser.Password)
+		assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(customMockUser.PasswordHash), []byte("password")))
+		mockRepo.AssertExpectations(t)
 	})
 
 	t.Run("Failed User Refister", func(t *testing.T) {
 		mockRepo := new(internalMock.MockUserRepository)
+		mockAudit := new(internalMock.MockAuditUsecase)
 
-		userUsecase := NewUserUsecase(mockRepo)
+		userUsecase := NewUserUsecase(mockRepo, mockAudit)
 
 		expectedError := errors.New("error")
 		mockRepo.On("GetUserByEmail", mock.Anything, MockUser.Email).Return(nil, nil)
+		mockRepo.On("BeginTx").Return(newMockTx(t, false), nil)
 		mockRepo.On("CreateUser", mock.Anything, MockUser).Return(expectedError)
 
 		err := userUsecase.RegisterUser(context.Background(), MockUser)
@@ -48,11 +100,13 @@ func TestRegisterUser(t *testing.T) {
 		assert.Error(t, err)
 		assert.Equal(t, expectedError, err)
 		mockRepo.AssertExpectations(t)
+		mockAudit.AssertNotCalled(t, "Record")
 	})
 
 	t.Run("Failed User Register - Missing field", func(t *testing.T) {
 		mockRepo := new(internalMock.MockUserRepository)
-		userUsecase := NewUserUsecase(mockRepo)
+		mockAudit := new(internalMock.MockAuditUsecase)
+		userUsecase := NewUserUsecase(mockRepo, mockAudit)
 
 		customMockUser := *MockUser
 		customMockUser.Name = ""
@@ -68,7 +122,8 @@ func TestRegisterUser(t *testing.T) {
 
 	t.Run("Failed User Register - Email already used", func(t *testing.T) {
 		mockRepo := new(internalMock.MockUserRepository)
-		userUsecase := NewUserUsecase(mockRepo)
+		mockAudit := new(internalMock.MockAuditUsecase)
+		userUsecase := NewUserUsecase(mockRepo, mockAudit)
 
 		mockRepo.On("GetUserByEmail", mock.Anything, MockUser.Email).Return(MockUser, nil)
 
@@ -84,8 +139,9 @@ func TestRegisterUser(t *testing.T) {
 func TestUserGetAllUsers(t *testing.T) {
 	t.Run("Success Get All Users", func(t *testing.T) {
 		mockRepo := new(internalMock.MockUserRepository)
+		mockAudit := new(internalMock.MockAuditUsecase)
 
-		userUsecase := NewUserUsecase(mockRepo)
+		userUsecase := NewUserUsecase(mockRepo, mockAudit)
 
 		expectedUsers := []*entity.User{MockUser}
 
@@ -100,8 +156,9 @@ func TestUserGetAllUsers(t *testing.T) {
 
 	t.Run("Failed Get All Users", func(t *testing.T) {
 		mockRepo := new(internalMock.MockUserRepository)
+		mockAudit := new(internalMock.MockAuditUsecase)
 
-		userUsecase := NewUserUsecase(mockRepo)
+		userUsecase := NewUserUsecase(mockRepo, mockAudit)
 
 		expectedError := errors.New("database error")
 		mockRepo.On("GetAllUsers", mock.Anything).Return([]*entity.User{}, expectedError)
@@ -118,8 +175,9 @@ func TestUserGetAllUsers(t *testing.T) {
 func TestUserGetByID(t *testing.T) {
 	t.Run("Success Get User by ID", func(t *testing.T) {
 		mockRepo := new(internalMock.MockUserRepository)
+		mockAudit := new(internalMock.MockAuditUsecase)
 
-		userUsecase := NewUserUsecase(mockRepo)
+		userUsecase := NewUserUsecase(mockRepo, mockAudit)
 
 		expectedUser := MockUser
 		expectedUser.ID = 1
@@ -135,8 +193,9 @@ func TestUserGetByID(t *testing.T) {
 
 	t.Run("Failed Get User by ID", func(t *testing.T) {
 		mockRepo := new(internalMock.MockUserRepository)
+		mockAudit := new(internalMock.MockAuditUsecase)
 
-		userUsecase := NewUserUsecase(mockRepo)
+		userUsecase := NewUserUsecase(mockRepo, mockAudit)
 
 		expectedError := errors.New("user not found")
 		mockRepo.On("GetUserByID", mock.Anything, int64(69)).Return((*entity.User)(nil), expectedError)
@@ -149,3 +208,49 @@ func TestUserGetByID(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 }
+
+func TestUpdateUserRole(t *testing.T) {
+	adminCtx := entity.WithIdentity(context.Background(), &entity.Identity{Type: entity.IdentityTypeBorrower, Role: entity.RoleAdmin, UserID: 1})
+
+	t.Run("Success Update User Role", func(t *testing.T) {
+		mockRepo := new(internalMock.MockUserRepository)
+		mockAudit := new(internalMock.MockAuditUsecase)
+		userUsecase := NewUserUsecase(mockRepo, mockAudit)
+
+		currentUser := &entity.User{ID: 2, Email: "officer@test", Name: "officer", Role: entity.RoleBorrower}
+		expectedUser := &entity.User{ID: 2, Email: "officer@test", Name: "officer", Role: entity.RoleCreditOfficer}
+		mockRepo.On("GetUserByID", mock.Anything, int64(2)).Return(currentUser, nil)
+		mockRepo.On("BeginTx").Return(newMockTx(t, true), nil)
+		mockRepo.On("UpdateUserRole", mock.Anything, int64(2), entity.RoleCreditOfficer).Return(nil)
+		mockAudit.On("Record", adminCtx, mock.Anything, entity.AuditActionUserUpdateRole, entity.AuditEntityUser, int64(2), currentUser, expectedUser).Return(nil)
+
+		user, err := userUsecase.UpdateUserRole(adminCtx, 2, entity.RoleCreditOfficer)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expectedUser, user)
+		mockRepo.AssertExpectations(t)
+		mockAudit.AssertExpectations(t)
+	})
+
+	t.Run("Failed Update User Role - Not Admin", func(t *testing.T) {
+		mockRepo := new(internalMock.MockUserRepository)
+		mockAudit := new(internalMock.MockAuditUsecase)
+		userUsecase := NewUserUsecase(mockRepo, mockAudit)
+		ctx := entity.WithIdentity(context.Background(), &entity.Identity{Type: entity.IdentityTypeBorrower, Role: entity.RoleCreditOfficer, UserID: 1})
+
+		_, err := userUsecase.UpdateUserRole(ctx, 2, entity.RoleAdmin)
+
+		assert.ErrorIs(t, err, ErrForbidden)
+		mockRepo.AssertNotCalled(t, "UpdateUserRole", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failed Update User Role - Partner Role", func(t *testing.T) {
+		mockRepo := new(internalMock.MockUserRepository)
+		mockAudit := new(internalMock.MockAuditUsecase)
+		userUsecase := NewUserUsecase(mockRepo, mockAudit)
+
+		_, err := userUsecase.UpdateUserRole(adminCtx, 2, entity.RolePartner)
+
+		assert.ErrorIs(t, err, ErrInvalidRole)
+	})
+}
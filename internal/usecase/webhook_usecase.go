@@ -0,0 +1,433 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"loan-management/internal/apperror"
+	"loan-management/internal/entity"
+	"loan-management/internal/repository"
+	"loan-management/internal/validation"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	webhookBatchSize   = 100
+	webhookMaxAttempts = 10
+	webhookTimeout     = 10 * time.Second
+	// response bodies are cut to this length in the delivery log
+	webhookErrorBodyLimit = 256
+	webhookSecretPrefix   = "whsec_"
+
+	HeaderWebhookID        = "X-Webhook-ID"
+	HeaderWebhookTimestamp = "X-Webhook-Timestamp"
+	HeaderWebhookSignature = "X-Webhook-Signature"
+)
+
+var (
+	ErrInvalidEventType            = apperror.Validation("INVALID_EVENT_TYPE", "Event type can't be subscribed to")
+	ErrWebhookSubscriptionDisabled = apperror.Conflict("WEBHOOK_SUBSCRIPTION_DISABLED", "Webhook subscription is disabled")
+)
+
+type WebhookUsecaseInterface interface {
+	CreateSubscription(ctx context.Context, payload *entity.WebhookSubscriptionPayload) (*entity.CreatedWebhookSubscription, error)
+	GetAllSubscriptions(ctx context.Context) ([]*entity.WebhookSubscription, error)
+	GetSubscriptionByID(ctx context.Context, id int64) (*entity.WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, id int64, payload *entity.UpdateWebhookSubscriptionPayload) (*entity.WebhookSubscription, error)
+	DisableSubscription(ctx context.Context, id int64) error
+	GetDeliveries(ctx context.Context, subscriptionID int64, filter entity.WebhookDeliveryFilter) ([]*entity.WebhookDelivery, error)
+	Redeliver(ctx context.Context, deliveryID int64) (*entity.WebhookDelivery, error)
+}
+
+// WebhookUsecase manages partner webhook subscriptions. As an EventSink it only records a delivery per matching
+// subscription, the deliveries are then sent and retried on their own so a slow partner never holds back the outbox
+type WebhookUsecase struct {
+	webhookRepo  repository.WebhookRepository
+	outboxRepo   repository.OutboxRepository
+	auditUsecase AuditUsecaseInterface
+	client       *http.Client
+}
+
+func NewWebhookUsecase(webhookRepo repository.WebhookRepository, outboxRepo repository.OutboxRepository, auditUsecase AuditUsecaseInterface) *WebhookUsecase {
+	return &WebhookUsecase{
+		webhookRepo:  webhookRepo,
+		outboxRepo:   outboxRepo,
+		auditUsecase: auditUsecase,
+		client:       &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+func (u *WebhookUsecase) CreateSubscription(ctx context.Context, payload *entity.WebhookSubscriptionPayload) (*entity.CreatedWebhookSubscription, error) {
+	if err := authorize(ctx, entity.PermWebhookManage); err != nil {
+		return nil, err
+	}
+
+	if err := validation.Struct(payload); err != nil {
+		return nil, err
+	}
+
+	eventTypes, err := uniqueEventTypes(payload.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := payload.Secret
+	if secret == "" {
+		random, err := randomHex(24)
+		if err != nil {
+			return nil, err
+		}
+		secret = webhookSecretPrefix + random
+	}
+
+	subscription := &entity.WebhookSubscription{
+		URL:        payload.URL,
+		EventTypes: eventTypes,
+		Secret:     secret,
+		Status:     entity.WebhookStatusActive,
+		CreatedAt:  now(),
+	}
+
+	tx, err := u.webhookRepo.BeginTx()
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = u.webhookRepo.CreateSubscription(tx, subscription); err != nil {
+		return nil, err
+	}
+
+	if err = u.auditUsecase.Record(ctx, tx, entity.AuditActionWebhookCreate, entity.AuditEntityWebhook, subscription.ID, nil, subscription); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &entity.CreatedWebhookSubscription{Subscription: subscription, Secret: secret}, nil
+}
+
+func (u *WebhookUsecase) GetAllSubscriptions(ctx context.Context) ([]*entity.WebhookSubscription, error) {
+	if err := authorize(ctx, entity.PermWebhookManage); err != nil {
+		return nil, err
+	}
+
+	return u.webhookRepo.GetAllSubscriptions(ctx, nil)
+}
+
+func (u *WebhookUsecase) GetSubscriptionByID(ctx context.Context, id int64) (*entity.WebhookSubscription, error) {
+	if err := authorize(ctx, entity.PermWebhookManage); err != nil {
+		return nil, err
+	}
+
+	return u.webhookRepo.GetSubscriptionByID(ctx, id)
+}
+
+// UpdateSubscription changes only the fields set in payload, the secret can't be changed
+func (u *WebhookUsecase) UpdateSubscription(ctx context.Context, id int64, payload *entity.UpdateWebhookSubscriptionPayload) (*entity.WebhookSubscription, error) {
+	if err := authorize(ctx, entity.PermWebhookManage); err != nil {
+		return nil, err
+	}
+
+	if err := validation.Struct(payload); err != nil {
+		return nil, err
+	}
+
+	before, err := u.webhookRepo.GetSubscriptionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	after := *before
+	if payload.URL != "" {
+		after.URL = payload.URL
+	}
+	if payload.EventTypes != nil {
+		if after.EventTypes, err = uniqueEventTypes(payload.EventTypes); err != nil {
+			return nil, err
+		}
+	}
+	if payload.Status != 0 {
+		after.Status = payload.Status
+	}
+
+	if err := u.saveSubscription(ctx, entity.AuditActionWebhookUpdate, before, &after); err != nil {
+		return nil, err
+	}
+
+	return &after, nil
+}
+
+// DisableSubscription stops new deliveries, the subscription and its delivery log are kept
+func (u *WebhookUsecase) DisableSubscription(ctx context.Context, id int64) error {
+	if err := authorize(ctx, entity.PermWebhookManage); err != nil {
+		return err
+	}
+
+	before, err := u.webhookRepo.GetSubscriptionByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	after := *before
+	after.Status = entity.WebhookStatusDisabled
+
+	return u.saveSubscription(ctx, entity.AuditActionWebhookDisable, before, &after)
+}
+
+func (u *WebhookUsecase) saveSubscription(ctx context.Context, action entity.AuditAction, before *entity.WebhookSubscription, after *entity.WebhookSubscription) error {
+	updatedAt := now()
+	after.UpdatedAt = &updatedAt
+
+	tx, err := u.webhookRepo.BeginTx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = u.webhookRepo.UpdateSubscription(tx, after); err != nil {
+		return err
+	}
+
+	if err = u.auditUsecase.Record(ctx, tx, action, entity.AuditEntityWebhook, after.ID, before, after); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (u *WebhookUsecase) GetDeliveries(ctx context.Context, subscriptionID int64, filter entity.WebhookDeliveryFilter) ([]*entity.WebhookDelivery, error) {
+	if err := authorize(ctx, entity.PermWebhookManage); err != nil {
+		return nil, err
+	}
+
+	if err := validation.Struct(filter); err != nil {
+		return nil, err
+	}
+
+	if _, err := u.webhookRepo.GetSubscriptionByID(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+
+	return u.webhookRepo.GetDeliveriesBySubscriptionID(ctx, subscriptionID, filter)
+}
+
+// Redeliver sends a delivery again right away whatever its status, e.g. after a partner fixed their endpoint
+func (u *WebhookUsecase) Redeliver(ctx context.Context, deliveryID int64) (*entity.WebhookDelivery, error) {
+	if err := authorize(ctx, entity.PermWebhookManage); err != nil {
+		return nil, err
+	}
+
+	delivery, err := u.webhookRepo.GetDeliveryByID(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription, err := u.webhookRepo.GetSubscriptionByID(ctx, delivery.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if subscription.Status != entity.WebhookStatusActive {
+		return nil, ErrWebhookSubscriptionDisabled
+	}
+
+	if err := u.attempt(ctx, subscription, delivery); err != nil {
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+func (u *WebhookUsecase) Name() string {
+	return "webhooks"
+}
+
+// Deliver records a pending delivery of event for every active subscription to its type
+func (u *WebhookUsecase) Deliver(ctx context.Context, event *entity.Event) error {
+	active := entity.WebhookStatusActive
+	subscriptions, err := u.webhookRepo.GetAllSubscriptions(ctx, &active)
+	if err != nil {
+		return err
+	}
+
+	var deliveries []*entity.WebhookDelivery
+	for _, subscription := range subscriptions {
+		if !subscription.Accepts(event.Type) {
+			continue
+		}
+
+		deliveries = append(deliveries, &entity.WebhookDelivery{
+			SubscriptionID: subscription.ID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			Status:         entity.WebhookDeliveryStatusPending,
+			NextAttemptAt:  now(),
+			CreatedAt:      now(),
+		})
+	}
+
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	return u.webhookRepo.CreateDeliveries(ctx, deliveries)
+}
+
+// Run sends due deliveries every interval until ctx is done
+func (u *WebhookUsecase) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := u.DeliverPending(ctx); err != nil {
+			log.Printf("Failed to deliver webhooks: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverPending sends one batch of due deliveries and returns how many were accepted
+func (u *WebhookUsecase) DeliverPending(ctx context.Context) (int, error) {
+	deliveries, err := u.webhookRepo.GetPendingDeliveries(ctx, now(), webhookBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	subscriptions := map[int64]*entity.WebhookSubscription{}
+	delivered := 0
+	for _, delivery := range deliveries {
+		subscription, ok := subscriptions[delivery.SubscriptionID]
+		if !ok {
+			if subscription, err = u.webhookRepo.GetSubscriptionByID(ctx, delivery.SubscriptionID); err != nil {
+				return delivered, err
+			}
+			subscriptions[subscription.ID] = subscription
+		}
+
+		if err := u.attempt(ctx, subscription, delivery); err != nil {
+			return delivered, err
+		}
+		if delivery.Status == entity.WebhookDeliveryStatusDelivered {
+			delivered++
+		}
+	}
+
+	return delivered, nil
+}
+
+// attempt posts the event to the subscription and saves the outcome, only failing to save is returned as an error
+func (u *WebhookUsecase) attempt(ctx context.Context, subscription *entity.WebhookSubscription, delivery *entity.WebhookDelivery) error {
+	event, err := u.outboxRepo.GetEventByID(ctx, delivery.EventID)
+	if err != nil {
+		return err
+	}
+
+	responseStatus, sendErr := u.send(ctx, subscription, delivery, event)
+
+	delivery.Attempts++
+	delivery.ResponseStatus = responseStatus
+	if sendErr == nil {
+		deliveredAt := now()
+		delivery.Status = entity.WebhookDeliveryStatusDelivered
+		delivery.DeliveredAt = &deliveredAt
+		delivery.LastError = ""
+	} else {
+		delivery.LastError = sendErr.Error()
+		delivery.NextAttemptAt = now().Add(retryBackoff(delivery.Attempts))
+		delivery.Status = entity.WebhookDeliveryStatusPending
+		if delivery.Attempts >= webhookMaxAttempts {
+			delivery.Status = entity.WebhookDeliveryStatusFailed
+			log.Printf("Giving up on webhook delivery %d to %s: %s", delivery.ID, subscription.URL, delivery.LastError)
+		}
+	}
+
+	return u.webhookRepo.UpdateDeliveryAttempt(ctx, delivery)
+}
+
+func (u *WebhookUsecase) send(ctx context.Context, subscription *entity.WebhookSubscription, delivery *entity.WebhookDelivery, event *entity.Event) (int, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", fmt.Sprint(event.ID))
+	req.Header.Set("X-Event-Type", string(event.Type))
+	req.Header.Set(HeaderWebhookID, fmt.Sprint(delivery.ID))
+	req.Header.Set(HeaderWebhookTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderWebhookSignature, SignWebhook(subscription.Secret, timestamp, body))
+
+	res, err := u.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(res.Body, webhookErrorBodyLimit))
+		return res.StatusCode, fmt.Errorf("unexpected status %d: %s", res.StatusCode, bytes.TrimSpace(snippet))
+	}
+
+	io.Copy(io.Discard, res.Body)
+	return res.StatusCode, nil
+}
+
+// SignWebhook returns the signature header value, a hex HMAC-SHA256 of "<timestamp>.<body>" keyed with the secret
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func uniqueEventTypes(eventTypes []entity.EventType) ([]entity.EventType, error) {
+	if len(eventTypes) == 0 {
+		return nil, ErrInvalidEventType
+	}
+
+	var unique []entity.EventType
+	seen := map[entity.EventType]bool{}
+	for _, eventType := range eventTypes {
+		if !eventType.IsValid() {
+			return nil, ErrInvalidEventType
+		}
+		if !seen[eventType] {
+			seen[eventType] = true
+			unique = append(unique, eventType)
+		}
+	}
+
+	return unique, nil
+}
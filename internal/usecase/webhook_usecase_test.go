@@ -0,0 +1,262 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"loan-management/internal/entity"
+	internalMock "loan-management/internal/mock"
+	"loan-management/internal/validation"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func setupWebhookMocks() (*WebhookUsecase, *internalMock.MockWebhookRepository, *internalMock.MockOutboxRepository, *internalMock.MockAuditUsecase) {
+	mockWebhookRepo := new(internalMock.MockWebhookRepository)
+	mockOutboxRepo := new(internalMock.MockOutboxRepository)
+	mockAuditUsecase := new(internalMock.MockAuditUsecase)
+
+	webhookUsecase := NewWebhookUsecase(mockWebhookRepo, mockOutboxRepo, mockAuditUsecase)
+
+	return webhookUsecase, mockWebhookRepo, mockOutboxRepo, mockAuditUsecase
+}
+
+// newWebhookReceiver verifies the signature of every request and answers with the next status in statuses
+func newWebhookReceiver(t *testing.T, secret string, statuses ...int) (*httptest.Server, *[]http.Header) {
+	var received []http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(HeaderWebhookTimestamp), 10, 64)
+		assert.Equal(t, SignWebhook(secret, timestamp, body), r.Header.Get(HeaderWebhookSignature))
+		assert.JSONEq(t, `{"id":7,"type":"payment.posted","aggregate_type":"loan","aggregate_id":1,"payload":{"loan_id":1},"occurred_at":"2025-01-01T00:00:00Z"}`, string(body))
+
+		received = append(received, r.Header)
+		status := statuses[len(received)-1]
+		w.WriteHeader(status)
+		if status >= 300 {
+			w.Write([]byte("partner is down\n"))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &received
+}
+
+func TestCreateSubscription(t *testing.T) {
+	mockTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Success CreateSubscription - Generated Secret", func(t *testing.T) {
+		webhookUsecase, mockWebhookRepo, _, mockAuditUsecase := setupWebhookMocks()
+		mockTx := newMockTx(t, true)
+		now = func() time.Time { return mockTime }
+		defer func() { now = time.Now }()
+
+		mockWebhookRepo.On("BeginTx").Return(mockTx, nil)
+		mockWebhookRepo.On("CreateSubscription", mockTx, mock.Anything).Run(func(args mock.Arguments) {
+			args.Get(1).(*entity.WebhookSubscription).ID = 1
+		}).Return(nil)
+		mockAuditUsecase.On("Record", mock.Anything, mockTx, entity.AuditActionWebhookCreate, entity.AuditEntityWebhook, int64(1), nil, mock.Anything).Return(nil)
+
+		created, err := webhookUsecase.CreateSubscription(context.Background(), &entity.WebhookSubscriptionPayload{
+			URL:        "https://partner.test/hooks",
+			EventTypes: []entity.EventType{entity.EventPaymentPosted, entity.EventPaymentPosted, entity.EventLoanBecameDelinquent},
+		})
+
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(created.Secret, webhookSecretPrefix))
+		assert.Equal(t, created.Secret, created.Subscription.Secret)
+		assert.Equal(t, []entity.EventType{entity.EventPaymentPosted, entity.EventLoanBecameDelinquent}, created.Subscription.EventTypes)
+		assert.Equal(t, entity.WebhookStatusActive, created.Subscription.Status)
+		mockAuditUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Failed CreateSubscription - Unknown Event Type", func(t *testing.T) {
+		webhookUsecase, mockWebhookRepo, _, _ := setupWebhookMocks()
+
+		_, err := webhookUsecase.CreateSubscription(context.Background(), &entity.WebhookSubscriptionPayload{
+			URL:        "https://partner.test/hooks",
+			EventTypes: []entity.EventType{"loan.exploded"},
+		})
+
+		assert.ErrorIs(t, err, ErrInvalidEventType)
+		mockWebhookRepo.AssertNotCalled(t, "BeginTx")
+	})
+
+	t.Run("Failed CreateSubscription - Invalid URL", func(t *testing.T) {
+		webhookUsecase, mockWebhookRepo, _, _ := setupWebhookMocks()
+
+		_, err := webhookUsecase.CreateSubscription(context.Background(), &entity.WebhookSubscriptionPayload{
+			URL:        "partner.test/hooks",
+			EventTypes: []entity.EventType{entity.EventPaymentPosted},
+		})
+
+		assert.ErrorIs(t, err, validation.ErrValidationFailed)
+		mockWebhookRepo.AssertNotCalled(t, "BeginTx")
+	})
+
+	t.Run("Failed CreateSubscription - Finance", func(t *testing.T) {
+		webhookUsecase, mockWebhookRepo, _, _ := setupWebhookMocks()
+		ctx := entity.WithIdentity(context.Background(), &entity.Identity{Type: entity.IdentityTypeBorrower, Role: entity.RoleFinance, UserID: 1})
+
+		_, err := webhookUsecase.CreateSubscription(ctx, &entity.WebhookSubscriptionPayload{
+			URL:        "https://partner.test/hooks",
+			EventTypes: []entity.EventType{entity.EventPaymentPosted},
+		})
+
+		assert.ErrorIs(t, err, ErrForbidden)
+		mockWebhookRepo.AssertNotCalled(t, "BeginTx")
+	})
+}
+
+func TestWebhookFanOut(t *testing.T) {
+	t.Run("Success Deliver - Only Subscribed Types", func(t *testing.T) {
+		webhookUsecase, mockWebhookRepo, _, _ := setupWebhookMocks()
+		mockTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		now = func() time.Time { return mockTime }
+		defer func() { now = time.Now }()
+
+		active := entity.WebhookStatusActive
+		mockWebhookRepo.On("GetAllSubscriptions", mock.Anything, &active).Return([]*entity.WebhookSubscription{
+			{ID: 1, EventTypes: []entity.EventType{entity.EventPaymentPosted}, Status: entity.WebhookStatusActive},
+			{ID: 2, EventTypes: []entity.EventType{entity.EventLoanCreated}, Status: entity.WebhookStatusActive},
+		}, nil)
+		mockWebhookRepo.On("CreateDeliveries", mock.Anything, []*entity.WebhookDelivery{{
+			SubscriptionID: 1,
+			EventID:        7,
+			EventType:      entity.EventPaymentPosted,
+			Status:         entity.WebhookDeliveryStatusPending,
+			NextAttemptAt:  mockTime,
+			CreatedAt:      mockTime,
+		}}).Return(nil)
+
+		err := webhookUsecase.Deliver(context.Background(), &entity.Event{ID: 7, Type: entity.EventPaymentPosted})
+
+		assert.NoError(t, err)
+		mockWebhookRepo.AssertExpectations(t)
+	})
+}
+
+func TestDeliverPending(t *testing.T) {
+	mockTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	event := &entity.Event{
+		ID:            7,
+		Type:          entity.EventPaymentPosted,
+		AggregateType: entity.EventAggregateLoan,
+		AggregateID:   1,
+		Payload:       json.RawMessage(`{"loan_id":1}`),
+		OccurredAt:    mockTime,
+	}
+
+	t.Run("Success DeliverPending - Signed And Retried", func(t *testing.T) {
+		webhookUsecase, mockWebhookRepo, mockOutboxRepo, _ := setupWebhookMocks()
+		now = func() time.Time { return mockTime }
+		defer func() { now = time.Now }()
+
+		server, received := newWebhookReceiver(t, "whsec_test", http.StatusInternalServerError, http.StatusOK)
+		subscription := &entity.WebhookSubscription{ID: 1, URL: server.URL, Secret: "whsec_test", Status: entity.WebhookStatusActive}
+		delivery := &entity.WebhookDelivery{ID: 3, SubscriptionID: 1, EventID: 7, EventType: entity.EventPaymentPosted, Status: entity.WebhookDeliveryStatusPending}
+
+		mockWebhookRepo.On("GetPendingDeliveries", mock.Anything, mockTime, webhookBatchSize).Return([]*entity.WebhookDelivery{delivery}, nil)
+		mockWebhookRepo.On("GetSubscriptionByID", mock.Anything, int64(1)).Return(subscription, nil)
+		mockOutboxRepo.On("GetEventByID", mock.Anything, int64(7)).Return(event, nil)
+		mockWebhookRepo.On("UpdateDeliveryAttempt", mock.Anything, delivery).Return(nil)
+
+		delivered, err := webhookUsecase.DeliverPending(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, delivered)
+		assert.Equal(t, entity.WebhookDeliveryStatusPending, delivery.Status)
+		assert.Equal(t, 1, delivery.Attempts)
+		assert.Equal(t, http.StatusInternalServerError, delivery.ResponseStatus)
+		assert.Equal(t, "unexpected status 500: partner is down", delivery.LastError)
+		assert.Equal(t, mockTime.Add(2*time.Second), delivery.NextAttemptAt)
+
+		delivered, err = webhookUsecase.DeliverPending(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, delivered)
+		assert.Equal(t, entity.WebhookDeliveryStatusDelivered, delivery.Status)
+		assert.Equal(t, 2, delivery.Attempts)
+		assert.Equal(t, http.StatusOK, delivery.ResponseStatus)
+		assert.Empty(t, delivery.LastError)
+		assert.Equal(t, &mockTime, delivery.DeliveredAt)
+
+		assert.Len(t, *received, 2)
+		assert.Equal(t, "3", (*received)[0].Get(HeaderWebhookID))
+		assert.Equal(t, "7", (*received)[0].Get("X-Event-ID"))
+		assert.Equal(t, "payment.posted", (*received)[0].Get("X-Event-Type"))
+		mockWebhookRepo.AssertNumberOfCalls(t, "UpdateDeliveryAttempt", 2)
+	})
+
+	t.Run("Success DeliverPending - Give Up After Max Attempts", func(t *testing.T) {
+		webhookUsecase, mockWebhookRepo, mockOutboxRepo, _ := setupWebhookMocks()
+		now = func() time.Time { return mockTime }
+		defer func() { now = time.Now }()
+
+		server, _ := newWebhookReceiver(t, "whsec_test", http.StatusGone)
+		subscription := &entity.WebhookSubscription{ID: 1, URL: server.URL, Secret: "whsec_test", Status: entity.WebhookStatusActive}
+		delivery := &entity.WebhookDelivery{ID: 3, SubscriptionID: 1, EventID: 7, Status: entity.WebhookDeliveryStatusPending, Attempts: webhookMaxAttempts - 1}
+
+		mockWebhookRepo.On("GetPendingDeliveries", mock.Anything, mockTime, webhookBatchSize).Return([]*entity.WebhookDelivery{delivery}, nil)
+		mockWebhookRepo.On("GetSubscriptionByID", mock.Anything, int64(1)).Return(subscription, nil)
+		mockOutboxRepo.On("GetEventByID", mock.Anything, int64(7)).Return(event, nil)
+		mockWebhookRepo.On("UpdateDeliveryAttempt", mock.Anything, delivery).Return(nil)
+
+		_, err := webhookUsecase.DeliverPending(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.WebhookDeliveryStatusFailed, delivery.Status)
+		assert.Equal(t, webhookMaxAttempts, delivery.Attempts)
+	})
+}
+
+func TestRedeliver(t *testing.T) {
+	mockTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Success Redeliver - Failed Delivery", func(t *testing.T) {
+		webhookUsecase, mockWebhookRepo, mockOutboxRepo, _ := setupWebhookMocks()
+		now = func() time.Time { return mockTime }
+		defer func() { now = time.Now }()
+
+		server, received := newWebhookReceiver(t, "whsec_test", http.StatusNoContent)
+		delivery := &entity.WebhookDelivery{ID: 3, SubscriptionID: 1, EventID: 7, Status: entity.WebhookDeliveryStatusFailed, Attempts: webhookMaxAttempts}
+
+		mockWebhookRepo.On("GetDeliveryByID", mock.Anything, int64(3)).Return(delivery, nil)
+		mockWebhookRepo.On("GetSubscriptionByID", mock.Anything, int64(1)).Return(&entity.WebhookSubscription{ID: 1, URL: server.URL, Secret: "whsec_test", Status: entity.WebhookStatusActive}, nil)
+		mockOutboxRepo.On("GetEventByID", mock.Anything, int64(7)).Return(&entity.Event{
+			ID:            7,
+			Type:          entity.EventPaymentPosted,
+			AggregateType: entity.EventAggregateLoan,
+			AggregateID:   1,
+			Payload:       json.RawMessage(`{"loan_id":1}`),
+			OccurredAt:    mockTime,
+		}, nil)
+		mockWebhookRepo.On("UpdateDeliveryAttempt", mock.Anything, delivery).Return(nil)
+
+		redelivered, err := webhookUsecase.Redeliver(context.Background(), 3)
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.WebhookDeliveryStatusDelivered, redelivered.Status)
+		assert.Equal(t, webhookMaxAttempts+1, redelivered.Attempts)
+		assert.Len(t, *received, 1)
+	})
+
+	t.Run("Failed Redeliver - Subscription Disabled", func(t *testing.T) {
+		webhookUsecase, mockWebhookRepo, mockOutboxRepo, _ := setupWebhookMocks()
+
+		mockWebhookRepo.On("GetDeliveryByID", mock.Anything, int64(3)).Return(&entity.WebhookDelivery{ID: 3, SubscriptionID: 1, EventID: 7}, nil)
+		mockWebhookRepo.On("GetSubscriptionByID", mock.Anything, int64(1)).Return(&entity.WebhookSubscription{ID: 1, Status: entity.WebhookStatusDisabled}, nil)
+
+		_, err := webhookUsecase.Redeliver(context.Background(), 3)
+
+		assert.ErrorIs(t, err, ErrWebhookSubscriptionDisabled)
+		mockOutboxRepo.AssertNotCalled(t, "GetEventByID", mock.Anything, mock.Anything)
+	})
+}
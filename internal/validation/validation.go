@@ -0,0 +1,129 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"loan-management/internal/apperror"
+	"net/mail"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var ErrValidationFailed = apperror.Validation("VALIDATION_FAILED", "Validation failed")
+
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	// report fields by their json name, falling back to the db column
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"json", "db"} {
+			name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			if name != "" {
+				return name
+			}
+		}
+		return field.Name
+	})
+
+	// the builtin rule requires a dotted domain, which rejects addresses like test@test
+	v.RegisterValidation("email", func(fl validator.FieldLevel) bool {
+		address, err := mail.ParseAddress(fl.Field().String())
+		return err == nil && address.Address == fl.Field().String()
+	})
+
+	return v
+}
+
+// Struct validates s against its `validate` tags and returns ErrValidationFailed listing every rejected field
+func Struct(s any) error {
+	err := validate.Struct(s)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return err
+	}
+
+	fields := make([]apperror.FieldError, len(validationErrors))
+	for i, fieldErr := range validationErrors {
+		fields[i] = apperror.FieldError{
+			Field:   fieldErr.Field(),
+			Rule:    fieldErr.Tag(),
+			Param:   fieldErr.Param(),
+			Message: message(fieldErr),
+			Key:     "validation." + key(fieldErr),
+		}
+	}
+
+	return ErrValidationFailed.WithFields(fields)
+}
+
+func message(fieldErr validator.FieldError) string {
+	field := fieldErr.Field()
+	switch key(fieldErr) {
+	case "required":
+		return fmt.Sprintf("%s is required", field)
+	case "email":
+		return fmt.Sprintf("%s must be a valid email", field)
+	case "http_url":
+		return fmt.Sprintf("%s must be a valid http url", field)
+	case "numeric":
+		return fmt.Sprintf("%s must be numeric", field)
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s", field, fieldErr.Param())
+	case "gte":
+		return fmt.Sprintf("%s must be at least %s", field, fieldErr.Param())
+	case "lt":
+		return fmt.Sprintf("%s must be less than %s", field, fieldErr.Param())
+	case "lte":
+		return fmt.Sprintf("%s must be at most %s", field, fieldErr.Param())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of [%s]", field, fieldErr.Param())
+	case "min.characters":
+		return fmt.Sprintf("%s must be at least %s characters", field, fieldErr.Param())
+	case "max.characters":
+		return fmt.Sprintf("%s must be at most %s characters", field, fieldErr.Param())
+	case "min.items":
+		return fmt.Sprintf("%s must have at least %s items", field, fieldErr.Param())
+	case "max.items":
+		return fmt.Sprintf("%s must have at most %s items", field, fieldErr.Param())
+	case "min":
+		return fmt.Sprintf("%s must be at least %s", field, fieldErr.Param())
+	case "max":
+		return fmt.Sprintf("%s must be at most %s", field, fieldErr.Param())
+	default:
+		return fmt.Sprintf("%s is invalid", field)
+	}
+}
+
+// key names the catalog message of fieldErr, min and max read differently for text, collections and numbers
+func key(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "min", "max":
+		if unit := unit(fieldErr); unit != "" {
+			return fieldErr.Tag() + "." + unit
+		}
+	}
+	return fieldErr.Tag()
+}
+
+// unit is what a length rule counts on the field, empty when it bounds a plain value
+func unit(fieldErr validator.FieldError) string {
+	switch fieldErr.Kind() {
+	case reflect.String:
+		return "characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return "items"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,74 @@
+package validation
+
+import (
+	"loan-management/internal/apperror"
+	"loan-management/internal/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStruct(t *testing.T) {
+	t.Run("Success Valid Payload", func(t *testing.T) {
+		payload := entity.CreateLoanPayload{
+			UserID:           1,
+			Amount:           5000000,
+			Interest:         10,
+			InterestType:     entity.InterestTypeFlatAnnual,
+			Tenure:           52,
+			TenureType:       entity.TenureTypeWeekly,
+			BillingStartDate: time.Now(),
+		}
+
+		assert.NoError(t, Struct(payload))
+	})
+
+	t.Run("Failed Invalid Payload", func(t *testing.T) {
+		payload := entity.CreateLoanPayload{
+			UserID:       1,
+			Amount:       -1,
+			Interest:     10,
+			InterestType: entity.InterestType(7),
+			Tenure:       0,
+		}
+
+		err := Struct(payload)
+		assert.ErrorIs(t, err, ErrValidationFailed)
+
+		appErr, ok := apperror.As(err)
+		assert.True(t, ok)
+		assert.Equal(t, []apperror.FieldError{
+			{Field: "amount", Rule: "gt", Param: "0", Message: "amount must be greater than 0", Key: "validation.gt"},
+			{Field: "interest_type", Rule: "oneof", Param: "0 1", Message: "interest_type must be one of [0 1]", Key: "validation.oneof"},
+			{Field: "tenure", Rule: "gt", Param: "0", Message: "tenure must be greater than 0", Key: "validation.gt"},
+			{Field: "billing_start_date", Rule: "required", Message: "billing_start_date is required", Key: "validation.required"},
+		}, appErr.Fields)
+	})
+
+	t.Run("Failed Min And Max By Kind", func(t *testing.T) {
+		payload := struct {
+			Secret     string   `json:"secret" validate:"min=16"`
+			EventTypes []string `json:"event_types" validate:"min=1"`
+			Tags       []string `json:"tags" validate:"max=1"`
+			Tenure     int      `json:"tenure" validate:"min=1,max=520"`
+		}{Secret: "short", EventTypes: []string{}, Tags: []string{"a", "b"}, Tenure: 600}
+
+		err := Struct(payload)
+
+		appErr, ok := apperror.As(err)
+		assert.True(t, ok)
+		assert.Equal(t, []apperror.FieldError{
+			{Field: "secret", Rule: "min", Param: "16", Message: "secret must be at least 16 characters", Key: "validation.min.characters"},
+			{Field: "event_types", Rule: "min", Param: "1", Message: "event_types must have at least 1 items", Key: "validation.min.items"},
+			{Field: "tags", Rule: "max", Param: "1", Message: "tags must have at most 1 items", Key: "validation.max.items"},
+			{Field: "tenure", Rule: "max", Param: "520", Message: "tenure must be at most 520", Key: "validation.max"},
+		}, appErr.Fields)
+	})
+
+	t.Run("Email", func(t *testing.T) {
+		assert.NoError(t, Struct(entity.CreateUserPayload{Email: "test@test", Name: "test"}))
+		assert.Error(t, Struct(entity.CreateUserPayload{Email: "not an email", Name: "test"}))
+		assert.Error(t, Struct(entity.CreateUserPayload{Email: "Test <test@test>", Name: "test"}))
+	})
+}
@@ -1,13 +1,17 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	_ "time/tzdata"
 
 	"loan-management/cmd"
 	"loan-management/infrastructure"
+	"loan-management/internal/calendar"
 	"loan-management/internal/delivery"
+	"loan-management/internal/entity"
 	"loan-management/internal/repository"
 	"loan-management/internal/usecase"
 	"loan-management/routes"
@@ -17,6 +21,13 @@ import (
 )
 
 func main() {
+	err := godotenv.Load()
+	if err != nil {
+		log.Println("Warning: No .env file found")
+	}
+
+	usecase.SetBusinessLocation(infrastructure.BusinessLocation())
+
 	if len(os.Args) > 1 {
 		command := os.Args[1]
 
@@ -27,17 +38,21 @@ func main() {
 			cmd.Seed()
 		case "destroy":
 			cmd.Destroy()
+		case "apikey":
+			cmd.APIKey(os.Args[2:])
+		case "role":
+			cmd.Role(os.Args[2:])
+		case "verify":
+			cmd.Verify(os.Args[2:])
+		case "statement":
+			cmd.Statement(os.Args[2:])
 		default:
 			fmt.Println("Unknown command:", command)
-			fmt.Println("Usage: app [migrate|seed|destroy]")
+			fmt.Println("Usage: app [migrate|seed|destroy|apikey|role|verify|statement]")
 			os.Exit(1)
 		}
 		return
 	}
-	err := godotenv.Load()
-	if err != nil {
-		log.Println("Warning: No .env file found")
-	}
 
 	db, err := infrastructure.Initialize()
 	if err != nil {
@@ -45,29 +60,96 @@ func main() {
 	}
 	defer infrastructure.CloseDB()
 
-	userRepo := repository.NewUserRepository(db)
-	userUsecase := usecase.NewUserUsecase(userRepo)
+	auditLogRepo := repository.NewAuditLogRepository(db, infrastructure.DBDialect)
+	auditUsecase := usecase.NewAuditUsecase(auditLogRepo)
+	auditHandler := delivery.NewAuditHandler(auditUsecase)
+
+	ledgerRepo := repository.NewLedgerRepository(db, infrastructure.DBDialect)
+	ledgerUsecase := usecase.NewLedgerUsecase(ledgerRepo)
+	ledgerHandler := delivery.NewLedgerHandler(ledgerUsecase)
+
+	outboxRepo := repository.NewOutboxRepository(db, infrastructure.DBDialect)
+	eventUsecase := usecase.NewEventUsecase(outboxRepo)
+	eventSubscribers := usecase.NewSubscriberSink()
+
+	webhookRepo := repository.NewWebhookRepository(db, infrastructure.DBDialect)
+	webhookUsecase := usecase.NewWebhookUsecase(webhookRepo, outboxRepo, auditUsecase)
+	webhookHandler := delivery.NewWebhookHandler(webhookUsecase)
+
+	eventDispatcher := usecase.NewEventDispatcher(outboxRepo, eventSinks(eventSubscribers, webhookUsecase)...)
+
+	userRepo := repository.NewUserRepository(db, infrastructure.DBDialect)
+	userUsecase := usecase.NewUserUsecase(userRepo, auditUsecase)
 	userHandler := delivery.NewUserHandler(userUsecase)
 
-	paymentRepo := repository.NewPaymentRepository(db)
+	paymentRepo := repository.NewPaymentRepository(db, infrastructure.DBDialect)
 	paymentUsecase := usecase.NewPaymentUsecase(paymentRepo)
 	paymentHandler := delivery.NewPaymentHandler(paymentUsecase)
 
-	loanRepo := repository.NewLoanRepository(db)
-	loanUsecase := usecase.NewLoanUsecase(loanRepo, userUsecase, paymentUsecase)
+	holidayRepo := repository.NewHolidayRepository(db, infrastructure.DBDialect)
+	holidayUsecase := usecase.NewHolidayUsecase(holidayRepo, paymentRepo, auditUsecase, infrastructure.BusinessDayConvention())
+	holidayHandler := delivery.NewHolidayHandler(holidayUsecase)
+	if path := os.Getenv("HOLIDAY_FILE"); path != "" {
+		importHolidays(holidayUsecase, path)
+	}
+
+	loanRepo := repository.NewLoanRepository(db, infrastructure.DBDialect)
+	loanUsecase := usecase.NewLoanUsecase(loanRepo, userUsecase, paymentUsecase, auditUsecase, ledgerUsecase, eventUsecase, holidayUsecase, infrastructure.VirtualAccountPrefix())
 	loanHandler := delivery.NewLoanHandler(loanUsecase)
 
 	userUsecase.InjectDependencies(loanUsecase)
 
-	transactionRepo := repository.NewTransactionRepository(db)
-	transactionUsecase := usecase.NewTransactionUsecase(transactionRepo, loanUsecase, paymentUsecase)
+	transactionRepo := repository.NewTransactionRepository(db, infrastructure.DBDialect)
+	transactionUsecase := usecase.NewTransactionUsecase(transactionRepo, loanUsecase, paymentUsecase, auditUsecase, ledgerUsecase, eventUsecase)
 	transactionHandler := delivery.NewTransactionHandler(transactionUsecase)
 
-	app := fiber.New()
+	paymentCallbackRepo := repository.NewPaymentCallbackRepository(db, infrastructure.DBDialect)
+	paymentCallbackUsecase := usecase.NewPaymentCallbackUsecase(paymentCallbackRepo, transactionUsecase, paymentProviders()...)
+	paymentCallbackHandler := delivery.NewPaymentCallbackHandler(paymentCallbackUsecase)
+
+	bankStatementRepo := repository.NewBankStatementRepository(db, infrastructure.DBDialect)
+	bankStatementUsecase := usecase.NewBankStatementUsecase(bankStatementRepo, transactionUsecase, auditUsecase)
+	bankStatementHandler := delivery.NewBankStatementHandler(bankStatementUsecase)
+
+	autodebitRepo := repository.NewAutodebitRepository(db, infrastructure.DBDialect)
+	autodebitPolicy := usecase.AutodebitRetryPolicy{MaxAttempts: infrastructure.AutodebitMaxAttempts(), Interval: infrastructure.AutodebitRetryInterval()}
+	autodebitUsecase := usecase.NewAutodebitUsecase(autodebitRepo, loanUsecase, transactionUsecase, auditUsecase, autodebitPolicy, debitProviders()...)
+	autodebitHandler := delivery.NewAutodebitHandler(autodebitUsecase)
 
-	routes := routes.NewRoutes(app, userHandler, paymentHandler, loanHandler, transactionHandler)
+	notificationRepo := repository.NewNotificationRepository(db, infrastructure.DBDialect)
+	notificationUsecase := usecase.NewNotificationUsecase(notificationRepo, userRepo, loanRepo, paymentRepo, auditUsecase, infrastructure.NotificationReminderDays(), notificationChannels()...)
+	notificationHandler := delivery.NewNotificationHandler(notificationUsecase)
+
+	eventSubscribers.Subscribe(entity.EventPaymentPosted, notificationUsecase.OnPaymentPosted)
+	eventSubscribers.Subscribe(entity.EventLoanPaidOff, notificationUsecase.OnLoanPaidOff)
+
+	statementUsecase := usecase.NewStatementUsecase(loanRepo, paymentRepo, transactionRepo, ledgerRepo)
+	statementHandler := delivery.NewStatementHandler(statementUsecase)
+
+	reportRepo := repository.NewReportRepository(db, infrastructure.DBDialect)
+	reportUsecase := usecase.NewReportUsecase(reportRepo)
+	reportHandler := delivery.NewReportHandler(reportUsecase)
+
+	reconciliationUsecase := usecase.NewReconciliationUsecase(loanRepo, paymentRepo, ledgerRepo, auditUsecase, ledgerUsecase)
+	reconciliationHandler := delivery.NewReconciliationHandler(reconciliationUsecase)
+
+	apiKeyRepo := repository.NewAPIKeyRepository(db, infrastructure.DBDialect)
+	authUsecase := usecase.NewAuthUsecase(userUsecase, apiKeyRepo, infrastructure.JWTSecret(), infrastructure.JWTTTL())
+	authHandler := delivery.NewAuthHandler(authUsecase)
+
+	app := fiber.New(fiber.Config{
+		ErrorHandler: delivery.ErrorHandler,
+	})
+
+	routes := routes.NewRoutes(app, authHandler, userHandler, paymentHandler, loanHandler, transactionHandler, auditHandler, ledgerHandler, reconciliationHandler, webhookHandler, paymentCallbackHandler, bankStatementHandler, autodebitHandler, notificationHandler, statementHandler, reportHandler, holidayHandler)
 	routes.SetupRoutes()
 
+	go eventDispatcher.Run(context.Background(), infrastructure.EventDispatchInterval())
+	go webhookUsecase.Run(context.Background(), infrastructure.WebhookDeliveryInterval())
+	go loanUsecase.WatchDelinquency(context.Background(), infrastructure.DelinquencyCheckInterval())
+	go autodebitUsecase.Run(context.Background(), infrastructure.AutodebitInterval())
+	go notificationUsecase.Run(context.Background(), infrastructure.NotificationInterval())
+
 	port := os.Getenv("APP_PORT")
 	if port == "" {
 		port = "3000"
@@ -75,3 +157,112 @@ func main() {
 
 	log.Fatal(app.Listen(":" + port))
 }
+
+// eventSinks always includes the in-process subscribers and the webhook subscriptions, the other sinks are enabled from the env
+func eventSinks(subscribers *usecase.SubscriberSink, webhooks *usecase.WebhookUsecase) []usecase.EventSink {
+	sinks := []usecase.EventSink{subscribers, webhooks}
+
+	if os.Getenv("EVENT_STDOUT") == "true" {
+		sinks = append(sinks, infrastructure.NewWriterSink("stdout", os.Stdout))
+	}
+
+	if path := os.Getenv("EVENT_FILE"); path != "" {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			log.Fatalf("Failed to open event file: %v", err)
+		}
+		sinks = append(sinks, infrastructure.NewWriterSink("file", file))
+	}
+
+	if url := os.Getenv("EVENT_WEBHOOK_URL"); url != "" {
+		sinks = append(sinks, infrastructure.NewWebhookSink(url))
+	}
+
+	return sinks
+}
+
+// paymentProviders are the gateways allowed to post payment callbacks, each enabled by its secret
+func paymentProviders() []usecase.PaymentProvider {
+	var providers []usecase.PaymentProvider
+
+	if secret := os.Getenv("PAYMENT_PROVIDER_FAKE_SECRET"); secret != "" {
+		providers = append(providers, infrastructure.NewFakePaymentProvider(secret))
+	}
+
+	return providers
+}
+
+// debitProviders are the direct debit schemes mandates can be collected through
+func debitProviders() []usecase.DebitProvider {
+	var providers []usecase.DebitProvider
+
+	if os.Getenv("AUTODEBIT_PROVIDER_FAKE") == "true" {
+		providers = append(providers, infrastructure.NewFakeDebitProvider())
+	}
+
+	return providers
+}
+
+// notificationChannels are the transports borrowers can be notified through, each enabled from the env
+func notificationChannels() []usecase.NotificationChannel {
+	var channels []usecase.NotificationChannel
+	enabled := map[string]bool{}
+
+	if host := os.Getenv("SMTP_HOST"); host != "" {
+		port := os.Getenv("SMTP_PORT")
+		if port == "" {
+			port = "25"
+		}
+		channels = append(channels, infrastructure.NewSMTPChannel(host, port, os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"), os.Getenv("SMTP_FROM")))
+		enabled[entity.NotificationChannelEmail] = true
+	}
+
+	if url := os.Getenv("SMS_GATEWAY_URL"); url != "" {
+		channels = append(channels, infrastructure.NewGatewayChannel(entity.NotificationChannelSMS, url, os.Getenv("SMS_GATEWAY_TOKEN")))
+		enabled[entity.NotificationChannelSMS] = true
+	}
+
+	if url := os.Getenv("PUSH_GATEWAY_URL"); url != "" {
+		channels = append(channels, infrastructure.NewGatewayChannel(entity.NotificationChannelPush, url, os.Getenv("PUSH_GATEWAY_TOKEN")))
+		enabled[entity.NotificationChannelPush] = true
+	}
+
+	if path := os.Getenv("NOTIFICATION_FILE"); path != "" {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			log.Fatalf("Failed to open notification file: %v", err)
+		}
+
+		var names []string
+		for _, name := range []string{entity.NotificationChannelEmail, entity.NotificationChannelSMS, entity.NotificationChannelPush} {
+			if !enabled[name] {
+				names = append(names, name)
+			}
+		}
+		for _, channel := range infrastructure.NewWriterChannels(file, names...) {
+			channels = append(channels, channel)
+		}
+	}
+
+	return channels
+}
+
+// importHolidays adds the holidays of a csv file (date,name) missing from the calendar
+func importHolidays(holidayUsecase *usecase.HolidayUsecase, path string) {
+	file, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("Failed to open holiday file: %v", err)
+	}
+	defer file.Close()
+
+	holidays, err := calendar.ParseCSV(file)
+	if err != nil {
+		log.Fatalf("Failed to read holiday file: %v", err)
+	}
+
+	imported, err := holidayUsecase.ImportHolidays(context.Background(), holidays)
+	if err != nil {
+		log.Fatalf("Failed to import holidays: %v", err)
+	}
+	log.Printf("Imported %d of %d holidays from %s", imported, len(holidays), path)
+}
@@ -2,57 +2,174 @@ package routes
 
 import (
 	"loan-management/internal/delivery"
+	"loan-management/internal/entity"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 type Routes struct {
-	app                *fiber.App
-	userHandler        *delivery.UserHandler
-	paymentHandler     *delivery.PaymentHandler
-	loanHandler        *delivery.LoanHandler
-	transactionHandler *delivery.TransactionHandler
+	app                   *fiber.App
+	authHandler           *delivery.AuthHandler
+	userHandler           *delivery.UserHandler
+	paymentHandler        *delivery.PaymentHandler
+	loanHandler           *delivery.LoanHandler
+	transactionHandler    *delivery.TransactionHandler
+	auditHandler          *delivery.AuditHandler
+	ledgerHandler         *delivery.LedgerHandler
+	reconciliationHandler *delivery.ReconciliationHandler
+	webhookHandler        *delivery.WebhookHandler
+	callbackHandler       *delivery.PaymentCallbackHandler
+	bankStatementHandler  *delivery.BankStatementHandler
+	autodebitHandler      *delivery.AutodebitHandler
+	notificationHandler   *delivery.NotificationHandler
+	statementHandler      *delivery.StatementHandler
+	reportHandler         *delivery.ReportHandler
+	holidayHandler        *delivery.HolidayHandler
 }
 
 func NewRoutes(
 	app *fiber.App,
+	authHandler *delivery.AuthHandler,
 	userHandler *delivery.UserHandler,
 	paymentHandler *delivery.PaymentHandler,
 	loanHandler *delivery.LoanHandler,
 	transactionHandler *delivery.TransactionHandler,
+	auditHandler *delivery.AuditHandler,
+	ledgerHandler *delivery.LedgerHandler,
+	reconciliationHandler *delivery.ReconciliationHandler,
+	webhookHandler *delivery.WebhookHandler,
+	callbackHandler *delivery.PaymentCallbackHandler,
+	bankStatementHandler *delivery.BankStatementHandler,
+	autodebitHandler *delivery.AutodebitHandler,
+	notificationHandler *delivery.NotificationHandler,
+	statementHandler *delivery.StatementHandler,
+	reportHandler *delivery.ReportHandler,
+	holidayHandler *delivery.HolidayHandler,
 ) *Routes {
 	return &Routes{
-		app:                app,
-		userHandler:        userHandler,
-		paymentHandler:     paymentHandler,
-		loanHandler:        loanHandler,
-		transactionHandler: transactionHandler,
+		app:                   app,
+		authHandler:           authHandler,
+		userHandler:           userHandler,
+		paymentHandler:        paymentHandler,
+		loanHandler:           loanHandler,
+		transactionHandler:    transactionHandler,
+		auditHandler:          auditHandler,
+		ledgerHandler:         ledgerHandler,
+		reconciliationHandler: reconciliationHandler,
+		webhookHandler:        webhookHandler,
+		callbackHandler:       callbackHandler,
+		bankStatementHandler:  bankStatementHandler,
+		autodebitHandler:      autodebitHandler,
+		notificationHandler:   notificationHandler,
+		statementHandler:      statementHandler,
+		reportHandler:         reportHandler,
+		holidayHandler:        holidayHandler,
 	}
 }
 
 func (r *Routes) SetupRoutes() {
 
-	api := r.app.Group("/api")
+	api := r.app.Group("/api", delivery.RequestID)
+	authenticate := func(ctx *fiber.Ctx) error { return r.authHandler.Authenticate(ctx) }
+	can := delivery.RequirePermission
+
+	// Auth Group
+	auth := api.Group("/auth")
+	auth.Post("/login", func(ctx *fiber.Ctx) error { return r.authHandler.Login(ctx) })
+	auth.Get("/me", authenticate, func(ctx *fiber.Ctx) error { return r.authHandler.Me(ctx) })
 
 	// Users Group
 	users := api.Group("/users")
-	users.Get("/", func(ctx *fiber.Ctx) error { return r.userHandler.GetAllUsers(ctx) })
-	users.Get("/:id", func(ctx *fiber.Ctx) error { return r.userHandler.GetUserByID(ctx) })
-	users.Get("/:id/delinquent-status", func(ctx *fiber.Ctx) error { return r.userHandler.CheckUserDelinquentStatus(ctx) })
 	users.Post("/register", func(ctx *fiber.Ctx) error { return r.userHandler.RegisterUser(ctx) })
+	users.Get("/", authenticate, can(entity.PermUserRead), func(ctx *fiber.Ctx) error { return r.userHandler.GetAllUsers(ctx) })
+	users.Get("/:id", authenticate, can(entity.PermUserRead, entity.PermUserReadOwn), func(ctx *fiber.Ctx) error { return r.userHandler.GetUserByID(ctx) })
+	users.Get("/:id/delinquent-status", authenticate, can(entity.PermUserRead, entity.PermUserReadOwn), func(ctx *fiber.Ctx) error { return r.userHandler.CheckUserDelinquentStatus(ctx) })
+	users.Get("/:id/notification-preferences", authenticate, can(entity.PermNotificationManage, entity.PermNotificationManageOwn), func(ctx *fiber.Ctx) error { return r.notificationHandler.GetPreferences(ctx) })
+	users.Put("/:id/notification-preferences", authenticate, can(entity.PermNotificationManage, entity.PermNotificationManageOwn), func(ctx *fiber.Ctx) error { return r.notificationHandler.UpdatePreferences(ctx) })
+	users.Put("/:id/role", authenticate, can(entity.PermUserManageRole), func(ctx *fiber.Ctx) error { return r.userHandler.UpdateUserRole(ctx) })
 
 	// Payment Group
-	payments := api.Group("/payments")
-	payments.Get("/", func(ctx *fiber.Ctx) error { return r.paymentHandler.GetAllPayments(ctx) })
+	payments := api.Group("/payments", authenticate)
+	payments.Get("/", can(entity.PermPaymentRead), func(ctx *fiber.Ctx) error { return r.paymentHandler.GetAllPayments(ctx) })
 
 	// Loans Group
-	loans := api.Group("/loans")
-	loans.Get("/", func(ctx *fiber.Ctx) error { return r.loanHandler.GetAllLoans(ctx) })
-	loans.Get("/:id", func(ctx *fiber.Ctx) error { return r.loanHandler.GetLoanByID(ctx) })
-	loans.Post("/create", func(ctx *fiber.Ctx) error { return r.loanHandler.CreateLoan(ctx) })
+	loans := api.Group("/loans", authenticate)
+	loans.Get("/", can(entity.PermLoanRead, entity.PermLoanReadOwn), func(ctx *fiber.Ctx) error { return r.loanHandler.GetAllLoans(ctx) })
+	loans.Get("/:id", can(entity.PermLoanRead, entity.PermLoanReadOwn), func(ctx *fiber.Ctx) error { return r.loanHandler.GetLoanByID(ctx) })
+	loans.Get("/:id/statement", can(entity.PermLoanRead, entity.PermLoanReadOwn), func(ctx *fiber.Ctx) error { return r.statementHandler.GetStatement(ctx) })
+	loans.Get("/:id/restructures", can(entity.PermLoanRead, entity.PermLoanReadOwn), func(ctx *fiber.Ctx) error { return r.loanHandler.GetRestructures(ctx) })
+	loans.Post("/create", can(entity.PermLoanCreate, entity.PermLoanCreateOwn), func(ctx *fiber.Ctx) error { return r.loanHandler.CreateLoan(ctx) })
+	loans.Post("/:id/approve", can(entity.PermLoanApprove), func(ctx *fiber.Ctx) error { return r.loanHandler.ApproveLoan(ctx) })
+	loans.Post("/:id/reject", can(entity.PermLoanApprove), func(ctx *fiber.Ctx) error { return r.loanHandler.RejectLoan(ctx) })
+	loans.Post("/:id/restructure", can(entity.PermLoanApprove), func(ctx *fiber.Ctx) error { return r.loanHandler.RestructureLoan(ctx) })
 
 	// Transaction Group
-	trx := api.Group("/transaction")
-	trx.Get("/inquiry", func(ctx *fiber.Ctx) error { return r.transactionHandler.InquiryTransaction(ctx) })
-	trx.Post("/create", func(ctx *fiber.Ctx) error { return r.transactionHandler.CreateTransaction(ctx) })
+	trx := api.Group("/transaction", authenticate)
+	trx.Get("/inquiry", can(entity.PermTransactionInquiry, entity.PermTransactionInquiryOwn), func(ctx *fiber.Ctx) error { return r.transactionHandler.InquiryTransaction(ctx) })
+	trx.Get("/inquiry/user", can(entity.PermTransactionInquiry, entity.PermTransactionInquiryOwn), func(ctx *fiber.Ctx) error { return r.transactionHandler.InquiryUser(ctx) })
+	trx.Post("/create", can(entity.PermTransactionCreate, entity.PermTransactionCreateOwn), func(ctx *fiber.Ctx) error { return r.transactionHandler.CreateTransaction(ctx) })
+	trx.Post("/create/user", can(entity.PermTransactionCreate, entity.PermTransactionCreateOwn), func(ctx *fiber.Ctx) error { return r.transactionHandler.CreateUserTransaction(ctx) })
+	trx.Post("/:id/reverse", can(entity.PermTransactionReverse), func(ctx *fiber.Ctx) error { return r.transactionHandler.ReverseTransaction(ctx) })
+
+	// Audit Log Group
+	auditLogs := api.Group("/audit-logs", authenticate)
+	auditLogs.Get("/", can(entity.PermAuditRead), func(ctx *fiber.Ctx) error { return r.auditHandler.GetAuditLogs(ctx) })
+
+	// Ledger Group
+	ledger := api.Group("/ledger", authenticate)
+	ledger.Get("/trial-balance", can(entity.PermLedgerRead), func(ctx *fiber.Ctx) error { return r.ledgerHandler.GetTrialBalance(ctx) })
+
+	// Admin Group
+	admin := api.Group("/admin", authenticate)
+	admin.Get("/verify", can(entity.PermReconcile), func(ctx *fiber.Ctx) error { return r.reconciliationHandler.Verify(ctx) })
+	admin.Post("/verify/repair", can(entity.PermReconcile), func(ctx *fiber.Ctx) error { return r.reconciliationHandler.Repair(ctx) })
+	admin.Get("/holidays", can(entity.PermHolidayManage), func(ctx *fiber.Ctx) error { return r.holidayHandler.GetHolidays(ctx) })
+	admin.Post("/holidays", can(entity.PermHolidayManage), func(ctx *fiber.Ctx) error { return r.holidayHandler.CreateHoliday(ctx) })
+	admin.Delete("/holidays/:id", can(entity.PermHolidayManage), func(ctx *fiber.Ctx) error { return r.holidayHandler.DeleteHoliday(ctx) })
+
+	// Webhooks Group
+	webhooks := api.Group("/webhooks", authenticate, can(entity.PermWebhookManage))
+	webhooks.Post("/", func(ctx *fiber.Ctx) error { return r.webhookHandler.CreateSubscription(ctx) })
+	webhooks.Get("/", func(ctx *fiber.Ctx) error { return r.webhookHandler.GetAllSubscriptions(ctx) })
+	webhooks.Get("/:id", func(ctx *fiber.Ctx) error { return r.webhookHandler.GetSubscriptionByID(ctx) })
+	webhooks.Put("/:id", func(ctx *fiber.Ctx) error { return r.webhookHandler.UpdateSubscription(ctx) })
+	webhooks.Delete("/:id", func(ctx *fiber.Ctx) error { return r.webhookHandler.DisableSubscription(ctx) })
+	webhooks.Get("/:id/deliveries", func(ctx *fiber.Ctx) error { return r.webhookHandler.GetDeliveries(ctx) })
+	webhooks.Post("/deliveries/:id/redeliver", func(ctx *fiber.Ctx) error { return r.webhookHandler.Redeliver(ctx) })
+
+	// Payment Callbacks Group, providers authenticate with their signature
+	callbacks := api.Group("/callbacks/payments")
+	callbacks.Post("/:provider", func(ctx *fiber.Ctx) error { return r.callbackHandler.HandleCallback(ctx) })
+	callbacks.Get("/", authenticate, can(entity.PermPaymentCallbackRead), func(ctx *fiber.Ctx) error { return r.callbackHandler.GetPaymentCallbacks(ctx) })
+	callbacks.Get("/:id", authenticate, can(entity.PermPaymentCallbackRead), func(ctx *fiber.Ctx) error { return r.callbackHandler.GetPaymentCallbackByID(ctx) })
+
+	// Bank Statements Group, lines are registered before the statement ids
+	statements := api.Group("/bank-statements", authenticate, can(entity.PermBankStatementManage))
+	statements.Post("/", func(ctx *fiber.Ctx) error { return r.bankStatementHandler.ImportStatement(ctx) })
+	statements.Get("/", func(ctx *fiber.Ctx) error { return r.bankStatementHandler.GetStatements(ctx) })
+	statements.Get("/lines", func(ctx *fiber.Ctx) error { return r.bankStatementHandler.GetLines(ctx) })
+	statements.Post("/lines/:id/match", func(ctx *fiber.Ctx) error { return r.bankStatementHandler.MatchLine(ctx) })
+	statements.Post("/lines/:id/reject", func(ctx *fiber.Ctx) error { return r.bankStatementHandler.RejectLine(ctx) })
+	statements.Get("/:id", func(ctx *fiber.Ctx) error { return r.bankStatementHandler.GetStatementByID(ctx) })
+
+	// Autodebit Group
+	autodebit := api.Group("/autodebit", authenticate)
+	autodebit.Post("/mandates", can(entity.PermAutodebitManage, entity.PermAutodebitManageOwn), func(ctx *fiber.Ctx) error { return r.autodebitHandler.CreateMandate(ctx) })
+	autodebit.Get("/mandates", can(entity.PermAutodebitManage), func(ctx *fiber.Ctx) error { return r.autodebitHandler.GetMandates(ctx) })
+	autodebit.Get("/mandates/:id", can(entity.PermAutodebitManage, entity.PermAutodebitManageOwn), func(ctx *fiber.Ctx) error { return r.autodebitHandler.GetMandateByID(ctx) })
+	autodebit.Delete("/mandates/:id", can(entity.PermAutodebitManage, entity.PermAutodebitManageOwn), func(ctx *fiber.Ctx) error { return r.autodebitHandler.RevokeMandate(ctx) })
+	autodebit.Get("/mandates/:id/attempts", can(entity.PermAutodebitManage, entity.PermAutodebitManageOwn), func(ctx *fiber.Ctx) error { return r.autodebitHandler.GetAttempts(ctx) })
+	autodebit.Post("/collect", can(entity.PermAutodebitManage), func(ctx *fiber.Ctx) error { return r.autodebitHandler.Collect(ctx) })
+
+	// Notification Group
+	notifications := api.Group("/notifications", authenticate)
+	notifications.Get("/", can(entity.PermNotificationRead, entity.PermNotificationReadOwn), func(ctx *fiber.Ctx) error { return r.notificationHandler.GetNotifications(ctx) })
+	notifications.Post("/remind", can(entity.PermNotificationManage), func(ctx *fiber.Ctx) error { return r.notificationHandler.Remind(ctx) })
+
+	// Report Group
+	reports := api.Group("/reports", authenticate, can(entity.PermReportRead))
+	reports.Get("/portfolio", func(ctx *fiber.Ctx) error { return r.reportHandler.GetPortfolio(ctx) })
+	reports.Get("/aging", func(ctx *fiber.Ctx) error { return r.reportHandler.GetAging(ctx) })
+	reports.Get("/collections", func(ctx *fiber.Ctx) error { return r.reportHandler.GetCollections(ctx) })
+	reports.Get("/vintages", func(ctx *fiber.Ctx) error { return r.reportHandler.GetVintages(ctx) })
 }